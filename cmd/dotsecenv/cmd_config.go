@@ -0,0 +1,87 @@
+package main
+
+import (
+	clilib "github.com/dotsecenv/dotsecenv/internal/cli"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/gpg"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the configuration file",
+	Long:  `Commands for managing the configuration file: check, migrate.`,
+}
+
+var configCheckJSON bool
+
+var configCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check a config file for problems, for CI linting",
+	Long: `Check the configuration file for problems that parsing alone does not
+catch, exiting non-zero if any error is found.
+
+Errors:
+  - a vault path that is relative once ~ is expanded
+  - an approved_algorithms entry with an unknown algorithm or curve, a
+    non-positive min_bits, or an ECC/EdDSA entry without curves
+  - a behavior or hooks flag that is not true or false
+  - a duration or limit that does not parse
+  - a gpg.program that is not 'PATH' or an absolute executable path
+  - a login fingerprint with no encryption-capable secret key in the
+    GPG keyring
+
+Warnings:
+  - a vault file that does not exist yet
+  - curves listed for RSA
+
+Use -c to check a config file other than the default.
+
+Options:
+  --json  Print the problems as JSON`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		out := defaultOutput()
+		configPath := clilib.ResolveConfigPath(globalOpts.ConfigPath, globalOpts.Silent, out.Stderr())
+		exitWithError(clilib.ConfigCheck(configPath, &gpg.GPGClient{}, configCheckJSON, out))
+	},
+}
+
+var configMigrateDryRun bool
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Rewrite a config file from an older shape to the current one",
+	Long: `Rewrite the configuration file from an older shape to the current one,
+printing each change made.
+
+Migrations:
+  strict: true     Replaced by behavior.require_explicit_vault_upgrade and
+                   behavior.restrict_to_configured_vaults set to true; a
+                   behavior setting that is already present is kept
+  strict: false    Removed; behavior settings default to false
+  fingerprint: FP  Removed; identity comes from the signed login section
+                   created by 'dotsecenv login'
+
+Comments on the remaining entries are kept, though indentation is
+normalized to two spaces. A config that is already current is left
+untouched, so running migrate again changes nothing.
+
+Use -c to migrate a config file other than the default.
+
+Options:
+  --dry-run  Print the changes without writing them`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		out := defaultOutput()
+		configPath := clilib.ResolveConfigPath(globalOpts.ConfigPath, globalOpts.Silent, out.Stderr())
+		exitWithError(clilib.ConfigMigrate(configPath, configMigrateDryRun, out))
+	},
+}
+
+func init() {
+	addJSONFlag(configCheckCmd, &configCheckJSON, "Print the problems as JSON")
+	configMigrateCmd.Flags().BoolVar(&configMigrateDryRun, "dry-run", false, "Print the changes without writing them")
+
+	configCmd.AddCommand(configCheckCmd)
+	configCmd.AddCommand(configMigrateCmd)
+}
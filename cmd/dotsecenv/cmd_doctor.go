@@ -53,6 +53,6 @@ Options:
 }
 
 func init() {
-	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "Output as JSON")
+	addJSONFlag(doctorCmd, &doctorJSON, "Output as JSON")
 	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Auto-fix issues without prompting")
 }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dotsecenv/dotsecenv/internal/awssm"
+	clilib "github.com/dotsecenv/dotsecenv/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export secrets to another secret store",
+	Long: `Commands for pushing secrets to other secret stores: aws.
+
+To print secrets in a deployment format instead, use 'secret export'.`,
+}
+
+// export aws flags
+var (
+	exportAWSPrefix string
+	exportAWSRegion string
+)
+
+var exportAWSCmd = &cobra.Command{
+	Use:   "aws",
+	Short: "Push readable secrets to AWS Secrets Manager",
+	Long: `Decrypt every secret you can read and store each in AWS Secrets Manager
+as a plain string secret named PREFIX followed by the secret key. Namespaced
+keys become paths: with --prefix myapp/, prod::DB_URL is stored as
+myapp/prod/DB_URL. Existing secrets get a new version; missing ones are
+created.
+
+Without -v, each secret resolves exactly as 'secret get SECRET' does (the
+first vault holding it wins). With -v, only that vault is exported. Secrets
+you cannot read are left out.
+
+Requests are made with the aws command-line tool, which must be on PATH.
+Credentials and region are resolved by it as usual (environment variables,
+profiles, SSO, instance roles). Values are passed to it in a private
+temporary file that is removed once it exits.
+
+Options:
+  --prefix PREFIX  Prefix of the Secrets Manager secret names (required)
+  --region NAME    AWS region, overriding the resolved one
+  --progress[=WHEN]
+                   Report progress on stderr: auto (default; only on a
+                   terminal) or always`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultPath, fromIndex, err := parseVaultSpec(globalOpts.ConfigPath, globalOpts.VaultPaths)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(int(clilib.ExitGeneralError))
+		}
+
+		// Clear VaultPaths for createCLI if we're using an index
+		if fromIndex > 0 {
+			globalOpts.VaultPaths = []string{}
+		}
+
+		cli, cliErr := createCLI()
+		if cliErr != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, cliErr)))
+		}
+		defer func() { _ = cli.Close() }()
+
+		client := &awssm.CLIClient{Region: exportAWSRegion}
+		exitWithError(cli.SecretExportAWS(client, exportAWSPrefix, vaultPath, fromIndex))
+	},
+}
+
+func init() {
+	exportAWSCmd.Flags().StringVar(&exportAWSPrefix, "prefix", "", "Prefix of the Secrets Manager secret names")
+	exportAWSCmd.Flags().StringVar(&exportAWSRegion, "region", "", "AWS region, overriding the resolved one")
+	addProgressFlag(exportAWSCmd)
+	_ = exportAWSCmd.MarkFlagRequired("prefix")
+
+	exportCmd.AddCommand(exportAWSCmd)
+}
@@ -8,6 +8,9 @@ import (
 )
 
 var identityAddAll bool
+var identityAddAllMissing bool
+var identityAddExpireWarnDays int
+var identityAddFailOnExpiring bool
 
 var identityAddCmd = &cobra.Command{
 	Use:   "add FINGERPRINT",
@@ -19,26 +22,47 @@ configured approved_algorithms, signed, and appended to the vault.
 
 If the identity already exists in a vault, it is skipped.
 
+With --all-missing, the identity is added only to vaults that lack it and
+vaults that already contain it count as success, so the command is safe to
+rerun. A summary of added and already-present vaults is printed. With -v,
+only the selected vault is considered.
+
 Options:
-  --all  Add identity to all configured vaults
-  -v     Target vault (path or 1-based index)
+  --all          Add identity to all configured vaults
+  --all-missing  Add identity to every configured vault that lacks it
+  --fingerprint  Sign as this identity instead of the logged-in one
+  -v             Target vault (path or 1-based index)
+  --expire-warn-days N
+                 Warn when the key expires within N days (default 30)
+  --fail-on-expiring
+                 Refuse such keys instead of warning
 
 When neither --all nor -v is specified, the vault is auto-selected if only
 one is configured, or you are prompted to choose interactively.`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		fingerprint := args[0]
+		if identityAddExpireWarnDays < 0 {
+			exitWithError(clilib.NewError("--expire-warn-days must not be negative", clilib.ExitGeneralError))
+		}
+
 		cli, err := createCLI()
 		if err != nil {
 			os.Exit(int(clilib.PrintError(os.Stderr, err)))
 		}
 		defer func() { _ = cli.Close() }()
+		cli.SetExpiryWarning(identityAddExpireWarnDays, identityAddFailOnExpiring)
 
 		vaultPath, fromIndex, parseErr := parseVaultSpec(globalOpts.ConfigPath, globalOpts.VaultPaths)
 		if parseErr != nil {
 			os.Exit(int(clilib.PrintError(os.Stderr, clilib.NewError(parseErr.Error(), clilib.ExitGeneralError))))
 		}
 
+		if identityAddAllMissing {
+			exitWithError(cli.IdentityAddAllMissing(fingerprint, vaultPath, fromIndex))
+			return
+		}
+
 		exitErr := cli.IdentityAdd(fingerprint, identityAddAll, vaultPath, fromIndex)
 		exitWithError(exitErr)
 	},
@@ -46,6 +70,11 @@ one is configured, or you are prompted to choose interactively.`,
 
 func init() {
 	identityAddCmd.Flags().BoolVar(&identityAddAll, "all", false, "Add identity to all configured vaults")
+	identityAddCmd.Flags().BoolVar(&identityAddAllMissing, "all-missing", false, "Add identity to every configured vault that lacks it")
+	identityAddCmd.Flags().StringVar(&globalOpts.Fingerprint, "fingerprint", "", "Sign as this identity for this command instead of the logged-in one")
+	identityAddCmd.Flags().IntVar(&identityAddExpireWarnDays, "expire-warn-days", 30, "Warn when the key expires within this many days")
+	identityAddCmd.Flags().BoolVar(&identityAddFailOnExpiring, "fail-on-expiring", false, "Refuse keys that expire within --expire-warn-days instead of warning")
+	identityAddCmd.MarkFlagsMutuallyExclusive("all", "all-missing")
 
 	identityCmd.AddCommand(identityAddCmd)
 }
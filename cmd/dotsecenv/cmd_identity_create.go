@@ -10,11 +10,13 @@ import (
 )
 
 var identityCreateOpts struct {
-	Algorithm    string
-	Name         string
-	Email        string
-	TemplateOnly bool
-	NoPassphrase bool
+	Algorithm            string
+	Name                 string
+	Email                string
+	TemplateOnly         bool
+	NoPassphrase         bool
+	TemplateFile         string
+	PrintFingerprintOnly bool
 }
 
 var identityCmd = &cobra.Command{
@@ -42,15 +44,29 @@ Supported algorithms:
   - RSA4096           - RSA 4096-bit key
 
 The requested algorithm must be allowed by your configuration's approved_algorithms
-setting. See https://dotsecenv.com/concepts/compliance/ for details.`,
+setting. See https://dotsecenv.com/concepts/compliance/ for details.
+
+With --template-file, your own GPG batch parameters are used instead of the
+generated ones, e.g. for a custom expiration or subkey. The template must
+set Key-Type (and Key-Curve or Key-Length) for the --algo in use, and
+Name-Real and Name-Email matching --name and --email; these are taken from
+the template when the flags are omitted.
+
+With --print-fingerprint-only, the new key's fingerprint is the only output
+on stdout and all other messages go to stderr, so it can be captured:
+
+  FP=$(dotsecenv identity create --name CI --email ci@example.com \
+        --no-passphrase --print-fingerprint-only)`,
 	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		opts := clilib.IdentityCreateOptions{
-			Algorithm:    identityCreateOpts.Algorithm,
-			Name:         identityCreateOpts.Name,
-			Email:        identityCreateOpts.Email,
-			TemplateOnly: identityCreateOpts.TemplateOnly,
-			NoPassphrase: identityCreateOpts.NoPassphrase,
+			Algorithm:            identityCreateOpts.Algorithm,
+			Name:                 identityCreateOpts.Name,
+			Email:                identityCreateOpts.Email,
+			TemplateOnly:         identityCreateOpts.TemplateOnly,
+			NoPassphrase:         identityCreateOpts.NoPassphrase,
+			TemplateFile:         identityCreateOpts.TemplateFile,
+			PrintFingerprintOnly: identityCreateOpts.PrintFingerprintOnly,
 		}
 
 		// Try to create CLI (may fail if no config exists)
@@ -88,6 +104,11 @@ This allows you to:
 - Reduce attack surface by avoiding secret material handling in dotsecenv`)
 	identityCreateCmd.Flags().BoolVar(&identityCreateOpts.NoPassphrase, "no-passphrase", false,
 		"Create key without passphrase protection (for CI/automation only)")
+	identityCreateCmd.Flags().StringVar(&identityCreateOpts.TemplateFile, "template-file", "",
+		"Generate the key from this GPG batch template instead of the built-in one")
+	identityCreateCmd.Flags().BoolVar(&identityCreateOpts.PrintFingerprintOnly, "print-fingerprint-only", false,
+		"Print only the new key's fingerprint on stdout; other output goes to stderr")
+	identityCreateCmd.MarkFlagsMutuallyExclusive("print-fingerprint-only", "template")
 
 	identityCmd.AddCommand(identityCreateCmd)
 }
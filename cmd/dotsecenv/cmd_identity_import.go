@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+
+	clilib "github.com/dotsecenv/dotsecenv/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+var identityImportCmd = &cobra.Command{
+	Use:   "import SOURCE_PATH",
+	Short: "Copy identities from another vault",
+	Long: `Copy the identities of the vault at SOURCE_PATH into a target vault.
+Secrets are not copied, so this seeds a new vault with an existing team.
+
+Each identity's signature is verified against the source vault before it
+is imported. Identities that fail verification are reported and skipped,
+and the command exits non-zero. Identities already in the target vault are
+skipped. Imported identities are signed by the logged-in identity.
+
+Options:
+  --fingerprint  Sign as this identity instead of the logged-in one
+  -v             Target vault (path or 1-based index)
+
+When -v is not specified, the vault is auto-selected if only one is
+configured, or you are prompted to choose interactively.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sourcePath := args[0]
+		cli, err := createCLI()
+		if err != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, err)))
+		}
+		defer func() { _ = cli.Close() }()
+
+		vaultPath, fromIndex, parseErr := parseVaultSpec(globalOpts.ConfigPath, globalOpts.VaultPaths)
+		if parseErr != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, clilib.NewError(parseErr.Error(), clilib.ExitGeneralError))))
+		}
+
+		exitWithError(cli.IdentityImport(sourcePath, vaultPath, fromIndex))
+	},
+}
+
+func init() {
+	identityImportCmd.Flags().StringVar(&globalOpts.Fingerprint, "fingerprint", "", "Sign as this identity for this command instead of the logged-in one")
+
+	identityCmd.AddCommand(identityImportCmd)
+}
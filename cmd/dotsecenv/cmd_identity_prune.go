@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+
+	clilib "github.com/dotsecenv/dotsecenv/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+// identity prune-expired flags
+var identityPruneExpiredDryRun bool
+var identityPruneExpiredYes bool
+
+var identityPruneExpiredCmd = &cobra.Command{
+	Use:   "prune-expired",
+	Short: "Revoke unused identities whose keys have expired",
+	Long: `Revoke the identities whose keys have expired in a vault.
+
+An expired identity is kept, and reported with the reason, while it can read
+the current value of a secret or while it signed any identity, secret,
+secret value or revocation still in the vault. Re-encrypt with 'vault rekey
+--remove' first to stop an expired identity from reading secrets.
+
+Vaults are append-only: for each pruned identity a revocation, signed by
+you, is added, and readers stop seeing the identity. Its records stay in
+the vault file for history, and 'vault verify' checks the revocations. The
+first revocation moves the vault to format version 6, which older releases
+cannot read. Without --yes it prints the plan and asks for confirmation
+(nothing is written in CI or without a terminal).
+
+Use -v to target a specific vault.
+
+Options:
+  --dry-run  Print the plan without writing
+  --yes      Skip the confirmation prompt`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultPath, fromIndex, parseErr := parseVaultSpecScoped()
+		if parseErr != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, clilib.NewError(parseErr.Error(), clilib.ExitGeneralError))))
+		}
+
+		cli, err := createCLI()
+		if err != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, err)))
+		}
+		defer func() { _ = cli.Close() }()
+
+		exitWithError(cli.IdentityPruneExpired(identityPruneExpiredDryRun, identityPruneExpiredYes, vaultPath, fromIndex))
+	},
+}
+
+func init() {
+	identityPruneExpiredCmd.Flags().BoolVar(&identityPruneExpiredDryRun, "dry-run", false, "Print the plan without writing")
+	identityPruneExpiredCmd.Flags().BoolVar(&identityPruneExpiredYes, "yes", false, "Skip the confirmation prompt")
+	identityPruneExpiredCmd.MarkFlagsMutuallyExclusive("dry-run", "yes")
+
+	identityCmd.AddCommand(identityPruneExpiredCmd)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+
+	clilib "github.com/dotsecenv/dotsecenv/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+var identityRefreshCmd = &cobra.Command{
+	Use:   "refresh FINGERPRINT",
+	Short: "Update an identity's UID and expiry from GPG",
+	Long: `Re-read the UID, expiry and public key of the identity FINGERPRINT from
+GPG and record them in each vault holding it, for example after its owner
+changed their email address.
+
+Vaults are append-only: a new identity record, signed by you, is added and
+becomes the current one. The old record stays in the vault file for
+history. Vaults where nothing changed are left alone.
+
+Options:
+  --fingerprint  Sign as this identity instead of the logged-in one
+  -v             Refresh only the copy in this vault (path or 1-based index)`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		_, fromIndex, parseErr := parseVaultSpecScoped()
+		if parseErr != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, clilib.NewError(parseErr.Error(), clilib.ExitGeneralError))))
+		}
+
+		cli, err := createCLI()
+		if err != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, err)))
+		}
+		defer func() { _ = cli.Close() }()
+
+		exitWithError(cli.IdentityRefresh(args[0], fromIndex))
+	},
+}
+
+func init() {
+	identityRefreshCmd.Flags().StringVar(&globalOpts.Fingerprint, "fingerprint", "", "Sign as this identity for this command instead of the logged-in one")
+
+	identityCmd.AddCommand(identityRefreshCmd)
+}
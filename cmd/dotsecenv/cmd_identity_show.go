@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+
+	clilib "github.com/dotsecenv/dotsecenv/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+var identityShowJSON bool
+
+var identityShowCmd = &cobra.Command{
+	Use:   "show FINGERPRINT",
+	Short: "Show an identity and verify its signature",
+	Long: `Show everything the vaults record about the identity FINGERPRINT: its
+UID, algorithm, creation and expiry dates, and in each vault holding it,
+when it was added, who signed it and its hash.
+
+The identity's signature is verified against the signer's public key in
+each vault, and the result is printed. The command exits non-zero if the
+identity is in no vault, or if its signature fails in any of them.
+
+Options:
+  --json  Output as JSON, one entry per vault holding the identity
+  -v      Show only the copy in this vault (path or 1-based index)`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		_, fromIndex, parseErr := parseVaultSpecScoped()
+		if parseErr != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, clilib.NewError(parseErr.Error(), clilib.ExitGeneralError))))
+		}
+
+		cli, err := createCLI()
+		if err != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, err)))
+		}
+		defer func() { _ = cli.Close() }()
+
+		exitWithError(cli.IdentityShow(args[0], fromIndex, identityShowJSON))
+	},
+}
+
+func init() {
+	addJSONFlag(identityShowCmd, &identityShowJSON, "Output as JSON")
+
+	identityCmd.AddCommand(identityShowCmd)
+}
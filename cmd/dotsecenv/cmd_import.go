@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dotsecenv/dotsecenv/internal/awssm"
+	clilib "github.com/dotsecenv/dotsecenv/internal/cli"
+	"github.com/dotsecenv/dotsecenv/internal/hcvault"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import secrets from another secret store",
+	Long:  `Commands for importing secrets from other secret stores: hashicorp, aws.`,
+}
+
+// import hashicorp flags
+var (
+	importHashiCorpAddr   string
+	importHashiCorpPath   string
+	importHashiCorpAtomic bool
+	importHashiCorpResume bool
+)
+
+var importHashiCorpCmd = &cobra.Command{
+	Use:   "hashicorp",
+	Short: "Import a HashiCorp Vault KV v2 secret",
+	Long: `Read the latest version of a HashiCorp Vault KV version 2 secret and store
+each of its keys as a dotsecenv secret, encrypted to you.
+
+The token is read from the VAULT_TOKEN environment variable. The address
+defaults to VAULT_ADDR. --path is the mount followed by the secret path,
+as accepted by 'vault kv get' (e.g. secret/myapp).
+
+Keys must be valid dotsecenv secret keys. Without --atomic, every key is
+attempted and failures are reported; the command exits non-zero if any
+failed. With --atomic, nothing is stored unless every key can be.
+
+With --resume, keys whose latest value in the vault already matches the
+source are skipped, so an interrupted or partly failed import can be re-run.
+Each stored, skipped and failed key is reported, followed by a summary.
+No result log is written; --resume works from the vault's contents.
+
+Options:
+  --addr URL   Vault server address (default: $VAULT_ADDR)
+  --path PATH  KV v2 secret to import, as MOUNT/SECRET_PATH (required)
+  --atomic     Store all keys or none
+  --resume     Skip keys already imported with the same value
+  --progress[=WHEN]
+               Report progress on stderr: auto (default; only on a
+               terminal) or always
+  -v           Target vault (path or 1-based index)
+
+When -v is not specified, the vault is auto-selected if only one is
+configured, or you are prompted to choose interactively.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultPath, fromIndex, err := parseVaultSpec(globalOpts.ConfigPath, globalOpts.VaultPaths)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(int(clilib.ExitGeneralError))
+		}
+
+		addr := importHashiCorpAddr
+		if addr == "" {
+			addr = os.Getenv(hcvault.AddrEnv)
+		}
+
+		// Fetch before creating the CLI, which locks the vaults, so a slow
+		// server never holds the vault lock.
+		client := &hcvault.Client{Addr: addr, Token: os.Getenv(hcvault.TokenEnv)}
+		values, fetchErr := client.ReadKV2(importHashiCorpPath)
+		if fetchErr != nil {
+			fmt.Fprintf(os.Stderr, "error: hashicorp: %v\n", fetchErr)
+			os.Exit(int(clilib.ExitGeneralError))
+		}
+
+		// Clear VaultPaths for createCLI if we're using an index
+		if fromIndex > 0 {
+			globalOpts.VaultPaths = []string{}
+		}
+
+		cli, cliErr := createCLI()
+		if cliErr != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, cliErr)))
+		}
+		defer func() { _ = cli.Close() }()
+
+		exitWithError(cli.ImportSecrets(values, vaultPath, fromIndex, importHashiCorpAtomic, importHashiCorpResume))
+	},
+}
+
+// import aws flags
+var (
+	importAWSSecretID string
+	importAWSRegion   string
+	importAWSAtomic   bool
+	importAWSResume   bool
+)
+
+var importAWSCmd = &cobra.Command{
+	Use:   "aws",
+	Short: "Import an AWS Secrets Manager JSON secret",
+	Long: `Read an AWS Secrets Manager secret holding a JSON object and store each of
+its fields as a dotsecenv secret, encrypted to you.
+
+Requests are made with the aws command-line tool, which must be on PATH.
+Credentials and region are resolved by it as usual (environment variables,
+profiles, SSO, instance roles).
+
+Field names must be valid dotsecenv secret keys. Without --atomic, every
+field is attempted and failures are reported; the command exits non-zero if
+any failed. With --atomic, nothing is stored unless every field can be.
+
+With --resume, fields whose latest value in the vault already matches the
+source are skipped, so an interrupted or partly failed import can be re-run.
+Each stored, skipped and failed field is reported, followed by a summary.
+No result log is written; --resume works from the vault's contents.
+
+Options:
+  --secret-id ID  Name or ARN of the secret to import (required)
+  --region NAME   AWS region, overriding the resolved one
+  --atomic        Store all fields or none
+  --resume        Skip fields already imported with the same value
+  --progress[=WHEN]
+                  Report progress on stderr: auto (default; only on a
+                  terminal) or always
+  -v              Target vault (path or 1-based index)
+
+When -v is not specified, the vault is auto-selected if only one is
+configured, or you are prompted to choose interactively.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultPath, fromIndex, err := parseVaultSpec(globalOpts.ConfigPath, globalOpts.VaultPaths)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(int(clilib.ExitGeneralError))
+		}
+
+		// Fetch before creating the CLI, which locks the vaults.
+		client := &awssm.CLIClient{Region: importAWSRegion}
+		secretString, fetchErr := client.GetSecretString(importAWSSecretID)
+		if fetchErr != nil {
+			fmt.Fprintf(os.Stderr, "error: aws: %v\n", fetchErr)
+			os.Exit(int(clilib.ExitGeneralError))
+		}
+		values, parseErr := awssm.ParseSecretFields(secretString)
+		if parseErr != nil {
+			fmt.Fprintf(os.Stderr, "error: aws: %s: %v\n", importAWSSecretID, parseErr)
+			os.Exit(int(clilib.ExitValidationError))
+		}
+
+		// Clear VaultPaths for createCLI if we're using an index
+		if fromIndex > 0 {
+			globalOpts.VaultPaths = []string{}
+		}
+
+		cli, cliErr := createCLI()
+		if cliErr != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, cliErr)))
+		}
+		defer func() { _ = cli.Close() }()
+
+		exitWithError(cli.ImportSecrets(values, vaultPath, fromIndex, importAWSAtomic, importAWSResume))
+	},
+}
+
+func init() {
+	importHashiCorpCmd.Flags().StringVar(&importHashiCorpAddr, "addr", "", "Vault server address (default: $VAULT_ADDR)")
+	importHashiCorpCmd.Flags().StringVar(&importHashiCorpPath, "path", "", "KV v2 secret to import, as MOUNT/SECRET_PATH")
+	importHashiCorpCmd.Flags().BoolVar(&importHashiCorpAtomic, "atomic", false, "Store all keys or none")
+	importHashiCorpCmd.Flags().BoolVar(&importHashiCorpResume, "resume", false, "Skip keys already imported with the same value")
+	addProgressFlag(importHashiCorpCmd)
+	_ = importHashiCorpCmd.MarkFlagRequired("path")
+
+	importAWSCmd.Flags().StringVar(&importAWSSecretID, "secret-id", "", "Name or ARN of the secret to import")
+	importAWSCmd.Flags().StringVar(&importAWSRegion, "region", "", "AWS region, overriding the resolved one")
+	importAWSCmd.Flags().BoolVar(&importAWSAtomic, "atomic", false, "Store all fields or none")
+	importAWSCmd.Flags().BoolVar(&importAWSResume, "resume", false, "Skip fields already imported with the same value")
+	addProgressFlag(importAWSCmd)
+	_ = importAWSCmd.MarkFlagRequired("secret-id")
+
+	importCmd.AddCommand(importHashiCorpCmd)
+	importCmd.AddCommand(importAWSCmd)
+}
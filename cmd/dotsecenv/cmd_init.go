@@ -5,6 +5,7 @@ import (
 	"os"
 
 	clilib "github.com/dotsecenv/dotsecenv/internal/cli"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
 	"github.com/spf13/cobra"
 )
@@ -38,6 +39,7 @@ func defaultOutput() *output.Handler {
 	return output.NewHandler(os.Stdout, os.Stderr,
 		output.WithSilent(globalOpts.Silent),
 		output.WithStdin(os.Stdin),
+		output.WithFormat(outputFormat()),
 	)
 }
 
@@ -51,6 +53,10 @@ var initCmd = &cobra.Command{
 var initConfigOpts struct {
 	GPGProgram       string
 	LoginFingerprint string
+	SkipVerify       bool
+	Minimal          bool
+	FIPS             bool
+	Modern           bool
 }
 
 var initConfigCmd = &cobra.Command{
@@ -62,12 +68,34 @@ By default, creates a configuration file at the XDG config location.
 Use -c to specify a custom path.
 
 The config defaults to gpg.program: PATH, which resolves the gpg binary
-from the system PATH at runtime. Use --gpg-program to pin an absolute path.`,
+from the system PATH at runtime. Use --gpg-program to pin an absolute path.
+
+With --login, the config is initialized logged in as the given fingerprint.
+As with 'login', a random challenge is first signed with the key and
+verified against its public key; --skip-verify bypasses this check.
+
+Presets shape the approved_algorithms block. Without one, the default FIPS
+186-5 set is written: ECC P-384/P-521, EdDSA Ed25519/Ed448 and RSA 2048+.
+
+Presets:
+  --minimal  RSA 2048+ only
+  --fips     ECC P-384/P-521 and RSA 3072+; no EdDSA, whose OpenPGP
+             encryption subkeys (X25519/X448) are not FIPS-approved
+  --modern   EdDSA Ed25519/Ed448 and ECC P-384/P-521; no RSA`,
 	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		out := defaultOutput()
 		targetConfig := clilib.ResolveConfigPath(globalOpts.ConfigPath, globalOpts.Silent, out.Stderr())
-		err := clilib.InitConfig(targetConfig, globalOpts.VaultPaths, initConfigOpts.GPGProgram, initConfigOpts.LoginFingerprint, out)
+		preset := config.PresetDefault
+		switch {
+		case initConfigOpts.Minimal:
+			preset = config.PresetMinimal
+		case initConfigOpts.FIPS:
+			preset = config.PresetFIPS
+		case initConfigOpts.Modern:
+			preset = config.PresetModern
+		}
+		err := clilib.InitConfig(targetConfig, globalOpts.VaultPaths, initConfigOpts.GPGProgram, initConfigOpts.LoginFingerprint, initConfigOpts.SkipVerify, preset, out)
 		if err != nil {
 			os.Exit(int(clilib.PrintError(os.Stderr, err)))
 		}
@@ -75,6 +103,9 @@ from the system PATH at runtime. Use --gpg-program to pin an absolute path.`,
 	},
 }
 
+// initVaultName is stored in the header of newly initialized vaults.
+var initVaultName string
+
 var initVaultCmd = &cobra.Command{
 	Use:   "vault",
 	Short: "Initialize vault file(s)",
@@ -82,7 +113,10 @@ var initVaultCmd = &cobra.Command{
 
 Two modes of operation:
   1. With -v PATH or -v INDEX: Initialize a specific vault file (path or 1-based config index)
-  2. Without -v: Interactive mode using vaults from configuration`,
+  2. Without -v: Interactive mode using vaults from configuration
+
+Use --name to give the vault a human-readable label, shown by
+'vault describe'. The label is not signed and is informational only.`,
 	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		out := defaultOutput()
@@ -103,7 +137,7 @@ Two modes of operation:
 
 			// Init specific vaults
 			for _, vPath := range resolvedPaths {
-				if err := clilib.InitVaultFile(vPath, out); err != nil {
+				if err := clilib.InitVaultFile(vPath, initVaultName, out); err != nil {
 					os.Exit(int(clilib.PrintError(os.Stderr, err)))
 				}
 			}
@@ -112,7 +146,7 @@ Two modes of operation:
 
 		// Interactive mode
 		effectiveConfig := clilib.ResolveConfigPath(globalOpts.ConfigPath, globalOpts.Silent, out.Stderr())
-		exitErr := clilib.InitVaultInteractiveStandalone(effectiveConfig, out)
+		exitErr := clilib.InitVaultInteractiveStandalone(effectiveConfig, initVaultName, out)
 		if exitErr != nil {
 			os.Exit(int(clilib.PrintError(os.Stderr, exitErr)))
 		}
@@ -164,7 +198,13 @@ func init() {
 	// Use custom pathValue to reject flag-like values during parsing (before Cobra's subcommand resolution)
 	initConfigCmd.Flags().Var(&pathValue{value: &initConfigOpts.GPGProgram}, "gpg-program", "Set gpg.program to this absolute path (default: PATH, resolved at runtime)")
 	initConfigCmd.Flags().StringVar(&initConfigOpts.LoginFingerprint, "login", "", "Initialize config with specified fingerprint")
+	initConfigCmd.Flags().BoolVar(&initConfigOpts.SkipVerify, "skip-verify", false, "With --login, skip signing a challenge to prove control of the secret key")
+	initConfigCmd.Flags().BoolVar(&initConfigOpts.Minimal, "minimal", false, "Approve RSA 2048+ keys only")
+	initConfigCmd.Flags().BoolVar(&initConfigOpts.FIPS, "fips", false, "Approve FIPS-approved ECC P-384/P-521 and RSA 3072+ keys only")
+	initConfigCmd.Flags().BoolVar(&initConfigOpts.Modern, "modern", false, "Approve EdDSA and ECC P-384/P-521 keys only")
+	initConfigCmd.MarkFlagsMutuallyExclusive("minimal", "fips", "modern")
 
+	initVaultCmd.Flags().StringVar(&initVaultName, "name", "", "Human-readable vault name stored in the vault header")
 	initSecenvCmd.Flags().BoolVar(&initSecenvAll, "all", false, "Add all not-present references without prompting")
 
 	initCmd.AddCommand(initConfigCmd)
@@ -8,6 +8,9 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// login flags
+var loginSkipVerify bool
+
 var loginCmd = &cobra.Command{
 	Use:   "login [FINGERPRINT]",
 	Short: "Initialize user identity",
@@ -21,7 +24,14 @@ of the user who will be accessing secrets.
 
 This command creates a cryptographically signed login proof that is
 stored in your configuration file, ensuring only users with access
-to the secret key can configure dotsecenv to use it.`,
+to the secret key can configure dotsecenv to use it.
+
+Before writing the config, login signs a random challenge with the
+selected key and verifies it against the public key. This catches
+mistyped fingerprints and keys whose secret part is not available.
+
+Options:
+  --skip-verify  Skip the key-control check`,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		// Warn if -v is specified (it has no effect on login)
@@ -42,7 +52,11 @@ to the secret key can configure dotsecenv to use it.`,
 			fingerprint = args[0]
 		}
 
-		exitErr := cli.Login(fingerprint)
+		exitErr := cli.Login(fingerprint, loginSkipVerify)
 		exitWithError(exitErr)
 	},
 }
+
+func init() {
+	loginCmd.Flags().BoolVar(&loginSkipVerify, "skip-verify", false, "Skip signing a challenge to prove control of the secret key")
+}
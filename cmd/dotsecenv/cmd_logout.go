@@ -0,0 +1,33 @@
+package main
+
+import (
+	"os"
+
+	clilib "github.com/dotsecenv/dotsecenv/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Clear the cached session",
+	Long: `Clear the session cache next to the config file.
+
+When a command is run with --fingerprint, the fingerprint is checked
+against the secret keys in the GPG keyring, and a successful check is
+cached for session_ttl (a config setting, 15m by default; 0s disables the
+cache) so repeated commands skip it. The cache holds only the fingerprint,
+the GNUPGHOME it was checked in and its expiry, never key material.
+
+logout removes the cache, so the next --fingerprint is checked again. The
+signed login in the config is kept; run 'dotsecenv login' to change it.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cli, err := clilib.NewCLIConfigOnly(globalOpts.ConfigPath, globalOpts.Silent, os.Stdin, os.Stdout, os.Stderr)
+		if err != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, err)))
+		}
+		defer func() { _ = cli.Close() }()
+
+		exitWithError(cli.Logout())
+	},
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+
+	clilib "github.com/dotsecenv/dotsecenv/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+var preCommitCmd = &cobra.Command{
+	Use:   "pre-commit FILE...",
+	Short: "Validate staged vault files from a git pre-commit hook",
+	Long: `Validate vault files before they are committed, blocking the commit on
+structural errors or tampering.
+
+Each FILE gets the structural and signature checks of 'validate FILE',
+with one line of output per file and the errors of files that fail. The
+exit code is non-zero if any file fails. Files are never modified.
+
+Pass the staged vault files as arguments, e.g. from .git/hooks/pre-commit:
+
+  git diff --cached --name-only --diff-filter=ACM -- '*.vault' |
+    xargs -r dotsecenv pre-commit
+
+or as a local hook of the pre-commit framework, which passes the files
+matching its 'files' pattern:
+
+  - id: dotsecenv
+    name: dotsecenv vault validation
+    entry: dotsecenv pre-commit
+    language: system
+    files: \.vault$`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cli, err := clilib.NewCLIConfigOnly(globalOpts.ConfigPath, globalOpts.Silent, os.Stdin, os.Stdout, os.Stderr)
+		if err != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, err)))
+		}
+		defer func() { _ = cli.Close() }()
+
+		exitWithError(cli.PreCommit(args))
+	},
+}
@@ -16,12 +16,17 @@ import (
 var secretCmd = &cobra.Command{
 	Use:   "secret",
 	Short: "Manage secrets",
-	Long:  `Commands for managing secrets: store, get, share, revoke, forget.`,
+	Long: `Commands for managing secrets: store, get, put-file, get-file, export, share, revoke, forget.
+
+Secret commands act as the identity you logged in with. Use --fingerprint
+to act as a different identity for a single command; it takes precedence
+over the login in config, which is left unchanged. The secret key for the
+fingerprint must be in your GPG keyring.`,
 }
 
 // secret store (alias: put)
 var secretPutCmd = &cobra.Command{
-	Use:     "store SECRET",
+	Use:     "store [SECRET]",
 	Aliases: []string{"put"},
 	Short:   "Store an encrypted secret",
 	Long: `Store an encrypted secret value.
@@ -35,8 +40,92 @@ Keys are case-insensitive and normalized when stored:
   - Key name part: UPPERCASE
 
 The secret value is read from stdin. Use -v to specify which vault
-to store the secret in (either a path or 1-based index).`,
+to store the secret in (either a path or 1-based index).
+
+On a terminal, a single line is read with input hidden. When stdin is a
+pipe or file, the entire stream is read and exactly one trailing newline
+is removed, so these store the same value:
+
+  echo "value" | dotsecenv secret store KEY
+  printf '%s' "value" | dotsecenv secret store KEY
+
+Any further trailing newlines, and newlines within the value, are kept.
+
+With --stdin-json, no SECRET is given: stdin is read as a JSON object and
+each member is stored as a secret, named by its normalized key, and the
+vault is saved once. String members are stored as their text, other
+members as their JSON. Each key's result is reported, and keys that fail
+do not stop the others. --if-absent and --replace apply to every key:
+
+  echo '{"DB_URL": "postgres://...", "myapp::API_KEY": "abc"}' | \
+    dotsecenv secret store --stdin-json
+
+A value typed at the terminal for a new secret is asked for twice, and
+nothing is stored if the two entries differ. Pass --confirm to be asked
+twice when replacing an existing secret too, or --confirm=false never to
+be. Piped values are never asked for again.
+
+With --from-env VAR, the value of the environment variable VAR is stored
+instead and stdin is not read. The command fails if VAR is unset, or if it
+is empty unless --allow-empty is also given.
+
+With --generate, a random value is generated with crypto/rand and stored
+instead, and stdin is not read. --length sets its length (32 by default)
+and --charset its characters: alnum (letters and digits, the default), hex
+or base64url. The value is not printed unless --show is given, in which
+case it is written once to stderr after it is stored.
+
+With --if-absent, an existing secret is left unchanged and the command
+exits successfully without storing a new value. A deleted secret counts
+as absent, but deleted secrets still cannot be overwritten.
+
+Vaults are append-only, so a plain store adds the new value alongside the
+secret's history. With --replace, the new value also supersedes that
+history: after it is stored, all but the newest max_history values (a
+config setting; 0, the default, keeps every value) are permanently removed
+from the vault, including values shared with other identities, who lose
+access. On a terminal you are asked to confirm first. To drop superseded
+values across a whole vault instead, use 'vault compact'.
+
+Values larger than max_secret_size (a config setting in bytes, 1 MiB by
+default) are refused, so a large file isn't stored by accident. Pass
+--allow-large to store one anyway.
+
+With --detach, the encrypted value is written to a sidecar file in the
+<vault>.values directory, named by its SHA-256, and the vault records only
+the reference. This keeps large values out of the vault file. The value's
+signature still covers its content, and the sidecar's hash is checked when
+it is read. Commit the .values directory together with the vault.
+
+With --compress, the value is gzipped before it is encrypted, which shrinks
+verbose text values such as certificates or JSON documents. The value is
+marked compressed, and reads decompress it, refusing a value that inflates
+past max_secret_size. Vaults holding compressed values use format version 4,
+which older releases cannot read. Compression is off by default: the
+ciphertext length then depends on the content, so a value that mixes a
+secret with text an attacker controls can leak the secret through its size
+(as in CRIME). Only compress values written wholly by trusted parties.
+
+With --source STRING, the value is tagged with where it came from, such as
+imported-from:aws or rotated-by-ci, so audits can tell human and automated
+changes apart. The source is covered by the value's signature and shown by
+'secret get --all'. Vaults holding values with a source use format
+version 5.
+
+A vault configured with default_recipients shares every new value with
+those identities as well as you, as if by 'secret share':
+
+  vault:
+    - name: team
+      path: ~/team/.dotsecenv/vault
+      default_recipients: [ALICEFP, BOBFP]
+
+Each default recipient must already be an identity in the vault. Pass
+--no-default-recipients to store a value readable by you only.`,
 	Args: func(cmd *cobra.Command, args []string) error {
+		if secretPutStdinJSON {
+			return cobra.NoArgs(cmd, args)
+		}
 		if err := cobra.ExactArgs(1)(cmd, args); err != nil {
 			return err
 		}
@@ -47,34 +136,53 @@ to store the secret in (either a path or 1-based index).`,
 		return nil
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		secretKey := args[0]
-
 		vaultPath, fromIndex, err := parseVaultSpec(globalOpts.ConfigPath, globalOpts.VaultPaths)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "error: %v\n", err)
 			os.Exit(int(clilib.ExitGeneralError))
 		}
 
+		if secretPutStdinJSON {
+			runSecretPutJSON(vaultPath, fromIndex)
+			return
+		}
+		secretKey := args[0]
+
+		if secretPutAllowEmpty && secretPutFromEnv == "" {
+			fmt.Fprintf(os.Stderr, "error: --allow-empty requires --from-env\n")
+			os.Exit(int(clilib.ExitGeneralError))
+		}
+		if !secretPutGenerate && (cmd.Flags().Changed("length") || cmd.Flags().Changed("charset") || secretPutShow) {
+			fmt.Fprintf(os.Stderr, "error: --length, --charset and --show require --generate\n")
+			os.Exit(int(clilib.ExitGeneralError))
+		}
+
 		// Read stdin BEFORE creating CLI (which acquires vault locks) to prevent
 		// deadlock when piping: `dotsecenv secret get KEY | dotsecenv secret store KEY`
 		// If stdin is piped (not TTY), read it now before vault lock acquisition.
 		var preReadValue string
-		if !term.IsTerminal(int(os.Stdin.Fd())) {
-			data, readErr := io.ReadAll(os.Stdin)
+		if secretPutFromEnv != "" {
+			envValue, envErr := clilib.LookupSecretEnv(secretPutFromEnv, secretPutAllowEmpty)
+			if envErr != nil {
+				os.Exit(int(clilib.PrintError(os.Stderr, envErr)))
+			}
+			preReadValue = envValue
+		} else if !secretPutGenerate && !term.IsTerminal(int(os.Stdin.Fd())) {
+			value, readErr := clilib.ReadPipedSecret(os.Stdin)
 			if readErr != nil {
 				fmt.Fprintf(os.Stderr, "error: failed to read from stdin: %v\n", readErr)
 				os.Exit(int(clilib.ExitGeneralError))
 			}
-			preReadValue = strings.TrimRight(string(data), "\n")
-			if preReadValue == "" {
-				fmt.Fprintf(os.Stderr, "error: no input provided on stdin\n")
-				os.Exit(int(clilib.ExitGeneralError))
-			}
+			preReadValue = value
 		}
 
-		if secretPutJSON && preReadValue != "" {
+		if secretPutJSON && (preReadValue != "" || secretPutFromEnv != "") {
 			if !json.Valid([]byte(preReadValue)) {
-				fmt.Fprintf(os.Stderr, "error: --json flag set but stdin is not valid JSON\n")
+				source := "stdin"
+				if secretPutFromEnv != "" {
+					source = "$" + secretPutFromEnv
+				}
+				fmt.Fprintf(os.Stderr, "error: --json flag set but %s is not valid JSON\n", source)
 				os.Exit(int(clilib.ExitValidationError))
 			}
 		}
@@ -89,20 +197,71 @@ to store the secret in (either a path or 1-based index).`,
 			os.Exit(int(clilib.PrintError(os.Stderr, cliErr)))
 		}
 		defer func() { _ = cli.Close() }()
+		cli.SetReplace(secretPutReplace)
+		cli.SetAllowLarge(secretPutAllowLarge)
+		cli.SetDetach(secretPutDetach)
+		cli.SetCompress(secretPutCompress)
+		cli.SetNoDefaultRecipients(secretPutNoDefaults)
+		if sourceErr := cli.SetSource(secretPutSource); sourceErr != nil {
+			exitWithError(sourceErr)
+		}
+		if cmd.Flags().Changed("confirm") {
+			cli.SetConfirmValue(secretPutConfirm)
+		}
 
-		exitErr := cli.SecretPut(secretKey, vaultPath, fromIndex, preReadValue)
+		var exitErr *clilib.Error
+		if secretPutGenerate {
+			exitErr = cli.SecretPutGenerated(secretKey, vaultPath, fromIndex, clilib.GenerateOptions{
+				Length:  secretPutLength,
+				Charset: secretPutCharset,
+				Show:    secretPutShow,
+			}, secretPutIfAbsent)
+		} else if secretPutFromEnv != "" {
+			exitErr = cli.SecretPutValue(secretKey, vaultPath, fromIndex, preReadValue, secretPutIfAbsent)
+		} else {
+			exitErr = cli.SecretPut(secretKey, vaultPath, fromIndex, preReadValue, secretPutIfAbsent)
+		}
 		exitWithError(exitErr)
 	},
 }
 
 // secret store flags
-var secretPutJSON bool
+var (
+	secretPutJSON       bool
+	secretPutIfAbsent   bool
+	secretPutFromEnv    string
+	secretPutAllowEmpty bool
+	secretPutReplace    bool
+	secretPutAllowLarge bool
+	secretPutDetach     bool
+	secretPutCompress   bool
+	secretPutNoDefaults bool
+	secretPutSource     string
+	secretPutConfirm    bool
+	secretPutStdinJSON  bool
+	secretPutGenerate   bool
+	secretPutLength     int
+	secretPutCharset    string
+	secretPutShow       bool
+)
 
 // secret get flags
 var (
-	secretGetAll  bool
-	secretGetLast bool
-	secretGetJSON bool
+	secretGetAll         bool
+	secretGetLast        bool
+	secretGetJSON        bool
+	secretGetConcurrency int
+	secretGetLatest      bool
+	secretGetRelative    bool
+	secretGetReverse     bool
+	secretGetFailFast    bool
+	secretGetDedupe      bool
+	secretGetBase64      bool
+	secretGetHex         bool
+	secretGetInclDeleted bool
+	secretGetFilter      string
+	secretGetExplain     bool
+	secretGetDepth       int
 )
 
 var secretGetCmd = &cobra.Command{
@@ -122,9 +281,48 @@ When called with a SECRET argument:
   Retrieves the secret value from the vault.
 
 Options:
-  --all   Retrieve all values for the secret across all vaults
-  --last  Retrieve the most recent value across all vaults
-  --json  Output as JSON`,
+  --all              Retrieve all values for the secret across all vaults
+  --last             Retrieve the most recent value across all vaults
+  --json             Output as JSON
+  --concurrency N    With --all, decrypt up to N values at once (default 1)
+  --require-latest   Fail if the latest value is not shared with you, instead
+                     of falling back to the newest value you can read
+  --relative         With --all, show when each value was added as a relative
+                     time (e.g. "3 days ago"); --json keeps RFC3339
+  --reverse          With --all, list values oldest first instead of
+                     newest first, e.g. to replay or audit history
+  --fail-fast        With --all, stop at the first value that cannot be
+                     decrypted instead of skipping it with a warning
+  --dedupe           With --all, collapse runs of consecutive identical
+                     values into the earliest one, listing only changes
+  --base64           Print the decrypted bytes base64-encoded
+  --hex              Print the decrypted bytes hex-encoded
+  --include-deleted  With SECRET, print the last value a deleted secret
+                     had before it was deleted (with a warning)
+  --filter GLOB      Without SECRET, list only keys matching GLOB
+                     (e.g. "DB_*", "*_PROD", "myapp::*")
+  --explain          When access is denied, explain why on stderr
+  --depth N          Search only the first N vaults in search order
+                     (default: all); cannot be combined with -v
+
+Deleted secrets are listed marked "(deleted)", but cannot be retrieved
+unless --include-deleted is given. It cannot be combined with --all or --last.
+
+--base64 and --hex make binary secrets safe to print; they apply to every
+value, including --all and --json output.
+
+By default --all is best effort: a value that cannot be decrypted, such as
+one not shared with you, is skipped with a warning and the others are
+printed. With --fail-fast, the command prints nothing and exits with the
+first such error.
+
+With --explain, an access-denied error is followed by a diagnostic: the
+vaults holding the secret, whether it is deleted there, whether your
+identity is in each vault, and the fingerprints the latest value is shared
+with. It reads vault metadata only; nothing is decrypted or printed.
+
+Higher --concurrency values may not help: some gpg-agent setups serialize
+decryption internally.`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
 			// List mode - no arguments needed
@@ -146,6 +344,31 @@ Options:
 			os.Exit(int(clilib.ExitGeneralError))
 		}
 
+		if secretGetConcurrency < 1 {
+			fmt.Fprintf(os.Stderr, "error: --concurrency must be at least 1\n")
+			os.Exit(int(clilib.ExitGeneralError))
+		}
+		if secretGetReverse && !secretGetAll {
+			fmt.Fprintf(os.Stderr, "error: --reverse requires --all\n")
+			os.Exit(int(clilib.ExitGeneralError))
+		}
+		if secretGetFailFast && !secretGetAll {
+			fmt.Fprintf(os.Stderr, "error: --fail-fast requires --all\n")
+			os.Exit(int(clilib.ExitGeneralError))
+		}
+		if secretGetDedupe && !secretGetAll {
+			fmt.Fprintf(os.Stderr, "error: --dedupe requires --all\n")
+			os.Exit(int(clilib.ExitGeneralError))
+		}
+		if secretGetDepth < 0 {
+			fmt.Fprintf(os.Stderr, "error: --depth must not be negative\n")
+			os.Exit(int(clilib.ExitGeneralError))
+		}
+		if secretGetDepth > 0 && (vaultPath != "" || fromIndex != 0) {
+			fmt.Fprintf(os.Stderr, "error: --depth and -v cannot be used together\n")
+			os.Exit(int(clilib.ExitGeneralError))
+		}
+
 		// Clear VaultPaths so createCLI loads from config
 		globalOpts.VaultPaths = []string{}
 
@@ -166,12 +389,44 @@ Options:
 				fmt.Fprintf(os.Stderr, "error: --last flag requires a secret key argument\n")
 				os.Exit(int(clilib.ExitGeneralError))
 			}
+			if secretGetLatest {
+				fmt.Fprintf(os.Stderr, "error: --require-latest flag requires a secret key argument\n")
+				os.Exit(int(clilib.ExitGeneralError))
+			}
+			if secretGetBase64 || secretGetHex {
+				fmt.Fprintf(os.Stderr, "error: --base64 and --hex flags require a secret key argument\n")
+				os.Exit(int(clilib.ExitGeneralError))
+			}
+			if secretGetDepth > 0 {
+				fmt.Fprintf(os.Stderr, "error: --depth flag requires a secret key argument\n")
+				os.Exit(int(clilib.ExitGeneralError))
+			}
 
+			cli.SetIncludeDeleted(secretGetInclDeleted)
+			cli.SetFilter(secretGetFilter, "")
 			exitErr := cli.SecretList(secretGetJSON, vaultPath, fromIndex)
 			exitWithError(exitErr)
 			return
 		}
 
+		cli.SetConcurrency(secretGetConcurrency)
+		cli.SetRequireLatest(secretGetLatest)
+		cli.SetIncludeDeleted(secretGetInclDeleted)
+		cli.SetRelativeTimes(secretGetRelative)
+		cli.SetOldestFirst(secretGetReverse)
+		cli.SetDedupe(secretGetDedupe)
+		cli.SetExplain(secretGetExplain)
+		cli.SetSearchDepth(secretGetDepth)
+		if secretGetFailFast {
+			cli.SetDecryptFailureMode(clilib.DecryptFailFast)
+		}
+		switch {
+		case secretGetBase64:
+			cli.SetValueEncoding(clilib.ValueEncodingBase64)
+		case secretGetHex:
+			cli.SetValueEncoding(clilib.ValueEncodingHex)
+		}
+
 		// Get secret value
 		secretKey := args[0]
 		exitErr := cli.SecretGet(secretKey, secretGetAll, secretGetLast, secretGetJSON, vaultPath, fromIndex)
@@ -317,10 +572,12 @@ Options:
 
 // secret forget flags
 var secretForgetIgnoreNotFound bool
+var secretForgetPrefix string
+var secretForgetYes bool
 
 // secret forget
 var secretForgetCmd = &cobra.Command{
-	Use:   "forget SECRET",
+	Use:   "forget SECRET | --prefix PREFIX",
 	Short: "Mark a secret as deleted",
 	Long: `Mark a secret as deleted in the vault.
 
@@ -332,8 +589,20 @@ This adds a deletion marker to the secret. The secret will no longer be
 returned by 'secret get' and will be shown as deleted in 'vault describe'.
 
 Use -v to specify which vault to delete the secret from (either a path
-or 1-based index).`,
+or 1-based index).
+
+With --prefix, every secret in the vault whose key starts with PREFIX is
+marked as deleted, such as when decommissioning a service. Secrets that
+are already deleted, or whose latest value you can't read, are skipped and
+reported. The matches are listed for confirmation unless --yes is given.
+
+Examples:
+  dotsecenv secret forget DATABASE_URL
+  dotsecenv secret forget --prefix SVC_ --yes`,
 	Args: func(cmd *cobra.Command, args []string) error {
+		if secretForgetPrefix != "" {
+			return cobra.NoArgs(cmd, args)
+		}
 		if err := cobra.ExactArgs(1)(cmd, args); err != nil {
 			return err
 		}
@@ -344,7 +613,306 @@ or 1-based index).`,
 		return nil
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		secretKey := args[0]
+		vaultPath, fromIndex, err := parseVaultSpec(globalOpts.ConfigPath, globalOpts.VaultPaths)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(int(clilib.ExitGeneralError))
+		}
+
+		// Clear VaultPaths for createCLI if we're using an index
+		if fromIndex > 0 {
+			globalOpts.VaultPaths = []string{}
+		}
+
+		cli, cliErr := createCLI()
+		if cliErr != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, cliErr)))
+		}
+		defer func() { _ = cli.Close() }()
+
+		if secretForgetPrefix != "" {
+			exitWithError(cli.SecretForgetPrefix(secretForgetPrefix, vaultPath, fromIndex, secretForgetYes))
+			return
+		}
+
+		exitErr := cli.SecretForget(args[0], vaultPath, fromIndex, secretForgetIgnoreNotFound)
+		exitWithError(exitErr)
+	},
+}
+
+var secretTouchCmd = &cobra.Command{
+	Use:   "touch SECRET",
+	Short: "Re-sign the latest value of a secret without changing it",
+	Long: `Re-sign the latest value of a secret without changing it.
+
+Secret key formats:
+  Namespaced:     namespace::KEY_NAME  (e.g., myapp::DATABASE_URL)
+  Non-namespaced: KEY_NAME             (e.g., DATABASE_URL)
+
+The latest value is decrypted and appended again, re-encrypted to the same
+recipients and signed by your identity. Use it to refresh a value's
+provenance, such as after the key that signed it was revoked.
+
+Use -v to specify which vault holds the secret (either a path or 1-based
+index).
+
+Examples:
+  dotsecenv secret touch DATABASE_URL
+  dotsecenv secret touch myapp::API_KEY -v 2`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if err := cobra.ExactArgs(1)(cmd, args); err != nil {
+			return err
+		}
+		// Validate secret key format
+		if _, err := vault.NormalizeSecretKey(args[0]); err != nil {
+			return fmt.Errorf("%s", vault.FormatSecretKeyError(err))
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultPath, fromIndex, err := parseVaultSpec(globalOpts.ConfigPath, globalOpts.VaultPaths)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(int(clilib.ExitGeneralError))
+		}
+
+		// Clear VaultPaths for createCLI if we're using an index
+		if fromIndex > 0 {
+			globalOpts.VaultPaths = []string{}
+		}
+
+		cli, cliErr := createCLI()
+		if cliErr != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, cliErr)))
+		}
+		defer func() { _ = cli.Close() }()
+
+		exitWithError(cli.SecretTouch(args[0], vaultPath, fromIndex))
+	},
+}
+
+// secretKeyAndFileArgs validates SECRET FILE positional arguments.
+func secretKeyAndFileArgs(cmd *cobra.Command, args []string) error {
+	if err := cobra.ExactArgs(2)(cmd, args); err != nil {
+		return err
+	}
+	// Validate secret key format
+	if _, err := vault.NormalizeSecretKey(args[0]); err != nil {
+		return fmt.Errorf("%s", vault.FormatSecretKeyError(err))
+	}
+	return nil
+}
+
+// runSecretPutJSON runs 'secret store --stdin-json'. The JSON object is read
+// from stdin before the vault is locked, as for a piped value.
+func runSecretPutJSON(vaultPath string, fromIndex int) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintf(os.Stderr, "error: --stdin-json reads a JSON object from stdin; pipe one in\n")
+		os.Exit(int(clilib.ExitGeneralError))
+	}
+	data, readErr := io.ReadAll(os.Stdin)
+	if readErr != nil {
+		fmt.Fprintf(os.Stderr, "error: failed to read from stdin: %v\n", readErr)
+		os.Exit(int(clilib.ExitGeneralError))
+	}
+
+	// Clear VaultPaths for createCLI if we're using an index
+	if fromIndex > 0 {
+		globalOpts.VaultPaths = []string{}
+	}
+
+	cli, cliErr := createCLI()
+	if cliErr != nil {
+		os.Exit(int(clilib.PrintError(os.Stderr, cliErr)))
+	}
+	defer func() { _ = cli.Close() }()
+	cli.SetReplace(secretPutReplace)
+	cli.SetAllowLarge(secretPutAllowLarge)
+	cli.SetDetach(secretPutDetach)
+	cli.SetCompress(secretPutCompress)
+	cli.SetNoDefaultRecipients(secretPutNoDefaults)
+	if sourceErr := cli.SetSource(secretPutSource); sourceErr != nil {
+		exitWithError(sourceErr)
+	}
+
+	exitWithError(cli.SecretPutJSON(data, vaultPath, fromIndex, secretPutIfAbsent))
+}
+
+// secret put-file
+var (
+	secretPutFileAllowLarge bool
+	secretPutFileDetach     bool
+	secretPutFileCompress   bool
+	secretPutFileNoDefaults bool
+	secretPutFileSource     string
+)
+
+var secretPutFileCmd = &cobra.Command{
+	Use:   "put-file SECRET FILE",
+	Short: "Store the contents of a file as an encrypted secret",
+	Long: `Store the contents of FILE as an encrypted secret value.
+
+The file is streamed through encryption rather than read into memory, which
+suits large values such as certificates, keystores or archives. The value is
+stored exactly as read, including any trailing newline.
+
+Files larger than max_secret_size (1 MiB unless configured) are refused
+unless --allow-large is given. With --detach, the encrypted value is stored
+in a sidecar file in the <vault>.values directory instead of inline. With
+--compress, the file is gzipped before it is encrypted (see 'secret store
+--help' for when not to), and with --source
+it is tagged with where it came from. The value is shared
+with the vault's default_recipients, if any, unless --no-default-recipients
+is given.
+
+Use -v to specify which vault to store the secret in (either a path or
+1-based index).`,
+	Args: secretKeyAndFileArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultPath, fromIndex, err := parseVaultSpec(globalOpts.ConfigPath, globalOpts.VaultPaths)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(int(clilib.ExitGeneralError))
+		}
+
+		// Clear VaultPaths for createCLI if we're using an index
+		if fromIndex > 0 {
+			globalOpts.VaultPaths = []string{}
+		}
+
+		cli, cliErr := createCLI()
+		if cliErr != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, cliErr)))
+		}
+		defer func() { _ = cli.Close() }()
+
+		cli.SetAllowLarge(secretPutFileAllowLarge)
+		cli.SetDetach(secretPutFileDetach)
+		cli.SetCompress(secretPutFileCompress)
+		cli.SetNoDefaultRecipients(secretPutFileNoDefaults)
+		if sourceErr := cli.SetSource(secretPutFileSource); sourceErr != nil {
+			exitWithError(sourceErr)
+		}
+
+		exitErr := cli.SecretPutFile(args[0], args[1], vaultPath, fromIndex)
+		exitWithError(exitErr)
+	},
+}
+
+// secret get-file
+var secretGetFileCmd = &cobra.Command{
+	Use:   "get-file SECRET FILE",
+	Short: "Decrypt a secret value into a file",
+	Long: `Decrypt a secret value and write it to FILE.
+
+The value is streamed from the vault through decryption rather than read into
+memory. FILE is created with mode 0600 and replaced only once decryption has
+succeeded; an existing FILE is overwritten.
+
+Without -v, the newest value you can read is taken from the first vault that
+holds one. Use -v to read from a specific vault (either a path or 1-based
+index).`,
+	Args: secretKeyAndFileArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultPath, fromIndex, err := parseVaultSpec(globalOpts.ConfigPath, globalOpts.VaultPaths)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(int(clilib.ExitGeneralError))
+		}
+
+		// Clear VaultPaths for createCLI if we're using an index
+		if fromIndex > 0 {
+			globalOpts.VaultPaths = []string{}
+		}
+
+		cli, cliErr := createCLI()
+		if cliErr != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, cliErr)))
+		}
+		defer func() { _ = cli.Close() }()
+
+		exitErr := cli.SecretGetFile(args[0], args[1], vaultPath, fromIndex)
+		exitWithError(exitErr)
+	},
+}
+
+// secret export flags
+var (
+	secretExportFormat           string
+	secretExportName             string
+	secretExportOutputDir        string
+	secretExportLowercase        bool
+	secretExportFilenameTemplate string
+	secretExportInclSignatures   bool
+	secretExportEnvPrefix        string
+)
+
+// secret export
+var secretExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export all readable secrets in a deployment format",
+	Long: `Decrypt every secret you can read and print them in the given format.
+
+Without -v, each secret resolves exactly as 'secret get SECRET' does (the
+first vault holding it wins). With -v, only that vault is exported. Secrets
+you cannot read are left out.
+
+Formats:
+  k8s-secret  Kubernetes Secret manifest (requires --name). Each value is
+              base64-encoded into data; secret keys are mapped to valid data
+              keys (namespace::KEY becomes namespace.KEY), and keys that
+              cannot be mapped are reported on stderr and skipped.
+  github-actions
+              GitHub Actions workflow commands. Every value is first
+              registered with ::add-mask:: (one mask per line), then set as
+              an environment variable named as in .secenv
+              (namespace::KEY becomes NAMESPACE_KEY). Inside GitHub Actions
+              the variables are appended to $GITHUB_ENV, so later steps see
+              them and they never reach the log; elsewhere they are printed
+              after the masks. --env-prefix prepends a prefix to every
+              name (--env-prefix APP_ turns DB_URL into APP_DB_URL); keys
+              that do not map to a valid shell variable name are reported
+              on stderr and skipped.
+  terraform   Terraform .tfvars assignments (name = "value"). Variable names
+              are the lowercased keys (namespace::KEY becomes namespace_key);
+              keys that are not valid HCL identifiers are reported on stderr
+              and skipped. Values are escaped as HCL strings, including
+              template sequences.
+
+With --output-dir instead of --format, each value is written to its own
+file in DIR, as Docker and Kubernetes secret mounts expect. Files are named
+after the secret keys (namespace::KEY becomes namespace.KEY), created with
+mode 0600 and replaced atomically. Deleted and unreadable secrets are
+reported on stderr and skipped.
+
+With --include-signatures instead of --format, nothing is decrypted: a JSON
+bundle lists every secret value's hash, signature, signer fingerprint and
+signer public key, so the values can be verified offline with
+'dotsecenv vault verify-bundle FILE'. Signatures are hex-encoded detached
+OpenPGP signatures of the hash, so standard GPG tooling can check them too.
+
+Options:
+  --format FORMAT  Output format (required unless --output-dir or
+                   --include-signatures is given)
+  --name NAME      metadata.name of the Kubernetes Secret
+  --env-prefix PREFIX
+                   Prefix of the environment variable names (github-actions
+                   only)
+  --output-dir DIR Write one file per secret into DIR
+  --lowercase      Lowercase file names (--output-dir only)
+  --filename-template TEMPLATE
+                   Shape file names, with {key} standing for the name
+                   derived from the key, e.g. "{key}.txt" (--output-dir only)
+  --include-signatures
+                   Print signature metadata as JSON instead of values
+  --progress[=WHEN]
+                   Report decryption progress on stderr: auto (default;
+                   only on a terminal) or always`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if secretExportOutputDir == "" && (secretExportLowercase || secretExportFilenameTemplate != "") {
+			exitWithError(clilib.NewError("--lowercase and --filename-template require --output-dir", clilib.ExitValidationError))
+		}
 
 		vaultPath, fromIndex, err := parseVaultSpec(globalOpts.ConfigPath, globalOpts.VaultPaths)
 		if err != nil {
@@ -363,19 +931,156 @@ or 1-based index).`,
 		}
 		defer func() { _ = cli.Close() }()
 
-		exitErr := cli.SecretForget(secretKey, vaultPath, fromIndex, secretForgetIgnoreNotFound)
+		if secretExportInclSignatures {
+			exitWithError(cli.SecretExportSignatures(vaultPath, fromIndex))
+			return
+		}
+
+		if secretExportOutputDir != "" {
+			exitWithError(cli.SecretExportDir(clilib.DirExportOptions{
+				Dir:              secretExportOutputDir,
+				Lowercase:        secretExportLowercase,
+				FilenameTemplate: secretExportFilenameTemplate,
+			}, vaultPath, fromIndex))
+			return
+		}
+
+		exitErr := cli.SecretExport(clilib.ExportOptions{
+			Format:    secretExportFormat,
+			Name:      secretExportName,
+			EnvPrefix: secretExportEnvPrefix,
+		}, vaultPath, fromIndex)
 		exitWithError(exitErr)
 	},
 }
 
+// secret render flags
+var (
+	secretRenderTemplate string
+	secretRenderDefault  string
+)
+
+var secretRenderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Render a template with secret values",
+	Long: `Render the Go text/template in FILE to stdout, filling in secret values,
+for example to generate nginx or application config files from the vault.
+
+Templates read a secret with the secret function:
+
+  password = {{ secret "DB_PASSWORD" }}
+  api_key  = {{ secret "prod::API_KEY" }}
+
+Without -v, each secret resolves exactly as 'secret get SECRET' does (the
+first vault holding a value you can read wins). With -v, only that vault is
+searched. Only the secrets the template references are decrypted, each
+once. A missing or deleted secret fails the render unless --default is
+given; a secret you cannot read always fails it. Nothing is printed if the
+render fails.
+
+Options:
+  --template FILE  Template to render (required)
+  --default VALUE  Use VALUE for missing secrets instead of failing`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultPath, fromIndex, err := parseVaultSpec(globalOpts.ConfigPath, globalOpts.VaultPaths)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(int(clilib.ExitGeneralError))
+		}
+
+		// Clear VaultPaths for createCLI if we're using an index
+		if fromIndex > 0 {
+			globalOpts.VaultPaths = []string{}
+		}
+
+		cli, cliErr := createCLI()
+		if cliErr != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, cliErr)))
+		}
+		defer func() { _ = cli.Close() }()
+
+		exitWithError(cli.SecretRender(clilib.RenderOptions{
+			Template:   secretRenderTemplate,
+			Default:    secretRenderDefault,
+			UseDefault: cmd.Flags().Changed("default"),
+		}, vaultPath, fromIndex))
+	},
+}
+
 func init() {
+	secretCmd.PersistentFlags().StringVar(&globalOpts.Fingerprint, "fingerprint", "", "Act as this identity for this command instead of the logged-in one")
+
 	// secret store flags
 	secretPutCmd.Flags().BoolVar(&secretPutJSON, "json", false, "Validate stdin is valid JSON before storing")
+	secretPutCmd.Flags().BoolVar(&secretPutIfAbsent, "if-absent", false, "Do nothing if the secret already exists")
+	secretPutCmd.Flags().StringVar(&secretPutFromEnv, "from-env", "", "Read the secret value from the named environment variable")
+	secretPutCmd.Flags().BoolVar(&secretPutAllowEmpty, "allow-empty", false, "With --from-env, allow storing an empty value")
+	secretPutCmd.Flags().BoolVar(&secretPutReplace, "replace", false, "Supersede the secret's history, keeping max_history values")
+	secretPutCmd.Flags().BoolVar(&secretPutAllowLarge, "allow-large", false, "Store values larger than max_secret_size")
+	secretPutCmd.Flags().BoolVar(&secretPutDetach, "detach", false, "Store the encrypted value in a sidecar file instead of inline")
+	secretPutCmd.Flags().BoolVar(&secretPutCompress, "compress", false, "Gzip the value before encrypting it")
+	secretPutCmd.Flags().BoolVar(&secretPutNoDefaults, "no-default-recipients", false, "Do not share the value with the vault's default_recipients")
+	secretPutCmd.Flags().StringVar(&secretPutSource, "source", "", "Tag the value with where it came from (e.g. rotated-by-ci)")
+	secretPutCmd.Flags().BoolVar(&secretPutConfirm, "confirm", false, "Ask twice for a value typed at the terminal (default for new secrets)")
+	secretPutCmd.Flags().BoolVar(&secretPutGenerate, "generate", false, "Store a random value instead of reading stdin")
+	secretPutCmd.Flags().IntVar(&secretPutLength, "length", clilib.DefaultGenerateLength, "Length of the generated value (--generate only)")
+	secretPutCmd.Flags().StringVar(&secretPutCharset, "charset", clilib.GenerateCharsetAlnum, "Characters of the generated value: "+strings.Join(clilib.GenerateCharsets, ", "))
+	secretPutCmd.Flags().BoolVar(&secretPutShow, "show", false, "Print the generated value to stderr once (--generate only)")
+	secretPutCmd.Flags().BoolVar(&secretPutStdinJSON, "stdin-json", false, "Store every member of a JSON object read from stdin")
+	secretPutCmd.MarkFlagsMutuallyExclusive("generate", "from-env")
+	secretPutCmd.MarkFlagsMutuallyExclusive("stdin-json", "generate")
+	secretPutCmd.MarkFlagsMutuallyExclusive("stdin-json", "from-env")
+	secretPutCmd.MarkFlagsMutuallyExclusive("stdin-json", "json")
+	secretPutCmd.MarkFlagsMutuallyExclusive("generate", "json")
+
+	// secret put-file flags
+	secretPutFileCmd.Flags().BoolVar(&secretPutFileAllowLarge, "allow-large", false, "Store files larger than max_secret_size")
+	secretPutFileCmd.Flags().BoolVar(&secretPutFileDetach, "detach", false, "Store the encrypted value in a sidecar file instead of inline")
+	secretPutFileCmd.Flags().BoolVar(&secretPutFileCompress, "compress", false, "Gzip the file before encrypting it")
+	secretPutFileCmd.Flags().BoolVar(&secretPutFileNoDefaults, "no-default-recipients", false, "Do not share the value with the vault's default_recipients")
+	secretPutFileCmd.Flags().StringVar(&secretPutFileSource, "source", "", "Tag the file's value with where it came from")
+	secretPutCmd.MarkFlagsMutuallyExclusive("if-absent", "replace")
 
 	// secret get flags
 	secretGetCmd.Flags().BoolVar(&secretGetAll, "all", false, "Retrieve all values")
 	secretGetCmd.Flags().BoolVar(&secretGetLast, "last", false, "Retrieve most recent value across all vaults")
-	secretGetCmd.Flags().BoolVar(&secretGetJSON, "json", false, "Output as JSON")
+	addJSONFlag(secretGetCmd, &secretGetJSON, "Output as JSON")
+	secretGetCmd.Flags().IntVar(&secretGetConcurrency, "concurrency", 1, "With --all, number of values to decrypt concurrently")
+	secretGetCmd.Flags().BoolVar(&secretGetLatest, "require-latest", false, "Fail instead of falling back to an older value")
+	secretGetCmd.Flags().BoolVar(&secretGetRelative, "relative", false, "With --all, show relative times instead of RFC3339")
+	secretGetCmd.Flags().BoolVar(&secretGetReverse, "reverse", false, "With --all, list values oldest first")
+	secretGetCmd.Flags().BoolVar(&secretGetFailFast, "fail-fast", false, "With --all, stop at the first value that fails to decrypt")
+	secretGetCmd.Flags().BoolVar(&secretGetDedupe, "dedupe", false, "With --all, collapse runs of identical consecutive values")
+	secretGetCmd.Flags().BoolVar(&secretGetExplain, "explain", false, "When access is denied, explain why on stderr")
+	secretGetCmd.Flags().IntVar(&secretGetDepth, "depth", 0, "Search only the first N vaults in search order (0 = all)")
+	secretGetCmd.Flags().BoolVar(&secretGetBase64, "base64", false, "Print the decrypted value base64-encoded")
+	secretGetCmd.Flags().BoolVar(&secretGetHex, "hex", false, "Print the decrypted value hex-encoded")
+	secretGetCmd.MarkFlagsMutuallyExclusive("all", "require-latest")
+	secretGetCmd.Flags().BoolVar(&secretGetInclDeleted, "include-deleted", false, "Read the last value of a deleted secret")
+	secretGetCmd.Flags().StringVar(&secretGetFilter, "filter", "", "Without SECRET, list only keys matching this glob")
+	secretGetCmd.MarkFlagsMutuallyExclusive("base64", "hex")
+	secretGetCmd.MarkFlagsMutuallyExclusive("include-deleted", "all")
+	secretGetCmd.MarkFlagsMutuallyExclusive("include-deleted", "last")
+
+	// secret export flags
+	secretExportCmd.Flags().StringVar(&secretExportFormat, "format", "", "Output format: "+strings.Join(clilib.ExportFormats, ", "))
+	secretExportCmd.Flags().StringVar(&secretExportName, "name", "", "Kubernetes Secret name (k8s-secret only)")
+	secretExportCmd.Flags().StringVar(&secretExportEnvPrefix, "env-prefix", "", "Prefix of the environment variable names (github-actions only)")
+	addProgressFlag(secretExportCmd)
+	secretExportCmd.Flags().StringVar(&secretExportOutputDir, "output-dir", "", "Write each secret to its own file in this directory")
+	secretExportCmd.Flags().BoolVar(&secretExportLowercase, "lowercase", false, "Lowercase file names (--output-dir only)")
+	secretExportCmd.Flags().StringVar(&secretExportFilenameTemplate, "filename-template", "", "File name template with {key} for the derived name (--output-dir only)")
+	secretExportCmd.MarkFlagsMutuallyExclusive("format", "output-dir")
+	secretExportCmd.MarkFlagsMutuallyExclusive("name", "output-dir")
+	secretExportCmd.Flags().BoolVar(&secretExportInclSignatures, "include-signatures", false, "Print each value's hash, signature and signer key as JSON, without decrypting")
+	secretExportCmd.MarkFlagsMutuallyExclusive("include-signatures", "format", "output-dir", "name")
+	secretExportCmd.MarkFlagsMutuallyExclusive("env-prefix", "output-dir")
+
+	// secret render flags
+	secretRenderCmd.Flags().StringVar(&secretRenderTemplate, "template", "", "Go text/template file to render")
+	secretRenderCmd.Flags().StringVar(&secretRenderDefault, "default", "", "Value used for missing secrets instead of failing")
+	_ = secretRenderCmd.MarkFlagRequired("template")
 
 	// secret share flags
 	secretShareCmd.Flags().BoolVar(&secretShareAll, "all", false, "Share secret in all vaults where it exists")
@@ -385,10 +1090,18 @@ func init() {
 
 	// secret forget flags
 	secretForgetCmd.Flags().BoolVar(&secretForgetIgnoreNotFound, "ignore-not-found", false, "Exit successfully if secret is not found or already deleted")
+	secretForgetCmd.Flags().StringVar(&secretForgetPrefix, "prefix", "", "Mark every secret whose key starts with this prefix as deleted")
+	secretForgetCmd.Flags().BoolVar(&secretForgetYes, "yes", false, "Skip the confirmation prompt for --prefix")
+	secretForgetCmd.MarkFlagsMutuallyExclusive("prefix", "ignore-not-found")
 
 	secretCmd.AddCommand(secretPutCmd)
 	secretCmd.AddCommand(secretGetCmd)
+	secretCmd.AddCommand(secretPutFileCmd)
+	secretCmd.AddCommand(secretGetFileCmd)
+	secretCmd.AddCommand(secretExportCmd)
+	secretCmd.AddCommand(secretRenderCmd)
 	secretCmd.AddCommand(secretShareCmd)
 	secretCmd.AddCommand(secretRevokeCmd)
 	secretCmd.AddCommand(secretForgetCmd)
+	secretCmd.AddCommand(secretTouchCmd)
 }
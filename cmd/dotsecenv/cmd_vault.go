@@ -1,7 +1,10 @@
 package main
 
 import (
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	clilib "github.com/dotsecenv/dotsecenv/internal/cli"
 	"github.com/spf13/cobra"
@@ -10,28 +13,143 @@ import (
 var vaultCmd = &cobra.Command{
 	Use:   "vault",
 	Short: "Manage vaults",
-	Long:  `Commands for managing vaults: describe, doctor, compact.`,
+	Long:  `Commands for managing vaults: describe, doctor, clone, compact, export-public, reindex, rekey, upgrade, verify, verify-bundle.`,
 }
 
 // vault describe flags
-var vaultDescribeJSON bool
+var (
+	vaultDescribeJSON        bool
+	vaultDescribeCheckAccess string
+	vaultDescribeDiffConfig  bool
+	vaultDescribeSort        string
+	vaultDescribeFilter      string
+	vaultDescribeFilterID    string
+	vaultDescribeSince       time.Duration
+	vaultDescribeByIdentity  bool
+	vaultDescribeDecrypt     bool
+	vaultDescribeIDsOnly     bool
+	vaultDescribeSecretsOnly bool
+	vaultDescribeUsage       bool
+)
 
 var vaultDescribeCmd = &cobra.Command{
 	Use:   "describe",
 	Short: "Describe configured vaults with identities and secrets",
 	Long: `Describe all configured vaults showing their identities and secrets.
 
+With --check-access, list instead every secret the given fingerprint can
+read, grouped by vault. A secret counts as readable when its latest value
+is shared with the fingerprint; deleted secrets are never listed. Only
+access lists are inspected, nothing is decrypted.
+
+With --diff-config, report how the configured vaults match the files on
+disk instead: each configured vault is shown as loaded, missing or failing
+to load, and vault files next to the config file or next to a configured
+vault that the config does not reference are listed as unreferenced.
+
+With --by-identity, list each identity once instead, with the vaults it
+is a member of and whether its signature verifies in all of them. This
+answers "which vaults is Alice in?" in one query; --filter-identity
+applies:
+
+  dotsecenv vault describe --by-identity --filter-identity 'Alice*' --json
+
+With --usage, list each vault's identities with the number of secrets
+each can currently read, counting the latest value of every secret that
+is not deleted. An identity at "no secrets" was added but never granted
+anything. --filter narrows the secrets counted and --filter-identity the
+identities listed.
+
+Identities are listed by UID and secrets by key. Use --sort added to list
+the most recently added first, or --sort fingerprint to list identities by
+fingerprint.
+
+Use --filter to list only secrets whose key matches a shell glob, and
+--filter-identity to list only identities whose UID matches one. Globs
+follow path.Match: * and ? match any characters, [abc] a set. Vaults are
+still all listed; with --json this narrows output for scripting:
+
+  dotsecenv vault describe --filter 'DB_*' --json
+  dotsecenv vault describe --filter '*_PROD' --filter-identity '*@example.com*'
+
+Use --since to list only what changed recently: secrets whose latest value
+was added, and identities added, within the given duration of now. Only
+timestamps are compared, nothing is decrypted:
+
+  dotsecenv vault describe --since 24h
+
+Use --decrypt-check to confirm you can still decrypt what you are listed
+on: the latest value of each secret shared with you is decrypted through
+gpg-agent and reported as ok or FAILED, which catches a key that the
+access lists name but that is no longer available. Values are never
+printed. The command fails if any secret cannot be decrypted; with --json,
+those secrets carry "decryptable": true or false.
+
+Use --identities-only or --secrets-only to show just one section of each
+vault. With --json, the other section's key is left out rather than empty:
+
+  dotsecenv vault describe --secrets-only --json
+
 Options:
-  --json  Output as JSON`,
+  --json                      Output as JSON
+  --sort ORDER                Order of identities and secrets: key (default),
+                              added or fingerprint
+  --filter GLOB               List only secrets whose key matches GLOB
+  --filter-identity GLOB      List only identities whose UID matches GLOB
+  --since DURATION            List only secrets and identities changed within
+                              DURATION (e.g. 24h, 168h)
+  --decrypt-check             Check that secrets shared with you decrypt
+  --identities-only           Show only the identities of each vault
+  --secrets-only              Show only the secrets of each vault
+  --by-identity               List each identity with the vaults holding it
+  --usage                     Count the secrets each identity can read
+  --check-access FINGERPRINT  List secrets readable by FINGERPRINT
+  --diff-config               Compare configured vaults with vault files on disk`,
 	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
+		if vaultDescribeDiffConfig && cmd.Flags().Changed("check-access") {
+			fmt.Fprintf(os.Stderr, "error: --diff-config cannot be combined with --check-access\n")
+			os.Exit(int(clilib.ExitGeneralError))
+		}
+
 		cli, err := createCLI()
 		if err != nil {
 			os.Exit(int(clilib.PrintError(os.Stderr, err)))
 		}
 		defer func() { _ = cli.Close() }()
 
-		exitErr := cli.VaultDescribe(vaultDescribeJSON)
+		if vaultDescribeDiffConfig {
+			exitWithError(cli.VaultDiffConfig(vaultDescribeJSON))
+			return
+		}
+
+		if cmd.Flags().Changed("check-access") {
+			exitWithError(cli.VaultCheckAccess(vaultDescribeCheckAccess, vaultDescribeJSON))
+			return
+		}
+
+		if vaultDescribeSince < 0 {
+			exitWithError(clilib.NewError("--since must not be negative", clilib.ExitValidationError))
+		}
+
+		cli.SetFilter(vaultDescribeFilter, vaultDescribeFilterID)
+		cli.SetSince(vaultDescribeSince)
+		cli.SetDecryptCheck(vaultDescribeDecrypt)
+		switch {
+		case vaultDescribeIDsOnly:
+			cli.SetDescribeOnly(clilib.DescribeIdentities)
+		case vaultDescribeSecretsOnly:
+			cli.SetDescribeOnly(clilib.DescribeSecrets)
+		}
+		if vaultDescribeByIdentity {
+			exitWithError(cli.VaultDescribeByIdentity(vaultDescribeJSON))
+			return
+		}
+		if vaultDescribeUsage {
+			exitWithError(cli.VaultDescribeUsage(vaultDescribeJSON))
+			return
+		}
+		exitErr := cli.VaultDescribe(vaultDescribeJSON, vaultDescribeSort)
 		exitWithError(exitErr)
 	},
 }
@@ -39,6 +157,10 @@ Options:
 // vault doctor flags
 var vaultDoctorJSON bool
 var vaultDoctorFix bool
+var vaultDoctorSelect string
+
+// doctorSelectActive is the --select value used when no fingerprint is given.
+const doctorSelectActive = "active"
 
 var vaultDoctorCmd = &cobra.Command{
 	Use:   "doctor",
@@ -48,7 +170,21 @@ var vaultDoctorCmd = &cobra.Command{
 Checks performed:
   - GPG agent availability
   - Vault format version (upgrades outdated vaults)
+  - Vault and config file permissions, warning when group or other
+    can access them (not on Windows)
+  - With min_recipients set, secrets whose current value is readable by
+    fewer identities
+  - With a keyserver set in GnuPG's dirmngr.conf or gpg.conf, that it
+    answers an HTTP request (proxies are taken from the environment)
   - Vault fragmentation (defragments if needed)
+  - With --select, that a key can sign and decrypt (can_sign, can_decrypt)
+
+With --select, a random challenge is signed and verified, then encrypted
+to the key and decrypted, through gpg-agent. This catches a running agent
+that cannot reach the key, such as a smartcard that is not inserted. You
+may be asked for the key's passphrase or PIN. No vault is modified.
+Without a value, --select checks the identity you are logged in as; give a
+fingerprint as --select=FINGERPRINT.
 
 In CI environments (CI=true, GITHUB_ACTIONS, GITLAB_CI, etc.),
 interactive prompts are automatically skipped to avoid blocking
@@ -58,7 +194,9 @@ Use -v to target a specific vault for checks.
 
 Options:
   --json  Output as JSON
-  --fix   Auto-fix issues without prompting`,
+  --fix   Auto-fix issues without prompting
+  --select[=FINGERPRINT]
+          Check that the key can sign and decrypt`,
 	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		vaultPath, fromIndex, parseErr := parseVaultSpecScoped()
@@ -72,11 +210,86 @@ Options:
 		}
 		defer func() { _ = cli.Close() }()
 
+		if cmd.Flags().Changed("select") {
+			fingerprint := vaultDoctorSelect
+			if fingerprint == doctorSelectActive {
+				fingerprint = ""
+			}
+			cli.SetDoctorKeyCheck(fingerprint)
+		}
+
 		exitErr := cli.VaultDoctor(vaultDoctorJSON, vaultDoctorFix, vaultPath, fromIndex)
 		exitWithError(exitErr)
 	},
 }
 
+// vault clone flags
+var vaultCloneName string
+
+var vaultCloneCmd = &cobra.Command{
+	Use:   "clone SOURCE DEST",
+	Short: "Create a new vault with another vault's identities",
+	Long: `Create the vault file DEST holding the same identities as the vault
+SOURCE, and no secrets, to set up a new environment for the same team in
+one step.
+
+Every identity's signature is verified in SOURCE first; if one fails,
+nothing is created. Identities are copied with their original signatures,
+which verify in DEST as they do in SOURCE, so no GPG key is needed. DEST
+must not exist yet. Add it to your config's vault list to use it. The
+post_write hook, if configured, runs for DEST once it is written.
+
+Options:
+  --name NAME  Human-readable vault name stored in the new vault's header`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := clilib.ValidateVaultPathsAgainstConfig(globalOpts.ConfigPath, args[1:], defaultOutput()); err != nil {
+			exitWithError(err)
+		}
+
+		// Neither vault is opened through the resolver: DEST does not exist yet.
+		cli, err := clilib.NewCLIConfigOnly(globalOpts.ConfigPath, globalOpts.Silent, os.Stdin, os.Stdout, os.Stderr)
+		if err != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, err)))
+		}
+		exitWithError(cli.VaultClone(args[0], args[1], vaultCloneName))
+	},
+}
+
+var vaultReindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Rebuild a vault's header and defragment it",
+	Long: `Rebuild a vault's header from its data lines and rewrite the vault
+defragmented, in one pass.
+
+The header indexes where each identity, secret and value is in the file. If
+it drifts from the data, for example after a bad merge or a manual edit, the
+vault can no longer be read. reindex ignores the header, reads every data
+line, and writes the vault back in the order 'vault doctor --fix' uses, with
+a header that matches. Older records of refreshed identities are dropped.
+
+The result is read back and validated, signatures included, before it
+replaces the vault file; if validation fails, the vault is left unchanged.
+Header drift found and what changed are reported.
+
+Use -v to target a specific vault.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultPath, fromIndex, parseErr := parseVaultSpecScoped()
+		if parseErr != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, clilib.NewError(parseErr.Error(), clilib.ExitGeneralError))))
+		}
+
+		cli, err := createCLI()
+		if err != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, err)))
+		}
+		defer func() { _ = cli.Close() }()
+
+		exitWithError(cli.VaultReindex(vaultPath, fromIndex))
+	},
+}
+
 // vault compact flags
 var vaultCompactJSON bool
 var vaultCompactYes bool
@@ -119,20 +332,299 @@ Options:
 	},
 }
 
+// vault rekey flags
+var (
+	vaultRekeyAdd    []string
+	vaultRekeyRemove []string
+	vaultRekeyDryRun bool
+)
+
+var vaultRekeyCmd = &cobra.Command{
+	Use:   "rekey",
+	Short: "Re-encrypt all secrets in a vault to a new recipient set",
+	Long: `Re-encrypt every secret in a vault, granting access to the --add
+fingerprints and removing it from the --remove fingerprints.
+
+Each secret you can decrypt gets a new value encrypted to its adjusted
+recipient set and signed by you. Secrets you cannot decrypt are skipped and
+reported; a current reader must rotate those manually. Secrets already shared
+exactly as requested are left unchanged. Added identities are imported into
+the vault if missing.
+
+Every new value is prepared before anything is written, and the vault is
+saved once at the end.
+
+Use -v to target a specific vault.
+
+Options:
+  --add FINGERPRINT     Grant access to FINGERPRINT (repeatable)
+  --remove FINGERPRINT  Remove access from FINGERPRINT (repeatable)
+  --dry-run             Print the plan without decrypting or writing
+  --progress[=WHEN]     Report progress on stderr: auto (default; only on
+                        a terminal) or always`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultPath, fromIndex, parseErr := parseVaultSpecScoped()
+		if parseErr != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, clilib.NewError(parseErr.Error(), clilib.ExitGeneralError))))
+		}
+
+		cli, err := createCLI()
+		if err != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, err)))
+		}
+		defer func() { _ = cli.Close() }()
+
+		exitErr := cli.VaultRekey(vaultRekeyAdd, vaultRekeyRemove, vaultRekeyDryRun, vaultPath, fromIndex)
+		exitWithError(exitErr)
+	},
+}
+
+// vault upgrade flags
+var vaultUpgradeDryRun bool
+
+var vaultUpgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrade vaults to the latest format version",
+	Long: `Upgrade vault files to the latest format version.
+
+Vaults are normally upgraded automatically when opened. With
+require_explicit_vault_upgrade set in config, they are only warned about
+and this command performs the upgrade.
+
+Use -v to target a specific vault; otherwise every configured vault is
+checked and vaults that do not exist are skipped.
+
+Options:
+  --dry-run  Report which vaults would be upgraded without writing`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultPath, fromIndex, parseErr := parseVaultSpecScoped()
+		if parseErr != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, clilib.NewError(parseErr.Error(), clilib.ExitGeneralError))))
+		}
+
+		// Vaults are not opened through the resolver: opening them may
+		// upgrade them automatically, which would defeat --dry-run.
+		cli, err := clilib.NewCLIConfigOnly(globalOpts.ConfigPath, globalOpts.Silent, os.Stdin, os.Stdout, os.Stderr)
+		if err != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, err)))
+		}
+
+		exitErr := cli.VaultUpgrade(vaultUpgradeDryRun, vaultPath, fromIndex)
+		exitWithError(exitErr)
+	},
+}
+
+// vault verify flags
+var vaultVerifySecret string
+var vaultVerifyIdentity string
+var vaultVerifyDetailed bool
+var vaultVerifyAgainstKeyring bool
+var vaultVerifyStructureOnly bool
+var vaultVerifyFailOn []string
+
+var vaultVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify vault hashes and signatures",
+	Long: `Verify the hash and signature of every identity, secret and secret value
+in the vaults, without decrypting anything.
+
+Each entry's hash is recomputed from its canonical data and compared with
+the stored hash, and the stored hash's signature is checked against the
+signer's public key in the vault.
+
+With --detailed, each entry is followed by the exact canonical data string
+that was hashed and signed, the computed hash and the stored hash. This
+shows which field changed when verification fails. Canonical data holds
+encrypted values only; plaintext is never printed.
+
+Signatures are checked with the public keys stored in the vault itself.
+With --against-keyring, each stored public key is also compared with the
+key your local GPG keyring holds for the same fingerprint, to detect a
+stored key replaced by someone else's. A mismatch is a high-severity
+failure; keys not in your keyring are reported and skipped. With --secret,
+the keys of the secret's signers are compared.
+
+With --structure-only, nothing is hashed or signature-checked: the header's
+line references are cross-checked against the data lines, and the command
+fails when they have drifted apart (a reference to a missing line, a line
+of the wrong entry type, or a value filed under the wrong secret). It is
+fast enough to run as a pre-commit hook or CI gate; 'vault reindex'
+rebuilds a drifted header.
+
+Failures have the levels 'validate' reports: GLOBAL for a vault that fails
+to load, STRUCTURE for header drift, IDENTITY for identities, revocations
+and keyring mismatches, and SECRET for secrets and their values. With
+--fail-on, every failure is still reported but only those at the given
+levels make the command exit non-zero.
+
+Use -v to verify a single vault.
+
+Options:
+  --secret KEY       Verify only this secret and its values
+  --identity FP      Verify only this identity
+  --detailed         Show canonical data and hashes for each entry
+  --against-keyring  Compare stored public keys with the local keyring
+  --structure-only   Only check that the header matches the data lines
+  --fail-on LEVEL    Fail only on failures at these levels: STRUCTURE,
+                     IDENTITY, SECRET, GLOBAL (comma-separated or
+                     repeated), or any (default) or none
+  --progress[=WHEN]  Report progress on stderr: auto (default; only on a
+                     terminal) or always`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		_, fromIndex, parseErr := parseVaultSpecScoped()
+		if parseErr != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, clilib.NewError(parseErr.Error(), clilib.ExitGeneralError))))
+		}
+
+		cli, err := createCLI()
+		if err != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, err)))
+		}
+		defer func() { _ = cli.Close() }()
+		if failOnErr := cli.SetValidateFailOn(vaultVerifyFailOn); failOnErr != nil {
+			exitWithError(failOnErr)
+		}
+
+		if vaultVerifyStructureOnly {
+			exitWithError(cli.VaultVerifyStructure(fromIndex))
+			return
+		}
+
+		cli.SetAgainstKeyring(vaultVerifyAgainstKeyring)
+		exitErr := cli.VaultVerify(fromIndex, vaultVerifySecret, vaultVerifyIdentity, vaultVerifyDetailed)
+		exitWithError(exitErr)
+	},
+}
+
+var vaultVerifyBundleCmd = &cobra.Command{
+	Use:   "verify-bundle FILE",
+	Short: "Verify a signature bundle offline",
+	Long: `Verify a signature bundle written by 'secret export --include-signatures',
+without access to the vault.
+
+For each secret value in the bundle, the signer public key is checked to
+have the signer's fingerprint, and the signature is checked against the
+value's hash. The bundle holds no ciphertext, so hashes are not recomputed;
+compare them with 'vault verify --detailed' where the vault is available.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cli, err := clilib.NewCLIConfigOnly(globalOpts.ConfigPath, globalOpts.Silent, os.Stdin, os.Stdout, os.Stderr)
+		if err != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, err)))
+		}
+		defer func() { _ = cli.Close() }()
+
+		exitWithError(cli.VerifyBundle(args[0]))
+	},
+}
+
+var vaultExportPublicCmd = &cobra.Command{
+	Use:   "export-public",
+	Short: "Print the vault identities' public keys for gpg --import",
+	Long: `Print the public keys of the identities in the vaults as one ASCII-armored
+keyring, so teammates can import each other's keys without a keyserver:
+
+  dotsecenv vault export-public > team.asc
+  gpg --import team.asc
+
+The keys are the ones stored in the vault; no secret is read or decrypted.
+An identity in several vaults is printed once.
+
+Use -v to export the identities of a single vault.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		vaultPath, fromIndex, parseErr := parseVaultSpecScoped()
+		if parseErr != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, clilib.NewError(parseErr.Error(), clilib.ExitGeneralError))))
+		}
+
+		cli, err := createCLI()
+		if err != nil {
+			os.Exit(int(clilib.PrintError(os.Stderr, err)))
+		}
+		defer func() { _ = cli.Close() }()
+
+		exitWithError(cli.VaultExportPublic(vaultPath, fromIndex))
+	},
+}
+
 func init() {
 	// vault describe flags
-	vaultDescribeCmd.Flags().BoolVar(&vaultDescribeJSON, "json", false, "Output as JSON")
+	addJSONFlag(vaultDescribeCmd, &vaultDescribeJSON, "Output as JSON")
+	vaultDescribeCmd.Flags().StringVar(&vaultDescribeCheckAccess, "check-access", "", "List secrets readable by this fingerprint")
+	vaultDescribeCmd.Flags().StringVar(&vaultDescribeSort, "sort", clilib.DescribeSortKey, "Order of identities and secrets: "+strings.Join(clilib.DescribeSortOrders, ", "))
+	vaultDescribeCmd.Flags().BoolVar(&vaultDescribeDiffConfig, "diff-config", false, "Compare configured vaults with vault files on disk")
+	vaultDescribeCmd.Flags().StringVar(&vaultDescribeFilter, "filter", "", "List only secrets whose key matches this glob")
+	vaultDescribeCmd.Flags().StringVar(&vaultDescribeFilterID, "filter-identity", "", "List only identities whose UID matches this glob")
+	vaultDescribeCmd.Flags().DurationVar(&vaultDescribeSince, "since", 0, "List only secrets and identities changed within this duration")
+	vaultDescribeCmd.Flags().BoolVar(&vaultDescribeByIdentity, "by-identity", false, "List each identity with the vaults it is a member of")
+	vaultDescribeCmd.Flags().BoolVar(&vaultDescribeDecrypt, "decrypt-check", false, "Check that the secrets shared with you can be decrypted")
+	vaultDescribeCmd.Flags().BoolVar(&vaultDescribeIDsOnly, "identities-only", false, "Show only the identities of each vault")
+	vaultDescribeCmd.Flags().BoolVar(&vaultDescribeSecretsOnly, "secrets-only", false, "Show only the secrets of each vault")
+	vaultDescribeCmd.Flags().BoolVar(&vaultDescribeUsage, "usage", false, "List each identity with the number of secrets it can read")
+	vaultDescribeCmd.MarkFlagsMutuallyExclusive("identities-only", "secrets-only")
+	vaultDescribeCmd.MarkFlagsMutuallyExclusive("identities-only", "decrypt-check")
+	vaultDescribeCmd.MarkFlagsMutuallyExclusive("by-identity", "check-access")
+	vaultDescribeCmd.MarkFlagsMutuallyExclusive("by-identity", "diff-config")
+	vaultDescribeCmd.MarkFlagsMutuallyExclusive("by-identity", "filter")
+	vaultDescribeCmd.MarkFlagsMutuallyExclusive("decrypt-check", "by-identity")
+	vaultDescribeCmd.MarkFlagsMutuallyExclusive("decrypt-check", "check-access")
+	vaultDescribeCmd.MarkFlagsMutuallyExclusive("decrypt-check", "diff-config")
+	vaultDescribeCmd.MarkFlagsMutuallyExclusive("usage", "by-identity")
+	vaultDescribeCmd.MarkFlagsMutuallyExclusive("usage", "check-access")
+	vaultDescribeCmd.MarkFlagsMutuallyExclusive("usage", "diff-config")
+	vaultDescribeCmd.MarkFlagsMutuallyExclusive("usage", "decrypt-check")
+	vaultDescribeCmd.MarkFlagsMutuallyExclusive("usage", "identities-only")
+	vaultDescribeCmd.MarkFlagsMutuallyExclusive("usage", "secrets-only")
 
 	// vault doctor flags
-	vaultDoctorCmd.Flags().BoolVar(&vaultDoctorJSON, "json", false, "Output as JSON")
+	addJSONFlag(vaultDoctorCmd, &vaultDoctorJSON, "Output as JSON")
 	vaultDoctorCmd.Flags().BoolVar(&vaultDoctorFix, "fix", false, "Auto-fix issues without prompting")
+	vaultDoctorCmd.Flags().StringVar(&vaultDoctorSelect, "select", "", "Check that this key (default: the logged-in one) can sign and decrypt")
+	vaultDoctorCmd.Flags().Lookup("select").NoOptDefVal = doctorSelectActive
 
 	// vault compact flags
-	vaultCompactCmd.Flags().BoolVar(&vaultCompactJSON, "json", false, "Output as JSON")
+	addJSONFlag(vaultCompactCmd, &vaultCompactJSON, "Output as JSON")
 	vaultCompactCmd.Flags().BoolVar(&vaultCompactYes, "yes", false, "Skip the confirmation prompt")
 
+	// vault rekey flags
+	vaultRekeyCmd.Flags().StringArrayVar(&vaultRekeyAdd, "add", nil, "Grant access to this fingerprint (repeatable)")
+	vaultRekeyCmd.Flags().StringArrayVar(&vaultRekeyRemove, "remove", nil, "Remove access from this fingerprint (repeatable)")
+	vaultRekeyCmd.Flags().BoolVar(&vaultRekeyDryRun, "dry-run", false, "Print the plan without decrypting or writing")
+	addProgressFlag(vaultRekeyCmd)
+
+	// vault upgrade flags
+	vaultUpgradeCmd.Flags().BoolVar(&vaultUpgradeDryRun, "dry-run", false, "Report planned upgrades without writing")
+
+	// vault clone flags
+	vaultCloneCmd.Flags().StringVar(&vaultCloneName, "name", "", "Human-readable vault name stored in the new vault's header")
+
+	// vault verify flags
+	vaultVerifyCmd.Flags().StringVar(&vaultVerifySecret, "secret", "", "Verify only this secret and its values")
+	vaultVerifyCmd.Flags().StringVar(&vaultVerifyIdentity, "identity", "", "Verify only this identity")
+	vaultVerifyCmd.Flags().BoolVar(&vaultVerifyDetailed, "detailed", false, "Show canonical data and hashes for each entry")
+	vaultVerifyCmd.Flags().BoolVar(&vaultVerifyAgainstKeyring, "against-keyring", false, "Compare stored public keys with the local GPG keyring")
+	vaultVerifyCmd.Flags().BoolVar(&vaultVerifyStructureOnly, "structure-only", false, "Only cross-check the header against the data lines, without crypto")
+	vaultVerifyCmd.Flags().StringSliceVar(&vaultVerifyFailOn, "fail-on", []string{"any"}, "Failure levels that fail verification: STRUCTURE, IDENTITY, SECRET, GLOBAL, any or none")
+	vaultVerifyCmd.MarkFlagsMutuallyExclusive("secret", "identity")
+	vaultVerifyCmd.MarkFlagsMutuallyExclusive("structure-only", "secret")
+	vaultVerifyCmd.MarkFlagsMutuallyExclusive("structure-only", "identity")
+	vaultVerifyCmd.MarkFlagsMutuallyExclusive("structure-only", "detailed")
+	vaultVerifyCmd.MarkFlagsMutuallyExclusive("structure-only", "against-keyring")
+	addProgressFlag(vaultVerifyCmd)
+
 	// Build command tree
 	vaultCmd.AddCommand(vaultDescribeCmd)
 	vaultCmd.AddCommand(vaultDoctorCmd)
+	vaultCmd.AddCommand(vaultCloneCmd)
 	vaultCmd.AddCommand(vaultCompactCmd)
+	vaultCmd.AddCommand(vaultExportPublicCmd)
+	vaultCmd.AddCommand(vaultReindexCmd)
+	vaultCmd.AddCommand(vaultRekeyCmd)
+	vaultCmd.AddCommand(vaultUpgradeCmd)
+	vaultCmd.AddCommand(vaultVerifyCmd)
+	vaultCmd.AddCommand(vaultVerifyBundleCmd)
 }
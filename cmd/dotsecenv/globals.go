@@ -8,16 +8,27 @@ import (
 	"strings"
 
 	clilib "github.com/dotsecenv/dotsecenv/internal/cli"
+	"github.com/dotsecenv/dotsecenv/internal/progress"
 	"github.com/dotsecenv/dotsecenv/internal/xdg"
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+	"github.com/spf13/cobra"
 )
 
 // GlobalOptions holds the global configuration flags
 type GlobalOptions struct {
-	ConfigPath string
-	VaultPaths []string
-	Silent     bool
+	ConfigPath   string
+	VaultPaths   []string
+	Silent       bool
+	RedactStdout bool
+	NoColor      bool
+	Canonical    bool
+	Format       string
+	Wait         bool
+	NoWait       bool
+	Fingerprint  string
+	Progress     string
 }
 
 // globalOpts is the shared global options instance
@@ -42,7 +53,7 @@ func resolveVaultPaths(configPath string, vaultPaths []string) ([]string, error)
 				return nil, fmt.Errorf("failed to load config for vault index %d: %v", idx, configErr)
 			}
 
-			vaultCfg, parseErr := vault.ParseVaultConfig(cfg.Vault)
+			vaultCfg, parseErr := vault.ParseNamedVaultConfig(cfg.Vault, cfg.VaultNameList(), cfg.SearchOrder)
 			if parseErr != nil {
 				return nil, fmt.Errorf("failed to parse vault config: %v", parseErr)
 			}
@@ -60,20 +71,93 @@ func resolveVaultPaths(configPath string, vaultPaths []string) ([]string, error)
 			}
 
 			resolvedPaths[i] = vaultCfg.Entries[idx-1].Path
+		} else if _, path := lookupVaultName(configPath, vPath); path != "" {
+			resolvedPaths[i] = path
 		}
 	}
 
 	return resolvedPaths, nil
 }
 
+// addProgressFlag adds --progress to a long-running command. The flag is off
+// by default; given without a value it means auto.
+func addProgressFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&globalOpts.Progress, "progress", string(progress.ModeNever), "Report progress on stderr: "+strings.Join(progress.Modes, ", "))
+	cmd.Flags().Lookup("progress").NoOptDefVal = string(progress.ModeAuto)
+}
+
+// formatCommands are the commands whose --json output the global --output
+// flag can re-encode. See addJSONFlag.
+var formatCommands = map[*cobra.Command]bool{}
+
+// addJSONFlag adds --json to a command whose structured output goes through
+// the output handler, and lets --output json, yaml or porcelain select it.
+func addJSONFlag(cmd *cobra.Command, p *bool, usage string) {
+	cmd.Flags().BoolVar(p, "json", false, usage)
+	formatCommands[cmd] = true
+}
+
+// applyFormat resolves the global --output flag for cmd before it runs: a
+// structured format turns on the command's --json output, which the CLI
+// then encodes in that format.
+func applyFormat(cmd *cobra.Command) error {
+	format, err := output.ParseFormat(globalOpts.Format)
+	if err != nil {
+		return err
+	}
+	if !format.Structured() {
+		return nil
+	}
+	if !formatCommands[cmd] {
+		return fmt.Errorf("'%s' does not support --output %s", cmd.CommandPath(), format)
+	}
+	if cmd.Flags().Changed("json") && format != output.FormatJSON {
+		return fmt.Errorf("--json cannot be combined with --output %s", format)
+	}
+	return cmd.Flags().Set("json", "true")
+}
+
+// outputFormat returns the --output value applyFormat validated.
+func outputFormat() output.Format {
+	format, _ := output.ParseFormat(globalOpts.Format)
+	return format
+}
+
 // createCLI creates a CLI instance with resolved vault paths
 func createCLI() (*clilib.CLI, error) {
 	resolvedPaths, err := resolveVaultPaths(globalOpts.ConfigPath, globalOpts.VaultPaths)
 	if err != nil {
 		return nil, err
 	}
+	progressMode, err := progress.ParseMode(globalOpts.Progress)
+	if err != nil {
+		return nil, clilib.NewError(err.Error(), clilib.ExitValidationError)
+	}
 
-	return clilib.NewCLI(resolvedPaths, globalOpts.ConfigPath, globalOpts.Silent, os.Stdin, os.Stdout, os.Stderr)
+	lockWait := clilib.LockWaitConfigured
+	switch {
+	case globalOpts.Wait:
+		lockWait = clilib.LockWaitBlock
+	case globalOpts.NoWait:
+		lockWait = clilib.LockWaitFail
+	}
+
+	cli, err := clilib.NewCLIWithLockWait(resolvedPaths, globalOpts.ConfigPath, globalOpts.Silent, os.Stdin, os.Stdout, os.Stderr, lockWait)
+	if err != nil {
+		return nil, err
+	}
+	cli.SetRedactStdout(globalOpts.RedactStdout)
+	cli.SetNoColor(globalOpts.NoColor)
+	cli.SetCanonical(globalOpts.Canonical)
+	cli.SetOutputFormat(outputFormat())
+	cli.SetProgress(progressMode)
+	if globalOpts.Fingerprint != "" {
+		if fpErr := cli.SetFingerprint(globalOpts.Fingerprint); fpErr != nil {
+			_ = cli.Close()
+			return nil, fpErr
+		}
+	}
+	return cli, nil
 }
 
 // parseVaultSpec parses a vault specification (-v value) and returns the vault path and index
@@ -105,7 +189,7 @@ func parseVaultSpec(configPath string, vaultPaths []string) (vaultPath string, f
 			return "", 0, fmt.Errorf("failed to load config: %v", configErr)
 		}
 
-		vaultCfg, parseErr := vault.ParseVaultConfig(cfg.Vault)
+		vaultCfg, parseErr := vault.ParseNamedVaultConfig(cfg.Vault, cfg.VaultNameList(), cfg.SearchOrder)
 		if parseErr != nil {
 			return "", 0, fmt.Errorf("failed to parse vault config: %v", parseErr)
 		}
@@ -118,10 +202,45 @@ func parseVaultSpec(configPath string, vaultPaths []string) (vaultPath string, f
 		return "", idx, nil
 	}
 
+	// A vault name from config resolves to that vault's index
+	if idx, _ := lookupVaultName(configPath, vaultSpec); idx > 0 {
+		return "", idx, nil
+	}
+
 	// It's a path
 	return vaultSpec, 0, nil
 }
 
+// lookupVaultName returns the 1-based index and path of the configured vault
+// named spec, or 0 and "" if spec is not a vault name. Specs that look like
+// paths are never treated as names.
+func lookupVaultName(configPath, spec string) (int, string) {
+	if spec == "" || strings.ContainsAny(spec, "/\\.~") {
+		return 0, ""
+	}
+
+	cfgPath := configPath
+	if cfgPath == "" {
+		xdgPaths, _ := xdg.NewPaths()
+		cfgPath = xdgPaths.ConfigPath()
+	}
+
+	cfg, configErr := config.Load(cfgPath)
+	if configErr != nil {
+		return 0, ""
+	}
+
+	vaultCfg, parseErr := vault.ParseNamedVaultConfig(cfg.Vault, cfg.VaultNameList(), cfg.SearchOrder)
+	if parseErr != nil {
+		return 0, ""
+	}
+	idx := vaultCfg.IndexByName(spec)
+	if idx < 0 {
+		return 0, ""
+	}
+	return idx + 1, vaultCfg.Entries[idx].Path
+}
+
 // parseVaultSpecScoped parses the -v spec and adjusts the session scope so the
 // result resolves correctly inside the command.
 //
@@ -153,13 +272,17 @@ func printVaultList(configPath string, w io.Writer) {
 		return
 	}
 
-	vaultCfg, parseErr := vault.ParseVaultConfig(cfg.Vault)
+	vaultCfg, parseErr := vault.ParseNamedVaultConfig(cfg.Vault, cfg.VaultNameList(), cfg.SearchOrder)
 	if parseErr != nil || len(vaultCfg.Entries) == 0 {
 		return
 	}
 
 	_, _ = fmt.Fprintf(w, "Configured vaults:\n")
 	for i, entry := range vaultCfg.Entries {
+		if entry.Name != "" {
+			_, _ = fmt.Fprintf(w, "  %d: %s (%s)\n", i+1, entry.Path, entry.Name)
+			continue
+		}
 		_, _ = fmt.Fprintf(w, "  %d: %s\n", i+1, entry.Path)
 	}
 }
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func TestParseVaultSpec_Name(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	prodPath := filepath.Join(dir, "prod.vault")
+	body := "vault:\n  - " + filepath.Join(dir, "dev.vault") + "\n  - {name: prod, path: " + prodPath + "}\n"
+	if err := os.WriteFile(cfgPath, []byte(body), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	vaultPath, fromIndex, err := parseVaultSpec(cfgPath, []string{"prod"})
+	if err != nil {
+		t.Fatalf("parseVaultSpec: %v", err)
+	}
+	if vaultPath != "" || fromIndex != 2 {
+		t.Errorf("parseVaultSpec(prod) = %q, %d; want \"\", 2", vaultPath, fromIndex)
+	}
+
+	// An unknown name is taken as a path, as before.
+	vaultPath, fromIndex, err = parseVaultSpec(cfgPath, []string{"staging"})
+	if err != nil || vaultPath != "staging" || fromIndex != 0 {
+		t.Errorf("parseVaultSpec(staging) = %q, %d, %v; want path", vaultPath, fromIndex, err)
+	}
+
+	resolved, err := resolveVaultPaths(cfgPath, []string{"prod", "1", "./prod"})
+	if err != nil {
+		t.Fatalf("resolveVaultPaths: %v", err)
+	}
+	want := []string{prodPath, filepath.Join(dir, "dev.vault"), "./prod"}
+	if !slices.Equal(resolved, want) {
+		t.Errorf("resolveVaultPaths = %v, want %v", resolved, want)
+	}
+}
+
+func TestApplyFormat(t *testing.T) {
+	newCmd := func(supported bool) (*cobra.Command, *bool) {
+		cmd := &cobra.Command{Use: "list"}
+		jsonOut := new(bool)
+		if supported {
+			addJSONFlag(cmd, jsonOut, "Output as JSON")
+		}
+		return cmd, jsonOut
+	}
+	defer func(format string) { globalOpts.Format = format }(globalOpts.Format)
+
+	globalOpts.Format = "yaml"
+	cmd, jsonOut := newCmd(true)
+	if err := applyFormat(cmd); err != nil || !*jsonOut {
+		t.Errorf("--output yaml should turn on --json, got %v, json=%v", err, *jsonOut)
+	}
+
+	globalOpts.Format = "text"
+	cmd, jsonOut = newCmd(true)
+	if err := applyFormat(cmd); err != nil || *jsonOut {
+		t.Errorf("--output text should leave --json off, got %v, json=%v", err, *jsonOut)
+	}
+
+	globalOpts.Format = "porcelain"
+	cmd, _ = newCmd(false)
+	if err := applyFormat(cmd); err == nil {
+		t.Error("expected an error for a command without structured output")
+	}
+
+	globalOpts.Format = "yaml"
+	cmd, _ = newCmd(true)
+	_ = cmd.Flags().Set("json", "true")
+	if err := applyFormat(cmd); err == nil {
+		t.Error("expected --json and --output yaml to conflict")
+	}
+
+	globalOpts.Format = "xml"
+	cmd, _ = newCmd(true)
+	if err := applyFormat(cmd); err == nil {
+		t.Error("expected an invalid format to be rejected")
+	}
+}
+
+func TestGlobalFlagsNotShadowed(t *testing.T) {
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		cmd.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+			if rootCmd.PersistentFlags().Lookup(f.Name) != nil {
+				t.Errorf("'%s --%s' shadows the global flag", cmd.CommandPath(), f.Name)
+			}
+		})
+		for _, sub := range cmd.Commands() {
+			walk(sub)
+		}
+	}
+	walk(rootCmd)
+}
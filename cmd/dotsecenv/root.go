@@ -1,6 +1,9 @@
 package main
 
 import (
+	"strings"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
 	"github.com/spf13/cobra"
 )
 
@@ -29,18 +32,34 @@ Secrets are stored in vault files and can be shared between team members.`
 
 	// Persistent flags available to all commands
 	rootCmd.PersistentFlags().StringVarP(&globalOpts.ConfigPath, "config", "c", "", "Path to config file")
-	rootCmd.PersistentFlags().StringArrayVarP(&globalOpts.VaultPaths, "vault", "v", nil, "Path to vault file or vault index (1-based)")
+	rootCmd.PersistentFlags().StringArrayVarP(&globalOpts.VaultPaths, "vault", "v", nil, "Path to vault file, vault index (1-based), or vault name")
 	rootCmd.PersistentFlags().BoolVarP(&globalOpts.Silent, "silent", "s", false, "Silent mode (suppress warnings)")
+	rootCmd.PersistentFlags().BoolVar(&globalOpts.RedactStdout, "redact-stdout", false, "Refuse to print secret values to non-terminal stdout outside of 'secret get'")
+	rootCmd.PersistentFlags().BoolVar(&globalOpts.Wait, "wait", false, "Wait until a vault locked by another dotsecenv process is released")
+	rootCmd.PersistentFlags().BoolVar(&globalOpts.NoWait, "no-wait", false, "Fail immediately if a vault is locked by another dotsecenv process")
+	rootCmd.MarkFlagsMutuallyExclusive("wait", "no-wait")
+	rootCmd.PersistentFlags().BoolVar(&globalOpts.NoColor, "no-color", false, "Disable colored output (also disabled by the NO_COLOR environment variable)")
+	rootCmd.PersistentFlags().BoolVar(&globalOpts.Canonical, "canonical", false, "Sort object keys and array elements in --json output, for byte-stable diffs")
+	rootCmd.PersistentFlags().StringVar(&globalOpts.Format, "output", string(output.FormatText), "Output format of listing commands: "+strings.Join(output.Formats, ", "))
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return applyFormat(cmd)
+	}
 
 	// Add subcommands
 	rootCmd.AddCommand(loginCmd)
+	rootCmd.AddCommand(logoutCmd)
 	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(identityCmd)
 	rootCmd.AddCommand(policyCmd)
 	rootCmd.AddCommand(secretCmd)
 	rootCmd.AddCommand(vaultCmd)
 	rootCmd.AddCommand(doctorCmd)
 	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(preCommitCmd)
+	rootCmd.AddCommand(selftestCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(exportCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(completionCmd)
 }
@@ -0,0 +1,175 @@
+// Package awssm reads and writes AWS Secrets Manager secrets for the 'import
+// aws' and 'export aws' commands.
+//
+// Requests go through the aws command-line tool rather than the AWS SDK, so
+// no AWS code is vendored into dotsecenv. The tool resolves credentials and
+// region the standard way: environment variables, shared config and
+// credential files, SSO and instance roles. Secret values are passed in a
+// private temporary file, never as arguments, and the file is removed as
+// soon as the tool exits.
+package awssm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Client is the subset of AWS Secrets Manager used by the aws adapters.
+type Client interface {
+	// GetSecretString returns the SecretString of the current version of
+	// the secret identified by secretID (a name or ARN).
+	GetSecretString(secretID string) (string, error)
+	// PutSecretString stores value as a new version of the secret called
+	// name, creating the secret if it does not exist.
+	PutSecretString(name, value string) error
+}
+
+// CLIClient is a Client backed by the aws command-line tool.
+type CLIClient struct {
+	// Program is the aws executable; empty means "aws" from PATH.
+	Program string
+	// Region overrides the region resolved by the aws tool when non-empty.
+	Region string
+}
+
+// Compile-time check that CLIClient implements Client.
+var _ Client = (*CLIClient)(nil)
+
+// errNotFound is returned by run when AWS reports ResourceNotFoundException.
+var errNotFound = errors.New("secret not found")
+
+// GetSecretString implements Client.
+func (c *CLIClient) GetSecretString(secretID string) (string, error) {
+	out, err := c.run(nil, "get-secret-value", "--secret-id", secretID, "--output", "json")
+	if errors.Is(err, errNotFound) {
+		return "", fmt.Errorf("secret %s not found in AWS Secrets Manager", secretID)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		SecretString *string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return "", fmt.Errorf("unexpected get-secret-value output: %w", err)
+	}
+	if resp.SecretString == nil {
+		return "", fmt.Errorf("secret %s has no SecretString (binary secrets are not supported)", secretID)
+	}
+	return *resp.SecretString, nil
+}
+
+// PutSecretString implements Client.
+func (c *CLIClient) PutSecretString(name, value string) error {
+	input, err := json.Marshal(map[string]string{"SecretId": name, "SecretString": value})
+	if err != nil {
+		return err
+	}
+	_, err = c.run(input, "put-secret-value")
+	if !errors.Is(err, errNotFound) {
+		return err
+	}
+
+	input, err = json.Marshal(map[string]string{"Name": name, "SecretString": value})
+	if err != nil {
+		return err
+	}
+	_, err = c.run(input, "create-secret")
+	return err
+}
+
+// run executes an 'aws secretsmanager' subcommand and returns its stdout.
+// A non-nil input is passed as --cli-input-json through a temporary file
+// readable only by the current user, which works on every platform, unlike
+// /dev/stdin.
+func (c *CLIClient) run(input []byte, args ...string) ([]byte, error) {
+	program := c.Program
+	if program == "" {
+		program = "aws"
+	}
+	args = append([]string{"secretsmanager"}, args...)
+	if input != nil {
+		path, err := writeInputFile(input)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = os.Remove(path) }()
+		args = append(args, "--cli-input-json", "file://"+path)
+	}
+	if c.Region != "" {
+		args = append(args, "--region", c.Region)
+	}
+
+	cmd := exec.Command(program, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var execErr *exec.Error
+		if errors.As(err, &execErr) || errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("aws command-line tool not found (%s): install it or put it on PATH", program)
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if strings.Contains(msg, "ResourceNotFoundException") {
+			return nil, errNotFound
+		}
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("aws secretsmanager %s failed: %s", args[1], msg)
+	}
+	return stdout.Bytes(), nil
+}
+
+// writeInputFile writes input to a new 0600 temporary file and returns its
+// path. The caller removes it.
+func writeInputFile(input []byte) (string, error) {
+	f, err := os.CreateTemp("", "dotsecenv-aws-*.json")
+	if err != nil {
+		return "", fmt.Errorf("failed to create aws input file: %w", err)
+	}
+	path := f.Name()
+	_, err = f.Write(input)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return "", fmt.Errorf("failed to write aws input file: %w", err)
+	}
+	return path, nil
+}
+
+// ParseSecretFields parses a JSON object secret into its fields. String
+// values are returned as-is; other JSON values are returned JSON-encoded.
+func ParseSecretFields(secretString string) (map[string]string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(secretString), &fields); err != nil || fields == nil {
+		return nil, errors.New("secret is not a JSON object of key/value pairs")
+	}
+
+	values := make(map[string]string, len(fields))
+	for k, raw := range fields {
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			values[k] = s
+			continue
+		}
+		values[k] = string(raw)
+	}
+	return values, nil
+}
+
+// SecretName maps a dotsecenv secret key to a Secrets Manager secret name
+// under prefix. Namespaced keys become paths: "prod::DB_URL" with prefix
+// "myapp/" is stored as "myapp/prod/DB_URL".
+func SecretName(prefix, key string) string {
+	return prefix + strings.ReplaceAll(key, "::", "/")
+}
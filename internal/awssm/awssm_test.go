@@ -0,0 +1,106 @@
+package awssm
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParseSecretFields(t *testing.T) {
+	values, err := ParseSecretFields(`{"DB_URL":"postgres://db","PORT":5432,"TLS":{"on":true}}`)
+	if err != nil {
+		t.Fatalf("ParseSecretFields failed: %v", err)
+	}
+	want := map[string]string{"DB_URL": "postgres://db", "PORT": "5432", "TLS": `{"on":true}`}
+	for k, v := range want {
+		if values[k] != v {
+			t.Errorf("%s = %q, want %q", k, values[k], v)
+		}
+	}
+
+	for _, bad := range []string{"plain text", `["a"]`, "null"} {
+		if _, err := ParseSecretFields(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestSecretName(t *testing.T) {
+	if got := SecretName("myapp/", "prod::DB_URL"); got != "myapp/prod/DB_URL" {
+		t.Errorf("SecretName = %q", got)
+	}
+	if got := SecretName("", "API_KEY"); got != "API_KEY" {
+		t.Errorf("SecretName = %q", got)
+	}
+}
+
+// fakeAWS writes a shell script standing in for the aws tool.
+func fakeAWS(t *testing.T, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake aws tool is a shell script")
+	}
+	path := filepath.Join(t.TempDir(), "aws")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCLIClient_GetSecretString(t *testing.T) {
+	c := &CLIClient{Program: fakeAWS(t, `echo '{"Name":"app","SecretString":"{\"K\":\"v\"}"}'`)}
+	got, err := c.GetSecretString("app")
+	if err != nil {
+		t.Fatalf("GetSecretString failed: %v", err)
+	}
+	if got != `{"K":"v"}` {
+		t.Errorf("GetSecretString = %q", got)
+	}
+}
+
+func TestCLIClient_PutSecretStringCreatesMissing(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "calls")
+	c := &CLIClient{Program: fakeAWS(t, `input="${4#file://}"
+echo "$input" >> `+log+`.paths
+echo "$2 $(cat "$input")" >> `+log+`
+if [ "$2" = put-secret-value ]; then
+  echo "An error occurred (ResourceNotFoundException)" >&2
+  exit 254
+fi
+`)}
+	if err := c.PutSecretString("p/K", "v"); err != nil {
+		t.Fatalf("PutSecretString failed: %v", err)
+	}
+	calls, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "put-secret-value {\"SecretId\":\"p/K\",\"SecretString\":\"v\"}\n" +
+		"create-secret {\"Name\":\"p/K\",\"SecretString\":\"v\"}\n"
+	if string(calls) != want {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+
+	// The input files holding the value are gone once the tool exits
+	paths, err := os.ReadFile(log + ".paths")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range strings.Fields(string(paths)) {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected input file %s to be removed, got %v", path, err)
+		}
+	}
+}
+
+func TestCLIClient_MissingProgram(t *testing.T) {
+	c := &CLIClient{Program: filepath.Join(t.TempDir(), "no-such-aws")}
+	_, err := c.GetSecretString("app")
+	if err == nil {
+		t.Fatal("expected an error for a missing aws tool")
+	} else if !strings.Contains(err.Error(), "not found") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
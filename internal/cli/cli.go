@@ -7,11 +7,15 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
+	"unicode"
 
+	"github.com/dotsecenv/dotsecenv/internal/progress"
 	"github.com/dotsecenv/dotsecenv/internal/xdg"
 
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/gpg"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/policy"
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
@@ -38,17 +42,51 @@ func ResolveConfigPath(configPath string, silent bool, stderr io.Writer) string
 
 // CLI represents the command-line interface
 type CLI struct {
-	vaultPaths    []string // For reference only
-	configPath    string
-	xdgPaths      xdg.Paths
-	config        config.Config
-	policy        policy.Policy // System policy (empty when none enforced)
-	vaultResolver VaultResolver // Multiple vaults
-	gpgClient     gpg.Client
-	stdin         io.Reader
-	Silent        bool
-	output        *output.Handler // Unified output handler
-	hasTTY        func() bool     // Returns true if a controlling terminal is present
+	vaultPaths     []string // For reference only
+	configPath     string
+	xdgPaths       xdg.Paths
+	config         config.Config
+	policy         policy.Policy // System policy (empty when none enforced)
+	vaultResolver  VaultResolver // Multiple vaults
+	gpgClient      gpg.Client
+	stdin          io.Reader
+	Silent         bool
+	output         *output.Handler // Unified output handler
+	hasTTY         func() bool     // Returns true if a controlling terminal is present
+	concurrency    int             // Max concurrent decryptions in batch paths (<= 1 means serial)
+	requireLatest  bool            // Refuse to fall back to older values in 'secret get'
+	relativeTimes  bool            // Show "3 days ago" instead of RFC3339 in human output
+	oldestFirst    bool            // 'secret get --all' lists values oldest first
+	dedupe         bool            // 'secret get --all' collapses runs of identical values
+	failFast       bool            // 'secret get --all' stops at the first value that fails to decrypt
+	explain        bool            // 'secret get' explains access-denied errors on stderr
+	valueEncoding  ValueEncoding   // Re-encoding of decrypted values in 'secret get'
+	replace        bool            // 'secret put' supersedes older values, trimmed to max_history
+	inclDeleted    bool            // 'secret get' reads deleted secrets
+	allowLarge     bool            // 'secret put' skips the max_secret_size check
+	detach         bool            // 'secret put' stores the value in a sidecar file
+	compress       bool            // 'secret put' gzips the value before encryption
+	noDefaultRcpt  bool            // 'secret put' ignores the vault's default_recipients
+	source         string          // 'secret put' tags new values with where they came from
+	confirmValue   *bool           // 'secret put' asks twice for a value typed at the terminal; nil asks for new secrets only
+	keyFilter      string          // Glob narrowing listed secret keys in describe and list
+	uidFilter      string          // Glob narrowing listed identity UIDs in describe
+	since          time.Duration   // 'vault describe' lists only entries changed within this window
+	againstKeyring bool            // 'vault verify' compares stored public keys with the keyring
+	decryptCheck   bool            // 'vault describe' tries to decrypt the secrets shared with the caller
+	describeOnly   string          // 'vault describe' shows only this section; empty shows both
+	failOn         map[string]bool // Levels whose errors fail 'validate' and 'vault verify'; nil means every level
+	progress       progress.Mode   // When long-running commands report progress on stderr
+
+	// readHidden reads a line from stdin without echo. Nil detects a
+	// terminal on stdin; tests set it to script one.
+	readHidden func() (string, error)
+
+	expireWarnDays       int    // 'identity add' flags keys expiring within this many days
+	failOnExpiring       bool   // 'identity add' refuses such keys instead of warning
+	doctorKeyCheck       bool   // 'vault doctor' runs a sign/decrypt round trip
+	doctorKeyFingerprint string // Key for the round trip; empty means the active identity
+	fingerprint          string // Per-invocation identity override (--fingerprint); wins over Login
 }
 
 // Policy returns the loaded system policy. Empty Policy means no policy is enforced.
@@ -66,7 +104,7 @@ func defaultHasTTY() bool {
 
 // NewCLI creates a new CLI instance
 func NewCLI(vaultPaths []string, configPath string, silent bool, stdin io.Reader, stdout, stderr io.Writer) (*CLI, error) {
-	return newCLI(vaultPaths, configPath, silent, stdin, stdout, stderr, nil)
+	return newCLI(vaultPaths, configPath, silent, stdin, stdout, stderr, nil, LockWaitConfigured)
 }
 
 // NewCLIConfigOnly creates a CLI instance that only loads config and GPG,
@@ -139,7 +177,7 @@ func loadConfigAndPrepareGPG(configPath string, silent bool, stdin io.Reader, st
 		xdgPaths:   xdgPaths,
 		config:     cfg,
 		policy:     pol,
-		gpgClient:  &gpg.GPGClient{},
+		gpgClient:  &gpg.GPGClient{PassphraseCommand: cfg.GPG.PassphraseCommand},
 		stdin:      stdin,
 		Silent:     silent,
 		output: output.NewHandler(stdout, stderr,
@@ -150,13 +188,19 @@ func loadConfigAndPrepareGPG(configPath string, silent bool, stdin io.Reader, st
 }
 
 // newCLI creates a CLI instance. If requireExplicitUpgradeOverride is non-nil, it overrides the config setting.
-func newCLI(vaultPaths []string, configPath string, silent bool, stdin io.Reader, stdout, stderr io.Writer, requireExplicitUpgradeOverride *bool) (*CLI, error) {
+// lockWait selects how long to wait for vaults locked by another process.
+func newCLI(vaultPaths []string, configPath string, silent bool, stdin io.Reader, stdout, stderr io.Writer, requireExplicitUpgradeOverride *bool, lockWait LockWait) (*CLI, error) {
 	cli, err := loadConfigAndPrepareGPG(configPath, silent, stdin, stdout, stderr)
 	if err != nil {
 		return nil, err
 	}
 	cfg := cli.config
 
+	lockTimeout, lockErr := resolveLockTimeout(cfg, lockWait)
+	if lockErr != nil {
+		return nil, lockErr
+	}
+
 	// Determine output writer for warnings
 	warnWriter := stderr
 	if silent {
@@ -229,13 +273,15 @@ func newCLI(vaultPaths []string, configPath string, silent bool, stdin io.Reader
 		}
 		vaultResolver = vault.NewVaultResolver(vault.VaultConfig{
 			RequireExplicitVaultUpgrade: requireExplicit,
+			LockTimeout:                 lockTimeout,
+			StructureCheck:              structureCheck(cfg),
 		})
 		if err := vaultResolver.OpenVaultsFromPaths(vaultPaths, warnWriter); err != nil {
-			return nil, NewError(fmt.Sprintf("failed to open vaults from -v paths: %v", err), ExitVaultError)
+			return nil, vaultOpenError("failed to open vaults from -v paths", err)
 		}
 	} else {
 		// Use config file vault settings
-		vaultCfg, err := vault.ParseVaultConfig(cfg.Vault)
+		vaultCfg, err := vault.ParseNamedVaultConfig(cfg.Vault, cfg.VaultNameList(), cfg.SearchOrder)
 		if err != nil {
 			return nil, NewError(fmt.Sprintf("failed to parse vault config: %v", err), ExitConfigError)
 		}
@@ -252,10 +298,16 @@ func newCLI(vaultPaths []string, configPath string, silent bool, stdin io.Reader
 			vaultCfg.RequireExplicitVaultUpgrade = cfg.ShouldRequireExplicitVaultUpgrade()
 		}
 
+		vaultCfg.LockTimeout = lockTimeout
+		vaultCfg.StructureCheck = structureCheck(cfg)
+		for i, recipients := range cfg.VaultDefaultRecipientList() {
+			vaultCfg.Entries[i].DefaultRecipients = recipients
+		}
+
 		vaultResolver = vault.NewVaultResolver(vaultCfg)
 		// Suppress startup warnings; commands like 'identity add' or 'validate' will report status
 		if err := vaultResolver.OpenVaults(io.Discard); err != nil {
-			return nil, NewError(fmt.Sprintf("failed to open vaults from config: %v", err), ExitVaultError)
+			return nil, vaultOpenError("failed to open vaults from config", err)
 		}
 	}
 
@@ -289,6 +341,211 @@ func (c *CLI) SetJSONMode(enabled bool) {
 	c.output = c.output.WithJSONMode(enabled)
 }
 
+// SetRedactStdout enables or disables the guard that refuses to print secret
+// values to a non-terminal stdout outside of secret get.
+func (c *CLI) SetRedactStdout(enabled bool) {
+	c.output = c.output.WithRedactStdoutMode(enabled)
+}
+
+// SetNoColor disables ANSI colors in human-readable output.
+func (c *CLI) SetNoColor(noColor bool) {
+	c.output = c.output.WithNoColorMode(noColor)
+}
+
+// SetCanonical makes --json outputs canonical: object keys and array
+// elements sorted, so equal data always prints the same bytes.
+func (c *CLI) SetCanonical(canonical bool) {
+	c.output = c.output.WithCanonicalMode(canonical)
+}
+
+// SetOutputFormat sets the encoding of --json outputs: JSON, YAML or
+// porcelain lines. Text leaves them JSON.
+func (c *CLI) SetOutputFormat(format output.Format) {
+	c.output = c.output.WithFormatMode(format)
+}
+
+// SetConcurrency sets how many values batch paths such as 'secret get --all'
+// decrypt at once. Values below 1 mean serial decryption.
+func (c *CLI) SetConcurrency(n int) {
+	c.concurrency = n
+}
+
+// SetRequireLatest makes 'secret get' fail when the caller cannot read the
+// latest value, instead of falling back to the newest value they can read.
+func (c *CLI) SetRequireLatest(requireLatest bool) {
+	c.requireLatest = requireLatest
+}
+
+// SetRelativeTimes switches human output between RFC3339 timestamps and
+// relative ones such as "3 days ago".
+func (c *CLI) SetRelativeTimes(relative bool) {
+	c.relativeTimes = relative
+}
+
+// SetSearchDepth limits 'secret get' without -v to the first depth vaults
+// in search order. A depth of 0 searches all of them.
+func (c *CLI) SetSearchDepth(depth int) {
+	c.vaultResolver.SetSearchDepth(depth)
+}
+
+// SetOldestFirst makes 'secret get --all' list values oldest first instead
+// of newest first.
+func (c *CLI) SetOldestFirst(oldestFirst bool) {
+	c.oldestFirst = oldestFirst
+}
+
+// SetDedupe makes 'secret get --all' collapse each run of consecutive
+// values with identical plaintext into its earliest-added value.
+func (c *CLI) SetDedupe(dedupe bool) {
+	c.dedupe = dedupe
+}
+
+// SetDecryptFailureMode sets whether 'secret get --all' skips values that
+// fail to decrypt or stops at the first one.
+func (c *CLI) SetDecryptFailureMode(mode DecryptFailureMode) {
+	c.failFast = mode == DecryptFailFast
+}
+
+// SetExplain makes 'secret get' follow an access-denied error with a
+// diagnostic of where the secret is and who it is shared with.
+func (c *CLI) SetExplain(explain bool) {
+	c.explain = explain
+}
+
+// SetValueEncoding makes 'secret get' re-encode decrypted values before
+// printing them.
+func (c *CLI) SetValueEncoding(enc ValueEncoding) {
+	c.valueEncoding = enc
+}
+
+// SetIncludeDeleted makes 'secret get' read the last value a deleted secret
+// had before it was deleted. Listing always shows deleted secrets.
+func (c *CLI) SetIncludeDeleted(include bool) {
+	c.inclDeleted = include
+}
+
+// SetReplace makes 'secret put' replace the secret: after confirmation, the
+// new value is appended and older values beyond max_history are dropped.
+func (c *CLI) SetReplace(replace bool) {
+	c.replace = replace
+}
+
+// SetAllowLarge makes 'secret put' and 'secret put-file' store values
+// larger than max_secret_size.
+func (c *CLI) SetAllowLarge(allow bool) {
+	c.allowLarge = allow
+}
+
+// SetDetach makes 'secret put' and 'secret put-file' store the encrypted
+// value in a sidecar file next to the vault instead of inline.
+func (c *CLI) SetDetach(detach bool) {
+	c.detach = detach
+}
+
+// SetCompress makes 'secret put' and 'secret put-file' gzip values before
+// encrypting them, and mark them compressed so reads undo it.
+func (c *CLI) SetCompress(compress bool) {
+	c.compress = compress
+}
+
+// SetConfirmValue makes 'secret put' ask twice for a value typed at the
+// terminal, and refuse to store it when the entries differ, or never ask
+// twice. Without it, new secrets are confirmed and existing ones are not.
+// Values that are piped in are never confirmed.
+func (c *CLI) SetConfirmValue(confirm bool) {
+	c.confirmValue = &confirm
+}
+
+// maxValueSource is the longest source 'secret put --source' accepts.
+const maxValueSource = 128
+
+// SetSource makes 'secret put' and 'secret put-file' tag new values with
+// source, recording where they came from (e.g. "rotated-by-ci"). The
+// source must be printable text of at most maxValueSource bytes.
+func (c *CLI) SetSource(source string) *Error {
+	if len(source) > maxValueSource {
+		return NewError(fmt.Sprintf("--source must be at most %d bytes", maxValueSource), ExitValidationError)
+	}
+	for _, r := range source {
+		if !unicode.IsPrint(r) {
+			return NewError(fmt.Sprintf("--source %q contains a non-printable character", source), ExitValidationError)
+		}
+	}
+	c.source = source
+	return nil
+}
+
+// SetNoDefaultRecipients makes 'secret put' encrypt new values to the
+// signer only, ignoring the target vault's default_recipients.
+func (c *CLI) SetNoDefaultRecipients(skip bool) {
+	c.noDefaultRcpt = skip
+}
+
+// SetFilter narrows 'vault describe' and 'secret get' list mode to secret
+// keys matching keyGlob and, in describe, identities whose UID matches
+// uidGlob. Globs use path.Match syntax; empty matches everything.
+func (c *CLI) SetFilter(keyGlob, uidGlob string) {
+	c.keyFilter = keyGlob
+	c.uidFilter = uidGlob
+}
+
+// SetSince narrows 'vault describe' to secrets whose latest value, and
+// identities that were added, within d of now. Zero lists everything.
+func (c *CLI) SetSince(d time.Duration) {
+	c.since = d
+}
+
+// SetAgainstKeyring makes 'vault verify' also compare each public key
+// stored in the vault with the local keyring's key for its fingerprint.
+func (c *CLI) SetAgainstKeyring(against bool) {
+	c.againstKeyring = against
+}
+
+// SetDecryptCheck makes 'vault describe' decrypt the latest value of each
+// secret shared with the caller, reporting per secret whether it could. The
+// plaintext is discarded.
+func (c *CLI) SetDecryptCheck(check bool) {
+	c.decryptCheck = check
+}
+
+// SetDescribeOnly limits 'vault describe' to one section, DescribeIdentities
+// or DescribeSecrets. Empty shows both. In JSON the other section's key is
+// left out entirely.
+func (c *CLI) SetDescribeOnly(section string) {
+	c.describeOnly = section
+}
+
+// SetValidateFailOn sets the ValidationError levels (STRUCTURE, IDENTITY,
+// SECRET, GLOBAL, or "any" or "none") whose errors make 'validate' and
+// 'vault verify' fail. Errors at other levels are still reported.
+func (c *CLI) SetValidateFailOn(levels []string) *Error {
+	failOn, err := parseFailOn(levels)
+	if err != nil {
+		return err
+	}
+	c.failOn = failOn
+	return nil
+}
+
+// SetProgress selects when long-running commands (import, export, vault
+// rekey, vault verify) report their progress on stderr.
+func (c *CLI) SetProgress(mode progress.Mode) {
+	c.progress = mode
+}
+
+// newProgress returns a progress reporter for total items, or nil when
+// progress is disabled.
+func (c *CLI) newProgress(verb, unit string, total int) *progress.Reporter {
+	return progress.New(c.output.Stderr(), c.progress, verb, unit, total)
+}
+
+// SetExpiryWarning makes 'identity add' warn about keys that expire within
+// days, or refuse them when fail is set.
+func (c *CLI) SetExpiryWarning(days int, fail bool) {
+	c.expireWarnDays = days
+	c.failOnExpiring = fail
+}
+
 // Close closes the vault and releases locks
 func (c *CLI) Close() error {
 	if c.vaultResolver != nil {
@@ -297,9 +554,41 @@ func (c *CLI) Close() error {
 	return nil
 }
 
-// activeFingerprint returns the configured login fingerprint, or "" if no
-// signed Login proof is present in the config.
+// SetFingerprint makes fingerprint the identity for this invocation only,
+// taking precedence over the login in config. The config is not modified.
+// The secret key for fingerprint must be in the GPG keyring; a successful
+// check is cached for session_ttl, until 'dotsecenv logout'.
+func (c *CLI) SetFingerprint(fingerprint string) *Error {
+	fp := identity.NormalizeFingerprint(fingerprint)
+	if fp == "" {
+		return NewError("--fingerprint must not be empty", ExitFingerprintRequired)
+	}
+	if c.cachedFingerprint(fp) {
+		c.fingerprint = fp
+		return nil
+	}
+
+	keys, err := c.gpgClient.ListSecretKeys()
+	if err != nil {
+		return NewError(fmt.Sprintf("failed to list secret keys: %v", err), ExitGPGError)
+	}
+	for _, key := range keys {
+		if identity.CompareFingerprints(key.Fingerprint, fp) {
+			c.fingerprint = fp
+			c.cacheFingerprint(fp)
+			return nil
+		}
+	}
+	return NewError(fmt.Sprintf("no secret key found in GPG keyring for fingerprint %s", fp), ExitGPGError)
+}
+
+// activeFingerprint returns the --fingerprint override if set, otherwise the
+// configured login fingerprint, or "" if no signed Login proof is present in
+// the config.
 func (c *CLI) activeFingerprint() string {
+	if c.fingerprint != "" {
+		return c.fingerprint
+	}
 	if c.config.Login == nil {
 		return ""
 	}
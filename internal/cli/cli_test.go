@@ -7,9 +7,12 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/policy"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
 )
 
 // withTempPolicyDir overrides policy.DefaultDir for the duration of t and
@@ -184,3 +187,89 @@ func TestNewCLIConfigOnly_PolicyLoadErrorClassification(t *testing.T) {
 // errorsIsCheck satisfies the goimports lint by ensuring we use errors.Is
 // somewhere in this file even when tests don't reference it directly.
 var _ = errors.Is
+
+func TestSetFingerprint(t *testing.T) {
+	cli := &CLI{
+		config:    config.Config{Login: newTestSignedLogin(t, "LOGINFINGERPRINT")},
+		gpgClient: NewMockGPGClient(),
+	}
+
+	if err := cli.SetFingerprint("unknownfingerprint"); err == nil {
+		t.Fatal("expected an error for a fingerprint without a secret key")
+	} else if err.ExitCode != ExitGPGError {
+		t.Errorf("expected ExitGPGError (%d), got %d", ExitGPGError, err.ExitCode)
+	}
+	if got := cli.activeFingerprint(); got != "LOGINFINGERPRINT" {
+		t.Errorf("failed override should leave the login in effect, got %q", got)
+	}
+
+	// The mock keyring holds TESTFINGERPRINT; case is normalized.
+	if err := cli.SetFingerprint("testfingerprint"); err != nil {
+		t.Fatalf("SetFingerprint failed: %v", err)
+	}
+	if got := cli.activeFingerprint(); got != "TESTFINGERPRINT" {
+		t.Errorf("activeFingerprint = %q, want TESTFINGERPRINT", got)
+	}
+	if cli.config.Login.Fingerprint != "LOGINFINGERPRINT" {
+		t.Errorf("config login should be unchanged, got %q", cli.config.Login.Fingerprint)
+	}
+}
+
+// TestSetFingerprint_UsedForAccessChecks verifies that secret get decrypts
+// as the override rather than the logged-in identity.
+func TestSetFingerprint_UsedForAccessChecks(t *testing.T) {
+	const override = "TESTFINGERPRINT"
+
+	resolver := NewMockVaultResolver()
+	resolver.VaultPaths = []string{"/vault1.yaml"}
+	resolver.VaultEntries = []vault.VaultEntry{{Path: "/vault1.yaml"}}
+	resolver.Secrets[0] = map[string]vault.Secret{
+		"MY_SECRET": {
+			Key: "MY_SECRET",
+			Values: []vault.SecretValue{
+				{AddedAt: time.Now().UTC(), Value: "Y2lwaGVydGV4dA==", AvailableTo: []string{override}},
+			},
+		},
+	}
+
+	var decryptedAs string
+	gpgClient := &MockGPGClientWithDecrypt{
+		MockGPGClient: NewMockGPGClient(),
+		DecryptFunc: func(ciphertext []byte, fingerprint string) ([]byte, error) {
+			decryptedAs = fingerprint
+			return []byte("plaintext"), nil
+		},
+	}
+
+	newCLI := func() (*CLI, *bytes.Buffer) {
+		stdout := &bytes.Buffer{}
+		return &CLI{
+			config:        config.Config{Login: newTestSignedLogin(t, "LOGINFINGERPRINT")},
+			vaultResolver: resolver,
+			gpgClient:     gpgClient,
+			stdin:         strings.NewReader(""),
+			output:        output.NewHandler(stdout, &bytes.Buffer{}),
+		}, stdout
+	}
+
+	// Without the override, secret get acts as the logged-in identity.
+	cli, stdout := newCLI()
+	_ = cli.SecretGet("MY_SECRET", true, false, false, "", 1)
+	if decryptedAs != "LOGINFINGERPRINT" {
+		t.Fatalf("decrypted as %q, want the logged-in identity", decryptedAs)
+	}
+
+	cli, stdout = newCLI()
+	if err := cli.SetFingerprint(override); err != nil {
+		t.Fatalf("SetFingerprint failed: %v", err)
+	}
+	if err := cli.SecretGet("MY_SECRET", true, false, false, "", 1); err != nil {
+		t.Fatalf("SecretGet with override failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "plaintext") {
+		t.Errorf("expected decrypted value in output, got %q", stdout.String())
+	}
+	if decryptedAs != override {
+		t.Errorf("decrypted as %q, want %q", decryptedAs, override)
+	}
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// VaultClone creates a vault at destPath holding the identities of the
+// vault at sourcePath and no secrets, to start a new environment with the
+// same team. A non-empty name is stored in the new vault's header; the
+// source's name is not copied.
+//
+// Every identity's signature is verified against the source first, and
+// nothing is created when one fails. Identities are copied as they are,
+// signatures included: each signer is copied along with them, so they
+// verify in the clone as in the source and no key is needed to sign.
+// The post_write hook runs for the new vault.
+func (c *CLI) VaultClone(sourcePath, destPath, name string) *Error {
+	sourcePath = vault.ExpandPath(sourcePath)
+	destPath = vault.ExpandPath(destPath)
+	if sourceAbs, err := filepath.Abs(sourcePath); err == nil {
+		if destAbs, err := filepath.Abs(destPath); err == nil && sourceAbs == destAbs {
+			return NewError(fmt.Sprintf("source and destination vault are the same: %s", sourcePath), ExitGeneralError)
+		}
+	}
+	if _, err := os.Stat(destPath); err == nil {
+		return NewError(fmt.Sprintf("vault file already exists: %s", destPath), ExitVaultError)
+	}
+
+	reader, openErr := vault.NewWriterReadOnly(sourcePath)
+	if openErr != nil {
+		return NewError(fmt.Sprintf("failed to open source vault: %v", openErr), ExitVaultError)
+	}
+	source, readErr := reader.ReadVault()
+	if readErr != nil {
+		return NewError(fmt.Sprintf("failed to read source vault: %v", readErr), ExitVaultError)
+	}
+	if len(source.Identities) == 0 {
+		return NewError(fmt.Sprintf("source vault %s has no identities to clone", sourcePath), ExitVaultError)
+	}
+
+	var rejected int
+	for i := range source.Identities {
+		id := &source.Identities[i]
+		if valid, verifyErr := verifyIdentitySignature(id, &source); verifyErr != nil || !valid {
+			reason := "signature verification failed"
+			if verifyErr != nil {
+				reason = verifyErr.Error()
+			}
+			_, _ = fmt.Fprintf(c.output.Stderr(), "rejected: identity %s (%s): %s\n", id.Fingerprint, id.UID, reason)
+			rejected++
+		}
+	}
+	if rejected > 0 {
+		return NewError(fmt.Sprintf("%d identity(ies) failed verification; %s was not created", rejected, destPath), ExitValidationError)
+	}
+
+	writer, err := vault.NewWriter(destPath)
+	if err != nil {
+		return NewError(fmt.Sprintf("failed to create vault: %v", err), ExitVaultError)
+	}
+	if err := writer.RewriteFromVault(vault.Vault{Identities: source.Identities}); err != nil {
+		_ = os.Remove(destPath)
+		return NewError(fmt.Sprintf("failed to write vault: %v", err), ExitVaultError)
+	}
+	if name != "" {
+		if err := writer.SetName(name); err != nil {
+			return NewError(fmt.Sprintf("failed to set vault name: %v", err), ExitVaultError)
+		}
+	}
+
+	for _, id := range source.Identities {
+		_, _ = fmt.Fprintf(c.output.Stdout(), "cloned: identity %s (%s)\n", id.Fingerprint, id.UID)
+	}
+	_, _ = fmt.Fprintf(c.output.Stdout(), "summary: created %s with %d identity(ies) and no secrets\n", destPath, len(source.Identities))
+	return c.runPostWriteHookForPath(destPath)
+}
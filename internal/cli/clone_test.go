@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// writeCloneSource writes a vault holding ids and one secret.
+func writeCloneSource(t *testing.T, ids ...vault.Identity) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "source.vault")
+	w, err := vault.NewWriter(path)
+	if err != nil {
+		t.Fatalf("failed to create source vault: %v", err)
+	}
+	now := time.Now().UTC()
+	if err := w.RewriteFromVault(vault.Vault{
+		Identities: ids,
+		Secrets: []vault.Secret{
+			{Key: "DB_URL", AddedAt: now, Values: []vault.SecretValue{{AddedAt: now, AvailableTo: []string{ids[0].Fingerprint}, Value: "c2VjcmV0"}}},
+		},
+	}); err != nil {
+		t.Fatalf("failed to write source vault: %v", err)
+	}
+	return path
+}
+
+func TestVaultClone_CopiesIdentitiesOnly(t *testing.T) {
+	alice, aliceID := newSignedVaultIdentity(t, "Alice", nil)
+	_, bobID := newSignedVaultIdentity(t, "Bob", alice)
+	sourcePath := writeCloneSource(t, aliceID, bobID)
+	destPath := filepath.Join(t.TempDir(), "staging", "vault")
+
+	stdout := &bytes.Buffer{}
+	cli := &CLI{output: output.NewHandler(stdout, &bytes.Buffer{})}
+	if err := cli.VaultClone(sourcePath, destPath, "staging"); err != nil {
+		t.Fatalf("VaultClone failed: %v", err)
+	}
+
+	reader, err := vault.NewWriterReadOnly(destPath)
+	if err != nil {
+		t.Fatalf("failed to open clone: %v", err)
+	}
+	clone, err := reader.ReadVault()
+	if err != nil {
+		t.Fatalf("failed to read clone: %v", err)
+	}
+	if len(clone.Secrets) != 0 {
+		t.Errorf("expected no secrets in the clone, got %d", len(clone.Secrets))
+	}
+	if len(clone.Identities) != 2 {
+		t.Fatalf("expected 2 identities, got %d", len(clone.Identities))
+	}
+	for i, want := range []vault.Identity{aliceID, bobID} {
+		got := clone.Identities[i]
+		if got.Fingerprint != want.Fingerprint || got.Hash != want.Hash || got.Signature != want.Signature || got.SignedBy != want.SignedBy {
+			t.Errorf("identity %d not copied as is: got %+v", i, got)
+		}
+		if valid, err := verifyIdentitySignature(&got, &clone); err != nil || !valid {
+			t.Errorf("identity %s does not verify in the clone: %v", got.Fingerprint, err)
+		}
+	}
+	if name := reader.Header().Name; name != "staging" {
+		t.Errorf("expected the clone to be named staging, got %q", name)
+	}
+	if !strings.Contains(stdout.String(), "summary: created "+destPath+" with 2 identity(ies) and no secrets") {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+
+	// The destination must not be overwritten.
+	again := cli.VaultClone(sourcePath, destPath, "")
+	if again == nil || !strings.Contains(again.Message, "already exists") {
+		t.Errorf("expected an existing destination to be refused, got %v", again)
+	}
+}
+
+func TestVaultClone_RejectsUnverifiedIdentity(t *testing.T) {
+	alice, aliceID := newSignedVaultIdentity(t, "Alice", nil)
+	_, malloryID := newSignedVaultIdentity(t, "Mallory", alice)
+	// Tampered after signing, so the stored hash no longer matches.
+	malloryID.UID = "Carol <carol@example.com>"
+	sourcePath := writeCloneSource(t, aliceID, malloryID)
+	destPath := filepath.Join(t.TempDir(), "vault")
+
+	stderr := &bytes.Buffer{}
+	cli := &CLI{output: output.NewHandler(&bytes.Buffer{}, stderr)}
+	err := cli.VaultClone(sourcePath, destPath, "")
+	if err == nil || err.ExitCode != ExitValidationError {
+		t.Fatalf("expected ExitValidationError, got %v", err)
+	}
+	if !strings.Contains(stderr.String(), "rejected: identity "+malloryID.Fingerprint) {
+		t.Errorf("expected a rejection report, got: %s", stderr.String())
+	}
+	if _, statErr := os.Stat(destPath); !os.IsNotExist(statErr) {
+		t.Errorf("nothing should be created when verification fails, stat: %v", statErr)
+	}
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -79,7 +80,7 @@ func TestSecretPut_WithVaultPath(t *testing.T) {
 	}
 
 	// Use -v flag
-	putErr := cli.SecretPut("MY_SECRET", vaultPath, 0, "")
+	putErr := cli.SecretPut("MY_SECRET", vaultPath, 0, "", false)
 	if putErr != nil {
 		t.Fatalf("SecretPut with -v failed unexpectedly: %v", putErr)
 	}
@@ -151,7 +152,7 @@ func TestSecretPut_WithFromIndex(t *testing.T) {
 	}
 
 	// Test --from 2 (index 1)
-	err := cli.SecretPut("MY_SECRET", "", 2, "")
+	err := cli.SecretPut("MY_SECRET", "", 2, "", false)
 	if err != nil {
 		t.Fatalf("SecretPut with --from 2 failed unexpectedly: %v", err)
 	}
@@ -208,7 +209,7 @@ func TestSecretPut_FromIndexOutOfRange(t *testing.T) {
 	}
 
 	// Test -v 4 (out of range)
-	err := cli.SecretPut("MY_SECRET", "", 4, "")
+	err := cli.SecretPut("MY_SECRET", "", 4, "", false)
 	switch {
 	case err == nil:
 		t.Fatalf("Expected SecretPut with -v 4 to fail, but it succeeded")
@@ -605,7 +606,7 @@ func TestSecretPut_BlockedByDeleted(t *testing.T) {
 	}
 
 	// Try to put to a deleted secret
-	putErr := cli.SecretPut("DELETED_SECRET", vaultPath, 0, "")
+	putErr := cli.SecretPut("DELETED_SECRET", vaultPath, 0, "", false)
 	switch {
 	case putErr == nil:
 		t.Fatal("SecretPut should fail for deleted secret")
@@ -816,6 +817,73 @@ func TestSecretList_JSONOutput(t *testing.T) {
 	}
 }
 
+// TestSecretList_MemoryResolver runs the CLI against the public in-memory
+// resolver, as embedders do in their tests.
+func TestSecretList_MemoryResolver(t *testing.T) {
+	var resolver VaultResolver = vault.NewMemoryResolver(
+		vault.Vault{Secrets: []vault.Secret{{Key: "SECRET_A", Values: []vault.SecretValue{{Value: "a"}}}}},
+		vault.Vault{Secrets: []vault.Secret{{Key: "SECRET_B", Values: []vault.SecretValue{{Value: "b"}}}}},
+	)
+
+	stdoutBuf := &bytes.Buffer{}
+	cli := &CLI{
+		vaultResolver: resolver,
+		output:        output.NewHandler(stdoutBuf, &bytes.Buffer{}),
+	}
+
+	if err := cli.SecretList(false, "", 2); err != nil {
+		t.Fatalf("SecretList failed: %v", err)
+	}
+	out := stdoutBuf.String()
+	if strings.Contains(out, "SECRET_A") || !strings.Contains(out, "SECRET_B") {
+		t.Errorf("expected only SECRET_B from vault 2, got: %s", out)
+	}
+}
+
+// TestSecretList_Filter tests that --filter narrows the listed keys
+func TestSecretList_Filter(t *testing.T) {
+	mockVaultResolver := NewMockVaultResolver()
+	mockVaultResolver.VaultPaths = []string{"/vault1.yaml"}
+	mockVaultResolver.VaultEntries = []vault.VaultEntry{
+		{Path: "/vault1.yaml"},
+	}
+	mockVaultResolver.Secrets[0] = map[string]vault.Secret{
+		"DB_URL":           {Key: "DB_URL", Values: []vault.SecretValue{{Value: "a"}}},
+		"DB_PASSWORD_PROD": {Key: "DB_PASSWORD_PROD", Values: []vault.SecretValue{{Value: "b"}}},
+		"API_KEY_PROD":     {Key: "API_KEY_PROD", Values: []vault.SecretValue{{Value: "c"}}},
+	}
+
+	stdoutBuf := &bytes.Buffer{}
+	cli := &CLI{
+		vaultResolver: mockVaultResolver,
+		output:        output.NewHandler(stdoutBuf, &bytes.Buffer{}),
+	}
+
+	tests := []struct {
+		glob string
+		want string
+	}{
+		{"DB_*", "DB_PASSWORD_PROD\nDB_URL\n"},
+		{"*_PROD", "API_KEY_PROD\nDB_PASSWORD_PROD\n"},
+		{"NOPE_*", "No secrets found\n"},
+	}
+	for _, tt := range tests {
+		stdoutBuf.Reset()
+		cli.SetFilter(tt.glob, "")
+		if err := cli.SecretList(false, "", 0); err != nil {
+			t.Fatalf("SecretList(%q) failed: %v", tt.glob, err)
+		}
+		if got := stdoutBuf.String(); got != tt.want {
+			t.Errorf("SecretList(%q) = %q, want %q", tt.glob, got, tt.want)
+		}
+	}
+
+	cli.SetFilter("[", "")
+	if err := cli.SecretList(false, "", 0); err == nil || err.ExitCode != ExitValidationError {
+		t.Errorf("expected validation error for malformed glob, got %v", err)
+	}
+}
+
 // TestSecretList_WithDeletedSecrets tests that deleted secrets are shown with (deleted) marker
 func TestSecretList_WithDeletedSecrets(t *testing.T) {
 	mockVaultResolver := NewMockVaultResolver()
@@ -859,6 +927,47 @@ func TestSecretList_WithDeletedSecrets(t *testing.T) {
 	}
 }
 
+// TestSecretGet_IncludeDeleted tests that --include-deleted reads the last
+// value a deleted secret had, with a warning, and that it is refused otherwise.
+func TestSecretGet_IncludeDeleted(t *testing.T) {
+	deleted := exportTestSecret("GONE", "before-deletion", "ME")
+	deleted.Values = append(deleted.Values, vault.SecretValue{AvailableTo: []string{}, Deleted: true})
+	cli, stdout, stderr := newExportCLI(t, map[string]vault.Secret{"GONE": deleted})
+
+	if err := cli.SecretGet("GONE", false, false, false, "", 0); err == nil {
+		t.Fatal("expected a deleted secret to be refused without --include-deleted")
+	}
+
+	for _, fromIndex := range []int{0, 1} {
+		stdout.Reset()
+		stderr.Reset()
+		cli.SetIncludeDeleted(true)
+		if err := cli.SecretGet("GONE", false, false, false, "", fromIndex); err != nil {
+			t.Fatalf("-v %d: SecretGet failed: %v", fromIndex, err)
+		}
+		if stdout.String() != "before-deletion\n" {
+			t.Errorf("-v %d: expected the value before deletion, got %q", fromIndex, stdout.String())
+		}
+		if !strings.Contains(stderr.String(), "was DELETED") {
+			t.Errorf("-v %d: expected a deletion warning, got: %s", fromIndex, stderr.String())
+		}
+	}
+}
+
+// TestSecretGet_IncludeDeletedNotShared tests that a deleted secret whose
+// earlier values were not shared with the caller stays unreadable.
+func TestSecretGet_IncludeDeletedNotShared(t *testing.T) {
+	deleted := exportTestSecret("GONE", "before-deletion", "SOMEONE")
+	deleted.Values = append(deleted.Values, vault.SecretValue{AvailableTo: []string{}, Deleted: true})
+	cli, _, _ := newExportCLI(t, map[string]vault.Secret{"GONE": deleted})
+	cli.SetIncludeDeleted(true)
+
+	err := cli.SecretGet("GONE", false, false, false, "", 0)
+	if err == nil || err.ExitCode != ExitAccessDenied {
+		t.Fatalf("expected access denied, got %v", err)
+	}
+}
+
 // TestSecretList_Empty tests listing when no secrets exist
 func TestSecretList_Empty(t *testing.T) {
 	mockVaultResolver := NewMockVaultResolver()
@@ -1193,6 +1302,84 @@ func TestSecretForget_IgnoreNotFound_AlreadyDeleted(t *testing.T) {
 	}
 }
 
+func TestSecretForgetPrefix(t *testing.T) {
+	t.Setenv("DOTSECENV_CONFIG", "")
+
+	mockVaultResolver := NewMockVaultResolver()
+	testFP := "TESTFINGERPRINT"
+
+	mockVaultResolver.Identities[testFP] = vault.Identity{
+		Fingerprint:   testFP,
+		PublicKey:     "mock_public_key",
+		Algorithm:     "RSA",
+		AlgorithmBits: 2048,
+	}
+
+	vaultPath := "/vault1.yaml"
+	mockVaultResolver.VaultPaths = []string{vaultPath}
+	mockVaultResolver.VaultEntries = []vault.VaultEntry{{Path: vaultPath}}
+
+	live := func(key string, readers ...string) vault.Secret {
+		return vault.Secret{Key: key, Values: []vault.SecretValue{{Value: "v", AvailableTo: readers}}}
+	}
+	gone := live("SVC_GONE", testFP)
+	gone.Values = append(gone.Values, vault.SecretValue{Deleted: true})
+	mockVaultResolver.Secrets[0] = map[string]vault.Secret{
+		"SVC_DB_URL":  live("SVC_DB_URL", testFP),
+		"SVC_API_KEY": live("SVC_API_KEY", testFP),
+		"SVC_OTHERS":  live("SVC_OTHERS", "OTHERFP"),
+		"SVC_GONE":    gone,
+		"APP_DB_URL":  live("APP_DB_URL", testFP),
+	}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cli := &CLI{
+		config: config.Config{
+			ApprovedAlgorithms: []config.ApprovedAlgorithm{{Algo: "RSA", MinBits: 2048}},
+			Login:              newTestSignedLogin(t, testFP),
+		},
+		vaultResolver: mockVaultResolver,
+		gpgClient:     NewMockGPGClient(),
+		output:        output.NewHandler(stdout, stderr),
+	}
+
+	if err := cli.SecretForgetPrefix("SVC_", "", 1, true); err != nil {
+		t.Fatalf("SecretForgetPrefix failed: %v", err)
+	}
+
+	for _, key := range []string{"SVC_API_KEY", "SVC_DB_URL"} {
+		if !mockVaultResolver.Secrets[0][key].IsDeleted() {
+			t.Errorf("%s should be deleted", key)
+		}
+		if !strings.Contains(stdout.String(), "deleted: "+key+"\n") {
+			t.Errorf("expected %s to be reported deleted, got: %s", key, stdout.String())
+		}
+	}
+	if mockVaultResolver.Secrets[0]["APP_DB_URL"].IsDeleted() {
+		t.Error("non-matching APP_DB_URL must be left intact")
+	}
+	if mockVaultResolver.Secrets[0]["SVC_OTHERS"].IsDeleted() {
+		t.Error("SVC_OTHERS is not readable by the caller and must be skipped")
+	}
+	if !strings.Contains(stderr.String(), "skipped: SVC_OTHERS: access denied") {
+		t.Errorf("expected SVC_OTHERS skipped for access, got: %s", stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "skipped: SVC_GONE: secret 'SVC_GONE' is already deleted") {
+		t.Errorf("expected SVC_GONE skipped as already deleted, got: %s", stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "summary: deleted=2 skipped=2") {
+		t.Errorf("expected summary, got: %s", stdout.String())
+	}
+	if len(mockVaultResolver.SavedVaults) != 1 {
+		t.Errorf("expected a single save, got %v", mockVaultResolver.SavedVaults)
+	}
+
+	if err := cli.SecretForgetPrefix("NOPE_", "", 1, true); err == nil || err.ExitCode != ExitVaultError {
+		t.Errorf("expected a vault error for a prefix matching nothing, got %v", err)
+	}
+}
+
 func TestSecretGet_JSONOutput(t *testing.T) {
 	t.Setenv("DOTSECENV_CONFIG", "")
 
@@ -1485,6 +1672,60 @@ func TestSecretGet_FallbackNotFound(t *testing.T) {
 	}
 }
 
+// TestSecretGet_DepthExcludesLaterVaults tests that --depth stops the search
+// before a vault holding the secret.
+func TestSecretGet_DepthExcludesLaterVaults(t *testing.T) {
+	t.Setenv("DOTSECENV_CONFIG", "")
+
+	mockVaultResolver := NewMockVaultResolver()
+	mockVaultResolver.VaultPaths = []string{"/vault1", "/vault2"}
+	mockVaultResolver.VaultEntries = []vault.VaultEntry{{Path: "/vault1"}, {Path: "/vault2"}}
+	mockVaultResolver.Secrets[1] = map[string]vault.Secret{
+		"DB_URL": {Key: "DB_URL", Values: []vault.SecretValue{{
+			AvailableTo: []string{"SOMEFP"},
+			Value:       base64.StdEncoding.EncodeToString([]byte("ciphertext")),
+		}}},
+	}
+
+	mockGPGClient := &MockGPGClientWithDecrypt{
+		MockGPGClient: NewMockGPGClient(),
+		DecryptFunc: func(ciphertext []byte, fingerprint string) ([]byte, error) {
+			return []byte("postgres://db"), nil
+		},
+	}
+
+	stdoutBuf := &bytes.Buffer{}
+	cli := &CLI{
+		config:        config.Config{Login: newTestSignedLogin(t, "SOMEFP")},
+		vaultResolver: mockVaultResolver,
+		gpgClient:     mockGPGClient,
+		stdin:         strings.NewReader(""),
+		output:        output.NewHandler(stdoutBuf, &bytes.Buffer{}),
+	}
+
+	if err := cli.SecretGet("DB_URL", false, false, false, "", 0); err != nil {
+		t.Fatalf("expected the secret from vault 2 without --depth, got: %v", err)
+	}
+	if !strings.Contains(stdoutBuf.String(), "postgres://db") {
+		t.Errorf("expected the value in output, got: %s", stdoutBuf.String())
+	}
+
+	stdoutBuf.Reset()
+	cli.SetSearchDepth(1)
+	for _, last := range []bool{false, true} {
+		err := cli.SecretGet("DB_URL", false, last, false, "", 0)
+		if err == nil || err.ExitCode != ExitVaultError || !strings.Contains(err.Message, "not found") {
+			t.Errorf("last=%v: expected not found with --depth 1, got: %v", last, err)
+		}
+	}
+	if err := cli.SecretGet("DB_URL", true, false, false, "", 0); err == nil || err.ExitCode != ExitVaultError {
+		t.Errorf("--all: expected not found with --depth 1, got: %v", err)
+	}
+	if stdoutBuf.Len() != 0 {
+		t.Errorf("expected no output with --depth 1, got: %s", stdoutBuf.String())
+	}
+}
+
 // TestSecretGet_ExistsButNotAccessible tests that when a secret exists but the
 // logged-in identity has no access and the GPG agent cannot decrypt it either,
 // the error is "access denied" (with the identity named) rather than "not found".
@@ -1545,6 +1786,233 @@ func TestSecretGet_ExistsButNotAccessible(t *testing.T) {
 	}
 }
 
+// TestSecretGet_ExplainAccessDenied covers --explain for a secret shared only
+// with another fingerprint.
+func TestSecretGet_ExplainAccessDenied(t *testing.T) {
+	loggedInFP := "LOGGED_IN_FP"
+	otherFP := "OTHER_FP"
+
+	mockVaultResolver := NewMockVaultResolver()
+	mockVaultResolver.Secrets[0] = map[string]vault.Secret{
+		"HCLOUD_TOKEN": {
+			Key: "HCLOUD_TOKEN",
+			Values: []vault.SecretValue{
+				{AddedAt: time.Now().UTC(), Value: "c2VjcmV0", AvailableTo: []string{otherFP}},
+			},
+		},
+	}
+	mockVaultResolver.Identities[otherFP] = vault.Identity{Fingerprint: otherFP, UID: "Other <other@example.com>"}
+	mockVaultResolver.IdentitiesByVault[0] = map[string]vault.Identity{loggedInFP: {Fingerprint: loggedInFP}}
+	mockVaultResolver.VaultPaths = []string{"/vault1.yaml", "/vault2.yaml"}
+	mockVaultResolver.VaultEntries = []vault.VaultEntry{{Path: "/vault1.yaml"}, {Path: "/vault2.yaml"}}
+
+	mockGPGClient := &MockGPGClientWithDecrypt{
+		MockGPGClient: NewMockGPGClient(),
+		DecryptFunc: func(ciphertext []byte, fingerprint string) ([]byte, error) {
+			return nil, fmt.Errorf("gpg: decryption failed: No secret key")
+		},
+	}
+
+	for _, explain := range []bool{false, true} {
+		stderrBuf := &bytes.Buffer{}
+		cli := &CLI{
+			config: config.Config{
+				ApprovedAlgorithms: []config.ApprovedAlgorithm{{Algo: "RSA", MinBits: 2048}},
+				Login:              newTestSignedLogin(t, loggedInFP),
+			},
+			vaultResolver: mockVaultResolver,
+			gpgClient:     mockGPGClient,
+			stdin:         strings.NewReader(""),
+			output:        output.NewHandler(&bytes.Buffer{}, stderrBuf),
+		}
+		cli.SetExplain(explain)
+
+		err := cli.SecretGet("HCLOUD_TOKEN", false, false, false, "", 0)
+		if err == nil || err.ExitCode != ExitAccessDenied {
+			t.Fatalf("explain=%v: expected ExitAccessDenied, got %v", explain, err)
+		}
+
+		stderr := stderrBuf.String()
+		if !explain {
+			if strings.Contains(stderr, "explain:") {
+				t.Errorf("diagnostic printed without --explain:\n%s", stderr)
+			}
+			continue
+		}
+		for _, want := range []string{
+			"explain: access to secret 'HCLOUD_TOKEN' for identity " + loggedInFP,
+			"vault 1 (/vault1.yaml): secret present with 1 value(s); your identity is in this vault",
+			"      " + otherFP + " (Other <other@example.com>)",
+			"no value is shared with your identity",
+			"vault 2 (/vault2.yaml): secret not present; your identity is not in this vault",
+			"ask one of " + otherFP + " to run: dotsecenv secret share HCLOUD_TOKEN " + loggedInFP,
+		} {
+			if !strings.Contains(stderr, want) {
+				t.Errorf("expected %q in diagnostic, got:\n%s", want, stderr)
+			}
+		}
+		if strings.Contains(stderr, "c2VjcmV0") {
+			t.Errorf("diagnostic includes the encrypted value:\n%s", stderr)
+		}
+	}
+}
+
+// TestSecretGet_RequireLatest tests that --require-latest refuses the older
+// value a caller still has access to once the latest value was not shared with
+// them, in default, -v and --last modes, while plain secret get falls back.
+func TestSecretGet_RequireLatest(t *testing.T) {
+	t.Setenv("DOTSECENV_CONFIG", "")
+
+	loggedInFP := "LOGGED_IN_FP"
+	otherFP := "OTHER_FP"
+
+	now := time.Now().UTC()
+	older := now.Add(-1 * time.Hour)
+
+	// The caller could read the older value but was left out of the latest.
+	secret := vault.Secret{
+		Key:     "MY_SECRET",
+		AddedAt: older,
+		Values: []vault.SecretValue{
+			{AddedAt: older, Value: "b2xk", AvailableTo: []string{loggedInFP, otherFP}},
+			{AddedAt: now, Value: "bmV3", AvailableTo: []string{otherFP}},
+		},
+	}
+
+	newResolver := func(t *testing.T) *MockVaultResolver {
+		manager := newTestManager(t, vault.Vault{Secrets: []vault.Secret{secret}})
+		r := NewMockVaultResolver()
+		r.Secrets[0] = map[string]vault.Secret{"MY_SECRET": secret}
+		r.Managers = map[int]*vault.Manager{0: manager}
+		r.VaultPaths = []string{manager.Path()}
+		r.VaultEntries = []vault.VaultEntry{{Path: manager.Path()}}
+		return r
+	}
+
+	tests := []struct {
+		name          string
+		requireLatest bool
+		last          bool
+		fromIndex     int
+	}{
+		{name: "default"},
+		{name: "default require latest", requireLatest: true},
+		{name: "from index", fromIndex: 1},
+		{name: "from index require latest", requireLatest: true, fromIndex: 1},
+		{name: "last", last: true},
+		{name: "last require latest", requireLatest: true, last: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGPGClient := &MockGPGClientWithDecrypt{
+				MockGPGClient: NewMockGPGClient(),
+				DecryptFunc: func(ciphertext []byte, fingerprint string) ([]byte, error) {
+					// The agent only holds the caller's key, so only the
+					// older value decrypts.
+					if string(ciphertext) != "old" {
+						return nil, fmt.Errorf("gpg: decryption failed: No secret key")
+					}
+					return []byte("old_value"), nil
+				},
+			}
+
+			stdoutBuf := &bytes.Buffer{}
+			stderrBuf := &bytes.Buffer{}
+
+			cli := &CLI{
+				config: config.Config{
+					ApprovedAlgorithms: []config.ApprovedAlgorithm{{Algo: "RSA", MinBits: 2048}},
+					Login:              newTestSignedLogin(t, loggedInFP),
+				},
+				vaultResolver: newResolver(t),
+				gpgClient:     mockGPGClient,
+				stdin:         strings.NewReader(""),
+				output:        output.NewHandler(stdoutBuf, stderrBuf),
+			}
+			cli.SetRequireLatest(tt.requireLatest)
+
+			err := cli.SecretGet("MY_SECRET", false, tt.last, false, "", tt.fromIndex)
+			if !tt.requireLatest {
+				if err != nil {
+					t.Fatalf("SecretGet should fall back to the older value, got: %v", err)
+				}
+				if !strings.Contains(stdoutBuf.String(), "old_value") {
+					t.Errorf("Expected older value, got: %s", stdoutBuf.String())
+				}
+				return
+			}
+
+			switch {
+			case err == nil:
+				t.Fatalf("Expected error, got output: %s", stdoutBuf.String())
+			case err.ExitCode != ExitAccessDenied:
+				t.Errorf("Expected ExitAccessDenied, got exit code: %d", err.ExitCode)
+			case !strings.Contains(err.Message, "latest value"):
+				t.Errorf("Expected message to mention the latest value, got: %s", err.Message)
+			}
+			if stdoutBuf.Len() != 0 {
+				t.Errorf("Expected no value printed, got: %s", stdoutBuf.String())
+			}
+		})
+	}
+}
+
+// TestSecretGet_RequireLatest_DecryptsViaAgent tests that --require-latest
+// still lets the GPG agent decrypt a latest value not shared with the
+// logged-in identity; only the older-value fallback is disabled.
+func TestSecretGet_RequireLatest_DecryptsViaAgent(t *testing.T) {
+	t.Setenv("DOTSECENV_CONFIG", "")
+
+	loggedInFP := "LOGGED_IN_FP"
+	otherFP := "OTHER_FP_IN_AGENT"
+
+	now := time.Now().UTC()
+	older := now.Add(-1 * time.Hour)
+
+	mockVaultResolver := NewMockVaultResolver()
+	mockVaultResolver.Secrets[0] = map[string]vault.Secret{
+		"MY_SECRET": {
+			Key: "MY_SECRET",
+			Values: []vault.SecretValue{
+				{AddedAt: older, Value: "b2xk", AvailableTo: []string{loggedInFP}},
+				{AddedAt: now, Value: "bmV3", AvailableTo: []string{otherFP}},
+			},
+		},
+	}
+	mockVaultResolver.VaultPaths = []string{"/vault.yaml"}
+	mockVaultResolver.VaultEntries = []vault.VaultEntry{{Path: "/vault.yaml"}}
+
+	mockGPGClient := &MockGPGClientWithDecrypt{
+		MockGPGClient: NewMockGPGClient(),
+		DecryptFunc: func(ciphertext []byte, fingerprint string) ([]byte, error) {
+			return []byte(string(ciphertext) + "_value"), nil
+		},
+	}
+
+	stdoutBuf := &bytes.Buffer{}
+	stderrBuf := &bytes.Buffer{}
+
+	cli := &CLI{
+		config: config.Config{
+			ApprovedAlgorithms: []config.ApprovedAlgorithm{{Algo: "RSA", MinBits: 2048}},
+			Login:              newTestSignedLogin(t, loggedInFP),
+		},
+		vaultResolver: mockVaultResolver,
+		gpgClient:     mockGPGClient,
+		stdin:         strings.NewReader(""),
+		output:        output.NewHandler(stdoutBuf, stderrBuf),
+	}
+	cli.SetRequireLatest(true)
+
+	if err := cli.SecretGet("MY_SECRET", false, false, false, "", 0); err != nil {
+		t.Fatalf("SecretGet --require-latest should succeed via GPG agent, got: %v", err)
+	}
+	if !strings.Contains(stdoutBuf.String(), "new_value") {
+		t.Errorf("Expected latest value, got: %s", stdoutBuf.String())
+	}
+}
+
 // TestSecretGet_JSONOutput_AllMode_IncludesAvailableToAndSignedBy verifies that
 // `secret get NAME --all --json` exposes per-value available_to and signed_by,
 // which are required for auditing access control across versions.
@@ -1625,6 +2093,132 @@ func TestSecretGet_JSONOutput_AllMode_IncludesAvailableToAndSignedBy(t *testing.
 	}
 }
 
+// TestSecretGet_AllMode_Reverse verifies that SetOldestFirst flips the
+// order of `secret get --all` in both the all-vaults and single-vault paths.
+func TestSecretGet_AllMode_Reverse(t *testing.T) {
+	t.Setenv("DOTSECENV_CONFIG", "")
+
+	now := time.Now().UTC()
+	mockVaultResolver := NewMockVaultResolver()
+	mockVaultResolver.Secrets[0] = map[string]vault.Secret{
+		"DB_PASSWORD": {
+			Key: "DB_PASSWORD",
+			Values: []vault.SecretValue{
+				{AddedAt: now.Add(-2 * time.Hour), Value: "b2xk", AvailableTo: []string{"ME"}},
+				{AddedAt: now.Add(-1 * time.Hour), Value: "bWlk", AvailableTo: []string{"ME"}},
+				{AddedAt: now, Value: "bmV3", AvailableTo: []string{"ME"}},
+			},
+		},
+	}
+	mockVaultResolver.VaultPaths = []string{"/vault.yaml"}
+	mockVaultResolver.VaultEntries = []vault.VaultEntry{{Path: "/vault.yaml"}}
+
+	stdoutBuf := &bytes.Buffer{}
+	cli := &CLI{
+		config:        config.Config{Login: newTestSignedLogin(t, "ME")},
+		vaultResolver: mockVaultResolver,
+		gpgClient:     &plainDecryptGPGClient{MockGPGClient: NewMockGPGClient()},
+		stdin:         strings.NewReader(""),
+		output:        output.NewHandler(stdoutBuf, &bytes.Buffer{}),
+	}
+
+	order := func(t *testing.T, fromIndex int) string {
+		t.Helper()
+		stdoutBuf.Reset()
+		if err := cli.SecretGet("DB_PASSWORD", true, false, true, "", fromIndex); err != nil {
+			t.Fatalf("SecretGet --all failed: %v", err)
+		}
+		var results []SecretValueJSON
+		if err := json.Unmarshal(stdoutBuf.Bytes(), &results); err != nil {
+			t.Fatalf("Failed to parse JSON output: %v\nOutput: %s", err, stdoutBuf.String())
+		}
+		var values []string
+		for _, r := range results {
+			values = append(values, fmt.Sprint(r.Value))
+		}
+		return strings.Join(values, ",")
+	}
+
+	for _, fromIndex := range []int{0, 1} {
+		cli.SetOldestFirst(false)
+		if got := order(t, fromIndex); got != "new,mid,old" {
+			t.Errorf("-v %d: default order = %s, want newest first", fromIndex, got)
+		}
+		cli.SetOldestFirst(true)
+		if got := order(t, fromIndex); got != "old,mid,new" {
+			t.Errorf("-v %d: reversed order = %s, want oldest first", fromIndex, got)
+		}
+	}
+}
+
+// TestSecretGet_AllMode_Dedupe verifies that SetDedupe collapses runs of
+// identical consecutive values into the earliest one, keeping its added_at,
+// while a value that returns after a change is listed again.
+func TestSecretGet_AllMode_Dedupe(t *testing.T) {
+	t.Setenv("DOTSECENV_CONFIG", "")
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return base.Add(time.Duration(h) * time.Hour) }
+	mockVaultResolver := NewMockVaultResolver()
+	mockVaultResolver.Secrets[0] = map[string]vault.Secret{
+		"DB_PASSWORD": {
+			Key: "DB_PASSWORD",
+			Values: []vault.SecretValue{
+				{AddedAt: at(0), Value: "b2xk", AvailableTo: []string{"ME"}}, // old
+				{AddedAt: at(1), Value: "b2xk", AvailableTo: []string{"ME"}}, // old
+				{AddedAt: at(2), Value: "bmV3", AvailableTo: []string{"ME"}}, // new
+				{AddedAt: at(3), Value: "bmV3", AvailableTo: []string{"ME"}}, // new
+				{AddedAt: at(4), Value: "bmV3", AvailableTo: []string{"ME"}}, // new
+				{AddedAt: at(5), Value: "b2xk", AvailableTo: []string{"ME"}}, // old
+			},
+		},
+	}
+	mockVaultResolver.VaultPaths = []string{"/vault.yaml"}
+	mockVaultResolver.VaultEntries = []vault.VaultEntry{{Path: "/vault.yaml"}}
+
+	stdoutBuf := &bytes.Buffer{}
+	cli := &CLI{
+		config:        config.Config{Login: newTestSignedLogin(t, "ME")},
+		vaultResolver: mockVaultResolver,
+		gpgClient:     &plainDecryptGPGClient{MockGPGClient: NewMockGPGClient()},
+		stdin:         strings.NewReader(""),
+		output:        output.NewHandler(stdoutBuf, &bytes.Buffer{}),
+	}
+
+	history := func(t *testing.T, fromIndex int) string {
+		t.Helper()
+		stdoutBuf.Reset()
+		if err := cli.SecretGet("DB_PASSWORD", true, false, true, "", fromIndex); err != nil {
+			t.Fatalf("SecretGet --all failed: %v", err)
+		}
+		var results []SecretValueJSON
+		if err := json.Unmarshal(stdoutBuf.Bytes(), &results); err != nil {
+			t.Fatalf("Failed to parse JSON output: %v\nOutput: %s", err, stdoutBuf.String())
+		}
+		var entries []string
+		for _, r := range results {
+			entries = append(entries, fmt.Sprintf("%v@%d", r.Value, int(r.AddedAt.Sub(base).Hours())))
+		}
+		return strings.Join(entries, ",")
+	}
+
+	for _, fromIndex := range []int{0, 1} {
+		cli.SetDedupe(false)
+		cli.SetOldestFirst(false)
+		if got := history(t, fromIndex); got != "old@5,new@4,new@3,new@2,old@1,old@0" {
+			t.Errorf("-v %d: without --dedupe = %s, want every value", fromIndex, got)
+		}
+		cli.SetDedupe(true)
+		if got := history(t, fromIndex); got != "old@5,new@2,old@0" {
+			t.Errorf("-v %d: with --dedupe = %s, want runs collapsed to their earliest value", fromIndex, got)
+		}
+		cli.SetOldestFirst(true)
+		if got := history(t, fromIndex); got != "old@0,new@2,old@5" {
+			t.Errorf("-v %d: with --dedupe --reverse = %s", fromIndex, got)
+		}
+	}
+}
+
 // TestSecretGet_JSONOutput_NoAll_OmitsAvailableToAndSignedBy verifies that the
 // single-value JSON output stays lean and does not leak access metadata.
 func TestSecretGet_JSONOutput_NoAll_OmitsAvailableToAndSignedBy(t *testing.T) {
@@ -1819,3 +2413,74 @@ func TestSecretValueJSON_OmitemptyContract(t *testing.T) {
 		})
 	}
 }
+
+// TestSecretGet_SignerAlgorithmNoLongerApproved verifies that tightening
+// approved_algorithms does not lock anyone out of existing secrets: policy
+// gates creating values, not reading them.
+func TestSecretGet_SignerAlgorithmNoLongerApproved(t *testing.T) {
+	t.Setenv("DOTSECENV_CONFIG", "")
+
+	client := gpg.NewMemoryClient()
+	fp, err := client.GenerateKey("Old Key", "old@example.com")
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	info, err := client.GetPublicKeyInfo(fp)
+	if err != nil {
+		t.Fatalf("GetPublicKeyInfo failed: %v", err)
+	}
+	armored, err := client.EncryptToRecipients([]byte("still-readable"), []string{info.PublicKeyBase64}, nil)
+	if err != nil {
+		t.Fatalf("EncryptToRecipients failed: %v", err)
+	}
+	login, err := CreateSignedLogin(client, fp)
+	if err != nil {
+		t.Fatalf("CreateSignedLogin failed: %v", err)
+	}
+
+	mockVaultResolver := NewMockVaultResolver()
+	mockVaultResolver.Identities[fp] = vault.Identity{Fingerprint: fp, Algorithm: "EdDSA", Curve: "Ed25519", AlgorithmBits: 255, SignedBy: fp}
+	mockVaultResolver.Secrets[0] = map[string]vault.Secret{
+		"DB_PASSWORD": {
+			Key:      "DB_PASSWORD",
+			SignedBy: fp,
+			Values: []vault.SecretValue{{
+				AddedAt:     time.Now().UTC(),
+				AvailableTo: []string{fp},
+				SignedBy:    fp,
+				Value:       base64.StdEncoding.EncodeToString([]byte(armored)),
+			}},
+		},
+	}
+	mockVaultResolver.VaultPaths = []string{"/vault.yaml"}
+	mockVaultResolver.VaultEntries = []vault.VaultEntry{{Path: "/vault.yaml"}}
+
+	// The key's EdDSA is no longer approved
+	cfg := config.Config{
+		Login:              login,
+		ApprovedAlgorithms: []config.ApprovedAlgorithm{{Algo: "RSA", MinBits: 4096}},
+	}
+	if cfg.IsAlgorithmAllowed(info.Algorithm, info.AlgorithmBits) {
+		t.Fatalf("expected %s to be disallowed by the test config", info.Algorithm)
+	}
+
+	stdoutBuf := &bytes.Buffer{}
+	cli := &CLI{
+		config:        cfg,
+		vaultResolver: mockVaultResolver,
+		gpgClient:     client,
+		stdin:         strings.NewReader(""),
+		output:        output.NewHandler(stdoutBuf, &bytes.Buffer{}),
+	}
+
+	if err := cli.SecretGet("DB_PASSWORD", true, false, true, "", 1); err != nil {
+		t.Fatalf("expected the secret to stay readable, got: %v", err)
+	}
+	var results []SecretValueJSON
+	if err := json.Unmarshal(stdoutBuf.Bytes(), &results); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v\nOutput: %s", err, stdoutBuf.String())
+	}
+	if len(results) != 1 || results[0].Value != "still-readable" {
+		t.Errorf("expected the decrypted value, got %+v", results)
+	}
+}
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
@@ -65,6 +64,9 @@ func (c *CLI) VaultCompact(jsonOutput bool, yes bool, vaultPath string, fromInde
 				return NewError(fmt.Sprintf("failed to rewrite vault: %v", rewriteErr), ExitVaultError)
 			}
 			applied = true
+			if hookErr := c.runPostWriteHook(targetIndex); hookErr != nil {
+				return hookErr
+			}
 		}
 		return c.printCompactJSON(entry.Path, stats, applied)
 	}
@@ -92,6 +94,9 @@ func (c *CLI) VaultCompact(jsonOutput bool, yes bool, vaultPath string, fromInde
 	if rewriteErr := writer.RewriteFromVault(compacted); rewriteErr != nil {
 		return NewError(fmt.Sprintf("failed to rewrite vault: %v", rewriteErr), ExitVaultError)
 	}
+	if hookErr := c.runPostWriteHook(targetIndex); hookErr != nil {
+		return hookErr
+	}
 
 	_, _ = fmt.Fprintf(c.output.Stdout(), "\nCompacted %s: dropped %d value(s)", expandedPath, stats.ValuesDropped)
 	if stats.SecretsRemoved > 0 {
@@ -146,9 +151,7 @@ func (c *CLI) printCompactJSON(path string, stats *vault.CompactStats, applied b
 		})
 	}
 
-	encoder := json.NewEncoder(c.output.Stdout())
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(result); err != nil {
+	if err := c.output.EncodeJSON(result); err != nil {
 		return NewError(fmt.Sprintf("failed to encode json: %v", err), ExitGeneralError)
 	}
 	return nil
@@ -0,0 +1,273 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/gpg"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// Levels of the problems reported by ConfigCheck.
+const (
+	ConfigProblemError   = "error"
+	ConfigProblemWarning = "warning"
+)
+
+// ConfigProblemJSON is one problem found by 'config check'. Field is the
+// config setting it concerns, e.g. "approved_algorithms[1].min_bits".
+type ConfigProblemJSON struct {
+	Level   string `json:"level"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ConfigCheckJSON is the JSON output of 'config check --json'.
+type ConfigCheckJSON struct {
+	Config   string              `json:"config"`
+	Problems []ConfigProblemJSON `json:"problems"`
+}
+
+// approvedAlgorithmCurves lists, per algorithm family an approved_algorithms
+// entry may name, the curves it may list. RSA takes no curves.
+var approvedAlgorithmCurves = map[string][]string{
+	"RSA":   nil,
+	"ECC":   {"P-256", "P-384", "P-521", "secp256k1"},
+	"EdDSA": {"Ed25519", "Ed448"},
+}
+
+// behaviorFlagFields lists the config settings that must be YAML booleans,
+// as paths of mapping keys.
+var behaviorFlagFields = [][]string{
+	{"behavior", "require_explicit_vault_upgrade"},
+	{"behavior", "restrict_to_configured_vaults"},
+	{"hooks", "fail_on_error"},
+	{"strict_structure"},
+}
+
+// ConfigCheck lints the config file at configPath beyond YAML parsing: vault
+// paths must be absolute once ~ is expanded, approved_algorithms entries
+// must be well-formed, behavior flags must be booleans, durations and limits
+// must parse, gpg.program must be usable, and a login fingerprint must have
+// an encryption-capable secret key in the keyring of gpgClient. Missing
+// vault files are warnings. It fails with ExitConfigError if any error is
+// found.
+func ConfigCheck(configPath string, gpgClient gpg.Client, jsonOutput bool, out *output.Handler) *Error {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return NewError(fmt.Sprintf("failed to read config: %v", err), ExitConfigError)
+	}
+
+	problems := checkConfigData(data, gpgClient)
+
+	var errorCount, warningCount int
+	for _, p := range problems {
+		if p.Level == ConfigProblemError {
+			errorCount++
+		} else {
+			warningCount++
+		}
+	}
+
+	if jsonOutput {
+		if problems == nil {
+			problems = []ConfigProblemJSON{}
+		}
+		if err := out.EncodeJSON(ConfigCheckJSON{Config: configPath, Problems: problems}); err != nil {
+			return NewError(fmt.Sprintf("failed to encode json: %v", err), ExitGeneralError)
+		}
+	} else {
+		for _, p := range problems {
+			if p.Field != "" {
+				_, _ = fmt.Fprintf(out.Stdout(), "%s: %s: %s\n", p.Level, p.Field, p.Message)
+			} else {
+				_, _ = fmt.Fprintf(out.Stdout(), "%s: %s\n", p.Level, p.Message)
+			}
+		}
+		if len(problems) == 0 {
+			_, _ = fmt.Fprintf(out.Stdout(), "%s: no problems found\n", configPath)
+		} else {
+			_, _ = fmt.Fprintf(out.Stdout(), "%s: %d error(s), %d warning(s)\n", configPath, errorCount, warningCount)
+		}
+	}
+
+	if errorCount > 0 {
+		return NewError(fmt.Sprintf("config check found %d error(s) in %s", errorCount, configPath), ExitConfigError)
+	}
+	return nil
+}
+
+// checkConfigData returns the problems found in the config file content
+// data, in the order of the settings checked.
+func checkConfigData(data []byte, gpgClient gpg.Client) []ConfigProblemJSON {
+	var problems []ConfigProblemJSON
+	addError := func(field, format string, args ...any) {
+		problems = append(problems, ConfigProblemJSON{Level: ConfigProblemError, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+	addWarning := func(field, format string, args ...any) {
+		problems = append(problems, ConfigProblemJSON{Level: ConfigProblemWarning, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		addError("", "invalid YAML: %v", err)
+		return problems
+	}
+	for _, path := range behaviorFlagFields {
+		if node := lookupYAMLPath(&root, path); node != nil && node.Tag != "!!bool" {
+			addError(strings.Join(path, "."), "must be true or false, got %q", node.Value)
+		}
+	}
+
+	var cfg config.Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		// The flags above are the usual cause; report anything else.
+		if len(problems) == 0 {
+			addError("", "failed to parse config: %v", err)
+		}
+		return problems
+	}
+
+	if len(cfg.Vault) == 0 {
+		addWarning("vault", "no vaults configured")
+	}
+	for i, p := range cfg.Vault {
+		field := fmt.Sprintf("vault[%d]", i)
+		expanded := vault.ExpandPath(p)
+		if !filepath.IsAbs(expanded) {
+			addError(field, "vault path %q is relative; use an absolute path or one starting with ~", p)
+			continue
+		}
+		if _, err := os.Stat(expanded); errors.Is(err, os.ErrNotExist) {
+			addWarning(field, "vault file %s does not exist; run 'dotsecenv init vault' to create it", expanded)
+		} else if err != nil {
+			addError(field, "cannot access vault file %s: %v", expanded, err)
+		}
+	}
+
+	if len(cfg.ApprovedAlgorithms) == 0 {
+		addError("approved_algorithms", "no approved algorithms; every key would be rejected")
+	}
+	for i, alg := range cfg.ApprovedAlgorithms {
+		field := fmt.Sprintf("approved_algorithms[%d]", i)
+		curves, known := approvedAlgorithmCurves[alg.Algo]
+		if !known {
+			addError(field+".algo", "unknown algorithm %q (one of: RSA, ECC, EdDSA)", alg.Algo)
+			continue
+		}
+		if alg.MinBits <= 0 {
+			addError(field+".min_bits", "must be a positive number of bits, got %d", alg.MinBits)
+		}
+		switch {
+		case curves == nil && len(alg.Curves) > 0:
+			addWarning(field+".curves", "curves are ignored for %s", alg.Algo)
+		case curves != nil && len(alg.Curves) == 0:
+			addError(field+".curves", "no curves listed; every %s key would be rejected", alg.Algo)
+		}
+		for _, curve := range alg.Curves {
+			if curves != nil && !containsFold(curves, curve) {
+				addError(field+".curves", "unknown %s curve %q (one of: %s)", alg.Algo, curve, strings.Join(curves, ", "))
+			}
+		}
+	}
+
+	if _, err := cfg.GetLockTimeout(); err != nil {
+		addError("lock_timeout", "%v", err)
+	}
+	if _, err := cfg.GetClockSkewTolerance(); err != nil {
+		addError("clock_skew_tolerance", "%v", err)
+	}
+	if _, err := cfg.GetSessionTTL(); err != nil {
+		addError("session_ttl", "%v", err)
+	}
+	if _, err := cfg.GetMaxHistory(); err != nil {
+		addError("max_history", "%v", err)
+	}
+	if _, err := cfg.GetMaxSecretSize(); err != nil {
+		addError("max_secret_size", "%v", err)
+	}
+	if _, err := cfg.GetMinRecipients(); err != nil {
+		addError("min_recipients", "%v", err)
+	}
+
+	if err := gpg.ValidateAndSetGPGProgram(cfg.GPG.Program); err != nil {
+		addError("gpg.program", "%v", err)
+	} else if cfg.Login != nil && cfg.Login.Fingerprint != "" {
+		checkLoginKey(cfg.Login.Fingerprint, gpgClient, addError)
+	}
+	return problems
+}
+
+// checkLoginKey reports through addError when fingerprint has no secret key
+// in the GPG keyring or its key cannot encrypt.
+func checkLoginKey(fingerprint string, gpgClient gpg.Client, addError func(field, format string, args ...any)) {
+	const field = "login.fingerprint"
+	keys, err := gpgClient.ListSecretKeys()
+	if err != nil {
+		addError(field, "cannot list GPG secret keys: %v", err)
+		return
+	}
+	found := false
+	for _, key := range keys {
+		if identity.CompareFingerprints(key.Fingerprint, fingerprint) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		addError(field, "no secret key for %s in the GPG keyring", fingerprint)
+		return
+	}
+	info, err := gpgClient.GetPublicKeyInfo(fingerprint)
+	if err != nil {
+		addError(field, "cannot read key %s: %v", fingerprint, err)
+		return
+	}
+	if !info.CanEncrypt {
+		addError(field, "key %s cannot encrypt", fingerprint)
+	}
+}
+
+// lookupYAMLPath returns the value node at the mapping keys path in the
+// document root, or nil when a key is missing.
+func lookupYAMLPath(root *yaml.Node, path []string) *yaml.Node {
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	for _, key := range path {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+// containsFold reports whether list holds s, ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
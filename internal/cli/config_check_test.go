@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/gpg"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+)
+
+// runConfigCheck writes content as a config file and checks it with --json,
+// returning the problems found.
+func runConfigCheck(t *testing.T, content string) ([]ConfigProblemJSON, *Error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	stdout := &bytes.Buffer{}
+	mock := NewMockGPGClient()
+	mock.PublicKeyInfo = map[string]gpg.KeyInfo{"TESTFINGERPRINT": {CanEncrypt: true}}
+
+	checkErr := ConfigCheck(path, mock, true, output.NewHandler(stdout, &bytes.Buffer{}))
+
+	var result ConfigCheckJSON
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout.String())
+	}
+	return result.Problems, checkErr
+}
+
+func findConfigProblem(problems []ConfigProblemJSON, field string) *ConfigProblemJSON {
+	for i := range problems {
+		if problems[i].Field == field {
+			return &problems[i]
+		}
+	}
+	return nil
+}
+
+func TestConfigCheck_NonexistentVault(t *testing.T) {
+	vaultPath := filepath.Join(t.TempDir(), "missing", "vault")
+	problems, err := runConfigCheck(t, `approved_algorithms:
+  - algo: RSA
+    min_bits: 2048
+vault:
+  - `+vaultPath+`
+  - relative/vault
+gpg:
+  program: PATH
+`)
+
+	if p := findConfigProblem(problems, "vault[0]"); p == nil || p.Level != ConfigProblemWarning || !strings.Contains(p.Message, "does not exist") {
+		t.Errorf("expected a missing-vault warning, got %+v", problems)
+	}
+	if p := findConfigProblem(problems, "vault[1]"); p == nil || p.Level != ConfigProblemError || !strings.Contains(p.Message, "relative") {
+		t.Errorf("expected a relative-path error, got %+v", problems)
+	}
+	if err == nil || err.ExitCode != ExitConfigError {
+		t.Errorf("expected a config error, got %v", err)
+	}
+}
+
+func TestConfigCheck_BadAlgorithmEntry(t *testing.T) {
+	vaultPath := filepath.Join(t.TempDir(), "vault")
+	if err := os.WriteFile(vaultPath, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	problems, err := runConfigCheck(t, `approved_algorithms:
+  - algo: RSA
+    min_bits: 2048
+  - algo: DSA
+    min_bits: 1024
+  - algo: ECC
+    curves: [P-256, P-999]
+    min_bits: 0
+vault:
+  - `+vaultPath+`
+behavior:
+  restrict_to_configured_vaults: "yes"
+gpg:
+  program: PATH
+`)
+
+	for _, field := range []string{
+		"approved_algorithms[1].algo",
+		"approved_algorithms[2].min_bits",
+		"approved_algorithms[2].curves",
+		"behavior.restrict_to_configured_vaults",
+	} {
+		if p := findConfigProblem(problems, field); p == nil || p.Level != ConfigProblemError {
+			t.Errorf("expected an error for %s, got %+v", field, problems)
+		}
+	}
+	if findConfigProblem(problems, "approved_algorithms[0].algo") != nil {
+		t.Errorf("valid RSA entry reported: %+v", problems)
+	}
+	if err == nil || err.ExitCode != ExitConfigError {
+		t.Errorf("expected a config error, got %v", err)
+	}
+}
+
+func TestConfigCheck_LoginKeyMustBeAvailable(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not found in PATH")
+	}
+	vaultPath := filepath.Join(t.TempDir(), "vault")
+	if err := os.WriteFile(vaultPath, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	base := `approved_algorithms:
+  - algo: EdDSA
+    curves: [Ed25519]
+    min_bits: 255
+vault:
+  - ` + vaultPath + `
+gpg:
+  program: PATH
+`
+
+	problems, err := runConfigCheck(t, base+"login:\n  fingerprint: TESTFINGERPRINT\n")
+	if err != nil || len(problems) != 0 {
+		t.Fatalf("expected a clean config, got %v: %+v", err, problems)
+	}
+
+	problems, _ = runConfigCheck(t, base+"login:\n  fingerprint: UNKNOWNFINGERPRINT\n")
+	if p := findConfigProblem(problems, "login.fingerprint"); p == nil || !strings.Contains(p.Message, "no secret key") {
+		t.Errorf("expected a missing-key error, got %+v", problems)
+	}
+}
+
+func TestInitConfig_PresetsPassConfigCheck(t *testing.T) {
+	dir := t.TempDir()
+	vaultPath := filepath.Join(dir, "vault")
+	if err := os.WriteFile(vaultPath, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, preset := range config.AlgorithmPresets {
+		t.Run(preset, func(t *testing.T) {
+			path := filepath.Join(dir, preset+".yaml")
+			out := output.NewHandler(&bytes.Buffer{}, &bytes.Buffer{})
+			if err := InitConfig(path, []string{vaultPath}, "", "", false, preset, out); err != nil {
+				t.Fatalf("InitConfig failed: %v", err)
+			}
+
+			cfg, err := config.Load(path)
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			want, _ := config.PresetApprovedAlgorithms(preset)
+			if !reflect.DeepEqual(cfg.ApprovedAlgorithms, want) {
+				t.Errorf("written approved_algorithms = %+v, want %+v", cfg.ApprovedAlgorithms, want)
+			}
+
+			stdout := &bytes.Buffer{}
+			_ = ConfigCheck(path, NewMockGPGClient(), true, output.NewHandler(stdout, &bytes.Buffer{}))
+			var result ConfigCheckJSON
+			if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+				t.Fatalf("invalid JSON output: %v\n%s", err, stdout.String())
+			}
+			for _, p := range result.Problems {
+				// gpg.program depends on the test machine
+				if p.Field != "gpg.program" {
+					t.Errorf("unexpected problem: %+v", p)
+				}
+			}
+		})
+	}
+
+	if err := InitConfig(filepath.Join(dir, "bad.yaml"), []string{vaultPath}, "", "", false, "legacy", output.NewHandler(&bytes.Buffer{}, &bytes.Buffer{})); err == nil || err.ExitCode != ExitValidationError {
+		t.Errorf("expected a validation error for an unknown preset, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+)
+
+// ConfigMigrate rewrites the config file at configPath from an older shape to
+// the current one, printing each change. A config that is already current is
+// left untouched. With dryRun, the changes are printed but not written.
+func ConfigMigrate(configPath string, dryRun bool, out *output.Handler) *Error {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return NewError(fmt.Sprintf("failed to read config: %v", err), ExitConfigError)
+	}
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return NewError(fmt.Sprintf("failed to read config: %v", err), ExitConfigError)
+	}
+
+	migrated, changes, err := config.Migrate(data)
+	if err != nil {
+		return NewError(fmt.Sprintf("cannot migrate %s: %v", configPath, err), ExitConfigError)
+	}
+	var check config.Config
+	if err := yaml.Unmarshal(migrated, &check); err != nil {
+		return NewError(fmt.Sprintf("cannot migrate %s: migrated config does not load: %v", configPath, err), ExitConfigError)
+	}
+	if len(changes) == 0 {
+		_, _ = fmt.Fprintf(out.Stdout(), "%s is already current; nothing to migrate\n", configPath)
+		return nil
+	}
+
+	for _, change := range changes {
+		_, _ = fmt.Fprintf(out.Stdout(), "%s\n", change)
+	}
+	if dryRun {
+		_, _ = fmt.Fprintf(out.Stdout(), "dry run: %s not modified\n", configPath)
+		return nil
+	}
+
+	if err := writeFileAtomic(configPath, migrated); err != nil {
+		return NewError(fmt.Sprintf("failed to write config: %v", err), ExitConfigError)
+	}
+	// Keep the permissions the user gave the file
+	if err := os.Chmod(configPath, info.Mode().Perm()); err != nil {
+		return NewError(fmt.Sprintf("failed to restore config permissions: %v", err), ExitConfigError)
+	}
+	_, _ = fmt.Fprintf(out.Stdout(), "Migrated %s\n", configPath)
+	return nil
+}
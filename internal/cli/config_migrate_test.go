@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+)
+
+func TestConfigMigrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	legacy := "vault:\n  - /tmp/v\nstrict: true\n"
+	if err := os.WriteFile(path, []byte(legacy), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	stdout := &bytes.Buffer{}
+	out := output.NewHandler(stdout, &bytes.Buffer{})
+
+	if err := ConfigMigrate(path, true, out); err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != legacy {
+		t.Errorf("dry run modified the config:\n%s", data)
+	}
+	if !strings.Contains(stdout.String(), "replaced strict: true with behavior.restrict_to_configured_vaults: true") {
+		t.Errorf("expected changes reported, got:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := ConfigMigrate(path, false, out); err != nil {
+		t.Fatalf("ConfigMigrate failed: %v", err)
+	}
+	cfg, loadErr := config.Load(path)
+	if loadErr != nil {
+		t.Fatalf("migrated config does not load: %v", loadErr)
+	}
+	if !cfg.ShouldRequireExplicitVaultUpgrade() || !cfg.ShouldRestrictToConfiguredVaults() {
+		t.Errorf("expected strict behavior settings, got %+v", cfg.Behavior)
+	}
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm() != 0o640 {
+		t.Errorf("config mode = %o, want 640", info.Mode().Perm())
+	}
+
+	stdout.Reset()
+	if err := ConfigMigrate(path, false, out); err != nil {
+		t.Fatalf("second ConfigMigrate failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "already current") {
+		t.Errorf("expected no further changes, got:\n%s", stdout.String())
+	}
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// doctorKeyDetails is shown when the selected key fails a round trip.
+const doctorKeyDetails = "Check that the key's smartcard or token is present and gpg-agent can reach it"
+
+// SetDoctorKeyCheck makes 'vault doctor' prove that the key for fingerprint
+// can sign and decrypt. An empty fingerprint checks the active identity.
+func (c *CLI) SetDoctorKeyCheck(fingerprint string) {
+	c.doctorKeyCheck = true
+	c.doctorKeyFingerprint = fingerprint
+}
+
+// doctorKeyChecks runs a sign and an encrypt-decrypt round trip with the
+// selected key and reports them as the can_sign and can_decrypt checks.
+// Only random challenges are used; no vault is read or written.
+func (c *CLI) doctorKeyChecks() []DoctorCheckJSON {
+	fp := c.doctorKeyFingerprint
+	if fp == "" {
+		fp = c.activeFingerprint()
+	}
+	failBoth := func(msg string) []DoctorCheckJSON {
+		return []DoctorCheckJSON{
+			{Name: "can_sign", Status: "error", Message: msg},
+			{Name: "can_decrypt", Status: "error", Message: msg},
+		}
+	}
+	if fp == "" {
+		return failBoth("no key to check: run `dotsecenv login FINGERPRINT` or pass --select=FINGERPRINT")
+	}
+	info, err := c.gpgClient.GetPublicKeyInfo(fp)
+	if err != nil {
+		return failBoth(fmt.Sprintf("key %s not found: %v", fp, err))
+	}
+
+	signCheck := DoctorCheckJSON{Name: "can_sign", Status: "ok", Message: fmt.Sprintf("key %s can sign", fp)}
+	if signErr := verifyKeyControl(c.gpgClient, info, fp); signErr != nil {
+		signCheck.Status = "error"
+		signCheck.Message = fmt.Sprintf("key %s cannot sign: %v", fp, signErr)
+		signCheck.Details = doctorKeyDetails
+	}
+
+	decryptCheck := DoctorCheckJSON{Name: "can_decrypt", Status: "ok", Message: fmt.Sprintf("key %s can decrypt", fp)}
+	if decErr := c.decryptRoundTrip(info.PublicKeyBase64, fp); decErr != nil {
+		decryptCheck.Status = "error"
+		decryptCheck.Message = fmt.Sprintf("key %s cannot decrypt: %v", fp, decErr)
+		decryptCheck.Details = doctorKeyDetails
+	}
+
+	return []DoctorCheckJSON{signCheck, decryptCheck}
+}
+
+// decryptRoundTrip encrypts a random challenge to publicKeyBase64 and checks
+// that the secret key for fp decrypts it back.
+func (c *CLI) decryptRoundTrip(publicKeyBase64, fp string) error {
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate challenge: %w", err)
+	}
+	challenge := []byte("dotsecenv-doctor-challenge:" + hex.EncodeToString(nonce))
+
+	armored, err := c.gpgClient.EncryptToRecipients(challenge, []string{publicKeyBase64}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt challenge: %w", err)
+	}
+	plaintext, err := c.gpgClient.DecryptWithAgent([]byte(armored), fp)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(plaintext, challenge) {
+		return fmt.Errorf("decrypted challenge does not match")
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/gpg"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+)
+
+// noSignClient is a MemoryClient whose key can decrypt but not sign, as when
+// gpg-agent runs but a signing smartcard is missing.
+type noSignClient struct {
+	*gpg.MemoryClient
+}
+
+func (n *noSignClient) SignDataWithAgent(fingerprint string, data []byte) (string, error) {
+	return "", errors.New("card not present")
+}
+
+// runDoctorKeyCheck runs 'vault doctor --json --select' for fingerprint and
+// returns the parsed result.
+func runDoctorKeyCheck(t *testing.T, client gpg.Client, login, fingerprint string) DoctorResultJSON {
+	t.Helper()
+	var stdout bytes.Buffer
+	cli := &CLI{
+		vaultResolver: NewMockVaultResolver(),
+		gpgClient:     client,
+		output:        output.NewHandler(&stdout, &bytes.Buffer{}),
+	}
+	if login != "" {
+		cli.config = config.Config{Login: &config.Login{Fingerprint: login}}
+	}
+	cli.SetDoctorKeyCheck(fingerprint)
+
+	if err := cli.VaultDoctor(true, false, "", 0); err != nil {
+		t.Fatalf("VaultDoctor failed: %v", err)
+	}
+	var result DoctorResultJSON
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	return result
+}
+
+func doctorCheck(t *testing.T, result DoctorResultJSON, name string) DoctorCheckJSON {
+	t.Helper()
+	for _, check := range result.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("no %s check in %+v", name, result.Checks)
+	return DoctorCheckJSON{}
+}
+
+func TestVaultDoctor_KeyCheckPasses(t *testing.T) {
+	client := gpg.NewMemoryClient()
+	fp, err := client.GenerateKey("Doctor", "doctor@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := runDoctorKeyCheck(t, client, fp, "")
+	if result.Status != "healthy" {
+		t.Errorf("status = %s, want healthy: %+v", result.Status, result.Checks)
+	}
+	for _, name := range []string{"can_sign", "can_decrypt"} {
+		if check := doctorCheck(t, result, name); check.Status != "ok" {
+			t.Errorf("%s = %+v, want ok", name, check)
+		}
+	}
+}
+
+func TestVaultDoctor_KeyCheckReportsSigningFailure(t *testing.T) {
+	client := &noSignClient{MemoryClient: gpg.NewMemoryClient()}
+	fp, err := client.GenerateKey("Doctor", "doctor@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := runDoctorKeyCheck(t, client, "", fp)
+	if result.Status != "error" {
+		t.Errorf("status = %s, want error", result.Status)
+	}
+	if check := doctorCheck(t, result, "can_sign"); check.Status != "error" {
+		t.Errorf("can_sign = %+v, want error", check)
+	}
+	if check := doctorCheck(t, result, "can_decrypt"); check.Status != "ok" {
+		t.Errorf("can_decrypt = %+v, want ok", check)
+	}
+}
+
+func TestVaultDoctor_KeyCheckWithoutIdentity(t *testing.T) {
+	result := runDoctorKeyCheck(t, gpg.NewMemoryClient(), "", "")
+	if check := doctorCheck(t, result, "can_sign"); check.Status != "error" {
+		t.Errorf("can_sign = %+v, want error", check)
+	}
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// keyserverTimeout bounds the keyserver reachability check in 'vault doctor'.
+var keyserverTimeout = 5 * time.Second
+
+// gnupgHome returns the GnuPG home directory: $GNUPGHOME, or ~/.gnupg.
+func gnupgHome() string {
+	if home := os.Getenv("GNUPGHOME"); home != "" {
+		return home
+	}
+	userHome, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(userHome, ".gnupg")
+}
+
+// configuredKeyserver returns the first keyserver set in dirmngr.conf, or
+// failing that gpg.conf, of the GnuPG home dir. It returns "" when neither
+// sets one.
+func configuredKeyserver(home string) string {
+	if home == "" {
+		return ""
+	}
+	for _, name := range []string{"dirmngr.conf", "gpg.conf"} {
+		f, err := os.Open(filepath.Join(home, name))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) >= 2 && fields[0] == "keyserver" {
+				_ = f.Close()
+				return fields[1]
+			}
+		}
+		_ = f.Close()
+	}
+	return ""
+}
+
+// keyserverURL maps a GnuPG keyserver address to the URL checked over HTTP.
+// hkps:// is served over https; hkp:// and bare host names over http on
+// port 11371 unless a port is given.
+func keyserverURL(keyserver string) (string, error) {
+	if !strings.Contains(keyserver, "://") {
+		keyserver = "hkp://" + keyserver
+	}
+	u, err := url.Parse(keyserver)
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "hkps":
+		u.Scheme = "https"
+	case "hkp":
+		u.Scheme = "http"
+		if u.Port() == "" {
+			u.Host = net.JoinHostPort(u.Hostname(), "11371")
+		}
+	case "http", "https":
+	default:
+		return "", fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("no host in %q", keyserver)
+	}
+	return u.String(), nil
+}
+
+// doctorKeyserverChecks sends a HEAD request to the keyserver GnuPG is
+// configured with and reports whether it answered, as the keyserver check.
+// Any HTTP response counts as reachable. Proxies are taken from the
+// environment. It returns no checks when no keyserver is configured.
+func (c *CLI) doctorKeyserverChecks() []DoctorCheckJSON {
+	keyserver := configuredKeyserver(gnupgHome())
+	if keyserver == "" {
+		return nil
+	}
+
+	invalid := func(err error) []DoctorCheckJSON {
+		return []DoctorCheckJSON{{
+			Name:    "keyserver",
+			Status:  "warning",
+			Message: fmt.Sprintf("keyserver %s is not a valid address", keyserver),
+			Details: err.Error(),
+		}}
+	}
+	target, err := keyserverURL(keyserver)
+	if err != nil {
+		return invalid(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), keyserverTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, target, nil)
+	if err != nil {
+		return invalid(err)
+	}
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyFromEnvironment}}
+	resp, err := client.Do(req)
+	if err != nil {
+		return []DoctorCheckJSON{{
+			Name:    "keyserver",
+			Status:  "warning",
+			Message: fmt.Sprintf("keyserver %s is not reachable", keyserver),
+			Details: err.Error(),
+		}}
+	}
+	_ = resp.Body.Close()
+	return []DoctorCheckJSON{{
+		Name:    "keyserver",
+		Status:  "ok",
+		Message: fmt.Sprintf("keyserver %s is reachable (%s)", keyserver, resp.Status),
+	}}
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withKeyserver points GNUPGHOME at a fresh dir whose dirmngr.conf sets
+// keyserver, unless it is empty.
+func withKeyserver(t *testing.T, keyserver string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("GNUPGHOME", home)
+	if keyserver == "" {
+		return
+	}
+	conf := "# dirmngr options\nkeyserver " + keyserver + "\n"
+	if err := os.WriteFile(filepath.Join(home, "dirmngr.conf"), []byte(conf), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDoctorKeyserverChecks_NotConfigured(t *testing.T) {
+	withKeyserver(t, "")
+	if checks := (&CLI{}).doctorKeyserverChecks(); len(checks) != 0 {
+		t.Errorf("expected no keyserver check, got %+v", checks)
+	}
+}
+
+func TestDoctorKeyserverChecks_Reachable(t *testing.T) {
+	var method string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	withKeyserver(t, server.URL)
+
+	checks := (&CLI{}).doctorKeyserverChecks()
+	if len(checks) != 1 || checks[0].Name != "keyserver" || checks[0].Status != "ok" {
+		t.Fatalf("expected an ok keyserver check, got %+v", checks)
+	}
+	if method != http.MethodHead {
+		t.Errorf("expected a HEAD request, got %q", method)
+	}
+}
+
+func TestDoctorKeyserverChecks_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+	withKeyserver(t, server.URL)
+
+	saved := keyserverTimeout
+	keyserverTimeout = 100 * time.Millisecond
+	defer func() { keyserverTimeout = saved }()
+
+	start := time.Now()
+	checks := (&CLI{}).doctorKeyserverChecks()
+	if len(checks) != 1 || checks[0].Status != "warning" || !strings.Contains(checks[0].Message, "not reachable") {
+		t.Fatalf("expected a not-reachable warning, got %+v", checks)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("check took %v, expected it to give up after the timeout", elapsed)
+	}
+}
+
+func TestKeyserverURL(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"hkps://keys.openpgp.org", "https://keys.openpgp.org"},
+		{"hkp://keyserver.ubuntu.com", "http://keyserver.ubuntu.com:11371"},
+		{"hkp://keyserver.ubuntu.com:80", "http://keyserver.ubuntu.com:80"},
+		{"keyserver.ubuntu.com", "http://keyserver.ubuntu.com:11371"},
+		{"https://keys.example.com/pks", "https://keys.example.com/pks"},
+	}
+	for _, tt := range tests {
+		got, err := keyserverURL(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("keyserverURL(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := keyserverURL("ldap://keys.example.com"); err == nil {
+		t.Error("expected an unsupported scheme to be rejected")
+	}
+}
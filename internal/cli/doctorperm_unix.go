@@ -0,0 +1,53 @@
+//go:build unix
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// doctorPermissionChecks warns about each vault file and the config file
+// that group or other can read or write. Files that do not exist are
+// skipped.
+func (c *CLI) doctorPermissionChecks() []DoctorCheckJSON {
+	var checks []DoctorCheckJSON
+	check := func(name, path string) {
+		info, err := os.Stat(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return
+		}
+		if err != nil {
+			checks = append(checks, DoctorCheckJSON{
+				Name:    name,
+				Status:  "error",
+				Message: fmt.Sprintf("%s: failed to check permissions", path),
+				Details: err.Error(),
+			})
+			return
+		}
+		if perm := info.Mode().Perm(); perm&0o077 != 0 {
+			checks = append(checks, DoctorCheckJSON{
+				Name:    name,
+				Status:  "warning",
+				Message: fmt.Sprintf("%s: mode %#o is accessible to group or other", path, perm),
+				Details: fmt.Sprintf("Run: chmod 600 %s", path),
+			})
+			return
+		}
+		checks = append(checks, DoctorCheckJSON{
+			Name:    name,
+			Status:  "ok",
+			Message: fmt.Sprintf("%s: permissions restricted to owner", path),
+		})
+	}
+
+	if c.configPath != "" {
+		check("config_permissions", c.configPath)
+	}
+	for i, entry := range c.vaultResolver.GetConfig().Entries {
+		check(fmt.Sprintf("vault_%d_permissions", i+1), entry.Path)
+	}
+	return checks
+}
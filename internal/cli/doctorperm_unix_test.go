@@ -0,0 +1,58 @@
+//go:build unix
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+func TestVaultDoctor_WarnsOnGroupReadableVault(t *testing.T) {
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, "private.vault")
+	sharedPath := filepath.Join(dir, "shared.vault")
+	if err := os.WriteFile(privatePath, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sharedPath, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// Set explicitly, since the umask may mask bits from WriteFile
+	if err := os.Chmod(sharedPath, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := NewMockVaultResolver()
+	mock.VaultEntries = []vault.VaultEntry{{Path: privatePath}, {Path: sharedPath}}
+	var stdout bytes.Buffer
+	cli := &CLI{
+		vaultResolver: mock,
+		gpgClient:     NewMockGPGClient(),
+		output:        output.NewHandler(&stdout, &bytes.Buffer{}),
+	}
+	if err := cli.VaultDoctor(true, false, "", 0); err != nil {
+		t.Fatalf("VaultDoctor failed: %v", err)
+	}
+	var result DoctorResultJSON
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+
+	if check := doctorCheck(t, result, "vault_1_permissions"); check.Status != "ok" {
+		t.Errorf("expected the 0600 vault to pass, got %+v", check)
+	}
+	check := doctorCheck(t, result, "vault_2_permissions")
+	if check.Status != "warning" || !strings.Contains(check.Message, "0644") || check.Details != "Run: chmod 600 "+sharedPath {
+		t.Errorf("expected a chmod 600 warning for the 0644 vault, got %+v", check)
+	}
+	if result.Status != "warning" {
+		t.Errorf("expected overall status warning, got %s", result.Status)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package cli
+
+// doctorPermissionChecks returns no checks on Windows, where file access is
+// controlled by ACLs rather than POSIX mode bits.
+func (c *CLI) doctorPermissionChecks() []DoctorCheckJSON {
+	return nil
+}
@@ -24,6 +24,7 @@ const (
 	ExitFingerprintRequired = output.ExitFingerprintRequired
 	ExitAccessDenied        = output.ExitAccessDenied
 	ExitAlgorithmNotAllowed = output.ExitAlgorithmNotAllowed
+	ExitVaultLocked         = output.ExitVaultLocked
 )
 
 // Error represents a CLI error with an exit code.
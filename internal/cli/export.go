@@ -0,0 +1,416 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dotsecenv/dotsecenv/internal/secenv"
+)
+
+// Export formats supported by SecretExport.
+const (
+	ExportFormatK8sSecret     = "k8s-secret"
+	ExportFormatGitHubActions = "github-actions"
+	ExportFormatTerraform     = "terraform"
+)
+
+// ExportFormats lists the supported export formats, for help and errors.
+var ExportFormats = []string{ExportFormatK8sSecret, ExportFormatGitHubActions, ExportFormatTerraform}
+
+// ExportOptions configures SecretExport.
+type ExportOptions struct {
+	Format string
+	// Name is the metadata.name of the generated Kubernetes Secret.
+	Name string
+	// EnvPrefix is prepended to each environment variable name in the
+	// github-actions format, e.g. APP_ turns DB_URL into APP_DB_URL.
+	EnvPrefix string
+}
+
+// exportEntry is a decrypted secret ready to be formatted.
+type exportEntry struct {
+	Key   string
+	Value []byte
+}
+
+var (
+	// k8sDataKeyInvalidChars matches characters not allowed in a Secret data key.
+	k8sDataKeyInvalidChars = regexp.MustCompile(`[^-._a-zA-Z0-9]`)
+	// k8sNamePattern matches an RFC 1123 DNS subdomain, as required for metadata.name.
+	k8sNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	// hclIdentifierPattern matches the lowercase HCL identifiers used as
+	// Terraform variable names.
+	hclIdentifierPattern = regexp.MustCompile(`^[a-z_][a-z0-9_-]*$`)
+	// shellIdentifierPattern matches a POSIX shell variable name.
+	shellIdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// k8sMaxKeyLength is the maximum length of a Secret data key and of metadata.name.
+const k8sMaxKeyLength = 253
+
+// SecretExport decrypts every secret the current identity can read and prints
+// them in the requested format. Without -v, each key resolves exactly as
+// 'secret get KEY' does; with -v, only that vault is exported. Secrets the
+// identity cannot read are left out.
+func (c *CLI) SecretExport(opts ExportOptions, vaultPath string, fromIndex int) *Error {
+	if optErr := validateExportOptions(opts); optErr != nil {
+		return optErr
+	}
+
+	// Printing values is the purpose of secret export, so it is exempt from
+	// the --redact-stdout guard.
+	c.output.AllowSecretValues()
+
+	fp, err := c.checkFingerprintRequired("secret export")
+	if err != nil {
+		return err
+	}
+
+	targetIndex, resolveErr := c.resolveReadableVaultIndex(vaultPath, fromIndex)
+	if resolveErr != nil {
+		return resolveErr
+	}
+
+	entries, collectErr := c.collectExportEntries(fp, targetIndex, false)
+	if collectErr != nil {
+		return collectErr
+	}
+	defer func() {
+		for _, e := range entries {
+			clear(e.Value)
+		}
+	}()
+
+	var buf bytes.Buffer
+	defer func() { clear(buf.Bytes()) }()
+
+	switch opts.Format {
+	case ExportFormatK8sSecret:
+		c.formatK8sSecret(&buf, opts.Name, entries)
+	case ExportFormatGitHubActions:
+		var env bytes.Buffer
+		defer func() { clear(env.Bytes()) }()
+		if fmtErr := c.formatGitHubActions(&buf, &env, opts.EnvPrefix, entries); fmtErr != nil {
+			return fmtErr
+		}
+		return c.writeGitHubActionsExport(buf.Bytes(), env.Bytes())
+	case ExportFormatTerraform:
+		c.formatTerraform(&buf, entries)
+	}
+
+	if writeErr := c.output.WriteSecretValue(buf.Bytes()); writeErr != nil {
+		return NewError(writeErr.Error(), ExitGeneralError)
+	}
+	return nil
+}
+
+// validateExportOptions checks the format and its options before anything is
+// decrypted.
+func validateExportOptions(opts ExportOptions) *Error {
+	if opts.EnvPrefix != "" {
+		if opts.Format != ExportFormatGitHubActions {
+			return NewError("--env-prefix is only used with --format github-actions", ExitValidationError)
+		}
+		// Any valid name stays valid with a valid prefix in front
+		if !shellIdentifierPattern.MatchString(opts.EnvPrefix) {
+			return NewError(fmt.Sprintf("invalid --env-prefix %q: must start with a letter or underscore and contain only letters, digits and underscores", opts.EnvPrefix), ExitValidationError)
+		}
+	}
+	switch opts.Format {
+	case ExportFormatK8sSecret:
+		if opts.Name == "" {
+			return NewError("--name is required for --format k8s-secret", ExitValidationError)
+		}
+		if len(opts.Name) > k8sMaxKeyLength || !k8sNamePattern.MatchString(opts.Name) {
+			return NewError(fmt.Sprintf("invalid Kubernetes Secret name %q: must be a lowercase RFC 1123 subdomain", opts.Name), ExitValidationError)
+		}
+		return nil
+	case ExportFormatGitHubActions, ExportFormatTerraform:
+		if opts.Name != "" {
+			return NewError("--name is only used with --format k8s-secret", ExitValidationError)
+		}
+		return nil
+	case "":
+		return NewError(fmt.Sprintf("--format is required (one of: %s)", strings.Join(ExportFormats, ", ")), ExitValidationError)
+	default:
+		return NewError(fmt.Sprintf("unknown export format %q (one of: %s)", opts.Format, strings.Join(ExportFormats, ", ")), ExitValidationError)
+	}
+}
+
+// collectExportEntries decrypts the readable secrets of the vault at index, or
+// of all vaults when index is -1, sorted by key. With warnSkipped, deleted
+// and unreadable secrets are reported on stderr.
+func (c *CLI) collectExportEntries(fp string, index int, warnSkipped bool) ([]exportEntry, *Error) {
+	infos := c.vaultResolver.ListAllSecretKeys()
+	if index >= 0 {
+		infos = c.vaultResolver.ListSecretKeysFromVault(index)
+	}
+	var keys []string
+	for _, info := range infos {
+		if info.Deleted {
+			if warnSkipped {
+				c.Warnf("skipped '%s': secret is deleted", info.Key)
+			}
+			continue
+		}
+		keys = append(keys, info.Key)
+	}
+	sort.Strings(keys)
+
+	progress := c.newProgress("decrypted", "secrets", len(keys))
+	defer progress.Finish()
+
+	var entries []exportEntry
+	for _, key := range keys {
+		value, lookupErr := c.readableSecretValue(key, fp, index)
+		if lookupErr != nil {
+			if lookupErr.ExitCode == ExitAccessDenied {
+				if warnSkipped {
+					c.Warnf("skipped '%s': no value is readable by %s", key, fp)
+				}
+				progress.Increment()
+				continue
+			}
+			return entries, lookupErr
+		}
+
+		if detErr := detachedValueError(key, value); detErr != nil {
+			return entries, detErr
+		}
+		encryptedArmored, decodeErr := base64.StdEncoding.DecodeString(value.Value)
+		if decodeErr != nil {
+			return entries, NewError(fmt.Sprintf("failed to decode encrypted value of '%s': %v", key, decodeErr), ExitGeneralError)
+		}
+		plaintext, decErr := c.gpgClient.DecryptWithAgent(encryptedArmored, fp)
+		if decErr != nil {
+			return entries, NewError(fmt.Sprintf("failed to decrypt secret '%s': %v", key, decErr), ExitGPGError)
+		}
+		plaintext, inflateErr := c.inflateValue(value, plaintext)
+		if inflateErr != nil {
+			return entries, inflateErr
+		}
+		entries = append(entries, exportEntry{Key: key, Value: plaintext})
+		progress.Increment()
+	}
+	return entries, nil
+}
+
+// formatK8sSecret writes a Kubernetes Secret manifest holding entries as
+// base64-encoded data. Keys that cannot be mapped to a valid data key are
+// reported on stderr and left out.
+func (c *CLI) formatK8sSecret(buf *bytes.Buffer, name string, entries []exportEntry) {
+	type dataItem struct{ key, value string }
+	var data []dataItem
+	mappedFrom := make(map[string]string)
+
+	for _, e := range entries {
+		dataKey, ok := k8sDataKey(e.Key)
+		if !ok {
+			c.Warnf("skipped '%s': cannot be mapped to a valid Kubernetes data key", e.Key)
+			continue
+		}
+		if other, taken := mappedFrom[dataKey]; taken {
+			c.Warnf("skipped '%s': Kubernetes data key '%s' is already used by '%s'", e.Key, dataKey, other)
+			continue
+		}
+		mappedFrom[dataKey] = e.Key
+		data = append(data, dataItem{dataKey, base64.StdEncoding.EncodeToString(e.Value)})
+	}
+
+	buf.WriteString("apiVersion: v1\n")
+	buf.WriteString("kind: Secret\n")
+	buf.WriteString("metadata:\n")
+	fmt.Fprintf(buf, "  name: %q\n", name)
+	buf.WriteString("type: Opaque\n")
+	if len(data) == 0 {
+		buf.WriteString("data: {}\n")
+		return
+	}
+	// Keys are quoted so that names such as TRUE or 1.0 stay strings.
+	buf.WriteString("data:\n")
+	for _, d := range data {
+		fmt.Fprintf(buf, "  %q: %s\n", d.key, d.value)
+	}
+}
+
+// k8sDataKey maps a secret key to a Kubernetes Secret data key. The namespace
+// separator becomes a dot and any other disallowed character an underscore.
+func k8sDataKey(secretKey string) (string, bool) {
+	key := strings.ReplaceAll(secretKey, "::", ".")
+	key = k8sDataKeyInvalidChars.ReplaceAllString(key, "_")
+	if key == "" || key == "." || key == ".." || len(key) > k8sMaxKeyLength {
+		return "", false
+	}
+	return key, true
+}
+
+// formatGitHubActions writes an ::add-mask:: workflow command for every line
+// of every value to masks, and the matching environment assignments to env in
+// the multiline NAME<<DELIMITER form that $GITHUB_ENV accepts. Env names are
+// derived as for .secenv (prod::DB_PASSWORD becomes PROD_DB_PASSWORD), with
+// envPrefix in front. Keys whose name is not a valid shell identifier are
+// reported on stderr and skipped.
+func (c *CLI) formatGitHubActions(masks, env *bytes.Buffer, envPrefix string, entries []exportEntry) *Error {
+	mappedFrom := make(map[string]string)
+
+	for _, e := range entries {
+		ref, err := secenv.DeriveRef(e.Key)
+		if err != nil {
+			c.Warnf("skipped '%s': cannot be mapped to an environment variable name: %v", e.Key, err)
+			continue
+		}
+		envName := envPrefix + ref.EnvName
+		if !shellIdentifierPattern.MatchString(envName) {
+			c.Warnf("skipped '%s': '%s' is not a valid environment variable name", e.Key, envName)
+			continue
+		}
+		if other, taken := mappedFrom[envName]; taken {
+			c.Warnf("skipped '%s': environment variable '%s' is already used by '%s'", e.Key, envName, other)
+			continue
+		}
+		mappedFrom[envName] = e.Key
+
+		// The runner masks line by line, so a multiline value needs one mask
+		// per line to be hidden wherever any part of it is echoed.
+		for _, line := range strings.Split(string(e.Value), "\n") {
+			line = strings.TrimSuffix(line, "\r")
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			masks.WriteString("::add-mask::")
+			masks.WriteString(escapeWorkflowCommandData(line))
+			masks.WriteString("\n")
+		}
+
+		delimiter, delimErr := githubEnvDelimiter(e.Value)
+		if delimErr != nil {
+			return NewError(fmt.Sprintf("failed to generate delimiter for '%s': %v", e.Key, delimErr), ExitGeneralError)
+		}
+		fmt.Fprintf(env, "%s<<%s\n", envName, delimiter)
+		env.Write(e.Value)
+		fmt.Fprintf(env, "\n%s\n", delimiter)
+	}
+	return nil
+}
+
+// writeGitHubActionsExport prints the masks, then the assignments. Inside
+// GitHub Actions the assignments are appended to $GITHUB_ENV instead, so they
+// never reach the log; elsewhere they follow the masks on stdout.
+func (c *CLI) writeGitHubActionsExport(masks, env []byte) *Error {
+	if !isGitHubActions() {
+		c.Warnf("not running in GitHub Actions (GITHUB_ACTIONS is unset); ::add-mask:: lines will not mask anything")
+	}
+
+	// Masks go out first so the runner knows every value before it can be
+	// echoed by anything that follows.
+	if writeErr := c.output.WriteSecretValue(masks); writeErr != nil {
+		return NewError(writeErr.Error(), ExitGeneralError)
+	}
+
+	envPath := os.Getenv("GITHUB_ENV")
+	if !isGitHubActions() || envPath == "" {
+		if writeErr := c.output.WriteSecretValue(env); writeErr != nil {
+			return NewError(writeErr.Error(), ExitGeneralError)
+		}
+		return nil
+	}
+
+	f, openErr := os.OpenFile(envPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if openErr != nil {
+		return NewError(fmt.Sprintf("failed to open $GITHUB_ENV: %v", openErr), ExitGeneralError)
+	}
+	if _, writeErr := f.Write(env); writeErr != nil {
+		_ = f.Close()
+		return NewError(fmt.Sprintf("failed to write $GITHUB_ENV: %v", writeErr), ExitGeneralError)
+	}
+	if closeErr := f.Close(); closeErr != nil {
+		return NewError(fmt.Sprintf("failed to write $GITHUB_ENV: %v", closeErr), ExitGeneralError)
+	}
+	return nil
+}
+
+// formatTerraform writes entries as .tfvars assignments. Variable names are
+// the lowercased secret keys (namespace::KEY becomes namespace_key); keys
+// that are not valid HCL identifiers are reported on stderr and skipped.
+func (c *CLI) formatTerraform(buf *bytes.Buffer, entries []exportEntry) {
+	mappedFrom := make(map[string]string)
+
+	for _, e := range entries {
+		name := strings.ToLower(strings.ReplaceAll(e.Key, "::", "_"))
+		if !hclIdentifierPattern.MatchString(name) {
+			c.Warnf("skipped '%s': cannot be mapped to a valid Terraform variable name", e.Key)
+			continue
+		}
+		if other, taken := mappedFrom[name]; taken {
+			c.Warnf("skipped '%s': Terraform variable '%s' is already used by '%s'", e.Key, name, other)
+			continue
+		}
+		mappedFrom[name] = e.Key
+
+		buf.WriteString(name)
+		buf.WriteString(" = ")
+		writeHCLString(buf, e.Value)
+		buf.WriteString("\n")
+	}
+}
+
+// writeHCLString writes value as a quoted HCL string. Besides the usual
+// escapes, template sequences are doubled so "${" and "%{" stay literal.
+func writeHCLString(buf *bytes.Buffer, value []byte) {
+	buf.WriteByte('"')
+	for i := 0; i < len(value); {
+		r, size := utf8.DecodeRune(value[i:])
+		i += size
+		switch {
+		case r == '"':
+			buf.WriteString(`\"`)
+		case r == '\\':
+			buf.WriteString(`\\`)
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\r':
+			buf.WriteString(`\r`)
+		case r == '\t':
+			buf.WriteString(`\t`)
+		case (r == '$' || r == '%') && bytes.HasPrefix(value[i:], []byte("{")):
+			buf.WriteRune(r)
+			buf.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(buf, `\u%04X`, r)
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// escapeWorkflowCommandData escapes a value for the data part of a workflow
+// command, as the runner's own toolkit does.
+func escapeWorkflowCommandData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// githubEnvDelimiter returns a random heredoc delimiter that does not occur in
+// value, so a value cannot end its own assignment early.
+func githubEnvDelimiter(value []byte) (string, error) {
+	for {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		delimiter := "ghadelimiter_" + hex.EncodeToString(b)
+		if !bytes.Contains(value, []byte(delimiter)) {
+			return delimiter, nil
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/dotsecenv/dotsecenv/internal/awssm"
+)
+
+// SecretExportAWS decrypts every secret the current identity can read and
+// pushes each to AWS Secrets Manager under prefix (see awssm.SecretName).
+// Secrets are resolved as in SecretExport. Every secret is attempted;
+// failures are reported on stderr and make the command fail.
+func (c *CLI) SecretExportAWS(client awssm.Client, prefix string, vaultPath string, fromIndex int) *Error {
+	fp, err := c.checkFingerprintRequired("export aws")
+	if err != nil {
+		return err
+	}
+
+	targetIndex, resolveErr := c.resolveReadableVaultIndex(vaultPath, fromIndex)
+	if resolveErr != nil {
+		return resolveErr
+	}
+
+	entries, collectErr := c.collectExportEntries(fp, targetIndex, false)
+	defer func() {
+		for _, e := range entries {
+			clear(e.Value)
+		}
+	}()
+	if collectErr != nil {
+		return collectErr
+	}
+	if len(entries) == 0 {
+		return NewError("no readable secrets to export", ExitVaultError)
+	}
+
+	progress := c.newProgress("exported", "secrets", len(entries))
+	var failed int
+	for _, e := range entries {
+		name := awssm.SecretName(prefix, e.Key)
+		putErr := client.PutSecretString(name, string(e.Value))
+		progress.Increment()
+		if putErr != nil {
+			failed++
+			_, _ = fmt.Fprintf(c.output.Stderr(), "failed: %s: %v\n", e.Key, putErr)
+			continue
+		}
+		_, _ = fmt.Fprintf(c.output.Stdout(), "Secret '%s' exported to %s\n", e.Key, name)
+	}
+
+	_, _ = fmt.Fprintf(c.output.Stderr(), "summary: exported=%d failed=%d\n", len(entries)-failed, failed)
+	if failed > 0 {
+		return NewError(fmt.Sprintf("%d of %d secrets failed to export", failed, len(entries)), ExitGeneralError)
+	}
+	return nil
+}
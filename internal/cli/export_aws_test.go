@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// mockSMClient records the secrets put into it and fails for names in fail.
+type mockSMClient struct {
+	puts map[string]string
+	fail map[string]bool
+}
+
+func (m *mockSMClient) GetSecretString(secretID string) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (m *mockSMClient) PutSecretString(name, value string) error {
+	if m.fail[name] {
+		return errors.New("AccessDeniedException")
+	}
+	if m.puts == nil {
+		m.puts = make(map[string]string)
+	}
+	m.puts[name] = value
+	return nil
+}
+
+func TestSecretExportAWS(t *testing.T) {
+	cli, stdout, _ := newExportCLI(t, map[string]vault.Secret{
+		"DB_URL":      exportTestSecret("DB_URL", "postgres://db", "ME"),
+		"prod::API":   exportTestSecret("prod::API", "k", "ME"),
+		"OTHERS_ONLY": exportTestSecret("OTHERS_ONLY", "hidden", "SOMEONE"),
+	})
+	client := &mockSMClient{}
+
+	if err := cli.SecretExportAWS(client, "myapp/", "", 0); err != nil {
+		t.Fatalf("SecretExportAWS failed: %v", err)
+	}
+	if len(client.puts) != 2 || client.puts["myapp/DB_URL"] != "postgres://db" || client.puts["myapp/prod/API"] != "k" {
+		t.Errorf("unexpected puts: %v", client.puts)
+	}
+	if !strings.Contains(stdout.String(), "Secret 'prod::API' exported to myapp/prod/API") {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+}
+
+func TestSecretExportAWS_ReportsFailures(t *testing.T) {
+	cli, _, stderr := newExportCLI(t, map[string]vault.Secret{
+		"A": exportTestSecret("A", "1", "ME"),
+		"B": exportTestSecret("B", "2", "ME"),
+	})
+	client := &mockSMClient{fail: map[string]bool{"p/A": true}}
+
+	err := cli.SecretExportAWS(client, "p/", "", 0)
+	if err == nil {
+		t.Fatal("expected an error when a put fails")
+	}
+	if client.puts["p/B"] != "2" {
+		t.Errorf("expected B to be exported despite A failing, got %v", client.puts)
+	}
+	if !strings.Contains(stderr.String(), "failed: A: AccessDeniedException") ||
+		!strings.Contains(stderr.String(), "summary: exported=1 failed=1") {
+		t.Errorf("unexpected stderr: %s", stderr.String())
+	}
+}
+
+func TestSecretExportAWS_NothingReadable(t *testing.T) {
+	cli, _, _ := newExportCLI(t, map[string]vault.Secret{
+		"OTHERS_ONLY": exportTestSecret("OTHERS_ONLY", "hidden", "SOMEONE"),
+	})
+	if err := cli.SecretExportAWS(&mockSMClient{}, "p/", "", 0); err == nil {
+		t.Fatal("expected an error when no secret is readable")
+	}
+}
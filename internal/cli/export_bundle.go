@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/gpg"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
+)
+
+// SignatureBundleVersion is the version of the bundle written by
+// SecretExportSignatures.
+const SignatureBundleVersion = 1
+
+// SignatureBundle is the output of 'secret export --include-signatures': the
+// signature metadata of secret values, enough to check them without the
+// vault. It holds no plaintext and no ciphertext.
+type SignatureBundle struct {
+	Version   int                 `json:"version"`
+	CreatedAt time.Time           `json:"created_at"`
+	Values    []BundleSecretValue `json:"values"`
+}
+
+// BundleSecretValue is one secret value in a SignatureBundle. Signature is
+// the hex-encoded detached signature of Hash by SignedBy, whose public key
+// (base64, as stored in the vault) is SignerPublicKey.
+type BundleSecretValue struct {
+	Position        int       `json:"position"`
+	Vault           string    `json:"vault"`
+	Key             string    `json:"key"`
+	Index           int       `json:"index"`
+	AddedAt         time.Time `json:"added_at"`
+	Deleted         bool      `json:"deleted,omitempty"`
+	Hash            string    `json:"hash"`
+	Signature       string    `json:"signature"`
+	SignedBy        string    `json:"signed_by"`
+	SignerPublicKey string    `json:"signer_public_key"`
+}
+
+// SecretExportSignatures prints a SignatureBundle of every secret value in
+// the configured vaults, or in the vault at vaultPath or fromIndex, so the
+// signatures can be verified elsewhere with 'vault verify-bundle'. Nothing
+// is decrypted, so no fingerprint is required.
+func (c *CLI) SecretExportSignatures(vaultPath string, fromIndex int) *Error {
+	targetIndex, resolveErr := c.resolveReadableVaultIndex(vaultPath, fromIndex)
+	if resolveErr != nil {
+		return resolveErr
+	}
+
+	bundle := SignatureBundle{
+		Version:   SignatureBundleVersion,
+		CreatedAt: time.Now().UTC(),
+		Values:    []BundleSecretValue{},
+	}
+	for i, entry := range c.vaultResolver.GetConfig().Entries {
+		if targetIndex != -1 && targetIndex != i {
+			continue
+		}
+		manager := c.describeManager(i, entry)
+		if manager == nil {
+			continue
+		}
+		vaultData := manager.Get()
+		for _, secret := range vaultData.Secrets {
+			for j, value := range secret.Values {
+				item := BundleSecretValue{
+					Position:  i + 1,
+					Vault:     entry.Path,
+					Key:       secret.Key,
+					Index:     j,
+					AddedAt:   value.AddedAt,
+					Deleted:   value.Deleted,
+					Hash:      value.Hash,
+					Signature: value.Signature,
+					SignedBy:  value.SignedBy,
+				}
+				if signer := vaultData.GetIdentityByFingerprint(value.SignedBy); signer != nil {
+					item.SignerPublicKey = signer.PublicKey
+				} else {
+					c.Warnf("signer %s of secret '%s' value[%d] is not in vault %d; its public key is left out", value.SignedBy, secret.Key, j, i+1)
+				}
+				bundle.Values = append(bundle.Values, item)
+			}
+		}
+	}
+
+	if err := c.output.EncodeJSON(bundle); err != nil {
+		return NewError(fmt.Sprintf("failed to encode json: %v", err), ExitGeneralError)
+	}
+	return nil
+}
+
+// VerifyBundle checks every value in the SignatureBundle at path: that the
+// signer's public key has the SignedBy fingerprint and that Signature is its
+// signature of Hash. It reports each value as 'vault verify' does and fails
+// if any value does not verify.
+func (c *CLI) VerifyBundle(path string) *Error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return NewError(fmt.Sprintf("failed to read bundle: %v", err), ExitGeneralError)
+	}
+	var bundle SignatureBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return NewError(fmt.Sprintf("failed to parse bundle: %v", err), ExitValidationError)
+	}
+	if bundle.Version != SignatureBundleVersion {
+		return NewError(fmt.Sprintf("unsupported bundle version %d (expected %d)", bundle.Version, SignatureBundleVersion), ExitValidationError)
+	}
+	if len(bundle.Values) == 0 {
+		return NewError("bundle holds no secret values to verify", ExitValidationError)
+	}
+
+	out := c.output.Stdout()
+	var verified, failed int
+	for _, value := range bundle.Values {
+		label := fmt.Sprintf("vault %d (%s) secret %s value[%d]", value.Position, value.Vault, value.Key, value.Index)
+		if value.Deleted {
+			label += " (deletion)"
+		}
+		if err := verifyBundleValue(value); err != nil {
+			_, _ = fmt.Fprintf(out, "FAILED: %s: %v\n", label, err)
+			failed++
+		} else {
+			_, _ = fmt.Fprintf(out, "ok: %s\n", label)
+			verified++
+		}
+	}
+
+	_, _ = fmt.Fprintf(out, "summary: ok=%d failed=%d\n", verified, failed)
+	if failed > 0 {
+		return NewError(fmt.Sprintf("%d bundle value(s) failed verification", failed), ExitValidationError)
+	}
+	return nil
+}
+
+// verifyBundleValue checks that value's signer key matches its SignedBy
+// fingerprint and signed its hash.
+func verifyBundleValue(value BundleSecretValue) error {
+	if value.SignerPublicKey == "" {
+		return fmt.Errorf("no public key for signer %s", value.SignedBy)
+	}
+	keyFP, err := gpg.GetKeyFingerprint(value.SignerPublicKey)
+	if err != nil {
+		return fmt.Errorf("failed to read signer key: %w", err)
+	}
+	if !identity.CompareFingerprints(keyFP, value.SignedBy) {
+		return fmt.Errorf("signer key is %s, not %s", keyFP, value.SignedBy)
+	}
+	valid, err := verifySignatureWithPublicKey(value.SignerPublicKey, []byte(value.Hash), value.Signature)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return fmt.Errorf("signature does not match %s's key", value.SignedBy)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vaulttest"
+)
+
+// exportSignatureBundle exports the signature bundle of a signed vault
+// holding two values of DB_PASSWORD and returns it with the vault.
+func exportSignatureBundle(t *testing.T) (SignatureBundle, []byte, vault.Vault) {
+	t.Helper()
+	alice, err := vaulttest.NewIdentity("Alice", "alice@example.com")
+	if err != nil {
+		t.Fatalf("NewIdentity failed: %v", err)
+	}
+	v, err := vaulttest.BuildSignedVault([]*vaulttest.Identity{alice}, []vault.Secret{
+		{Key: "DB_PASSWORD", Values: []vault.SecretValue{
+			{AvailableTo: []string{alice.Fingerprint}, Value: "Y2lwaGVyMQ=="},
+			{AvailableTo: []string{alice.Fingerprint}, Value: "Y2lwaGVyMg=="},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("BuildSignedVault failed: %v", err)
+	}
+	manager := newTestManager(t, v)
+
+	mock := NewMockVaultResolver()
+	mock.VaultEntries = []vault.VaultEntry{{Path: manager.Path()}}
+	mock.Managers = map[int]*vault.Manager{0: manager}
+
+	stdout := &bytes.Buffer{}
+	cli := &CLI{
+		vaultResolver: mock,
+		output:        output.NewHandler(stdout, &bytes.Buffer{}),
+	}
+	if err := cli.SecretExportSignatures("", 0); err != nil {
+		t.Fatalf("SecretExportSignatures failed: %v", err)
+	}
+
+	var bundle SignatureBundle
+	if err := json.Unmarshal(stdout.Bytes(), &bundle); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout.String())
+	}
+	return bundle, stdout.Bytes(), manager.Get()
+}
+
+func TestSecretExportSignatures_SignaturesVerify(t *testing.T) {
+	bundle, raw, v := exportSignatureBundle(t)
+
+	if bundle.Version != SignatureBundleVersion || len(bundle.Values) != 2 {
+		t.Fatalf("unexpected bundle: %+v", bundle)
+	}
+	for i, item := range bundle.Values {
+		stored := v.Secrets[0].Values[i]
+		if item.Key != "DB_PASSWORD" || item.Index != i || item.Hash != stored.Hash || item.SignedBy != stored.SignedBy {
+			t.Errorf("value %d: unexpected metadata: %+v", i, item)
+		}
+		if item.SignerPublicKey != v.Identities[0].PublicKey {
+			t.Errorf("value %d: expected the signer's public key", i)
+		}
+		valid, err := verifySignatureWithPublicKey(item.SignerPublicKey, []byte(item.Hash), item.Signature)
+		if err != nil || !valid {
+			t.Errorf("value %d: exported signature does not verify: valid=%v err=%v", i, valid, err)
+		}
+		// Metadata only: no ciphertext is exported
+		if strings.Contains(string(raw), stored.Value) {
+			t.Errorf("value %d: bundle contains the encrypted value", i)
+		}
+	}
+}
+
+func TestVerifyBundle(t *testing.T) {
+	bundle, raw, _ := exportSignatureBundle(t)
+	dir := t.TempDir()
+
+	verify := func(data []byte) (string, *Error) {
+		path := filepath.Join(dir, "bundle.json")
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatalf("failed to write bundle: %v", err)
+		}
+		stdout := &bytes.Buffer{}
+		cli := &CLI{output: output.NewHandler(stdout, &bytes.Buffer{})}
+		err := cli.VerifyBundle(path)
+		return stdout.String(), err
+	}
+
+	out, err := verify(raw)
+	if err != nil {
+		t.Fatalf("VerifyBundle failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "summary: ok=2 failed=0") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	// A hash changed after export no longer matches its signature
+	bundle.Values[1].Hash = bundle.Values[0].Hash
+	tampered, marshalErr := json.Marshal(bundle)
+	if marshalErr != nil {
+		t.Fatalf("failed to marshal bundle: %v", marshalErr)
+	}
+	out, err = verify(tampered)
+	if err == nil || err.ExitCode != ExitValidationError {
+		t.Fatalf("expected a validation error, got %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "FAILED: vault 1") || !strings.Contains(out, "value[1]") || !strings.Contains(out, "summary: ok=1 failed=1") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestVerifyBundle_SignedByMustMatchKey(t *testing.T) {
+	bundle, _, _ := exportSignatureBundle(t)
+	for i := range bundle.Values {
+		bundle.Values[i].SignedBy = "0000000000000000000000000000000000000000"
+	}
+
+	if err := verifyBundleValue(bundle.Values[0]); err == nil || !strings.Contains(err.Error(), "signer key is") {
+		t.Errorf("expected a signer mismatch, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FilenameTemplateKey is the placeholder in --filename-template replaced by
+// the file name derived from a secret key.
+const FilenameTemplateKey = "{key}"
+
+// DirExportOptions configures SecretExportDir.
+type DirExportOptions struct {
+	Dir string
+	// Lowercase lowercases the file name derived from each key.
+	Lowercase bool
+	// FilenameTemplate shapes each file name, with FilenameTemplateKey
+	// standing for the derived name. Empty means the derived name alone.
+	FilenameTemplate string
+}
+
+// SecretExportDir decrypts every secret the current identity can read and
+// writes each value to its own file in opts.Dir, as Docker and Kubernetes
+// secret mounts expect. Secrets are resolved as in SecretExport; deleted and
+// unreadable ones are reported on stderr and skipped.
+//
+// File names are the secret keys, with namespace::KEY becoming namespace.KEY.
+// Each file is written with mode 0600 next to its final path and renamed
+// into place, so readers never see a partial value.
+func (c *CLI) SecretExportDir(opts DirExportOptions, vaultPath string, fromIndex int) *Error {
+	if opts.Dir == "" {
+		return NewError("--output-dir must not be empty", ExitValidationError)
+	}
+	if opts.FilenameTemplate != "" && !strings.Contains(opts.FilenameTemplate, FilenameTemplateKey) {
+		return NewError(fmt.Sprintf("--filename-template must contain %s", FilenameTemplateKey), ExitValidationError)
+	}
+
+	fp, err := c.checkFingerprintRequired("secret export")
+	if err != nil {
+		return err
+	}
+
+	targetIndex, resolveErr := c.resolveReadableVaultIndex(vaultPath, fromIndex)
+	if resolveErr != nil {
+		return resolveErr
+	}
+
+	entries, collectErr := c.collectExportEntries(fp, targetIndex, true)
+	defer func() {
+		for _, e := range entries {
+			clear(e.Value)
+		}
+	}()
+	if collectErr != nil {
+		return collectErr
+	}
+
+	if mkErr := os.MkdirAll(opts.Dir, 0o700); mkErr != nil {
+		return NewError(fmt.Sprintf("failed to create output directory: %v", mkErr), ExitGeneralError)
+	}
+
+	mappedFrom := make(map[string]string)
+	var written int
+	for _, e := range entries {
+		name, ok := exportFileName(e.Key, opts)
+		if !ok {
+			c.Warnf("skipped '%s': '%s' is not a valid file name", e.Key, name)
+			continue
+		}
+		if other, taken := mappedFrom[name]; taken {
+			c.Warnf("skipped '%s': file name '%s' is already used by '%s'", e.Key, name, other)
+			continue
+		}
+		mappedFrom[name] = e.Key
+
+		path := filepath.Join(opts.Dir, name)
+		if writeErr := writeFileAtomic(path, e.Value); writeErr != nil {
+			return NewError(fmt.Sprintf("failed to write '%s': %v", path, writeErr), ExitGeneralError)
+		}
+		_, _ = fmt.Fprintf(c.output.Stdout(), "Secret '%s' written to %s\n", e.Key, path)
+		written++
+	}
+
+	if written == 0 {
+		return NewError("no readable secrets to export", ExitVaultError)
+	}
+	return nil
+}
+
+// exportFileName returns the file name for key under opts, and whether it
+// is a plain file name that stays inside the output directory.
+func exportFileName(key string, opts DirExportOptions) (string, bool) {
+	name := strings.ReplaceAll(key, "::", ".")
+	if opts.Lowercase {
+		name = strings.ToLower(name)
+	}
+	if opts.FilenameTemplate != "" {
+		name = strings.ReplaceAll(opts.FilenameTemplate, FilenameTemplateKey, name)
+	}
+	valid := name != "" && name != "." && name != ".." &&
+		!strings.ContainsAny(name, `/\`) && name == filepath.Base(name)
+	return name, valid
+}
+
+// writeFileAtomic writes data to path with mode 0600 through a temporary
+// file in the same directory that is renamed into place.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tmp.Close()
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	if err := tmp.Chmod(0600); err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+func readExportDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read output dir: %v", err)
+	}
+	files := make(map[string]string)
+	for _, de := range dirEntries {
+		path := filepath.Join(dir, de.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", path, err)
+		}
+		files[de.Name()] = string(data)
+
+		if runtime.GOOS != "windows" {
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("failed to stat %s: %v", path, err)
+			}
+			if mode := info.Mode().Perm(); mode != 0600 {
+				t.Errorf("%s has mode %o, want 600", de.Name(), mode)
+			}
+		}
+	}
+	return files
+}
+
+func TestSecretExportDir(t *testing.T) {
+	cli, stdout, stderr := newExportCLI(t, map[string]vault.Secret{
+		"DB_URL":       exportTestSecret("DB_URL", "postgres://db", "ME"),
+		"app::API_KEY": exportTestSecret("app::API_KEY", "line1\nline2\n", "ME"),
+		"OTHERS_ONLY":  exportTestSecret("OTHERS_ONLY", "hidden", "SOMEONE"),
+		"GONE":         {Key: "GONE", Values: []vault.SecretValue{{AvailableTo: []string{"ME"}, Value: "eA=="}, {Deleted: true}}},
+	})
+	dir := filepath.Join(t.TempDir(), "secrets")
+
+	if err := cli.SecretExportDir(DirExportOptions{Dir: dir}, "", 0); err != nil {
+		t.Fatalf("SecretExportDir failed: %v", err)
+	}
+
+	files := readExportDir(t, dir)
+	want := map[string]string{
+		"DB_URL":      "postgres://db",
+		"app.API_KEY": "line1\nline2\n",
+	}
+	if len(files) != len(want) {
+		names := make([]string, 0, len(files))
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		t.Fatalf("files = %v, want %d files", names, len(want))
+	}
+	for name, value := range want {
+		if files[name] != value {
+			t.Errorf("%s = %q, want %q", name, files[name], value)
+		}
+	}
+
+	if !strings.Contains(stdout.String(), "Secret 'DB_URL' written to") {
+		t.Errorf("stdout missing confirmation: %q", stdout.String())
+	}
+	for _, w := range []string{
+		"skipped 'GONE': secret is deleted",
+		"skipped 'OTHERS_ONLY': no value is readable by ME",
+	} {
+		if !strings.Contains(stderr.String(), w) {
+			t.Errorf("stderr missing %q: %q", w, stderr.String())
+		}
+	}
+}
+
+func TestSecretExportDir_ReplacesExistingFile(t *testing.T) {
+	cli, _, _ := newExportCLI(t, map[string]vault.Secret{
+		"TOKEN": exportTestSecret("TOKEN", "new", "ME"),
+	})
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "TOKEN"), []byte("old value"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cli.SecretExportDir(DirExportOptions{Dir: dir}, "", 0); err != nil {
+		t.Fatalf("SecretExportDir failed: %v", err)
+	}
+
+	files := readExportDir(t, dir)
+	if len(files) != 1 || files["TOKEN"] != "new" {
+		t.Errorf("files = %v, want only TOKEN=new", files)
+	}
+}
+
+func TestSecretExportDir_FileNames(t *testing.T) {
+	cli, _, stderr := newExportCLI(t, map[string]vault.Secret{
+		"DB_URL":       exportTestSecret("DB_URL", "a", "ME"),
+		"app::API_KEY": exportTestSecret("app::API_KEY", "b", "ME"),
+		"db_url":       exportTestSecret("db_url", "c", "ME"),
+	})
+	dir := t.TempDir()
+
+	opts := DirExportOptions{Dir: dir, Lowercase: true, FilenameTemplate: "{key}.txt"}
+	if err := cli.SecretExportDir(opts, "", 0); err != nil {
+		t.Fatalf("SecretExportDir failed: %v", err)
+	}
+
+	files := readExportDir(t, dir)
+	if _, ok := files["app.api_key.txt"]; !ok {
+		t.Errorf("missing app.api_key.txt in %v", files)
+	}
+	if _, ok := files["db_url.txt"]; !ok {
+		t.Errorf("missing db_url.txt in %v", files)
+	}
+	if len(files) != 2 {
+		t.Errorf("files = %v, want 2", files)
+	}
+	if !strings.Contains(stderr.String(), "file name 'db_url.txt' is already used") {
+		t.Errorf("stderr missing collision warning: %q", stderr.String())
+	}
+}
+
+func TestSecretExportDir_InvalidOptions(t *testing.T) {
+	cli, _, _ := newExportCLI(t, map[string]vault.Secret{
+		"DB_URL": exportTestSecret("DB_URL", "a", "ME"),
+	})
+
+	tests := []struct {
+		name string
+		opts DirExportOptions
+	}{
+		{"empty dir", DirExportOptions{}},
+		{"template without key", DirExportOptions{Dir: t.TempDir(), FilenameTemplate: "secret.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := cli.SecretExportDir(tt.opts, "", 0)
+			if err == nil || err.ExitCode != ExitValidationError {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSecretExportDir_NothingReadable(t *testing.T) {
+	cli, _, _ := newExportCLI(t, map[string]vault.Secret{
+		"OTHERS_ONLY": exportTestSecret("OTHERS_ONLY", "hidden", "SOMEONE"),
+	})
+
+	err := cli.SecretExportDir(DirExportOptions{Dir: t.TempDir()}, "", 0)
+	if err == nil || err.ExitCode != ExitVaultError {
+		t.Fatalf("expected vault error, got %v", err)
+	}
+}
+
+func TestExportFileName(t *testing.T) {
+	tests := []struct {
+		key   string
+		opts  DirExportOptions
+		want  string
+		valid bool
+	}{
+		{"DB_URL", DirExportOptions{}, "DB_URL", true},
+		{"ns::KEY", DirExportOptions{Lowercase: true}, "ns.key", true},
+		{"KEY", DirExportOptions{FilenameTemplate: "prefix-{key}.env"}, "prefix-KEY.env", true},
+		{"KEY", DirExportOptions{FilenameTemplate: "sub/{key}"}, "sub/KEY", false},
+	}
+	for _, tt := range tests {
+		got, valid := exportFileName(tt.key, tt.opts)
+		if got != tt.want || valid != tt.valid {
+			t.Errorf("exportFileName(%q, %+v) = %q, %v; want %q, %v", tt.key, tt.opts, got, valid, tt.want, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/gpg"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
+)
+
+// VaultExportPublic prints the public keys of the identities in the
+// configured vaults, or in the vault at vaultPath or fromIndex, as
+// concatenated ASCII-armored key blocks that 'gpg --import' accepts. An
+// identity in several vaults is exported once. Keys come from the vault
+// itself, so no keyring or keyserver is needed.
+func (c *CLI) VaultExportPublic(vaultPath string, fromIndex int) *Error {
+	targetIndex, resolveErr := c.resolveReadableVaultIndex(vaultPath, fromIndex)
+	if resolveErr != nil {
+		return resolveErr
+	}
+
+	var blocks []string
+	var exported []string
+	for i, entry := range c.vaultResolver.GetConfig().Entries {
+		if targetIndex != -1 && targetIndex != i {
+			continue
+		}
+		manager := c.describeManager(i, entry)
+		if manager == nil {
+			continue
+		}
+		for _, id := range manager.Get().Identities {
+			if containsFingerprint(exported, id.Fingerprint) {
+				continue
+			}
+			armored, err := gpg.ArmorPublicKey(id.PublicKey)
+			if err != nil {
+				c.Warnf("skipping identity %s in vault %d: %v", id.Fingerprint, i+1, err)
+				continue
+			}
+			blocks = append(blocks, strings.TrimRight(armored, "\n")+"\n")
+			exported = append(exported, id.Fingerprint)
+		}
+	}
+
+	if len(blocks) == 0 {
+		return NewError("no identities to export", ExitVaultError)
+	}
+	_, _ = fmt.Fprint(c.output.Stdout(), strings.Join(blocks, "\n"))
+	return nil
+}
+
+// containsFingerprint reports whether fingerprints holds fp.
+func containsFingerprint(fingerprints []string, fp string) bool {
+	for _, f := range fingerprints {
+		if identity.CompareFingerprints(f, fp) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vaulttest"
+)
+
+func TestVaultExportPublic_ImportsAllIdentities(t *testing.T) {
+	alice, err := vaulttest.NewIdentity("Alice", "alice@example.com")
+	if err != nil {
+		t.Fatalf("NewIdentity failed: %v", err)
+	}
+	bob, err := vaulttest.NewIdentity("Bob", "bob@example.com")
+	if err != nil {
+		t.Fatalf("NewIdentity failed: %v", err)
+	}
+	shared, err := vaulttest.BuildSignedVault([]*vaulttest.Identity{alice, bob}, nil)
+	if err != nil {
+		t.Fatalf("BuildSignedVault failed: %v", err)
+	}
+	own, err := vaulttest.BuildSignedVault([]*vaulttest.Identity{alice}, nil)
+	if err != nil {
+		t.Fatalf("BuildSignedVault failed: %v", err)
+	}
+	sharedManager := newTestManager(t, shared)
+	ownManager := newTestManager(t, own)
+
+	mock := NewMockVaultResolver()
+	mock.VaultEntries = []vault.VaultEntry{{Path: sharedManager.Path()}, {Path: ownManager.Path()}}
+	mock.Managers = map[int]*vault.Manager{0: sharedManager, 1: ownManager}
+	stdout := &bytes.Buffer{}
+	cli := &CLI{
+		vaultResolver: mock,
+		output:        output.NewHandler(stdout, &bytes.Buffer{}),
+	}
+
+	if err := cli.VaultExportPublic("", 0); err != nil {
+		t.Fatalf("VaultExportPublic failed: %v", err)
+	}
+
+	// Import each armored block and collect the fingerprints
+	var fingerprints []string
+	for _, block := range strings.SplitAfter(stdout.String(), "-----END PGP PUBLIC KEY BLOCK-----") {
+		if strings.TrimSpace(block) == "" {
+			continue
+		}
+		key, err := crypto.NewKeyFromArmored(block)
+		if err != nil {
+			t.Fatalf("exported block does not import: %v\n%s", err, block)
+		}
+		if key.IsPrivate() {
+			t.Error("exported key holds secret key material")
+		}
+		fingerprints = append(fingerprints, key.GetFingerprint())
+	}
+
+	// Alice is in both vaults but exported once
+	want := []string{alice.Fingerprint, bob.Fingerprint}
+	if len(fingerprints) != len(want) {
+		t.Fatalf("expected fingerprints %v, got %v", want, fingerprints)
+	}
+	for i := range want {
+		if !strings.EqualFold(fingerprints[i], want[i]) {
+			t.Errorf("key %d: expected %s, got %s", i, want[i], fingerprints[i])
+		}
+	}
+
+	// -v 2 exports only that vault's identities
+	stdout.Reset()
+	if err := cli.VaultExportPublic("", 2); err != nil {
+		t.Fatalf("VaultExportPublic -v 2 failed: %v", err)
+	}
+	if n := strings.Count(stdout.String(), "-----BEGIN PGP PUBLIC KEY BLOCK-----"); n != 1 {
+		t.Errorf("expected 1 key from vault 2, got %d", n)
+	}
+}
@@ -0,0 +1,290 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// plainDecryptGPGClient treats ciphertext as plaintext.
+type plainDecryptGPGClient struct {
+	*MockGPGClient
+}
+
+func (m *plainDecryptGPGClient) DecryptWithAgent(ciphertext []byte, fingerprint string) ([]byte, error) {
+	return append([]byte(nil), ciphertext...), nil
+}
+
+// newExportCLI builds a CLI over a single mock vault holding secrets, whose
+// values are stored as base64 of their plaintext.
+func newExportCLI(t *testing.T, secrets map[string]vault.Secret) (*CLI, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+
+	resolver := NewMockVaultResolver()
+	resolver.VaultEntries = []vault.VaultEntry{{Path: "/vault1"}}
+	resolver.VaultPaths = []string{"/vault1"}
+	resolver.Secrets[0] = secrets
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cli := &CLI{
+		config:        config.Config{Login: newTestSignedLogin(t, "ME")},
+		vaultResolver: resolver,
+		gpgClient:     &plainDecryptGPGClient{MockGPGClient: NewMockGPGClient()},
+		output:        output.NewHandler(stdout, stderr),
+	}
+	return cli, stdout, stderr
+}
+
+func exportTestSecret(key, plaintext string, recipients ...string) vault.Secret {
+	return vault.Secret{Key: key, Values: []vault.SecretValue{{
+		AvailableTo: recipients,
+		Value:       base64.StdEncoding.EncodeToString([]byte(plaintext)),
+	}}}
+}
+
+func TestSecretExport_K8sSecret(t *testing.T) {
+	cli, stdout, stderr := newExportCLI(t, map[string]vault.Secret{
+		"DB_URL":       exportTestSecret("DB_URL", "postgres://db", "ME"),
+		"app::API_KEY": exportTestSecret("app::API_KEY", "line1\nline2\n", "ME"),
+		"TRUE":         exportTestSecret("TRUE", "yes", "ME"),
+		"1::X":         exportTestSecret("1::X", "first", "ME"),
+		"1.X":          exportTestSecret("1.X", "second", "ME"),
+		"OTHERS_ONLY":  exportTestSecret("OTHERS_ONLY", "hidden", "SOMEONE"),
+		"GONE":         {Key: "GONE", Values: []vault.SecretValue{{AvailableTo: []string{"ME"}, Value: "eA=="}, {Deleted: true}}},
+	})
+
+	if err := cli.SecretExport(ExportOptions{Format: ExportFormatK8sSecret, Name: "my-app"}, "", 0); err != nil {
+		t.Fatalf("SecretExport failed: %v", err)
+	}
+
+	var manifest struct {
+		APIVersion string `yaml:"apiVersion"`
+		Kind       string `yaml:"kind"`
+		Metadata   struct {
+			Name string `yaml:"name"`
+		} `yaml:"metadata"`
+		Type string            `yaml:"type"`
+		Data map[string]string `yaml:"data"`
+	}
+	if err := yaml.Unmarshal(stdout.Bytes(), &manifest); err != nil {
+		t.Fatalf("manifest is not valid YAML: %v\n%s", err, stdout.String())
+	}
+
+	if manifest.APIVersion != "v1" || manifest.Kind != "Secret" || manifest.Type != "Opaque" {
+		t.Errorf("unexpected manifest header: %+v", manifest)
+	}
+	if manifest.Metadata.Name != "my-app" {
+		t.Errorf("metadata.name = %q, want my-app", manifest.Metadata.Name)
+	}
+
+	want := map[string]string{
+		"DB_URL":      "postgres://db",
+		"app.API_KEY": "line1\nline2\n",
+		"TRUE":        "yes",
+		"1.X":         "second",
+	}
+	if len(manifest.Data) != len(want) {
+		t.Errorf("expected %d data keys, got %v", len(want), manifest.Data)
+	}
+	for key, plaintext := range want {
+		decoded, err := base64.StdEncoding.DecodeString(manifest.Data[key])
+		if err != nil {
+			t.Errorf("data[%s] is not base64: %v", key, err)
+			continue
+		}
+		if string(decoded) != plaintext {
+			t.Errorf("data[%s] = %q, want %q", key, decoded, plaintext)
+		}
+	}
+
+	if !strings.Contains(stderr.String(), "skipped '1::X': Kubernetes data key '1.X' is already used by '1.X'") {
+		t.Errorf("expected collision to be reported, got stderr: %s", stderr.String())
+	}
+}
+
+func TestSecretExport_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ExportOptions
+		want string
+	}{
+		{name: "missing format", opts: ExportOptions{}, want: "--format is required"},
+		{name: "unknown format", opts: ExportOptions{Format: "xml"}, want: "unknown export format"},
+		{name: "missing name", opts: ExportOptions{Format: ExportFormatK8sSecret}, want: "--name is required"},
+		{name: "invalid name", opts: ExportOptions{Format: ExportFormatK8sSecret, Name: "My_App"}, want: "invalid Kubernetes Secret name"},
+		{name: "name without k8s-secret", opts: ExportOptions{Format: ExportFormatGitHubActions, Name: "my-app"}, want: "--name is only used"},
+		{name: "name with terraform", opts: ExportOptions{Format: ExportFormatTerraform, Name: "my-app"}, want: "--name is only used"},
+		{name: "env prefix with terraform", opts: ExportOptions{Format: ExportFormatTerraform, EnvPrefix: "APP_"}, want: "--env-prefix is only used"},
+		{name: "env prefix starting with a digit", opts: ExportOptions{Format: ExportFormatGitHubActions, EnvPrefix: "1APP_"}, want: "invalid --env-prefix"},
+		{name: "env prefix with a dash", opts: ExportOptions{Format: ExportFormatGitHubActions, EnvPrefix: "APP-"}, want: "invalid --env-prefix"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, stdout, _ := newExportCLI(t, nil)
+			err := cli.SecretExport(tt.opts, "", 0)
+			if err == nil || err.ExitCode != ExitValidationError || !strings.Contains(err.Message, tt.want) {
+				t.Fatalf("expected validation error containing %q, got %v", tt.want, err)
+			}
+			if stdout.Len() != 0 {
+				t.Errorf("expected no output, got %q", stdout.String())
+			}
+		})
+	}
+}
+
+func TestSecretExport_GitHubActions(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("GITHUB_ENV", "")
+
+	cli, stdout, stderr := newExportCLI(t, map[string]vault.Secret{
+		"DB_PASSWORD":   exportTestSecret("DB_PASSWORD", "p%ss\r\nword", "ME"),
+		"prod::API_KEY": exportTestSecret("prod::API_KEY", "::stop-commands::x", "ME"),
+		"OTHERS_ONLY":   exportTestSecret("OTHERS_ONLY", "hidden", "SOMEONE"),
+	})
+
+	if err := cli.SecretExport(ExportOptions{Format: ExportFormatGitHubActions}, "", 0); err != nil {
+		t.Fatalf("SecretExport failed: %v", err)
+	}
+
+	lines := strings.Split(stdout.String(), "\n")
+	lastMask, firstAssignment := -1, -1
+	var masks []string
+	for i, line := range lines {
+		if strings.HasPrefix(line, "::add-mask::") {
+			lastMask = i
+			masks = append(masks, line)
+		} else if firstAssignment == -1 && strings.Contains(line, "<<ghadelimiter_") {
+			firstAssignment = i
+		}
+	}
+	if lastMask == -1 || firstAssignment == -1 || lastMask > firstAssignment {
+		t.Fatalf("expected every ::add-mask:: line before the first assignment:\n%s", stdout.String())
+	}
+
+	wantMasks := []string{
+		"::add-mask::p%25ss",
+		"::add-mask::word",
+		"::add-mask::::stop-commands::x",
+	}
+	if strings.Join(masks, "\n") != strings.Join(wantMasks, "\n") {
+		t.Errorf("masks = %q, want %q", masks, wantMasks)
+	}
+
+	for _, want := range []string{"DB_PASSWORD<<ghadelimiter_", "PROD_API_KEY<<ghadelimiter_", "\n::stop-commands::x\nghadelimiter_"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("expected %q in output:\n%s", want, stdout.String())
+		}
+	}
+	if strings.Contains(stdout.String(), "hidden") {
+		t.Errorf("inaccessible secret was exported:\n%s", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "not running in GitHub Actions") {
+		t.Errorf("expected warning outside GitHub Actions, got: %s", stderr.String())
+	}
+}
+
+func TestSecretExport_GitHubActionsEnvPrefix(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("GITHUB_ENV", "")
+
+	cli, stdout, _ := newExportCLI(t, map[string]vault.Secret{
+		"DB_PASSWORD":   exportTestSecret("DB_PASSWORD", "pw", "ME"),
+		"prod::API_KEY": exportTestSecret("prod::API_KEY", "key", "ME"),
+	})
+	if err := cli.SecretExport(ExportOptions{Format: ExportFormatGitHubActions, EnvPrefix: "APP_"}, "", 0); err != nil {
+		t.Fatalf("SecretExport failed: %v", err)
+	}
+	for _, want := range []string{"\nAPP_DB_PASSWORD<<ghadelimiter_", "\nAPP_PROD_API_KEY<<ghadelimiter_"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("expected %q in output:\n%s", want, stdout.String())
+		}
+	}
+}
+
+func TestSecretExport_GitHubActionsWritesGitHubEnv(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), "github_env")
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_ENV", envFile)
+
+	cli, stdout, stderr := newExportCLI(t, map[string]vault.Secret{
+		"TOKEN": exportTestSecret("TOKEN", "s3cret", "ME"),
+	})
+
+	if err := cli.SecretExport(ExportOptions{Format: ExportFormatGitHubActions}, "", 0); err != nil {
+		t.Fatalf("SecretExport failed: %v", err)
+	}
+
+	if got := stdout.String(); got != "::add-mask::s3cret\n" {
+		t.Errorf("stdout = %q, want only the mask", got)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("expected no warnings, got: %s", stderr.String())
+	}
+
+	data, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatalf("failed to read GITHUB_ENV: %v", err)
+	}
+	parts := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(parts) != 3 || !strings.HasPrefix(parts[0], "TOKEN<<") || parts[1] != "s3cret" || parts[0] != "TOKEN<<"+parts[2] {
+		t.Errorf("unexpected GITHUB_ENV contents: %q", data)
+	}
+}
+
+func TestSecretExport_Terraform(t *testing.T) {
+	cli, stdout, stderr := newExportCLI(t, map[string]vault.Secret{
+		"DB_PASSWORD":   exportTestSecret("DB_PASSWORD", `say "hi" \ bye`, "ME"),
+		"prod::API_KEY": exportTestSecret("prod::API_KEY", "line1\nline2\r\n\ttab", "ME"),
+		"TEMPLATE":      exportTestSecret("TEMPLATE", "${var.x} %{if} $5 50%", "ME"),
+		"1.X":           exportTestSecret("1.X", "unmappable", "ME"),
+		"OTHERS_ONLY":   exportTestSecret("OTHERS_ONLY", "hidden", "SOMEONE"),
+	})
+
+	if err := cli.SecretExport(ExportOptions{Format: ExportFormatTerraform}, "", 0); err != nil {
+		t.Fatalf("SecretExport failed: %v", err)
+	}
+
+	want := `db_password = "say \"hi\" \\ bye"` + "\n" +
+		`template = "$${var.x} %%{if} $5 50%"` + "\n" +
+		`prod_api_key = "line1\nline2\r\n\ttab"` + "\n"
+	if stdout.String() != want {
+		t.Fatalf("output = %q, want %q", stdout.String(), want)
+	}
+
+	// HCL string escapes are a subset of Go's, so every quoted value must
+	// unquote back to its plaintext once template escapes are undone.
+	values := map[string]string{
+		"db_password":  `say "hi" \ bye`,
+		"prod_api_key": "line1\nline2\r\n\ttab",
+		"template":     "${var.x} %{if} $5 50%",
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n") {
+		name, quoted, ok := strings.Cut(line, " = ")
+		if !ok {
+			t.Fatalf("not an assignment: %q", line)
+		}
+		got, err := strconv.Unquote(quoted)
+		if err != nil {
+			t.Fatalf("%s: invalid quoted string %s: %v", name, quoted, err)
+		}
+		got = strings.NewReplacer("$${", "${", "%%{", "%{").Replace(got)
+		if got != values[name] {
+			t.Errorf("%s = %q, want %q", name, got, values[name])
+		}
+	}
+
+	if !strings.Contains(stderr.String(), "skipped '1.X'") {
+		t.Errorf("expected unmappable key to be reported, got: %s", stderr.String())
+	}
+}
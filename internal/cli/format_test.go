@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+	"gopkg.in/yaml.v3"
+)
+
+// decodeYAML parses out as YAML into v, failing the test on JSON, which
+// YAML would also accept.
+func decodeYAML(t *testing.T, out string, v interface{}) {
+	t.Helper()
+	if strings.HasPrefix(strings.TrimSpace(out), "[") || strings.HasPrefix(strings.TrimSpace(out), "{") {
+		t.Fatalf("expected YAML, got JSON:\n%s", out)
+	}
+	if err := yaml.Unmarshal([]byte(out), v); err != nil {
+		t.Fatalf("invalid YAML: %v\n%s", err, out)
+	}
+}
+
+func TestVaultDescribe_FormatYAML(t *testing.T) {
+	cli, stdout := newDescribeOnlyCLI(t)
+	cli.SetOutputFormat(output.FormatYAML)
+
+	if err := cli.VaultDescribe(true, ""); err != nil {
+		t.Fatalf("VaultDescribe failed: %v", err)
+	}
+	var got []struct {
+		Position   int                 `yaml:"position"`
+		Identities []map[string]string `yaml:"identities"`
+		Secrets    []map[string]string `yaml:"secrets"`
+	}
+	decodeYAML(t, stdout.String(), &got)
+	if len(got) != 1 || got[0].Position != 1 {
+		t.Fatalf("unexpected vaults: %+v", got)
+	}
+	if len(got[0].Identities) != 1 || got[0].Identities[0]["fingerprint"] != "FP_A" {
+		t.Errorf("unexpected identities: %+v", got[0].Identities)
+	}
+	if len(got[0].Secrets) != 1 || got[0].Secrets[0]["key"] != "DB_URL" {
+		t.Errorf("unexpected secrets: %+v", got[0].Secrets)
+	}
+}
+
+func TestIdentityShow_FormatYAML(t *testing.T) {
+	cli, stdout, v := newIdentityShowCLI(t)
+	cli.SetOutputFormat(output.FormatYAML)
+	id := v.Identities[0]
+
+	if err := cli.IdentityShow(id.Fingerprint, 0, true); err != nil {
+		t.Fatalf("IdentityShow failed: %v", err)
+	}
+	var got []struct {
+		Fingerprint   string `yaml:"fingerprint"`
+		AlgorithmBits int    `yaml:"algorithm_bits"`
+		Verified      bool   `yaml:"verified"`
+	}
+	decodeYAML(t, stdout.String(), &got)
+	if len(got) != 1 || got[0].Fingerprint != id.Fingerprint || got[0].AlgorithmBits != id.AlgorithmBits || !got[0].Verified {
+		t.Errorf("unexpected identities: %+v", got)
+	}
+}
+
+func TestSecretGetAll_FormatYAML(t *testing.T) {
+	cli, _ := newReplaceCLI(t, 0)
+	cli.gpgClient = &prefixDecryptGPGClient{MockGPGClient: NewMockGPGClient(), prefix: "encrypted_to_base64pubkey_"}
+	for _, value := range []string{"first", "second"} {
+		if err := cli.SecretPutValue("DB_URL", "", 1, value, false); err != nil {
+			t.Fatalf("SecretPutValue failed: %v", err)
+		}
+	}
+	cli.SetOutputFormat(output.FormatYAML)
+
+	stdout := cli.output.Stdout().(*strings.Builder)
+	stdout.Reset()
+	if err := cli.SecretGet("DB_URL", true, false, true, "", 1); err != nil {
+		t.Fatalf("SecretGet failed: %v", err)
+	}
+	var got []map[string]interface{}
+	decodeYAML(t, stdout.String(), &got)
+	// Newest first
+	if len(got) != 2 || got[0]["value"] != "second" || got[1]["value"] != "first" {
+		t.Errorf("unexpected history: %+v", got)
+	}
+}
+
+func TestVaultDoctor_FormatYAML(t *testing.T) {
+	var stdout bytes.Buffer
+	cli := &CLI{
+		vaultResolver: NewMockVaultResolver(),
+		gpgClient:     NewMockGPGClient(),
+		output:        output.NewHandler(&stdout, &bytes.Buffer{}, output.WithFormat(output.FormatYAML)),
+	}
+
+	if err := cli.VaultDoctor(true, false, "", 0); err != nil {
+		t.Fatalf("VaultDoctor failed: %v", err)
+	}
+	var got struct {
+		Status string              `yaml:"status"`
+		Checks []map[string]string `yaml:"checks"`
+	}
+	decodeYAML(t, stdout.String(), &got)
+	if got.Status == "" || len(got.Checks) == 0 {
+		t.Errorf("unexpected doctor result: %+v", got)
+	}
+}
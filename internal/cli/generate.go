@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Character sets for generated secret values.
+const (
+	GenerateCharsetAlnum     = "alnum"
+	GenerateCharsetHex       = "hex"
+	GenerateCharsetBase64URL = "base64url"
+)
+
+// GenerateCharsets lists the supported character sets, for help and errors.
+var GenerateCharsets = []string{GenerateCharsetAlnum, GenerateCharsetHex, GenerateCharsetBase64URL}
+
+// DefaultGenerateLength is the length of generated values when none is given.
+const DefaultGenerateLength = 32
+
+// generateAlphabets maps each character set to its characters.
+var generateAlphabets = map[string]string{
+	GenerateCharsetAlnum:     "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789",
+	GenerateCharsetHex:       "0123456789abcdef",
+	GenerateCharsetBase64URL: "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_",
+}
+
+// GenerateOptions configures SecretPutGenerated.
+type GenerateOptions struct {
+	Length  int
+	Charset string
+	// Show prints the generated value to stderr once it is stored.
+	Show bool
+}
+
+// GenerateSecret returns length characters drawn uniformly from charset
+// using crypto/rand.
+func GenerateSecret(charset string, length int) (string, *Error) {
+	alphabet, ok := generateAlphabets[charset]
+	if !ok {
+		return "", NewError(fmt.Sprintf("unknown charset %q (one of: %s)", charset, strings.Join(GenerateCharsets, ", ")), ExitValidationError)
+	}
+	if length <= 0 {
+		return "", NewError("--length must be a positive number", ExitValidationError)
+	}
+
+	max := big.NewInt(int64(len(alphabet)))
+	value := make([]byte, length)
+	for i := range value {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", NewError(fmt.Sprintf("failed to generate secret: %v", err), ExitGeneralError)
+		}
+		value[i] = alphabet[n.Int64()]
+	}
+	return string(value), nil
+}
+
+// SecretPutGenerated stores a new random value for the secret, generated
+// as opts describes instead of read from stdin. The value is only printed,
+// to stderr, with opts.Show; otherwise 'secret get' is the way to read it.
+func (c *CLI) SecretPutGenerated(secretKeyArg, vaultPath string, fromIndex int, opts GenerateOptions, ifAbsent bool) *Error {
+	value, genErr := GenerateSecret(opts.Charset, opts.Length)
+	if genErr != nil {
+		return genErr
+	}
+	if sizeErr := c.checkSecretSize(int64(len(value))); sizeErr != nil {
+		return sizeErr
+	}
+
+	target, prepErr := c.prepareSecretPut(secretKeyArg, vaultPath, fromIndex, ifAbsent)
+	if prepErr != nil || target == nil {
+		return prepErr
+	}
+	if proceed, confirmErr := c.confirmReplace(target); !proceed {
+		return confirmErr
+	}
+	if storeErr := c.encryptAndStoreValue(target, value); storeErr != nil {
+		return storeErr
+	}
+
+	if opts.Show {
+		_, _ = fmt.Fprintf(c.output.Stderr(), "%s\n", value)
+	}
+	return nil
+}
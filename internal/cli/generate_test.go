@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSecret(t *testing.T) {
+	for _, charset := range GenerateCharsets {
+		for _, length := range []int{1, 32, 200} {
+			value, err := GenerateSecret(charset, length)
+			if err != nil {
+				t.Fatalf("GenerateSecret(%s, %d) failed: %v", charset, length, err)
+			}
+			if len(value) != length {
+				t.Errorf("GenerateSecret(%s, %d) returned %d characters", charset, length, len(value))
+			}
+			for _, r := range value {
+				if !strings.ContainsRune(generateAlphabets[charset], r) {
+					t.Errorf("GenerateSecret(%s) returned %q outside the charset", charset, r)
+				}
+			}
+		}
+	}
+
+	first, _ := GenerateSecret(GenerateCharsetAlnum, 32)
+	second, _ := GenerateSecret(GenerateCharsetAlnum, 32)
+	if first == second {
+		t.Error("expected two generated values to differ")
+	}
+
+	if _, err := GenerateSecret("emoji", 32); err == nil || err.ExitCode != ExitValidationError {
+		t.Errorf("expected a validation error for an unknown charset, got %v", err)
+	}
+	if _, err := GenerateSecret(GenerateCharsetHex, 0); err == nil || err.ExitCode != ExitValidationError {
+		t.Errorf("expected a validation error for a zero length, got %v", err)
+	}
+}
+
+func TestSecretPutGenerated_StoredAndRetrievable(t *testing.T) {
+	cli, mock := newReplaceCLI(t, 0)
+	cli.gpgClient = &prefixDecryptGPGClient{MockGPGClient: NewMockGPGClient(), prefix: "encrypted_to_base64pubkey_"}
+	stderr := cli.output.Stderr().(*strings.Builder)
+
+	opts := GenerateOptions{Length: 24, Charset: GenerateCharsetHex}
+	if err := cli.SecretPutGenerated("API_TOKEN", "", 1, opts, false); err != nil {
+		t.Fatalf("SecretPutGenerated failed: %v", err)
+	}
+	if len(mock.Secrets[0]["API_TOKEN"].Values) != 1 {
+		t.Fatalf("expected one stored value, got %+v", mock.Secrets[0]["API_TOKEN"])
+	}
+
+	generated := storedValues(t, cli)[0]
+	if len(generated) != 24 || strings.Trim(generated, generateAlphabets[GenerateCharsetHex]) != "" {
+		t.Errorf("expected 24 hex characters, got %q", generated)
+	}
+	// Without --show the value is never echoed
+	if strings.Contains(stderr.String(), generated) {
+		t.Errorf("generated value was printed without --show:\n%s", stderr.String())
+	}
+
+	stderr.Reset()
+	opts.Show = true
+	if err := cli.SecretPutGenerated("API_TOKEN", "", 1, opts, false); err != nil {
+		t.Fatalf("SecretPutGenerated --show failed: %v", err)
+	}
+	if shown, stored := stderr.String(), storedValues(t, cli)[0]; shown != stored+"\n" {
+		t.Errorf("expected --show to print the stored value once, got %q, stored %q", shown, stored)
+	}
+}
+
+// storedValues returns the decrypted values of API_TOKEN, newest first.
+func storedValues(t *testing.T, cli *CLI) []string {
+	t.Helper()
+	stdout := cli.output.Stdout().(*strings.Builder)
+	stdout.Reset()
+	if err := cli.SecretGet("API_TOKEN", true, false, true, "", 1); err != nil {
+		t.Fatalf("SecretGet failed: %v", err)
+	}
+	var got []SecretValueJSON
+	if err := json.Unmarshal([]byte(stdout.String()), &got); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, stdout.String())
+	}
+	values := make([]string, len(got))
+	for i, v := range got {
+		values[i], _ = v.Value.(string)
+	}
+	return values
+}
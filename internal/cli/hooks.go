@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// HookVaultEnv is the environment variable holding the path of the written
+// vault when the post_write hook runs.
+const HookVaultEnv = "DOTSECENV_VAULT"
+
+// runPostWriteHook runs the configured hooks.post_write command after the
+// vault at index was written, for example to commit or upload it. The hook
+// only learns the vault path; it is never given secret values, and its
+// output goes to stderr so it cannot mix with command output.
+//
+// The write is not undone when the hook fails. The failure is a warning,
+// or an error with hooks.fail_on_error.
+func (c *CLI) runPostWriteHook(index int) *Error {
+	if c.config.Hooks.PostWrite == "" {
+		return nil
+	}
+	entries := c.vaultResolver.GetConfig().Entries
+	if index < 0 || index >= len(entries) {
+		return nil
+	}
+	return c.runPostWriteHookForPath(entries[index].Path)
+}
+
+// runPostWriteHookForPath runs the post_write hook for the vault written at
+// path, for commands that write vaults by path rather than through the
+// vault resolver.
+func (c *CLI) runPostWriteHookForPath(path string) *Error {
+	command := c.config.Hooks.PostWrite
+	if command == "" {
+		return nil
+	}
+	path = vault.ExpandPath(path)
+
+	cmd := hookCommand(command)
+	cmd.Env = append(os.Environ(), HookVaultEnv+"="+path)
+	cmd.Stdout = c.output.Stderr()
+	cmd.Stderr = c.output.Stderr()
+	if err := cmd.Run(); err != nil {
+		msg := fmt.Sprintf("post_write hook failed for %s: %v (the vault was written)", path, err)
+		if c.config.Hooks.FailOnError {
+			return NewError(msg, ExitGeneralError)
+		}
+		c.Warnf("%s", msg)
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// newHookCLI returns a store CLI whose vault already holds its identity, so
+// SecretPut writes, with hooks.post_write set to command.
+func newHookCLI(t *testing.T, command string, failOnError bool) (*CLI, *strings.Builder) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts use /bin/sh")
+	}
+	cli, stderr := newSecretStoreCLI(t, []string{"/vault1.yaml"}, []string{"/vault1.yaml"})
+	mock := cli.vaultResolver.(*MockVaultResolver)
+	id := vault.Identity{Fingerprint: "MYFINGERPRINT", PublicKey: "base64pubkey", Algorithm: "RSA", AlgorithmBits: 4096}
+	mock.Identities[id.Fingerprint] = id
+	mock.IdentitiesByVault[0] = map[string]vault.Identity{id.Fingerprint: id}
+	cli.config.Hooks.PostWrite = command
+	cli.config.Hooks.FailOnError = failOnError
+	return cli, stderr
+}
+
+func TestPostWriteHook_RunsAfterWrite(t *testing.T) {
+	record := filepath.Join(t.TempDir(), "hook.log")
+	cli, _ := newHookCLI(t, `echo "$DOTSECENV_VAULT" >> "`+record+`"; env >> "`+record+`"`, false)
+
+	if err := cli.SecretPut("DB_URL", "", 1, "hook-secret-value", false); err != nil {
+		t.Fatalf("SecretPut failed: %v", err)
+	}
+
+	data, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	lines := strings.SplitN(string(data), "\n", 2)
+	if lines[0] != "/vault1.yaml" {
+		t.Errorf("hook got vault %q, want /vault1.yaml", lines[0])
+	}
+	if strings.Contains(string(data), "hook-secret-value") {
+		t.Error("hook was given the secret value")
+	}
+}
+
+func TestPostWriteHook_DoesNotInheritFromEnv(t *testing.T) {
+	record := filepath.Join(t.TempDir(), "hook.log")
+	cli, _ := newHookCLI(t, `env >> "`+record+`"`, false)
+	t.Setenv("DOTSECENV_TEST_HOOK_SECRET", "from-env-secret-value")
+
+	value, lookupErr := LookupSecretEnv("DOTSECENV_TEST_HOOK_SECRET", false)
+	if lookupErr != nil {
+		t.Fatalf("LookupSecretEnv failed: %v", lookupErr)
+	}
+	if err := cli.SecretPut("DB_URL", "", 1, value, false); err != nil {
+		t.Fatalf("SecretPut failed: %v", err)
+	}
+
+	data, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	if strings.Contains(string(data), "from-env-secret-value") {
+		t.Error("hook inherited the --from-env variable")
+	}
+}
+
+func TestPostWriteHook_NotRunWithoutWrite(t *testing.T) {
+	record := filepath.Join(t.TempDir(), "hook.log")
+	cli, _ := newHookCLI(t, `touch "`+record+`"`, false)
+	mock := cli.vaultResolver.(*MockVaultResolver)
+	mock.Secrets[0] = map[string]vault.Secret{"DB_URL": {Key: "DB_URL", Values: []vault.SecretValue{
+		{AvailableTo: []string{"MYFINGERPRINT"}, Value: "old"},
+	}}}
+
+	if err := cli.SecretPut("DB_URL", "", 1, "new-value", true); err != nil {
+		t.Fatalf("SecretPut failed: %v", err)
+	}
+	if _, err := os.Stat(record); err == nil {
+		t.Error("hook ran although nothing was written")
+	}
+}
+
+func TestPostWriteHook_Failure(t *testing.T) {
+	t.Run("warns by default", func(t *testing.T) {
+		cli, stderr := newHookCLI(t, "exit 3", false)
+
+		if err := cli.SecretPut("DB_URL", "", 1, "value", false); err != nil {
+			t.Fatalf("a failed hook should not fail the command, got %v", err)
+		}
+		if !strings.Contains(stderr.String(), "warning: post_write hook failed for /vault1.yaml") {
+			t.Errorf("expected a hook warning, got %q", stderr.String())
+		}
+	})
+
+	t.Run("fails with fail_on_error", func(t *testing.T) {
+		cli, _ := newHookCLI(t, "exit 3", true)
+		mock := cli.vaultResolver.(*MockVaultResolver)
+
+		err := cli.SecretPut("DB_URL", "", 1, "value", false)
+		if err == nil || !strings.Contains(err.Message, "the vault was written") {
+			t.Fatalf("expected a hook failure error, got %v", err)
+		}
+		if len(mock.SavedVaults) == 0 {
+			t.Error("the vault write should be kept")
+		}
+	})
+}
+
+func TestPostWriteHook_RunsAfterVaultUpgrade(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts use /bin/sh")
+	}
+	path := filepath.Join(t.TempDir(), "vault")
+	data, err := os.ReadFile(filepath.Join("..", "..", "pkg", "dotsecenv", "vault", "testdata", "vault_v1.jsonl"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	record := filepath.Join(t.TempDir(), "hook.log")
+
+	cli := &CLI{
+		config: config.Config{Vault: []string{path}},
+		output: output.NewHandler(&bytes.Buffer{}, &bytes.Buffer{}),
+	}
+	cli.config.Hooks.PostWrite = `echo "$DOTSECENV_VAULT" >> "` + record + `"`
+
+	if err := cli.VaultUpgrade(false, "", 1); err != nil {
+		t.Fatalf("VaultUpgrade failed: %v", err)
+	}
+	got, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatalf("hook did not run after the upgrade: %v", err)
+	}
+	if strings.TrimSpace(string(got)) != path {
+		t.Errorf("hook got vault %q, want %s", got, path)
+	}
+}
+
+func TestPostWriteHook_RunsAfterValidateFix(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts use /bin/sh")
+	}
+	fixture, err := os.ReadFile(filepath.Join("..", "..", "pkg", "dotsecenv", "vault", "testdata", "vault_v2.jsonl"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "vault")
+	if err := os.WriteFile(path, bytes.ReplaceAll(fixture, []byte("\n"), []byte("\r\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "config")
+	if err := os.WriteFile(configPath, []byte("vault: []\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	record := filepath.Join(t.TempDir(), "hook.log")
+
+	manager := vault.NewManager(path, true)
+	if err := manager.OpenAndLock(); err != nil {
+		t.Fatalf("OpenAndLock failed: %v", err)
+	}
+	defer func() { _ = manager.Unlock() }()
+	mock := NewMockVaultResolver()
+	mock.VaultEntries = []vault.VaultEntry{{Path: path}}
+	mock.Managers = map[int]*vault.Manager{0: manager}
+	cli, _ := newValidateFileCLI()
+	cli.configPath = configPath
+	cli.vaultResolver = mock
+	cli.config.Hooks.PostWrite = `echo "$DOTSECENV_VAULT" >> "` + record + `"`
+
+	// Without --fix nothing is written, so the hook does not run
+	_ = cli.Validate(false, false)
+	if _, err := os.Stat(record); !os.IsNotExist(err) {
+		t.Fatalf("hook ran without a write: %v", err)
+	}
+
+	// The fixture's placeholder signatures still fail validation
+	_ = cli.Validate(true, false)
+	got, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatalf("hook did not run after validate --fix: %v", err)
+	}
+	if strings.TrimSpace(string(got)) != path {
+		t.Errorf("hook got vault %q, want %s", got, path)
+	}
+}
+
+func TestPostWriteHook_RunsAfterVaultClone(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts use /bin/sh")
+	}
+	_, aliceID := newSignedVaultIdentity(t, "Alice", nil)
+	sourcePath := writeCloneSource(t, aliceID)
+	destPath := filepath.Join(t.TempDir(), "vault")
+	record := filepath.Join(t.TempDir(), "hook.log")
+
+	cli := &CLI{output: output.NewHandler(&bytes.Buffer{}, &bytes.Buffer{})}
+	cli.config.Hooks.PostWrite = `echo "$DOTSECENV_VAULT" >> "` + record + `"`
+
+	if err := cli.VaultClone(sourcePath, destPath, ""); err != nil {
+		t.Fatalf("VaultClone failed: %v", err)
+	}
+	got, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatalf("hook did not run after the clone: %v", err)
+	}
+	if strings.TrimSpace(string(got)) != destPath {
+		t.Errorf("hook got vault %q, want %s", got, destPath)
+	}
+}
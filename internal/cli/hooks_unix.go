@@ -0,0 +1,10 @@
+//go:build unix
+
+package cli
+
+import "os/exec"
+
+// hookCommand returns a command running the hook command through the shell.
+func hookCommand(command string) *exec.Cmd {
+	return exec.Command("/bin/sh", "-c", command)
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package cli
+
+import "os/exec"
+
+// hookCommand returns a command running the hook command through cmd.exe.
+func hookCommand(command string) *exec.Cmd {
+	return exec.Command("cmd", "/C", command)
+}
@@ -14,12 +14,32 @@ import (
 // If fromIndex > 0, the vault at that 1-based index is targeted.
 // When none of the above are set and exactly one vault is configured, it is auto-selected.
 func (c *CLI) IdentityAdd(fingerprint string, addAll bool, vaultPath string, fromIndex int) *Error {
+	return c.identityAdd(fingerprint, addAll, vaultPath, fromIndex, false)
+}
+
+// IdentityAddAllMissing adds a GPG identity to every configured vault that
+// does not already contain it, or only to the vault selected by vaultPath or
+// fromIndex. Vaults where the identity is already present count as success
+// and are only reported in the summary, so the command can be rerun safely
+// from onboarding scripts.
+func (c *CLI) IdentityAddAllMissing(fingerprint, vaultPath string, fromIndex int) *Error {
+	return c.identityAdd(fingerprint, vaultPath == "" && fromIndex <= 0, vaultPath, fromIndex, true)
+}
+
+// identityAdd implements IdentityAdd and IdentityAddAllMissing. With
+// allMissing, vaults already holding the identity are counted as present
+// rather than reported as skipped, and the summary is always printed.
+func (c *CLI) identityAdd(fingerprint string, addAll bool, vaultPath string, fromIndex int, allMissing bool) *Error {
 	// The current user signs the new identity entry (vouching for it)
 	signerFP, fpErr := c.checkFingerprintRequired("identity add")
 	if fpErr != nil {
 		return fpErr
 	}
 
+	if err := c.checkKeyExpiry(fingerprint); err != nil {
+		return err
+	}
+
 	config := c.vaultResolver.GetConfig()
 	entries := config.Entries
 
@@ -43,30 +63,66 @@ func (c *CLI) IdentityAdd(fingerprint string, addAll bool, vaultPath string, fro
 		indices = []int{idx}
 	}
 
-	var added, skipped, failed int
+	// With several vaults, the additions are written together by SaveAll
+	// once all of them are made, so a vault failing to save does not leave
+	// the others changed.
+	together := len(indices) > 1
+	if together {
+		c.vaultResolver.DeferWrites()
+	}
+
+	var added []int
+	var skipped, failed int
 	var lastErr *Error
 	for _, idx := range indices {
 		vPath := entries[idx].Path
 
 		if c.vaultResolver.IdentityExistsInVault(fingerprint, idx) {
-			_, _ = fmt.Fprintf(c.output.Stderr(), "skipped: identity %s already in vault %d (%s)\n", fingerprint, idx+1, vPath)
+			if !allMissing {
+				_, _ = fmt.Fprintf(c.output.Stderr(), "skipped: identity %s already in vault %d (%s)\n", fingerprint, idx+1, vPath)
+			}
 			skipped++
 			continue
 		}
 
-		if err := c.addIdentityToVault(fingerprint, signerFP, idx); err != nil {
+		var err *Error
+		if together {
+			err = c.signIdentityIntoVault(fingerprint, signerFP, idx)
+		} else {
+			err = c.addIdentityToVault(fingerprint, signerFP, idx)
+		}
+		if err != nil {
 			_, _ = fmt.Fprintf(c.output.Stderr(), "failed: vault %d (%s): %s\n", idx+1, vPath, err.Message)
 			lastErr = err
 			failed++
 			continue
 		}
+		added = append(added, idx)
+	}
 
-		_, _ = fmt.Fprintf(c.output.Stdout(), "added: identity %s to vault %d (%s)\n", fingerprint, idx+1, vPath)
-		added++
+	if together && len(added) > 0 {
+		if err := c.vaultResolver.SaveAll(); err != nil {
+			return NewError(fmt.Sprintf("failed to save vaults: %v", err), ExitVaultError)
+		}
+	}
+	for _, idx := range added {
+		_, _ = fmt.Fprintf(c.output.Stdout(), "added: identity %s to vault %d (%s)\n", fingerprint, idx+1, entries[idx].Path)
+		if together {
+			if hookErr := c.runPostWriteHook(idx); hookErr != nil {
+				return hookErr
+			}
+		}
 	}
 
-	if len(indices) > 1 {
-		_, _ = fmt.Fprintf(c.output.Stdout(), "\nsummary: added=%d skipped=%d failed=%d\n", added, skipped, failed)
+	switch {
+	case allMissing:
+		_, _ = fmt.Fprintf(c.output.Stdout(), "summary: added=%d already-present=%d", len(added), skipped)
+		if failed > 0 {
+			_, _ = fmt.Fprintf(c.output.Stdout(), " failed=%d", failed)
+		}
+		_, _ = fmt.Fprintln(c.output.Stdout())
+	case len(indices) > 1:
+		_, _ = fmt.Fprintf(c.output.Stdout(), "\nsummary: added=%d skipped=%d failed=%d\n", len(added), skipped, failed)
 	}
 
 	if failed > 0 {
@@ -79,9 +135,155 @@ func (c *CLI) IdentityAdd(fingerprint string, addAll bool, vaultPath string, fro
 	return nil
 }
 
+// IdentityImport copies the identities of the vault at sourcePath into a
+// target vault, without touching secrets. Identities already in the target
+// are skipped. Each remaining identity's signature is verified against the
+// source vault first; identities that fail verification are reported and not
+// imported. Imported identities are re-signed by the current user, since their
+// original signer may not exist in the target vault.
+func (c *CLI) IdentityImport(sourcePath, vaultPath string, fromIndex int) *Error {
+	signerFP, fpErr := c.checkFingerprintRequired("identity import")
+	if fpErr != nil {
+		return fpErr
+	}
+
+	idx, resolveErr := c.resolveWritableVaultIndex(vaultPath, fromIndex, "Select vault to import identities into:")
+	if resolveErr != nil {
+		return resolveErr
+	}
+	targetPath := c.vaultResolver.GetConfig().Entries[idx].Path
+
+	sourcePath = vault.ExpandPath(sourcePath)
+	if sourcePath == vault.ExpandPath(targetPath) {
+		return NewError(fmt.Sprintf("source and target vault are the same: %s", sourcePath), ExitGeneralError)
+	}
+
+	reader, openErr := vault.NewWriterReadOnly(sourcePath)
+	if openErr != nil {
+		return NewError(fmt.Sprintf("failed to open source vault: %v", openErr), ExitVaultError)
+	}
+	source, readErr := reader.ReadVault()
+	if readErr != nil {
+		return NewError(fmt.Sprintf("failed to read source vault: %v", readErr), ExitVaultError)
+	}
+
+	var imported, present, rejected int
+	for i := range source.Identities {
+		id := &source.Identities[i]
+
+		if c.vaultResolver.IdentityExistsInVault(id.Fingerprint, idx) {
+			_, _ = fmt.Fprintf(c.output.Stderr(), "skipped: identity %s already in vault %d (%s)\n", id.Fingerprint, idx+1, targetPath)
+			present++
+			continue
+		}
+
+		if valid, verifyErr := verifyIdentitySignature(id, &source); verifyErr != nil || !valid {
+			reason := "signature verification failed"
+			if verifyErr != nil {
+				reason = verifyErr.Error()
+			}
+			_, _ = fmt.Fprintf(c.output.Stderr(), "rejected: identity %s (%s): %s\n", id.Fingerprint, id.UID, reason)
+			rejected++
+			continue
+		}
+
+		newIdentity := vault.Identity{
+			AddedAt:       time.Now().UTC(),
+			Fingerprint:   id.Fingerprint,
+			UID:           id.UID,
+			Algorithm:     id.Algorithm,
+			AlgorithmBits: id.AlgorithmBits,
+			Curve:         id.Curve,
+			CreatedAt:     id.CreatedAt,
+			ExpiresAt:     id.ExpiresAt,
+			PublicKey:     id.PublicKey,
+			SignedBy:      signerFP,
+		}
+		newIdentity.Hash = identity.ComputeIdentityHash(&newIdentity)
+
+		signature, signErr := c.gpgClient.SignDataWithAgent(signerFP, []byte(newIdentity.Hash))
+		if signErr != nil {
+			return NewError(fmt.Sprintf("failed to sign identity: %v", signErr), ExitGPGError)
+		}
+		newIdentity.Signature = signature
+
+		if err := c.vaultResolver.AddIdentity(newIdentity, idx); err != nil {
+			return NewError(fmt.Sprintf("failed to add identity: %v", err), ExitVaultError)
+		}
+
+		_, _ = fmt.Fprintf(c.output.Stdout(), "imported: identity %s (%s)\n", id.Fingerprint, id.UID)
+		imported++
+	}
+
+	if imported > 0 {
+		if err := c.vaultResolver.SaveVault(idx); err != nil {
+			return NewError(fmt.Sprintf("failed to save vault: %v", err), ExitVaultError)
+		}
+		if hookErr := c.runPostWriteHook(idx); hookErr != nil {
+			return hookErr
+		}
+	}
+
+	_, _ = fmt.Fprintf(c.output.Stdout(), "summary: imported=%d already-present=%d rejected=%d\n", imported, present, rejected)
+
+	if rejected > 0 {
+		return NewError(fmt.Sprintf("%d identity(ies) failed verification and were not imported", rejected), ExitValidationError)
+	}
+	return nil
+}
+
+// checkKeyExpiry warns when the key for fingerprint has expired or expires
+// within expireWarnDays, or fails with failOnExpiring set. Keys without an
+// expiry pass. A key whose info can't be read is left to the add itself to
+// report.
+func (c *CLI) checkKeyExpiry(fingerprint string) *Error {
+	publicKeyInfo, err := c.gpgClient.GetPublicKeyInfo(fingerprint)
+	if err != nil || publicKeyInfo.ExpiresAt == nil {
+		return nil
+	}
+
+	expiresAt := *publicKeyInfo.ExpiresAt
+	until := time.Until(expiresAt)
+	if until > time.Duration(c.expireWarnDays)*24*time.Hour {
+		return nil
+	}
+
+	var msg string
+	if until <= 0 {
+		msg = fmt.Sprintf("key %s expired on %s", fingerprint, expiresAt.UTC().Format(time.DateOnly))
+	} else {
+		days := int((until + 24*time.Hour - 1) / (24 * time.Hour))
+		msg = fmt.Sprintf("key %s expires in %d day(s), on %s", fingerprint, days, expiresAt.UTC().Format(time.DateOnly))
+	}
+
+	if c.failOnExpiring {
+		return NewError(msg+"\nRenew the key or use one with a later expiry, or drop --fail-on-expiring to add it anyway", ExitValidationError)
+	}
+
+	_, _ = fmt.Fprintf(c.output.Stderr(), "warning: %s\n", msg)
+	_, _ = fmt.Fprintf(c.output.Stderr(), "warning: after it expires, secrets can no longer be shared with it; renew the key or use another one\n")
+	return nil
+}
+
 // addIdentityToVault builds, signs, and adds an identity to the vault at the given index.
 // signerFingerprint is the current user's key used to sign (vouch for) the new identity.
 func (c *CLI) addIdentityToVault(fingerprint string, signerFingerprint string, index int) *Error {
+	if err := c.signIdentityIntoVault(fingerprint, signerFingerprint, index); err != nil {
+		return err
+	}
+
+	if err := c.vaultResolver.SaveVault(index); err != nil {
+		return NewError(fmt.Sprintf("failed to save vault: %v", err), ExitVaultError)
+	}
+	if hookErr := c.runPostWriteHook(index); hookErr != nil {
+		return hookErr
+	}
+
+	return nil
+}
+
+// signIdentityIntoVault is addIdentityToVault without saving the vault.
+func (c *CLI) signIdentityIntoVault(fingerprint string, signerFingerprint string, index int) *Error {
 	publicKeyInfo, pubKeyErr := c.gpgClient.GetPublicKeyInfo(fingerprint)
 	if pubKeyErr != nil {
 		return NewError(fmt.Sprintf("failed to get public key: %v", pubKeyErr), ExitGPGError)
@@ -122,11 +324,6 @@ func (c *CLI) addIdentityToVault(fingerprint string, signerFingerprint string, i
 	if err := c.vaultResolver.AddIdentity(newIdentity, index); err != nil {
 		return NewError(fmt.Sprintf("failed to add identity: %v", err), ExitVaultError)
 	}
-
-	if err := c.vaultResolver.SaveVault(index); err != nil {
-		return NewError(fmt.Sprintf("failed to save vault: %v", err), ExitVaultError)
-	}
-
 	return nil
 }
 
@@ -134,13 +331,27 @@ func (c *CLI) addIdentityToVault(fingerprint string, signerFingerprint string, i
 // If the identity doesn't exist, it will be auto-added with a warning.
 // The current user's key is used to sign (vouch for) the new identity.
 func (c *CLI) ensureIdentityInVault(fingerprint string, index int) *Error {
+	newIdentity, err := c.autoAddIdentity(fingerprint, index)
+	if err != nil || newIdentity == nil {
+		return err
+	}
+	if err := c.vaultResolver.AddIdentity(*newIdentity, index); err != nil {
+		return NewError(fmt.Sprintf("failed to add identity: %v", err), ExitVaultError)
+	}
+	return nil
+}
+
+// autoAddIdentity builds and signs the identity ensureIdentityInVault adds
+// for fingerprint, warning that it is being added, without adding it. It
+// returns nil when the identity is already in the vault at index.
+func (c *CLI) autoAddIdentity(fingerprint string, index int) (*vault.Identity, *Error) {
 	if c.vaultResolver.IdentityExistsInVault(fingerprint, index) {
-		return nil
+		return nil, nil
 	}
 
 	signerFP, fpErr := c.checkFingerprintRequired("identity auto-add")
 	if fpErr != nil {
-		return fpErr
+		return nil, fpErr
 	}
 
 	// Auto-add with warning
@@ -151,15 +362,15 @@ func (c *CLI) ensureIdentityInVault(fingerprint string, index int) *Error {
 
 	publicKeyInfo, pubKeyErr := c.gpgClient.GetPublicKeyInfo(fingerprint)
 	if pubKeyErr != nil {
-		return NewError(fmt.Sprintf("failed to get public key: %v", pubKeyErr), ExitGPGError)
+		return nil, NewError(fmt.Sprintf("failed to get public key: %v", pubKeyErr), ExitGPGError)
 	}
 
 	if !c.config.IsAlgorithmAllowed(publicKeyInfo.Algorithm, publicKeyInfo.AlgorithmBits) {
-		return NewError(fmt.Sprintf("algorithm not allowed: %s (%d bits)\n%s", publicKeyInfo.Algorithm, publicKeyInfo.AlgorithmBits, c.config.GetAllowedAlgorithmsString()), ExitAlgorithmNotAllowed)
+		return nil, NewError(fmt.Sprintf("algorithm not allowed: %s (%d bits)\n%s", publicKeyInfo.Algorithm, publicKeyInfo.AlgorithmBits, c.config.GetAllowedAlgorithmsString()), ExitAlgorithmNotAllowed)
 	}
 
 	if !publicKeyInfo.CanEncrypt {
-		return NewError(fmt.Sprintf("key %s is not capable of encryption (signing-only key).\nPlease ensure your key has an encryption subkey.", fingerprint), ExitGPGError)
+		return nil, NewError(fmt.Sprintf("key %s is not capable of encryption (signing-only key).\nPlease ensure your key has an encryption subkey.", fingerprint), ExitGPGError)
 	}
 
 	now := time.Now().UTC()
@@ -182,13 +393,8 @@ func (c *CLI) ensureIdentityInVault(fingerprint string, index int) *Error {
 
 	signature, signErr := c.gpgClient.SignDataWithAgent(signerFP, []byte(newIdentity.Hash))
 	if signErr != nil {
-		return NewError(fmt.Sprintf("failed to sign identity: %v", signErr), ExitGPGError)
+		return nil, NewError(fmt.Sprintf("failed to sign identity: %v", signErr), ExitGPGError)
 	}
 	newIdentity.Signature = signature
-
-	if err := c.vaultResolver.AddIdentity(newIdentity, index); err != nil {
-		return NewError(fmt.Sprintf("failed to add identity: %v", err), ExitVaultError)
-	}
-
-	return nil
+	return &newIdentity, nil
 }
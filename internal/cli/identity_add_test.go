@@ -2,12 +2,19 @@ package cli
 
 import (
 	"bytes"
+	"encoding/base64"
+	"encoding/hex"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/gpg"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
 	"golang.org/x/term"
@@ -260,3 +267,250 @@ func TestIdentityAdd_AlgorithmNotAllowed(t *testing.T) {
 		t.Errorf("expected ExitAlgorithmNotAllowed, got %d", err.ExitCode)
 	}
 }
+
+func TestIdentityAdd_ExpiringKey(t *testing.T) {
+	paths := createTempVaultFiles(t, 1)
+	cli, mock, gpgMock, _, stderr := newIdentityAddCLI(t, paths)
+	cli.SetExpiryWarning(30, false)
+
+	info := gpgMock.PublicKeyInfo["AABBCCDD"]
+	expiresAt := time.Now().Add(10*24*time.Hour - time.Minute)
+	info.ExpiresAt = &expiresAt
+	gpgMock.PublicKeyInfo["AABBCCDD"] = info
+
+	if err := cli.IdentityAdd("AABBCCDD", false, "", 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !mock.IdentityExistsInVault("AABBCCDD", 0) {
+		t.Error("expiring identity should still be added without --fail-on-expiring")
+	}
+	if !strings.Contains(stderr.String(), "warning: key AABBCCDD expires in 10 day(s)") {
+		t.Errorf("expected expiry warning, got: %s", stderr.String())
+	}
+}
+
+func TestIdentityAdd_ExpiringKeyFails(t *testing.T) {
+	paths := createTempVaultFiles(t, 1)
+	cli, mock, gpgMock, _, _ := newIdentityAddCLI(t, paths)
+	cli.SetExpiryWarning(30, true)
+
+	info := gpgMock.PublicKeyInfo["AABBCCDD"]
+	expiresAt := time.Now().Add(10 * 24 * time.Hour)
+	info.ExpiresAt = &expiresAt
+	gpgMock.PublicKeyInfo["AABBCCDD"] = info
+
+	err := cli.IdentityAdd("AABBCCDD", false, "", 0)
+	switch {
+	case err == nil:
+		t.Fatal("expected --fail-on-expiring to reject the key")
+	case err.ExitCode != ExitValidationError:
+		t.Errorf("expected ExitValidationError, got %d", err.ExitCode)
+	}
+	if mock.IdentityExistsInVault("AABBCCDD", 0) {
+		t.Error("rejected identity must not be added")
+	}
+
+	// Outside the window the key is added without complaint.
+	cli.SetExpiryWarning(5, true)
+	if err := cli.IdentityAdd("AABBCCDD", false, "", 0); err != nil {
+		t.Fatalf("unexpected error outside warning window: %v", err)
+	}
+}
+
+func TestIdentityAdd_NoExpiry(t *testing.T) {
+	paths := createTempVaultFiles(t, 1)
+	cli, mock, _, _, stderr := newIdentityAddCLI(t, paths)
+	cli.SetExpiryWarning(30, true)
+
+	if err := cli.IdentityAdd("AABBCCDD", false, "", 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !mock.IdentityExistsInVault("AABBCCDD", 0) {
+		t.Error("identity not added")
+	}
+	if strings.Contains(stderr.String(), "expire") {
+		t.Errorf("unexpected expiry warning for key without expiry: %s", stderr.String())
+	}
+}
+
+func TestIdentityAddAllMissing(t *testing.T) {
+	paths := createTempVaultFiles(t, 3)
+	cli, mock, _, stdout, stderr := newIdentityAddCLI(t, paths)
+
+	// Identity already present in vault 2 only
+	mock.IdentitiesByVault[1] = map[string]vault.Identity{
+		"AABBCCDD": {Fingerprint: "AABBCCDD"},
+	}
+
+	if err := cli.IdentityAddAllMissing("AABBCCDD", "", 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := range paths {
+		if !mock.IdentityExistsInVault("AABBCCDD", i) {
+			t.Errorf("identity missing from vault %d", i)
+		}
+	}
+
+	out := stdout.String()
+	if !strings.Contains(out, "added: identity AABBCCDD to vault 1") || !strings.Contains(out, "added: identity AABBCCDD to vault 3") {
+		t.Errorf("expected vaults 1 and 3 to be reported as added, got: %s", out)
+	}
+	if strings.Contains(out, "to vault 2") {
+		t.Errorf("vault 2 already had the identity and should not be reported, got: %s", out)
+	}
+	if !strings.Contains(out, "summary: added=2 already-present=1\n") {
+		t.Errorf("unexpected summary, got: %s", out)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("expected no stderr output, got: %s", stderr.String())
+	}
+
+	// Rerunning is a no-op success
+	stdout.Reset()
+	if err := cli.IdentityAddAllMissing("AABBCCDD", "", 0); err != nil {
+		t.Fatalf("unexpected error on rerun: %v", err)
+	}
+	if got := stdout.String(); got != "summary: added=0 already-present=3\n" {
+		t.Errorf("unexpected rerun output: %q", got)
+	}
+}
+
+func TestIdentityAddAllMissing_HonorsVaultSelection(t *testing.T) {
+	paths := createTempVaultFiles(t, 3)
+	cli, mock, _, stdout, _ := newIdentityAddCLI(t, paths)
+
+	if err := cli.IdentityAddAllMissing("AABBCCDD", "", 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := range paths {
+		if got := mock.IdentityExistsInVault("AABBCCDD", i); got != (i == 1) {
+			t.Errorf("vault %d: identity present = %v, want %v", i+1, got, i == 1)
+		}
+	}
+	if !strings.Contains(stdout.String(), "summary: added=1 already-present=0\n") {
+		t.Errorf("unexpected summary, got: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := cli.IdentityAddAllMissing("AABBCCDD", "", 2); err != nil {
+		t.Fatalf("unexpected error on rerun: %v", err)
+	}
+	if got := stdout.String(); got != "summary: added=0 already-present=1\n" {
+		t.Errorf("unexpected rerun output: %q", got)
+	}
+}
+
+// newSignedVaultIdentity generates a fresh key for name and returns it with a
+// vault identity signed by signer, or self-signed when signer is nil.
+func newSignedVaultIdentity(t *testing.T, name string, signer *openpgp.Entity) (*openpgp.Entity, vault.Identity) {
+	t.Helper()
+	email := strings.ToLower(name) + "@example.com"
+	entity, err := openpgp.NewEntity(name, "", email, &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	if signer == nil {
+		signer = entity
+	}
+
+	var pub bytes.Buffer
+	if err := entity.Serialize(&pub); err != nil {
+		t.Fatalf("failed to serialize key: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	id := vault.Identity{
+		AddedAt:       now,
+		Fingerprint:   strings.ToUpper(hex.EncodeToString(entity.PrimaryKey.Fingerprint)),
+		UID:           name + " <" + email + ">",
+		Algorithm:     "EdDSA",
+		AlgorithmBits: 255,
+		Curve:         "Ed25519",
+		CreatedAt:     now,
+		PublicKey:     base64.StdEncoding.EncodeToString(pub.Bytes()),
+		SignedBy:      strings.ToUpper(hex.EncodeToString(signer.PrimaryKey.Fingerprint)),
+	}
+	id.Hash = identity.ComputeIdentityHash(&id)
+
+	var sig bytes.Buffer
+	if err := openpgp.DetachSign(&sig, signer, strings.NewReader(id.Hash), nil); err != nil {
+		t.Fatalf("failed to sign identity: %v", err)
+	}
+	id.Signature = hex.EncodeToString(sig.Bytes())
+	return entity, id
+}
+
+func TestIdentityImport_CopiesVerifiedIdentities(t *testing.T) {
+	alice, aliceID := newSignedVaultIdentity(t, "Alice", nil)
+	_, bobID := newSignedVaultIdentity(t, "Bob", alice)
+	_, malloryID := newSignedVaultIdentity(t, "Mallory", alice)
+	// Tampered after signing, so the stored hash no longer matches.
+	malloryID.UID = "Carol <carol@example.com>"
+
+	sourcePath := filepath.Join(t.TempDir(), "source.vault")
+	w, err := vault.NewWriter(sourcePath)
+	if err != nil {
+		t.Fatalf("failed to create source vault: %v", err)
+	}
+	if err := w.RewriteFromVault(vault.Vault{
+		Identities: []vault.Identity{aliceID, bobID, malloryID},
+		Secrets: []vault.Secret{
+			{Key: "SOURCE_ONLY", AddedAt: time.Now().UTC(), Values: []vault.SecretValue{{AddedAt: time.Now().UTC(), Value: "c2VjcmV0"}}},
+		},
+	}); err != nil {
+		t.Fatalf("failed to write source vault: %v", err)
+	}
+
+	paths := createTempVaultFiles(t, 1)
+	cli, mock, _, stdout, stderr := newIdentityAddCLI(t, paths)
+	mock.IdentitiesByVault[0] = map[string]vault.Identity{bobID.Fingerprint: bobID}
+
+	importErr := cli.IdentityImport(sourcePath, "", 1)
+	if importErr == nil || importErr.ExitCode != ExitValidationError {
+		t.Fatalf("expected ExitValidationError for the tampered identity, got %v", importErr)
+	}
+
+	imported, ok := mock.IdentitiesByVault[0][aliceID.Fingerprint]
+	if !ok {
+		t.Fatalf("Alice was not imported; stderr: %s", stderr.String())
+	}
+	if imported.SignedBy != "MYFINGERPRINT" || imported.Signature != "signature_by_MYFINGERPRINT" {
+		t.Errorf("imported identity should be re-signed by the importer, got signed_by=%s signature=%s", imported.SignedBy, imported.Signature)
+	}
+	if imported.PublicKey != aliceID.PublicKey || imported.UID != aliceID.UID {
+		t.Error("imported identity should keep the source key and UID")
+	}
+	if imported.Hash != identity.ComputeIdentityHash(&imported) {
+		t.Error("imported identity hash does not match its fields")
+	}
+	if mock.IdentitiesByVault[0][bobID.Fingerprint].SignedBy != bobID.SignedBy {
+		t.Error("identity already in the target should be left untouched")
+	}
+	if _, ok := mock.IdentitiesByVault[0][malloryID.Fingerprint]; ok {
+		t.Error("identity failing verification must not be imported")
+	}
+	if len(mock.Secrets[0]) != 0 {
+		t.Error("secrets must not be copied")
+	}
+	if len(mock.SavedVaults) != 1 || mock.SavedVaults[0] != 0 {
+		t.Errorf("expected target vault to be saved once, got %v", mock.SavedVaults)
+	}
+
+	if !strings.Contains(stderr.String(), "rejected: identity "+malloryID.Fingerprint) {
+		t.Errorf("expected rejection report, got: %s", stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "summary: imported=1 already-present=1 rejected=1") {
+		t.Errorf("unexpected summary: %s", stdout.String())
+	}
+}
+
+func TestIdentityImport_SameVault(t *testing.T) {
+	paths := createTempVaultFiles(t, 1)
+	cli, _, _, _, _ := newIdentityAddCLI(t, paths)
+
+	err := cli.IdentityImport(paths[0], "", 1)
+	if err == nil || !strings.Contains(err.Message, "same") {
+		t.Fatalf("expected same-vault error, got %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"strings"
 
@@ -15,11 +16,13 @@ import (
 
 // IdentityCreateOptions holds options for the identity create command.
 type IdentityCreateOptions struct {
-	Algorithm    string // Algorithm to use (ED25519, RSA4096, P384, P521)
-	Name         string // User's full name
-	Email        string // User's email address
-	TemplateOnly bool   // If true, only output the template without generating
-	NoPassphrase bool   // If true, create key without passphrase (for CI/automation)
+	Algorithm            string // Algorithm to use (ED25519, RSA4096, P384, P521)
+	Name                 string // User's full name
+	Email                string // User's email address
+	TemplateOnly         bool   // If true, only output the template without generating
+	NoPassphrase         bool   // If true, create key without passphrase (for CI/automation)
+	TemplateFile         string // Custom GPG batch template used instead of the generated one
+	PrintFingerprintOnly bool   // If true, stdout gets only the new key's fingerprint; other output goes to stderr
 }
 
 // identityCreateIO holds the I/O streams for identity creation.
@@ -81,9 +84,44 @@ func identityCreateCore(opts IdentityCreateOptions, cfg *config.Config, io *iden
 		return NewError("--no-passphrase requires --name and --email flags", ExitGeneralError)
 	}
 
+	if opts.PrintFingerprintOnly && opts.TemplateOnly {
+		return NewError("--print-fingerprint-only cannot be used with --template", ExitGeneralError)
+	}
+
+	// Human-readable output; stdout is kept for the fingerprint alone when
+	// it is to be captured by a script.
+	out := io.stdout
+	if opts.PrintFingerprintOnly {
+		out = io.stderr
+	}
+
+	// A custom template supplies the name and email when not given as flags
+	var customTemplate string
+	var customParams map[string]string
+	if opts.TemplateFile != "" {
+		if opts.NoPassphrase {
+			return NewError("--no-passphrase cannot be used with --template-file; add %no-protection to the template instead", ExitGeneralError)
+		}
+		data, err := os.ReadFile(opts.TemplateFile)
+		if err != nil {
+			return NewError(fmt.Sprintf("failed to read template: %v", err), ExitGeneralError)
+		}
+		customTemplate = string(data)
+		customParams, err = gpg.ParseKeyTemplate(customTemplate)
+		if err != nil {
+			return NewError(fmt.Sprintf("invalid template %s: %v", opts.TemplateFile, err), ExitValidationError)
+		}
+		if opts.Name == "" {
+			opts.Name = customParams["name-real"]
+		}
+		if opts.Email == "" {
+			opts.Email = customParams["name-email"]
+		}
+	}
+
 	// Print algorithm before prompting for interactive input
 	if opts.Name == "" || opts.Email == "" {
-		_, _ = fmt.Fprintf(io.stdout, "Generating %s key...\n", opts.Algorithm)
+		_, _ = fmt.Fprintf(out, "Generating %s key...\n", opts.Algorithm)
 	}
 
 	// Prompt for name if not provided
@@ -112,15 +150,26 @@ func identityCreateCore(opts IdentityCreateOptions, cfg *config.Config, io *iden
 		}
 	}
 
-	// Build template options
-	templateOpts := &gpg.KeyTemplateOptions{
-		NoPassphrase: opts.NoPassphrase,
-	}
+	var template string
+	noPassphrase := opts.NoPassphrase
+	if opts.TemplateFile != "" {
+		if err := gpg.ValidateKeyTemplate(customTemplate, algo, name, email); err != nil {
+			return NewError(fmt.Sprintf("invalid template %s: %v", opts.TemplateFile, err), ExitValidationError)
+		}
+		template = customTemplate
+		_, noPassphrase = customParams["%no-protection"]
+	} else {
+		// Build template options
+		templateOpts := &gpg.KeyTemplateOptions{
+			NoPassphrase: opts.NoPassphrase,
+		}
 
-	// Generate the template
-	template, templateErr := gpg.GenerateKeyTemplate(algo, name, email, templateOpts)
-	if templateErr != nil {
-		return NewError(fmt.Sprintf("failed to generate template: %v", templateErr), ExitGeneralError)
+		// Generate the template
+		var templateErr error
+		template, templateErr = gpg.GenerateKeyTemplate(algo, name, email, templateOpts)
+		if templateErr != nil {
+			return NewError(fmt.Sprintf("failed to generate template: %v", templateErr), ExitGeneralError)
+		}
 	}
 
 	// If template-only mode, print shell-friendly wrapper
@@ -130,11 +179,11 @@ func identityCreateCore(opts IdentityCreateOptions, cfg *config.Config, io *iden
 	}
 
 	// Generate the key using GPG
-	_, _ = fmt.Fprintf(io.stdout, "Generating %s key for %s <%s>...\n", opts.Algorithm, name, email)
-	if opts.NoPassphrase {
+	_, _ = fmt.Fprintf(out, "Generating %s key for %s <%s>...\n", opts.Algorithm, name, email)
+	if noPassphrase {
 		_, _ = fmt.Fprintf(io.stderr, "WARNING: Creating key without passphrase. Only use for CI/automation.\n\n")
 	} else {
-		_, _ = fmt.Fprintf(io.stdout, "GPG will prompt for a passphrase to protect your key.\n\n")
+		_, _ = fmt.Fprintf(out, "GPG will prompt for a passphrase to protect your key.\n\n")
 	}
 
 	fingerprint, genErr := generateGPGKeyWithTemplate(template)
@@ -143,7 +192,10 @@ func identityCreateCore(opts IdentityCreateOptions, cfg *config.Config, io *iden
 	}
 
 	// Print success output
-	printSuccessOutput(io.stdout, name, email, opts.Algorithm, fingerprint, hasConfig)
+	printSuccessOutput(out, name, email, opts.Algorithm, fingerprint, hasConfig)
+	if opts.PrintFingerprintOnly {
+		_, _ = fmt.Fprintln(io.stdout, fingerprint)
+	}
 
 	return nil
 }
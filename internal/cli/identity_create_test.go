@@ -2,8 +2,14 @@ package cli
 
 import (
 	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/gpg"
 )
 
 func TestPrintSuccessOutput_WithConfig(t *testing.T) {
@@ -53,3 +59,133 @@ func TestPrintSuccessOutput_WithoutConfig(t *testing.T) {
 		t.Error("should not show single next step format when no config exists")
 	}
 }
+
+// writeKeyTemplate writes an ED25519 batch template for name that
+// expires in 30 days.
+func writeKeyTemplate(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "params")
+	template := `# Custom parameters
+Key-Type: eddsa
+Key-Curve: ed25519
+Key-Usage: sign
+Subkey-Type: ecdh
+Subkey-Curve: cv25519
+Subkey-Usage: encrypt
+Name-Real: ` + name + `
+Name-Email: test@example.com
+Expire-Date: 30d
+%no-protection
+%commit
+`
+	if err := os.WriteFile(path, []byte(template), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// useTempGPGHome points GnuPG at an empty home directory for the test,
+// skipping it when gpg is not installed.
+func useTempGPGHome(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not found in PATH")
+	}
+	// Keep the agent socket path short
+	gpgHome, err := os.MkdirTemp("/tmp", "gpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = exec.Command("gpgconf", "--homedir", gpgHome, "--kill", "gpg-agent").Run()
+		_ = os.RemoveAll(gpgHome)
+	})
+	t.Setenv("GNUPGHOME", gpgHome)
+	if err := gpg.ValidateAndSetGPGProgram("PATH"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIdentityCreate_TemplateFileWithExpiration(t *testing.T) {
+	useTempGPGHome(t)
+
+	cfg := config.DefaultConfig()
+	stdout := &bytes.Buffer{}
+	opts := IdentityCreateOptions{Algorithm: "ED25519", TemplateFile: writeKeyTemplate(t, "Test User")}
+	if createErr := identityCreateCore(opts, &cfg, &identityCreateIO{stdout: stdout, stderr: &bytes.Buffer{}}, true); createErr != nil {
+		t.Fatalf("identity create failed: %v", createErr)
+	}
+	if !strings.Contains(stdout.String(), "Created GPG key for Test User <test@example.com>") {
+		t.Errorf("name and email should come from the template:\n%s", stdout.String())
+	}
+
+	out, err := exec.Command("gpg", "--list-keys", "--with-colons", "test@example.com").Output()
+	if err != nil {
+		t.Fatalf("failed to list keys: %v", err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Split(line, ":")
+		if fields[0] == "pub" {
+			if len(fields) < 7 || fields[6] == "" {
+				t.Errorf("expected the key to expire, got %q", line)
+			}
+			return
+		}
+	}
+	t.Fatalf("generated key not found:\n%s", out)
+}
+
+func TestIdentityCreate_PrintFingerprintOnly(t *testing.T) {
+	useTempGPGHome(t)
+
+	cfg := config.DefaultConfig()
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	opts := IdentityCreateOptions{Algorithm: "ED25519", TemplateFile: writeKeyTemplate(t, "Script User"), PrintFingerprintOnly: true}
+	if createErr := identityCreateCore(opts, &cfg, &identityCreateIO{stdout: stdout, stderr: stderr}, true); createErr != nil {
+		t.Fatalf("identity create failed: %v", createErr)
+	}
+
+	fingerprint := strings.TrimSuffix(stdout.String(), "\n")
+	if len(fingerprint) != 40 || strings.Trim(fingerprint, "0123456789ABCDEF") != "" {
+		t.Fatalf("stdout should hold only the fingerprint, got %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "Fingerprint: "+fingerprint) {
+		t.Errorf("the human-readable output should go to stderr, got:\n%s", stderr.String())
+	}
+}
+
+func TestIdentityCreate_PrintFingerprintOnlyWithTemplate(t *testing.T) {
+	cfg := config.DefaultConfig()
+	stdout := &bytes.Buffer{}
+	opts := IdentityCreateOptions{Algorithm: "ED25519", Name: "A", Email: "a@example.com", TemplateOnly: true, PrintFingerprintOnly: true}
+	if err := identityCreateCore(opts, &cfg, &identityCreateIO{stdout: stdout, stderr: &bytes.Buffer{}}, true); err == nil {
+		t.Fatal("expected --print-fingerprint-only to be rejected with --template")
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("nothing should be printed, got:\n%s", stdout.String())
+	}
+}
+
+func TestIdentityCreate_TemplateFileMustMatch(t *testing.T) {
+	cfg := config.DefaultConfig()
+	io := &identityCreateIO{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}}
+	path := writeKeyTemplate(t, "Test User")
+
+	opts := IdentityCreateOptions{Algorithm: "ED25519", Name: "Someone Else", TemplateFile: path}
+	err := identityCreateCore(opts, &cfg, io, true)
+	if err == nil || err.ExitCode != ExitValidationError || !strings.Contains(err.Message, "Name-Real") {
+		t.Errorf("expected a Name-Real mismatch, got %v", err)
+	}
+
+	// The template generates an EdDSA key, not the RSA key requested
+	opts = IdentityCreateOptions{Algorithm: "RSA4096", TemplateFile: path}
+	err = identityCreateCore(opts, &cfg, io, true)
+	if err == nil || !strings.Contains(err.Message, "Key-Type") {
+		t.Errorf("expected a Key-Type mismatch, got %v", err)
+	}
+
+	opts = IdentityCreateOptions{Algorithm: "ED25519", Name: "Test User", Email: "test@example.com", NoPassphrase: true, TemplateFile: path}
+	if err := identityCreateCore(opts, &cfg, io, true); err == nil {
+		t.Error("expected --no-passphrase to be refused with --template-file")
+	}
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
+)
+
+// IdentityRefresh re-reads the UID, expiry and public key of the identity
+// fingerprint from the GPG keyring and, in each configured vault holding it
+// (or only the vault at fromIndex) where they changed, appends a new record
+// signed by the current user. The earlier record stays in the vault file
+// for history; readers see only the new one.
+func (c *CLI) IdentityRefresh(fingerprint string, fromIndex int) *Error {
+	signerFP, fpErr := c.checkFingerprintRequired("identity refresh")
+	if fpErr != nil {
+		return fpErr
+	}
+
+	fingerprint = identity.NormalizeFingerprint(fingerprint)
+	if fingerprint == "" {
+		return NewError("fingerprint must not be empty", ExitValidationError)
+	}
+	entries := c.vaultResolver.GetConfig().Entries
+	if fromIndex > len(entries) {
+		return NewError(fmt.Sprintf("-v index %d exceeds number of configured vaults (%d)", fromIndex, len(entries)), ExitGeneralError)
+	}
+
+	publicKeyInfo, err := c.gpgClient.GetPublicKeyInfo(fingerprint)
+	if err != nil {
+		return NewError(fmt.Sprintf("failed to get public key: %v", err), ExitGPGError)
+	}
+
+	found := false
+	for i, entry := range entries {
+		if fromIndex != 0 && fromIndex != i+1 {
+			continue
+		}
+		manager := c.vaultResolver.GetVaultManager(i)
+		if manager == nil {
+			continue
+		}
+		stored := manager.GetIdentityByFingerprint(fingerprint)
+		if stored == nil {
+			continue
+		}
+		found = true
+
+		if stored.UID == publicKeyInfo.UID && sameExpiry(stored.ExpiresAt, publicKeyInfo.ExpiresAt) && stored.PublicKey == publicKeyInfo.PublicKeyBase64 {
+			_, _ = fmt.Fprintf(c.output.Stderr(), "unchanged: identity %s in vault %d (%s)\n", fingerprint, i+1, entry.Path)
+			continue
+		}
+
+		oldUID := stored.UID
+		refreshed := *stored
+		refreshed.AddedAt = time.Now().UTC()
+		refreshed.UID = publicKeyInfo.UID
+		refreshed.ExpiresAt = publicKeyInfo.ExpiresAt
+		refreshed.PublicKey = publicKeyInfo.PublicKeyBase64
+		refreshed.SignedBy = signerFP
+		refreshed.Hash = identity.ComputeIdentityHash(&refreshed)
+
+		signature, signErr := c.gpgClient.SignDataWithAgent(signerFP, []byte(refreshed.Hash))
+		if signErr != nil {
+			return NewError(fmt.Sprintf("failed to sign identity: %v", signErr), ExitGPGError)
+		}
+		refreshed.Signature = signature
+
+		if err := c.vaultResolver.ReplaceIdentity(refreshed, i); err != nil {
+			return NewError(fmt.Sprintf("failed to refresh identity in vault %d: %v", i+1, err), ExitVaultError)
+		}
+		if err := c.vaultResolver.SaveVault(i); err != nil {
+			return NewError(fmt.Sprintf("failed to save vault: %v", err), ExitVaultError)
+		}
+		if hookErr := c.runPostWriteHook(i); hookErr != nil {
+			return hookErr
+		}
+
+		_, _ = fmt.Fprintf(c.output.Stdout(), "refreshed: identity %s in vault %d (%s)\n", fingerprint, i+1, entry.Path)
+		if oldUID != refreshed.UID {
+			_, _ = fmt.Fprintf(c.output.Stdout(), "  UID: %s -> %s\n", oldUID, refreshed.UID)
+		}
+	}
+
+	if !found {
+		if fromIndex != 0 {
+			return NewError(fmt.Sprintf("identity %s not found in vault %d", fingerprint, fromIndex), ExitVaultError)
+		}
+		return NewError(fmt.Sprintf("identity %s not found in any vault", fingerprint), ExitVaultError)
+	}
+	return nil
+}
+
+// sameExpiry reports whether two optional expiry times are equal.
+func sameExpiry(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
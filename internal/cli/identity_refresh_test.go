@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/gpg"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vaulttest"
+)
+
+func TestIdentityRefresh_UpdatesUID(t *testing.T) {
+	alice, err := vaulttest.NewIdentity("Alice", "alice@example.com")
+	if err != nil {
+		t.Fatalf("NewIdentity failed: %v", err)
+	}
+	v, err := vaulttest.BuildSignedVault([]*vaulttest.Identity{alice}, nil)
+	if err != nil {
+		t.Fatalf("BuildSignedVault failed: %v", err)
+	}
+	manager := newTestManager(t, v)
+	stored := manager.Get().Identities[0]
+
+	mock := NewMockVaultResolver()
+	mock.VaultEntries = []vault.VaultEntry{{Path: manager.Path()}}
+	mock.Managers = map[int]*vault.Manager{0: manager}
+
+	// The key now carries a new email address
+	gpgMock := NewMockGPGClient()
+	gpgMock.PublicKeyInfo[stored.Fingerprint] = gpg.KeyInfo{
+		Fingerprint:     stored.Fingerprint,
+		UID:             "Alice <alice@new.example.com>",
+		PublicKeyBase64: stored.PublicKey,
+	}
+
+	stdout := &bytes.Buffer{}
+	cli := &CLI{
+		config:        config.Config{Login: newTestSignedLogin(t, "MYFINGERPRINT")},
+		vaultResolver: mock,
+		gpgClient:     gpgMock,
+		output:        output.NewHandler(stdout, &bytes.Buffer{}),
+	}
+
+	if err := cli.IdentityRefresh(stored.Fingerprint, 0); err != nil {
+		t.Fatalf("IdentityRefresh failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "UID: "+stored.UID+" -> Alice <alice@new.example.com>") {
+		t.Errorf("expected the UID change in output, got:\n%s", stdout.String())
+	}
+
+	reader, err := vault.NewWriterReadOnly(manager.Path())
+	if err != nil {
+		t.Fatalf("failed to reopen vault: %v", err)
+	}
+	reread, err := reader.ReadVault()
+	if err != nil {
+		t.Fatalf("failed to read vault: %v", err)
+	}
+	refreshed := reread.GetIdentityByFingerprint(stored.Fingerprint)
+	if refreshed == nil || refreshed.UID != "Alice <alice@new.example.com>" {
+		t.Fatalf("expected the refreshed UID in the vault, got %+v", refreshed)
+	}
+	if refreshed.SignedBy != "MYFINGERPRINT" || refreshed.Signature != "signature_by_MYFINGERPRINT" {
+		t.Errorf("expected the refreshed record signed by the current user, got %s / %s", refreshed.SignedBy, refreshed.Signature)
+	}
+	if refreshed.Hash != identity.ComputeIdentityHash(refreshed) {
+		t.Errorf("refreshed record hash does not match its data")
+	}
+
+	// Refreshing again finds nothing to change
+	stdout.Reset()
+	if err := cli.IdentityRefresh(stored.Fingerprint, 0); err != nil {
+		t.Fatalf("second IdentityRefresh failed: %v", err)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("expected no refresh on the second run, got:\n%s", stdout.String())
+	}
+}
+
+func TestIdentityRefresh_NotFound(t *testing.T) {
+	cli, _, gpgMock, _, _ := newIdentityAddCLI(t, []string{"/tmp/vault1"})
+	gpgMock.PublicKeyInfo["AABBCCDD"] = gpg.KeyInfo{Fingerprint: "AABBCCDD", UID: "Alice"}
+
+	err := cli.IdentityRefresh("AABBCCDD", 0)
+	if err == nil || err.ExitCode != ExitVaultError {
+		t.Fatalf("expected a vault error, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
+)
+
+// IdentityShowJSON is the JSON output of 'identity show' for one vault
+// holding the identity.
+type IdentityShowJSON struct {
+	Position      int        `json:"position"`
+	Vault         string     `json:"vault"`
+	UID           string     `json:"uid"`
+	Fingerprint   string     `json:"fingerprint"`
+	Algorithm     string     `json:"algorithm"`
+	AlgorithmBits int        `json:"algorithm_bits"`
+	Curve         string     `json:"curve,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+	AddedAt       time.Time  `json:"added_at"`
+	SignedBy      string     `json:"signed_by"`
+	Hash          string     `json:"hash"`
+	Verified      bool       `json:"verified"`
+	VerifyError   string     `json:"verify_error,omitempty"`
+}
+
+// IdentityShow prints the identity fingerprint as stored in each configured
+// vault holding it, or only in the vault at fromIndex, with the result of
+// verifying its signature there. It fails if no vault holds the identity,
+// or if its signature does not verify in one of them.
+func (c *CLI) IdentityShow(fingerprint string, fromIndex int, jsonOutput bool) *Error {
+	fingerprint = identity.NormalizeFingerprint(fingerprint)
+	if fingerprint == "" {
+		return NewError("fingerprint must not be empty", ExitValidationError)
+	}
+	entries := c.vaultResolver.GetConfig().Entries
+	if fromIndex > len(entries) {
+		return NewError(fmt.Sprintf("-v index %d exceeds number of configured vaults (%d)", fromIndex, len(entries)), ExitGeneralError)
+	}
+
+	var shown []IdentityShowJSON
+	signers := make(map[string]string)
+	for i, entry := range entries {
+		if fromIndex != 0 && fromIndex != i+1 {
+			continue
+		}
+		manager := c.describeManager(i, entry)
+		if manager == nil {
+			continue
+		}
+		vaultData := manager.Get()
+		id := vaultData.GetIdentityByFingerprint(fingerprint)
+		if id == nil {
+			continue
+		}
+
+		item := IdentityShowJSON{
+			Position:      i + 1,
+			Vault:         entry.Path,
+			UID:           id.UID,
+			Fingerprint:   id.Fingerprint,
+			Algorithm:     id.Algorithm,
+			AlgorithmBits: id.AlgorithmBits,
+			Curve:         id.Curve,
+			CreatedAt:     id.CreatedAt,
+			ExpiresAt:     id.ExpiresAt,
+			AddedAt:       id.AddedAt,
+			SignedBy:      id.SignedBy,
+			Hash:          id.Hash,
+		}
+		valid, err := verifyIdentitySignature(id, &vaultData)
+		switch {
+		case err != nil:
+			item.VerifyError = err.Error()
+		case !valid:
+			item.VerifyError = "signature does not match the signer's key"
+		default:
+			item.Verified = true
+		}
+		if signer := vaultData.GetIdentityByFingerprint(id.SignedBy); signer != nil {
+			signers[id.SignedBy] = signer.UID
+		}
+		shown = append(shown, item)
+	}
+
+	if len(shown) == 0 {
+		if fromIndex != 0 {
+			return NewError(fmt.Sprintf("identity %s not found in vault %d", fingerprint, fromIndex), ExitVaultError)
+		}
+		return NewError(fmt.Sprintf("identity %s not found in any vault", fingerprint), ExitVaultError)
+	}
+
+	if jsonOutput {
+		if err := c.output.EncodeJSON(shown); err != nil {
+			return NewError(fmt.Sprintf("failed to encode json: %v", err), ExitGeneralError)
+		}
+	} else {
+		c.writeIdentityShow(shown, signers)
+	}
+
+	for _, item := range shown {
+		if !item.Verified {
+			return NewError(fmt.Sprintf("identity %s failed verification in vault %d", fingerprint, item.Position), ExitValidationError)
+		}
+	}
+	return nil
+}
+
+// writeIdentityShow prints the key details of shown once, followed by what
+// each vault holding the identity records about it. signers maps signer
+// fingerprints to their UIDs.
+func (c *CLI) writeIdentityShow(shown []IdentityShowJSON, signers map[string]string) {
+	out := c.output.Stdout()
+	first := shown[0]
+
+	algorithm := first.Algorithm
+	if first.Curve != "" {
+		algorithm += " " + first.Curve
+	}
+	expires := "never"
+	if first.ExpiresAt != nil {
+		expires = first.ExpiresAt.Format(time.RFC3339)
+	}
+
+	_, _ = fmt.Fprintf(out, "Identity %s\n", first.Fingerprint)
+	_, _ = fmt.Fprintf(out, "  UID:        %s\n", first.UID)
+	_, _ = fmt.Fprintf(out, "  Algorithm:  %s (%d bits)\n", algorithm, first.AlgorithmBits)
+	_, _ = fmt.Fprintf(out, "  Created:    %s\n", first.CreatedAt.Format(time.RFC3339))
+	_, _ = fmt.Fprintf(out, "  Expires:    %s\n", expires)
+
+	for _, item := range shown {
+		signedBy := item.SignedBy
+		if uid := signers[item.SignedBy]; uid != "" {
+			signedBy += " (" + uid + ")"
+		}
+		signature := "valid"
+		if !item.Verified {
+			signature = "INVALID: " + item.VerifyError
+		}
+
+		_, _ = fmt.Fprintf(out, "Vault %d (%s):\n", item.Position, item.Vault)
+		_, _ = fmt.Fprintf(out, "  Added:      %s\n", item.AddedAt.Format(time.RFC3339))
+		_, _ = fmt.Fprintf(out, "  Signed by:  %s\n", signedBy)
+		_, _ = fmt.Fprintf(out, "  Hash:       %s\n", item.Hash)
+		_, _ = fmt.Fprintf(out, "  Signature:  %s\n", signature)
+	}
+}
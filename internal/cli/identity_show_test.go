@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vaulttest"
+)
+
+// newIdentityShowCLI returns a CLI over a signed vault holding one identity.
+func newIdentityShowCLI(t *testing.T) (*CLI, *bytes.Buffer, vault.Vault) {
+	t.Helper()
+	alice, err := vaulttest.NewIdentity("Alice", "alice@example.com")
+	if err != nil {
+		t.Fatalf("NewIdentity failed: %v", err)
+	}
+	v, err := vaulttest.BuildSignedVault([]*vaulttest.Identity{alice}, nil)
+	if err != nil {
+		t.Fatalf("BuildSignedVault failed: %v", err)
+	}
+	manager := newTestManager(t, v)
+
+	mock := NewMockVaultResolver()
+	mock.VaultEntries = []vault.VaultEntry{{Path: manager.Path()}}
+	mock.Managers = map[int]*vault.Manager{0: manager}
+
+	stdout := &bytes.Buffer{}
+	cli := &CLI{
+		vaultResolver: mock,
+		output:        output.NewHandler(stdout, &bytes.Buffer{}),
+	}
+	return cli, stdout, manager.Get()
+}
+
+func TestIdentityShow(t *testing.T) {
+	cli, stdout, v := newIdentityShowCLI(t)
+	id := v.Identities[0]
+
+	if err := cli.IdentityShow(strings.ToLower(id.Fingerprint), 0, false); err != nil {
+		t.Fatalf("IdentityShow failed: %v", err)
+	}
+	out := stdout.String()
+	for _, want := range []string{
+		"Identity " + id.Fingerprint,
+		"UID:        " + id.UID,
+		"Hash:       " + id.Hash,
+		"Signed by:  " + id.SignedBy + " (" + id.UID + ")",
+		"Signature:  valid",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+
+	stdout.Reset()
+	if err := cli.IdentityShow(id.Fingerprint, 0, true); err != nil {
+		t.Fatalf("IdentityShow --json failed: %v", err)
+	}
+	var shown []IdentityShowJSON
+	if err := json.Unmarshal(stdout.Bytes(), &shown); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout.String())
+	}
+	if len(shown) != 1 || shown[0].Position != 1 || !shown[0].Verified || shown[0].Fingerprint != id.Fingerprint {
+		t.Errorf("unexpected JSON output: %+v", shown)
+	}
+}
+
+func TestIdentityShow_NotFound(t *testing.T) {
+	cli, stdout, _ := newIdentityShowCLI(t)
+
+	err := cli.IdentityShow("0000000000000000000000000000000000000000", 0, false)
+	if err == nil || err.ExitCode != ExitVaultError {
+		t.Fatalf("expected a vault error, got %v", err)
+	}
+	if !strings.Contains(err.Message, "not found in any vault") {
+		t.Errorf("unexpected message: %s", err.Message)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("expected no output, got:\n%s", stdout.String())
+	}
+}
+
+func TestIdentityShow_CanonicalJSONIsStable(t *testing.T) {
+	cli, stdout, v := newIdentityShowCLI(t)
+	cli.SetCanonical(true)
+	fp := v.Identities[0].Fingerprint
+
+	if err := cli.IdentityShow(fp, 0, true); err != nil {
+		t.Fatalf("IdentityShow failed: %v", err)
+	}
+	first := stdout.String()
+	stdout.Reset()
+	if err := cli.IdentityShow(fp, 0, true); err != nil {
+		t.Fatalf("IdentityShow failed: %v", err)
+	}
+	if second := stdout.String(); first != second {
+		t.Errorf("canonical output differs between runs:\n%s\n---\n%s", first, second)
+	}
+	// Keys are sorted: "added_at" comes first, not "position"
+	if !strings.HasPrefix(first, "[\n  {\n    \"added_at\":") {
+		t.Errorf("expected sorted keys, got:\n%s", first)
+	}
+}
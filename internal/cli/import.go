@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// ImportSecrets stores each key/value pair of values as a secret in the target
+// vault, encrypted to the current identity, in key order. It backs the
+// 'import' commands, which fetch values from another secret store.
+//
+// Without atomic, every key is attempted: failures are reported on stderr and
+// the others are still stored. With atomic, all values are validated,
+// encrypted and signed first and the vault is saved once, so any failure
+// leaves the vault untouched.
+//
+// With resume, keys whose latest value in the target vault is already the
+// imported one are skipped, so an import that failed part way can be re-run.
+// Each key's outcome is reported on stderr, followed by a summary. No result
+// log is kept: the vault is the record of what was imported, and resume
+// reads it back rather than trusting a log the source may have outdated.
+func (c *CLI) ImportSecrets(values map[string]string, vaultPath string, fromIndex int, atomic, resume bool) *Error {
+	if len(values) == 0 {
+		return NewError("nothing to import: the source has no keys", ExitGeneralError)
+	}
+
+	fp, err := c.checkFingerprintRequired("import")
+	if err != nil {
+		return err
+	}
+
+	// Resolve the vault once, so an interactive selection is asked only once.
+	targetIndex, resolveErr := c.resolveWritableVaultIndex(vaultPath, fromIndex)
+	if resolveErr != nil {
+		return resolveErr
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var skipped int
+	if resume {
+		pending := keys[:0]
+		for _, key := range keys {
+			if c.alreadyImported(targetIndex, key, values[key], fp) {
+				skipped++
+				_, _ = fmt.Fprintf(c.output.Stderr(), "skipped: %s (already imported)\n", key)
+				continue
+			}
+			pending = append(pending, key)
+		}
+		keys = pending
+		if len(keys) == 0 {
+			_, _ = fmt.Fprintf(c.output.Stderr(), "summary: imported=0 skipped=%d failed=0\n", skipped)
+			return nil
+		}
+	}
+
+	if atomic {
+		return c.importAtomic(keys, values, targetIndex, skipped)
+	}
+
+	progress := c.newProgress("imported", "secrets", len(keys))
+	var failed int
+	for _, key := range keys {
+		target, err := c.prepareSecretPut(key, "", targetIndex+1, false)
+		if err == nil {
+			err = c.encryptAndStoreValue(target, values[key])
+		}
+		if err != nil {
+			failed++
+			_, _ = fmt.Fprintf(c.output.Stderr(), "failed: %s: %s\n", key, err.Message)
+		}
+		progress.Increment()
+	}
+
+	_, _ = fmt.Fprintf(c.output.Stderr(), "summary: imported=%d skipped=%d failed=%d\n", len(keys)-failed, skipped, failed)
+	if failed > 0 {
+		return NewError(fmt.Sprintf("%d of %d secrets failed to import", failed, len(keys)), ExitGeneralError)
+	}
+	return nil
+}
+
+// importAtomic stages every value before adding any of them, then saves the
+// vault once.
+func (c *CLI) importAtomic(keys []string, values map[string]string, targetIndex, skipped int) *Error {
+	progress := c.newProgress("encrypted", "secrets", len(keys))
+	aborted := func(key string, err *Error) *Error {
+		progress.Finish()
+		return NewError(fmt.Sprintf("import aborted, nothing was stored: %s: %s", key, err.Message), err.ExitCode)
+	}
+
+	staged := make([]vault.Secret, 0, len(keys))
+	for _, key := range keys {
+		target, err := c.prepareSecretPut(key, "", targetIndex+1, false)
+		if err != nil {
+			return aborted(key, err)
+		}
+		encryptedBase64, err := c.encryptForTarget(target, values[key])
+		if err != nil {
+			return aborted(key, err)
+		}
+		secret, err := c.signNewSecretValue(target, encryptedBase64)
+		if err != nil {
+			return aborted(key, err)
+		}
+		staged = append(staged, secret)
+		progress.Increment()
+	}
+
+	// Hold the additions in memory so SaveVault writes them in one go.
+	c.vaultResolver.DeferWrites()
+	for _, secret := range staged {
+		if err := c.vaultResolver.AddSecret(secret, targetIndex); err != nil {
+			return NewError(fmt.Sprintf("import aborted, nothing was stored: %s: failed to add secret: %v", secret.Key, err), ExitVaultError)
+		}
+	}
+	if err := c.vaultResolver.SaveVault(targetIndex); err != nil {
+		return NewError(fmt.Sprintf("import aborted, nothing was stored: failed to save vault: %v", err), ExitVaultError)
+	}
+	if hookErr := c.runPostWriteHook(targetIndex); hookErr != nil {
+		return hookErr
+	}
+
+	for _, secret := range staged {
+		_, _ = fmt.Fprintf(c.output.Stdout(), "Secret '%s' stored successfully\n", secret.Key)
+	}
+	_, _ = fmt.Fprintf(c.output.Stderr(), "summary: imported=%d skipped=%d failed=0\n", len(staged), skipped)
+	return nil
+}
+
+// alreadyImported reports whether the latest value of key in the vault at
+// index decrypts, for fp, to value. Anything that prevents the comparison
+// counts as not imported, leaving the key to be stored and fail as usual.
+func (c *CLI) alreadyImported(index int, key, value, fp string) bool {
+	secretKey, normErr := vault.NormalizeSecretKey(key)
+	if normErr != nil {
+		return false
+	}
+	secret := c.vaultResolver.GetSecretByKeyFromVault(index, secretKey)
+	if secret == nil || len(secret.Values) == 0 || secret.IsDeleted() {
+		return false
+	}
+	latest := secret.Values[len(secret.Values)-1]
+	if !latest.CanBeReadBy(fp) || detachedValueError(secretKey, &latest) != nil {
+		return false
+	}
+	encryptedArmored, decodeErr := base64.StdEncoding.DecodeString(latest.Value)
+	if decodeErr != nil {
+		return false
+	}
+	plaintext, decErr := c.gpgClient.DecryptWithAgent(encryptedArmored, fp)
+	if decErr != nil {
+		return false
+	}
+	plaintext, inflateErr := c.inflateValue(&latest, plaintext)
+	if inflateErr != nil {
+		return false
+	}
+	return string(plaintext) == value
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// newImportCLI returns a CLI whose single vault already holds the logged-in
+// identity, recording the keys of added secrets.
+func newImportCLI(t *testing.T) (*CLI, *MockVaultResolver, *[]string) {
+	t.Helper()
+	const fp = "MYFINGERPRINT"
+	cli, _ := newSecretStoreCLI(t, []string{"/vault1.yaml"}, []string{"/vault1.yaml"})
+	mock := cli.vaultResolver.(*MockVaultResolver)
+	id := vault.Identity{Fingerprint: fp, PublicKey: "base64pubkey", Algorithm: "RSA", AlgorithmBits: 4096}
+	mock.Identities[fp] = id
+	mock.IdentitiesByVault[0] = map[string]vault.Identity{fp: id}
+	var added []string
+	mock.AddSecretFunc = func(s vault.Secret, _ int) error {
+		added = append(added, s.Key)
+		return nil
+	}
+	return cli, mock, &added
+}
+
+func TestImportSecrets(t *testing.T) {
+	cli, mock, added := newImportCLI(t)
+
+	values := map[string]string{"DB_URL": "postgres://db", "API_KEY": "k", "bad-key": "x"}
+	err := cli.ImportSecrets(values, "", 1, false, false)
+	if err == nil {
+		t.Fatal("expected an error for the invalid key")
+	}
+	if strings.Join(*added, ",") != "API_KEY,DB_URL" {
+		t.Errorf("expected the valid keys to be stored in order, got %v", *added)
+	}
+	if len(mock.SavedVaults) != 2 {
+		t.Errorf("expected one save per stored key, got %v", mock.SavedVaults)
+	}
+}
+
+func TestImportSecrets_AtomicStoresNothingOnFailure(t *testing.T) {
+	cli, mock, added := newImportCLI(t)
+
+	values := map[string]string{"DB_URL": "postgres://db", "API_KEY": "k", "bad-key": "x"}
+	err := cli.ImportSecrets(values, "", 1, true, false)
+	if err == nil {
+		t.Fatal("expected an error for the invalid key")
+	}
+	if !strings.Contains(err.Message, "nothing was stored: bad-key") {
+		t.Errorf("unexpected message: %s", err.Message)
+	}
+	if len(*added) != 0 || len(mock.SavedVaults) != 0 {
+		t.Errorf("expected no writes, got added=%v saved=%v", *added, mock.SavedVaults)
+	}
+}
+
+func TestImportSecrets_AtomicSavesOnce(t *testing.T) {
+	cli, mock, added := newImportCLI(t)
+
+	values := map[string]string{"DB_URL": "postgres://db", "API_KEY": "k"}
+	if err := cli.ImportSecrets(values, "", 1, true, false); err != nil {
+		t.Fatalf("ImportSecrets failed: %v", err)
+	}
+	if strings.Join(*added, ",") != "API_KEY,DB_URL" {
+		t.Errorf("expected both keys stored in order, got %v", *added)
+	}
+	if len(mock.SavedVaults) != 1 {
+		t.Errorf("expected a single save, got %v", mock.SavedVaults)
+	}
+}
+
+func TestImportSecrets_ResumeSkipsImportedKeys(t *testing.T) {
+	cli, mock := newReplaceCLI(t, 0)
+	cli.gpgClient = &prefixDecryptGPGClient{MockGPGClient: NewMockGPGClient(), prefix: "encrypted_to_base64pubkey_"}
+	stderr := &bytes.Buffer{}
+	cli.output = output.NewHandler(&bytes.Buffer{}, stderr)
+
+	// The first run fails part way: DB_URL is not stored
+	store := mock.AddSecretFunc
+	mock.AddSecretFunc = func(s vault.Secret, index int) error {
+		if s.Key == "DB_URL" {
+			return errors.New("disk full")
+		}
+		return store(s, index)
+	}
+	values := map[string]string{"API_KEY": "k", "DB_URL": "postgres://db", "TOKEN": "t"}
+	if err := cli.ImportSecrets(values, "", 1, false, false); err == nil {
+		t.Fatal("expected the first import to fail")
+	}
+
+	// The resumed run stores only the missing key, and a changed one
+	mock.AddSecretFunc = store
+	mock.SavedVaults = nil
+	values["TOKEN"] = "t2"
+	stderr.Reset()
+	if err := cli.ImportSecrets(values, "", 1, false, true); err != nil {
+		t.Fatalf("resumed import failed: %v\n%s", err, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "skipped: API_KEY (already imported)") {
+		t.Errorf("expected API_KEY to be skipped, got:\n%s", stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "summary: imported=2 skipped=1 failed=0") {
+		t.Errorf("unexpected summary:\n%s", stderr.String())
+	}
+	if len(mock.SavedVaults) != 2 {
+		t.Errorf("expected DB_URL and TOKEN to be saved, got %v", mock.SavedVaults)
+	}
+	if got := mock.Secrets[0]["API_KEY"].Values; len(got) != 1 {
+		t.Errorf("expected API_KEY to keep one value, got %d", len(got))
+	}
+
+	// Once everything is in place, resuming writes nothing
+	mock.SavedVaults = nil
+	stderr.Reset()
+	if err := cli.ImportSecrets(values, "", 1, true, true); err != nil {
+		t.Fatalf("resumed import failed: %v", err)
+	}
+	if len(mock.SavedVaults) != 0 || !strings.Contains(stderr.String(), "summary: imported=0 skipped=3 failed=0") {
+		t.Errorf("expected nothing to be imported, saved=%v:\n%s", mock.SavedVaults, stderr.String())
+	}
+}
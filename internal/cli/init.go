@@ -13,11 +13,19 @@ import (
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
 )
 
-// InitConfig initializes a configuration file with FIPS-compliant defaults.
+// InitConfig initializes a configuration file whose approved_algorithms are
+// the named preset (see config.PresetApprovedAlgorithms); an empty preset is
+// the FIPS-compliant default set.
 // gpgProgram: if non-empty, set gpg.program to this value (without validation).
 // Otherwise gpg.program defaults to "PATH" (resolved at runtime).
 // loginFingerprint: if non-empty, creates a signed login proof for this fingerprint.
-func InitConfig(configPath string, initialVaults []string, gpgProgram string, loginFingerprint string, out *output.Handler) *Error {
+// Unless skipVerify is set, control of its secret key is proven first, as by Login.
+func InitConfig(configPath string, initialVaults []string, gpgProgram string, loginFingerprint string, skipVerify bool, preset string, out *output.Handler) *Error {
+	algorithms, presetErr := config.PresetApprovedAlgorithms(preset)
+	if presetErr != nil {
+		return NewError(presetErr.Error(), ExitValidationError)
+	}
+
 	xdgPaths, err := xdg.NewPaths()
 	if err != nil {
 		return NewError(fmt.Sprintf("failed to get XDG paths: %v", err), ExitConfigError)
@@ -36,6 +44,7 @@ func InitConfig(configPath string, initialVaults []string, gpgProgram string, lo
 	// Use FIPS-compliant default configuration. DefaultConfig() seeds gpg.program
 	// to "PATH", which resolves the gpg binary from the system PATH at runtime.
 	cfg := config.DefaultConfig()
+	cfg.ApprovedAlgorithms = algorithms
 
 	// Override gpg.program only if --gpg-program was explicitly provided.
 	if gpgProgram != "" {
@@ -66,6 +75,13 @@ func InitConfig(configPath string, initialVaults []string, gpgProgram string, lo
 			return capErr
 		}
 
+		if !skipVerify {
+			_, _ = fmt.Fprintf(out.Stderr(), "Verifying control of secret key...\n")
+			if verifyErr := verifyKeyControl(gpgClient, publicKeyInfo, loginFingerprint); verifyErr != nil {
+				return NewError(fmt.Sprintf("could not prove control of the secret key for %s: %v\nConfig was not written. Use --skip-verify to bypass this check", loginFingerprint, verifyErr), ExitGPGError)
+			}
+		}
+
 		_, _ = fmt.Fprintf(out.Stderr(), "Creating signed login for: %s (%s)\n", publicKeyInfo.UID, loginFingerprint)
 
 		// Create signed login proof
@@ -228,8 +244,9 @@ func ValidateVaultPathsAgainstConfig(configPath string, vaultPaths []string, out
 	return nil
 }
 
-// InitVaultFile initializes a specific vault file
-func InitVaultFile(vaultPath string, out *output.Handler) *Error {
+// InitVaultFile initializes a specific vault file. A non-empty name is stored
+// in the vault header as a human-readable label.
+func InitVaultFile(vaultPath, name string, out *output.Handler) *Error {
 	// Check if file exists
 	if _, err := os.Stat(vaultPath); err == nil {
 		return NewError(fmt.Sprintf("vault file already exists: %s", vaultPath), ExitVaultError)
@@ -251,6 +268,12 @@ func InitVaultFile(vaultPath string, out *output.Handler) *Error {
 		_ = vm.Unlock()
 		return NewError(fmt.Sprintf("failed to initialize vault structure: %v", err), ExitVaultError)
 	}
+	if name != "" {
+		if err := vm.SetName(name); err != nil {
+			_ = vm.Unlock()
+			return NewError(fmt.Sprintf("failed to set vault name: %v", err), ExitVaultError)
+		}
+	}
 	_ = vm.Unlock()
 
 	_, _ = fmt.Fprintf(out.Stderr(), "Initialized empty vault: %s\n", vaultPath)
@@ -259,7 +282,7 @@ func InitVaultFile(vaultPath string, out *output.Handler) *Error {
 
 // InitVaultInteractiveStandalone allows user to select a vault from config to initialize
 // This runs without requiring the vaults to be openable (since they might not exist yet)
-func InitVaultInteractiveStandalone(configPath string, out *output.Handler) *Error {
+func InitVaultInteractiveStandalone(configPath, name string, out *output.Handler) *Error {
 	cfg, err := config.Load(configPath)
 	if err != nil {
 		// Provide helpful suggestion based on execution context
@@ -274,7 +297,7 @@ func InitVaultInteractiveStandalone(configPath string, out *output.Handler) *Err
 		return NewError(suggestion, ExitConfigError)
 	}
 
-	vaultCfg, err := vault.ParseVaultConfig(cfg.Vault)
+	vaultCfg, err := vault.ParseNamedVaultConfig(cfg.Vault, cfg.VaultNameList(), cfg.SearchOrder)
 	if err != nil {
 		return NewError(fmt.Sprintf("failed to parse vault config: %v", err), ExitConfigError)
 	}
@@ -303,5 +326,5 @@ func InitVaultInteractiveStandalone(configPath string, out *output.Handler) *Err
 		selectedPath = paths[idx]
 	}
 
-	return InitVaultFile(selectedPath, out)
+	return InitVaultFile(selectedPath, name, out)
 }
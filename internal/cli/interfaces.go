@@ -11,18 +11,22 @@ type VaultResolver interface {
 	GetIdentityByFingerprint(fingerprint string) *vault.Identity
 	AddSecret(secret vault.Secret, index int) error
 	SaveAll() error
+	DeferWrites()
 	GetSecretFromAnyVault(key string, stderr io.Writer) (*vault.SecretValue, error)
 	GetAccessibleSecretFromAnyVault(key, fingerprint string) (*vault.SecretValue, error)
 	GetSecretByKeyFromVault(index int, key string) *vault.Secret
 	FindSecretVaultIndex(key string) int
 	GetVaultManager(index int) *vault.Manager
 	AddIdentity(identity vault.Identity, index int) error
+	ReplaceIdentity(identity vault.Identity, index int) error
 	GetConfig() vault.VaultConfig
+	SetSearchDepth(depth int)
 	GetVaultPaths() []string
 	GetAvailableVaultPathsWithIndices() []vault.VaultPathWithIndex
 	IsPathInConfig(path string) bool
 	IdentityExistsInVault(fingerprint string, index int) bool
 	SaveVault(index int) error
+	TrimSecretHistory(index int, key string, keep int) (int, error)
 	CloseAll() error
 	GetLoadError(index int) error
 	GetSecret(index int, key string) (*vault.SecretValue, error)
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// LockWait selects how long to wait for a vault locked by another process.
+type LockWait int
+
+const (
+	// LockWaitConfigured waits for the lock_timeout set in config.
+	LockWaitConfigured LockWait = iota
+	// LockWaitBlock waits until the lock is released (--wait).
+	LockWaitBlock
+	// LockWaitFail fails immediately if the lock is held (--no-wait).
+	LockWaitFail
+)
+
+// NewCLIWithLockWait creates a new CLI instance like NewCLI, overriding the
+// configured lock timeout with wait.
+func NewCLIWithLockWait(vaultPaths []string, configPath string, silent bool, stdin io.Reader, stdout, stderr io.Writer, wait LockWait) (*CLI, error) {
+	return newCLI(vaultPaths, configPath, silent, stdin, stdout, stderr, nil, wait)
+}
+
+// resolveLockTimeout returns the vault lock timeout for wait, reading
+// lock_timeout from cfg for LockWaitConfigured.
+func resolveLockTimeout(cfg config.Config, wait LockWait) (time.Duration, *Error) {
+	switch wait {
+	case LockWaitBlock:
+		return vault.LockWaitForever, nil
+	case LockWaitFail:
+		return vault.LockNoWait, nil
+	}
+
+	timeout, err := cfg.GetLockTimeout()
+	if err != nil {
+		return 0, NewError(err.Error(), ExitConfigError)
+	}
+	if timeout == 0 {
+		return vault.LockNoWait, nil
+	}
+	return timeout, nil
+}
+
+// vaultOpenError converts an error from opening vaults into a CLI error.
+// A vault held by another process gets its own exit code and a hint.
+func vaultOpenError(prefix string, err error) *Error {
+	var lockErr *vault.LockTimeoutError
+	if errors.As(err, &lockErr) {
+		return NewError(fmt.Sprintf("%v; retry or use --wait", lockErr), ExitVaultLocked)
+	}
+	return NewError(fmt.Sprintf("%s: %v", prefix, err), ExitVaultError)
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+func TestResolveLockTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Config
+		wait LockWait
+		want time.Duration
+	}{
+		{"default", config.Config{}, LockWaitConfigured, config.DefaultLockTimeout},
+		{"configured", config.Config{LockTimeout: "2s"}, LockWaitConfigured, 2 * time.Second},
+		{"configured zero fails immediately", config.Config{LockTimeout: "0s"}, LockWaitConfigured, vault.LockNoWait},
+		{"--wait", config.Config{LockTimeout: "2s"}, LockWaitBlock, vault.LockWaitForever},
+		{"--no-wait", config.Config{LockTimeout: "2s"}, LockWaitFail, vault.LockNoWait},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveLockTimeout(tt.cfg, tt.wait)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveLockTimeout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := resolveLockTimeout(config.Config{LockTimeout: "soon"}, LockWaitConfigured); err == nil || err.ExitCode != ExitConfigError {
+		t.Errorf("expected config error for invalid lock_timeout, got %v", err)
+	}
+}
+
+func TestVaultOpenError(t *testing.T) {
+	lockErr := vaultOpenError("failed to open vaults from config", &vault.LockTimeoutError{Path: "/tmp/vault", Waited: 2 * time.Second})
+	if lockErr.ExitCode != ExitVaultLocked {
+		t.Errorf("expected ExitVaultLocked, got %d", lockErr.ExitCode)
+	}
+	if !strings.Contains(lockErr.Message, "another dotsecenv process is writing to /tmp/vault") || !strings.HasSuffix(lockErr.Message, "retry or use --wait") {
+		t.Errorf("unexpected message: %s", lockErr.Message)
+	}
+
+	otherErr := vaultOpenError("failed to open vaults from config", errors.New("boom"))
+	if otherErr.ExitCode != ExitVaultError || otherErr.Message != "failed to open vaults from config: boom" {
+		t.Errorf("unexpected error: %d %s", otherErr.ExitCode, otherErr.Message)
+	}
+}
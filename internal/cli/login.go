@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/gpg"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
 )
 
 // createSignedLogin creates a Login struct with cryptographic proof.
@@ -41,6 +43,32 @@ func CreateSignedLogin(gpgClient gpg.Client, fingerprint string) (*config.Login,
 	return createSignedLogin(gpgClient, fingerprint)
 }
 
+// verifyKeyControl proves the caller controls the secret key for fingerprint.
+// It signs a random challenge through gpg-agent and checks the signature
+// against the public key from the keyring, so a mistyped fingerprint or a
+// public-only key is rejected before anything is written to config.
+func verifyKeyControl(gpgClient gpg.Client, info *gpg.KeyInfo, fingerprint string) error {
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate challenge: %w", err)
+	}
+	challenge := []byte("dotsecenv-login-challenge:" + hex.EncodeToString(nonce))
+
+	signature, err := gpgClient.SignDataWithAgent(fingerprint, challenge)
+	if err != nil {
+		return fmt.Errorf("failed to sign challenge: %w", err)
+	}
+
+	valid, err := identity.VerifySignatureWithPublicKey(info.PublicKeyBase64, challenge, signature)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return fmt.Errorf("signature verification failed")
+	}
+	return nil
+}
+
 // selectSecretKey lists encryption-capable secret keys and prompts the user
 // to select one. Sign-only keys are filtered out: login eventually needs a
 // key that can also encrypt secrets, so catching that mismatch here yields a
@@ -141,7 +169,9 @@ func requireEncryptionCapableKey(info *gpg.KeyInfo, fingerprint string) *Error {
 
 // Login initializes the user's identity with a signed login proof.
 // If fingerprint is empty, it will interactively prompt the user to select from available secret keys.
-func (c *CLI) Login(fingerprint string) *Error {
+// Unless skipVerify is set, a signed challenge must verify against the public
+// key before the config is written.
+func (c *CLI) Login(fingerprint string, skipVerify bool) *Error {
 	// If no fingerprint provided, show interactive selection
 	if fingerprint == "" {
 		selectedFP, selectErr := c.selectSecretKey()
@@ -169,6 +199,13 @@ func (c *CLI) Login(fingerprint string) *Error {
 		_, _ = fmt.Fprintf(c.output.Stdout(), "  Fingerprint: %s\n", fingerprint)
 	}
 
+	if !skipVerify {
+		_, _ = fmt.Fprintf(c.output.Stdout(), "Verifying control of secret key...\n")
+		if verifyErr := verifyKeyControl(c.gpgClient, publicKeyInfo, fingerprint); verifyErr != nil {
+			return NewError(fmt.Sprintf("could not prove control of the secret key for %s: %v\nConfig was not changed. Use --skip-verify to bypass this check", fingerprint, verifyErr), ExitGPGError)
+		}
+	}
+
 	// Create signed login proof
 	_, _ = fmt.Fprintf(c.output.Stdout(), "Creating signed login proof...\n")
 	login, err := createSignedLogin(c.gpgClient, fingerprint)
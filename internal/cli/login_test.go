@@ -1,10 +1,19 @@
 package cli
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/ProtonMail/gopenpgp/v3/profile"
+
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/gpg"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
 )
 
 func TestFilterEncryptionCapableKeys(t *testing.T) {
@@ -155,3 +164,103 @@ func TestShortFingerprint(t *testing.T) {
 		})
 	}
 }
+
+// keySigningGPGClient signs with an in-memory key instead of gpg-agent so
+// verifyKeyControl can be exercised end to end without a keyring.
+type keySigningGPGClient struct {
+	*MockGPGClient
+	key    *crypto.Key
+	tamper bool
+}
+
+func (m *keySigningGPGClient) SignDataWithAgent(fingerprint string, data []byte) (string, error) {
+	signer, err := crypto.PGPWithProfile(profile.RFC9580()).Sign().SigningKey(m.key).Detached().New()
+	if err != nil {
+		return "", err
+	}
+	raw, err := signer.Sign(data, crypto.Bytes)
+	if err != nil {
+		return "", err
+	}
+	sig := hex.EncodeToString(raw)
+	if m.tamper {
+		// Flip the last hex digit so the signature no longer verifies.
+		last := sig[len(sig)-1]
+		if last == '0' {
+			last = '1'
+		} else {
+			last = '0'
+		}
+		sig = sig[:len(sig)-1] + string(last)
+	}
+	return sig, nil
+}
+
+func TestVerifyKeyControl(t *testing.T) {
+	key, err := crypto.PGPWithProfile(profile.RFC9580()).KeyGeneration().
+		AddUserId("Test User", "test@example.com").New().GenerateKey()
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	pub, err := key.GetPublicKey()
+	if err != nil {
+		t.Fatalf("export public key: %v", err)
+	}
+	fp := key.GetFingerprint()
+	info := &gpg.KeyInfo{Fingerprint: fp, CanEncrypt: true, PublicKeyBase64: base64.StdEncoding.EncodeToString(pub)}
+
+	t.Run("valid signature", func(t *testing.T) {
+		client := &keySigningGPGClient{MockGPGClient: NewMockGPGClient(), key: key}
+		if err := verifyKeyControl(client, info, fp); err != nil {
+			t.Fatalf("verifyKeyControl() = %v, want nil", err)
+		}
+	})
+
+	t.Run("tampered signature", func(t *testing.T) {
+		client := &keySigningGPGClient{MockGPGClient: NewMockGPGClient(), key: key, tamper: true}
+		if err := verifyKeyControl(client, info, fp); err == nil {
+			t.Fatal("verifyKeyControl() = nil, want error for tampered signature")
+		}
+	})
+}
+
+func TestLogin_VerifyFailureDoesNotWriteConfig(t *testing.T) {
+	key, err := crypto.PGPWithProfile(profile.RFC9580()).KeyGeneration().
+		AddUserId("Test User", "test@example.com").New().GenerateKey()
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	pub, err := key.GetPublicKey()
+	if err != nil {
+		t.Fatalf("export public key: %v", err)
+	}
+	fp := key.GetFingerprint()
+
+	client := &keySigningGPGClient{MockGPGClient: NewMockGPGClient(), key: key, tamper: true}
+	client.PublicKeyInfo[fp] = gpg.KeyInfo{Fingerprint: fp, UID: "Test User", CanEncrypt: true, PublicKeyBase64: base64.StdEncoding.EncodeToString(pub)}
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	c := &CLI{
+		configPath: configPath,
+		gpgClient:  client,
+		output:     output.NewHandler(&bytes.Buffer{}, &bytes.Buffer{}),
+	}
+
+	loginErr := c.Login(fp, false)
+	if loginErr == nil {
+		t.Fatal("Login() = nil, want verification error")
+	}
+	if loginErr.ExitCode != ExitGPGError {
+		t.Errorf("exit code = %v, want ExitGPGError", loginErr.ExitCode)
+	}
+	if _, statErr := os.Stat(configPath); !os.IsNotExist(statErr) {
+		t.Error("config must not be written when verification fails")
+	}
+
+	if loginErr := c.Login(fp, true); loginErr != nil {
+		t.Fatalf("Login() with skipVerify = %v, want nil", loginErr)
+	}
+	if _, statErr := os.Stat(configPath); statErr != nil {
+		t.Errorf("config should be written with skipVerify: %v", statErr)
+	}
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// PreCommit validates each vault file in paths as 'validate FILE' does, for
+// use as a git pre-commit hook. It prints one line per file, followed by the
+// errors of files that fail, and fails with ExitVaultError if any file has
+// structural errors or does not verify. Warnings do not fail the hook.
+func (c *CLI) PreCommit(paths []string) *Error {
+	if len(paths) == 0 {
+		return NewError("no vault files given; pass the staged vault files as arguments", ExitGeneralError)
+	}
+
+	out := c.output.Stdout()
+	failed := 0
+	for _, path := range paths {
+		problems := c.preCommitCheck(path)
+		if len(problems) == 0 {
+			_, _ = fmt.Fprintf(out, "ok: %s\n", path)
+			continue
+		}
+		failed++
+		_, _ = fmt.Fprintf(out, "FAILED: %s\n", path)
+		for _, p := range problems {
+			if p.Path != "" {
+				_, _ = fmt.Fprintf(out, "  - %s: %s at %s\n", p.Level, p.Message, p.Path)
+			} else {
+				_, _ = fmt.Fprintf(out, "  - %s: %s\n", p.Level, p.Message)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return NewError(fmt.Sprintf("%d of %d vault file(s) failed validation; commit blocked", failed, len(paths)), ExitVaultError)
+	}
+	return nil
+}
+
+// preCommitCheck returns the errors 'validate FILE' reports for the vault
+// file at path. The file is opened without upgrading it.
+func (c *CLI) preCommitCheck(path string) []ValidationError {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+	fileInfo, err := os.Stat(absPath)
+	if err != nil {
+		return []ValidationError{{Level: "GLOBAL", Message: fmt.Sprintf("cannot read vault file: %v", err)}}
+	}
+	if !fileInfo.Mode().IsRegular() {
+		return []ValidationError{{Level: "GLOBAL", Message: "not a regular file"}}
+	}
+	if fileInfo.Size() == 0 {
+		return []ValidationError{{Level: "GLOBAL", Message: "vault file is empty"}}
+	}
+
+	manager := vault.NewManager(absPath, true)
+	if err := manager.OpenAndLock(); err != nil {
+		return []ValidationError{{Level: "GLOBAL", Message: fmt.Sprintf("failed to load: %v", err)}}
+	}
+	defer func() { _ = manager.Unlock() }()
+
+	report := newValidateJSON()
+	hasErrors, _, checkErr := c.validateVault(io.Discard, absPath, manager, report, false)
+
+	var problems []ValidationError
+	for _, e := range report.Errors {
+		problems = append(problems, ValidationError{Level: e.Level, Message: e.Message, Path: e.Path})
+	}
+	if len(problems) == 0 {
+		switch {
+		case checkErr != nil:
+			problems = append(problems, ValidationError{Level: "GLOBAL", Message: checkErr.Message})
+		case hasErrors:
+			problems = append(problems, ValidationError{Level: "SECRET", Message: "missing signatures; run 'dotsecenv validate " + path + "' for details"})
+		}
+	}
+	return problems
+}
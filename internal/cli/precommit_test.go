@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vaulttest"
+)
+
+func TestPreCommit_BlocksTamperedVault(t *testing.T) {
+	alice, err := vaulttest.NewIdentity("Alice", "alice@example.com")
+	if err != nil {
+		t.Fatalf("NewIdentity failed: %v", err)
+	}
+	v, err := vaulttest.BuildSignedVault([]*vaulttest.Identity{alice}, nil)
+	if err != nil {
+		t.Fatalf("BuildSignedVault failed: %v", err)
+	}
+	dir := t.TempDir()
+	cleanPath := filepath.Join(dir, "clean.vault")
+	w, err := vault.NewWriter(cleanPath)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	if err := w.RewriteFromVault(v); err != nil {
+		t.Fatalf("RewriteFromVault failed: %v", err)
+	}
+
+	// The same vault with the identity's UID changed after signing
+	data, err := os.ReadFile(cleanPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tamperedPath := filepath.Join(dir, "tampered.vault")
+	tampered := strings.Replace(string(data), "alice@example.com", "mallory@example.com", 1)
+	if err := os.WriteFile(tamperedPath, []byte(tampered), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := &bytes.Buffer{}
+	cli := &CLI{
+		config: config.Config{
+			ApprovedAlgorithms: []config.ApprovedAlgorithm{
+				{Algo: "EdDSA", Curves: []string{"Ed25519"}, MinBits: 255},
+			},
+		},
+		gpgClient: NewMockGPGClient(),
+		output:    output.NewHandler(stdout, &bytes.Buffer{}),
+	}
+
+	if err := cli.PreCommit([]string{cleanPath}); err != nil {
+		t.Fatalf("clean vault blocked: %v\n%s", err, stdout.String())
+	}
+	if strings.TrimSpace(stdout.String()) != "ok: "+cleanPath {
+		t.Errorf("unexpected output for a clean vault:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	exitErr := cli.PreCommit([]string{cleanPath, tamperedPath})
+	if exitErr == nil || exitErr.ExitCode != ExitVaultError {
+		t.Fatalf("expected the tampered vault to block the commit, got %v\n%s", exitErr, stdout.String())
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "ok: "+cleanPath) || !strings.Contains(out, "FAILED: "+tamperedPath) {
+		t.Errorf("expected one line per file:\n%s", out)
+	}
+	if !strings.Contains(out, "IDENTITY:") {
+		t.Errorf("expected the identity error listed:\n%s", out)
+	}
+	if after, _ := os.ReadFile(tamperedPath); string(after) != tampered {
+		t.Error("pre-commit modified the vault file")
+	}
+}
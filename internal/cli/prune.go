@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// IdentityPruneExpired revokes in a vault the identities whose keys have
+// expired, can read no current secret value and signed nothing still in the
+// vault. It prints every expired identity with what happens to it.
+//
+// With dryRun nothing is written. Otherwise, after confirmation, a
+// revocation signed by the current user is appended for each pruned
+// identity; the identity's records stay in the file for history. Without
+// yes, confirmation is only asked interactively, and nothing is written
+// when it cannot be.
+func (c *CLI) IdentityPruneExpired(dryRun, yes bool, vaultPath string, fromIndex int) *Error {
+	signerFP, fpErr := c.checkFingerprintRequired("identity prune-expired")
+	if fpErr != nil {
+		return fpErr
+	}
+
+	targetIndex, resolveErr := c.resolveWritableVaultIndex(vaultPath, fromIndex, "Select vault to prune:")
+	if resolveErr != nil {
+		return resolveErr
+	}
+
+	entry := c.vaultResolver.GetConfig().Entries[targetIndex]
+	expandedPath := vault.ExpandPath(entry.Path)
+
+	if writeErr := checkVaultWritable(entry.Path); writeErr != nil {
+		return writeErr
+	}
+
+	writer, err := vault.NewWriter(expandedPath)
+	if err != nil {
+		return NewError(fmt.Sprintf("failed to open vault: %v", err), ExitVaultError)
+	}
+	v, err := writer.ReadVault()
+	if err != nil {
+		return NewError(fmt.Sprintf("failed to read vault: %v", err), ExitVaultError)
+	}
+
+	_, stats := vault.PlanIdentityPrune(v, time.Now())
+	for i := range stats {
+		// The revocations are signed by the current user, who must stay
+		if stats[i].Removed && stats[i].Fingerprint == signerFP {
+			stats[i].Removed, stats[i].Reason = false, "signs the revocations"
+		}
+	}
+
+	out := c.output.Stdout()
+	_, _ = fmt.Fprintf(out, "Vault: %s\n", entry.Path)
+	removed := 0
+	for _, s := range stats {
+		if s.Removed {
+			removed++
+			_, _ = fmt.Fprintf(out, "  prune: %s (%s), expired %s\n", s.UID, s.Fingerprint, s.ExpiresAt.Format(time.DateOnly))
+		} else {
+			_, _ = fmt.Fprintf(out, "  keep:  %s (%s), expired %s, but %s\n", s.UID, s.Fingerprint, s.ExpiresAt.Format(time.DateOnly), s.Reason)
+		}
+	}
+	if removed == 0 {
+		_, _ = fmt.Fprintf(out, "No expired identities to prune.\n")
+		return nil
+	}
+	if dryRun {
+		_, _ = fmt.Fprintf(out, "Dry run: %d identity(ies) would be pruned.\n", removed)
+		return nil
+	}
+
+	signer := v.GetIdentityByFingerprint(signerFP)
+	if signer == nil {
+		return NewError(fmt.Sprintf("identity %s is not in vault %s; only its identities can prune it", signerFP, entry.Path), ExitAccessDenied)
+	}
+
+	if !yes {
+		hasTTY := c.hasTTY
+		if hasTTY == nil {
+			hasTTY = defaultHasTTY
+		}
+		if !hasTTY() || isCI() {
+			_, _ = fmt.Fprintf(out, "Run with --yes to prune %d identity(ies).\n", removed)
+			return nil
+		}
+		confirmed, confirmErr := PromptConfirm(
+			fmt.Sprintf("Prune %d identity(ies) from %s?", removed, expandedPath),
+			c.output.Stderr())
+		if confirmErr != nil {
+			return confirmErr
+		}
+		if !confirmed {
+			_, _ = fmt.Fprintf(out, "Aborted; vault unchanged.\n")
+			return nil
+		}
+	}
+
+	for _, s := range stats {
+		if !s.Removed {
+			continue
+		}
+		revocation := vault.Revocation{
+			AddedAt:     time.Now().UTC(),
+			Fingerprint: s.Fingerprint,
+			SignedBy:    signerFP,
+		}
+		revocation.Hash = vault.ComputeRevocationHash(&revocation, signer.AlgorithmBits)
+		signature, sigErr := c.gpgClient.SignDataWithAgent(signerFP, []byte(revocation.Hash))
+		if sigErr != nil {
+			return NewError(fmt.Sprintf("failed to sign revocation: %v", sigErr), ExitGPGError)
+		}
+		revocation.Signature = signature
+		if revokeErr := writer.RevokeIdentity(revocation); revokeErr != nil {
+			return NewError(fmt.Sprintf("failed to revoke identity %s: %v", s.Fingerprint, revokeErr), ExitVaultError)
+		}
+	}
+	if hookErr := c.runPostWriteHook(targetIndex); hookErr != nil {
+		return hookErr
+	}
+	_, _ = fmt.Fprintf(out, "Pruned %d identity(ies) from %s.\nRun `dotsecenv validate` to verify.\n", removed, expandedPath)
+	return nil
+}
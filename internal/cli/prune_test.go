@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vaulttest"
+)
+
+// vaulttestSigner signs with a vaulttest identity, so the signatures it
+// makes verify against the vault.
+type vaulttestSigner struct {
+	*MockGPGClient
+	signer *vaulttest.Identity
+}
+
+func (s *vaulttestSigner) SignDataWithAgent(fingerprint string, data []byte) (string, error) {
+	return s.signer.Sign(data)
+}
+
+// newPruneCLI writes a signed vault holding Alice and Bob, whose key has
+// expired and who can read nothing, and returns a CLI logged in as Alice
+// over it, with the vault's path.
+func newPruneCLI(t *testing.T) (*CLI, *bytes.Buffer, string) {
+	t.Helper()
+	alice, err := vaulttest.NewIdentity("Alice", "alice@example.com")
+	if err != nil {
+		t.Fatalf("NewIdentity failed: %v", err)
+	}
+	bob, err := vaulttest.NewIdentity("Bob", "bob@example.com")
+	if err != nil {
+		t.Fatalf("NewIdentity failed: %v", err)
+	}
+	expired := time.Now().UTC().Add(-24 * time.Hour).Truncate(time.Second)
+	bob.ExpiresAt = &expired
+	bob.SignedBy = alice.Fingerprint
+
+	v, err := vaulttest.BuildSignedVault([]*vaulttest.Identity{alice, bob}, []vault.Secret{
+		{Key: "DB_PASSWORD", Values: []vault.SecretValue{
+			{AvailableTo: []string{alice.Fingerprint}, Value: "Y2lwaGVy"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("BuildSignedVault failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "vault")
+	w, err := vault.NewWriter(path)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	if err := w.RewriteFromVault(v); err != nil {
+		t.Fatalf("failed to write vault: %v", err)
+	}
+
+	mock := NewMockVaultResolver()
+	mock.VaultEntries = []vault.VaultEntry{{Path: path}}
+	stdout := &bytes.Buffer{}
+	cli := &CLI{
+		vaultResolver: mock,
+		gpgClient:     &vaulttestSigner{MockGPGClient: NewMockGPGClient(), signer: alice},
+		fingerprint:   alice.Fingerprint,
+		output:        output.NewHandler(stdout, &bytes.Buffer{}),
+		config: config.Config{ApprovedAlgorithms: []config.ApprovedAlgorithm{
+			{Algo: "EdDSA", Curves: []string{"Ed25519"}, MinBits: 255},
+		}},
+	}
+	return cli, stdout, path
+}
+
+func TestIdentityPruneExpired_AppendsRevocation(t *testing.T) {
+	cli, stdout, path := newPruneCLI(t)
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cli.IdentityPruneExpired(false, true, "", 1); err != nil {
+		t.Fatalf("IdentityPruneExpired failed: %v\n%s", err, stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "prune: Bob <bob@example.com>") {
+		t.Errorf("expected Bob to be pruned, got:\n%s", stdout.String())
+	}
+
+	// Every data line is kept and one revocation is appended
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	beforeLines := strings.Split(strings.TrimSpace(string(before)), "\n")
+	afterLines := strings.Split(strings.TrimSpace(string(after)), "\n")
+	if len(afterLines) != len(beforeLines)+1 {
+		t.Fatalf("expected one appended line, got %d lines from %d", len(afterLines), len(beforeLines))
+	}
+	for i := 2; i < len(beforeLines); i++ {
+		if afterLines[i] != beforeLines[i] {
+			t.Errorf("line %d changed:\n%s\n%s", i+1, beforeLines[i], afterLines[i])
+		}
+	}
+	if !strings.Contains(afterLines[len(afterLines)-1], `"type":"revocation"`) {
+		t.Errorf("expected a revocation entry, got %s", afterLines[len(afterLines)-1])
+	}
+
+	if err := cli.ValidateFile(path, false); err != nil {
+		t.Errorf("expected the pruned vault to validate, got %v\n%s", err, stdout.String())
+	}
+
+	manager := vault.NewManager(path, false)
+	if err := manager.OpenAndLock(); err != nil {
+		t.Fatalf("failed to open vault: %v", err)
+	}
+	t.Cleanup(func() { _ = manager.Unlock() })
+	v := manager.Get()
+	if len(v.Identities) != 1 || v.Identities[0].UID != "Alice <alice@example.com>" {
+		t.Errorf("expected only Alice left, got %+v", v.Identities)
+	}
+
+	// The revocation is signed by Alice and verifies
+	cli.vaultResolver.(*MockVaultResolver).Managers = map[int]*vault.Manager{0: manager}
+	stdout.Reset()
+	if err := cli.VaultVerify(0, "", "", false); err != nil {
+		t.Fatalf("VaultVerify failed: %v\n%s", err, stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "revocation of identity") {
+		t.Errorf("expected the revocation to be verified, got:\n%s", stdout.String())
+	}
+}
+
+func TestIdentityPruneExpired_DryRunAndLoginRequired(t *testing.T) {
+	cli, _, path := newPruneCLI(t)
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cli.IdentityPruneExpired(true, false, "", 1); err != nil {
+		t.Fatalf("IdentityPruneExpired --dry-run failed: %v", err)
+	}
+	cli.fingerprint = ""
+	if err := cli.IdentityPruneExpired(false, true, "", 1); err == nil || err.ExitCode != ExitFingerprintRequired {
+		t.Errorf("expected a login to be required, got %v", err)
+	}
+
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("expected the vault to be unchanged")
+	}
+}
+
+func TestValidateFile_RejectsBadRevocation(t *testing.T) {
+	tests := []struct {
+		name    string
+		rewrite func(line string) string
+		want    string
+	}{
+		{
+			name: "tampered",
+			rewrite: func(line string) string {
+				return regexp.MustCompile(`"added_at":"\d{4}`).ReplaceAllString(line, `"added_at":"1999`)
+			},
+			want: "failed to verify revocation signature: hash mismatch",
+		},
+		{
+			name: "unsigned",
+			rewrite: func(line string) string {
+				return regexp.MustCompile(`"signature":"[0-9a-f]*"`).ReplaceAllString(line, `"signature":""`)
+			},
+			want: "revocation has missing signature field",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, stdout, path := newPruneCLI(t)
+			if err := cli.IdentityPruneExpired(false, true, "", 1); err != nil {
+				t.Fatalf("IdentityPruneExpired failed: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+			last := len(lines) - 1
+			rewritten := tt.rewrite(lines[last])
+			if rewritten == lines[last] {
+				t.Fatalf("rewrite left the revocation unchanged: %s", lines[last])
+			}
+			lines[last] = rewritten
+			if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			stdout.Reset()
+			if err := cli.ValidateFile(path, false); err == nil {
+				t.Fatalf("expected validation to fail:\n%s", stdout.String())
+			}
+			if !strings.Contains(stdout.String(), tt.want) || !strings.Contains(stdout.String(), "revocations[0]") {
+				t.Errorf("expected %q for revocations[0], got:\n%s", tt.want, stdout.String())
+			}
+		})
+	}
+}
+
+func TestValidateHeaderLineNumbers_Revocations(t *testing.T) {
+	header := &vault.Header{
+		Identities:  map[string]int{"FP": 4},
+		Revocations: []int{6, 4, 0},
+	}
+
+	var messages []string
+	for _, e := range validateHeaderLineNumbers(header) {
+		messages = append(messages, e.Message)
+	}
+	got := strings.Join(messages, "\n")
+	for _, want := range []string{
+		"duplicate line number 4: used by identity FP and revocation[1]",
+		"revocation line numbers not strictly ascending: revocations[0]=6 >= revocations[1]=4",
+		"revocation has invalid line number 0 (must be >= 1)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestValidateVaultFileStructure_Revocations(t *testing.T) {
+	cli, _, path := newPruneCLI(t)
+	if err := cli.IdentityPruneExpired(false, true, "", 1); err != nil {
+		t.Fatalf("IdentityPruneExpired failed: %v", err)
+	}
+	manager := vault.NewManager(path, false)
+	if err := manager.OpenAndLock(); err != nil {
+		t.Fatalf("failed to open vault: %v", err)
+	}
+	t.Cleanup(func() { _ = manager.Unlock() })
+	header, lines := manager.GetHeader(), manager.GetLines()
+	if errs := validateVaultFileStructure(header, lines); len(errs) != 0 {
+		t.Fatalf("expected a valid file structure, got %+v", errs)
+	}
+
+	// Point the revocation at an identity line, then past the end of the file
+	header.Revocations = []int{4, len(lines) + 1}
+	var messages []string
+	for _, e := range validateVaultFileStructure(header, lines) {
+		messages = append(messages, e.Message)
+	}
+	got := strings.Join(messages, "\n")
+	for _, want := range []string{
+		"line 4 is not a revocation entry (type=identity)",
+		fmt.Sprintf("revocation line number %d out of range", len(lines)+1),
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q, got:\n%s", want, got)
+		}
+	}
+}
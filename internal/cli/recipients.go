@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// defaultRecipients resolves the default_recipients of vault index to their
+// identities, leaving out signerFP and repeats. Every default recipient must
+// be an identity in that vault. It returns none with --no-default-recipients.
+func (c *CLI) defaultRecipients(index int, signerFP string) ([]*vault.Identity, *Error) {
+	entries := c.vaultResolver.GetConfig().Entries
+	if c.noDefaultRcpt || index < 0 || index >= len(entries) {
+		return nil, nil
+	}
+
+	var recipients []*vault.Identity
+	seen := map[string]bool{signerFP: true}
+	for _, fp := range entries[index].DefaultRecipients {
+		if seen[fp] {
+			continue
+		}
+		seen[fp] = true
+		var id *vault.Identity
+		if c.vaultResolver.IdentityExistsInVault(fp, index) {
+			id = c.vaultResolver.GetIdentityByFingerprint(fp)
+		}
+		if id == nil {
+			return nil, NewError(fmt.Sprintf("default recipient %s of vault %d (%s) is not an identity in that vault; add it with 'dotsecenv identity add' or use --no-default-recipients", fp, index+1, entries[index].Path), ExitVaultError)
+		}
+		recipients = append(recipients, id)
+	}
+	return recipients, nil
+}
+
+// checkMinRecipients refuses a new value of secretKey readable by count
+// identities when min_recipients requires more. action names the command
+// for the error message.
+func (c *CLI) checkMinRecipients(secretKey, action string, count int) *Error {
+	minRecipients, err := c.config.GetMinRecipients()
+	if err != nil {
+		return NewError(err.Error(), ExitConfigError)
+	}
+	if count < minRecipients {
+		return NewError(fmt.Sprintf("cannot %s: the new value of '%s' would be readable by %d identity(ies), fewer than min_recipients (%d)", action, secretKey, count, minRecipients), ExitValidationError)
+	}
+	return nil
+}
+
+// doctorRecipientChecks reports, per loaded vault, the secrets whose current
+// value is readable by fewer identities than min_recipients. It returns no
+// checks when min_recipients is not set.
+func (c *CLI) doctorRecipientChecks() []DoctorCheckJSON {
+	minRecipients, err := c.config.GetMinRecipients()
+	if err != nil {
+		return []DoctorCheckJSON{{
+			Name:    "min_recipients",
+			Status:  "error",
+			Message: err.Error(),
+		}}
+	}
+	if minRecipients == 0 {
+		return nil
+	}
+
+	var checks []DoctorCheckJSON
+	for _, v := range c.vaultResolver.GetAvailableVaultPathsWithIndices() {
+		var violating []string
+		for _, info := range c.vaultResolver.ListSecretKeysFromVault(v.Index) {
+			secret := c.vaultResolver.GetSecretByKeyFromVault(v.Index, info.Key)
+			if secret == nil || len(secret.Values) == 0 || secret.IsDeleted() {
+				continue
+			}
+			current := secret.Values[len(secret.Values)-1]
+			if len(current.AvailableTo) < minRecipients {
+				violating = append(violating, fmt.Sprintf("%s (%d)", secret.Key, len(current.AvailableTo)))
+			}
+		}
+
+		check := DoctorCheckJSON{
+			Name:    fmt.Sprintf("vault_%d_recipients", v.Index+1),
+			Status:  "ok",
+			Message: fmt.Sprintf("%s: all secrets have at least %d recipient(s)", v.Path, minRecipients),
+		}
+		if len(violating) > 0 {
+			check.Status = "warning"
+			check.Message = fmt.Sprintf("%s: %d secret(s) below min_recipients (%d)", v.Path, len(violating), minRecipients)
+			check.Details = "Share them with 'dotsecenv secret share': " + strings.Join(violating, ", ")
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
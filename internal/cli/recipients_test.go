@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+const recipientsOtherFP = "OTHERFINGERPRINT"
+
+// newSharedSecretCLI returns a CLI for MYFINGERPRINT whose vault holds
+// DB_URL shared with recipients.
+func newSharedSecretCLI(t *testing.T, recipients ...string) (*CLI, *MockVaultResolver) {
+	t.Helper()
+	cli, _ := newSecretStoreCLI(t, []string{"/vault1.yaml"}, []string{"/vault1.yaml"})
+	mock := cli.vaultResolver.(*MockVaultResolver)
+
+	secret := vault.Secret{Key: "DB_URL", Values: []vault.SecretValue{
+		{AvailableTo: recipients, Value: "ZW5jcnlwdGVk"},
+	}}
+	mock.Secrets[0] = map[string]vault.Secret{"DB_URL": secret}
+	mock.Managers = map[int]*vault.Manager{0: newTestManager(t, vault.Vault{Secrets: []vault.Secret{secret}})}
+	return cli, mock
+}
+
+func TestSecretPut_RefusedBelowMinRecipients(t *testing.T) {
+	const fp = "MYFINGERPRINT"
+	cli, _ := newSecretStoreCLI(t, []string{"/vault1.yaml"}, []string{"/vault1.yaml"})
+	mock := cli.vaultResolver.(*MockVaultResolver)
+	id := vault.Identity{Fingerprint: fp, PublicKey: "base64pubkey", Algorithm: "RSA", AlgorithmBits: 4096}
+	mock.Identities[fp] = id
+	mock.IdentitiesByVault[0] = map[string]vault.Identity{fp: id}
+	cli.config.MinRecipients = 2
+
+	err := cli.SecretPutValue("DB_URL", "", 1, "value", false)
+	if err == nil || err.ExitCode != ExitValidationError {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	if !strings.Contains(err.Message, "min_recipients (2)") {
+		t.Errorf("error should name the policy: %q", err.Message)
+	}
+	if len(mock.Secrets[0]) != 0 {
+		t.Errorf("expected nothing stored, got %v", mock.Secrets[0])
+	}
+
+	cli.config.MinRecipients = 1
+	if err := cli.SecretPutValue("DB_URL", "", 1, "value", false); err != nil {
+		t.Fatalf("put should succeed with min_recipients: 1: %v", err)
+	}
+}
+
+func TestSecretRevoke_RefusedBelowMinRecipients(t *testing.T) {
+	cli, mock := newSharedSecretCLI(t, "MYFINGERPRINT", recipientsOtherFP)
+	cli.config.MinRecipients = 2
+
+	err := cli.SecretRevoke("DB_URL", recipientsOtherFP, 0)
+	if err == nil || err.ExitCode != ExitValidationError {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	if !strings.Contains(err.Message, "readable by 1 identity(ies)") {
+		t.Errorf("unexpected message: %q", err.Message)
+	}
+	if got := mock.Secrets[0]["DB_URL"].Values; len(got) != 1 {
+		t.Errorf("expected the secret unchanged, got %d value(s)", len(got))
+	}
+}
+
+func TestSecretPut_DefaultRecipientsMeetMinRecipients(t *testing.T) {
+	cli, stored := newDefaultRecipientsCLI(t, []string{"ALICEFP"}, []string{"ALICEFP"})
+	cli.config.MinRecipients = 2
+
+	if err := cli.SecretPutValue("DB_URL", "", 1, "v", false); err != nil {
+		t.Fatalf("put to the default recipients should meet min_recipients: 2: %v", err)
+	}
+	if got := (*stored)[0].AvailableTo; len(got) != 2 {
+		t.Errorf("expected the value readable by 2 identities, got %v", got)
+	}
+
+	cli.SetNoDefaultRecipients(true)
+	if err := cli.SecretPutValue("DB_URL", "", 1, "v", false); err == nil || err.ExitCode != ExitValidationError {
+		t.Errorf("expected a validation error without the default recipients, got %v", err)
+	}
+}
+
+func TestVaultRekey_RefusedBelowMinRecipients(t *testing.T) {
+	v := vault.Vault{Secrets: []vault.Secret{
+		rekeyTestSecret("A_SHARED", "ALICE", "ME"),
+		rekeyTestSecret("B_TRIO", "ALICE", "BOB", "ME"),
+	}}
+	cli, _, written := newRekeyCLI(t, v, "ME", "ALICE", "BOB", "CAROL")
+	cli.config.MinRecipients = 2
+
+	err := cli.VaultRekey(nil, []string{"ALICE"}, false, "", 1)
+	if err == nil || err.ExitCode != ExitValidationError {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	if !strings.Contains(err.Message, "cannot rekey: the new value of 'A_SHARED' would be readable by 1 identity(ies)") {
+		t.Errorf("unexpected message: %q", err.Message)
+	}
+	if len(written) != 0 {
+		t.Errorf("expected nothing rekeyed, got %v", written)
+	}
+
+	// Widening a value still below the minimum is allowed
+	cli.config.MinRecipients = 4
+	if err := cli.VaultRekey([]string{"CAROL"}, nil, false, "", 1); err != nil {
+		t.Fatalf("rekey --add should not be refused: %v", err)
+	}
+	if len(written) != 2 {
+		t.Errorf("expected both secrets rekeyed, got %v", written)
+	}
+}
+
+func TestVaultDoctor_FlagsSecretsBelowMinRecipients(t *testing.T) {
+	cli, _ := newSharedSecretCLI(t, "MYFINGERPRINT")
+	mock := cli.vaultResolver.(*MockVaultResolver)
+	mock.Secrets[0]["API_KEY"] = vault.Secret{Key: "API_KEY", Values: []vault.SecretValue{
+		{AvailableTo: []string{"MYFINGERPRINT", recipientsOtherFP}},
+	}}
+
+	checks := cli.doctorRecipientChecks()
+	if len(checks) != 0 {
+		t.Fatalf("expected no checks without min_recipients, got %+v", checks)
+	}
+
+	cli.config.MinRecipients = 2
+	checks = cli.doctorRecipientChecks()
+	if len(checks) != 1 || checks[0].Status != "warning" {
+		t.Fatalf("expected one warning, got %+v", checks)
+	}
+	if !strings.Contains(checks[0].Details, "DB_URL (1)") || strings.Contains(checks[0].Details, "API_KEY") {
+		t.Errorf("unexpected details: %q", checks[0].Details)
+	}
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// VaultReindex rebuilds a vault's header from its data lines and rewrites the
+// vault defragmented, in canonical order, in one pass. It recovers a vault
+// whose header no longer matches its data, which other commands cannot load.
+//
+// The rewritten vault is read back and validated, signatures included, before
+// it replaces the file; when validation fails the vault is left unchanged. It
+// prints the header drift it found and what changed.
+func (c *CLI) VaultReindex(vaultPath string, fromIndex int) *Error {
+	targetIndex, resolveErr := c.resolveWritableVaultIndex(vaultPath, fromIndex, "Select vault to reindex:")
+	if resolveErr != nil {
+		return resolveErr
+	}
+
+	entry := c.vaultResolver.GetConfig().Entries[targetIndex]
+	expandedPath := vault.ExpandPath(entry.Path)
+
+	if writeErr := checkVaultWritable(entry.Path); writeErr != nil {
+		return writeErr
+	}
+
+	writer, err := vault.NewWriter(expandedPath)
+	if err != nil {
+		return NewError(fmt.Sprintf("failed to open vault: %v", err), ExitVaultError)
+	}
+
+	var invalid []ValidationError
+	stats, err := vault.Reindex(writer, func(v vault.Vault) error {
+		invalid = validateVaultData(v, v)
+		if len(invalid) > 0 {
+			return fmt.Errorf("reindexed vault fails validation")
+		}
+		return nil
+	})
+	if len(invalid) > 0 {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "reindexed vault fails validation; vault unchanged:")
+		for _, v := range invalid {
+			fmt.Fprintf(&sb, "\n  %s: %s at %s", v.Level, v.Message, v.Path)
+		}
+		return NewError(sb.String(), ExitValidationError)
+	}
+	if err != nil {
+		return NewError(fmt.Sprintf("failed to reindex vault: %v", err), ExitVaultError)
+	}
+
+	out := c.output.Stdout()
+	_, _ = fmt.Fprintf(out, "Vault: %s\n", entry.Path)
+	if len(stats.Drift) == 0 {
+		_, _ = fmt.Fprintf(out, "Header matches the data lines.\n")
+	} else {
+		_, _ = fmt.Fprintf(out, "Header drift (%d):\n", len(stats.Drift))
+		for _, d := range stats.Drift {
+			_, _ = fmt.Fprintf(out, "  %s\n", d)
+		}
+	}
+	if !stats.Changed {
+		_, _ = fmt.Fprintf(out, "Vault is already indexed and in canonical order; nothing to do.\n")
+		return nil
+	}
+
+	if hookErr := c.runPostWriteHook(targetIndex); hookErr != nil {
+		return hookErr
+	}
+	if stats.SupersededIdentities > 0 {
+		_, _ = fmt.Fprintf(out, "Dropped %d superseded identity record(s).\n", stats.SupersededIdentities)
+	}
+	if stats.VersionBefore != stats.VersionAfter {
+		_, _ = fmt.Fprintf(out, "Upgraded format v%d -> v%d.\n", stats.VersionBefore, stats.VersionAfter)
+	}
+	_, _ = fmt.Fprintf(out, "Reindexed %s: %d identity(ies), %d secret(s), %d value(s); %d -> %d lines.\n",
+		expandedPath, stats.Identities, stats.Secrets, stats.Values, stats.LinesBefore, stats.LinesAfter)
+	return nil
+}
@@ -0,0 +1,275 @@
+package cli
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// rekeyPlan describes what vault rekey does to a single secret.
+type rekeyPlan struct {
+	key        string
+	current    vault.SecretValue
+	recipients []string
+	skipReason string
+}
+
+// VaultRekey re-encrypts every secret in a vault to an adjusted recipient set:
+// the fingerprints in add are granted access and those in remove lose it.
+// Secrets the caller cannot decrypt are skipped and reported, since only a
+// current reader can rotate them. All values are prepared before anything is
+// written, so a failing encryption leaves no partial rekey behind; the vault
+// is then saved once. Identities in add that are not yet in the vault are
+// added after the rekeyed values, in the same save.
+//
+// A value left readable by fewer identities than min_recipients refuses the
+// whole rekey, as for 'secret revoke'; values that only gain readers are
+// rekeyed even while still below the minimum, as for 'secret share'.
+//
+// With dryRun it prints the plan without decrypting or writing.
+func (c *CLI) VaultRekey(add, remove []string, dryRun bool, vaultPath string, fromIndex int) *Error {
+	add = normalizeFingerprints(add)
+	remove = normalizeFingerprints(remove)
+	if len(add) == 0 && len(remove) == 0 {
+		return NewError("nothing to do: specify at least one --add or --remove fingerprint", ExitValidationError)
+	}
+	for _, fp := range add {
+		for _, removed := range remove {
+			if fp == removed {
+				return NewError(fmt.Sprintf("fingerprint %s cannot be both added and removed", fp), ExitValidationError)
+			}
+		}
+	}
+
+	fp, fpErr := c.checkFingerprintRequired("vault rekey")
+	if fpErr != nil {
+		return fpErr
+	}
+
+	targetIndex, resolveErr := c.resolveWritableVaultIndex(vaultPath, fromIndex, "Select vault to rekey:")
+	if resolveErr != nil {
+		return resolveErr
+	}
+
+	manager := c.vaultResolver.GetVaultManager(targetIndex)
+	if manager == nil {
+		return NewError(fmt.Sprintf("vault %d is not loaded", targetIndex+1), ExitVaultError)
+	}
+
+	plans := planRekey(manager.Get(), fp, add, remove)
+	for _, p := range plans {
+		if p.skipReason != "" || len(p.recipients) >= len(p.current.AvailableTo) {
+			continue
+		}
+		if minErr := c.checkMinRecipients(p.key, "rekey", len(p.recipients)); minErr != nil {
+			return minErr
+		}
+	}
+
+	out := c.output.Stdout()
+	_, _ = fmt.Fprintf(out, "Vault %d (%s):\n", targetIndex+1, manager.Path())
+
+	if dryRun {
+		rekeyed, unchanged, skipped := 0, 0, 0
+		for _, p := range plans {
+			switch {
+			case p.skipReason != "":
+				_, _ = fmt.Fprintf(out, "  %s: skipped, %s\n", p.key, p.skipReason)
+				skipped++
+			case p.recipients == nil:
+				_, _ = fmt.Fprintf(out, "  %s: unchanged\n", p.key)
+				unchanged++
+			default:
+				_, _ = fmt.Fprintf(out, "  %s: would rekey\n", p.key)
+				rekeyed++
+			}
+		}
+		_, _ = fmt.Fprintf(out, "Dry run: would rekey %d, unchanged %d, skipped %d\n", rekeyed, unchanged, skipped)
+		return nil
+	}
+
+	// Build the missing identities now, so values can be encrypted to them,
+	// but add them only with the rekeyed values.
+	var newIdentities []*vault.Identity
+	pendingIdentities := make(map[string]*vault.Identity)
+	for _, addFP := range add {
+		newIdentity, idErr := c.autoAddIdentity(addFP, targetIndex)
+		if idErr != nil {
+			return idErr
+		}
+		if newIdentity != nil {
+			newIdentities = append(newIdentities, newIdentity)
+			pendingIdentities[addFP] = newIdentity
+		}
+	}
+
+	pending := 0
+	for _, p := range plans {
+		if p.skipReason == "" && p.recipients != nil {
+			pending++
+		}
+	}
+
+	// Prepare every new value before writing any of them.
+	progress := c.newProgress("re-encrypted", "secrets", pending)
+	var prepared []vault.Secret
+	for i := range plans {
+		p := &plans[i]
+		if p.skipReason != "" || p.recipients == nil {
+			continue
+		}
+		newValue, rekeyErr := c.rekeyValue(p.key, p.current, p.recipients, fp, pendingIdentities)
+		progress.Increment()
+		if rekeyErr != nil {
+			p.skipReason = rekeyErr.Message
+			continue
+		}
+		prepared = append(prepared, vault.Secret{Key: p.key, Values: []vault.SecretValue{newValue}})
+	}
+
+	c.vaultResolver.DeferWrites()
+	for _, s := range prepared {
+		if addErr := c.vaultResolver.AddSecret(s, targetIndex); addErr != nil {
+			return NewError(fmt.Sprintf("failed to add rekeyed value for %s: %v", s.Key, addErr), ExitVaultError)
+		}
+	}
+	for _, id := range newIdentities {
+		if addErr := c.vaultResolver.AddIdentity(*id, targetIndex); addErr != nil {
+			return NewError(fmt.Sprintf("failed to add identity: %v", addErr), ExitVaultError)
+		}
+	}
+	if len(prepared) > 0 || len(newIdentities) > 0 {
+		if saveErr := c.vaultResolver.SaveVault(targetIndex); saveErr != nil {
+			return NewError(fmt.Sprintf("failed to save vault: %v", saveErr), ExitVaultError)
+		}
+		if hookErr := c.runPostWriteHook(targetIndex); hookErr != nil {
+			return hookErr
+		}
+	}
+
+	unchanged, skipped := 0, 0
+	for _, p := range plans {
+		switch {
+		case p.skipReason != "":
+			_, _ = fmt.Fprintf(out, "  %s: skipped, %s\n", p.key, p.skipReason)
+			skipped++
+		case p.recipients == nil:
+			_, _ = fmt.Fprintf(out, "  %s: unchanged\n", p.key)
+			unchanged++
+		default:
+			_, _ = fmt.Fprintf(out, "  %s: rekeyed\n", p.key)
+		}
+	}
+	_, _ = fmt.Fprintf(out, "Rekeyed %d, unchanged %d, skipped %d\n", len(prepared), unchanged, skipped)
+	return nil
+}
+
+// rekeyValue decrypts current as fp and re-encrypts it to recipients, which
+// may include the not yet added identities in pending.
+func (c *CLI) rekeyValue(secretKey string, current vault.SecretValue, recipients []string, fp string, pending map[string]*vault.Identity) (vault.SecretValue, *Error) {
+	if detErr := detachedValueError(secretKey, &current); detErr != nil {
+		return vault.SecretValue{}, detErr
+	}
+	encryptedArmored, decodeErr := base64.StdEncoding.DecodeString(current.Value)
+	if decodeErr != nil {
+		return vault.SecretValue{}, NewError(fmt.Sprintf("failed to decode encrypted value: %v", decodeErr), ExitGeneralError)
+	}
+
+	plaintext, decErr := c.gpgClient.DecryptWithAgent(encryptedArmored, fp)
+	if decErr != nil {
+		return vault.SecretValue{}, NewError(fmt.Sprintf("failed to decrypt secret: %v", decErr), ExitGPGError)
+	}
+	defer func() {
+		for i := range plaintext {
+			plaintext[i] = 0
+		}
+	}()
+
+	return c.encryptValueForRecipients(secretKey, plaintext, current, recipients, fp, pending)
+}
+
+// planRekey computes the new recipient set for every live secret in v. A plan
+// with nil recipients and no skip reason means the secret is already shared
+// exactly as requested.
+func planRekey(v vault.Vault, fp string, add, remove []string) []rekeyPlan {
+	var plans []rekeyPlan
+	for _, s := range v.Secrets {
+		if s.IsDeleted() || len(s.Values) == 0 {
+			continue
+		}
+		current := s.Values[len(s.Values)-1]
+		plan := rekeyPlan{key: s.Key, current: current}
+
+		recipients := adjustRecipients(current.AvailableTo, add, remove)
+		switch {
+		case sameRecipients(current.AvailableTo, recipients):
+			// Nothing to change.
+		case !current.CanBeReadBy(fp):
+			plan.skipReason = "cannot decrypt; manual rotation needed"
+		case len(recipients) == 0:
+			plan.skipReason = "would remove all access"
+		default:
+			plan.recipients = recipients
+		}
+		plans = append(plans, plan)
+	}
+	sort.Slice(plans, func(i, j int) bool { return plans[i].key < plans[j].key })
+	return plans
+}
+
+// adjustRecipients returns the sorted, de-duplicated union of current and add,
+// minus remove.
+func adjustRecipients(current, add, remove []string) []string {
+	set := make(map[string]bool, len(current)+len(add))
+	for _, fp := range current {
+		set[identity.NormalizeFingerprint(fp)] = true
+	}
+	for _, fp := range add {
+		set[fp] = true
+	}
+	for _, fp := range remove {
+		delete(set, fp)
+	}
+
+	result := make([]string, 0, len(set))
+	for fp := range set {
+		result = append(result, fp)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// sameRecipients reports whether current holds exactly the fingerprints in
+// sorted, ignoring order and case.
+func sameRecipients(current, sorted []string) bool {
+	normalized := normalizeFingerprints(current)
+	if len(normalized) != len(sorted) {
+		return false
+	}
+	for i := range normalized {
+		if normalized[i] != sorted[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeFingerprints normalizes, sorts and de-duplicates fingerprints,
+// dropping empty entries.
+func normalizeFingerprints(fps []string) []string {
+	seen := make(map[string]bool, len(fps))
+	var result []string
+	for _, fp := range fps {
+		fp = identity.NormalizeFingerprint(fp)
+		if fp == "" || seen[fp] {
+			continue
+		}
+		seen[fp] = true
+		result = append(result, fp)
+	}
+	sort.Strings(result)
+	return result
+}
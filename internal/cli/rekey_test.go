@@ -0,0 +1,225 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/gpg"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// echoDecryptGPGClient "decrypts" any ciphertext to "plain-" + ciphertext.
+type echoDecryptGPGClient struct {
+	*MockGPGClient
+}
+
+func (m *echoDecryptGPGClient) DecryptWithAgent(ciphertext []byte, fingerprint string) ([]byte, error) {
+	return []byte("plain-" + string(ciphertext)), nil
+}
+
+// newRekeyCLI builds a CLI whose single vault holds v. Every identity in
+// fingerprints is known to the resolver and already present in the vault.
+// Writes are captured in the returned map by secret key.
+func newRekeyCLI(t *testing.T, v vault.Vault, fingerprints ...string) (*CLI, *bytes.Buffer, map[string]vault.SecretValue) {
+	t.Helper()
+
+	manager := newTestManager(t, v)
+	resolver := NewMockVaultResolver()
+	resolver.VaultEntries = []vault.VaultEntry{{Path: manager.Path()}}
+	resolver.VaultPaths = []string{manager.Path()}
+	resolver.Managers = map[int]*vault.Manager{0: manager}
+	resolver.IdentitiesByVault[0] = map[string]vault.Identity{}
+	for _, fp := range fingerprints {
+		id := vault.Identity{Fingerprint: fp, PublicKey: "pub-" + fp, AlgorithmBits: 256}
+		resolver.Identities[fp] = id
+		resolver.IdentitiesByVault[0][fp] = id
+	}
+
+	written := map[string]vault.SecretValue{}
+	resolver.AddSecretFunc = func(s vault.Secret, index int) error {
+		written[s.Key] = s.Values[0]
+		return nil
+	}
+
+	stdout := &bytes.Buffer{}
+	cli := &CLI{
+		config:        config.Config{Login: newTestSignedLogin(t, "ME")},
+		vaultResolver: resolver,
+		gpgClient:     &echoDecryptGPGClient{MockGPGClient: NewMockGPGClient()},
+		output:        output.NewHandler(stdout, &bytes.Buffer{}),
+	}
+	return cli, stdout, written
+}
+
+func rekeyTestSecret(key string, recipients ...string) vault.Secret {
+	now := time.Now().UTC()
+	return vault.Secret{
+		Key:     key,
+		AddedAt: now,
+		Values: []vault.SecretValue{{
+			AddedAt:     now,
+			AvailableTo: recipients,
+			Value:       base64.StdEncoding.EncodeToString([]byte("cipher-" + key)),
+		}},
+	}
+}
+
+func TestVaultRekey(t *testing.T) {
+	v := vault.Vault{
+		Secrets: []vault.Secret{
+			rekeyTestSecret("A_SHARED", "ALICE", "ME"),
+			rekeyTestSecret("B_MINE", "ME"),
+			rekeyTestSecret("C_FOREIGN", "ALICE"),
+		},
+	}
+
+	tests := []struct {
+		name    string
+		add     []string
+		remove  []string
+		want    map[string]string // secret key -> comma-joined recipients
+		summary string
+	}{
+		{
+			name:    "add only",
+			add:     []string{"bob"},
+			want:    map[string]string{"A_SHARED": "ALICE,BOB,ME", "B_MINE": "BOB,ME"},
+			summary: "Rekeyed 2, unchanged 0, skipped 1",
+		},
+		{
+			name:    "remove only",
+			remove:  []string{"ALICE"},
+			want:    map[string]string{"A_SHARED": "ME"},
+			summary: "Rekeyed 1, unchanged 1, skipped 1",
+		},
+		{
+			name:    "mixed",
+			add:     []string{"BOB"},
+			remove:  []string{"ALICE"},
+			want:    map[string]string{"A_SHARED": "BOB,ME", "B_MINE": "BOB,ME"},
+			summary: "Rekeyed 2, unchanged 0, skipped 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, stdout, written := newRekeyCLI(t, v, "ME", "ALICE", "BOB")
+
+			if err := cli.VaultRekey(tt.add, tt.remove, false, "", 1); err != nil {
+				t.Fatalf("VaultRekey failed: %v", err)
+			}
+
+			if len(written) != len(tt.want) {
+				t.Errorf("expected %d rekeyed secrets, got %d: %v", len(tt.want), len(written), written)
+			}
+			for key, wantRecipients := range tt.want {
+				value, ok := written[key]
+				if !ok {
+					t.Errorf("%s was not rekeyed", key)
+					continue
+				}
+				if got := strings.Join(value.AvailableTo, ","); got != wantRecipients {
+					t.Errorf("%s: recipients = %s, want %s", key, got, wantRecipients)
+				}
+				if value.SignedBy != "ME" || value.Hash == "" || value.Signature == "" {
+					t.Errorf("%s: new value is not signed by the caller: %+v", key, value)
+				}
+			}
+
+			text := stdout.String()
+			if !strings.Contains(text, "C_FOREIGN: skipped, cannot decrypt; manual rotation needed") {
+				t.Errorf("expected undecryptable secret to be reported as skipped:\n%s", text)
+			}
+			if !strings.Contains(text, tt.summary) {
+				t.Errorf("expected summary %q in output:\n%s", tt.summary, text)
+			}
+		})
+	}
+}
+
+func TestVaultRekey_AddsNewIdentityWithRekeyedValues(t *testing.T) {
+	v := vault.Vault{Secrets: []vault.Secret{rekeyTestSecret("KEY", "ME")}}
+	cli, _, written := newRekeyCLI(t, v, "ME")
+	cli.config.ApprovedAlgorithms = []config.ApprovedAlgorithm{{Algo: "RSA", MinBits: 2048}}
+	cli.gpgClient.(*echoDecryptGPGClient).PublicKeyInfo["BOB"] = gpg.KeyInfo{
+		Fingerprint:     "BOB",
+		UID:             "Bob <bob@example.com>",
+		Algorithm:       "RSA",
+		AlgorithmBits:   2048,
+		CanEncrypt:      true,
+		PublicKeyBase64: "pub-BOB",
+	}
+	resolver := cli.vaultResolver.(*MockVaultResolver)
+	store := resolver.AddSecretFunc
+	resolver.AddSecretFunc = func(s vault.Secret, index int) error {
+		if resolver.IdentityExistsInVault("BOB", index) {
+			t.Errorf("BOB was added before the rekeyed value of %s", s.Key)
+		}
+		return store(s, index)
+	}
+
+	if err := cli.VaultRekey([]string{"BOB"}, nil, false, "", 1); err != nil {
+		t.Fatalf("VaultRekey failed: %v", err)
+	}
+	if got := strings.Join(written["KEY"].AvailableTo, ","); got != "BOB,ME" {
+		t.Errorf("recipients = %s, want BOB,ME", got)
+	}
+	if ciphertext, _ := base64.StdEncoding.DecodeString(written["KEY"].Value); !strings.HasPrefix(string(ciphertext), "encrypted_to_pub-BOB_pub-ME_") {
+		t.Errorf("expected the value to be encrypted to BOB's key, got %s", ciphertext)
+	}
+	if !resolver.IdentityExistsInVault("BOB", 0) {
+		t.Error("expected BOB to be added to the vault")
+	}
+	if len(resolver.SavedVaults) != 1 {
+		t.Errorf("expected a single save, got %v", resolver.SavedVaults)
+	}
+}
+
+func TestVaultRekey_DryRunWritesNothing(t *testing.T) {
+	v := vault.Vault{Secrets: []vault.Secret{rekeyTestSecret("KEY", "ME")}}
+	cli, stdout, written := newRekeyCLI(t, v, "ME", "BOB")
+
+	if err := cli.VaultRekey([]string{"BOB"}, nil, true, "", 1); err != nil {
+		t.Fatalf("VaultRekey failed: %v", err)
+	}
+	if len(written) != 0 {
+		t.Errorf("dry run wrote values: %v", written)
+	}
+	if !strings.Contains(stdout.String(), "KEY: would rekey") {
+		t.Errorf("expected plan in output:\n%s", stdout.String())
+	}
+}
+
+func TestVaultRekey_InvalidArguments(t *testing.T) {
+	cli, _, _ := newRekeyCLI(t, vault.Vault{}, "ME")
+
+	if err := cli.VaultRekey(nil, nil, false, "", 1); err == nil || err.ExitCode != ExitValidationError {
+		t.Errorf("expected validation error without fingerprints, got %v", err)
+	}
+	if err := cli.VaultRekey([]string{"bob"}, []string{"BOB"}, false, "", 1); err == nil || err.ExitCode != ExitValidationError {
+		t.Errorf("expected validation error for conflicting fingerprints, got %v", err)
+	}
+}
+
+func TestVaultRekey_KeepsValueDetached(t *testing.T) {
+	secret := rekeyTestSecret("BIG", "ME")
+	secret.Values[0].Detach()
+	original := secret.Values[0].ValueRef
+	cli, _, written := newRekeyCLI(t, vault.Vault{Secrets: []vault.Secret{secret}}, "ME", "BOB")
+
+	if err := cli.VaultRekey([]string{"BOB"}, nil, false, "", 1); err != nil {
+		t.Fatalf("VaultRekey failed: %v", err)
+	}
+	value := written["BIG"]
+	if value.ValueRef == "" || value.ValueRef == original {
+		t.Fatalf("expected the rekeyed value to be detached under a new reference, got %q", value.ValueRef)
+	}
+	if value.ValueRef != vault.DetachedValueRef(value.Value) {
+		t.Errorf("expected the reference of the new encrypted value, got %q", value.ValueRef)
+	}
+}
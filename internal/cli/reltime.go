@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+)
+
+// formatTime renders t for human output: RFC3339 by default, or relative to
+// now (e.g. "3 days ago") when relative times are enabled. JSON output never
+// goes through here and always keeps absolute timestamps.
+func (c *CLI) formatTime(t time.Time) string {
+	if c.relativeTimes {
+		return relativeTime(t, time.Now())
+	}
+	return t.Format(time.RFC3339)
+}
+
+// relativeTime describes t relative to now in the largest whole unit, from
+// seconds up to years. Months are 30 days and years 365 days.
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	if d < time.Minute {
+		return "just now"
+	}
+
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+	for _, u := range units {
+		if d < u.size {
+			continue
+		}
+		n := int(d / u.size)
+		name := u.name
+		if n != 1 {
+			name += "s"
+		}
+		if future {
+			return fmt.Sprintf("in %d %s", n, name)
+		}
+		return fmt.Sprintf("%d %s ago", n, name)
+	}
+	return "just now"
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+)
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2024, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{10 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{59 * time.Minute, "59 minutes ago"},
+		{2 * time.Hour, "2 hours ago"},
+		{3*24*time.Hour + 5*time.Hour, "3 days ago"},
+		{45 * 24 * time.Hour, "1 month ago"},
+		{800 * 24 * time.Hour, "2 years ago"},
+		{-2 * 24 * time.Hour, "in 2 days"},
+	}
+
+	for _, tt := range tests {
+		if got := relativeTime(now.Add(-tt.ago), now); got != tt.want {
+			t.Errorf("relativeTime(now-%v) = %q, want %q", tt.ago, got, tt.want)
+		}
+	}
+}
+
+func TestWriteSecretValueList_RelativeTimes(t *testing.T) {
+	addedAt := time.Now().Add(-3*24*time.Hour - time.Hour).UTC()
+	values := []SecretValueJSON{{AddedAt: addedAt, Value: "v1", Vault: "/vault"}}
+
+	for _, relative := range []bool{false, true} {
+		stdout := &bytes.Buffer{}
+		cli := &CLI{output: output.NewHandler(stdout, &bytes.Buffer{})}
+		cli.SetRelativeTimes(relative)
+
+		if err := cli.writeSecretValueList(values); err != nil {
+			t.Fatalf("writeSecretValueList: %v", err)
+		}
+
+		want := addedAt.Format(time.RFC3339) + " (/vault): v1\n"
+		if relative {
+			want = "3 days ago (/vault): v1\n"
+		}
+		if got := stdout.String(); !strings.HasSuffix(got, want) {
+			t.Errorf("relative=%v: got %q, want %q", relative, got, want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// RenderOptions configures SecretRender.
+type RenderOptions struct {
+	// Template is the path of the Go text/template to render.
+	Template string
+	// Default replaces secrets that are missing or deleted when UseDefault
+	// is set; otherwise they fail the render.
+	Default    string
+	UseDefault bool
+}
+
+// SecretRender renders the Go text/template at opts.Template to stdout. The
+// template reads secrets with {{ secret "KEY" }}; each key resolves as
+// 'secret get KEY' does, or only from the vault at -v, and is decrypted the
+// first time the template references it. Nothing is printed if rendering
+// fails.
+func (c *CLI) SecretRender(opts RenderOptions, vaultPath string, fromIndex int) *Error {
+	// Printing values is the purpose of secret render, so it is exempt from
+	// the --redact-stdout guard.
+	c.output.AllowSecretValues()
+
+	fp, err := c.checkFingerprintRequired("secret render")
+	if err != nil {
+		return err
+	}
+
+	targetIndex, resolveErr := c.resolveReadableVaultIndex(vaultPath, fromIndex)
+	if resolveErr != nil {
+		return resolveErr
+	}
+
+	text, readErr := os.ReadFile(opts.Template)
+	if readErr != nil {
+		return NewError(fmt.Sprintf("failed to read template: %v", readErr), ExitGeneralError)
+	}
+
+	decrypted := make(map[string][]byte)
+	defer func() {
+		for _, v := range decrypted {
+			clear(v)
+		}
+	}()
+
+	// lookup errors are returned as *Error so their exit code survives
+	// text/template wrapping them.
+	secretFunc := func(key string) (string, error) {
+		secretKey, normErr := vault.NormalizeSecretKey(key)
+		if normErr != nil {
+			return "", NewError(vault.FormatSecretKeyError(normErr), ExitValidationError)
+		}
+		if plaintext, ok := decrypted[secretKey]; ok {
+			return string(plaintext), nil
+		}
+
+		value, lookupErr := c.readableSecretValue(secretKey, fp, targetIndex)
+		if lookupErr != nil {
+			if opts.UseDefault && lookupErr.ExitCode == ExitVaultError {
+				return opts.Default, nil
+			}
+			return "", lookupErr
+		}
+		if detErr := detachedValueError(secretKey, value); detErr != nil {
+			return "", detErr
+		}
+		encryptedArmored, decodeErr := base64.StdEncoding.DecodeString(value.Value)
+		if decodeErr != nil {
+			return "", NewError(fmt.Sprintf("failed to decode encrypted value of '%s': %v", secretKey, decodeErr), ExitGeneralError)
+		}
+		plaintext, decErr := c.gpgClient.DecryptWithAgent(encryptedArmored, fp)
+		if decErr != nil {
+			return "", NewError(fmt.Sprintf("failed to decrypt secret '%s': %v", secretKey, decErr), ExitGPGError)
+		}
+		plaintext, inflateErr := c.inflateValue(value, plaintext)
+		if inflateErr != nil {
+			return "", inflateErr
+		}
+		decrypted[secretKey] = plaintext
+		return string(plaintext), nil
+	}
+
+	tmpl, parseErr := template.New(filepath.Base(opts.Template)).
+		Funcs(template.FuncMap{"secret": secretFunc}).
+		Parse(string(text))
+	if parseErr != nil {
+		return NewError(fmt.Sprintf("failed to parse template: %v", parseErr), ExitValidationError)
+	}
+
+	var buf bytes.Buffer
+	defer func() { clear(buf.Bytes()) }()
+	if execErr := tmpl.Execute(&buf, nil); execErr != nil {
+		var lookupErr *Error
+		if errors.As(execErr, &lookupErr) {
+			return NewError(fmt.Sprintf("failed to render template: %v", execErr), lookupErr.ExitCode)
+		}
+		return NewError(fmt.Sprintf("failed to render template: %v", execErr), ExitValidationError)
+	}
+
+	if writeErr := c.output.WriteSecretValue(buf.Bytes()); writeErr != nil {
+		return NewError(writeErr.Error(), ExitGeneralError)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// writeRenderTemplate writes text to a template file and returns its path.
+func writeRenderTemplate(t *testing.T, text string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.conf.tmpl")
+	if err := os.WriteFile(path, []byte(text), 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	return path
+}
+
+func TestSecretRender(t *testing.T) {
+	cli, stdout, _ := newExportCLI(t, map[string]vault.Secret{
+		"DB_PASSWORD":   exportTestSecret("DB_PASSWORD", "hunter2", "ME"),
+		"prod::API_KEY": exportTestSecret("prod::API_KEY", "key-123", "ME"),
+		// Not referenced, so its unreadable value must not fail the render
+		"OTHERS_ONLY": exportTestSecret("OTHERS_ONLY", "hidden", "SOMEONE"),
+	})
+	path := writeRenderTemplate(t, "password = {{ secret \"DB_PASSWORD\" }}\napi_key = {{ secret \"prod::API_KEY\" }}\nagain = {{ secret \"DB_PASSWORD\" }}\n")
+
+	if err := cli.SecretRender(RenderOptions{Template: path}, "", 0); err != nil {
+		t.Fatalf("SecretRender failed: %v", err)
+	}
+	want := "password = hunter2\napi_key = key-123\nagain = hunter2\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestSecretRender_MissingSecret(t *testing.T) {
+	cli, stdout, _ := newExportCLI(t, map[string]vault.Secret{
+		"DB_PASSWORD": exportTestSecret("DB_PASSWORD", "hunter2", "ME"),
+	})
+	path := writeRenderTemplate(t, "password = {{ secret \"DB_PASSWORD\" }}\nhost = {{ secret \"DB_HOST\" }}\n")
+
+	err := cli.SecretRender(RenderOptions{Template: path}, "", 0)
+	if err == nil || err.ExitCode != ExitVaultError {
+		t.Fatalf("expected a vault error, got %v", err)
+	}
+	if !strings.Contains(err.Message, "DB_HOST") {
+		t.Errorf("expected the missing key in the error, got: %s", err.Message)
+	}
+	if strings.Contains(err.Message, "hunter2") {
+		t.Errorf("error leaks a secret value: %s", err.Message)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("expected no output on failure, got:\n%s", stdout.String())
+	}
+
+	if err := cli.SecretRender(RenderOptions{Template: path, Default: "localhost", UseDefault: true}, "", 0); err != nil {
+		t.Fatalf("SecretRender with default failed: %v", err)
+	}
+	if got, want := stdout.String(), "password = hunter2\nhost = localhost\n"; got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestSecretRender_AccessDenied(t *testing.T) {
+	cli, _, _ := newExportCLI(t, map[string]vault.Secret{
+		"OTHERS_ONLY": exportTestSecret("OTHERS_ONLY", "hidden", "SOMEONE"),
+	})
+	path := writeRenderTemplate(t, "{{ secret \"OTHERS_ONLY\" }}")
+
+	err := cli.SecretRender(RenderOptions{Template: path, UseDefault: true}, "", 0)
+	if err == nil || err.ExitCode != ExitAccessDenied {
+		t.Fatalf("expected access denied despite --default, got %v", err)
+	}
+}
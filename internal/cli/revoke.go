@@ -3,9 +3,7 @@ package cli
 import (
 	"encoding/base64"
 	"fmt"
-	"slices"
 	"sort"
-	"time"
 
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
 )
@@ -77,7 +75,7 @@ func (c *CLI) SecretRevokeAll(secretKey, targetFingerprint string) *Error {
 			continue
 		}
 		currentValue := secretObj.Values[len(secretObj.Values)-1]
-		if !slices.Contains(currentValue.AvailableTo, targetFingerprint) {
+		if !currentValue.CanBeReadBy(targetFingerprint) {
 			_, _ = fmt.Fprintf(c.output.Stdout(), "Vault %d (%s): skipped, target does not have access\n", displayPos, vaultPath)
 			skippedCount++
 			continue
@@ -132,12 +130,12 @@ func (c *CLI) secretRevokeInVault(secretKey, targetFingerprint string, vaultInde
 	currentValue := secretObj.Values[len(secretObj.Values)-1]
 
 	// Ensure the current user has access to the latest value (prevent revocation by users with only old access)
-	if !slices.Contains(currentValue.AvailableTo, fp) {
+	if !currentValue.CanBeReadBy(fp) {
 		return NewError(fmt.Sprintf("access denied: you do not have access to the latest value of secret: %s", secretKey), ExitAccessDenied)
 	}
 
 	// Check if the secret is shared with the target fingerprint
-	if !slices.Contains(currentValue.AvailableTo, targetFingerprint) {
+	if !currentValue.CanBeReadBy(targetFingerprint) {
 		if !silent {
 			vaultPath := ""
 			vaultPaths := c.vaultResolver.GetVaultPaths()
@@ -173,8 +171,14 @@ func (c *CLI) secretRevokeInVault(secretKey, targetFingerprint string, vaultInde
 	if len(newRecipients) == 0 {
 		return NewError("cannot revoke: this would remove all access to the secret", ExitGeneralError)
 	}
+	if minErr := c.checkMinRecipients(secretKey, "revoke", len(newRecipients)); minErr != nil {
+		return minErr
+	}
 
 	// Decrypt the current value
+	if detErr := detachedValueError(secretKey, &currentValue); detErr != nil {
+		return detErr
+	}
 	encryptedArmored, decodeErr := base64.StdEncoding.DecodeString(currentValue.Value)
 	if decodeErr != nil {
 		return NewError(fmt.Sprintf("failed to decode encrypted value: %v", decodeErr), ExitGeneralError)
@@ -187,50 +191,11 @@ func (c *CLI) secretRevokeInVault(secretKey, targetFingerprint string, vaultInde
 
 	sort.Strings(newRecipients)
 
-	// Gather public keys for the remaining recipients
-	var recipientPublicKeys []string
-	for _, recipientFP := range newRecipients {
-		recipientIdentity := c.vaultResolver.GetIdentityByFingerprint(recipientFP)
-		if recipientIdentity == nil {
-			return NewError(fmt.Sprintf("recipient identity not found: %s", recipientFP), ExitVaultError)
-		}
-		recipientPublicKeys = append(recipientPublicKeys, recipientIdentity.PublicKey)
-	}
-
-	// Re-encrypt for the remaining recipients
-	encryptedArmoredNew, encErr := c.gpgClient.EncryptToRecipients(plaintext, recipientPublicKeys, nil)
+	newSecretValue, encErr := c.encryptValueForRecipients(secretKey, plaintext, currentValue, newRecipients, fp, nil)
 	if encErr != nil {
-		return NewError(fmt.Sprintf("failed to encrypt secret: %v", encErr), ExitGeneralError)
+		return encErr
 	}
 
-	encryptedBase64 := base64.StdEncoding.EncodeToString([]byte(encryptedArmoredNew))
-
-	// Create the new secret value with updated metadata
-	now := time.Now().UTC()
-	algorithmBits := 256
-	signingIdentity := c.vaultResolver.GetIdentityByFingerprint(fp)
-	if signingIdentity != nil {
-		algorithmBits = signingIdentity.AlgorithmBits
-	}
-
-	// Build secret value struct first (without hash/signature)
-	newSecretValue := vault.SecretValue{
-		AddedAt:     now,
-		AvailableTo: newRecipients,
-		SignedBy:    fp,
-		Value:       encryptedBase64,
-		Deleted:     false,
-	}
-
-	// Compute hash using shared function
-	valueHash := vault.ComputeSecretValueHash(&newSecretValue, secretKey, algorithmBits)
-	valueSig, sigErr := c.gpgClient.SignDataWithAgent(fp, []byte(valueHash))
-	if sigErr != nil {
-		return NewError(fmt.Sprintf("failed to sign secret value: %v", sigErr), ExitGeneralError)
-	}
-	newSecretValue.Hash = valueHash
-	newSecretValue.Signature = valueSig
-
 	// Use AddSecret which handles adding values to existing secrets via the writer
 	newSecret := vault.Secret{
 		Key:    secretKey,
@@ -249,5 +214,5 @@ func (c *CLI) secretRevokeInVault(secretKey, targetFingerprint string, vaultInde
 		displayPos := vaultIndex + 1
 		_, _ = fmt.Fprintf(c.output.Stdout(), "Vault %d (%s): revoked access to secret '%s' for %s\n", displayPos, vaultPath, secretKey, targetFingerprint)
 	}
-	return nil
+	return c.runPostWriteHook(vaultIndex)
 }
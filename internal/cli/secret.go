@@ -1,30 +1,36 @@
 package cli
 
 import (
-	"bufio"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"slices"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/term"
 
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
 )
 
 // SecretValueJSON is the JSON output structure for secret values.
-// AvailableTo and SignedBy are populated when --all is used so the JSON
-// output is sufficient for auditing access control across versions.
+// AvailableTo, SignedBy and Source are populated when --all is used so the
+// JSON output is sufficient for auditing access control and provenance
+// across versions.
 type SecretValueJSON struct {
 	AddedAt     time.Time   `json:"added_at"`
 	Value       interface{} `json:"value"`
 	Vault       string      `json:"vault,omitempty"`
 	AvailableTo []string    `json:"available_to,omitempty"`
 	SignedBy    string      `json:"signed_by,omitempty"`
+	Source      string      `json:"source,omitempty"`
 }
 
 // accessDeniedMessage builds the error message for a secret that exists in a
@@ -34,6 +40,12 @@ func accessDeniedMessage(secretKey, fp string) string {
 	return fmt.Sprintf("access denied: secret '%s' exists but your identity (%s) has no access to it; ask someone with access to run: dotsecenv secret share %s %s", secretKey, fp, secretKey, fp)
 }
 
+// notLatestMessage builds the error for --require-latest when the latest
+// value of a secret is not shared with the caller.
+func notLatestMessage(secretKey, fp string) string {
+	return fmt.Sprintf("access denied: latest value of secret '%s' is not available to your identity (%s) and --require-latest forbids older values; ask someone with access to run: dotsecenv secret share %s %s", secretKey, fp, secretKey, fp)
+}
+
 // smartJSONValue returns a json.RawMessage if the value is a JSON object or array,
 // otherwise returns the plain string. This avoids double-escaping stored JSON.
 func smartJSONValue(s string) interface{} {
@@ -43,23 +55,301 @@ func smartJSONValue(s string) interface{} {
 	return s
 }
 
+// secretPutTarget is the resolved destination of a new secret value.
+type secretPutTarget struct {
+	key      string
+	fp       string
+	index    int
+	identity *vault.Identity
+
+	// recipients are the other identities the value is encrypted to, taken
+	// from the vault's default_recipients.
+	recipients []*vault.Identity
+}
+
+// availableTo returns the sorted fingerprints that can read a value stored
+// for target.
+func (t *secretPutTarget) availableTo() []string {
+	fps := []string{t.fp}
+	for _, r := range t.recipients {
+		fps = append(fps, r.Fingerprint)
+	}
+	sort.Strings(fps)
+	return fps
+}
+
+// publicKeys returns the public keys a value stored for target is encrypted
+// to: the signer's, then the default recipients'.
+func (t *secretPutTarget) publicKeys() []string {
+	keys := []string{t.identity.PublicKey}
+	for _, r := range t.recipients {
+		keys = append(keys, r.PublicKey)
+	}
+	return keys
+}
+
 // SecretPut stores a secret in the vault.
 // If preReadValue is non-empty, it's used as the secret value (for piped input read before vault lock).
 // If preReadValue is empty, the secret is read from stdin (interactive TTY mode).
-func (c *CLI) SecretPut(secretKeyArg, vaultPath string, fromIndex int, preReadValue string) *Error {
+func (c *CLI) SecretPut(secretKeyArg, vaultPath string, fromIndex int, preReadValue string, ifAbsent bool) *Error {
+	if preReadValue != "" {
+		if sizeErr := c.checkSecretSize(int64(len(preReadValue))); sizeErr != nil {
+			return sizeErr
+		}
+	}
+
+	target, prepErr := c.prepareSecretPut(secretKeyArg, vaultPath, fromIndex, ifAbsent)
+	if prepErr != nil || target == nil {
+		return prepErr
+	}
+	if proceed, confirmErr := c.confirmReplace(target); !proceed {
+		return confirmErr
+	}
+
+	var secretValue string
+	if preReadValue != "" {
+		// Use pre-read value (from piped stdin read before vault lock acquisition)
+		secretValue = preReadValue
+	} else {
+		// Read from stdin (TTY interactive mode)
+		readHidden := c.terminalReader()
+		if readHidden != nil {
+			_, _ = fmt.Fprintf(c.output.Stderr(), "Enter secret value (input will be redacted): ")
+		}
+		var readErr error
+		secretValue, readErr = c.readSecretFromStdin()
+		if readErr != nil {
+			return NewError(fmt.Sprintf("failed to read secret: %v", readErr), ExitGeneralError)
+		}
+		if sizeErr := c.checkSecretSize(int64(len(secretValue))); sizeErr != nil {
+			return sizeErr
+		}
+		if readHidden != nil && c.shouldConfirmValue(target) {
+			_, _ = fmt.Fprintf(c.output.Stderr(), "Confirm secret value: ")
+			again, confirmErr := readHidden()
+			if confirmErr != nil {
+				return NewError(fmt.Sprintf("failed to read secret: %v", confirmErr), ExitGeneralError)
+			}
+			if again != secretValue {
+				return NewError("values do not match; secret unchanged", ExitValidationError)
+			}
+		}
+	}
+
+	return c.encryptAndStoreValue(target, secretValue)
+}
+
+// SecretPutValue stores value exactly as given, without falling back to
+// stdin when it is empty. Used for values taken from other sources, such as
+// an environment variable.
+func (c *CLI) SecretPutValue(secretKeyArg, vaultPath string, fromIndex int, value string, ifAbsent bool) *Error {
+	if sizeErr := c.checkSecretSize(int64(len(value))); sizeErr != nil {
+		return sizeErr
+	}
+
+	target, prepErr := c.prepareSecretPut(secretKeyArg, vaultPath, fromIndex, ifAbsent)
+	if prepErr != nil || target == nil {
+		return prepErr
+	}
+	if proceed, confirmErr := c.confirmReplace(target); !proceed {
+		return confirmErr
+	}
+	return c.encryptAndStoreValue(target, value)
+}
+
+// checkSecretSize refuses a plaintext of size bytes that exceeds
+// max_secret_size, unless --allow-large was given.
+func (c *CLI) checkSecretSize(size int64) *Error {
+	if c.allowLarge {
+		return nil
+	}
+	maxSize, err := c.config.GetMaxSecretSize()
+	if err != nil {
+		return NewError(err.Error(), ExitConfigError)
+	}
+	if size > maxSize {
+		return NewError(fmt.Sprintf("secret value is %d bytes, larger than max_secret_size (%d bytes); use --allow-large to store it anyway", size, maxSize), ExitValidationError)
+	}
+	return nil
+}
+
+// LookupSecretEnv returns the value of the environment variable name for use
+// as a secret value. An unset variable is always an error; an empty one is an
+// error unless allowEmpty is set. The value never appears in error messages.
+// Once read, the variable is unset so that processes started later, such as
+// gpg and the post_write hook, never inherit the secret.
+func LookupSecretEnv(name string, allowEmpty bool) (string, *Error) {
+	if name == "" {
+		return "", NewError("environment variable name must not be empty", ExitGeneralError)
+	}
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", NewError(fmt.Sprintf("environment variable %s is not set", name), ExitGeneralError)
+	}
+	if value == "" && !allowEmpty {
+		return "", NewError(fmt.Sprintf("environment variable %s is empty (use --allow-empty to store an empty value)", name), ExitGeneralError)
+	}
+	_ = os.Unsetenv(name)
+	return value, nil
+}
+
+// encryptAndStoreValue encrypts secretValue for the signer and stores it as
+// the new value of the target secret.
+func (c *CLI) encryptAndStoreValue(target *secretPutTarget, secretValue string) *Error {
+	encryptedBase64, encErr := c.encryptForTarget(target, secretValue)
+	if encErr != nil {
+		return encErr
+	}
+	if storeErr := c.storeEncryptedValue(target, encryptedBase64); storeErr != nil {
+		return storeErr
+	}
+
+	_, _ = fmt.Fprintf(c.output.Stdout(), "Secret '%s' stored successfully\n", target.key)
+	if c.replace {
+		return c.trimReplacedHistory(target)
+	}
+	return nil
+}
+
+// confirmReplace asks before 'secret put --replace' supersedes an existing
+// secret. Confirmation is only requested interactively; it reports whether
+// to go on, and declining is not an error.
+func (c *CLI) confirmReplace(target *secretPutTarget) (bool, *Error) {
+	if !c.replace {
+		return true, nil
+	}
+	maxHistory, err := c.config.GetMaxHistory()
+	if err != nil {
+		return false, NewError(err.Error(), ExitConfigError)
+	}
+
+	existing := c.vaultResolver.GetSecretByKeyFromVault(target.index, target.key)
+	if existing == nil || len(existing.Values) == 0 {
+		return true, nil
+	}
+	hasTTY := c.hasTTY
+	if hasTTY == nil {
+		hasTTY = defaultHasTTY
+	}
+	if !hasTTY() || isCI() {
+		return true, nil
+	}
+
+	readers := "you only"
+	if len(target.recipients) > 0 {
+		readers = fmt.Sprintf("you and %d default recipient(s)", len(target.recipients))
+	}
+	prompt := fmt.Sprintf("Replace secret '%s'? The new value will be readable by %s.", target.key, readers)
+	if maxHistory > 0 && len(existing.Values) >= maxHistory {
+		prompt = fmt.Sprintf("Replace secret '%s'? The new value will be readable by %s, and all but the newest %d value(s) will be permanently removed.", target.key, readers, maxHistory)
+	}
+	confirmed, confirmErr := PromptConfirm(prompt, c.output.Stderr())
+	if confirmErr != nil {
+		return false, confirmErr
+	}
+	if !confirmed {
+		_, _ = fmt.Fprintf(c.output.Stdout(), "Aborted; secret unchanged.\n")
+		return false, nil
+	}
+	return true, nil
+}
+
+// trimReplacedHistory drops the values of a replaced secret beyond
+// max_history. The new value has already been stored.
+func (c *CLI) trimReplacedHistory(target *secretPutTarget) *Error {
+	maxHistory, err := c.config.GetMaxHistory()
+	if err != nil {
+		return NewError(err.Error(), ExitConfigError)
+	}
+	if maxHistory == 0 {
+		return nil
+	}
+
+	dropped, trimErr := c.vaultResolver.TrimSecretHistory(target.index, target.key, maxHistory)
+	if trimErr != nil {
+		return NewError(fmt.Sprintf("value stored, but failed to trim history of '%s': %v", target.key, trimErr), ExitVaultError)
+	}
+	if dropped > 0 {
+		_, _ = fmt.Fprintf(c.output.Stdout(), "Dropped %d older value(s) of '%s' (max_history: %d)\n", dropped, target.key, maxHistory)
+	}
+	return nil
+}
+
+// encryptForTarget encrypts secretValue for the signer and default
+// recipients of target, compressed
+// first with SetCompress, and returns the base64-encoded ciphertext stored
+// in the vault.
+func (c *CLI) encryptForTarget(target *secretPutTarget, secretValue string) (string, *Error) {
+	payload, compressErr := c.compressForTarget(secretValue)
+	if compressErr != nil {
+		return "", compressErr
+	}
+	encryptedArmored, encErr := c.gpgClient.EncryptToRecipients(payload, target.publicKeys(), nil)
+	if encErr != nil {
+		return "", NewError(fmt.Sprintf("failed to encrypt secret: %v", encErr), ExitGeneralError)
+	}
+	return base64.StdEncoding.EncodeToString([]byte(encryptedArmored)), nil
+}
+
+// compressForTarget gzips secretValue before encryption when values are
+// stored compressed.
+func (c *CLI) compressForTarget(secretValue string) ([]byte, *Error) {
+	if !c.compress {
+		return []byte(secretValue), nil
+	}
+	payload, err := vault.CompressValue([]byte(secretValue))
+	if err != nil {
+		return nil, NewError(err.Error(), ExitGeneralError)
+	}
+	return payload, nil
+}
+
+// detachedValueError returns the integrity error of a detached value of
+// secretKey whose file could not be read or was modified, or nil. Such a
+// value has no encrypted payload to decrypt.
+func detachedValueError(secretKey string, value *vault.SecretValue) *Error {
+	if err := value.DetachedErr(); err != nil {
+		return NewError(fmt.Sprintf("secret '%s': %v", secretKey, err), ExitValidationError)
+	}
+	return nil
+}
+
+// inflateValue returns the plaintext of value from its decrypted payload,
+// which is gunzipped when value was stored compressed. A value inflating past
+// max_secret_size is refused rather than expanded in memory.
+func (c *CLI) inflateValue(value *vault.SecretValue, payload []byte) ([]byte, *Error) {
+	maxSize, sizeErr := c.config.GetMaxSecretSize()
+	if sizeErr != nil {
+		maxSize = config.DefaultMaxSecretSize
+	}
+	plaintext, err := vault.DecompressValue(value, payload, maxSize)
+	if errors.Is(err, vault.ErrDecompressedTooLarge) {
+		return nil, NewError(fmt.Sprintf("secret decompresses to more than max_secret_size (%d bytes); raise max_secret_size to read it", maxSize), ExitValidationError)
+	}
+	if err != nil {
+		return nil, NewError(fmt.Sprintf("failed to decompress secret: %v", err), ExitGeneralError)
+	}
+	return plaintext, nil
+}
+
+// prepareSecretPut validates a store request and resolves the vault, signer
+// and identity it targets. With ifAbsent, it returns a nil target (and no
+// error) when the secret already exists, after reporting that it was left
+// unchanged.
+func (c *CLI) prepareSecretPut(secretKeyArg, vaultPath string, fromIndex int, ifAbsent bool) (*secretPutTarget, *Error) {
 	secretKey, normErr := vault.NormalizeSecretKey(secretKeyArg)
 	if normErr != nil {
-		return NewError(vault.FormatSecretKeyError(normErr), ExitValidationError)
+		return nil, NewError(vault.FormatSecretKeyError(normErr), ExitValidationError)
 	}
 
 	fp, err := c.checkFingerprintRequired("secret store")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	targetIndex, resolveErr := c.resolveWritableVaultIndex(vaultPath, fromIndex)
 	if resolveErr != nil {
-		return resolveErr
+		return nil, resolveErr
 	}
 
 	// The vault must already exist before storing a secret. 'identity add' may
@@ -78,16 +368,26 @@ func (c *CLI) SecretPut(secretKeyArg, vaultPath string, fromIndex int, preReadVa
 		if requested <= 0 {
 			requested = targetIndex + 1
 		}
-		return NewError(fmt.Sprintf("vault %d does not exist; run 'dotsecenv init vault -v %d' first", requested, requested), ExitVaultError)
+		return nil, NewError(fmt.Sprintf("vault %d does not exist; run 'dotsecenv init vault -v %d' first", requested, requested), ExitVaultError)
+	}
+
+	// With --if-absent, leave an existing secret untouched. Deleted secrets
+	// count as absent and fall through to the deletion check below.
+	if ifAbsent {
+		existing := c.vaultResolver.GetSecretByKeyFromVault(targetIndex, secretKey)
+		if existing != nil && len(existing.Values) > 0 && !existing.IsDeleted() {
+			_, _ = fmt.Fprintf(c.output.Stdout(), "Secret '%s' already exists; not modified\n", secretKey)
+			return nil, nil
+		}
 	}
 
 	if ensureErr := c.ensureIdentityInVault(fp, targetIndex); ensureErr != nil {
-		return ensureErr
+		return nil, ensureErr
 	}
 
 	identity := c.vaultResolver.GetIdentityByFingerprint(fp)
 	if identity == nil {
-		return NewError(fmt.Sprintf("identity not found in vault: %s", fp), ExitAccessDenied)
+		return nil, NewError(fmt.Sprintf("identity not found in vault: %s", fp), ExitAccessDenied)
 	}
 
 	// Check if secret exists and if we have access to the latest value
@@ -95,46 +395,55 @@ func (c *CLI) SecretPut(secretKeyArg, vaultPath string, fromIndex int, preReadVa
 	if existingSecret != nil && len(existingSecret.Values) > 0 {
 		// Check if secret has been deleted
 		if existingSecret.IsDeleted() {
-			return NewError(fmt.Sprintf("secret '%s' has been deleted; cannot overwrite a deleted secret", secretKey), ExitVaultError)
+			return nil, NewError(fmt.Sprintf("secret '%s' has been deleted; cannot overwrite a deleted secret", secretKey), ExitVaultError)
 		}
 		latestValue := existingSecret.Values[len(existingSecret.Values)-1]
-		if !slices.Contains(latestValue.AvailableTo, fp) {
-			return NewError(fmt.Sprintf("access denied: you do not have access to the latest value of secret '%s'", secretKey), ExitAccessDenied)
+		if !latestValue.CanBeReadBy(fp) {
+			return nil, NewError(fmt.Sprintf("access denied: you do not have access to the latest value of secret '%s'", secretKey), ExitAccessDenied)
 		}
 	}
 
-	var secretValue string
-	if preReadValue != "" {
-		// Use pre-read value (from piped stdin read before vault lock acquisition)
-		secretValue = preReadValue
-	} else {
-		// Read from stdin (TTY interactive mode)
-		isTTY := false
-		if f, ok := c.stdin.(*os.File); ok {
-			isTTY = term.IsTerminal(int(f.Fd()))
-		}
+	recipients, rcptErr := c.defaultRecipients(targetIndex, fp)
+	if rcptErr != nil {
+		return nil, rcptErr
+	}
 
-		if isTTY {
-			_, _ = fmt.Fprintf(c.output.Stderr(), "Enter secret value (input will be redacted): ")
-		}
-		var readErr error
-		secretValue, readErr = c.readSecretFromStdin()
-		if readErr != nil {
-			return NewError(fmt.Sprintf("failed to read secret: %v", readErr), ExitGeneralError)
-		}
+	// A stored value is readable by the signer and the default recipients
+	if minErr := c.checkMinRecipients(secretKey, "store secret", 1+len(recipients)); minErr != nil {
+		return nil, minErr
 	}
 
-	encryptedArmored, encErr := c.gpgClient.EncryptToRecipients(
-		[]byte(secretValue),
-		[]string{identity.PublicKey},
-		nil,
-	)
-	if encErr != nil {
-		return NewError(fmt.Sprintf("failed to encrypt secret: %v", encErr), ExitGeneralError)
+	return &secretPutTarget{key: secretKey, fp: fp, index: targetIndex, identity: identity, recipients: recipients}, nil
+}
+
+// storeEncryptedValue signs encryptedBase64 as the new value of the target
+// secret and persists it.
+func (c *CLI) storeEncryptedValue(target *secretPutTarget, encryptedBase64 string) *Error {
+	newSecret, signErr := c.signNewSecretValue(target, encryptedBase64)
+	if signErr != nil {
+		return signErr
+	}
+
+	if err := c.vaultResolver.AddSecret(newSecret, target.index); err != nil {
+		return NewError(fmt.Sprintf("failed to add secret: %v", err), ExitVaultError)
 	}
 
-	encryptedBase64 := base64.StdEncoding.EncodeToString([]byte(encryptedArmored))
+	if saveErr := c.vaultResolver.SaveVault(target.index); saveErr != nil {
+		return NewError(fmt.Sprintf("failed to save vault: %v", saveErr), ExitVaultError)
+	}
+	if hookErr := c.runPostWriteHook(target.index); hookErr != nil {
+		return hookErr
+	}
+
+	return nil
+}
+
+// signNewSecretValue builds the signed secret record that adds
+// encryptedBase64 as the new value of the target secret, readable by the
+// signer and its default recipients. Nothing is written to the vault.
+func (c *CLI) signNewSecretValue(target *secretPutTarget, encryptedBase64 string) (vault.Secret, *Error) {
 	now := time.Now().UTC()
+	secretKey, fp, identity := target.key, target.fp, target.identity
 
 	// Build secret struct first (without hash/signature)
 	newSecret := vault.Secret{
@@ -148,7 +457,7 @@ func (c *CLI) SecretPut(secretKeyArg, vaultPath string, fromIndex int, preReadVa
 	secretHash := vault.ComputeSecretHash(&newSecret, identity.AlgorithmBits)
 	secretSig, sigErr := c.gpgClient.SignDataWithAgent(fp, []byte(secretHash))
 	if sigErr != nil {
-		return NewError(fmt.Sprintf("failed to sign secret: %v", sigErr), ExitGeneralError)
+		return vault.Secret{}, NewError(fmt.Sprintf("failed to sign secret: %v", sigErr), ExitGeneralError)
 	}
 	newSecret.Hash = secretHash
 	newSecret.Signature = secretSig
@@ -156,33 +465,28 @@ func (c *CLI) SecretPut(secretKeyArg, vaultPath string, fromIndex int, preReadVa
 	// Build secret value struct (without hash/signature)
 	newValue := vault.SecretValue{
 		AddedAt:     now,
-		AvailableTo: []string{fp},
+		AvailableTo: target.availableTo(),
 		SignedBy:    fp,
 		Value:       encryptedBase64,
 		Deleted:     false,
 	}
+	if c.detach {
+		newValue.Detach()
+	}
+	newValue.Compressed = c.compress
+	newValue.Source = c.source
 
 	// Compute value hash using shared function
 	valueHash := vault.ComputeSecretValueHash(&newValue, secretKey, identity.AlgorithmBits)
 	valueSig, valueSigErr := c.gpgClient.SignDataWithAgent(fp, []byte(valueHash))
 	if valueSigErr != nil {
-		return NewError(fmt.Sprintf("failed to sign secret value: %v", valueSigErr), ExitGeneralError)
+		return vault.Secret{}, NewError(fmt.Sprintf("failed to sign secret value: %v", valueSigErr), ExitGeneralError)
 	}
 	newValue.Hash = valueHash
 	newValue.Signature = valueSig
 
 	newSecret.Values = []vault.SecretValue{newValue}
-
-	if err := c.vaultResolver.AddSecret(newSecret, targetIndex); err != nil {
-		return NewError(fmt.Sprintf("failed to add secret: %v", err), ExitVaultError)
-	}
-
-	if saveErr := c.vaultResolver.SaveVault(targetIndex); saveErr != nil {
-		return NewError(fmt.Sprintf("failed to save vault: %v", saveErr), ExitVaultError)
-	}
-
-	_, _ = fmt.Fprintf(c.output.Stdout(), "Secret '%s' stored successfully\n", secretKey)
-	return nil
+	return newSecret, nil
 }
 
 // SecretForget marks a secret as deleted by adding a deletion marker value.
@@ -204,37 +508,157 @@ func (c *CLI) SecretForget(secretKeyArg, vaultPath string, fromIndex int, ignore
 	}
 
 	existingSecret := c.vaultResolver.GetSecretByKeyFromVault(targetIndex, secretKey)
-	if existingSecret == nil {
-		if ignoreNotFound {
+	if checkErr := checkForgettable(existingSecret, secretKey, fp); checkErr != nil {
+		if ignoreNotFound && checkErr.ExitCode != ExitAccessDenied {
 			return nil
 		}
-		return NewError(fmt.Sprintf("secret '%s' not found in vault", secretKey), ExitVaultError)
+		return checkErr
+	}
+
+	deletionSecret, markErr := c.newDeletionMarker(existingSecret, secretKey, fp)
+	if markErr != nil {
+		return markErr
+	}
+	if err := c.vaultResolver.AddSecret(deletionSecret, targetIndex); err != nil {
+		return NewError(fmt.Sprintf("failed to add deletion marker: %v", err), ExitVaultError)
+	}
+
+	if saveErr := c.vaultResolver.SaveVault(targetIndex); saveErr != nil {
+		return NewError(fmt.Sprintf("failed to save vault: %v", saveErr), ExitVaultError)
+	}
+	if hookErr := c.runPostWriteHook(targetIndex); hookErr != nil {
+		return hookErr
+	}
+
+	_, _ = fmt.Fprintf(c.output.Stdout(), "Secret '%s' marked as deleted\n", secretKey)
+	return nil
+}
+
+// SecretForgetPrefix marks every secret in the target vault whose key starts
+// with prefix as deleted. All markers are signed before any is written. Secrets already deleted, or
+// whose latest value the caller can't read, are skipped and reported.
+// Without yes it lists the matches and asks for confirmation (skipped in CI).
+func (c *CLI) SecretForgetPrefix(prefix, vaultPath string, fromIndex int, yes bool) *Error {
+	if prefix == "" {
+		return NewError("--prefix must not be empty", ExitValidationError)
+	}
+
+	fp, err := c.checkFingerprintRequired("secret forget")
+	if err != nil {
+		return err
+	}
+
+	targetIndex, resolveErr := c.resolveWritableVaultIndex(vaultPath, fromIndex)
+	if resolveErr != nil {
+		return resolveErr
+	}
+
+	var matches []*vault.Secret
+	for _, info := range c.vaultResolver.ListSecretKeysFromVault(targetIndex) {
+		if !strings.HasPrefix(info.Key, prefix) {
+			continue
+		}
+		if secret := c.vaultResolver.GetSecretByKeyFromVault(targetIndex, info.Key); secret != nil {
+			matches = append(matches, secret)
+		}
+	}
+	if len(matches) == 0 {
+		return NewError(fmt.Sprintf("no secrets match prefix '%s'", prefix), ExitVaultError)
+	}
+	slices.SortFunc(matches, func(a, b *vault.Secret) int { return strings.Compare(a.Key, b.Key) })
+
+	var forgettable []*vault.Secret
+	var skipped int
+	for _, secret := range matches {
+		if checkErr := checkForgettable(secret, secret.Key, fp); checkErr != nil {
+			_, _ = fmt.Fprintf(c.output.Stderr(), "skipped: %s: %s\n", secret.Key, checkErr.Message)
+			skipped++
+			continue
+		}
+		forgettable = append(forgettable, secret)
 	}
 
-	if len(existingSecret.Values) == 0 {
-		if ignoreNotFound {
+	if len(forgettable) > 0 && !yes && !isCI() {
+		keys := make([]string, len(forgettable))
+		for i, secret := range forgettable {
+			keys[i] = secret.Key
+		}
+		confirmed, confirmErr := PromptConfirm(
+			fmt.Sprintf("Mark %d secret(s) as deleted: %s?", len(keys), strings.Join(keys, ", ")),
+			c.output.Stderr())
+		if confirmErr != nil {
+			return confirmErr
+		}
+		if !confirmed {
+			_, _ = fmt.Fprintf(c.output.Stdout(), "Aborted; vault unchanged.\n")
 			return nil
 		}
+	}
+
+	// Sign every marker before writing any, so a signing failure leaves
+	// the vault unchanged.
+	markers := make([]vault.Secret, len(forgettable))
+	for i, secret := range forgettable {
+		marker, markErr := c.newDeletionMarker(secret, secret.Key, fp)
+		if markErr != nil {
+			return NewError(fmt.Sprintf("%s: %s; vault unchanged", secret.Key, markErr.Message), markErr.ExitCode)
+		}
+		markers[i] = marker
+	}
+	c.vaultResolver.DeferWrites()
+	for _, marker := range markers {
+		if err := c.vaultResolver.AddSecret(marker, targetIndex); err != nil {
+			return NewError(fmt.Sprintf("failed to add deletion marker for '%s': %v", marker.Key, err), ExitVaultError)
+		}
+	}
+
+	if len(markers) > 0 {
+		if saveErr := c.vaultResolver.SaveVault(targetIndex); saveErr != nil {
+			return NewError(fmt.Sprintf("failed to save vault: %v", saveErr), ExitVaultError)
+		}
+		if hookErr := c.runPostWriteHook(targetIndex); hookErr != nil {
+			return hookErr
+		}
+	}
+
+	for _, secret := range forgettable {
+		_, _ = fmt.Fprintf(c.output.Stdout(), "deleted: %s\n", secret.Key)
+	}
+	_, _ = fmt.Fprintf(c.output.Stdout(), "summary: deleted=%d skipped=%d\n", len(forgettable), skipped)
+	return nil
+}
+
+// checkForgettable reports why secret can't be marked as deleted by fp: it
+// is missing, has no values, is already deleted, or fp can't read its latest
+// value (ExitAccessDenied).
+func checkForgettable(secret *vault.Secret, secretKey, fp string) *Error {
+	if secret == nil {
+		return NewError(fmt.Sprintf("secret '%s' not found in vault", secretKey), ExitVaultError)
+	}
+
+	if len(secret.Values) == 0 {
 		return NewError(fmt.Sprintf("secret '%s' has no values", secretKey), ExitVaultError)
 	}
 
 	// Check if already deleted
-	latestValue := existingSecret.Values[len(existingSecret.Values)-1]
+	latestValue := secret.Values[len(secret.Values)-1]
 	if latestValue.Deleted {
-		if ignoreNotFound {
-			return nil
-		}
 		return NewError(fmt.Sprintf("secret '%s' is already deleted", secretKey), ExitGeneralError)
 	}
 
 	// Check if user has access to the latest value
-	if !slices.Contains(latestValue.AvailableTo, fp) {
+	if !latestValue.CanBeReadBy(fp) {
 		return NewError(fmt.Sprintf("access denied: you do not have access to the latest value of secret '%s'", secretKey), ExitAccessDenied)
 	}
+	return nil
+}
 
+// newDeletionMarker returns a secret holding just a deletion marker for
+// existingSecret, signed by fp.
+func (c *CLI) newDeletionMarker(existingSecret *vault.Secret, secretKey, fp string) (vault.Secret, *Error) {
 	identity := c.vaultResolver.GetIdentityByFingerprint(fp)
 	if identity == nil {
-		return NewError(fmt.Sprintf("identity not found in vault: %s", fp), ExitAccessDenied)
+		return vault.Secret{}, NewError(fmt.Sprintf("identity not found in vault: %s", fp), ExitAccessDenied)
 	}
 
 	now := time.Now().UTC()
@@ -252,41 +676,73 @@ func (c *CLI) SecretForget(secretKeyArg, vaultPath string, fromIndex int, ignore
 	valueHash := vault.ComputeSecretValueHash(&deletionValue, secretKey, identity.AlgorithmBits)
 	valueSig, valueSigErr := c.gpgClient.SignDataWithAgent(fp, []byte(valueHash))
 	if valueSigErr != nil {
-		return NewError(fmt.Sprintf("failed to sign deletion marker: %v", valueSigErr), ExitGeneralError)
+		return vault.Secret{}, NewError(fmt.Sprintf("failed to sign deletion marker: %v", valueSigErr), ExitGeneralError)
 	}
 	deletionValue.Hash = valueHash
 	deletionValue.Signature = valueSig
 
 	// Create a secret with just the deletion value to add
-	deletionSecret := vault.Secret{
+	return vault.Secret{
 		AddedAt:   existingSecret.AddedAt,
 		Hash:      existingSecret.Hash,
 		Key:       secretKey,
 		Signature: existingSecret.Signature,
 		SignedBy:  existingSecret.SignedBy,
 		Values:    []vault.SecretValue{deletionValue},
-	}
+	}, nil
+}
 
-	if err := c.vaultResolver.AddSecret(deletionSecret, targetIndex); err != nil {
-		return NewError(fmt.Sprintf("failed to add deletion marker: %v", err), ExitVaultError)
+// resolveReadableVaultIndex resolves the vault a read targets from -v. It
+// returns -1 when no vault was specified, meaning all vaults are searched.
+func (c *CLI) resolveReadableVaultIndex(vaultPath string, fromIndex int) (int, *Error) {
+	if vaultPath != "" {
+		expandedPath := vault.ExpandPath(vaultPath)
+		// Check if in config
+		loadedPaths := c.vaultResolver.GetVaultPaths()
+		for i, p := range loadedPaths {
+			if vault.ExpandPath(p) == expandedPath {
+				return i, nil
+			}
+		}
+		// Check if file exists for better error message
+		if _, err := os.Stat(expandedPath); err != nil {
+			return -1, c.vaultPathError(expandedPath, fmt.Sprintf("vault file does not exist: %s", expandedPath), ExitVaultError)
+		}
+		return -1, c.vaultPathError(expandedPath, fmt.Sprintf("vault %s is not one of the loaded vaults", expandedPath), ExitVaultError)
 	}
 
-	if saveErr := c.vaultResolver.SaveVault(targetIndex); saveErr != nil {
-		return NewError(fmt.Sprintf("failed to save vault: %v", saveErr), ExitVaultError)
+	if fromIndex != 0 {
+		configEntries := c.vaultResolver.GetConfig().Entries
+		if fromIndex <= 0 || fromIndex > len(configEntries) {
+			return -1, NewError(fmt.Sprintf("-v index must be a positive integer between 1 and %d", len(configEntries)), ExitGeneralError)
+		}
+		return fromIndex - 1, nil
 	}
 
-	_, _ = fmt.Fprintf(c.output.Stdout(), "Secret '%s' marked as deleted\n", secretKey)
-	return nil
+	return -1, nil
 }
 
 // SecretGet retrieves a secret from the vault.
 // If the user cannot access the latest value, falls back to older accessible values with a warning.
+// When SetExplain is set and access is denied, a diagnostic follows on stderr.
 func (c *CLI) SecretGet(secretKey string, all bool, last bool, jsonOutput bool, vaultPath string, fromIndex int) *Error {
+	err := c.secretGet(secretKey, all, last, jsonOutput, vaultPath, fromIndex)
+	if err != nil && err.ExitCode == ExitAccessDenied && c.explain {
+		c.explainAccess(secretKey)
+	}
+	return err
+}
+
+func (c *CLI) secretGet(secretKey string, all bool, last bool, jsonOutput bool, vaultPath string, fromIndex int) *Error {
 	// Validate secret key format
 	if _, err := vault.NormalizeSecretKey(secretKey); err != nil {
 		return NewError(vault.FormatSecretKeyError(err), ExitValidationError)
 	}
 
+	// Printing values is the purpose of secret get, so it is exempt from
+	// the --redact-stdout guard.
+	c.output.AllowSecretValues()
+
 	fp, err := c.checkFingerprintRequired("secret get")
 	if err != nil {
 		return err
@@ -318,33 +774,9 @@ func (c *CLI) SecretGet(secretKey string, all bool, last bool, jsonOutput bool,
 		return NewError(fmt.Sprintf("--last and -v cannot be used together; omit -v to search all vaults or remove --last to use %s", targetDesc), ExitGeneralError)
 	}
 
-	targetIndex := -1
-
-	if vaultPath != "" {
-		expandedPath := vault.ExpandPath(vaultPath)
-		// Check if in config
-		loadedPaths := c.vaultResolver.GetVaultPaths()
-		found := false
-		for i, p := range loadedPaths {
-			if vault.ExpandPath(p) == expandedPath {
-				targetIndex = i
-				found = true
-				break
-			}
-		}
-		if !found {
-			// Check if file exists for better error message
-			if _, err := os.Stat(expandedPath); err != nil {
-				return NewError(fmt.Sprintf("vault file does not exist: %s", expandedPath), ExitVaultError)
-			}
-			return NewError(fmt.Sprintf("vault path '%s' not found in resolver", expandedPath), ExitVaultError)
-		}
-	} else if fromIndex != 0 {
-		configEntries := c.vaultResolver.GetConfig().Entries
-		if fromIndex <= 0 || fromIndex > len(configEntries) {
-			return NewError(fmt.Sprintf("-v index must be a positive integer between 1 and %d", len(configEntries)), ExitGeneralError)
-		}
-		targetIndex = fromIndex - 1
+	targetIndex, resolveErr := c.resolveReadableVaultIndex(vaultPath, fromIndex)
+	if resolveErr != nil {
+		return resolveErr
 	}
 
 	// Get secret based on mode
@@ -369,8 +801,10 @@ func (c *CLI) SecretGet(secretKey string, all bool, last bool, jsonOutput bool,
 			VaultPath string
 		}
 
-		// Search all vaults
-		for i, entry := range c.vaultResolver.GetConfig().Entries {
+		// Search all vaults, or the first --depth of them
+		config := c.vaultResolver.GetConfig()
+		for _, i := range config.SearchIndices() {
+			entry := config.Entries[i]
 			secretObj := c.vaultResolver.GetSecretByKeyFromVault(i, secretKey)
 			if secretObj != nil {
 				// Skip vaults where the secret is deleted
@@ -396,40 +830,30 @@ func (c *CLI) SecretGet(secretKey string, all bool, last bool, jsonOutput bool,
 		})
 
 		// Decrypt all values
-		for _, item := range allValues {
-			val := item.Value
-
-			encryptedArmored, decodeErr := base64.StdEncoding.DecodeString(val.Value)
-			if decodeErr != nil {
-				// Always warn and try other values
-				c.Warnf("failed to decode value from %s: %v", val.AddedAt, decodeErr)
-				continue
-			}
-
-			// Let GPG agent determine decryptability — the user may have a key
-			// in the agent that isn't the logged-in identity.
-			plaintext, decErr := c.gpgClient.DecryptWithAgent(encryptedArmored, fp)
-			if decErr != nil {
-				// Always warn and try other values
-				c.Warnf("failed to decrypt value from %s: %v", val.AddedAt, decErr)
-				continue
-			}
-			valStr := string(plaintext)
-			decryptedValues = append(decryptedValues, valStr)
-			decryptedValuesWithTime = append(decryptedValuesWithTime, SecretValueJSON{
-				AddedAt:     val.AddedAt,
-				Value:       smartJSONValue(valStr),
-				Vault:       item.VaultPath,
-				AvailableTo: val.AvailableTo,
-				SignedBy:    val.SignedBy,
-			})
+		jobs := make([]decryptJob, len(allValues))
+		for i, item := range allValues {
+			jobs[i] = decryptJob{value: item.Value, vaultPath: item.VaultPath}
+		}
+		var batchErr *Error
+		decryptedValuesWithTime, batchErr = c.decryptBatch(jobs, fp)
+		if batchErr != nil {
+			return batchErr
+		}
+		if c.dedupe {
+			decryptedValuesWithTime = dedupeValues(decryptedValuesWithTime)
+		}
+		if c.oldestFirst {
+			slices.Reverse(decryptedValuesWithTime)
 		}
 	} else {
 		// Default mode: search all vaults in order, return from first vault that has it
 		// First check if the secret exists but is deleted
-		for i := range c.vaultResolver.GetConfig().Entries {
+		for _, i := range c.vaultResolver.GetConfig().SearchIndices() {
 			secretObj := c.vaultResolver.GetSecretByKeyFromVault(i, secretKey)
 			if secretObj != nil && secretObj.IsDeleted() {
+				if c.inclDeleted {
+					return c.getDeletedSecret(secretObj, i, jsonOutput, fp)
+				}
 				_, _ = fmt.Fprintf(c.output.Stderr(), "error: secret '%s' has been deleted\n", secretKey)
 				return NewError(fmt.Sprintf("secret '%s' has been deleted", secretKey), ExitVaultError)
 			}
@@ -438,15 +862,19 @@ func (c *CLI) SecretGet(secretKey string, all bool, last bool, jsonOutput bool,
 		// Try fingerprint-matched access first (fast path), then fall back to
 		// any-vault lookup and let GPG agent determine decryptability.
 		// The user may have a different key in the agent than the logged-in identity.
+		// With --require-latest the fast path is skipped, since it may settle
+		// on an older value shared with fp.
 		var errGet error
 		notGranted := false
-		secret, errGet = c.vaultResolver.GetAccessibleSecretFromAnyVault(secretKey, fp)
-		if errGet != nil {
-			notGranted = true
+		if !c.requireLatest {
+			secret, errGet = c.vaultResolver.GetAccessibleSecretFromAnyVault(secretKey, fp)
+		}
+		if c.requireLatest || errGet != nil {
 			secret, errGet = c.vaultResolver.GetSecretFromAnyVault(secretKey, c.output.Stderr())
 			if errGet != nil {
 				return NewError(fmt.Sprintf("secret '%s' not found in any vault", secretKey), ExitVaultError)
 			}
+			notGranted = !secret.CanBeReadBy(fp)
 		}
 
 		// Find the vault path for this secret
@@ -458,6 +886,9 @@ func (c *CLI) SecretGet(secretKey string, all bool, last bool, jsonOutput bool,
 			}
 		}
 
+		if detErr := detachedValueError(secretKey, secret); detErr != nil {
+			return detErr
+		}
 		encryptedArmored, decodeErr := base64.StdEncoding.DecodeString(secret.Value)
 		if decodeErr != nil {
 			return NewError(fmt.Sprintf("failed to decode encrypted value: %v", decodeErr), ExitGeneralError)
@@ -465,51 +896,94 @@ func (c *CLI) SecretGet(secretKey string, all bool, last bool, jsonOutput bool,
 
 		plaintext, decErr := c.gpgClient.DecryptWithAgent(encryptedArmored, fp)
 		if decErr != nil {
+			if notGranted && c.requireLatest {
+				return NewError(notLatestMessage(secretKey, fp), ExitAccessDenied)
+			}
 			if notGranted {
 				return NewError(accessDeniedMessage(secretKey, fp), ExitAccessDenied)
 			}
 			return NewError(fmt.Sprintf("failed to decrypt secret: %v", decErr), ExitGPGError)
 		}
-		decryptedValues = append(decryptedValues, string(plaintext))
+		plaintext, inflateErr := c.inflateValue(secret, plaintext)
+		if inflateErr != nil {
+			return inflateErr
+		}
+		decryptedValues = append(decryptedValues, c.encodeValue(plaintext))
 	}
 
 	if jsonOutput {
-		encoder := json.NewEncoder(c.output.Stdout())
-		encoder.SetIndent("", "  ")
-
 		if all {
-			if err := encoder.Encode(decryptedValuesWithTime); err != nil {
-				return NewError(fmt.Sprintf("failed to encode json: %v", err), ExitGeneralError)
-			}
-		} else {
-			output := SecretValueJSON{
-				AddedAt: secret.AddedAt,
-				Value:   smartJSONValue(decryptedValues[0]),
-				Vault:   secretVaultPath,
-			}
-			if err := encoder.Encode(output); err != nil {
-				return NewError(fmt.Sprintf("failed to encode json: %v", err), ExitGeneralError)
-			}
+			return c.writeSecretJSON(decryptedValuesWithTime)
 		}
-	} else {
-		if all {
-			for _, item := range decryptedValuesWithTime {
-				_, _ = fmt.Fprintf(c.output.Stdout(), "%s (%s): %s\n", item.AddedAt.Format(time.RFC3339), item.Vault, item.Value)
-			}
-		} else {
-			if len(decryptedValues) > 0 {
-				// Trim trailing newline to avoid double newline (echo adds one, we add one)
-				value := strings.TrimSuffix(decryptedValues[0], "\n")
-				_, _ = fmt.Fprintf(c.output.Stdout(), "%s\n", value)
+		return c.writeSecretJSON(SecretValueJSON{
+			AddedAt: secret.AddedAt,
+			Value:   smartJSONValue(decryptedValues[0]),
+			Vault:   secretVaultPath,
+		})
+	}
+	if all {
+		return c.writeSecretValueList(decryptedValuesWithTime)
+	}
+	if len(decryptedValues) > 0 {
+		return c.writeSecretValue(decryptedValues[0])
+	}
+
+	return nil
+}
+
+// getDeletedSecret prints the last value a deleted secret had before it was
+// deleted, for --include-deleted. The newest such value readable by fp is
+// used; a warning makes clear that the secret no longer resolves.
+func (c *CLI) getDeletedSecret(secretObj *vault.Secret, index int, jsonOutput bool, fp string) *Error {
+	key := secretObj.Key
+	value := secretObj.LatestReadableValue(fp)
+	if value == nil {
+		for _, v := range secretObj.Values {
+			if !v.Deleted {
+				return NewError(accessDeniedMessage(key, fp), ExitAccessDenied)
 			}
 		}
+		return NewError(fmt.Sprintf("secret '%s' has been deleted and has no earlier value", key), ExitVaultError)
 	}
 
-	return nil
+	deletedAt := secretObj.Values[len(secretObj.Values)-1].AddedAt
+	// Printed even with --silent: the value no longer resolves anywhere else.
+	_, _ = fmt.Fprintf(c.output.Stderr(), "warning: secret '%s' was DELETED on %s; printing its last value from before the deletion\n", key, deletedAt.Format(time.RFC3339))
+
+	if detErr := detachedValueError(key, value); detErr != nil {
+		return detErr
+	}
+	encryptedArmored, decodeErr := base64.StdEncoding.DecodeString(value.Value)
+	if decodeErr != nil {
+		return NewError(fmt.Sprintf("failed to decode encrypted value: %v", decodeErr), ExitGeneralError)
+	}
+	plaintext, decErr := c.gpgClient.DecryptWithAgent(encryptedArmored, fp)
+	if decErr != nil {
+		return NewError(fmt.Sprintf("failed to decrypt secret: %v", decErr), ExitGPGError)
+	}
+	plaintext, inflateErr := c.inflateValue(value, plaintext)
+	if inflateErr != nil {
+		return inflateErr
+	}
+	decrypted := c.encodeValue(plaintext)
+
+	if jsonOutput {
+		var vaultPath string
+		if entries := c.vaultResolver.GetConfig().Entries; index < len(entries) {
+			vaultPath = entries[index].Path
+		}
+		return c.writeSecretJSON(SecretValueJSON{
+			AddedAt: value.AddedAt,
+			Value:   smartJSONValue(decrypted),
+			Vault:   vaultPath,
+		})
+	}
+	return c.writeSecretValue(decrypted)
 }
 
 // vaultGetFromIndex retrieves a secret from a specific vault index.
-// If the user cannot access the latest value, falls back to older accessible values with a warning.
+// If the user cannot access the latest value, falls back to older accessible values
+// unless --require-latest is set.
 func (c *CLI) vaultGetFromIndex(key string, index int, all bool, jsonOutput bool, fp string) *Error {
 	secretObj := c.vaultResolver.GetSecretByKeyFromVault(index, key)
 	if secretObj == nil {
@@ -522,6 +996,9 @@ func (c *CLI) vaultGetFromIndex(key string, index int, all bool, jsonOutput bool
 
 	// Check if secret is deleted
 	if secretObj.IsDeleted() {
+		if c.inclDeleted && !all {
+			return c.getDeletedSecret(secretObj, index, jsonOutput, fp)
+		}
 		_, _ = fmt.Fprintf(c.output.Stderr(), "error: secret '%s' has been deleted\n", key)
 		return NewError(fmt.Sprintf("secret '%s' has been deleted", key), ExitVaultError)
 	}
@@ -537,33 +1014,20 @@ func (c *CLI) vaultGetFromIndex(key string, index int, all bool, jsonOutput bool
 
 	if all {
 		// Decrypt all values in reverse order
+		jobs := make([]decryptJob, 0, len(secretObj.Values))
 		for i := len(secretObj.Values) - 1; i >= 0; i-- {
-			val := secretObj.Values[i]
-
-			encryptedArmored, decodeErr := base64.StdEncoding.DecodeString(val.Value)
-			if decodeErr != nil {
-				// Always warn and try other values
-				c.Warnf("failed to decode value from %s: %v", val.AddedAt, decodeErr)
-				continue
-			}
-
-			// Let GPG agent determine decryptability — the user may have a key
-			// in the agent that isn't the logged-in identity.
-			plaintext, decErr := c.gpgClient.DecryptWithAgent(encryptedArmored, fp)
-			if decErr != nil {
-				// Always warn and try other values
-				c.Warnf("failed to decrypt value from %s: %v", val.AddedAt, decErr)
-				continue
-			}
-			valStr := string(plaintext)
-			decryptedValues = append(decryptedValues, valStr)
-			decryptedValuesWithTime = append(decryptedValuesWithTime, SecretValueJSON{
-				AddedAt:     val.AddedAt,
-				Value:       smartJSONValue(valStr),
-				Vault:       vaultPath,
-				AvailableTo: val.AvailableTo,
-				SignedBy:    val.SignedBy,
-			})
+			jobs = append(jobs, decryptJob{value: secretObj.Values[i], vaultPath: vaultPath})
+		}
+		var batchErr *Error
+		decryptedValuesWithTime, batchErr = c.decryptBatch(jobs, fp)
+		if batchErr != nil {
+			return batchErr
+		}
+		if c.dedupe {
+			decryptedValuesWithTime = dedupeValues(decryptedValuesWithTime)
+		}
+		if c.oldestFirst {
+			slices.Reverse(decryptedValuesWithTime)
 		}
 	} else {
 		// Use manager to get accessible value (supporting fallback)
@@ -579,12 +1043,19 @@ func (c *CLI) vaultGetFromIndex(key string, index int, all bool, jsonOutput bool
 
 		// Try fingerprint-matched access first, fall back to latest value
 		// and let GPG agent determine if we can decrypt.
-		val := manager.GetAccessibleSecretValue(fp, key)
-		notGranted := val == nil
+		// --require-latest skips straight to the latest value.
+		var val *vault.SecretValue
+		if !c.requireLatest {
+			val = manager.GetAccessibleSecretValue(fp, key)
+		}
 		if val == nil {
 			val = &secretObj.Values[len(secretObj.Values)-1]
 		}
+		notGranted := !val.CanBeReadBy(fp)
 
+		if detErr := detachedValueError(key, val); detErr != nil {
+			return detErr
+		}
 		encryptedArmored, decodeErr := base64.StdEncoding.DecodeString(val.Value)
 		if decodeErr != nil {
 			return NewError(fmt.Sprintf("failed to decode encrypted value: %v", decodeErr), ExitGeneralError)
@@ -592,49 +1063,41 @@ func (c *CLI) vaultGetFromIndex(key string, index int, all bool, jsonOutput bool
 
 		plaintext, decErr := c.gpgClient.DecryptWithAgent(encryptedArmored, fp)
 		if decErr != nil {
+			if notGranted && c.requireLatest {
+				return NewError(notLatestMessage(key, fp), ExitAccessDenied)
+			}
 			if notGranted {
 				return NewError(accessDeniedMessage(key, fp), ExitAccessDenied)
 			}
 			return NewError(fmt.Sprintf("failed to decrypt secret: %v", decErr), ExitGPGError)
 		}
-		decryptedValues = append(decryptedValues, string(plaintext))
+		plaintext, inflateErr := c.inflateValue(val, plaintext)
+		if inflateErr != nil {
+			return inflateErr
+		}
+		decryptedValues = append(decryptedValues, c.encodeValue(plaintext))
 		decryptedValuesWithTime = append(decryptedValuesWithTime, SecretValueJSON{
 			AddedAt: val.AddedAt,
-			Value:   smartJSONValue(string(plaintext)),
+			Value:   smartJSONValue(c.encodeValue(plaintext)),
 			Vault:   vaultPath,
+			Source:  val.Source,
 		})
 	}
 
-	if len(decryptedValues) == 0 {
+	if len(decryptedValuesWithTime) == 0 {
 		return NewError(fmt.Sprintf("no accessible values for secret '%s'", key), ExitAccessDenied)
 	}
 
 	if jsonOutput {
-		encoder := json.NewEncoder(c.output.Stdout())
-		encoder.SetIndent("", "  ")
-
 		if all {
-			if err := encoder.Encode(decryptedValuesWithTime); err != nil {
-				return NewError(fmt.Sprintf("failed to encode json: %v", err), ExitGeneralError)
-			}
-		} else {
-			if err := encoder.Encode(decryptedValuesWithTime[0]); err != nil {
-				return NewError(fmt.Sprintf("failed to encode json: %v", err), ExitGeneralError)
-			}
-		}
-	} else {
-		if all {
-			for _, item := range decryptedValuesWithTime {
-				_, _ = fmt.Fprintf(c.output.Stdout(), "%s (%s): %s\n", item.AddedAt.Format(time.RFC3339), item.Vault, item.Value)
-			}
-		} else {
-			// Trim trailing newline to avoid double newline (echo adds one, we add one)
-			value := strings.TrimSuffix(decryptedValues[0], "\n")
-			_, _ = fmt.Fprintf(c.output.Stdout(), "%s\n", value)
+			return c.writeSecretJSON(decryptedValuesWithTime)
 		}
+		return c.writeSecretJSON(decryptedValuesWithTime[0])
 	}
-
-	return nil
+	if all {
+		return c.writeSecretValueList(decryptedValuesWithTime)
+	}
+	return c.writeSecretValue(decryptedValues[0])
 }
 
 // vaultGetLastFromAllVaults retrieves the most recent value (by added_at) across all vaults
@@ -644,9 +1107,10 @@ func (c *CLI) vaultGetLastFromAllVaults(key string, jsonOutput bool, fp string)
 	var mostRecentVaultPath string
 	secretExists := false
 
-	entries := c.vaultResolver.GetConfig().Entries
+	config := c.vaultResolver.GetConfig()
 
-	for i, entry := range entries {
+	for _, i := range config.SearchIndices() {
+		entry := config.Entries[i]
 		secretObj := c.vaultResolver.GetSecretByKeyFromVault(i, key)
 		if secretObj == nil || len(secretObj.Values) == 0 {
 			continue
@@ -661,14 +1125,9 @@ func (c *CLI) vaultGetLastFromAllVaults(key string, jsonOutput bool, fp string)
 		for j := range secretObj.Values {
 			val := &secretObj.Values[j]
 
-			hasAccess := false
-			for _, authorizedFp := range val.AvailableTo {
-				if authorizedFp == fp {
-					hasAccess = true
-					break
-				}
-			}
-			if !hasAccess {
+			// Under --require-latest, values not shared with fp still count
+			// as most recent; the agent then decrypts them or the call fails.
+			if !c.requireLatest && !val.CanBeReadBy(fp) {
 				continue
 			}
 
@@ -687,6 +1146,9 @@ func (c *CLI) vaultGetLastFromAllVaults(key string, jsonOutput bool, fp string)
 		return NewError(fmt.Sprintf("secret '%s' not found in any vault", key), ExitVaultError)
 	}
 
+	if detErr := detachedValueError(key, mostRecentValue); detErr != nil {
+		return detErr
+	}
 	encryptedArmored, decodeErr := base64.StdEncoding.DecodeString(mostRecentValue.Value)
 	if decodeErr != nil {
 		return NewError(fmt.Sprintf("failed to decode encrypted value: %v", decodeErr), ExitGeneralError)
@@ -694,32 +1156,220 @@ func (c *CLI) vaultGetLastFromAllVaults(key string, jsonOutput bool, fp string)
 
 	plaintext, decErr := c.gpgClient.DecryptWithAgent(encryptedArmored, fp)
 	if decErr != nil {
+		if !mostRecentValue.CanBeReadBy(fp) {
+			return NewError(notLatestMessage(key, fp), ExitAccessDenied)
+		}
 		return NewError(fmt.Sprintf("failed to decrypt secret: %v", decErr), ExitGPGError)
 	}
+	plaintext, inflateErr := c.inflateValue(mostRecentValue, plaintext)
+	if inflateErr != nil {
+		return inflateErr
+	}
 
 	if jsonOutput {
-		encoder := json.NewEncoder(c.output.Stdout())
-		encoder.SetIndent("", "  ")
-		output := SecretValueJSON{
+		return c.writeSecretJSON(SecretValueJSON{
 			AddedAt: mostRecentValue.AddedAt,
-			Value:   smartJSONValue(string(plaintext)),
+			Value:   smartJSONValue(c.encodeValue(plaintext)),
 			Vault:   mostRecentVaultPath,
+		})
+	}
+	return c.writeSecretValue(c.encodeValue(plaintext))
+}
+
+// decryptJob is one encrypted value to decrypt in a batch.
+type decryptJob struct {
+	value     vault.SecretValue
+	vaultPath string
+}
+
+// DecryptFailureMode is how 'secret get --all' handles a value that fails to
+// decode or decrypt.
+type DecryptFailureMode string
+
+// Failure modes for SetDecryptFailureMode.
+const (
+	// DecryptBestEffort warns about the value and keeps the others.
+	DecryptBestEffort DecryptFailureMode = ""
+	// DecryptFailFast stops at the first failure and returns it.
+	DecryptFailFast DecryptFailureMode = "fail-fast"
+)
+
+// dedupeValues collapses each run of consecutive values with identical
+// plaintext into the one added earliest, so the history lists only changes.
+func dedupeValues(values []SecretValueJSON) []SecretValueJSON {
+	var result []SecretValueJSON
+	for _, v := range values {
+		if n := len(result); n > 0 && jsonValueText(result[n-1].Value) == jsonValueText(v.Value) {
+			if v.AddedAt.Before(result[n-1].AddedAt) {
+				result[n-1] = v
+			}
+			continue
 		}
-		if err := encoder.Encode(output); err != nil {
-			return NewError(fmt.Sprintf("failed to encode json: %v", err), ExitGeneralError)
+		result = append(result, v)
+	}
+	return result
+}
+
+// jsonValueText returns the text of a value built by smartJSONValue.
+func jsonValueText(v interface{}) string {
+	if raw, ok := v.(json.RawMessage); ok {
+		return string(raw)
+	}
+	s, _ := v.(string)
+	return s
+}
+
+// decryptBatch decrypts jobs with at most c.concurrency decryptions in flight
+// and returns the decrypted values in job order, regardless of the order in
+// which decryptions finish. Values that fail to decode or decrypt are skipped
+// with a warning, also emitted in job order. With DecryptFailFast, no new
+// decryption starts after a failure, and the failure of the earliest job
+// is returned instead.
+func (c *CLI) decryptBatch(jobs []decryptJob, fp string) ([]SecretValueJSON, *Error) {
+	type result struct {
+		plaintext []byte
+		err       *Error
+	}
+	results := make([]result, len(jobs))
+	failFast := c.failFast
+
+	limit := c.concurrency
+	if limit < 1 {
+		limit = 1
+	}
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	var failed atomic.Bool
+	for i, job := range jobs {
+		if detErr := job.value.DetachedErr(); detErr != nil {
+			results[i].err = NewError(fmt.Sprintf("value from %s: %v", job.value.AddedAt, detErr), ExitValidationError)
+			failed.Store(true)
+			if failFast {
+				break
+			}
+			continue
 		}
-	} else {
-		_, _ = fmt.Fprintf(c.output.Stdout(), "%s\n", string(plaintext))
+		encryptedArmored, decodeErr := base64.StdEncoding.DecodeString(job.value.Value)
+		if decodeErr != nil {
+			results[i].err = NewError(fmt.Sprintf("failed to decode value from %s: %v", job.value.AddedAt, decodeErr), ExitGeneralError)
+			failed.Store(true)
+			if failFast {
+				break
+			}
+			continue
+		}
+
+		sem <- struct{}{}
+		if failFast && failed.Load() {
+			<-sem
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			// Let GPG agent determine decryptability — the user may have a key
+			// in the agent that isn't the logged-in identity.
+			plaintext, decErr := c.gpgClient.DecryptWithAgent(encryptedArmored, fp)
+			if decErr != nil {
+				results[i].err = NewError(fmt.Sprintf("failed to decrypt value from %s: %v", job.value.AddedAt, decErr), ExitGPGError)
+				failed.Store(true)
+				return
+			}
+			plaintext, inflateErr := c.inflateValue(&job.value, plaintext)
+			if inflateErr != nil {
+				results[i].err = inflateErr
+				failed.Store(true)
+				return
+			}
+			results[i].plaintext = plaintext
+		}()
+	}
+	wg.Wait()
+
+	var decrypted []SecretValueJSON
+	for i, r := range results {
+		if r.err != nil {
+			if failFast {
+				return nil, r.err
+			}
+			c.Warnf("%s", r.err.Message)
+			continue
+		}
+		val := jobs[i].value
+		decrypted = append(decrypted, SecretValueJSON{
+			AddedAt:     val.AddedAt,
+			Value:       smartJSONValue(c.encodeValue(r.plaintext)),
+			Vault:       jobs[i].vaultPath,
+			AvailableTo: val.AvailableTo,
+			SignedBy:    val.SignedBy,
+			Source:      val.Source,
+		})
 	}
+	return decrypted, nil
+}
+
+// writeSecretValue prints a single decrypted value followed by a newline.
+// A trailing newline in the value is trimmed to avoid a double newline (echo
+// adds one, we add one).
+func (c *CLI) writeSecretValue(value string) *Error {
+	return c.emitSecretValue([]byte(strings.TrimSuffix(value, "\n") + "\n"))
+}
+
+// writeSecretValueList prints decrypted values one per line, prefixed with
+// when and where each was added, and its source if it has one.
+func (c *CLI) writeSecretValueList(values []SecretValueJSON) *Error {
+	var sb strings.Builder
+	for _, item := range values {
+		if item.Source != "" {
+			_, _ = fmt.Fprintf(&sb, "%s (%s) [%s]: %s\n", c.formatTime(item.AddedAt), item.Vault, item.Source, item.Value)
+			continue
+		}
+		_, _ = fmt.Fprintf(&sb, "%s (%s): %s\n", c.formatTime(item.AddedAt), item.Vault, item.Value)
+	}
+	return c.emitSecretValue([]byte(sb.String()))
+}
 
+// writeSecretJSON prints decrypted values as indented JSON, or in the
+// format set with SetOutputFormat.
+func (c *CLI) writeSecretJSON(v interface{}) *Error {
+	data, err := c.output.FormatData(v)
+	if err != nil {
+		return NewError(fmt.Sprintf("failed to encode json: %v", err), ExitGeneralError)
+	}
+	return c.emitSecretValue(data)
+}
+
+// emitSecretValue hands formatted secret output to the output handler, which
+// owns the single audited path for printing values.
+func (c *CLI) emitSecretValue(data []byte) *Error {
+	if err := c.output.WriteSecretValue(data); err != nil {
+		return NewError(fmt.Sprintf("failed to write secret value: %v", err), ExitGeneralError)
+	}
 	return nil
 }
 
 // readSecretFromStdin reads a secret from stdin.
-// If stdin is a TTY, it uses term.ReadPassword to hide the input.
+// If stdin is a TTY, it uses term.ReadPassword to hide the input and reads a
+// single line. Otherwise the whole stream is read with ReadPipedSecret.
 func (c *CLI) readSecretFromStdin() (string, error) {
-	// Check if stdin is a TTY - if so, use secure password reading
-	if f, ok := c.stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+	if readHidden := c.terminalReader(); readHidden != nil {
+		return readHidden()
+	}
+	return ReadPipedSecret(c.stdin)
+}
+
+// terminalReader returns the function reading one line from stdin without
+// echo, or nil when stdin is not a terminal.
+func (c *CLI) terminalReader() func() (string, error) {
+	if c.readHidden != nil {
+		return c.readHidden
+	}
+	f, ok := c.stdin.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return nil
+	}
+	return func() (string, error) {
 		password, err := term.ReadPassword(int(f.Fd()))
 		if err != nil {
 			return "", err
@@ -728,16 +1378,36 @@ func (c *CLI) readSecretFromStdin() (string, error) {
 		_, _ = fmt.Fprintln(c.output.Stderr())
 		return string(password), nil
 	}
+}
 
-	// Non-TTY: read normally (e.g., piped input)
-	scanner := bufio.NewScanner(c.stdin)
-	if scanner.Scan() {
-		return scanner.Text(), nil
+// shouldConfirmValue reports whether a value typed at the terminal for
+// target is asked for twice: as set by SetConfirmValue, or by default only
+// when the secret is new.
+func (c *CLI) shouldConfirmValue(target *secretPutTarget) bool {
+	if c.confirmValue != nil {
+		return *c.confirmValue
 	}
-	if err := scanner.Err(); err != nil {
+	return c.vaultResolver.GetSecretByKeyFromVault(target.index, target.key) == nil
+}
+
+// ReadPipedSecret reads a secret value from non-interactive input such as a
+// pipe. The entire stream is read and exactly one trailing newline ("\n" or
+// "\r\n") is removed, so `echo value` and `printf %s value` store the same
+// value. Any further trailing newlines are kept as part of the value. Empty
+// input is an error.
+func ReadPipedSecret(r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
 		return "", err
 	}
-	return "", fmt.Errorf("no input provided")
+	value := string(data)
+	if strings.HasSuffix(value, "\n") {
+		value = strings.TrimSuffix(value[:len(value)-1], "\r")
+	}
+	if value == "" {
+		return "", fmt.Errorf("no input provided")
+	}
+	return value, nil
 }
 
 // SecretListJSON is the JSON output structure for secret list
@@ -751,6 +1421,9 @@ type SecretListJSON struct {
 // If vaultPath is specified or fromIndex > 0, lists secrets only from that vault.
 // Otherwise, lists secrets from all vaults.
 func (c *CLI) SecretList(jsonOutput bool, vaultPath string, fromIndex int) *Error {
+	if err := checkGlob("--filter", c.keyFilter); err != nil {
+		return err
+	}
 	targetIndex := -1
 
 	// Resolve which vault(s) to list from
@@ -768,9 +1441,9 @@ func (c *CLI) SecretList(jsonOutput bool, vaultPath string, fromIndex int) *Erro
 		if !found {
 			// Check if file exists for better error message
 			if _, err := os.Stat(expandedPath); err != nil {
-				return NewError(fmt.Sprintf("vault file does not exist: %s", expandedPath), ExitVaultError)
+				return c.vaultPathError(expandedPath, fmt.Sprintf("vault file does not exist: %s", expandedPath), ExitVaultError)
 			}
-			return NewError(fmt.Sprintf("vault path '%s' not found in resolver", expandedPath), ExitVaultError)
+			return c.vaultPathError(expandedPath, fmt.Sprintf("vault %s is not one of the loaded vaults", expandedPath), ExitVaultError)
 		}
 	} else if fromIndex != 0 {
 		configEntries := c.vaultResolver.GetConfig().Entries
@@ -788,6 +1461,8 @@ func (c *CLI) SecretList(jsonOutput bool, vaultPath string, fromIndex int) *Erro
 		secrets = c.vaultResolver.ListAllSecretKeys()
 	}
 
+	secrets = slices.DeleteFunc(secrets, func(s vault.SecretKeyInfo) bool { return !matchGlob(c.keyFilter, s.Key) })
+
 	// Sort secrets by key
 	sort.Slice(secrets, func(i, j int) bool {
 		return secrets[i].Key < secrets[j].Key
@@ -804,9 +1479,7 @@ func (c *CLI) SecretList(jsonOutput bool, vaultPath string, fromIndex int) *Erro
 			})
 		}
 
-		encoder := json.NewEncoder(c.output.Stdout())
-		encoder.SetIndent("", "  ")
-		if err := encoder.Encode(output); err != nil {
+		if err := c.output.EncodeJSON(output); err != nil {
 			return NewError(fmt.Sprintf("failed to encode json: %v", err), ExitGeneralError)
 		}
 	} else {
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// slowDecryptGPGClient "decrypts" cipher-N to plain-N, finishing earlier jobs
+// last so that out-of-order completion is exercised. It records the highest
+// number of decryptions in flight at once.
+type slowDecryptGPGClient struct {
+	*MockGPGClient
+	total int
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (m *slowDecryptGPGClient) DecryptWithAgent(ciphertext []byte, fingerprint string) ([]byte, error) {
+	m.mu.Lock()
+	m.inFlight++
+	m.maxInFlight = max(m.maxInFlight, m.inFlight)
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		m.inFlight--
+		m.mu.Unlock()
+	}()
+
+	var n int
+	if _, err := fmt.Sscanf(string(ciphertext), "cipher-%d", &n); err != nil {
+		return nil, fmt.Errorf("bad ciphertext %q", ciphertext)
+	}
+	time.Sleep(time.Duration(m.total-n) * time.Millisecond)
+	if n == 3 {
+		return nil, fmt.Errorf("no secret key")
+	}
+	return []byte(fmt.Sprintf("plain-%d", n)), nil
+}
+
+func TestDecryptBatch_OrderedRegardlessOfConcurrency(t *testing.T) {
+	const total = 8
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	jobs := make([]decryptJob, 0, total+1)
+	for i := 0; i < total; i++ {
+		jobs = append(jobs, decryptJob{
+			value: vault.SecretValue{
+				AddedAt: base.Add(time.Duration(i) * time.Hour),
+				Value:   base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("cipher-%d", i))),
+			},
+			vaultPath: "/vault",
+		})
+	}
+	jobs = append(jobs, decryptJob{value: vault.SecretValue{AddedAt: base, Value: "!not-base64!"}})
+
+	var want []string
+	for i := 0; i < total; i++ {
+		if i != 3 {
+			want = append(want, fmt.Sprintf("plain-%d", i))
+		}
+	}
+
+	for _, concurrency := range []int{0, 1, 3, total} {
+		t.Run(fmt.Sprintf("concurrency=%d", concurrency), func(t *testing.T) {
+			gpgMock := &slowDecryptGPGClient{MockGPGClient: NewMockGPGClient(), total: total}
+			stderr := &bytes.Buffer{}
+			cli := &CLI{
+				gpgClient: gpgMock,
+				output:    output.NewHandler(&bytes.Buffer{}, stderr),
+			}
+			cli.SetConcurrency(concurrency)
+
+			results, batchErr := cli.decryptBatch(jobs, "FP")
+			if batchErr != nil {
+				t.Fatalf("best-effort decryptBatch returned %v", batchErr)
+			}
+
+			var got []string
+			for _, r := range results {
+				got = append(got, fmt.Sprint(r.Value))
+			}
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("results = %v, want %v", got, want)
+			}
+
+			limit := max(concurrency, 1)
+			if gpgMock.maxInFlight > limit {
+				t.Errorf("max in-flight decryptions = %d, want <= %d", gpgMock.maxInFlight, limit)
+			}
+
+			warnings := stderr.String()
+			if !strings.Contains(warnings, "failed to decrypt value") || !strings.Contains(warnings, "failed to decode value") {
+				t.Errorf("expected decrypt and decode warnings, got:\n%s", warnings)
+			}
+			if strings.Index(warnings, "failed to decrypt") > strings.Index(warnings, "failed to decode") {
+				t.Errorf("warnings should follow job order:\n%s", warnings)
+			}
+		})
+	}
+}
+
+func TestSecretGet_AllMode_FailFast(t *testing.T) {
+	t.Setenv("DOTSECENV_CONFIG", "")
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var values []vault.SecretValue
+	for n := 2; n <= 4; n++ {
+		values = append(values, vault.SecretValue{
+			AddedAt:     base.Add(time.Duration(n) * time.Hour),
+			Value:       base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("cipher-%d", n))),
+			AvailableTo: []string{"ME"},
+		})
+	}
+	resolver := NewMockVaultResolver()
+	resolver.Secrets[0] = map[string]vault.Secret{"TOKEN": {Key: "TOKEN", Values: values}}
+	resolver.VaultPaths = []string{"/vault.yaml"}
+	resolver.VaultEntries = []vault.VaultEntry{{Path: "/vault.yaml"}}
+
+	for _, fromIndex := range []int{0, 1} {
+		t.Run(fmt.Sprintf("-v %d", fromIndex), func(t *testing.T) {
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			cli := &CLI{
+				config:        config.Config{Login: newTestSignedLogin(t, "ME")},
+				vaultResolver: resolver,
+				gpgClient:     &slowDecryptGPGClient{MockGPGClient: NewMockGPGClient(), total: 5},
+				output:        output.NewHandler(stdout, stderr),
+			}
+
+			// Best effort: the value that fails (cipher-3) is skipped with a warning.
+			if err := cli.SecretGet("TOKEN", true, false, false, "", fromIndex); err != nil {
+				t.Fatalf("best-effort SecretGet --all failed: %v", err)
+			}
+			if out := stdout.String(); !strings.Contains(out, "plain-4") || !strings.Contains(out, "plain-2") {
+				t.Errorf("expected the other values, got:\n%s", out)
+			}
+			if !strings.Contains(stderr.String(), "failed to decrypt value") {
+				t.Errorf("expected a decrypt warning, got:\n%s", stderr.String())
+			}
+
+			// Fail fast: nothing is printed and the failure is returned.
+			stdout.Reset()
+			cli.SetDecryptFailureMode(DecryptFailFast)
+			err := cli.SecretGet("TOKEN", true, false, false, "", fromIndex)
+			if err == nil || err.ExitCode != ExitGPGError {
+				t.Fatalf("expected a GPG error with fail-fast, got %v", err)
+			}
+			if !strings.Contains(err.Message, "failed to decrypt value") {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if stdout.Len() != 0 {
+				t.Errorf("fail-fast should print no values, got:\n%s", stdout.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// explainAccess prints to stderr why the active identity cannot read
+// secretKey: in which configured vaults the secret exists or is deleted,
+// whether the identity is in each vault, and which fingerprints the newest
+// value is shared with. It reads vault metadata only and never decrypts.
+func (c *CLI) explainAccess(secretKey string) {
+	out := c.output.Stderr()
+	fp := c.activeFingerprint()
+
+	_, _ = fmt.Fprintf(out, "explain: access to secret '%s' for identity %s\n", secretKey, fp)
+
+	var sharers []string
+	for i, entry := range c.vaultResolver.GetConfig().Entries {
+		label := fmt.Sprintf("  vault %d (%s)", i+1, entry.Path)
+		if loadErr := c.vaultResolver.GetLoadError(i); loadErr != nil {
+			_, _ = fmt.Fprintf(out, "%s: not loaded: %v\n", label, loadErr)
+			continue
+		}
+		member := "your identity is not in this vault"
+		if c.vaultResolver.IdentityExistsInVault(fp, i) {
+			member = "your identity is in this vault"
+		}
+
+		secret := c.vaultResolver.GetSecretByKeyFromVault(i, secretKey)
+		if secret == nil || len(secret.Values) == 0 {
+			_, _ = fmt.Fprintf(out, "%s: secret not present; %s\n", label, member)
+			continue
+		}
+		if secret.IsDeleted() {
+			deletedAt := secret.Values[len(secret.Values)-1].AddedAt
+			_, _ = fmt.Fprintf(out, "%s: secret deleted on %s; %s\n", label, deletedAt.Format(time.RFC3339), member)
+			continue
+		}
+
+		latest := secret.Values[len(secret.Values)-1]
+		_, _ = fmt.Fprintf(out, "%s: secret present with %d value(s); %s\n", label, len(secret.Values), member)
+		_, _ = fmt.Fprintf(out, "    latest value (%s) is shared with:\n", latest.AddedAt.Format(time.RFC3339))
+		for _, holder := range latest.AvailableTo {
+			desc := holder
+			if id := c.vaultResolver.GetIdentityByFingerprint(holder); id != nil && id.UID != "" {
+				desc += " (" + id.UID + ")"
+			}
+			if holder == fp {
+				desc += " <- you"
+			}
+			_, _ = fmt.Fprintf(out, "      %s\n", desc)
+		}
+		switch readable := secret.LatestReadableValue(fp); {
+		case latest.CanBeReadBy(fp):
+			_, _ = fmt.Fprintf(out, "    the latest value is shared with you; check that its secret key is in your GPG keyring\n")
+		case readable != nil:
+			_, _ = fmt.Fprintf(out, "    newest value shared with you: %s (older than the latest)\n", readable.AddedAt.Format(time.RFC3339))
+		default:
+			_, _ = fmt.Fprintf(out, "    no value is shared with your identity\n")
+			for _, holder := range latest.AvailableTo {
+				if !slices.Contains(sharers, holder) {
+					sharers = append(sharers, holder)
+				}
+			}
+		}
+	}
+
+	if len(sharers) > 0 {
+		_, _ = fmt.Fprintf(out, "  ask one of %s to run: dotsecenv secret share %s %s\n", strings.Join(sharers, ", "), secretKey, fp)
+	}
+}
@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// SecretPutFile stores the contents of a file as a secret value. The file is
+// streamed through encryption and base64 encoding, so the plaintext is never
+// held in memory as a whole. The encoded ciphertext still is: the vault stores
+// each value as a single JSON line. Files larger than max_secret_size are
+// refused unless --allow-large was given.
+func (c *CLI) SecretPutFile(secretKeyArg, filePath, vaultPath string, fromIndex int) *Error {
+	f, openErr := os.Open(filePath)
+	if openErr != nil {
+		return NewError(fmt.Sprintf("failed to open file: %v", openErr), ExitGeneralError)
+	}
+	defer func() { _ = f.Close() }()
+
+	info, statErr := f.Stat()
+	if statErr != nil {
+		return NewError(fmt.Sprintf("failed to stat file: %v", statErr), ExitGeneralError)
+	}
+	if !info.Mode().IsRegular() {
+		return NewError(fmt.Sprintf("not a regular file: %s", filePath), ExitGeneralError)
+	}
+	if sizeErr := c.checkSecretSize(info.Size()); sizeErr != nil {
+		return sizeErr
+	}
+
+	target, prepErr := c.prepareSecretPut(secretKeyArg, vaultPath, fromIndex, false)
+	if prepErr != nil {
+		return prepErr
+	}
+
+	var encoded strings.Builder
+	encoder := base64.NewEncoder(base64.StdEncoding, &encoded)
+	encrypter, encErr := c.gpgClient.EncryptStream(encoder, target.publicKeys())
+	if encErr != nil {
+		return NewError(fmt.Sprintf("failed to encrypt secret: %v", encErr), ExitGeneralError)
+	}
+	if c.compress {
+		encrypter = &gzipEncrypter{Writer: gzip.NewWriter(encrypter), encrypter: encrypter}
+	}
+
+	// The file may have grown since it was stat'ed; stop reading one byte
+	// past the limit so the check below catches that without reading it all.
+	var src io.Reader = f
+	if !c.allowLarge {
+		maxSize, _ := c.config.GetMaxSecretSize()
+		src = io.LimitReader(f, maxSize+1)
+	}
+	size, copyErr := io.Copy(encrypter, src)
+	if copyErr != nil {
+		_ = encrypter.Close()
+		return NewError(fmt.Sprintf("failed to encrypt file: %v", copyErr), ExitGeneralError)
+	}
+	if sizeErr := c.checkSecretSize(size); sizeErr != nil {
+		_ = encrypter.Close()
+		return sizeErr
+	}
+	if err := encrypter.Close(); err != nil {
+		return NewError(fmt.Sprintf("failed to encrypt file: %v", err), ExitGeneralError)
+	}
+	if err := encoder.Close(); err != nil {
+		return NewError(fmt.Sprintf("failed to encode ciphertext: %v", err), ExitGeneralError)
+	}
+
+	if storeErr := c.storeEncryptedValue(target, encoded.String()); storeErr != nil {
+		return storeErr
+	}
+
+	_, _ = fmt.Fprintf(c.output.Stdout(), "Secret '%s' stored from %s (%d bytes)\n", target.key, filePath, size)
+	return nil
+}
+
+// SecretGetFile decrypts a secret value into a file. The stored value is
+// base64-decoded and decrypted as a stream, so the plaintext is never held in
+// memory as a whole. The file is written with mode 0600 next to outPath and
+// renamed into place only after decryption succeeds.
+func (c *CLI) SecretGetFile(secretKeyArg, outPath, vaultPath string, fromIndex int) *Error {
+	secretKey, normErr := vault.NormalizeSecretKey(secretKeyArg)
+	if normErr != nil {
+		return NewError(vault.FormatSecretKeyError(normErr), ExitValidationError)
+	}
+
+	fp, err := c.checkFingerprintRequired("secret get-file")
+	if err != nil {
+		return err
+	}
+
+	targetIndex, resolveErr := c.resolveReadableVaultIndex(vaultPath, fromIndex)
+	if resolveErr != nil {
+		return resolveErr
+	}
+
+	value, lookupErr := c.readableSecretValue(secretKey, fp, targetIndex)
+	if lookupErr != nil {
+		return lookupErr
+	}
+	if detErr := detachedValueError(secretKey, value); detErr != nil {
+		return detErr
+	}
+
+	tmp, tmpErr := os.CreateTemp(filepath.Dir(outPath), "."+filepath.Base(outPath)+".tmp-*")
+	if tmpErr != nil {
+		return NewError(fmt.Sprintf("failed to create output file: %v", tmpErr), ExitGeneralError)
+	}
+	tmpPath := tmp.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tmp.Close()
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	if err := tmp.Chmod(0600); err != nil {
+		return NewError(fmt.Sprintf("failed to set output file permissions: %v", err), ExitGeneralError)
+	}
+
+	ciphertext := base64.NewDecoder(base64.StdEncoding, strings.NewReader(value.Value))
+	if value.Compressed {
+		if decErr := c.decryptCompressedStream(tmp, ciphertext, fp); decErr != nil {
+			return decErr
+		}
+	} else if decErr := c.gpgClient.DecryptStream(tmp, ciphertext, fp); decErr != nil {
+		return NewError(fmt.Sprintf("failed to decrypt secret: %v", decErr), ExitGPGError)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return NewError(fmt.Sprintf("failed to write output file: %v", err), ExitGeneralError)
+	}
+	if err := os.Rename(tmpPath, outPath); err != nil {
+		return NewError(fmt.Sprintf("failed to write output file: %v", err), ExitGeneralError)
+	}
+	committed = true
+
+	_, _ = fmt.Fprintf(c.output.Stdout(), "Secret '%s' written to %s\n", secretKey, outPath)
+	return nil
+}
+
+// readableSecretValue returns the newest value of secretKey that fp can read,
+// from the vault at index or, when index is -1, from the first vault holding
+// an accessible value.
+func (c *CLI) readableSecretValue(secretKey, fp string, index int) (*vault.SecretValue, *Error) {
+	if index >= 0 {
+		secretObj := c.vaultResolver.GetSecretByKeyFromVault(index, secretKey)
+		if secretObj == nil || len(secretObj.Values) == 0 {
+			return nil, NewError(fmt.Sprintf("secret '%s' not found in vault %d", secretKey, index+1), ExitVaultError)
+		}
+		if secretObj.IsDeleted() {
+			return nil, NewError(fmt.Sprintf("secret '%s' has been deleted", secretKey), ExitVaultError)
+		}
+		for i := len(secretObj.Values) - 1; i >= 0; i-- {
+			if secretObj.Values[i].CanBeReadBy(fp) {
+				return &secretObj.Values[i], nil
+			}
+		}
+		return nil, NewError(accessDeniedMessage(secretKey, fp), ExitAccessDenied)
+	}
+
+	found := false
+	for i := range c.vaultResolver.GetConfig().Entries {
+		secretObj := c.vaultResolver.GetSecretByKeyFromVault(i, secretKey)
+		if secretObj == nil {
+			continue
+		}
+		if secretObj.IsDeleted() {
+			return nil, NewError(fmt.Sprintf("secret '%s' has been deleted", secretKey), ExitVaultError)
+		}
+		found = true
+	}
+
+	value, getErr := c.vaultResolver.GetAccessibleSecretFromAnyVault(secretKey, fp)
+	if getErr != nil {
+		if found {
+			return nil, NewError(accessDeniedMessage(secretKey, fp), ExitAccessDenied)
+		}
+		return nil, NewError(fmt.Sprintf("secret '%s' not found in any vault", secretKey), ExitVaultError)
+	}
+	return value, nil
+}
+
+// gzipEncrypter compresses what is written to it before it reaches the
+// encrypter. Close flushes the compressed stream, then closes the encrypter.
+type gzipEncrypter struct {
+	*gzip.Writer
+	encrypter io.WriteCloser
+}
+
+func (g *gzipEncrypter) Close() error {
+	if err := g.Writer.Close(); err != nil {
+		_ = g.encrypter.Close()
+		return err
+	}
+	return g.encrypter.Close()
+}
+
+// decryptCompressedStream decrypts a value stored compressed into dst,
+// decompressing it on the way, so neither the payload nor the plaintext is
+// held in memory as a whole.
+func (c *CLI) decryptCompressedStream(dst io.Writer, ciphertext io.Reader, fp string) *Error {
+	pr, pw := io.Pipe()
+	decrypted := make(chan error, 1)
+	go func() {
+		err := c.gpgClient.DecryptStream(pw, ciphertext, fp)
+		_ = pw.CloseWithError(err)
+		decrypted <- err
+	}()
+
+	gz, err := gzip.NewReader(pr)
+	if err == nil {
+		_, err = io.Copy(dst, gz)
+	}
+	// Unblock the decrypter if decompression stopped early
+	_ = pr.CloseWithError(err)
+	decErr := <-decrypted
+	// A failed decryption reaches the reader as the same error
+	if err != nil && err != decErr {
+		return NewError(fmt.Sprintf("failed to decompress secret: %v", err), ExitGeneralError)
+	}
+	if decErr != nil {
+		return NewError(fmt.Sprintf("failed to decrypt secret: %v", decErr), ExitGPGError)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// streamGPGClient reverses MockGPGClient.EncryptStream, which prefixes the
+// plaintext with "encrypted_to_<recipients>_".
+type streamGPGClient struct {
+	*MockGPGClient
+	prefix string
+}
+
+func (m *streamGPGClient) DecryptStream(dst io.Writer, ciphertext io.Reader, fingerprint string) error {
+	prefix := make([]byte, len(m.prefix))
+	if _, err := io.ReadFull(ciphertext, prefix); err != nil || string(prefix) != m.prefix {
+		return fmt.Errorf("unexpected ciphertext prefix %q", prefix)
+	}
+	_, err := io.Copy(dst, ciphertext)
+	return err
+}
+
+func TestSecretPutFileGetFile_LargePayload(t *testing.T) {
+	const fp = "MYFINGERPRINT"
+
+	cli, _ := newSecretStoreCLI(t, []string{"/vault1.yaml"}, []string{"/vault1.yaml"})
+	cli.gpgClient = &streamGPGClient{MockGPGClient: NewMockGPGClient(), prefix: "encrypted_to_base64pubkey_"}
+	mock := cli.vaultResolver.(*MockVaultResolver)
+	id := vault.Identity{Fingerprint: fp, PublicKey: "base64pubkey", Algorithm: "RSA", AlgorithmBits: 4096}
+	mock.Identities[fp] = id
+	mock.IdentitiesByVault[0] = map[string]vault.Identity{fp: id}
+	cli.SetAllowLarge(true)
+
+	payload := make([]byte, 5<<20)
+	if _, err := rand.Read(payload); err != nil {
+		t.Fatalf("failed to build payload: %v", err)
+	}
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in.bin")
+	if err := os.WriteFile(in, payload, 0600); err != nil {
+		t.Fatalf("failed to write input: %v", err)
+	}
+
+	if err := cli.SecretPutFile("BLOB", in, "", 1); err != nil {
+		t.Fatalf("SecretPutFile failed: %v", err)
+	}
+
+	stored := mock.Secrets[0]["BLOB"]
+	if len(stored.Values) != 1 {
+		t.Fatalf("expected one stored value, got %d", len(stored.Values))
+	}
+	value := stored.Values[0].Value
+	if strings.ContainsAny(value, "\n\r") {
+		t.Error("stored value must be a single line")
+	}
+	if _, err := base64.StdEncoding.DecodeString(value); err != nil {
+		t.Errorf("stored value is not valid base64: %v", err)
+	}
+	if out := cli.output.Stdout().(*strings.Builder).String(); !strings.Contains(out, fmt.Sprintf("(%d bytes)", len(payload))) {
+		t.Errorf("expected stored size in output, got %q", out)
+	}
+
+	out := filepath.Join(dir, "out.bin")
+	if err := cli.SecretGetFile("BLOB", out, "", 0); err != nil {
+		t.Fatalf("SecretGetFile failed: %v", err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Errorf("round trip mismatch: got %d bytes, want %d", len(got), len(payload))
+	}
+	if info, err := os.Stat(out); err == nil && info.Mode().Perm() != 0600 {
+		t.Errorf("output mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestSecretPutFile_MaxSecretSize(t *testing.T) {
+	const fp = "MYFINGERPRINT"
+
+	cli, _ := newSecretStoreCLI(t, []string{"/vault1.yaml"}, []string{"/vault1.yaml"})
+	cli.config.MaxSecretSize = 16
+	mock := cli.vaultResolver.(*MockVaultResolver)
+	id := vault.Identity{Fingerprint: fp, PublicKey: "base64pubkey", Algorithm: "RSA", AlgorithmBits: 4096}
+	mock.Identities[fp] = id
+	mock.IdentitiesByVault[0] = map[string]vault.Identity{fp: id}
+
+	dir := t.TempDir()
+	atLimit := filepath.Join(dir, "at-limit")
+	overLimit := filepath.Join(dir, "over-limit")
+	if err := os.WriteFile(atLimit, bytes.Repeat([]byte("x"), 16), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(overLimit, bytes.Repeat([]byte("x"), 17), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cli.SecretPutFile("AT_LIMIT", atLimit, "", 1); err != nil {
+		t.Fatalf("file at the limit should be stored: %v", err)
+	}
+	err := cli.SecretPutFile("OVER_LIMIT", overLimit, "", 1)
+	if err == nil || err.ExitCode != ExitValidationError {
+		t.Fatalf("expected validation error for file over the limit, got %v", err)
+	}
+	if _, stored := mock.Secrets[0]["OVER_LIMIT"]; stored {
+		t.Error("file over the limit must not be stored")
+	}
+
+	cli.SetAllowLarge(true)
+	if err := cli.SecretPutFile("OVER_LIMIT", overLimit, "", 1); err != nil {
+		t.Fatalf("--allow-large should store the file: %v", err)
+	}
+}
+
+func TestSecretGetFile_DecryptFailureLeavesNoFile(t *testing.T) {
+	const fp = "MYFINGERPRINT"
+
+	cli, _ := newSecretStoreCLI(t, []string{"/vault1.yaml"}, []string{"/vault1.yaml"})
+	cli.gpgClient = &streamGPGClient{MockGPGClient: NewMockGPGClient(), prefix: "expected-prefix"}
+	mock := cli.vaultResolver.(*MockVaultResolver)
+	mock.Secrets[0] = map[string]vault.Secret{"BLOB": {Key: "BLOB", Values: []vault.SecretValue{
+		{AvailableTo: []string{fp}, Value: base64.StdEncoding.EncodeToString([]byte("garbage"))},
+	}}}
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out.bin")
+	err := cli.SecretGetFile("BLOB", out, "", 0)
+	if err == nil || err.ExitCode != ExitGPGError {
+		t.Fatalf("expected GPG error, got %v", err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("expected no files after failed decryption, found %d", len(entries))
+	}
+}
+
+func TestSecretPutFileGetFile_Compressed(t *testing.T) {
+	const fp = "MYFINGERPRINT"
+
+	cli, _ := newSecretStoreCLI(t, []string{"/vault1.yaml"}, []string{"/vault1.yaml"})
+	cli.gpgClient = &streamGPGClient{MockGPGClient: NewMockGPGClient(), prefix: "encrypted_to_base64pubkey_"}
+	mock := cli.vaultResolver.(*MockVaultResolver)
+	id := vault.Identity{Fingerprint: fp, PublicKey: "base64pubkey", Algorithm: "RSA", AlgorithmBits: 4096}
+	mock.Identities[fp] = id
+	mock.IdentitiesByVault[0] = map[string]vault.Identity{fp: id}
+	cli.SetCompress(true)
+
+	payload := bytes.Repeat([]byte("key = value\n"), 10000)
+	dir := t.TempDir()
+	in := filepath.Join(dir, "app.conf")
+	if err := os.WriteFile(in, payload, 0600); err != nil {
+		t.Fatalf("failed to write input: %v", err)
+	}
+	if err := cli.SecretPutFile("CONF", in, "", 1); err != nil {
+		t.Fatalf("SecretPutFile failed: %v", err)
+	}
+
+	stored := mock.Secrets[0]["CONF"].Values[0]
+	if !stored.Compressed {
+		t.Fatal("expected the stored value to be marked compressed")
+	}
+	if len(stored.Value) >= len(payload) {
+		t.Errorf("expected a compressed value, got %d bytes for %d", len(stored.Value), len(payload))
+	}
+
+	out := filepath.Join(dir, "out.conf")
+	if err := cli.SecretGetFile("CONF", out, "", 0); err != nil {
+		t.Fatalf("SecretGetFile failed: %v", err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Error("decompressed file does not match the original")
+	}
+}
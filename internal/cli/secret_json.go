@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// parseSecretJSONObject decodes data, a JSON object, into values keyed by
+// normalized secret key, with the keys in order. String members are stored
+// as their text; any other member is stored as its compact JSON.
+func parseSecretJSONObject(data []byte) ([]string, map[string]string, *Error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil || raw == nil {
+		return nil, nil, NewError("stdin is not a JSON object of {\"KEY\": \"value\"} members", ExitValidationError)
+	}
+	if len(raw) == 0 {
+		return nil, nil, NewError("nothing to store: the JSON object has no members", ExitGeneralError)
+	}
+
+	values := make(map[string]string, len(raw))
+	sources := make(map[string]string, len(raw))
+	for name, member := range raw {
+		key, normErr := vault.NormalizeSecretKey(name)
+		if normErr != nil {
+			return nil, nil, NewError(fmt.Sprintf("%s: %s", name, vault.FormatSecretKeyError(normErr)), ExitValidationError)
+		}
+		if other, dup := sources[key]; dup {
+			return nil, nil, NewError(fmt.Sprintf("members %q and %q both name secret '%s'", other, name, key), ExitValidationError)
+		}
+		sources[key] = name
+
+		var text string
+		if err := json.Unmarshal(member, &text); err != nil {
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, member); err != nil {
+				return nil, nil, NewError(fmt.Sprintf("%s: invalid JSON value: %v", name, err), ExitValidationError)
+			}
+			text = compact.String()
+		}
+		values[key] = text
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys, values, nil
+}
+
+// SecretPutJSON stores each member of the JSON object data as a secret in
+// the target vault, in key order, and saves the vault once. Keys are
+// normalized as for 'secret put'. Each key's result is reported; keys that
+// fail are skipped and the others are still stored. With ifAbsent, existing
+// secrets are left unchanged; with SetReplace, each stored secret's history
+// is trimmed as by 'secret put --replace'.
+func (c *CLI) SecretPutJSON(data []byte, vaultPath string, fromIndex int, ifAbsent bool) *Error {
+	keys, values, parseErr := parseSecretJSONObject(data)
+	if parseErr != nil {
+		return parseErr
+	}
+
+	if _, err := c.checkFingerprintRequired("secret store"); err != nil {
+		return err
+	}
+
+	// Resolve the vault once, so an interactive selection is asked only once.
+	targetIndex, resolveErr := c.resolveWritableVaultIndex(vaultPath, fromIndex)
+	if resolveErr != nil {
+		return resolveErr
+	}
+
+	var staged []vault.Secret
+	var targets []*secretPutTarget
+	unchanged, failed := 0, 0
+	for _, key := range keys {
+		secret, target, err := c.stageSecretPut(key, values[key], targetIndex, ifAbsent)
+		switch {
+		case err != nil:
+			failed++
+			_, _ = fmt.Fprintf(c.output.Stderr(), "failed: %s: %s\n", key, err.Message)
+		case target == nil:
+			unchanged++
+		default:
+			staged = append(staged, secret)
+			targets = append(targets, target)
+		}
+	}
+
+	if len(staged) > 0 {
+		// Hold the additions in memory so SaveVault writes them in one go.
+		c.vaultResolver.DeferWrites()
+		for _, secret := range staged {
+			if err := c.vaultResolver.AddSecret(secret, targetIndex); err != nil {
+				return NewError(fmt.Sprintf("nothing was stored: %s: failed to add secret: %v", secret.Key, err), ExitVaultError)
+			}
+		}
+		if err := c.vaultResolver.SaveVault(targetIndex); err != nil {
+			return NewError(fmt.Sprintf("nothing was stored: failed to save vault: %v", err), ExitVaultError)
+		}
+		if hookErr := c.runPostWriteHook(targetIndex); hookErr != nil {
+			return hookErr
+		}
+	}
+
+	for _, target := range targets {
+		_, _ = fmt.Fprintf(c.output.Stdout(), "Secret '%s' stored successfully\n", target.key)
+		if c.replace {
+			if trimErr := c.trimReplacedHistory(target); trimErr != nil {
+				return trimErr
+			}
+		}
+	}
+	_, _ = fmt.Fprintf(c.output.Stderr(), "summary: stored=%d unchanged=%d failed=%d\n", len(staged), unchanged, failed)
+	if failed > 0 {
+		return NewError(fmt.Sprintf("%d of %d secrets failed to store", failed, len(keys)), ExitGeneralError)
+	}
+	return nil
+}
+
+// stageSecretPut validates, encrypts and signs value as the new value of key
+// in vault index, without adding it. A nil target means the secret was left
+// unchanged: it exists and ifAbsent is set, or the replace was declined.
+func (c *CLI) stageSecretPut(key, value string, index int, ifAbsent bool) (vault.Secret, *secretPutTarget, *Error) {
+	if sizeErr := c.checkSecretSize(int64(len(value))); sizeErr != nil {
+		return vault.Secret{}, nil, sizeErr
+	}
+	target, err := c.prepareSecretPut(key, "", index+1, ifAbsent)
+	if err != nil || target == nil {
+		return vault.Secret{}, nil, err
+	}
+	if proceed, confirmErr := c.confirmReplace(target); !proceed {
+		return vault.Secret{}, nil, confirmErr
+	}
+	encryptedBase64, err := c.encryptForTarget(target, value)
+	if err != nil {
+		return vault.Secret{}, nil, err
+	}
+	secret, err := c.signNewSecretValue(target, encryptedBase64)
+	if err != nil {
+		return vault.Secret{}, nil, err
+	}
+	return secret, target, nil
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSecretPutJSON_StoresEveryKey(t *testing.T) {
+	cli, mock := newReplaceCLI(t, 0)
+	cli.gpgClient = &prefixDecryptGPGClient{MockGPGClient: NewMockGPGClient(), prefix: "encrypted_to_base64pubkey_"}
+
+	input := `{"db_url": "postgres://db", "MyApp::api_key": "k", "PORTS": [80, 443]}`
+	if err := cli.SecretPutJSON([]byte(input), "", 1, false); err != nil {
+		t.Fatalf("SecretPutJSON failed: %v", err)
+	}
+	if len(mock.SavedVaults) != 1 {
+		t.Errorf("expected a single save, got %v", mock.SavedVaults)
+	}
+
+	// Values as printed by 'secret get --json', where JSON values are inlined
+	want := map[string]string{"DB_URL": `"postgres://db"`, "myapp::API_KEY": `"k"`, "PORTS": `[80,443]`}
+	for key, value := range want {
+		stdout := cli.output.Stdout().(*strings.Builder)
+		stdout.Reset()
+		if err := cli.SecretGet(key, true, false, true, "", 1); err != nil {
+			t.Fatalf("SecretGet(%s) failed: %v", key, err)
+		}
+		var got []struct {
+			Value json.RawMessage `json:"value"`
+		}
+		if err := json.Unmarshal([]byte(stdout.String()), &got); err != nil {
+			t.Fatalf("invalid json output for %s: %v\n%s", key, err, stdout.String())
+		}
+		var compact bytes.Buffer
+		if len(got) != 1 || json.Compact(&compact, got[0].Value) != nil || compact.String() != value {
+			t.Errorf("%s = %s, want %s", key, stdout.String(), value)
+		}
+	}
+}
+
+func TestSecretPutJSON_IfAbsentAndFailures(t *testing.T) {
+	cli, mock := newReplaceCLI(t, 0)
+	if err := cli.SecretPutValue("DB_URL", "", 1, "old", false); err != nil {
+		t.Fatalf("SecretPutValue failed: %v", err)
+	}
+	mock.SavedVaults = nil
+	cli.config.MaxSecretSize = 8
+
+	err := cli.SecretPutJSON([]byte(`{"DB_URL": "new", "API_KEY": "k", "BIG": "much too large"}`), "", 1, true)
+	if err == nil || !strings.Contains(err.Message, "1 of 3") {
+		t.Fatalf("expected one failed key, got %v", err)
+	}
+	if n := len(mock.Secrets[0]["DB_URL"].Values); n != 1 {
+		t.Errorf("--if-absent must leave DB_URL unchanged, got %d values", n)
+	}
+	if _, ok := mock.Secrets[0]["API_KEY"]; !ok {
+		t.Error("expected API_KEY to be stored despite the other failure")
+	}
+	if _, ok := mock.Secrets[0]["BIG"]; ok {
+		t.Error("expected the oversized value to be refused")
+	}
+	stderr := cli.output.Stderr().(*strings.Builder).String()
+	if !strings.Contains(stderr, "failed: BIG:") || !strings.Contains(stderr, "summary: stored=1 unchanged=1 failed=1") {
+		t.Errorf("unexpected per-key report:\n%s", stderr)
+	}
+}
+
+func TestParseSecretJSONObject_Errors(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{"array", `["a"]`, "not a JSON object"},
+		{"null", `null`, "not a JSON object"},
+		{"empty", `{}`, "no members"},
+		{"invalid key", `{"bad-key": "x"}`, "bad-key"},
+		{"duplicate after normalizing", `{"db_url": "a", "DB_URL": "b"}`, "both name secret 'DB_URL'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := parseSecretJSONObject([]byte(tt.input))
+			if err == nil || !strings.Contains(err.Message, tt.want) {
+				t.Errorf("got %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
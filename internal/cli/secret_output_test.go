@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+)
+
+// decryptCalls are the gpg.Client methods whose results are plaintext.
+var decryptCalls = map[string]bool{
+	"DecryptWithAgent":   true,
+	"DecryptSecret":      true,
+	"DecryptSecretValue": true,
+}
+
+// stdoutWriters are output.Handler methods that print to stdout without the
+// secret value guard.
+var stdoutWriters = map[string]bool{
+	"WriteData": true,
+	"WriteLine": true,
+	"Success":   true,
+	"Successf":  true,
+}
+
+// findPlaintextLeaks reports every place in file where a value derived from a
+// decryption call reaches stdout without going through WriteSecretValue. The
+// analysis is per function: plaintext is tracked through assignments, range
+// loops and composite values, and any stdout sink that mentions a tainted
+// variable is reported.
+func findPlaintextLeaks(fset *token.FileSet, file *ast.File) []string {
+	var leaks []string
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+
+		tainted := map[string]bool{}
+		encoders := map[string]bool{} // json encoders writing to stdout
+
+		mentions := func(n ast.Node, set map[string]bool) bool {
+			found := false
+			ast.Inspect(n, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok && set[id.Name] {
+					found = true
+				}
+				return !found
+			})
+			return found
+		}
+		decrypts := func(n ast.Node) bool {
+			found := false
+			ast.Inspect(n, func(n ast.Node) bool {
+				if call, ok := n.(*ast.CallExpr); ok {
+					if sel, ok := call.Fun.(*ast.SelectorExpr); ok && decryptCalls[sel.Sel.Name] {
+						found = true
+					}
+				}
+				return !found
+			})
+			return found
+		}
+		isStdout := func(n ast.Node) bool {
+			found := false
+			ast.Inspect(n, func(n ast.Node) bool {
+				if sel, ok := n.(*ast.SelectorExpr); ok && sel.Sel.Name == "Stdout" {
+					found = true
+				}
+				return !found
+			})
+			return found
+		}
+		mark := func(exprs []ast.Expr, set map[string]bool) bool {
+			changed := false
+			for _, e := range exprs {
+				if id, ok := e.(*ast.Ident); ok && id.Name != "_" && !set[id.Name] {
+					set[id.Name] = true
+					changed = true
+				}
+			}
+			return changed
+		}
+
+		// Propagate taint to a fixed point.
+		for changed := true; changed; {
+			changed = false
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.AssignStmt:
+					for _, rhs := range n.Rhs {
+						if decrypts(rhs) || mentions(rhs, tainted) {
+							changed = mark(n.Lhs, tainted) || changed
+						}
+						if call, ok := rhs.(*ast.CallExpr); ok && isStdout(call) {
+							if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "NewEncoder" {
+								changed = mark(n.Lhs, encoders) || changed
+							}
+						}
+					}
+				case *ast.RangeStmt:
+					if mentions(n.X, tainted) {
+						changed = mark([]ast.Expr{n.Key, n.Value}, tainted) || changed
+					}
+				}
+				return true
+			})
+		}
+
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sink := false
+			switch fun := call.Fun.(type) {
+			case *ast.SelectorExpr:
+				switch {
+				case fun.Sel.Name == "WriteSecretValue":
+					return false
+				case stdoutWriters[fun.Sel.Name]:
+					sink = true
+				case fun.Sel.Name == "Encode" && mentions(fun.X, encoders):
+					sink = true
+				case fun.Sel.Name == "Write" && isStdout(fun.X):
+					sink = true
+				case strings.HasPrefix(fun.Sel.Name, "Fprint") && len(call.Args) > 0 && isStdout(call.Args[0]):
+					sink = true
+				}
+			}
+			if !sink {
+				return true
+			}
+			for _, arg := range call.Args {
+				if decrypts(arg) || mentions(arg, tainted) {
+					leaks = append(leaks, fmt.Sprintf("%s: %s prints decrypted data outside WriteSecretValue", fset.Position(call.Pos()), fn.Name.Name))
+					break
+				}
+			}
+			return true
+		})
+	}
+	return leaks
+}
+
+// TestSecretValues_OnlyPrintedViaWriteSecretValue guards the invariant that
+// output.WriteSecretValue is the single code path printing secret values.
+func TestSecretValues_OnlyPrintedViaWriteSecretValue(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	checked := 0
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", name, err)
+		}
+		for _, leak := range findPlaintextLeaks(fset, file) {
+			t.Error(leak)
+		}
+		checked++
+	}
+	if checked == 0 {
+		t.Fatal("no source files checked")
+	}
+}
+
+// TestFindPlaintextLeaks_DetectsLeak makes sure the guard above is not
+// vacuous.
+func TestFindPlaintextLeaks_DetectsLeak(t *testing.T) {
+	src := `package cli
+
+func (c *CLI) leaky(ct []byte) {
+	plaintext, _ := c.gpgClient.DecryptWithAgent(ct, "")
+	value := string(plaintext)
+	fmt.Fprintf(c.output.Stdout(), "%s\n", value)
+
+	enc := json.NewEncoder(c.output.Stdout())
+	_ = enc.Encode(map[string]string{"v": value})
+	c.output.WriteLine(value)
+	_ = c.output.WriteSecretValue([]byte(value))
+	fmt.Fprintf(c.output.Stdout(), "done\n")
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "leaky.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaks := findPlaintextLeaks(fset, file); len(leaks) != 3 {
+		t.Errorf("expected 3 leaks, got %d: %v", len(leaks), leaks)
+	}
+}
+
+func TestWriteSecretValue_RedactStdout(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	cli := &CLI{output: output.NewHandler(stdout, &bytes.Buffer{})}
+	cli.SetRedactStdout(true)
+
+	err := cli.writeSecretValue("s3cret\n")
+	if err == nil || !errors.Is(cli.output.WriteSecretValue(nil), output.ErrSecretValueRedacted) {
+		t.Fatalf("expected redaction error, got %v", err)
+	}
+	if stdout.Len() != 0 {
+		t.Fatalf("redacted value was written: %q", stdout.String())
+	}
+
+	cli.output.AllowSecretValues()
+	if err := cli.writeSecretValue("s3cret\n"); err != nil {
+		t.Fatalf("writeSecretValue after AllowSecretValues failed: %v", err)
+	}
+	if stdout.String() != "s3cret\n" {
+		t.Errorf("expected value with single newline, got %q", stdout.String())
+	}
+}
@@ -1,6 +1,11 @@
 package cli
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
 	"strings"
 	"testing"
 
@@ -66,7 +71,7 @@ func TestSecretStore_MissingVaultFile_FriendlyError(t *testing.T) {
 	// Vault index 1 is configured but did not load (no matching available path).
 	cli, stderr := newSecretStoreCLI(t, []string{"/vault1.yaml"}, nil)
 
-	err := cli.SecretPut("PULUMI_CONFIG_PASSPHRASE", "", 1, "v")
+	err := cli.SecretPut("PULUMI_CONFIG_PASSPHRASE", "", 1, "v", false)
 
 	if err == nil {
 		t.Fatal("SecretPut against a missing vault: got nil error, want a friendly failure")
@@ -104,9 +109,649 @@ func TestSecretStore_ExistingVault_NoMissingError(t *testing.T) {
 	// Vault index 1 is both configured and available (loaded).
 	cli, _ := newSecretStoreCLI(t, []string{"/vault1.yaml"}, []string{"/vault1.yaml"})
 
-	err := cli.SecretPut("PULUMI_CONFIG_PASSPHRASE", "", 1, "secret-value")
+	err := cli.SecretPut("PULUMI_CONFIG_PASSPHRASE", "", 1, "secret-value", false)
 
 	if err != nil && strings.Contains(err.Message, "does not exist") {
 		t.Errorf("vault is available but SecretPut returned a 'does not exist' error: %q", err.Message)
 	}
 }
+
+// TestSecretStore_IfAbsent covers --if-absent against absent, present and
+// deleted secrets.
+func TestSecretStore_IfAbsent(t *testing.T) {
+	const fp = "MYFINGERPRINT"
+
+	tests := []struct {
+		name      string
+		existing  *vault.Secret
+		wantAdded bool
+		wantErr   string
+		wantOut   string
+	}{
+		{
+			name:      "absent",
+			wantAdded: true,
+			wantOut:   "stored successfully",
+		},
+		{
+			name: "present",
+			existing: &vault.Secret{Key: "DB_URL", Values: []vault.SecretValue{
+				{AvailableTo: []string{fp}, Value: "old"},
+			}},
+			wantOut: "already exists; not modified",
+		},
+		{
+			name: "present but not readable",
+			existing: &vault.Secret{Key: "DB_URL", Values: []vault.SecretValue{
+				{AvailableTo: []string{"SOMEONEELSE"}, Value: "old"},
+			}},
+			wantOut: "already exists; not modified",
+		},
+		{
+			name: "deleted",
+			existing: &vault.Secret{Key: "DB_URL", Values: []vault.SecretValue{
+				{AvailableTo: []string{fp}, Value: "old"},
+				{Deleted: true},
+			}},
+			wantErr: "has been deleted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, _ := newSecretStoreCLI(t, []string{"/vault1.yaml"}, []string{"/vault1.yaml"})
+			mock := cli.vaultResolver.(*MockVaultResolver)
+			id := vault.Identity{Fingerprint: fp, PublicKey: "base64pubkey", Algorithm: "RSA", AlgorithmBits: 4096}
+			mock.Identities[fp] = id
+			mock.IdentitiesByVault[0] = map[string]vault.Identity{fp: id}
+			if tt.existing != nil {
+				mock.Secrets[0] = map[string]vault.Secret{tt.existing.Key: *tt.existing}
+			}
+			added := false
+			mock.AddSecretFunc = func(vault.Secret, int) error {
+				added = true
+				return nil
+			}
+
+			err := cli.SecretPut("DB_URL", "", 1, "new-value", true)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Message, tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("SecretPut failed: %v", err)
+			}
+			if added != tt.wantAdded {
+				t.Errorf("secret added = %v, want %v", added, tt.wantAdded)
+			}
+			if len(mock.SavedVaults) > 0 && !tt.wantAdded {
+				t.Errorf("vault should not be saved, got saves %v", mock.SavedVaults)
+			}
+			if out := cli.output.Stdout().(*strings.Builder).String(); !strings.Contains(out, tt.wantOut) {
+				t.Errorf("expected output containing %q, got %q", tt.wantOut, out)
+			}
+		})
+	}
+}
+
+// TestLookupSecretEnv covers set, empty and unset variables for --from-env.
+func TestLookupSecretEnv(t *testing.T) {
+	tests := []struct {
+		name       string
+		set        bool
+		value      string
+		allowEmpty bool
+		want       string
+		wantErr    string
+	}{
+		{name: "set", set: true, value: "s3cret", want: "s3cret"},
+		{name: "empty", set: true, wantErr: "is empty"},
+		{name: "empty allowed", set: true, allowEmpty: true, want: ""},
+		{name: "unset", wantErr: "is not set"},
+		{name: "unset with allow empty", allowEmpty: true, wantErr: "is not set"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const envName = "DOTSECENV_TEST_FROM_ENV"
+			if tt.set {
+				t.Setenv(envName, tt.value)
+			} else {
+				t.Setenv(envName, "")
+				_ = os.Unsetenv(envName)
+			}
+
+			got, err := LookupSecretEnv(envName, tt.allowEmpty)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Message, tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				if !strings.Contains(err.Message, envName) {
+					t.Errorf("error %q should name the variable", err.Message)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LookupSecretEnv failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("value = %q, want %q", got, tt.want)
+			}
+			if _, ok := os.LookupEnv(envName); ok {
+				t.Error("expected the variable to be unset once read")
+			}
+		})
+	}
+}
+
+// TestSecretPutValue_StoresEmptyValue verifies that an empty value is stored
+// as given rather than triggering a read from stdin.
+func TestSecretPutValue_StoresEmptyValue(t *testing.T) {
+	const fp = "MYFINGERPRINT"
+
+	cli, _ := newSecretStoreCLI(t, []string{"/vault1.yaml"}, []string{"/vault1.yaml"})
+	cli.stdin = strings.NewReader("from-stdin\n")
+	mock := cli.vaultResolver.(*MockVaultResolver)
+	id := vault.Identity{Fingerprint: fp, PublicKey: "base64pubkey", Algorithm: "RSA", AlgorithmBits: 4096}
+	mock.Identities[fp] = id
+	mock.IdentitiesByVault[0] = map[string]vault.Identity{fp: id}
+	var stored []vault.SecretValue
+	mock.AddSecretFunc = func(s vault.Secret, _ int) error {
+		stored = s.Values
+		return nil
+	}
+
+	if err := cli.SecretPutValue("DB_URL", "", 1, "", false); err != nil {
+		t.Fatalf("SecretPutValue failed: %v", err)
+	}
+	if len(stored) != 1 {
+		t.Fatalf("expected one stored value, got %d", len(stored))
+	}
+	// The mock "encrypts" by appending the plaintext to a fixed prefix.
+	ciphertext, decErr := base64.StdEncoding.DecodeString(stored[0].Value)
+	if decErr != nil {
+		t.Fatalf("decode stored value: %v", decErr)
+	}
+	if got := string(ciphertext); got != "encrypted_to_base64pubkey_" {
+		t.Errorf("stored ciphertext = %q, want the empty plaintext to be encrypted", got)
+	}
+}
+
+func TestReadPipedSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "no trailing newline", input: "value", want: "value"},
+		{name: "one trailing newline", input: "value\n", want: "value"},
+		{name: "CRLF", input: "value\r\n", want: "value"},
+		{name: "two trailing newlines keep one", input: "value\n\n", want: "value\n"},
+		{name: "multi-line", input: "line1\nline2\n", want: "line1\nline2"},
+		{name: "surrounding spaces kept", input: "  value  \n", want: "  value  "},
+		{name: "empty", input: "", wantErr: true},
+		{name: "only newline", input: "\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadPipedSecret(strings.NewReader(tt.input))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ReadPipedSecret(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecretPut_PipedStdinReadsWholeStream(t *testing.T) {
+	const fp = "MYFINGERPRINT"
+
+	for _, input := range []string{"line1\nline2", "line1\nline2\n"} {
+		cli, _ := newSecretStoreCLI(t, []string{"/vault1.yaml"}, []string{"/vault1.yaml"})
+		cli.stdin = strings.NewReader(input)
+		mock := cli.vaultResolver.(*MockVaultResolver)
+		id := vault.Identity{Fingerprint: fp, PublicKey: "base64pubkey", Algorithm: "RSA", AlgorithmBits: 4096}
+		mock.Identities[fp] = id
+		mock.IdentitiesByVault[0] = map[string]vault.Identity{fp: id}
+		var stored []vault.SecretValue
+		mock.AddSecretFunc = func(s vault.Secret, _ int) error {
+			stored = s.Values
+			return nil
+		}
+
+		if err := cli.SecretPut("DB_URL", "", 1, "", false); err != nil {
+			t.Fatalf("SecretPut(%q) failed: %v", input, err)
+		}
+		if len(stored) != 1 {
+			t.Fatalf("expected one stored value, got %d", len(stored))
+		}
+		ciphertext, decErr := base64.StdEncoding.DecodeString(stored[0].Value)
+		if decErr != nil {
+			t.Fatalf("decode stored value: %v", decErr)
+		}
+		if got := string(ciphertext); got != "encrypted_to_base64pubkey_line1\nline2" {
+			t.Errorf("stdin %q stored %q, want both lines without the trailing newline", input, got)
+		}
+	}
+}
+
+// newReplaceCLI returns a non-interactive CLI whose mock vault appends stored
+// values to the secret's history, as the real vault does.
+func newReplaceCLI(t *testing.T, maxHistory int) (*CLI, *MockVaultResolver) {
+	t.Helper()
+	cli, mock, _ := newImportCLI(t)
+	cli.hasTTY = func() bool { return false }
+	cli.config.MaxHistory = maxHistory
+	mock.AddSecretFunc = func(s vault.Secret, index int) error {
+		if existing, ok := mock.Secrets[index][s.Key]; ok {
+			existing.Values = append(existing.Values, s.Values...)
+			s = existing
+		}
+		if mock.Secrets[index] == nil {
+			mock.Secrets[index] = make(map[string]vault.Secret)
+		}
+		mock.Secrets[index][s.Key] = s
+		return nil
+	}
+	return cli, mock
+}
+
+func TestSecretPutValue_ReplaceHonorsMaxHistory(t *testing.T) {
+	cli, mock := newReplaceCLI(t, 2)
+	cli.SetReplace(true)
+
+	for _, value := range []string{"v1", "v2", "v3", "v4"} {
+		if err := cli.SecretPutValue("DB_URL", "", 1, value, false); err != nil {
+			t.Fatalf("SecretPutValue(%s) failed: %v", value, err)
+		}
+	}
+
+	values := mock.Secrets[0]["DB_URL"].Values
+	if len(values) != 2 {
+		t.Fatalf("expected history trimmed to max_history=2, got %d values", len(values))
+	}
+	if values[1].Value != base64.StdEncoding.EncodeToString([]byte("encrypted_to_base64pubkey_v4")) {
+		t.Errorf("expected the newest value to be v4, got %q", values[1].Value)
+	}
+}
+
+func TestSecretPutValue_WithoutReplaceKeepsHistory(t *testing.T) {
+	cli, mock := newReplaceCLI(t, 2)
+
+	for _, value := range []string{"v1", "v2", "v3"} {
+		if err := cli.SecretPutValue("DB_URL", "", 1, value, false); err != nil {
+			t.Fatalf("SecretPutValue(%s) failed: %v", value, err)
+		}
+	}
+	if n := len(mock.Secrets[0]["DB_URL"].Values); n != 3 {
+		t.Errorf("expected every value kept without --replace, got %d", n)
+	}
+}
+
+func TestSecretPutValue_ReplaceRejectsNegativeMaxHistory(t *testing.T) {
+	cli, mock := newReplaceCLI(t, -1)
+	cli.SetReplace(true)
+
+	err := cli.SecretPutValue("DB_URL", "", 1, "v1", false)
+	if err == nil || err.ExitCode != ExitConfigError {
+		t.Fatalf("expected a config error, got %v", err)
+	}
+	if len(mock.Secrets[0]) != 0 {
+		t.Errorf("expected nothing stored, got %v", mock.Secrets[0])
+	}
+}
+
+func TestSecretPutValue_MaxSecretSize(t *testing.T) {
+	const fp = "MYFINGERPRINT"
+
+	cli, _ := newSecretStoreCLI(t, []string{"/vault1.yaml"}, []string{"/vault1.yaml"})
+	mock := cli.vaultResolver.(*MockVaultResolver)
+	id := vault.Identity{Fingerprint: fp, PublicKey: "base64pubkey", Algorithm: "RSA", AlgorithmBits: 4096}
+	mock.Identities[fp] = id
+	mock.IdentitiesByVault[0] = map[string]vault.Identity{fp: id}
+
+	atLimit := strings.Repeat("x", int(config.DefaultMaxSecretSize))
+	if err := cli.SecretPutValue("AT_LIMIT", "", 1, atLimit, false); err != nil {
+		t.Fatalf("value at the default limit should be stored: %v", err)
+	}
+
+	err := cli.SecretPutValue("OVER_LIMIT", "", 1, atLimit+"x", false)
+	if err == nil || err.ExitCode != ExitValidationError {
+		t.Fatalf("expected validation error for value over the limit, got %v", err)
+	}
+	if !strings.Contains(err.Message, "--allow-large") {
+		t.Errorf("error should mention --allow-large: %q", err.Message)
+	}
+	if _, stored := mock.Secrets[0]["OVER_LIMIT"]; stored {
+		t.Error("value over the limit must not be stored")
+	}
+
+	cli.config.MaxSecretSize = 4
+	if err := cli.SecretPut("PIPED", "", 1, "12345", false); err == nil {
+		t.Error("expected piped value over max_secret_size to be refused")
+	}
+
+	cli.SetAllowLarge(true)
+	if err := cli.SecretPut("PIPED", "", 1, "12345", false); err != nil {
+		t.Fatalf("--allow-large should store the value: %v", err)
+	}
+}
+
+// prefixDecryptGPGClient reverses MockGPGClient.EncryptToRecipients, which
+// prefixes the plaintext with "encrypted_to_<recipients>_".
+type prefixDecryptGPGClient struct {
+	*MockGPGClient
+	prefix string
+}
+
+func (m *prefixDecryptGPGClient) DecryptWithAgent(ciphertext []byte, fingerprint string) ([]byte, error) {
+	return []byte(strings.TrimPrefix(string(ciphertext), m.prefix)), nil
+}
+
+func TestSecretPutValue_CompressedAndPlainUnderOneKey(t *testing.T) {
+	cli, mock := newReplaceCLI(t, 0)
+	cli.gpgClient = &prefixDecryptGPGClient{MockGPGClient: NewMockGPGClient(), prefix: "encrypted_to_base64pubkey_"}
+
+	plain := "short value"
+	verbose := strings.Repeat("-----BEGIN CERTIFICATE-----\nMIIB...\n-----END CERTIFICATE-----\n", 50)
+	if err := cli.SecretPutValue("CERT", "", 1, plain, false); err != nil {
+		t.Fatalf("SecretPutValue failed: %v", err)
+	}
+	cli.SetCompress(true)
+	if err := cli.SecretPutValue("CERT", "", 1, verbose, false); err != nil {
+		t.Fatalf("SecretPutValue --compress failed: %v", err)
+	}
+
+	values := mock.Secrets[0]["CERT"].Values
+	if len(values) != 2 || values[0].Compressed || !values[1].Compressed {
+		t.Fatalf("expected a plain then a compressed value, got %+v", values)
+	}
+	if len(values[1].Value) >= len(base64.StdEncoding.EncodeToString([]byte(verbose))) {
+		t.Error("expected the compressed value to be smaller than the plaintext")
+	}
+	// The flag is signed: flipping it changes the value's hash
+	flipped := values[1]
+	flipped.Compressed = false
+	if vault.ComputeSecretValueHash(&flipped, "CERT", 4096) == values[1].Hash {
+		t.Error("expected the compressed flag to be covered by the value hash")
+	}
+
+	stdout := cli.output.Stdout().(*strings.Builder)
+	stdout.Reset()
+	if err := cli.SecretGet("CERT", true, false, true, "", 1); err != nil {
+		t.Fatalf("SecretGet --all failed: %v", err)
+	}
+	var got []SecretValueJSON
+	if err := json.Unmarshal([]byte(stdout.String()), &got); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, stdout.String())
+	}
+	if len(got) != 2 || got[0].Value != verbose || got[1].Value != plain {
+		t.Errorf("expected both values decrypted newest first, got %+v", got)
+	}
+}
+
+func TestSecretGet_CompressedValueOverMaxSecretSize(t *testing.T) {
+	cli, _ := newReplaceCLI(t, 0)
+	cli.gpgClient = &prefixDecryptGPGClient{MockGPGClient: NewMockGPGClient(), prefix: "encrypted_to_base64pubkey_"}
+	cli.SetCompress(true)
+
+	if err := cli.SecretPutValue("BOMB", "", 1, strings.Repeat("0", 64<<10), false); err != nil {
+		t.Fatalf("SecretPutValue --compress failed: %v", err)
+	}
+
+	// The payload is a few hundred bytes but inflates past the limit
+	cli.config.MaxSecretSize = 1024
+	err := cli.SecretGet("BOMB", false, false, false, "", 0)
+	if err == nil || err.ExitCode != ExitValidationError {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	if !strings.Contains(err.Message, "max_secret_size (1024 bytes)") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSecretPutValue_SourceShownInHistory(t *testing.T) {
+	cli, mock := newReplaceCLI(t, 0)
+	cli.gpgClient = &prefixDecryptGPGClient{MockGPGClient: NewMockGPGClient(), prefix: "encrypted_to_base64pubkey_"}
+
+	if err := cli.SetSource("rotated\nby-ci"); err == nil || err.ExitCode != ExitValidationError {
+		t.Fatalf("expected a source with a newline to be refused, got %v", err)
+	}
+	if err := cli.SetSource(strings.Repeat("x", maxValueSource+1)); err == nil {
+		t.Fatal("expected an overlong source to be refused")
+	}
+
+	if err := cli.SecretPutValue("TOKEN", "", 1, "by-hand", false); err != nil {
+		t.Fatalf("SecretPutValue failed: %v", err)
+	}
+	if err := cli.SetSource("rotated-by-ci"); err != nil {
+		t.Fatalf("SetSource failed: %v", err)
+	}
+	if err := cli.SecretPutValue("TOKEN", "", 1, "by-ci", false); err != nil {
+		t.Fatalf("SecretPutValue --source failed: %v", err)
+	}
+
+	values := mock.Secrets[0]["TOKEN"].Values
+	if len(values) != 2 || values[0].Source != "" || values[1].Source != "rotated-by-ci" {
+		t.Fatalf("expected the source on the second value only, got %+v", values)
+	}
+	// The source is signed: changing it changes the value's hash
+	relabelled := values[1]
+	relabelled.Source = "human"
+	if vault.ComputeSecretValueHash(&relabelled, "TOKEN", 4096) == values[1].Hash {
+		t.Error("expected the source to be covered by the value hash")
+	}
+
+	stdout := cli.output.Stdout().(*strings.Builder)
+	stdout.Reset()
+	if err := cli.SecretGet("TOKEN", true, false, true, "", 1); err != nil {
+		t.Fatalf("SecretGet --all --json failed: %v", err)
+	}
+	var got []SecretValueJSON
+	if err := json.Unmarshal([]byte(stdout.String()), &got); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, stdout.String())
+	}
+	if len(got) != 2 || got[0].Source != "rotated-by-ci" || got[1].Source != "" {
+		t.Errorf("expected the source in the history, newest first, got %+v", got)
+	}
+
+	stdout.Reset()
+	if err := cli.SecretGet("TOKEN", true, false, false, "", 1); err != nil {
+		t.Fatalf("SecretGet --all failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "[rotated-by-ci]: by-ci") || strings.Contains(lines[1], "[") {
+		t.Errorf("expected only the sourced value tagged, got:\n%s", stdout.String())
+	}
+}
+
+func TestSecretShare_KeepsSource(t *testing.T) {
+	cli, mock := newReplaceCLI(t, 0)
+	cli.gpgClient = &prefixDecryptGPGClient{MockGPGClient: NewMockGPGClient(), prefix: "encrypted_to_"}
+	bob := vault.Identity{Fingerprint: "BOB", PublicKey: "bobkey", Algorithm: "RSA", AlgorithmBits: 4096}
+	mock.Identities["BOB"] = bob
+	mock.IdentitiesByVault[0]["BOB"] = bob
+
+	if err := cli.SetSource("imported-from:aws"); err != nil {
+		t.Fatalf("SetSource failed: %v", err)
+	}
+	if err := cli.SecretPutValue("TOKEN", "", 1, "v1", false); err != nil {
+		t.Fatalf("SecretPutValue failed: %v", err)
+	}
+	// Share checks access against the vault file
+	mock.Managers = map[int]*vault.Manager{0: newTestManager(t, vault.Vault{Secrets: []vault.Secret{mock.Secrets[0]["TOKEN"]}})}
+	if err := cli.SecretShare("TOKEN", "BOB", 0); err != nil {
+		t.Fatalf("SecretShare failed: %v", err)
+	}
+
+	values := mock.Secrets[0]["TOKEN"].Values
+	if len(values) != 2 || strings.Join(values[1].AvailableTo, ",") != "BOB,MYFINGERPRINT" {
+		t.Fatalf("expected a shared second value, got %+v", values)
+	}
+
+	stdout := cli.output.Stdout().(*strings.Builder)
+	stdout.Reset()
+	if err := cli.SecretGet("TOKEN", false, false, true, "", 1); err != nil {
+		t.Fatalf("SecretGet --json failed: %v", err)
+	}
+	var got SecretValueJSON
+	if err := json.Unmarshal([]byte(stdout.String()), &got); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, stdout.String())
+	}
+	if got.Source != "imported-from:aws" {
+		t.Errorf("expected the source to survive sharing, got %+v", got)
+	}
+}
+
+// scriptTerminal makes cli read values as typed at a terminal, one entry
+// of lines per prompt, and returns how many were read.
+func scriptTerminal(cli *CLI, lines ...string) *int {
+	reads := 0
+	cli.readHidden = func() (string, error) {
+		if reads >= len(lines) {
+			return "", errors.New("no more input")
+		}
+		reads++
+		return lines[reads-1], nil
+	}
+	return &reads
+}
+
+func TestSecretPut_ConfirmsTypedValue(t *testing.T) {
+	cli, mock := newReplaceCLI(t, 0)
+
+	// A new secret is asked for twice; a mismatch stores nothing
+	reads := scriptTerminal(cli, "hunter2", "hunter3")
+	err := cli.SecretPut("DB_PASSWORD", "", 1, "", false)
+	if err == nil || err.ExitCode != ExitValidationError || !strings.Contains(err.Message, "do not match") {
+		t.Fatalf("expected a mismatch to abort, got %v", err)
+	}
+	if *reads != 2 {
+		t.Errorf("expected two prompts, got %d", *reads)
+	}
+	if _, stored := mock.Secrets[0]["DB_PASSWORD"]; stored {
+		t.Fatal("expected nothing stored after a mismatch")
+	}
+
+	scriptTerminal(cli, "hunter2", "hunter2")
+	if err := cli.SecretPut("DB_PASSWORD", "", 1, "", false); err != nil {
+		t.Fatalf("expected matching entries to be stored, got %v", err)
+	}
+	if len(mock.Secrets[0]["DB_PASSWORD"].Values) != 1 {
+		t.Fatalf("expected the value stored, got %+v", mock.Secrets[0]["DB_PASSWORD"])
+	}
+
+	// An existing secret is asked for once, unless --confirm is given
+	reads = scriptTerminal(cli, "rotated")
+	if err := cli.SecretPut("DB_PASSWORD", "", 1, "", false); err != nil {
+		t.Fatalf("SecretPut of an existing secret failed: %v", err)
+	}
+	if *reads != 1 {
+		t.Errorf("expected one prompt for an existing secret, got %d", *reads)
+	}
+	cli.SetConfirmValue(true)
+	scriptTerminal(cli, "rotated", "rotatde")
+	if err := cli.SecretPut("DB_PASSWORD", "", 1, "", false); err == nil {
+		t.Error("expected --confirm to catch a mismatch on an existing secret")
+	}
+
+	// --confirm=false never asks twice
+	cli.SetConfirmValue(false)
+	reads = scriptTerminal(cli, "fresh")
+	if err := cli.SecretPut("NEW_TOKEN", "", 1, "", false); err != nil {
+		t.Fatalf("SecretPut with --confirm=false failed: %v", err)
+	}
+	if *reads != 1 {
+		t.Errorf("expected one prompt with --confirm=false, got %d", *reads)
+	}
+}
+
+func TestSecretPut_PipedValueNotConfirmed(t *testing.T) {
+	cli, mock := newReplaceCLI(t, 0)
+	cli.SetConfirmValue(true)
+	cli.stdin = strings.NewReader("piped-value")
+
+	if err := cli.SecretPut("PIPED", "", 1, "", false); err != nil {
+		t.Fatalf("expected a piped value to be stored without confirmation, got %v", err)
+	}
+	if len(mock.Secrets[0]["PIPED"].Values) != 1 {
+		t.Fatalf("expected the value stored, got %+v", mock.Secrets[0]["PIPED"])
+	}
+}
+
+// newDefaultRecipientsCLI returns a store CLI for one vault whose
+// default_recipients are recipients, holding the caller's identity and the
+// identities in present. Stored values are returned through the pointer.
+func newDefaultRecipientsCLI(t *testing.T, recipients, present []string) (*CLI, *[]vault.SecretValue) {
+	t.Helper()
+	cli, _ := newSecretStoreCLI(t, []string{"/team.yaml"}, []string{"/team.yaml"})
+	mock := cli.vaultResolver.(*MockVaultResolver)
+	mock.VaultEntries[0].DefaultRecipients = recipients
+	mock.IdentitiesByVault[0] = map[string]vault.Identity{}
+	for _, fp := range append([]string{"MYFINGERPRINT"}, present...) {
+		id := vault.Identity{Fingerprint: fp, PublicKey: "key" + fp, Algorithm: "RSA", AlgorithmBits: 4096}
+		mock.Identities[fp] = id
+		mock.IdentitiesByVault[0][fp] = id
+	}
+	var stored []vault.SecretValue
+	mock.AddSecretFunc = func(s vault.Secret, _ int) error {
+		stored = s.Values
+		return nil
+	}
+	return cli, &stored
+}
+
+func TestSecretPutValue_DefaultRecipients(t *testing.T) {
+	cli, stored := newDefaultRecipientsCLI(t, []string{"BOBFP", "ALICEFP", "MYFINGERPRINT", "BOBFP"}, []string{"ALICEFP", "BOBFP"})
+
+	if err := cli.SecretPutValue("DB_URL", "", 1, "v", false); err != nil {
+		t.Fatalf("SecretPutValue failed: %v", err)
+	}
+	if len(*stored) != 1 {
+		t.Fatalf("expected one stored value, got %d", len(*stored))
+	}
+	value := (*stored)[0]
+	if want := []string{"ALICEFP", "BOBFP", "MYFINGERPRINT"}; !slices.Equal(value.AvailableTo, want) {
+		t.Errorf("available_to = %v, want %v", value.AvailableTo, want)
+	}
+	ciphertext, _ := base64.StdEncoding.DecodeString(value.Value)
+	if got, want := string(ciphertext), "encrypted_to_keyMYFINGERPRINT_keyBOBFP_keyALICEFP_v"; got != want {
+		t.Errorf("ciphertext = %q, want %q", got, want)
+	}
+}
+
+func TestSecretPutValue_NoDefaultRecipients(t *testing.T) {
+	cli, stored := newDefaultRecipientsCLI(t, []string{"ALICEFP"}, []string{"ALICEFP"})
+	cli.SetNoDefaultRecipients(true)
+
+	if err := cli.SecretPutValue("DB_URL", "", 1, "v", false); err != nil {
+		t.Fatalf("SecretPutValue failed: %v", err)
+	}
+	if got := (*stored)[0].AvailableTo; !slices.Equal(got, []string{"MYFINGERPRINT"}) {
+		t.Errorf("available_to = %v, want the caller only", got)
+	}
+}
+
+func TestSecretPutValue_DefaultRecipientNotInVault(t *testing.T) {
+	cli, stored := newDefaultRecipientsCLI(t, []string{"ALICEFP", "CAROLFP"}, []string{"ALICEFP"})
+
+	err := cli.SecretPutValue("DB_URL", "", 1, "v", false)
+	if err == nil || err.ExitCode != ExitVaultError || !strings.Contains(err.Message, "CAROLFP") {
+		t.Fatalf("expected a vault error naming CAROLFP, got %v", err)
+	}
+	if len(*stored) != 0 {
+		t.Error("nothing must be stored when a default recipient is missing")
+	}
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/gpg"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// selfTestSecretKey is the secret stored and read back by SelfTest.
+const selfTestSecretKey = "SELFTEST_SECRET"
+
+// SelfTestStep is the outcome of one SelfTest step.
+type SelfTestStep struct {
+	Name string
+	Err  error
+}
+
+// SelfTest runs the crypto pipeline end to end against an in-memory key and a
+// temporary vault: it generates a key, creates a vault, adds the identity,
+// stores and reads back a secret, and verifies every signature in the vault.
+// The user's keyring, config and vaults are never touched. Each step is
+// reported to out; the run stops at the first failing step.
+func SelfTest(out io.Writer) ([]SelfTestStep, *Error) {
+	dir, err := os.MkdirTemp("", "dotsecenv-selftest-*")
+	if err != nil {
+		return nil, NewError(fmt.Sprintf("failed to create temporary directory: %v", err), ExitGeneralError)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	client := gpg.NewMemoryClient()
+	vaultPath := filepath.Join(dir, "vault")
+	// Output of the commands under test is captured, not shown.
+	var captured bytes.Buffer
+	c := &CLI{
+		config:    config.DefaultConfig(),
+		gpgClient: client,
+		stdin:     strings.NewReader(""),
+		output:    output.NewHandler(&captured, &captured),
+		hasTTY:    func() bool { return true },
+	}
+	defer func() {
+		if c.vaultResolver != nil {
+			_ = c.vaultResolver.CloseAll()
+		}
+	}()
+
+	plaintext := make([]byte, 16)
+	if _, err := rand.Read(plaintext); err != nil {
+		return nil, NewError(fmt.Sprintf("failed to generate test value: %v", err), ExitGeneralError)
+	}
+	secretValue := hex.EncodeToString(plaintext)
+
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"generate ephemeral key", func() error {
+			fp, err := client.GenerateKey("dotsecenv selftest", "selftest@dotsecenv.invalid")
+			c.fingerprint = fp
+			return err
+		}},
+		{"create temporary vault", func() error {
+			if err := InitVaultFile(vaultPath, "", c.output); err != nil {
+				return err
+			}
+			resolver := vault.NewVaultResolver(vault.VaultConfig{Entries: []vault.VaultEntry{{Path: vaultPath}}})
+			c.vaultResolver = resolver
+			return resolver.OpenVaults(&captured)
+		}},
+		{"add identity", func() error {
+			return errOrNil(c.IdentityAdd(c.fingerprint, false, "", 1))
+		}},
+		{"store secret", func() error {
+			return errOrNil(c.SecretPutValue(selfTestSecretKey, "", 1, secretValue, false))
+		}},
+		{"retrieve secret", func() error {
+			captured.Reset()
+			if err := c.SecretGet(selfTestSecretKey, false, false, false, "", 1); err != nil {
+				return err
+			}
+			if got := strings.TrimSuffix(captured.String(), "\n"); got != secretValue {
+				return fmt.Errorf("decrypted value does not match the stored value")
+			}
+			return nil
+		}},
+		{"verify signatures", func() error {
+			// Reopen the vault so what was written to disk is verified.
+			if err := c.vaultResolver.CloseAll(); err != nil {
+				return err
+			}
+			c.vaultResolver = nil
+			manager := vault.NewManager(vaultPath, true)
+			if err := manager.OpenAndLock(); err != nil {
+				return err
+			}
+			defer func() { _ = manager.Unlock() }()
+			data := manager.Get()
+			if len(data.Identities) == 0 || len(data.Secrets) == 0 {
+				return fmt.Errorf("vault holds %d identities and %d secrets, want at least one of each", len(data.Identities), len(data.Secrets))
+			}
+			if errs := validateVaultData(data, manager); len(errs) > 0 {
+				return fmt.Errorf("%s at %s", errs[0].Message, errs[0].Path)
+			}
+			return nil
+		}},
+	}
+
+	var results []SelfTestStep
+	for _, step := range steps {
+		stepErr := step.run()
+		results = append(results, SelfTestStep{Name: step.name, Err: stepErr})
+		if stepErr != nil {
+			_, _ = fmt.Fprintf(out, "  [✗] %s: %v\n", step.name, stepErr)
+			return results, NewError(fmt.Sprintf("selftest failed at step '%s'", step.name), ExitGeneralError)
+		}
+		_, _ = fmt.Fprintf(out, "  [✓] %s\n", step.name)
+	}
+
+	_, _ = fmt.Fprintf(out, "\nStatus: all %d steps passed\n", len(results))
+	return results, nil
+}
+
+// errOrNil converts a nil *Error to a nil error interface.
+func errOrNil(err *Error) error {
+	if err == nil {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSelfTest_AllStepsPass(t *testing.T) {
+	t.Setenv("DOTSECENV_CONFIG", "")
+	t.Setenv("TMPDIR", t.TempDir())
+
+	var out bytes.Buffer
+	steps, err := SelfTest(&out)
+	if err != nil {
+		t.Fatalf("SelfTest failed: %v\n%s", err, out.String())
+	}
+
+	want := []string{
+		"generate ephemeral key",
+		"create temporary vault",
+		"add identity",
+		"store secret",
+		"retrieve secret",
+		"verify signatures",
+	}
+	if len(steps) != len(want) {
+		t.Fatalf("got %d steps, want %d:\n%s", len(steps), len(want), out.String())
+	}
+	for i, step := range steps {
+		if step.Name != want[i] {
+			t.Errorf("step %d = %q, want %q", i, step.Name, want[i])
+		}
+		if step.Err != nil {
+			t.Errorf("step %q failed: %v", step.Name, step.Err)
+		}
+		if !strings.Contains(out.String(), "[✓] "+want[i]) {
+			t.Errorf("output does not report %q as passed:\n%s", want[i], out.String())
+		}
+	}
+}
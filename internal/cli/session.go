@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
+)
+
+// sessionFileName is the file, next to the config file, caching the
+// fingerprint last validated against the GPG keyring.
+const sessionFileName = "session.json"
+
+// session is the cached result of validating a --fingerprint against the
+// GPG keyring. It holds no secret material: only which fingerprint was
+// found, in which keyring, and until when to trust that.
+type session struct {
+	Fingerprint string    `json:"fingerprint"`
+	GPGHome     string    `json:"gpg_home,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// sessionPath returns the path of the session cache, or "" when there is no
+// config file to put it next to.
+func (c *CLI) sessionPath() string {
+	if c.configPath == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(c.configPath), sessionFileName)
+}
+
+// cachedFingerprint reports whether fp was validated against the current
+// GPG keyring within the session TTL. A missing, expired or unreadable
+// cache is a miss. The TTL is read from the current config, so setting
+// session_ttl to 0s, or lowering it, takes effect on a cache written under
+// the old value.
+func (c *CLI) cachedFingerprint(fp string) bool {
+	path := c.sessionPath()
+	if path == "" {
+		return false
+	}
+	ttl, err := c.config.GetSessionTTL()
+	if err != nil || ttl == 0 {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var s session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return false
+	}
+	return identity.CompareFingerprints(s.Fingerprint, fp) &&
+		s.GPGHome == os.Getenv("GNUPGHOME") &&
+		time.Now().Before(s.ExpiresAt) &&
+		!s.ExpiresAt.After(time.Now().Add(ttl))
+}
+
+// cacheFingerprint records fp as validated for the session TTL. Caching is
+// an optimization, so failing to write the cache is not an error.
+func (c *CLI) cacheFingerprint(fp string) {
+	path := c.sessionPath()
+	if path == "" {
+		return
+	}
+	ttl, err := c.config.GetSessionTTL()
+	if err != nil || ttl == 0 {
+		return
+	}
+	data, err := json.Marshal(session{
+		Fingerprint: fp,
+		GPGHome:     os.Getenv("GNUPGHOME"),
+		ExpiresAt:   time.Now().Add(ttl).UTC(),
+	})
+	if err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+sessionFileName+".tmp-*")
+	if err != nil {
+		return
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil || os.Rename(tmp.Name(), path) != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
+
+// Logout clears the session cache, so the next --fingerprint is validated
+// against the GPG keyring again. The signed login in the config is kept.
+func (c *CLI) Logout() *Error {
+	path := c.sessionPath()
+	if path == "" {
+		return NewError("no config file; nothing to log out of", ExitConfigError)
+	}
+	if err := os.Remove(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			_, _ = fmt.Fprintf(c.output.Stdout(), "No active session.\n")
+			return nil
+		}
+		return NewError(fmt.Sprintf("failed to clear session: %v", err), ExitGeneralError)
+	}
+	_, _ = fmt.Fprintf(c.output.Stdout(), "Session cleared.\n")
+	return nil
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/gpg"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+)
+
+// keyListCountingGPGClient counts how often the keyring is listed.
+type keyListCountingGPGClient struct {
+	*MockGPGClient
+	listed int
+}
+
+func (m *keyListCountingGPGClient) ListSecretKeys() ([]gpg.SecretKeyInfo, error) {
+	m.listed++
+	return m.MockGPGClient.ListSecretKeys()
+}
+
+// newSessionCLI returns a CLI whose config lives in dir, sharing gpgClient
+// so keyring lookups can be counted across invocations.
+func newSessionCLI(dir string, cfg config.Config, gpgClient gpg.Client) (*CLI, *bytes.Buffer) {
+	stdout := &bytes.Buffer{}
+	return &CLI{
+		configPath: filepath.Join(dir, "config"),
+		config:     cfg,
+		gpgClient:  gpgClient,
+		output:     output.NewHandler(stdout, &bytes.Buffer{}),
+	}, stdout
+}
+
+func TestSetFingerprint_SessionCacheHit(t *testing.T) {
+	t.Setenv("GNUPGHOME", "/home/test/.gnupg")
+	dir := t.TempDir()
+	gpgClient := &keyListCountingGPGClient{MockGPGClient: NewMockGPGClient()}
+
+	first, _ := newSessionCLI(dir, config.Config{}, gpgClient)
+	if err := first.SetFingerprint("TESTFINGERPRINT"); err != nil {
+		t.Fatalf("SetFingerprint failed: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(dir, sessionFileName))
+	if err != nil {
+		t.Fatalf("expected a session file: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("session file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	second, _ := newSessionCLI(dir, config.Config{}, gpgClient)
+	if err := second.SetFingerprint("testfingerprint"); err != nil {
+		t.Fatalf("SetFingerprint failed: %v", err)
+	}
+	if gpgClient.listed != 1 {
+		t.Errorf("expected the keyring listed once within the TTL, got %d", gpgClient.listed)
+	}
+	if got := second.activeFingerprint(); got != "TESTFINGERPRINT" {
+		t.Errorf("activeFingerprint = %q, want TESTFINGERPRINT", got)
+	}
+
+	// Another keyring does not reuse the session
+	t.Setenv("GNUPGHOME", "/home/other/.gnupg")
+	third, _ := newSessionCLI(dir, config.Config{}, gpgClient)
+	if err := third.SetFingerprint("TESTFINGERPRINT"); err != nil {
+		t.Fatalf("SetFingerprint failed: %v", err)
+	}
+	if gpgClient.listed != 2 {
+		t.Errorf("expected a different GNUPGHOME to miss the cache, got %d listings", gpgClient.listed)
+	}
+}
+
+func TestSetFingerprint_SessionCacheExpired(t *testing.T) {
+	t.Setenv("GNUPGHOME", "")
+	dir := t.TempDir()
+	gpgClient := &keyListCountingGPGClient{MockGPGClient: NewMockGPGClient()}
+
+	expired, err := json.Marshal(session{Fingerprint: "TESTFINGERPRINT", ExpiresAt: time.Now().Add(-time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, sessionFileName), expired, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cli, _ := newSessionCLI(dir, config.Config{}, gpgClient)
+	if err := cli.SetFingerprint("TESTFINGERPRINT"); err != nil {
+		t.Fatalf("SetFingerprint failed: %v", err)
+	}
+	if gpgClient.listed != 1 {
+		t.Errorf("expected an expired session to be revalidated, got %d listings", gpgClient.listed)
+	}
+
+	// An expired session must not vouch for a key that is gone
+	if err := os.WriteFile(filepath.Join(dir, sessionFileName), expired, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.SetFingerprint("GONEFINGERPRINT"); err == nil {
+		t.Error("expected an unknown fingerprint to fail")
+	}
+}
+
+func TestSetFingerprint_SessionTTLZeroDisablesCache(t *testing.T) {
+	dir := t.TempDir()
+	cli, _ := newSessionCLI(dir, config.Config{SessionTTL: "0s"}, NewMockGPGClient())
+	if err := cli.SetFingerprint("TESTFINGERPRINT"); err != nil {
+		t.Fatalf("SetFingerprint failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, sessionFileName)); !os.IsNotExist(err) {
+		t.Errorf("expected no session file with session_ttl 0s, got %v", err)
+	}
+}
+
+func TestSetFingerprint_SessionTTLCheckedOnRead(t *testing.T) {
+	t.Setenv("GNUPGHOME", "")
+	dir := t.TempDir()
+	gpgClient := &keyListCountingGPGClient{MockGPGClient: NewMockGPGClient()}
+
+	first, _ := newSessionCLI(dir, config.Config{SessionTTL: "1h"}, gpgClient)
+	if err := first.SetFingerprint("TESTFINGERPRINT"); err != nil {
+		t.Fatalf("SetFingerprint failed: %v", err)
+	}
+
+	// A cache written under a longer TTL is not reused after it is lowered
+	lowered, _ := newSessionCLI(dir, config.Config{SessionTTL: "1m"}, gpgClient)
+	if err := lowered.SetFingerprint("TESTFINGERPRINT"); err != nil {
+		t.Fatalf("SetFingerprint failed: %v", err)
+	}
+	if gpgClient.listed != 2 {
+		t.Errorf("expected a lowered session_ttl to revalidate, got %d listings", gpgClient.listed)
+	}
+
+	// Nor once caching is disabled
+	disabled, _ := newSessionCLI(dir, config.Config{SessionTTL: "0s"}, gpgClient)
+	if err := disabled.SetFingerprint("TESTFINGERPRINT"); err != nil {
+		t.Fatalf("SetFingerprint failed: %v", err)
+	}
+	if gpgClient.listed != 3 {
+		t.Errorf("expected session_ttl 0s to revalidate, got %d listings", gpgClient.listed)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	t.Setenv("GNUPGHOME", "")
+	dir := t.TempDir()
+	gpgClient := &keyListCountingGPGClient{MockGPGClient: NewMockGPGClient()}
+
+	cli, stdout := newSessionCLI(dir, config.Config{}, gpgClient)
+	if err := cli.SetFingerprint("TESTFINGERPRINT"); err != nil {
+		t.Fatalf("SetFingerprint failed: %v", err)
+	}
+	if err := cli.Logout(); err != nil {
+		t.Fatalf("Logout failed: %v", err)
+	}
+	if stdout.String() != "Session cleared.\n" {
+		t.Errorf("unexpected output: %q", stdout.String())
+	}
+	if err := cli.SetFingerprint("TESTFINGERPRINT"); err != nil {
+		t.Fatalf("SetFingerprint failed: %v", err)
+	}
+	if gpgClient.listed != 2 {
+		t.Errorf("expected logout to force revalidation, got %d listings", gpgClient.listed)
+	}
+
+	_ = cli.Logout()
+	stdout.Reset()
+	if err := cli.Logout(); err != nil {
+		t.Fatalf("Logout without a session failed: %v", err)
+	}
+	if stdout.String() != "No active session.\n" {
+		t.Errorf("unexpected output: %q", stdout.String())
+	}
+}
@@ -3,7 +3,6 @@ package cli
 import (
 	"encoding/base64"
 	"fmt"
-	"slices"
 	"sort"
 	"time"
 
@@ -71,7 +70,7 @@ func (c *CLI) SecretShareAll(secretKey, targetFingerprint string) *Error {
 		// Check if already shared
 		if len(secretObj.Values) > 0 {
 			currentValue := secretObj.Values[len(secretObj.Values)-1]
-			if slices.Contains(currentValue.AvailableTo, targetFingerprint) {
+			if currentValue.CanBeReadBy(targetFingerprint) {
 				_, _ = fmt.Fprintf(c.output.Stdout(), "Vault %d (%s): skipped, already shared\n", displayPos, vaultPath)
 				skippedCount++
 				continue
@@ -129,7 +128,7 @@ func (c *CLI) secretShareInVault(secretKey, targetFingerprint string, vaultIndex
 	currentValue := secretObj.Values[len(secretObj.Values)-1]
 
 	// Check if already shared
-	if slices.Contains(currentValue.AvailableTo, targetFingerprint) {
+	if currentValue.CanBeReadBy(targetFingerprint) {
 		if !silent {
 			vaultPath := ""
 			vaultPaths := c.vaultResolver.GetVaultPaths()
@@ -152,6 +151,9 @@ func (c *CLI) secretShareInVault(secretKey, targetFingerprint string, vaultIndex
 		return NewError(fmt.Sprintf("identity not found: %s", targetFingerprint), ExitVaultError)
 	}
 
+	if detErr := detachedValueError(secretKey, &currentValue); detErr != nil {
+		return detErr
+	}
 	encryptedArmored, decodeErr := base64.StdEncoding.DecodeString(currentValue.Value)
 	if decodeErr != nil {
 		return NewError(fmt.Sprintf("failed to decode encrypted value: %v", decodeErr), ExitGeneralError)
@@ -169,46 +171,16 @@ func (c *CLI) secretShareInVault(secretKey, targetFingerprint string, vaultIndex
 
 	sort.Strings(newRecipients)
 
-	var recipientPublicKeys []string
-	for _, recipientFP := range newRecipients {
-		recipientIdentity := c.vaultResolver.GetIdentityByFingerprint(recipientFP)
-		if recipientIdentity == nil {
-			return NewError(fmt.Sprintf("recipient identity not found: %s", recipientFP), ExitVaultError)
-		}
-		recipientPublicKeys = append(recipientPublicKeys, recipientIdentity.PublicKey)
+	// Sharing only ever widens access, so a value still below min_recipients
+	// is written anyway; refusing would leave no way to reach the minimum.
+	if minRecipients, err := c.config.GetMinRecipients(); err == nil && len(newRecipients) < minRecipients && !silent {
+		c.Warnf("secret '%s' is readable by %d identity(ies), still fewer than min_recipients (%d)", secretKey, len(newRecipients), minRecipients)
 	}
 
-	encryptedArmoredNew, encErr := c.gpgClient.EncryptToRecipients(plaintext, recipientPublicKeys, nil)
+	newSecretValue, encErr := c.encryptValueForRecipients(secretKey, plaintext, currentValue, newRecipients, fp, nil)
 	if encErr != nil {
-		return NewError(fmt.Sprintf("failed to encrypt secret: %v", encErr), ExitGeneralError)
-	}
-
-	encryptedBase64 := base64.StdEncoding.EncodeToString([]byte(encryptedArmoredNew))
-
-	now := time.Now().UTC()
-	algorithmBits := 256
-	signingIdentity := c.vaultResolver.GetIdentityByFingerprint(fp)
-	if signingIdentity != nil {
-		algorithmBits = signingIdentity.AlgorithmBits
-	}
-
-	// Build secret value struct first (without hash/signature)
-	newSecretValue := vault.SecretValue{
-		AddedAt:     now,
-		AvailableTo: newRecipients,
-		SignedBy:    fp,
-		Value:       encryptedBase64,
-		Deleted:     false,
-	}
-
-	// Compute hash using shared function
-	valueHash := vault.ComputeSecretValueHash(&newSecretValue, secretKey, algorithmBits)
-	valueSig, sigErr := c.gpgClient.SignDataWithAgent(fp, []byte(valueHash))
-	if sigErr != nil {
-		return NewError(fmt.Sprintf("failed to sign secret value: %v", sigErr), ExitGeneralError)
+		return encErr
 	}
-	newSecretValue.Hash = valueHash
-	newSecretValue.Signature = valueSig
 
 	// Use AddSecret which handles adding values to existing secrets via the writer
 	newSecret := vault.Secret{
@@ -228,5 +200,62 @@ func (c *CLI) secretShareInVault(secretKey, targetFingerprint string, vaultIndex
 		displayPos := vaultIndex + 1
 		_, _ = fmt.Fprintf(c.output.Stdout(), "Vault %d (%s): shared secret '%s' with %s\n", displayPos, vaultPath, secretKey, targetFingerprint)
 	}
-	return nil
+	return c.runPostWriteHook(vaultIndex)
+}
+
+// encryptValueForRecipients encrypts plaintext to the sorted recipients and
+// returns a new value for secretKey, hashed and signed by signerFP. All
+// recipients must exist as identities in a loaded vault or in pending,
+// identities about to be added alongside the new value. plaintext is the
+// decrypted payload of current, the value being replaced, whose metadata
+// the new value keeps: a compressed payload is re-encrypted as it was
+// stored, the recorded source is carried over, and a detached value stays
+// detached under the reference of its new encrypted value.
+func (c *CLI) encryptValueForRecipients(secretKey string, plaintext []byte, current vault.SecretValue, recipients []string, signerFP string, pending map[string]*vault.Identity) (vault.SecretValue, *Error) {
+	var recipientPublicKeys []string
+	for _, recipientFP := range recipients {
+		recipientIdentity := pending[recipientFP]
+		if recipientIdentity == nil {
+			recipientIdentity = c.vaultResolver.GetIdentityByFingerprint(recipientFP)
+		}
+		if recipientIdentity == nil {
+			return vault.SecretValue{}, NewError(fmt.Sprintf("recipient identity not found: %s", recipientFP), ExitVaultError)
+		}
+		recipientPublicKeys = append(recipientPublicKeys, recipientIdentity.PublicKey)
+	}
+
+	encryptedArmored, encErr := c.gpgClient.EncryptToRecipients(plaintext, recipientPublicKeys, nil)
+	if encErr != nil {
+		return vault.SecretValue{}, NewError(fmt.Sprintf("failed to encrypt secret: %v", encErr), ExitGeneralError)
+	}
+
+	algorithmBits := 256
+	signingIdentity := c.vaultResolver.GetIdentityByFingerprint(signerFP)
+	if signingIdentity != nil {
+		algorithmBits = signingIdentity.AlgorithmBits
+	}
+
+	// Build secret value struct first (without hash/signature)
+	value := vault.SecretValue{
+		AddedAt:     time.Now().UTC(),
+		AvailableTo: recipients,
+		SignedBy:    signerFP,
+		Value:       base64.StdEncoding.EncodeToString([]byte(encryptedArmored)),
+		Deleted:     false,
+		Compressed:  current.Compressed,
+		Source:      current.Source,
+	}
+	if current.ValueRef != "" {
+		value.Detach()
+	}
+
+	// Compute hash using shared function
+	value.Hash = vault.ComputeSecretValueHash(&value, secretKey, algorithmBits)
+	valueSig, sigErr := c.gpgClient.SignDataWithAgent(signerFP, []byte(value.Hash))
+	if sigErr != nil {
+		return vault.SecretValue{}, NewError(fmt.Sprintf("failed to sign secret value: %v", sigErr), ExitGeneralError)
+	}
+	value.Signature = valueSig
+
+	return value, nil
 }
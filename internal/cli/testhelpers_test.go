@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"io"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
@@ -25,6 +26,8 @@ type MockVaultResolver struct {
 	SavedVaults       []int // Track which vaults (indices) were saved
 	VaultEntries      []vault.VaultEntry
 	Managers          map[int]*vault.Manager // Optional managers for tests that need them
+	LoadErrors        map[int]error          // Optional per-index load errors
+	SearchDepth       int                    // Set by SetSearchDepth
 }
 
 func NewMockVaultResolver() *MockVaultResolver {
@@ -75,10 +78,26 @@ func (m *MockVaultResolver) AddIdentity(identity vault.Identity, index int) erro
 	return nil
 }
 
+func (m *MockVaultResolver) ReplaceIdentity(identity vault.Identity, index int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if manager := m.Managers[index]; manager != nil {
+		return manager.ReplaceIdentity(identity)
+	}
+	if _, ok := m.IdentitiesByVault[index][identity.Fingerprint]; !ok {
+		return fmt.Errorf("identity not found: %s", identity.Fingerprint)
+	}
+	m.IdentitiesByVault[index][identity.Fingerprint] = identity
+	m.Identities[identity.Fingerprint] = identity
+	return nil
+}
+
 func (m *MockVaultResolver) SaveAll() error {
 	return nil
 }
 
+func (m *MockVaultResolver) DeferWrites() {}
+
 func (m *MockVaultResolver) CloseAll() error {
 	return nil
 }
@@ -88,10 +107,7 @@ func (m *MockVaultResolver) GetSecretFromAnyVault(key string, stderr io.Writer)
 	defer m.mu.Unlock()
 
 	// Search in order
-	count := len(m.VaultPaths)
-	if len(m.VaultEntries) > count {
-		count = len(m.VaultEntries)
-	}
+	count := m.searchCount()
 
 	for i := 0; i < count; i++ {
 		if secrets, ok := m.Secrets[i]; ok {
@@ -110,10 +126,7 @@ func (m *MockVaultResolver) GetAccessibleSecretFromAnyVault(key, fingerprint str
 	defer m.mu.Unlock()
 
 	// Search in order
-	count := len(m.VaultPaths)
-	if len(m.VaultEntries) > count {
-		count = len(m.VaultEntries)
-	}
+	count := m.searchCount()
 
 	for i := 0; i < count; i++ {
 		if secrets, ok := m.Secrets[i]; ok {
@@ -151,10 +164,7 @@ func (m *MockVaultResolver) FindSecretVaultIndex(key string) int {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	count := len(m.VaultPaths)
-	if len(m.VaultEntries) > count {
-		count = len(m.VaultEntries)
-	}
+	count := m.searchCount()
 
 	for i := 0; i < count; i++ {
 		if secrets, ok := m.Secrets[i]; ok {
@@ -178,7 +188,26 @@ func (m *MockVaultResolver) GetVaultManager(index int) *vault.Manager {
 func (m *MockVaultResolver) GetConfig() vault.VaultConfig {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	return vault.VaultConfig{Entries: m.VaultEntries}
+	return vault.VaultConfig{Entries: m.VaultEntries, SearchDepth: m.SearchDepth}
+}
+
+func (m *MockVaultResolver) SetSearchDepth(depth int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.SearchDepth = depth
+}
+
+// searchCount returns how many vaults, from the first, lookups search.
+// The caller holds m.mu.
+func (m *MockVaultResolver) searchCount() int {
+	count := len(m.VaultPaths)
+	if len(m.VaultEntries) > count {
+		count = len(m.VaultEntries)
+	}
+	if m.SearchDepth > 0 && m.SearchDepth < count {
+		count = m.SearchDepth
+	}
+	return count
 }
 
 func (m *MockVaultResolver) GetVaultPaths() []string {
@@ -226,8 +255,21 @@ func (m *MockVaultResolver) SaveVault(index int) error {
 	return nil
 }
 
+func (m *MockVaultResolver) TrimSecretHistory(index int, key string, keep int) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.Secrets[index][key]
+	if !ok || keep <= 0 || len(s.Values) <= keep {
+		return 0, nil
+	}
+	dropped := len(s.Values) - keep
+	s.Values = s.Values[dropped:]
+	m.Secrets[index][key] = s
+	return dropped, nil
+}
+
 func (m *MockVaultResolver) GetLoadError(index int) error {
-	return nil
+	return m.LoadErrors[index]
 }
 
 func (m *MockVaultResolver) GetSecret(index int, key string) (*vault.SecretValue, error) {
@@ -366,6 +408,22 @@ func (m *MockGPGClient) DecryptWithAgent(ciphertext []byte, fingerprint string)
 	return nil, fmt.Errorf("not implemented")
 }
 
+func (m *MockGPGClient) EncryptStream(dst io.Writer, recipients []string) (io.WriteCloser, error) {
+	if _, err := fmt.Fprintf(dst, "encrypted_to_%s_", strings.Join(recipients, "_")); err != nil {
+		return nil, err
+	}
+	return nopWriteCloser{dst}, nil
+}
+
+func (m *MockGPGClient) DecryptStream(dst io.Writer, ciphertext io.Reader, fingerprint string) error {
+	return fmt.Errorf("not implemented")
+}
+
+// nopWriteCloser adds a no-op Close to a writer.
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
 func (m *MockGPGClient) ExtractAlgorithmAndCurve(fullAlgorithm string) (algorithm string, curve string) {
 	return fullAlgorithm, ""
 }
@@ -416,3 +474,23 @@ func newTestSignedLogin(t *testing.T, fingerprint string) *config.Login {
 	}
 	return login
 }
+
+// newTestManager writes v to a temporary vault file and returns a locked
+// manager for it. The lock is released when the test finishes.
+func newTestManager(t *testing.T, v vault.Vault) *vault.Manager {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "vault")
+	w, err := vault.NewWriter(path)
+	if err != nil {
+		t.Fatalf("failed to create vault writer: %v", err)
+	}
+	if err := w.RewriteFromVault(v); err != nil {
+		t.Fatalf("failed to write vault: %v", err)
+	}
+	m := vault.NewManager(path, false)
+	if err := m.OpenAndLock(); err != nil {
+		t.Fatalf("failed to open vault: %v", err)
+	}
+	t.Cleanup(func() { _ = m.Unlock() })
+	return m
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// SecretTouch re-signs the latest value of a secret without changing it. The
+// value is decrypted, re-encrypted to the same recipients and appended as a
+// new value signed by the caller, such as after the original signer's key was
+// revoked or to refresh provenance. The new value keeps the compression,
+// source and detached storage of the old one.
+func (c *CLI) SecretTouch(secretKeyArg, vaultPath string, fromIndex int) *Error {
+	secretKey, normErr := vault.NormalizeSecretKey(secretKeyArg)
+	if normErr != nil {
+		return NewError(vault.FormatSecretKeyError(normErr), ExitValidationError)
+	}
+
+	fp, err := c.checkFingerprintRequired("secret touch")
+	if err != nil {
+		return err
+	}
+
+	targetIndex, resolveErr := c.resolveWritableVaultIndex(vaultPath, fromIndex)
+	if resolveErr != nil {
+		return resolveErr
+	}
+
+	existingSecret := c.vaultResolver.GetSecretByKeyFromVault(targetIndex, secretKey)
+	if checkErr := checkForgettable(existingSecret, secretKey, fp); checkErr != nil {
+		return checkErr
+	}
+
+	current := existingSecret.Values[len(existingSecret.Values)-1]
+	recipients := append([]string(nil), current.AvailableTo...)
+	newValue, rekeyErr := c.rekeyValue(secretKey, current, recipients, fp, nil)
+	if rekeyErr != nil {
+		return rekeyErr
+	}
+
+	touched := vault.Secret{Key: secretKey, Values: []vault.SecretValue{newValue}}
+	if err := c.vaultResolver.AddSecret(touched, targetIndex); err != nil {
+		return NewError(fmt.Sprintf("failed to add secret value: %v", err), ExitVaultError)
+	}
+
+	if saveErr := c.vaultResolver.SaveVault(targetIndex); saveErr != nil {
+		return NewError(fmt.Sprintf("failed to save vault: %v", saveErr), ExitVaultError)
+	}
+	if hookErr := c.runPostWriteHook(targetIndex); hookErr != nil {
+		return hookErr
+	}
+
+	_, _ = fmt.Fprintf(c.output.Stdout(), "Secret '%s' re-signed by %s\n", secretKey, fp)
+	return nil
+}
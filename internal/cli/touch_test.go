@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+func TestSecretTouch(t *testing.T) {
+	secret := rekeyTestSecret("DB_URL", "ALICE", "ME")
+	secret.Values[0].SignedBy = "ALICE"
+	cli, stdout, written := newRekeyCLI(t, vault.Vault{Secrets: []vault.Secret{secret}}, "ME", "ALICE")
+	resolver := cli.vaultResolver.(*MockVaultResolver)
+	resolver.Secrets[0] = map[string]vault.Secret{"DB_URL": secret}
+
+	if err := cli.SecretTouch("DB_URL", "", 1); err != nil {
+		t.Fatalf("SecretTouch failed: %v", err)
+	}
+
+	value, ok := written["DB_URL"]
+	if !ok {
+		t.Fatalf("expected a new value for DB_URL, got %v", written)
+	}
+	if value.SignedBy != "ME" {
+		t.Errorf("expected new value signed by ME, got %q", value.SignedBy)
+	}
+	if value.Signature != "signature_by_ME" {
+		t.Errorf("expected signature by ME, got %q", value.Signature)
+	}
+	if got := strings.Join(value.AvailableTo, ","); got != "ALICE,ME" {
+		t.Errorf("expected recipients ALICE,ME, got %s", got)
+	}
+
+	// The mock encrypts to "encrypted_to_<keys>_<plaintext>"; the plaintext is
+	// what the echo client decrypted from the original value.
+	ciphertext, err := base64.StdEncoding.DecodeString(value.Value)
+	if err != nil {
+		t.Fatalf("failed to decode new value: %v", err)
+	}
+	if want := "encrypted_to_pub-ALICE_pub-ME_plain-cipher-DB_URL"; string(ciphertext) != want {
+		t.Errorf("expected plaintext to be re-encrypted unchanged, got %q, want %q", ciphertext, want)
+	}
+	if len(resolver.SavedVaults) != 1 {
+		t.Errorf("expected vault to be saved once, got %v", resolver.SavedVaults)
+	}
+	if !strings.Contains(stdout.String(), "Secret 'DB_URL' re-signed by ME") {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+}
+
+func TestSecretTouch_Refused(t *testing.T) {
+	deleted := rekeyTestSecret("GONE", "ME")
+	deleted.Values = append(deleted.Values, vault.SecretValue{Deleted: true})
+
+	tests := []struct {
+		name    string
+		secrets []vault.Secret
+		key     string
+		code    ExitCode
+	}{
+		{name: "not found", key: "MISSING", code: ExitVaultError},
+		{name: "deleted", secrets: []vault.Secret{deleted}, key: "GONE", code: ExitGeneralError},
+		{name: "no access", secrets: []vault.Secret{rekeyTestSecret("FOREIGN", "ALICE")}, key: "FOREIGN", code: ExitAccessDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, _, written := newRekeyCLI(t, vault.Vault{}, "ME", "ALICE")
+			resolver := cli.vaultResolver.(*MockVaultResolver)
+			resolver.Secrets[0] = map[string]vault.Secret{}
+			for _, s := range tt.secrets {
+				resolver.Secrets[0][s.Key] = s
+			}
+
+			err := cli.SecretTouch(tt.key, "", 1)
+			if err == nil {
+				t.Fatal("expected SecretTouch to fail")
+			}
+			if err.ExitCode != tt.code {
+				t.Errorf("expected exit code %d, got %d: %v", tt.code, err.ExitCode, err)
+			}
+			if len(written) != 0 {
+				t.Errorf("expected nothing written, got %v", written)
+			}
+		})
+	}
+}
+
+func TestSecretTouch_KeepsMetadata(t *testing.T) {
+	secret := rekeyTestSecret("CERT", "ALICE", "ME")
+	before := &secret.Values[0]
+	before.SignedBy = "ALICE"
+	before.Compressed = true
+	before.Source = "imported-from:aws"
+	before.Detach()
+	cli, _, written := newRekeyCLI(t, vault.Vault{Secrets: []vault.Secret{secret}}, "ME", "ALICE")
+	cli.vaultResolver.(*MockVaultResolver).Secrets[0] = map[string]vault.Secret{"CERT": secret}
+
+	if err := cli.SecretTouch("CERT", "", 1); err != nil {
+		t.Fatalf("SecretTouch failed: %v", err)
+	}
+
+	after := written["CERT"]
+	if after.SignedBy != "ME" || after.Deleted {
+		t.Fatalf("expected a live value signed by ME, got %+v", after)
+	}
+	if strings.Join(after.AvailableTo, ",") != strings.Join(before.AvailableTo, ",") {
+		t.Errorf("recipients changed: %v -> %v", before.AvailableTo, after.AvailableTo)
+	}
+	if after.Compressed != before.Compressed || after.Source != before.Source {
+		t.Errorf("metadata changed: compressed %t -> %t, source %q -> %q",
+			before.Compressed, after.Compressed, before.Source, after.Source)
+	}
+	if after.ValueRef != vault.DetachedValueRef(after.Value) {
+		t.Errorf("expected the value to stay detached under its new reference, got %q", after.ValueRef)
+	}
+	if after.Hash != vault.ComputeSecretValueHash(&after, "CERT", 256) {
+		t.Error("expected the value hash to cover the carried metadata")
+	}
+}
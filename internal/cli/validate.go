@@ -2,44 +2,54 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/crypto"
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
 )
 
-// Validate validates the configuration and vault files
-func (c *CLI) Validate(fix bool) *Error {
-	_, _ = fmt.Fprintf(c.output.Stdout(), "=== DotSecEnv Configuration Validation ===\n\n")
+// Validate validates the configuration and vault files. With jsonOutput,
+// the problems found are printed as ValidateJSON instead of the report.
+func (c *CLI) Validate(fix bool, jsonOutput bool) *Error {
+	out := c.output.Stdout()
+	if jsonOutput {
+		out = io.Discard
+	}
+	report := newValidateJSON()
+
+	_, _ = fmt.Fprintf(out, "=== DotSecEnv Configuration Validation ===\n\n")
 
 	absConfigPath, err := filepath.Abs(c.configPath)
 	if err != nil {
 		absConfigPath = c.configPath
 	}
-	_, _ = fmt.Fprintf(c.output.Stdout(), "Configuration file: %s\n", absConfigPath)
+	_, _ = fmt.Fprintf(out, "Configuration file: %s\n", absConfigPath)
 	if _, err := os.Stat(absConfigPath); err != nil {
 		return NewError(fmt.Sprintf("config file not found: %s", absConfigPath), ExitConfigError)
 	}
-	_, _ = fmt.Fprintf(c.output.Stdout(), "  Status: ✓ Found\n\n")
+	_, _ = fmt.Fprintf(out, "  Status: ✓ Found\n\n")
 
-	_, _ = fmt.Fprintf(c.output.Stdout(), "Approved Algorithms:\n")
+	_, _ = fmt.Fprintf(out, "Approved Algorithms:\n")
 	if len(c.config.ApprovedAlgorithms) == 0 {
-		_, _ = fmt.Fprintf(c.output.Stdout(), "  (No requirements defined)\n")
+		_, _ = fmt.Fprintf(out, "  (No requirements defined)\n")
 	} else {
 		for _, req := range c.config.ApprovedAlgorithms {
-			_, _ = fmt.Fprintf(c.output.Stdout(), "  %s: minimum %d bits", req.Algo, req.MinBits)
+			_, _ = fmt.Fprintf(out, "  %s: minimum %d bits", req.Algo, req.MinBits)
 			if len(req.Curves) > 0 {
-				_, _ = fmt.Fprintf(c.output.Stdout(), " (curves: %s)", strings.Join(req.Curves, ", "))
+				_, _ = fmt.Fprintf(out, " (curves: %s)", strings.Join(req.Curves, ", "))
 			}
-			_, _ = fmt.Fprintf(c.output.Stdout(), " ✓\n")
+			_, _ = fmt.Fprintf(out, " ✓\n")
 		}
 	}
-	_, _ = fmt.Fprintf(c.output.Stdout(), "\n")
+	_, _ = fmt.Fprintf(out, "\n")
 
-	_, _ = fmt.Fprintf(c.output.Stdout(), "Vault Configuration:\n")
+	_, _ = fmt.Fprintf(out, "Vault Configuration:\n")
 	vaultCount := 0
 	hasErrors := false
 	hasHashMismatch := false
@@ -53,16 +63,17 @@ func (c *CLI) Validate(fix bool) *Error {
 			absVaultPath = vaultPath
 		}
 
-		_, _ = fmt.Fprintf(c.output.Stdout(), "  Vault %d: %s\n", vaultCount, absVaultPath)
+		_, _ = fmt.Fprintf(out, "  Vault %d: %s\n", vaultCount, absVaultPath)
 
 		fileInfo, err := os.Stat(absVaultPath)
 		if err != nil {
-			_, _ = fmt.Fprintf(c.output.Stdout(), "    Status: ⚠ File not found (warning)\n")
+			_, _ = fmt.Fprintf(out, "    Status: ⚠ File not found (warning)\n")
 			continue
 		}
 
 		if fileInfo.Size() == 0 {
-			_, _ = fmt.Fprintf(c.output.Stdout(), "    Status: ✗ Vault file is empty (invalid vault structure)\n")
+			_, _ = fmt.Fprintf(out, "    Status: ✗ Vault file is empty (invalid vault structure)\n")
+			report.addErrors(absVaultPath, []ValidationError{{Level: "GLOBAL", Message: "vault file is empty"}})
 			hasErrors = true
 			continue
 		}
@@ -74,186 +85,456 @@ func (c *CLI) Validate(fix bool) *Error {
 			if loadErr != nil {
 				errMsg = loadErr.Error()
 			}
-			_, _ = fmt.Fprintf(c.output.Stdout(), "    Status: ✗ Failed to load: %s\n", errMsg)
+			_, _ = fmt.Fprintf(out, "    Status: ✗ Failed to load: %s\n", errMsg)
+			report.addErrors(absVaultPath, []ValidationError{{Level: "GLOBAL", Message: "failed to load: " + errMsg}})
 			hasErrors = true
 			continue
 		}
 
-		vaultData := manager.Get()
+		vaultErrors, vaultHashMismatch, checkErr := c.validateVault(out, absVaultPath, manager, report, fix)
+		if checkErr != nil {
+			return c.finishValidateJSON(jsonOutput, report, checkErr)
+		}
+		hasErrors = hasErrors || vaultErrors
+		hasHashMismatch = hasHashMismatch || vaultHashMismatch
+	}
 
-		_, _ = fmt.Fprintf(c.output.Stdout(), "    Status: ✓ Valid vault file\n")
-		_, _ = fmt.Fprintf(c.output.Stdout(), "    Identities: %d\n", len(vaultData.Identities))
-		_, _ = fmt.Fprintf(c.output.Stdout(), "    Secrets: %d\n", len(vaultData.Secrets))
+	if vaultCount == 0 {
+		_, _ = fmt.Fprintf(out, "  (No vaults configured)\n\n")
+	}
 
-		_, _ = fmt.Fprintf(c.output.Stdout(), "\n    === Structural Validation ===\n")
+	return c.finishValidateJSON(jsonOutput, report, c.printValidationResult(out, report, hasErrors, hasHashMismatch))
+}
 
-		structErrors := validateYAMLStructure(absVaultPath)
-		if len(structErrors) > 0 {
-			_, _ = fmt.Fprintf(c.output.Stdout(), "    YAML Indentation: ✗ (%d issues)\n", len(structErrors))
-			for _, err := range structErrors {
-				_, _ = fmt.Fprintf(c.output.Stdout(), "      - %s at %s\n", err.Message, err.Path)
-				hasErrors = true
-			}
-		} else {
-			_, _ = fmt.Fprintf(c.output.Stdout(), "    YAML Indentation: ✓\n")
+// ValidateFile runs the vault checks of Validate against a single vault file,
+// which need not be listed in the config. The file is opened without
+// upgrading it, so an older format version is validated as it is on disk.
+func (c *CLI) ValidateFile(path string, jsonOutput bool) *Error {
+	out := c.output.Stdout()
+	if jsonOutput {
+		out = io.Discard
+	}
+	report := newValidateJSON()
+
+	_, _ = fmt.Fprintf(out, "=== DotSecEnv Vault Validation ===\n\n")
+
+	absVaultPath, err := filepath.Abs(path)
+	if err != nil {
+		absVaultPath = path
+	}
+	_, _ = fmt.Fprintf(out, "  Vault: %s\n", absVaultPath)
+
+	fileInfo, err := os.Stat(absVaultPath)
+	if err != nil {
+		return NewError(fmt.Sprintf("vault file not found: %s", absVaultPath), ExitVaultError)
+	}
+	if !fileInfo.Mode().IsRegular() {
+		return NewError(fmt.Sprintf("not a regular file: %s", absVaultPath), ExitVaultError)
+	}
+	if fileInfo.Size() == 0 {
+		_, _ = fmt.Fprintf(out, "    Status: ✗ Vault file is empty (invalid vault structure)\n\n")
+		report.addErrors(absVaultPath, []ValidationError{{Level: "GLOBAL", Message: "vault file is empty"}})
+		return c.finishValidateJSON(jsonOutput, report, c.printValidationResult(out, report, true, false))
+	}
+
+	version, err := vault.DetectVaultVersion(absVaultPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(out, "    Status: ✗ Failed to load: %v\n\n", err)
+		report.addErrors(absVaultPath, []ValidationError{{Level: "GLOBAL", Message: fmt.Sprintf("failed to load: %v", err)}})
+		return c.finishValidateJSON(jsonOutput, report, c.printValidationResult(out, report, true, false))
+	}
+	_, _ = fmt.Fprintf(out, "    Format: v%d\n", version)
+
+	// Require an explicit upgrade so that opening the file never rewrites it.
+	manager := vault.NewManager(absVaultPath, true)
+	if err := manager.OpenAndLock(); err != nil {
+		_, _ = fmt.Fprintf(out, "    Status: ✗ Failed to load: %v\n\n", err)
+		report.addErrors(absVaultPath, []ValidationError{{Level: "GLOBAL", Message: fmt.Sprintf("failed to load: %v", err)}})
+		return c.finishValidateJSON(jsonOutput, report, c.printValidationResult(out, report, true, false))
+	}
+	defer func() { _ = manager.Unlock() }()
+
+	hasErrors, hasHashMismatch, checkErr := c.validateVault(out, absVaultPath, manager, report, false)
+	if checkErr != nil {
+		return c.finishValidateJSON(jsonOutput, report, checkErr)
+	}
+	return c.finishValidateJSON(jsonOutput, report, c.printValidationResult(out, report, hasErrors, hasHashMismatch))
+}
+
+// printValidationResult prints the closing summary shared by Validate and
+// ValidateFile and turns failures into the command's error. Errors in report
+// whose level is below the --fail-on threshold do not fail.
+func (c *CLI) printValidationResult(out io.Writer, report *ValidateJSON, hasErrors, hasHashMismatch bool) *Error {
+	_, _ = fmt.Fprintf(out, "=== Validation Complete ===\n")
+	if hasErrors && c.failOn != nil && c.failingErrorCount(report) == 0 {
+		_, _ = fmt.Fprintf(out, "Status: ⚠ Errors found, but none at a --fail-on level (%s)\n", c.failOnString())
+		return nil
+	}
+	if hasErrors {
+		_, _ = fmt.Fprintf(out, "Status: ✗ Validation failed - see errors above\n")
+		if hasHashMismatch {
+			_, _ = fmt.Fprintf(out, "\nIf you recently upgraded to v0.4, see: %s\n", hashMismatchMigrationURL)
 		}
+		return NewError("", ExitVaultError)
+	}
+	_, _ = fmt.Fprintf(out, "Status: ✓ All checks passed\n")
 
-		orderErrors := validateYAMLFieldOrder(absVaultPath)
-		if len(orderErrors) > 0 {
-			_, _ = fmt.Fprintf(c.output.Stdout(), "    Field Order: ✗ (%d issues)\n", len(orderErrors))
-			for _, err := range orderErrors {
-				_, _ = fmt.Fprintf(c.output.Stdout(), "      - %s at %s\n", err.Message, err.Path)
-				hasErrors = true
-			}
-		} else {
-			_, _ = fmt.Fprintf(c.output.Stdout(), "    Field Order: ✓\n")
+	return nil
+}
+
+// validateVault runs the structural, cryptographic and identity checks on one
+// opened vault file, prints the results to out and adds the problems found
+// to report. With fix, a UTF-8 BOM and CRLF line endings are removed from
+// the file. It reports whether any check failed and whether a hash
+// mismatch was among the failures.
+func (c *CLI) validateVault(out io.Writer, absVaultPath string, manager *vault.Manager, report *ValidateJSON, fix bool) (hasErrors, hasHashMismatch bool, _ *Error) {
+	vaultData := manager.Get()
+
+	_, _ = fmt.Fprintf(out, "    Status: ✓ Valid vault file\n")
+	_, _ = fmt.Fprintf(out, "    Identities: %d\n", len(vaultData.Identities))
+	_, _ = fmt.Fprintf(out, "    Secrets: %d\n", len(vaultData.Secrets))
+
+	_, _ = fmt.Fprintf(out, "\n    === Structural Validation ===\n")
+
+	structErrors := validateYAMLStructure(absVaultPath)
+	report.addErrors(absVaultPath, structErrors)
+	if len(structErrors) > 0 {
+		_, _ = fmt.Fprintf(out, "    YAML Indentation: ✗ (%d issues)\n", len(structErrors))
+		for _, err := range structErrors {
+			_, _ = fmt.Fprintf(out, "      - %s at %s\n", err.Message, err.Path)
+			hasErrors = true
 		}
+	} else {
+		_, _ = fmt.Fprintf(out, "    YAML Indentation: ✓\n")
+	}
 
-		dataErrors := validateVaultData(vaultData, manager)
-		if len(dataErrors) > 0 {
-			_, _ = fmt.Fprintf(c.output.Stdout(), "    Vault Structure: ✗ (%d issues)\n", len(dataErrors))
-			for _, err := range dataErrors {
-				_, _ = fmt.Fprintf(c.output.Stdout(), "      - %s at %s\n", err.Message, err.Path)
-				hasErrors = true
-				if strings.Contains(err.Message, "hash mismatch") {
-					hasHashMismatch = true
-				}
-			}
-		} else {
-			_, _ = fmt.Fprintf(c.output.Stdout(), "    Vault Structure: ✓\n")
+	orderErrors := validateYAMLFieldOrder(absVaultPath)
+	report.addErrors(absVaultPath, orderErrors)
+	if len(orderErrors) > 0 {
+		_, _ = fmt.Fprintf(out, "    Field Order: ✗ (%d issues)\n", len(orderErrors))
+		for _, err := range orderErrors {
+			_, _ = fmt.Fprintf(out, "      - %s at %s\n", err.Message, err.Path)
+			hasErrors = true
 		}
+	} else {
+		_, _ = fmt.Fprintf(out, "    Field Order: ✓\n")
+	}
 
-		encErrors := validateSecretEncryption(vaultData)
-		if len(encErrors) > 0 {
-			_, _ = fmt.Fprintf(c.output.Stdout(), "    Secret Encryption: ✗ (%d issues)\n", len(encErrors))
-			for _, err := range encErrors {
-				_, _ = fmt.Fprintf(c.output.Stdout(), "      - %s at %s\n", err.Message, err.Path)
-				hasErrors = true
+	dataErrors := validateVaultData(vaultData, manager)
+	report.addErrors(absVaultPath, dataErrors)
+	if len(dataErrors) > 0 {
+		_, _ = fmt.Fprintf(out, "    Vault Structure: ✗ (%d issues)\n", len(dataErrors))
+		for _, err := range dataErrors {
+			_, _ = fmt.Fprintf(out, "      - %s at %s\n", err.Message, err.Path)
+			hasErrors = true
+			if strings.Contains(err.Message, "hash mismatch") {
+				hasHashMismatch = true
 			}
-		} else {
-			_, _ = fmt.Fprintf(c.output.Stdout(), "    Secret Encryption: ✓\n")
 		}
+	} else {
+		_, _ = fmt.Fprintf(out, "    Vault Structure: ✓\n")
+	}
 
-		metaErrors := validateSecretMetadata(vaultData)
-		if len(metaErrors) > 0 {
-			_, _ = fmt.Fprintf(c.output.Stdout(), "    Secret Metadata: ✗ (%d issues)\n", len(metaErrors))
-			for _, err := range metaErrors {
-				_, _ = fmt.Fprintf(c.output.Stdout(), "      - %s at %s\n", err.Message, err.Path)
-				hasErrors = true
-			}
-		} else {
-			_, _ = fmt.Fprintf(c.output.Stdout(), "    Secret Metadata: ✓\n")
+	encErrors := validateSecretEncryption(vaultData)
+	report.addErrors(absVaultPath, encErrors)
+	if len(encErrors) > 0 {
+		_, _ = fmt.Fprintf(out, "    Secret Encryption: ✗ (%d issues)\n", len(encErrors))
+		for _, err := range encErrors {
+			_, _ = fmt.Fprintf(out, "      - %s at %s\n", err.Message, err.Path)
+			hasErrors = true
 		}
+	} else {
+		_, _ = fmt.Fprintf(out, "    Secret Encryption: ✓\n")
+	}
 
-		headerErrors := validateHeaderLineNumbers(manager.GetHeader())
-		if len(headerErrors) > 0 {
-			_, _ = fmt.Fprintf(c.output.Stdout(), "    Header Line Numbers: ✗ (%d issues)\n", len(headerErrors))
-			for _, err := range headerErrors {
-				_, _ = fmt.Fprintf(c.output.Stdout(), "      - %s at %s\n", err.Message, err.Path)
-				hasErrors = true
-			}
-		} else {
-			_, _ = fmt.Fprintf(c.output.Stdout(), "    Header Line Numbers: ✓\n")
+	metaErrors := validateSecretMetadata(vaultData)
+	report.addErrors(absVaultPath, metaErrors)
+	if len(metaErrors) > 0 {
+		_, _ = fmt.Fprintf(out, "    Secret Metadata: ✗ (%d issues)\n", len(metaErrors))
+		for _, err := range metaErrors {
+			_, _ = fmt.Fprintf(out, "      - %s at %s\n", err.Message, err.Path)
+			hasErrors = true
 		}
+	} else {
+		_, _ = fmt.Fprintf(out, "    Secret Metadata: ✓\n")
+	}
 
-		fileStructErrors := validateVaultFileStructure(manager.GetHeader(), manager.GetLines())
-		if len(fileStructErrors) > 0 {
-			_, _ = fmt.Fprintf(c.output.Stdout(), "    File Structure: ✗ (%d issues)\n", len(fileStructErrors))
-			for _, err := range fileStructErrors {
-				_, _ = fmt.Fprintf(c.output.Stdout(), "      - %s at %s\n", err.Message, err.Path)
-				hasErrors = true
-			}
-		} else {
-			_, _ = fmt.Fprintf(c.output.Stdout(), "    File Structure: ✓\n")
+	tolerance, toleranceErr := c.config.GetClockSkewTolerance()
+	if toleranceErr != nil {
+		return false, false, NewError(toleranceErr.Error(), ExitConfigError)
+	}
+	// Skewed timestamps are warnings: the values are intact, only their
+	// order may be wrong.
+	skewWarnings := validateValueTimestamps(vaultData, time.Now(), tolerance)
+	report.addWarnings(absVaultPath, skewWarnings)
+	if len(skewWarnings) > 0 {
+		_, _ = fmt.Fprintf(out, "    Value Timestamps: ⚠ (%d warnings)\n", len(skewWarnings))
+		for _, w := range skewWarnings {
+			_, _ = fmt.Fprintf(out, "      - %s at %s\n", w.Message, w.Path)
 		}
+	} else {
+		_, _ = fmt.Fprintf(out, "    Value Timestamps: ✓\n")
+	}
 
-		_, _ = fmt.Fprintf(c.output.Stdout(), "\n    === Identity Validation ===\n")
-
-		if len(vaultData.Identities) > 0 {
-			_, _ = fmt.Fprintf(c.output.Stdout(), "    Identity Details:\n")
-			for _, identity := range vaultData.Identities {
-				keyInfo, err := c.gpgClient.GetPublicKeyInfo(identity.Fingerprint)
-				var algo string
-				var bits int
-				if err != nil {
-					if identity.Curve != "" {
-						algo = identity.Algorithm + " " + identity.Curve
-					} else {
-						algo = identity.Algorithm
-					}
-					// bits removed (see previous fix)
-				} else {
-					algo = keyInfo.Algorithm
-					// bits removed (see previous fix)
-				}
+	headerErrors := validateHeaderLineNumbers(manager.GetHeader())
+	report.addErrors(absVaultPath, headerErrors)
+	if len(headerErrors) > 0 {
+		_, _ = fmt.Fprintf(out, "    Header Line Numbers: ✗ (%d issues)\n", len(headerErrors))
+		for _, err := range headerErrors {
+			_, _ = fmt.Fprintf(out, "      - %s at %s\n", err.Message, err.Path)
+			hasErrors = true
+		}
+	} else {
+		_, _ = fmt.Fprintf(out, "    Header Line Numbers: ✓\n")
+	}
 
-				name, bits := crypto.GetAlgorithmDetails(algo)
-				if bits > 0 {
-					_, _ = fmt.Fprintf(c.output.Stdout(), "      - %s (%s %d bits)", identity.UID, name, bits)
+	fileStructErrors := validateVaultFileStructure(manager.GetHeader(), manager.GetLines())
+	report.addErrors(absVaultPath, fileStructErrors)
+	if len(fileStructErrors) > 0 {
+		_, _ = fmt.Fprintf(out, "    File Structure: ✗ (%d issues)\n", len(fileStructErrors))
+		for _, err := range fileStructErrors {
+			_, _ = fmt.Fprintf(out, "      - %s at %s\n", err.Message, err.Path)
+			hasErrors = true
+		}
+	} else {
+		_, _ = fmt.Fprintf(out, "    File Structure: ✓\n")
+	}
+
+	encodingWarnings, encodingErr := c.validateVaultEncoding(out, absVaultPath, manager, fix)
+	if encodingErr != nil {
+		return false, false, encodingErr
+	}
+	report.addWarnings(absVaultPath, encodingWarnings)
+
+	_, _ = fmt.Fprintf(out, "\n    === Identity Validation ===\n")
+
+	if len(vaultData.Identities) > 0 {
+		_, _ = fmt.Fprintf(out, "    Identity Details:\n")
+		for _, identity := range vaultData.Identities {
+			keyInfo, err := c.gpgClient.GetPublicKeyInfo(identity.Fingerprint)
+			var algo string
+			var bits int
+			if err != nil {
+				if identity.Curve != "" {
+					algo = identity.Algorithm + " " + identity.Curve
 				} else {
-					_, _ = fmt.Fprintf(c.output.Stdout(), "      - %s (%s)", identity.UID, name)
+					algo = identity.Algorithm
 				}
+				// bits removed (see previous fix)
+			} else {
+				algo = keyInfo.Algorithm
+				// bits removed (see previous fix)
+			}
 
-				if c.config.IsAlgorithmAllowed(algo, bits) {
-					_, _ = fmt.Fprintf(c.output.Stdout(), " ✓\n")
-				} else {
-					_, _ = fmt.Fprintf(c.output.Stdout(), " ✗ (not allowed by requirements)\n")
-					return NewError(fmt.Sprintf("algorithm not allowed: %s", algo), ExitAlgorithmNotAllowed)
+			name, bits := crypto.GetAlgorithmDetails(algo)
+			if bits > 0 {
+				_, _ = fmt.Fprintf(out, "      - %s (%s %d bits)", identity.UID, name, bits)
+			} else {
+				_, _ = fmt.Fprintf(out, "      - %s (%s)", identity.UID, name)
+			}
+
+			if c.config.IsAlgorithmAllowed(algo, bits) {
+				_, _ = fmt.Fprintf(out, " ✓\n")
+			} else {
+				_, _ = fmt.Fprintf(out, " ✗ (not allowed by requirements)\n")
+				report.addErrors(absVaultPath, []ValidationError{{Level: "IDENTITY", Message: fmt.Sprintf("algorithm not allowed: %s", algo), Path: identity.Fingerprint}})
+				if !c.failsOnLevel("IDENTITY") {
+					hasErrors = true
+					continue
 				}
+				return hasErrors, hasHashMismatch, NewError(fmt.Sprintf("algorithm not allowed: %s", algo), ExitAlgorithmNotAllowed)
 			}
 		}
+	}
 
-		_, _ = fmt.Fprintf(c.output.Stdout(), "\n    === Secret Validation ===\n")
+	_, _ = fmt.Fprintf(out, "\n    === Secret Validation ===\n")
 
-		if len(vaultData.Secrets) > 0 {
-			_, _ = fmt.Fprintf(c.output.Stdout(), "    Secret Details:\n")
-			secretKeys := make([]string, 0, len(vaultData.Secrets))
-			secretMap := make(map[string]*vault.Secret)
-			for i := range vaultData.Secrets {
-				key := vaultData.Secrets[i].Key
-				secretKeys = append(secretKeys, key)
-				secretMap[key] = &vaultData.Secrets[i]
-			}
-			sort.Strings(secretKeys)
+	if len(vaultData.Secrets) > 0 {
+		_, _ = fmt.Fprintf(out, "    Secret Details:\n")
+		secretKeys := make([]string, 0, len(vaultData.Secrets))
+		secretMap := make(map[string]*vault.Secret)
+		for i := range vaultData.Secrets {
+			key := vaultData.Secrets[i].Key
+			secretKeys = append(secretKeys, key)
+			secretMap[key] = &vaultData.Secrets[i]
+		}
+		sort.Strings(secretKeys)
 
-			for _, key := range secretKeys {
-				secret := secretMap[key]
-				_, _ = fmt.Fprintf(c.output.Stdout(), "      - %s: %d value(s)", secret.Key, len(secret.Values))
+		for _, key := range secretKeys {
+			secret := secretMap[key]
+			_, _ = fmt.Fprintf(out, "      - %s: %d value(s)", secret.Key, len(secret.Values))
 
-				if secret.Signature == "" {
-					_, _ = fmt.Fprintf(c.output.Stdout(), " ✗ (missing signature)")
+			if secret.Signature == "" {
+				_, _ = fmt.Fprintf(out, " ✗ (missing signature)")
+				hasErrors = true
+			} else {
+				_, _ = fmt.Fprintf(out, " ✓")
+			}
+			_, _ = fmt.Fprintf(out, "\n")
+
+			for j, value := range secret.Values {
+				if value.Signature == "" {
+					_, _ = fmt.Fprintf(out, "        [%d] ✗ Missing signature\n", j+1)
 					hasErrors = true
 				} else {
-					_, _ = fmt.Fprintf(c.output.Stdout(), " ✓")
-				}
-				_, _ = fmt.Fprintf(c.output.Stdout(), "\n")
-
-				for j, value := range secret.Values {
-					if value.Signature == "" {
-						_, _ = fmt.Fprintf(c.output.Stdout(), "        [%d] ✗ Missing signature\n", j+1)
-						hasErrors = true
-					} else {
-						_, _ = fmt.Fprintf(c.output.Stdout(), "        [%d] added at %s ✓\n", j+1, value.AddedAt.Format("2006-01-02 15:04:05"))
-					}
+					_, _ = fmt.Fprintf(out, "        [%d] added at %s ✓\n", j+1, value.AddedAt.Format("2006-01-02 15:04:05"))
 				}
 			}
 		}
-		_, _ = fmt.Fprintf(c.output.Stdout(), "\n")
 	}
+	_, _ = fmt.Fprintf(out, "\n")
 
-	if vaultCount == 0 {
-		_, _ = fmt.Fprintf(c.output.Stdout(), "  (No vaults configured)\n\n")
+	return hasErrors, hasHashMismatch, nil
+}
+
+// validationLevels lists the ValidationError levels --fail-on accepts.
+var validationLevels = []string{"STRUCTURE", "IDENTITY", "SECRET", "GLOBAL"}
+
+// parseFailOn parses --fail-on values into the set of levels whose errors
+// fail validation, or nil for every level. "any" (the default) selects every
+// level and "none" no level; both must be given alone. Level names are
+// case-insensitive.
+func parseFailOn(values []string) (map[string]bool, *Error) {
+	if len(values) == 0 {
+		return nil, nil
 	}
+	levels := make(map[string]bool)
+	for _, v := range values {
+		name := strings.ToUpper(strings.TrimSpace(v))
+		switch {
+		case name == "ANY" || name == "NONE":
+			if len(values) > 1 {
+				return nil, NewError(fmt.Sprintf("--fail-on %s cannot be combined with other levels", strings.ToLower(name)), ExitValidationError)
+			}
+			if name == "ANY" {
+				return nil, nil
+			}
+		case slices.Contains(validationLevels, name):
+			levels[name] = true
+		default:
+			return nil, NewError(fmt.Sprintf("invalid --fail-on level %q (one of: %s, any, none)", v, strings.Join(validationLevels, ", ")), ExitValidationError)
+		}
+	}
+	return levels, nil
+}
 
-	_, _ = fmt.Fprintf(c.output.Stdout(), "=== Validation Complete ===\n")
-	if hasErrors {
-		_, _ = fmt.Fprintf(c.output.Stdout(), "Status: ✗ Validation failed - see errors above\n")
-		if hasHashMismatch {
-			_, _ = fmt.Fprintf(c.output.Stdout(), "\nIf you recently upgraded to v0.4, see: %s\n", hashMismatchMigrationURL)
+// failsOnLevel reports whether errors of level fail validation.
+func (c *CLI) failsOnLevel(level string) bool {
+	return c.failOn == nil || c.failOn[level]
+}
+
+// failingErrorCount counts the errors in report that fail validation.
+func (c *CLI) failingErrorCount(report *ValidateJSON) int {
+	count := 0
+	for _, e := range report.Errors {
+		if c.failsOnLevel(e.Level) {
+			count++
 		}
-		return NewError("", ExitVaultError)
 	}
-	_, _ = fmt.Fprintf(c.output.Stdout(), "Status: ✓ All checks passed\n")
+	return count
+}
 
-	return nil
+// failOnString describes the --fail-on levels for the summary.
+func (c *CLI) failOnString() string {
+	var levels []string
+	for _, level := range validationLevels {
+		if c.failOn[level] {
+			levels = append(levels, level)
+		}
+	}
+	if len(levels) == 0 {
+		return "none"
+	}
+	return strings.Join(levels, ", ")
+}
+
+// validateVaultEncoding checks the vault file for a UTF-8 BOM and CRLF line
+// endings, prints the result to out and returns them as warnings: the vault
+// still loads, but its next write changes every line. With fix, the file is
+// rewritten with LF endings instead, and the post_write hook runs.
+func (c *CLI) validateVaultEncoding(out io.Writer, absVaultPath string, manager *vault.Manager, fix bool) ([]ValidationError, *Error) {
+	issues, err := vault.DetectEncodingIssues(absVaultPath)
+	if err != nil {
+		return nil, NewError(err.Error(), ExitVaultError)
+	}
+	if !issues.Any() {
+		_, _ = fmt.Fprintf(out, "    Line Endings: ✓\n")
+		return nil, nil
+	}
+
+	var warnings []ValidationError
+	if issues.BOM {
+		warnings = append(warnings, ValidationError{Level: "STRUCTURE", Message: "vault file starts with a UTF-8 BOM", Path: "line 1"})
+	}
+	if issues.CRLF {
+		warnings = append(warnings, ValidationError{Level: "STRUCTURE", Message: "vault file uses CRLF line endings", Path: absVaultPath})
+	}
+
+	if fix {
+		if err := manager.Normalize(); err != nil {
+			return nil, NewError(fmt.Sprintf("failed to rewrite vault: %v", err), ExitVaultError)
+		}
+		_, _ = fmt.Fprintf(out, "    Line Endings: ✓ (fixed %d issue(s), rewrote with LF endings)\n", len(warnings))
+		return nil, c.runPostWriteHookForPath(absVaultPath)
+	}
+
+	_, _ = fmt.Fprintf(out, "    Line Endings: ⚠ (%d warnings, run 'dotsecenv validate --fix' to rewrite)\n", len(warnings))
+	for _, w := range warnings {
+		_, _ = fmt.Fprintf(out, "      - %s at %s\n", w.Message, w.Path)
+	}
+	return warnings, nil
+}
+
+// ValidateJSON is the JSON output of 'validate --json'. Summary counts the
+// errors by level, and always holds every level.
+type ValidateJSON struct {
+	Errors   []ValidateIssueJSON `json:"errors"`
+	Warnings []ValidateIssueJSON `json:"warnings"`
+	Summary  map[string]int      `json:"summary"`
+}
+
+// ValidateIssueJSON is one problem found by 'validate --json'.
+type ValidateIssueJSON struct {
+	Vault   string `json:"vault"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+	Path    string `json:"path,omitempty"`
+}
+
+// newValidateJSON returns an empty report with a zero count per level.
+func newValidateJSON() *ValidateJSON {
+	return &ValidateJSON{
+		Errors:   []ValidateIssueJSON{},
+		Warnings: []ValidateIssueJSON{},
+		Summary:  map[string]int{"STRUCTURE": 0, "IDENTITY": 0, "SECRET": 0, "GLOBAL": 0},
+	}
+}
+
+// addErrors records errs found in the vault at vaultPath.
+func (r *ValidateJSON) addErrors(vaultPath string, errs []ValidationError) {
+	for _, e := range errs {
+		r.Errors = append(r.Errors, ValidateIssueJSON{Vault: vaultPath, Level: e.Level, Message: e.Message, Path: e.Path})
+		r.Summary[e.Level]++
+	}
+}
+
+// addWarnings records warnings found in the vault at vaultPath. They are
+// not counted in the summary.
+func (r *ValidateJSON) addWarnings(vaultPath string, warnings []ValidationError) {
+	for _, w := range warnings {
+		r.Warnings = append(r.Warnings, ValidateIssueJSON{Vault: vaultPath, Level: w.Level, Message: w.Message, Path: w.Path})
+	}
+}
+
+// finishValidateJSON prints report when jsonOutput is set, and returns
+// result, the error the text report ended with. Validation failures leave
+// the JSON on stdout and exit non-zero without a message.
+func (c *CLI) finishValidateJSON(jsonOutput bool, report *ValidateJSON, result *Error) *Error {
+	if !jsonOutput {
+		return result
+	}
+	if err := c.output.EncodeJSON(report); err != nil {
+		return NewError(fmt.Sprintf("failed to encode json: %v", err), ExitGeneralError)
+	}
+	return result
 }
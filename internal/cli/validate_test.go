@@ -0,0 +1,283 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vaulttest"
+)
+
+func newValidateFileCLI() (*CLI, *bytes.Buffer) {
+	stdout := &bytes.Buffer{}
+	cli := &CLI{
+		config: config.Config{
+			ApprovedAlgorithms: []config.ApprovedAlgorithm{
+				{Algo: "RSA", MinBits: 2048},
+			},
+		},
+		gpgClient: NewMockGPGClient(),
+		output:    output.NewHandler(stdout, &bytes.Buffer{}),
+	}
+	return cli, stdout
+}
+
+func TestValidateFile_Fixtures(t *testing.T) {
+	for _, version := range []string{"v1", "v2"} {
+		t.Run(version, func(t *testing.T) {
+			fixture, err := os.ReadFile(filepath.Join("..", "..", "pkg", "dotsecenv", "vault", "testdata", "vault_"+version+".jsonl"))
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+			path := filepath.Join(t.TempDir(), "vault")
+			if err := os.WriteFile(path, fixture, 0600); err != nil {
+				t.Fatalf("failed to write vault: %v", err)
+			}
+
+			cli, stdout := newValidateFileCLI()
+
+			// The fixtures carry placeholder hashes and signatures, so the
+			// cryptographic checks must fail while the structure passes.
+			exitErr := cli.ValidateFile(path, false)
+			if exitErr == nil || exitErr.ExitCode != ExitVaultError {
+				t.Fatalf("expected vault validation failure, got %v\n%s", exitErr, stdout.String())
+			}
+
+			text := stdout.String()
+			for _, want := range []string{
+				"Format: " + version,
+				"Identities: 2",
+				"Secrets: 1",
+				"File Structure: ✓",
+				"Header Line Numbers: ✓",
+				"Vault Structure: ✗",
+				"identity signature is not valid hex encoding at identities[0] (test1@example.com)",
+				"Status: ✗ Validation failed",
+			} {
+				if !strings.Contains(text, want) {
+					t.Errorf("expected %q in output:\n%s", want, text)
+				}
+			}
+
+			after, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to re-read vault: %v", err)
+			}
+			if !bytes.Equal(after, fixture) {
+				t.Errorf("validate modified the vault file")
+			}
+		})
+	}
+}
+
+func TestValidateFile_JSON(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("..", "..", "pkg", "dotsecenv", "vault", "testdata", "vault_v2.jsonl"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "vault")
+	if err := os.WriteFile(path, fixture, 0600); err != nil {
+		t.Fatalf("failed to write vault: %v", err)
+	}
+
+	cli, stdout := newValidateFileCLI()
+
+	// The fixture's placeholder hashes and signatures stand in for tampering
+	exitErr := cli.ValidateFile(path, true)
+	if exitErr == nil || exitErr.ExitCode != ExitVaultError {
+		t.Fatalf("expected vault validation failure, got %v\n%s", exitErr, stdout.String())
+	}
+
+	var report ValidateJSON
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout.String())
+	}
+	for _, level := range []string{"STRUCTURE", "IDENTITY", "SECRET", "GLOBAL"} {
+		if _, ok := report.Summary[level]; !ok {
+			t.Errorf("expected summary count for %s, got %v", level, report.Summary)
+		}
+	}
+	if len(report.Errors) == 0 {
+		t.Fatal("expected errors in the report")
+	}
+	counts := make(map[string]int)
+	for _, e := range report.Errors {
+		counts[e.Level]++
+		if e.Vault == "" || e.Message == "" {
+			t.Errorf("incomplete error entry: %+v", e)
+		}
+	}
+	for level, n := range counts {
+		if report.Summary[level] != n {
+			t.Errorf("summary[%s] = %d, want %d", level, report.Summary[level], n)
+		}
+	}
+	if report.Summary["IDENTITY"] == 0 {
+		t.Errorf("expected identity errors for the invalid signatures, got %v", report.Summary)
+	}
+}
+
+func TestValidateFile_Missing(t *testing.T) {
+	cli, _ := newValidateFileCLI()
+
+	exitErr := cli.ValidateFile(filepath.Join(t.TempDir(), "missing"), false)
+	if exitErr == nil || exitErr.ExitCode != ExitVaultError || !strings.Contains(exitErr.Message, "vault file not found") {
+		t.Errorf("expected not-found error, got %v", exitErr)
+	}
+}
+
+func TestValidate_FixRewritesWindowsLineEndings(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("..", "..", "pkg", "dotsecenv", "vault", "testdata", "vault_v2.jsonl"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "vault")
+	dirty := append([]byte("\ufeff"), bytes.ReplaceAll(fixture, []byte("\n"), []byte("\r\n"))...)
+	if err := os.WriteFile(path, dirty, 0600); err != nil {
+		t.Fatalf("failed to write vault: %v", err)
+	}
+
+	// Without --fix the vault loads and the encoding is only a warning
+	cli, stdout := newValidateFileCLI()
+	_ = cli.ValidateFile(path, false)
+	for _, want := range []string{
+		"Line Endings: ⚠ (2 warnings",
+		"vault file starts with a UTF-8 BOM at line 1",
+		"vault file uses CRLF line endings",
+		"Identities: 2",
+	} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("expected %q in output:\n%s", want, stdout.String())
+		}
+	}
+	if after, _ := os.ReadFile(path); !bytes.Equal(after, dirty) {
+		t.Fatalf("validate without --fix modified the vault file")
+	}
+
+	configPath := filepath.Join(dir, "config")
+	if err := os.WriteFile(configPath, []byte("vault: []\n"), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	manager := vault.NewManager(path, true)
+	if err := manager.OpenAndLock(); err != nil {
+		t.Fatalf("OpenAndLock failed: %v", err)
+	}
+	defer func() { _ = manager.Unlock() }()
+	mock := NewMockVaultResolver()
+	mock.VaultEntries = []vault.VaultEntry{{Path: path}}
+	mock.Managers = map[int]*vault.Manager{0: manager}
+	cli, stdout = newValidateFileCLI()
+	cli.configPath = configPath
+	cli.vaultResolver = mock
+
+	// The fixture's placeholder signatures still fail validation
+	_ = cli.Validate(true, false)
+	if !strings.Contains(stdout.String(), "Line Endings: ✓ (fixed 2 issue(s)") {
+		t.Errorf("expected the encoding to be fixed:\n%s", stdout.String())
+	}
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to re-read vault: %v", err)
+	}
+	if bytes.HasPrefix(after, []byte("\ufeff")) || bytes.Contains(after, []byte("\r\n")) {
+		t.Errorf("expected a BOM-free LF vault, got:\n%q", after)
+	}
+}
+
+// writeStructureOnlyVault writes a correctly signed vault file whose only
+// problem is a misindented line, a STRUCTURE error.
+func writeStructureOnlyVault(t *testing.T) string {
+	t.Helper()
+	alice, err := vaulttest.NewIdentity("Alice", "alice@example.com")
+	if err != nil {
+		t.Fatalf("NewIdentity failed: %v", err)
+	}
+	v, err := vaulttest.BuildSignedVault([]*vaulttest.Identity{alice}, []vault.Secret{
+		{Key: "DB_PASSWORD", Values: []vault.SecretValue{
+			{AvailableTo: []string{alice.Fingerprint}, Value: "Y2lwaGVy"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("BuildSignedVault failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "vault")
+	w, err := vault.NewWriter(path)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	if err := w.RewriteFromVault(v); err != nil {
+		t.Fatalf("RewriteFromVault failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read vault: %v", err)
+	}
+	lines := strings.Split(string(data), "\n")
+	lines[len(lines)-2] = " " + lines[len(lines)-2] // the last entry
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatalf("failed to write vault: %v", err)
+	}
+	return path
+}
+
+func TestValidateFile_FailOnThreshold(t *testing.T) {
+	path := writeStructureOnlyVault(t)
+
+	tests := []struct {
+		failOn []string
+		fails  bool
+	}{
+		{nil, true},
+		{[]string{"any"}, true},
+		{[]string{"STRUCTURE"}, true},
+		{[]string{"structure", "SECRET"}, true},
+		{[]string{"SECRET", "IDENTITY"}, false},
+		{[]string{"GLOBAL"}, false},
+		{[]string{"none"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.failOn, ","), func(t *testing.T) {
+			cli, stdout := newValidateFileCLI()
+			cli.config.ApprovedAlgorithms = append(cli.config.ApprovedAlgorithms,
+				config.ApprovedAlgorithm{Algo: "EdDSA", Curves: []string{"Ed25519"}, MinBits: 255})
+			if err := cli.SetValidateFailOn(tt.failOn); err != nil {
+				t.Fatalf("SetValidateFailOn failed: %v", err)
+			}
+
+			exitErr := cli.ValidateFile(path, false)
+			text := stdout.String()
+			if !strings.Contains(text, "invalid indentation") {
+				t.Errorf("the structural error must be reported regardless of --fail-on:\n%s", text)
+			}
+			if tt.fails {
+				if exitErr == nil || exitErr.ExitCode != ExitVaultError {
+					t.Errorf("expected a vault error, got %v\n%s", exitErr, text)
+				}
+				return
+			}
+			if exitErr != nil {
+				t.Errorf("expected success below the threshold, got %v\n%s", exitErr, text)
+			}
+			if !strings.Contains(text, "none at a --fail-on level") {
+				t.Errorf("expected the threshold summary:\n%s", text)
+			}
+		})
+	}
+}
+
+func TestSetValidateFailOn_Invalid(t *testing.T) {
+	cli, _ := newValidateFileCLI()
+	for _, failOn := range [][]string{{"WARN"}, {"any", "SECRET"}, {"none", "STRUCTURE"}} {
+		if err := cli.SetValidateFailOn(failOn); err == nil || err.ExitCode != ExitValidationError {
+			t.Errorf("SetValidateFailOn(%v): expected a validation error, got %v", failOn, err)
+		}
+	}
+}
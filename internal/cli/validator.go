@@ -5,12 +5,13 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
-	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
 	"gopkg.in/yaml.v3"
@@ -26,11 +27,12 @@ type ValidationError struct {
 	Path    string // For errors in specific items
 }
 
-// validateYAMLStructure checks YAML formatting requirements
+// validateYAMLStructure checks YAML formatting requirements. Compressed
+// vaults are checked on their decompressed content.
 func validateYAMLStructure(filePath string) []ValidationError {
 	var errors []ValidationError
 
-	content, err := os.ReadFile(filePath)
+	content, err := vault.ReadFile(filePath)
 	if err != nil {
 		errors = append(errors, ValidationError{
 			Level:   "STRUCTURE",
@@ -82,7 +84,7 @@ func validateYAMLStructure(filePath string) []ValidationError {
 func validateYAMLFieldOrder(filePath string) []ValidationError {
 	var errors []ValidationError
 
-	content, err := os.ReadFile(filePath)
+	content, err := vault.ReadFile(filePath)
 	if err != nil {
 		return errors
 	}
@@ -130,8 +132,37 @@ func validateYAMLFieldOrder(filePath string) []ValidationError {
 	return errors
 }
 
+// structureCheck returns the check that refuses to open a vault file that
+// is not in canonical structural form when strict_structure is set, or nil.
+func structureCheck(cfg config.Config) func(path string) error {
+	if !cfg.StrictStructure {
+		return nil
+	}
+	return checkCanonicalStructure
+}
+
+// checkCanonicalStructure returns an error naming the first structural
+// problem 'validate' reports for the vault file at path, or nil.
+func checkCanonicalStructure(path string) error {
+	problems := validateYAMLStructure(path)
+	if len(problems) == 0 {
+		problems = validateYAMLFieldOrder(path)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("not in canonical structure (strict_structure is set): %s at %s; run 'dotsecenv validate %s' for all problems",
+		problems[0].Message, problems[0].Path, path)
+}
+
+// identityLookup finds an identity by fingerprint. *vault.Manager and
+// vault.Vault both provide it.
+type identityLookup interface {
+	GetIdentityByFingerprint(fingerprint string) *vault.Identity
+}
+
 // validateVaultData checks vault logical structure
-func validateVaultData(vaultData vault.Vault, manager *vault.Manager) []ValidationError {
+func validateVaultData(vaultData vault.Vault, manager identityLookup) []ValidationError {
 	var errors []ValidationError
 
 	// Check 1: Identities sorted by added_at (most recent last = ascending order)
@@ -273,6 +304,14 @@ func validateVaultData(vaultData vault.Vault, manager *vault.Manager) []Validati
 			continue
 		}
 
+		if msg := checkHashAlgorithm(secret.Hash, signingIdentity); msg != "" {
+			errors = append(errors, ValidationError{
+				Level:   "SECRET",
+				Message: msg,
+				Path:    fmt.Sprintf("secrets[%d] (%s)", i, secret.Key),
+			})
+		}
+
 		// Check secret signature is valid hex and cryptographically valid
 		if secret.Signature != "" {
 			if !isValidHex(secret.Signature) {
@@ -313,6 +352,16 @@ func validateVaultData(vaultData vault.Vault, manager *vault.Manager) []Validati
 				continue
 			}
 
+			if valueSigningIdentity != nil {
+				if msg := checkHashAlgorithm(value.Hash, valueSigningIdentity); msg != "" {
+					errors = append(errors, ValidationError{
+						Level:   "SECRET",
+						Message: msg,
+						Path:    fmt.Sprintf("secrets[%d].values[%d] (%s)", i, j, secret.Key),
+					})
+				}
+			}
+
 			if value.Signature != "" {
 				if !isValidHex(value.Signature) {
 					errors = append(errors, ValidationError{
@@ -341,9 +390,87 @@ func validateVaultData(vaultData vault.Vault, manager *vault.Manager) []Validati
 		}
 	}
 
+	// Check 5: Verify revocation signatures are valid
+	for i, revocation := range vaultData.Revocations {
+		path := fmt.Sprintf("revocations[%d] (%s)", i, revocation.Fingerprint)
+		if revocation.Signature == "" {
+			errors = append(errors, ValidationError{
+				Level:   "IDENTITY",
+				Message: "revocation has missing signature field",
+				Path:    path,
+			})
+		}
+
+		signingIdentity := manager.GetIdentityByFingerprint(revocation.SignedBy)
+		if signingIdentity == nil {
+			errors = append(errors, ValidationError{
+				Level:   "IDENTITY",
+				Message: fmt.Sprintf("signing identity not found: %s", revocation.SignedBy),
+				Path:    path,
+			})
+			continue
+		}
+
+		if msg := checkHashAlgorithm(revocation.Hash, signingIdentity); msg != "" {
+			errors = append(errors, ValidationError{
+				Level:   "IDENTITY",
+				Message: msg,
+				Path:    path,
+			})
+		}
+
+		if revocation.Signature != "" {
+			if !isValidHex(revocation.Signature) {
+				errors = append(errors, ValidationError{
+					Level:   "IDENTITY",
+					Message: "revocation signature is not valid hex encoding",
+					Path:    path,
+				})
+			} else {
+				valid, err := verifyRevocationSignature(&vaultData.Revocations[i], signingIdentity)
+				if err != nil {
+					errors = append(errors, ValidationError{
+						Level:   "IDENTITY",
+						Message: fmt.Sprintf("failed to verify revocation signature: %v", err),
+						Path:    path,
+					})
+				} else if !valid {
+					errors = append(errors, ValidationError{
+						Level:   "IDENTITY",
+						Message: "revocation signature verification failed - possible tampering",
+						Path:    path,
+					})
+				}
+			}
+		}
+	}
+
 	return errors
 }
 
+// validateValueTimestamps flags secret values whose added_at is more than
+// tolerance after now. added_at comes from the clock of the machine that
+// wrote the value, so a skewed clock can put a value out of the
+// most-recent-last order that --last and history resolution rely on.
+func validateValueTimestamps(vaultData vault.Vault, now time.Time, tolerance time.Duration) []ValidationError {
+	var warnings []ValidationError
+	limit := now.Add(tolerance)
+
+	for i, secret := range vaultData.Secrets {
+		for j, value := range secret.Values {
+			if value.AddedAt.After(limit) {
+				warnings = append(warnings, ValidationError{
+					Level:   "SECRET",
+					Message: fmt.Sprintf("added_at %s is %s in the future; the writer's clock may be wrong", value.AddedAt.Format(time.RFC3339), value.AddedAt.Sub(now).Round(time.Second)),
+					Path:    fmt.Sprintf("secrets[%d].values[%d] (%s)", i, j, secret.Key),
+				})
+			}
+		}
+	}
+
+	return warnings
+}
+
 // validateSecretEncryption checks that all secret values can be decrypted
 func validateSecretEncryption(vaultData vault.Vault) []ValidationError {
 	var errors []ValidationError
@@ -416,10 +543,10 @@ func validateVaultFileStructure(header *vault.Header, lines []string) []Validati
 	}
 
 	// Check version from header JSON (line 2, index 1)
-	if header != nil && (header.Version < vault.MinSupportedVersion || header.Version > vault.LatestFormatVersion) {
+	if header != nil && (header.Version < vault.MinSupportedVersion || header.Version > vault.MaxSupportedVersion) {
 		errors = append(errors, ValidationError{
 			Level:   "STRUCTURE",
-			Message: fmt.Sprintf("unsupported vault format version %d (supported: v%d-v%d)", header.Version, vault.MinSupportedVersion, vault.LatestFormatVersion),
+			Message: fmt.Sprintf("unsupported vault format version %d (supported: v%d-v%d)", header.Version, vault.MinSupportedVersion, vault.MaxSupportedVersion),
 			Path:    "line 2 (header JSON)",
 		})
 	}
@@ -476,6 +603,36 @@ func validateVaultFileStructure(header *vault.Header, lines []string) []Validati
 		}
 	}
 
+	// Check that revocation entries match their header references
+	for i, revocationLine := range header.Revocations {
+		if revocationLine < 1 || revocationLine > len(lines) {
+			errors = append(errors, ValidationError{
+				Level:   "STRUCTURE",
+				Message: fmt.Sprintf("revocation line number %d out of range (file has %d lines)", revocationLine, len(lines)),
+				Path:    fmt.Sprintf("header.revocations[%d]", i),
+			})
+			continue
+		}
+
+		entry, err := vault.UnmarshalEntry([]byte(lines[revocationLine-1]))
+		if err != nil {
+			errors = append(errors, ValidationError{
+				Level:   "STRUCTURE",
+				Message: fmt.Sprintf("failed to parse entry at line %d: %v", revocationLine, err),
+				Path:    fmt.Sprintf("header.revocations[%d]", i),
+			})
+			continue
+		}
+
+		if entry.Type != vault.EntryTypeRevocation {
+			errors = append(errors, ValidationError{
+				Level:   "STRUCTURE",
+				Message: fmt.Sprintf("line %d is not a revocation entry (type=%s)", revocationLine, entry.Type),
+				Path:    fmt.Sprintf("header.revocations[%d]", i),
+			})
+		}
+	}
+
 	return errors
 }
 
@@ -558,9 +715,52 @@ func validateHeaderLineNumbers(header *vault.Header) []ValidationError {
 		}
 	}
 
+	// Check 3: Revocation line numbers, strictly ascending like value lines
+	for i, revocationLine := range header.Revocations {
+		if revocationLine < 1 {
+			errors = append(errors, ValidationError{
+				Level:   "STRUCTURE",
+				Message: fmt.Sprintf("revocation has invalid line number %d (must be >= 1)", revocationLine),
+				Path:    fmt.Sprintf("header.revocations[%d]", i),
+			})
+		}
+		if existing, exists := allLineNumbers[revocationLine]; exists {
+			errors = append(errors, ValidationError{
+				Level:   "STRUCTURE",
+				Message: fmt.Sprintf("duplicate line number %d: used by %s and revocation[%d]", revocationLine, existing, i),
+				Path:    "header",
+			})
+		}
+		allLineNumbers[revocationLine] = fmt.Sprintf("revocation[%d]", i)
+
+		if i > 0 && header.Revocations[i-1] >= revocationLine {
+			errors = append(errors, ValidationError{
+				Level:   "STRUCTURE",
+				Message: fmt.Sprintf("revocation line numbers not strictly ascending: revocations[%d]=%d >= revocations[%d]=%d", i-1, header.Revocations[i-1], i, revocationLine),
+				Path:    "header.revocations",
+			})
+		}
+	}
+
 	return errors
 }
 
+// checkHashAlgorithm verifies that a stored hash has the length of the hash
+// algorithm mandated by the signer's key size. A shorter hash than the key
+// warrants indicates a downgrade (or tampering). Returns an empty string when
+// the hash is absent or has the expected length.
+func checkHashAlgorithm(hash string, signingIdentity *vault.Identity) string {
+	if hash == "" {
+		return ""
+	}
+	expected := identity.HashHexLength(signingIdentity.AlgorithmBits)
+	if len(hash) == expected {
+		return ""
+	}
+	return fmt.Sprintf("hash length %d does not match the %d hex characters required for a %d-bit signing key - possible downgrade or tampering",
+		len(hash), expected, signingIdentity.AlgorithmBits)
+}
+
 // isValidHex checks if a string is valid hex encoding
 func isValidHex(s string) bool {
 	if len(s)%2 != 0 {
@@ -637,6 +837,18 @@ func verifySecretValueSignature(value *vault.SecretValue, secretKey string, sign
 	return verifySignatureWithPublicKey(signingIdentity.PublicKey, []byte(value.Hash), value.Signature)
 }
 
+// verifyRevocationSignature verifies the cryptographic signature of an identity revocation
+func verifyRevocationSignature(revocation *vault.Revocation, signingIdentity *vault.Identity) (bool, error) {
+	// Step 1: Verify hash (tampering detection) using shared hash computation
+	computedHash := vault.ComputeRevocationHash(revocation, signingIdentity.AlgorithmBits)
+	if computedHash != revocation.Hash {
+		return false, fmt.Errorf("hash mismatch: computed %s, stored %s", computedHash, revocation.Hash)
+	}
+
+	// Step 2: Verify signature of hash (identity verification)
+	return verifySignatureWithPublicKey(signingIdentity.PublicKey, []byte(revocation.Hash), revocation.Signature)
+}
+
 // verifySignatureWithPublicKey performs cryptographic verification of a detached signature.
 // The signature is stored as hex-encoded binary (created by gpg --detach-sign).
 // The public key is base64-encoded and stored in the vault's identities list.
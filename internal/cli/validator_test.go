@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vaulttest"
+)
+
+func TestValidateVaultData_HashDowngrade(t *testing.T) {
+	const signer = "SIGNERFP"
+	now := time.Now().UTC()
+
+	tests := []struct {
+		name          string
+		algorithmBits int
+		hashLen       int
+		wantFlagged   bool
+	}{
+		{name: "sha512 for 4096-bit key", algorithmBits: 4096, hashLen: 128},
+		{name: "sha256 for 4096-bit key", algorithmBits: 4096, hashLen: 64, wantFlagged: true},
+		{name: "sha256 for 255-bit key", algorithmBits: 255, hashLen: 64},
+		{name: "sha512 for 255-bit key", algorithmBits: 255, hashLen: 128, wantFlagged: true},
+		{name: "truncated hash", algorithmBits: 256, hashLen: 100, wantFlagged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash := strings.Repeat("a", tt.hashLen)
+			v := vault.Vault{
+				Identities: []vault.Identity{{
+					AddedAt:       now,
+					AlgorithmBits: tt.algorithmBits,
+					Fingerprint:   signer,
+					UID:           "Signer",
+				}},
+				Secrets: []vault.Secret{{
+					AddedAt:  now,
+					Hash:     hash,
+					Key:      "KEY",
+					SignedBy: signer,
+					Values: []vault.SecretValue{{
+						AddedAt:     now,
+						AvailableTo: []string{signer},
+						Hash:        hash,
+						SignedBy:    signer,
+						Value:       "dmFsdWU=",
+					}},
+				}},
+			}
+			manager := newTestManager(t, v)
+
+			var flagged []string
+			for _, e := range validateVaultData(manager.Get(), manager) {
+				if strings.Contains(e.Message, "possible downgrade") {
+					flagged = append(flagged, e.Path)
+				}
+			}
+
+			if !tt.wantFlagged {
+				if len(flagged) != 0 {
+					t.Errorf("expected no downgrade errors, got %v", flagged)
+				}
+				return
+			}
+			want := []string{"secrets[0] (KEY)", "secrets[0].values[0] (KEY)"}
+			if strings.Join(flagged, ",") != strings.Join(want, ",") {
+				t.Errorf("downgrade errors at %v, want %v", flagged, want)
+			}
+		})
+	}
+}
+
+func TestValidateVaultData_SignedVault(t *testing.T) {
+	alice, err := vaulttest.NewIdentity("Alice", "alice@example.com")
+	if err != nil {
+		t.Fatalf("NewIdentity failed: %v", err)
+	}
+	bob, err := vaulttest.NewIdentity("Bob", "bob@example.com")
+	if err != nil {
+		t.Fatalf("NewIdentity failed: %v", err)
+	}
+	bob.SignedBy = alice.Fingerprint
+
+	v, err := vaulttest.BuildSignedVault([]*vaulttest.Identity{alice, bob}, []vault.Secret{
+		{Key: "DB_PASSWORD", Values: []vault.SecretValue{
+			{AvailableTo: []string{alice.Fingerprint, bob.Fingerprint}, Value: "Y2lwaGVy"},
+		}},
+		{Key: "prod::API_KEY", SignedBy: bob.Fingerprint, Values: []vault.SecretValue{
+			{AvailableTo: []string{bob.Fingerprint}, Value: "Y2lwaGVy"},
+			{Deleted: true},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("BuildSignedVault failed: %v", err)
+	}
+
+	// Round-trip through a vault file so the check covers what is stored.
+	manager := newTestManager(t, v)
+	if errs := validateVaultData(manager.Get(), manager); len(errs) != 0 {
+		t.Errorf("expected a valid vault, got %+v", errs)
+	}
+}
+
+func TestValidateValueTimestamps(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	v := vault.Vault{
+		Secrets: []vault.Secret{{
+			Key: "KEY",
+			Values: []vault.SecretValue{
+				{AddedAt: now.Add(-time.Hour)},
+				{AddedAt: now.Add(time.Minute)},
+				{AddedAt: now.AddDate(1, 0, 0)},
+			},
+		}},
+	}
+
+	warnings := validateValueTimestamps(v, now, 5*time.Minute)
+	if len(warnings) != 1 {
+		t.Fatalf("expected one warning for the far-future value, got %+v", warnings)
+	}
+	if warnings[0].Level != "SECRET" || warnings[0].Path != "secrets[0].values[2] (KEY)" {
+		t.Errorf("unexpected warning: %+v", warnings[0])
+	}
+	if !strings.Contains(warnings[0].Message, "in the future") {
+		t.Errorf("unexpected message: %s", warnings[0].Message)
+	}
+
+	if warnings := validateValueTimestamps(v, now, 0); len(warnings) != 2 {
+		t.Errorf("with zero tolerance, expected two warnings, got %+v", warnings)
+	}
+}
+
+func TestStrictStructure_RejectsTabIndentedVault(t *testing.T) {
+	alice, err := vaulttest.NewIdentity("Alice", "alice@example.com")
+	if err != nil {
+		t.Fatalf("NewIdentity failed: %v", err)
+	}
+	v, err := vaulttest.BuildSignedVault([]*vaulttest.Identity{alice}, nil)
+	if err != nil {
+		t.Fatalf("BuildSignedVault failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "vault")
+	w, err := vault.NewWriter(path)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	if err := w.RewriteFromVault(v); err != nil {
+		t.Fatalf("RewriteFromVault failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Indent the identity line, the fourth, with a tab
+	lines := strings.Split(string(data), "\n")
+	lines[3] = "\t" + lines[3]
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	open := func(cfg config.Config) error {
+		resolver := vault.NewVaultResolver(vault.VaultConfig{
+			Entries:        []vault.VaultEntry{{Path: path}},
+			StructureCheck: structureCheck(cfg),
+		})
+		defer func() { _ = resolver.CloseAll() }()
+		return resolver.OpenVaults(io.Discard)
+	}
+
+	if err := open(config.Config{}); err != nil {
+		t.Fatalf("tab-indented vault should open by default: %v", err)
+	}
+	err = open(config.Config{StrictStructure: true})
+	if err == nil {
+		t.Fatal("expected strict_structure to reject the tab-indented vault")
+	}
+	if !strings.Contains(err.Error(), "tabs instead of spaces at line 4") || !strings.Contains(err.Error(), path) {
+		t.Errorf("expected the error to name the first violation, got: %v", err)
+	}
+}
+
+func TestStrictStructure_AcceptsCanonicalVault(t *testing.T) {
+	for _, name := range []string{"vault", "vault" + vault.CompressedExt} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			w, err := vault.NewWriter(path)
+			if err != nil {
+				t.Fatalf("NewWriter failed: %v", err)
+			}
+			if err := w.RewriteFromVault(vault.NewVault()); err != nil {
+				t.Fatalf("RewriteFromVault failed: %v", err)
+			}
+			if err := checkCanonicalStructure(path); err != nil {
+				t.Errorf("canonical vault rejected: %v", err)
+			}
+			if problems := validateYAMLFieldOrder(path); len(problems) != 0 {
+				t.Errorf("unexpected field order problems: %v", problems)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// ValueEncoding is how 'secret get' encodes decrypted bytes for output.
+type ValueEncoding string
+
+// Value encodings for SetValueEncoding.
+const (
+	ValueEncodingNone   ValueEncoding = ""
+	ValueEncodingBase64 ValueEncoding = "base64"
+	ValueEncodingHex    ValueEncoding = "hex"
+)
+
+// encodeValue renders decrypted bytes for output. Without an encoding the
+// bytes are printed as-is; base64 and hex make binary values safe to print.
+func (c *CLI) encodeValue(plaintext []byte) string {
+	switch c.valueEncoding {
+	case ValueEncodingBase64:
+		return base64.StdEncoding.EncodeToString(plaintext)
+	case ValueEncodingHex:
+		return hex.EncodeToString(plaintext)
+	default:
+		return string(plaintext)
+	}
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// binaryPlaintext contains a NUL, a high byte and a trailing newline, none of
+// which survive printing as text.
+var binaryPlaintext = []byte{0x00, 0xff, 0x10, '\n'}
+
+func TestEncodeValue(t *testing.T) {
+	tests := []struct {
+		enc  ValueEncoding
+		want string
+	}{
+		{ValueEncodingNone, "\x00\xff\x10\n"},
+		{ValueEncodingBase64, "AP8QCg=="},
+		{ValueEncodingHex, "00ff100a"},
+	}
+	for _, tt := range tests {
+		c := &CLI{valueEncoding: tt.enc}
+		if got := c.encodeValue(binaryPlaintext); got != tt.want {
+			t.Errorf("encodeValue with %q = %q, want %q", tt.enc, got, tt.want)
+		}
+	}
+}
+
+func TestSecretGet_ValueEncoding(t *testing.T) {
+	t.Setenv("DOTSECENV_CONFIG", "")
+
+	fp := "ALICEFP"
+	tests := []struct {
+		name       string
+		enc        ValueEncoding
+		jsonOutput bool
+		want       string
+	}{
+		{"hex", ValueEncodingHex, false, "00ff100a\n"},
+		{"base64", ValueEncodingBase64, false, "AP8QCg==\n"},
+		{"hex json", ValueEncodingHex, true, "00ff100a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewMockVaultResolver()
+			r.Secrets[0] = map[string]vault.Secret{
+				"BINARY": {
+					Key:    "BINARY",
+					Values: []vault.SecretValue{{AddedAt: time.Now().UTC(), Value: "Ymlu", AvailableTo: []string{fp}}},
+				},
+			}
+			r.VaultPaths = []string{"/vault.yaml"}
+			r.VaultEntries = []vault.VaultEntry{{Path: "/vault.yaml"}}
+
+			stdout := &bytes.Buffer{}
+			cli := &CLI{
+				config: config.Config{
+					ApprovedAlgorithms: []config.ApprovedAlgorithm{{Algo: "RSA", MinBits: 2048}},
+					Login:              newTestSignedLogin(t, fp),
+				},
+				vaultResolver: r,
+				gpgClient: &MockGPGClientWithDecrypt{
+					MockGPGClient: NewMockGPGClient(),
+					DecryptFunc: func(ciphertext []byte, fingerprint string) ([]byte, error) {
+						return binaryPlaintext, nil
+					},
+				},
+				stdin:  strings.NewReader(""),
+				output: output.NewHandler(stdout, &bytes.Buffer{}),
+				hasTTY: func() bool { return true },
+			}
+			cli.SetValueEncoding(tt.enc)
+
+			if err := cli.SecretGet("BINARY", false, false, tt.jsonOutput, "", 0); err != nil {
+				t.Fatalf("SecretGet failed: %v", err)
+			}
+
+			if !tt.jsonOutput {
+				if stdout.String() != tt.want {
+					t.Errorf("output = %q, want %q", stdout.String(), tt.want)
+				}
+				return
+			}
+			var got SecretValueJSON
+			if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+				t.Fatalf("invalid JSON output: %v", err)
+			}
+			if got.Value != tt.want {
+				t.Errorf("JSON value = %v, want %q", got.Value, tt.want)
+			}
+		})
+	}
+}
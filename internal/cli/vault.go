@@ -1,17 +1,83 @@
 package cli
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
 )
 
+// maxVaultPathSuggestionDistance is the largest edit distance at which a
+// configured vault path is suggested for a -v PATH that did not resolve.
+const maxVaultPathSuggestionDistance = 3
+
+// vaultPathError returns the error for a -v PATH that did not resolve to a
+// loaded vault. The configured and loaded vault paths are listed on stderr,
+// and the one closest to expandedPath, if near enough to be a typo, is
+// suggested in the message.
+func (c *CLI) vaultPathError(expandedPath, message string, exitCode ExitCode) *Error {
+	var candidates []string
+	for _, entry := range c.vaultResolver.GetConfig().Entries {
+		candidates = append(candidates, vault.ExpandPath(entry.Path))
+	}
+	for _, p := range c.vaultResolver.GetVaultPaths() {
+		if expanded := vault.ExpandPath(p); !slices.Contains(candidates, expanded) {
+			candidates = append(candidates, expanded)
+		}
+	}
+	if len(candidates) == 0 {
+		return NewError(message, exitCode)
+	}
+
+	_, _ = fmt.Fprintf(c.output.Stderr(), "Configured vaults:\n")
+	for i, p := range candidates {
+		_, _ = fmt.Fprintf(c.output.Stderr(), "  %d: %s\n", i+1, p)
+	}
+
+	closest, bestDistance := "", maxVaultPathSuggestionDistance+1
+	for _, p := range candidates {
+		if d := levenshtein(expandedPath, p); d < bestDistance {
+			closest, bestDistance = p, d
+		}
+	}
+	if closest != "" {
+		message = fmt.Sprintf("%s (did you mean %s?)", message, closest)
+	}
+	return NewError(message, exitCode)
+}
+
+// levenshtein returns the edit distance between a and b, counted in bytes.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
 // resolveWritableVaultIndex resolves which vault to write to based on vaultPath and fromIndex.
 // If neither is specified, it performs interactive selection from available vaults.
 // The prompt parameter customizes the interactive selection prompt (empty uses default).
@@ -23,7 +89,7 @@ func (c *CLI) resolveWritableVaultIndex(vaultPath string, fromIndex int, prompt
 		// Check if file exists/writable
 		if _, err := os.Stat(expandedPath); err != nil {
 			if os.IsNotExist(err) {
-				return -1, NewError(fmt.Sprintf("vault file does not exist: %s", expandedPath), ExitVaultError)
+				return -1, c.vaultPathError(expandedPath, fmt.Sprintf("vault file does not exist: %s", expandedPath), ExitVaultError)
 			}
 			return -1, NewError(fmt.Sprintf("cannot access vault file: %v", err), ExitVaultError)
 		}
@@ -40,7 +106,7 @@ func (c *CLI) resolveWritableVaultIndex(vaultPath string, fromIndex int, prompt
 				return i, nil
 			}
 		}
-		return -1, NewError(fmt.Sprintf("vault path '%s' is not loaded in current session", expandedPath), ExitGeneralError)
+		return -1, c.vaultPathError(expandedPath, fmt.Sprintf("vault path '%s' is not loaded in current session", expandedPath), ExitGeneralError)
 	}
 
 	if fromIndex != 0 {
@@ -117,10 +183,12 @@ func checkVaultWritable(vaultPath string) *Error {
 // VaultDescribeSecretJSON represents a secret in the vault describe JSON output.
 // AvailableTo reflects the current authorization snapshot (the most-recent value's
 // access list); it is omitted for deleted secrets and secrets without values.
+// Decryptable is set by --decrypt-check for secrets shared with the caller.
 type VaultDescribeSecretJSON struct {
 	Key         string   `json:"key"`
 	Deleted     bool     `json:"deleted,omitempty"`
 	AvailableTo []string `json:"available_to,omitempty"`
+	Decryptable *bool    `json:"decryptable,omitempty"`
 }
 
 // VaultDescribeIdentityJSON represents an identity in the vault describe JSON output
@@ -138,82 +206,237 @@ type VaultDescribeIdentityJSON struct {
 type VaultDescribeJSON struct {
 	Position   int                         `json:"position"`
 	Vault      string                      `json:"vault"`
+	Name       string                      `json:"name,omitempty"`
 	Identities []VaultDescribeIdentityJSON `json:"identities"`
 	Secrets    []VaultDescribeSecretJSON   `json:"secrets"`
+
+	// hideIdentities and hideSecrets drop a section suppressed with
+	// --secrets-only or --identities-only from the JSON entirely, where an
+	// empty section is still encoded.
+	hideIdentities, hideSecrets bool
+}
+
+// MarshalJSON encodes v, leaving out the identities or secrets key when that
+// section is suppressed.
+func (v VaultDescribeJSON) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Position   int                          `json:"position"`
+		Vault      string                       `json:"vault"`
+		Name       string                       `json:"name,omitempty"`
+		Identities *[]VaultDescribeIdentityJSON `json:"identities,omitempty"`
+		Secrets    *[]VaultDescribeSecretJSON   `json:"secrets,omitempty"`
+	}{Position: v.Position, Vault: v.Vault, Name: v.Name}
+	if !v.hideIdentities {
+		out.Identities = &v.Identities
+	}
+	if !v.hideSecrets {
+		out.Secrets = &v.Secrets
+	}
+	return json.Marshal(out)
+}
+
+// describeManager returns the loaded manager for a config entry, or nil when
+// the vault did not load or the manager's path does not match the entry.
+func (c *CLI) describeManager(index int, entry vault.VaultEntry) *vault.Manager {
+	manager := c.vaultResolver.GetVaultManager(index)
+	if manager == nil {
+		return nil
+	}
+	// Ensure paths match (manager path is absolute)
+	entryAbs, _ := filepath.Abs(entry.Path)
+	managerAbs, _ := filepath.Abs(manager.Path())
+	if entryAbs != managerAbs {
+		return nil
+	}
+	return manager
+}
+
+// Orderings for VaultDescribe.
+const (
+	DescribeSortKey         = "key"
+	DescribeSortAdded       = "added"
+	DescribeSortFingerprint = "fingerprint"
+)
+
+// DescribeSortOrders lists the supported describe orderings, for help and errors.
+var DescribeSortOrders = []string{DescribeSortKey, DescribeSortAdded, DescribeSortFingerprint}
+
+// Sections VaultDescribe can be limited to.
+const (
+	DescribeIdentities = "identities"
+	DescribeSecrets    = "secrets"
+)
+
+// sortDescribeIdentities returns a copy of ids in describe order: by UID for
+// "key" (the default), newest added first for "added", and by fingerprint
+// for "fingerprint".
+func sortDescribeIdentities(ids []vault.Identity, sortBy string) []vault.Identity {
+	sorted := make([]vault.Identity, len(ids))
+	copy(sorted, ids)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		switch sortBy {
+		case DescribeSortAdded:
+			if !sorted[i].AddedAt.Equal(sorted[j].AddedAt) {
+				return sorted[i].AddedAt.After(sorted[j].AddedAt)
+			}
+		case DescribeSortFingerprint:
+			return sorted[i].Fingerprint < sorted[j].Fingerprint
+		}
+		return sorted[i].UID < sorted[j].UID
+	})
+	return sorted
 }
 
-// VaultDescribe lists all vaults with their identities and secrets
-func (c *CLI) VaultDescribe(jsonOutput bool) *Error {
+// sortDescribeSecrets returns a copy of secrets in describe order: newest
+// added first for "added", otherwise by key. Secrets carry no fingerprint,
+// so "fingerprint" orders them by key.
+func sortDescribeSecrets(secrets []vault.Secret, sortBy string) []vault.Secret {
+	sorted := make([]vault.Secret, len(secrets))
+	copy(sorted, secrets)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sortBy == DescribeSortAdded && !sorted[i].AddedAt.Equal(sorted[j].AddedAt) {
+			return sorted[i].AddedAt.After(sorted[j].AddedAt)
+		}
+		return sorted[i].Key < sorted[j].Key
+	})
+	return sorted
+}
+
+// checkGlob rejects a malformed glob given to flag.
+func checkGlob(flag, pattern string) *Error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return NewError(fmt.Sprintf("invalid %s pattern %q: %v", flag, pattern, err), ExitValidationError)
+	}
+	return nil
+}
+
+// matchGlob reports whether s matches pattern, which was validated with
+// checkGlob. An empty pattern matches everything.
+func matchGlob(pattern, s string) bool {
+	if pattern == "" {
+		return true
+	}
+	matched, _ := path.Match(pattern, s)
+	return matched
+}
+
+// describeIdentities returns ids in describe order, keeping those whose UID
+// matches the --filter-identity glob and, with --since, that were added
+// within the window.
+func (c *CLI) describeIdentities(ids []vault.Identity, sortBy string) []vault.Identity {
+	return slices.DeleteFunc(sortDescribeIdentities(ids, sortBy), func(id vault.Identity) bool {
+		return !matchGlob(c.uidFilter, id.UID) || !c.changedSince(id.AddedAt)
+	})
+}
+
+// describeSecrets returns secrets in describe order, keeping those whose key
+// matches the --filter glob and, with --since, whose latest value was added
+// within the window.
+func (c *CLI) describeSecrets(secrets []vault.Secret, sortBy string) []vault.Secret {
+	return slices.DeleteFunc(sortDescribeSecrets(secrets, sortBy), func(s vault.Secret) bool {
+		changed := s.AddedAt
+		if len(s.Values) > 0 {
+			changed = s.Values[len(s.Values)-1].AddedAt
+		}
+		return !matchGlob(c.keyFilter, s.Key) || !c.changedSince(changed)
+	})
+}
+
+// changedSince reports whether t falls within the --since window. Without
+// --since every time does.
+func (c *CLI) changedSince(t time.Time) bool {
+	return c.since <= 0 || !t.Before(time.Now().Add(-c.since))
+}
+
+// VaultDescribe lists all vaults with their identities and secrets.
+// sortBy is one of DescribeSortOrders; empty means DescribeSortKey. Secrets
+// and identities are narrowed by the globs given to SetFilter and the window
+// given to SetSince.
+func (c *CLI) VaultDescribe(jsonOutput bool, sortBy string) *Error {
+	if sortBy != "" && !slices.Contains(DescribeSortOrders, sortBy) {
+		return NewError(fmt.Sprintf("unknown sort order %q (one of: %s)", sortBy, strings.Join(DescribeSortOrders, ", ")), ExitValidationError)
+	}
+	if err := checkGlob("--filter", c.keyFilter); err != nil {
+		return err
+	}
+	if err := checkGlob("--filter-identity", c.uidFilter); err != nil {
+		return err
+	}
+
+	var fp string
+	if c.decryptCheck {
+		var fpErr *Error
+		if fp, fpErr = c.checkFingerprintRequired("vault describe --decrypt-check"); fpErr != nil {
+			return fpErr
+		}
+	}
+	undecryptable := 0
+
 	config := c.vaultResolver.GetConfig()
 
 	if jsonOutput {
 		var output []VaultDescribeJSON
 		for i, entry := range config.Entries {
-			manager := c.vaultResolver.GetVaultManager(i)
-
-			// Ensure paths match (manager path is absolute)
-			if manager != nil {
-				entryAbs, _ := filepath.Abs(entry.Path)
-				managerAbs, _ := filepath.Abs(manager.Path())
-				if entryAbs != managerAbs {
-					manager = nil // Mismatch
-				}
-			}
-
+			manager := c.describeManager(i, entry)
 			if manager != nil {
 				vaultData := manager.Get()
 
 				// Build identities list
 				var identities []VaultDescribeIdentityJSON
-				sortedIdentities := make([]vault.Identity, len(vaultData.Identities))
-				copy(sortedIdentities, vaultData.Identities)
-				sort.Slice(sortedIdentities, func(i, j int) bool {
-					return sortedIdentities[i].UID < sortedIdentities[j].UID
-				})
-				for _, id := range sortedIdentities {
-					identities = append(identities, VaultDescribeIdentityJSON{
-						UID:           id.UID,
-						Fingerprint:   id.Fingerprint,
-						Algorithm:     id.Algorithm,
-						AlgorithmBits: id.AlgorithmBits,
-						Curve:         id.Curve,
-						CreatedAt:     id.CreatedAt,
-						ExpiresAt:     id.ExpiresAt,
-					})
+				if c.describeOnly != DescribeSecrets {
+					for _, id := range c.describeIdentities(vaultData.Identities, sortBy) {
+						identities = append(identities, VaultDescribeIdentityJSON{
+							UID:           id.UID,
+							Fingerprint:   id.Fingerprint,
+							Algorithm:     id.Algorithm,
+							AlgorithmBits: id.AlgorithmBits,
+							Curve:         id.Curve,
+							CreatedAt:     id.CreatedAt,
+							ExpiresAt:     id.ExpiresAt,
+						})
+					}
 				}
 
 				// Build secrets list
 				var secrets []VaultDescribeSecretJSON
-				for _, s := range vaultData.Secrets {
-					var availableTo []string
-					if !s.IsDeleted() && len(s.Values) > 0 {
-						availableTo = s.Values[len(s.Values)-1].AvailableTo
+				if c.describeOnly != DescribeIdentities {
+					for _, s := range c.describeSecrets(vaultData.Secrets, sortBy) {
+						var availableTo []string
+						if !s.IsDeleted() && len(s.Values) > 0 {
+							availableTo = s.Values[len(s.Values)-1].AvailableTo
+						}
+						secretJSON := VaultDescribeSecretJSON{
+							Key:         s.Key,
+							Deleted:     s.IsDeleted(),
+							AvailableTo: availableTo,
+						}
+						if checked, decErr := c.describeDecryptCheck(s, fp); checked {
+							decryptable := decErr == nil
+							secretJSON.Decryptable = &decryptable
+							if !decryptable {
+								undecryptable++
+							}
+						}
+						secrets = append(secrets, secretJSON)
 					}
-					secrets = append(secrets, VaultDescribeSecretJSON{
-						Key:         s.Key,
-						Deleted:     s.IsDeleted(),
-						AvailableTo: availableTo,
-					})
 				}
-				sort.Slice(secrets, func(i, j int) bool {
-					return secrets[i].Key < secrets[j].Key
-				})
 
 				output = append(output, VaultDescribeJSON{
-					Position:   i + 1,
-					Vault:      entry.Path,
-					Identities: identities,
-					Secrets:    secrets,
+					Position:       i + 1,
+					Vault:          entry.Path,
+					Name:           manager.Name(),
+					Identities:     identities,
+					Secrets:        secrets,
+					hideIdentities: c.describeOnly == DescribeSecrets,
+					hideSecrets:    c.describeOnly == DescribeIdentities,
 				})
 			}
 		}
 
-		encoder := json.NewEncoder(c.output.Stdout())
-		encoder.SetIndent("", "  ")
-		if err := encoder.Encode(output); err != nil {
+		if err := c.output.EncodeJSON(output); err != nil {
 			return NewError(fmt.Sprintf("failed to encode json: %v", err), ExitGeneralError)
 		}
-		return nil
+		return decryptCheckResult(undecryptable)
 	}
 
 	// Text output
@@ -222,17 +445,7 @@ func (c *CLI) VaultDescribe(jsonOutput bool) *Error {
 			_, _ = fmt.Fprintf(c.output.Stdout(), "\n")
 		}
 		displayPos := i + 1
-		manager := c.vaultResolver.GetVaultManager(i)
-
-		// Verify manager path
-		if manager != nil {
-			entryAbs, _ := filepath.Abs(entry.Path)
-			managerAbs, _ := filepath.Abs(manager.Path())
-			if entryAbs != managerAbs {
-				manager = nil
-			}
-		}
-
+		manager := c.describeManager(i, entry)
 		if manager == nil {
 			loadErr := c.vaultResolver.GetLoadError(i)
 			isNotExist := loadErr != nil && errors.Is(loadErr, os.ErrNotExist)
@@ -246,55 +459,498 @@ func (c *CLI) VaultDescribe(jsonOutput bool) *Error {
 		} else {
 			vaultData := manager.Get()
 			_, _ = fmt.Fprintf(c.output.Stdout(), "Vault %d (%s):\n", displayPos, entry.Path)
+			if name := manager.Name(); name != "" {
+				_, _ = fmt.Fprintf(c.output.Stdout(), "  Name: %s\n", name)
+			}
 
 			// Print identities
-			_, _ = fmt.Fprintf(c.output.Stdout(), "  Identities:\n")
-			if len(vaultData.Identities) == 0 {
-				_, _ = fmt.Fprintf(c.output.Stdout(), "    (none)\n")
-			} else {
-				sortedIdentities := make([]vault.Identity, len(vaultData.Identities))
-				copy(sortedIdentities, vaultData.Identities)
-				sort.Slice(sortedIdentities, func(i, j int) bool {
-					return sortedIdentities[i].UID < sortedIdentities[j].UID
-				})
-				for _, id := range sortedIdentities {
-					_, _ = fmt.Fprintf(c.output.Stdout(), "    - %s (%s)\n", id.UID, id.Fingerprint)
+			if c.describeOnly != DescribeSecrets {
+				_, _ = fmt.Fprintf(c.output.Stdout(), "  Identities:\n")
+				identities := c.describeIdentities(vaultData.Identities, sortBy)
+				if len(identities) == 0 {
+					_, _ = fmt.Fprintf(c.output.Stdout(), "    (none)\n")
+				} else {
+					for _, id := range identities {
+						_, _ = fmt.Fprintf(c.output.Stdout(), "    - %s (%s)\n", id.UID, id.Fingerprint)
+					}
 				}
 			}
 
 			// Print secrets
-			_, _ = fmt.Fprintf(c.output.Stdout(), "  Secrets:\n")
-			if len(vaultData.Secrets) == 0 {
-				_, _ = fmt.Fprintf(c.output.Stdout(), "    (none)\n")
-			} else {
-				type secretInfo struct {
-					key     string
-					deleted bool
+			if c.describeOnly != DescribeIdentities {
+				_, _ = fmt.Fprintf(c.output.Stdout(), "  Secrets:\n")
+				secrets := c.describeSecrets(vaultData.Secrets, sortBy)
+				if len(secrets) == 0 {
+					_, _ = fmt.Fprintf(c.output.Stdout(), "    (none)\n")
+				} else {
+					for _, s := range secrets {
+						checked, decErr := c.describeDecryptCheck(s, fp)
+						switch {
+						case s.IsDeleted():
+							_, _ = fmt.Fprintf(c.output.Stdout(), "    - %s (deleted)\n", s.Key)
+						case checked && decErr != nil:
+							_, _ = fmt.Fprintf(c.output.Stdout(), "    - %s (decrypt: FAILED: %v)\n", s.Key, decErr)
+							undecryptable++
+						case checked:
+							_, _ = fmt.Fprintf(c.output.Stdout(), "    - %s (decrypt: ok)\n", s.Key)
+						default:
+							_, _ = fmt.Fprintf(c.output.Stdout(), "    - %s\n", s.Key)
+						}
+					}
 				}
-				var secrets []secretInfo
-				for _, s := range vaultData.Secrets {
-					secrets = append(secrets, secretInfo{
-						key:     s.Key,
-						deleted: s.IsDeleted(),
-					})
+			}
+		}
+	}
+
+	return decryptCheckResult(undecryptable)
+}
+
+// describeDecryptCheck decrypts the latest value of s for --decrypt-check
+// and discards the plaintext. It reports whether s was checked, which it is
+// only with --decrypt-check when the value is shared with fp, and why
+// decryption failed.
+func (c *CLI) describeDecryptCheck(s vault.Secret, fp string) (bool, error) {
+	if !c.decryptCheck || s.IsDeleted() || len(s.Values) == 0 {
+		return false, nil
+	}
+	latest := s.Values[len(s.Values)-1]
+	if !latest.CanBeReadBy(fp) {
+		return false, nil
+	}
+	if err := latest.DetachedErr(); err != nil {
+		return true, err
+	}
+	encryptedArmored, err := base64.StdEncoding.DecodeString(latest.Value)
+	if err != nil {
+		return true, fmt.Errorf("failed to decode value: %w", err)
+	}
+	payload, err := c.gpgClient.DecryptWithAgent(encryptedArmored, fp)
+	if err != nil {
+		return true, err
+	}
+	if _, inflateErr := c.inflateValue(&latest, payload); inflateErr != nil {
+		return true, errors.New(inflateErr.Message)
+	}
+	return true, nil
+}
+
+// decryptCheckResult fails 'vault describe --decrypt-check' when count
+// secrets shared with the caller could not be decrypted.
+func decryptCheckResult(count int) *Error {
+	if count > 0 {
+		return NewError(fmt.Sprintf("%d secret(s) shared with you could not be decrypted", count), ExitGPGError)
+	}
+	return nil
+}
+
+// VaultAccessJSON lists the secrets a fingerprint can read in one vault.
+type VaultAccessJSON struct {
+	Position int      `json:"position"`
+	Vault    string   `json:"vault"`
+	Secrets  []string `json:"secrets"`
+}
+
+// VaultCheckAccess lists, per vault, every secret whose latest value is
+// readable by fingerprint. Deleted secrets are never listed. This only reads
+// access lists; nothing is decrypted.
+func (c *CLI) VaultCheckAccess(fingerprint string, jsonOutput bool) *Error {
+	fingerprint = identity.NormalizeFingerprint(fingerprint)
+	if fingerprint == "" {
+		return NewError("--check-access requires a fingerprint", ExitValidationError)
+	}
+
+	config := c.vaultResolver.GetConfig()
+	var results []VaultAccessJSON
+	for i, entry := range config.Entries {
+		manager := c.describeManager(i, entry)
+		if manager == nil {
+			continue
+		}
+
+		secrets := []string{}
+		for _, s := range manager.Get().Secrets {
+			if s.IsDeleted() || len(s.Values) == 0 {
+				continue
+			}
+			if s.Values[len(s.Values)-1].CanBeReadBy(fingerprint) {
+				secrets = append(secrets, s.Key)
+			}
+		}
+		sort.Strings(secrets)
+
+		results = append(results, VaultAccessJSON{
+			Position: i + 1,
+			Vault:    entry.Path,
+			Secrets:  secrets,
+		})
+	}
+
+	if jsonOutput {
+		if results == nil {
+			results = []VaultAccessJSON{}
+		}
+		if err := c.output.EncodeJSON(results); err != nil {
+			return NewError(fmt.Sprintf("failed to encode json: %v", err), ExitGeneralError)
+		}
+		return nil
+	}
+
+	if id := c.vaultResolver.GetIdentityByFingerprint(fingerprint); id != nil {
+		_, _ = fmt.Fprintf(c.output.Stdout(), "Secrets readable by %s (%s):\n", id.UID, fingerprint)
+	} else {
+		_, _ = fmt.Fprintf(c.output.Stdout(), "Secrets readable by %s:\n", fingerprint)
+	}
+	for _, r := range results {
+		_, _ = fmt.Fprintf(c.output.Stdout(), "\nVault %d (%s):\n", r.Position, r.Vault)
+		if len(r.Secrets) == 0 {
+			_, _ = fmt.Fprintf(c.output.Stdout(), "  (none)\n")
+			continue
+		}
+		for _, key := range r.Secrets {
+			_, _ = fmt.Fprintf(c.output.Stdout(), "  - %s\n", key)
+		}
+	}
+
+	return nil
+}
+
+// VaultIdentityUsageJSON is one identity in the vault describe --usage
+// JSON output, with the number of secrets it can currently read.
+type VaultIdentityUsageJSON struct {
+	UID         string `json:"uid"`
+	Fingerprint string `json:"fingerprint"`
+	Readable    int    `json:"readable_secrets"`
+}
+
+// VaultUsageJSON lists the identities of one vault with their access.
+type VaultUsageJSON struct {
+	Position   int                      `json:"position"`
+	Vault      string                   `json:"vault"`
+	Identities []VaultIdentityUsageJSON `json:"identities"`
+}
+
+// VaultDescribeUsage lists, per vault, each identity with the number of
+// secrets whose latest value is shared with it. Deleted secrets are not
+// counted, so an identity with zero was added but has nothing to read. The
+// globs given to SetFilter narrow the identities listed and the secrets
+// counted; nothing is decrypted.
+func (c *CLI) VaultDescribeUsage(jsonOutput bool) *Error {
+	if err := checkGlob("--filter", c.keyFilter); err != nil {
+		return err
+	}
+	if err := checkGlob("--filter-identity", c.uidFilter); err != nil {
+		return err
+	}
+
+	config := c.vaultResolver.GetConfig()
+	var results []VaultUsageJSON
+	for i, entry := range config.Entries {
+		manager := c.describeManager(i, entry)
+		if manager == nil {
+			continue
+		}
+		vaultData := manager.Get()
+
+		readable := make(map[string]int)
+		for _, s := range vaultData.Secrets {
+			if s.IsDeleted() || len(s.Values) == 0 || !matchGlob(c.keyFilter, s.Key) {
+				continue
+			}
+			for _, fp := range s.Values[len(s.Values)-1].AvailableTo {
+				readable[fp]++
+			}
+		}
+
+		usage := VaultUsageJSON{Position: i + 1, Vault: entry.Path, Identities: []VaultIdentityUsageJSON{}}
+		for _, id := range sortDescribeIdentities(vaultData.Identities, DescribeSortKey) {
+			if !matchGlob(c.uidFilter, id.UID) {
+				continue
+			}
+			usage.Identities = append(usage.Identities, VaultIdentityUsageJSON{
+				UID:         id.UID,
+				Fingerprint: id.Fingerprint,
+				Readable:    readable[id.Fingerprint],
+			})
+		}
+		results = append(results, usage)
+	}
+
+	if jsonOutput {
+		if results == nil {
+			results = []VaultUsageJSON{}
+		}
+		if err := c.output.EncodeJSON(results); err != nil {
+			return NewError(fmt.Sprintf("failed to encode json: %v", err), ExitGeneralError)
+		}
+		return nil
+	}
+
+	out := c.output.Stdout()
+	for i, r := range results {
+		if i > 0 {
+			_, _ = fmt.Fprintf(out, "\n")
+		}
+		_, _ = fmt.Fprintf(out, "Vault %d (%s):\n", r.Position, r.Vault)
+		if len(r.Identities) == 0 {
+			_, _ = fmt.Fprintf(out, "  (no identities)\n")
+			continue
+		}
+		for _, id := range r.Identities {
+			switch id.Readable {
+			case 0:
+				_, _ = fmt.Fprintf(out, "  %s (%s): no secrets\n", id.UID, id.Fingerprint)
+			case 1:
+				_, _ = fmt.Fprintf(out, "  %s (%s): 1 secret\n", id.UID, id.Fingerprint)
+			default:
+				_, _ = fmt.Fprintf(out, "  %s (%s): %d secrets\n", id.UID, id.Fingerprint, id.Readable)
+			}
+		}
+	}
+	return nil
+}
+
+// VaultIdentityMembershipJSON is one identity in the vault describe
+// --by-identity JSON output. Vaults lists the configured vaults holding it,
+// and Verified reports whether its signature verifies in each of them.
+type VaultIdentityMembershipJSON struct {
+	UID           string   `json:"uid"`
+	Fingerprint   string   `json:"fingerprint"`
+	Algorithm     string   `json:"algorithm"`
+	AlgorithmBits int      `json:"algorithm_bits"`
+	Curve         string   `json:"curve,omitempty"`
+	Verified      bool     `json:"verified"`
+	Vaults        []string `json:"vaults"`
+}
+
+// VaultDescribeByIdentity lists each identity found in the configured vaults
+// once, with the vaults it is a member of, ordered by UID. The identity
+// filter set with SetFilter applies; nothing is decrypted.
+func (c *CLI) VaultDescribeByIdentity(jsonOutput bool) *Error {
+	if err := checkGlob("--filter-identity", c.uidFilter); err != nil {
+		return err
+	}
+
+	config := c.vaultResolver.GetConfig()
+	var results []VaultIdentityMembershipJSON
+	seen := make(map[string]bool)
+	for i, entry := range config.Entries {
+		manager := c.describeManager(i, entry)
+		if manager == nil {
+			continue
+		}
+		for _, id := range c.describeIdentities(manager.Get().Identities, DescribeSortKey) {
+			if seen[id.Fingerprint] {
+				continue
+			}
+			seen[id.Fingerprint] = true
+
+			item := VaultIdentityMembershipJSON{
+				UID:           id.UID,
+				Fingerprint:   id.Fingerprint,
+				Algorithm:     id.Algorithm,
+				AlgorithmBits: id.AlgorithmBits,
+				Curve:         id.Curve,
+				Verified:      true,
+				Vaults:        []string{},
+			}
+			// Later vaults were not visited yet, so membership is checked
+			// across every index.
+			for j, member := range config.Entries {
+				if !c.vaultResolver.IdentityExistsInVault(id.Fingerprint, j) {
+					continue
 				}
-				sort.Slice(secrets, func(i, j int) bool {
-					return secrets[i].key < secrets[j].key
-				})
-				for _, s := range secrets {
-					if s.deleted {
-						_, _ = fmt.Fprintf(c.output.Stdout(), "    - %s (deleted)\n", s.key)
-					} else {
-						_, _ = fmt.Fprintf(c.output.Stdout(), "    - %s\n", s.key)
+				item.Vaults = append(item.Vaults, member.Path)
+				if memberManager := c.describeManager(j, member); memberManager != nil {
+					vaultData := memberManager.Get()
+					stored := vaultData.GetIdentityByFingerprint(id.Fingerprint)
+					if stored == nil {
+						continue
+					}
+					if valid, err := verifyIdentitySignature(stored, &vaultData); err != nil || !valid {
+						item.Verified = false
 					}
 				}
 			}
+			results = append(results, item)
+		}
+	}
+	sort.SliceStable(results, func(a, b int) bool {
+		if results[a].UID != results[b].UID {
+			return results[a].UID < results[b].UID
+		}
+		return results[a].Fingerprint < results[b].Fingerprint
+	})
+
+	if jsonOutput {
+		if results == nil {
+			results = []VaultIdentityMembershipJSON{}
+		}
+		if err := c.output.EncodeJSON(results); err != nil {
+			return NewError(fmt.Sprintf("failed to encode json: %v", err), ExitGeneralError)
+		}
+		return nil
+	}
+
+	if len(results) == 0 {
+		_, _ = fmt.Fprintf(c.output.Stdout(), "(no identities)\n")
+		return nil
+	}
+	for i, r := range results {
+		if i > 0 {
+			_, _ = fmt.Fprintf(c.output.Stdout(), "\n")
+		}
+		status := ""
+		if !r.Verified {
+			status = " (signature INVALID)"
+		}
+		_, _ = fmt.Fprintf(c.output.Stdout(), "%s (%s)%s:\n", r.UID, r.Fingerprint, status)
+		for _, v := range r.Vaults {
+			_, _ = fmt.Fprintf(c.output.Stdout(), "  - %s\n", v)
+		}
+	}
+	return nil
+}
+
+// Vault config status values reported by VaultDiffConfig.
+const (
+	VaultStatusLoaded    = "loaded"
+	VaultStatusMissing   = "missing"
+	VaultStatusError     = "error"
+	VaultStatusNotLoaded = "not_loaded"
+)
+
+// VaultConfigStatusJSON is the load status of one configured vault.
+type VaultConfigStatusJSON struct {
+	Position int    `json:"position"`
+	Vault    string `json:"vault"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+// VaultDiffConfigJSON is the JSON output structure for vault describe --diff-config
+type VaultDiffConfigJSON struct {
+	Configured   []VaultConfigStatusJSON `json:"configured"`
+	Unreferenced []string                `json:"unreferenced"`
+}
+
+// VaultDiffConfig reconciles the configured vaults with the vault files on
+// disk. Each config entry is reported as loaded, missing or failed to load.
+// Vault files that sit next to the config file or next to a configured vault
+// but are not referenced by the config are listed as unreferenced.
+func (c *CLI) VaultDiffConfig(jsonOutput bool) *Error {
+	config := c.vaultResolver.GetConfig()
+
+	result := VaultDiffConfigJSON{
+		Configured:   []VaultConfigStatusJSON{},
+		Unreferenced: []string{},
+	}
+	for i, entry := range config.Entries {
+		status := VaultConfigStatusJSON{Position: i + 1, Vault: entry.Path}
+		loadErr := c.vaultResolver.GetLoadError(i)
+		switch {
+		case c.describeManager(i, entry) != nil:
+			status.Status = VaultStatusLoaded
+		case loadErr != nil && errors.Is(loadErr, os.ErrNotExist):
+			status.Status = VaultStatusMissing
+		case loadErr != nil:
+			status.Status = VaultStatusError
+			status.Error = loadErr.Error()
+		default:
+			status.Status = VaultStatusNotLoaded
+		}
+		result.Configured = append(result.Configured, status)
+	}
+	result.Unreferenced = c.findUnreferencedVaults(config.Entries)
+
+	if jsonOutput {
+		if err := c.output.EncodeJSON(result); err != nil {
+			return NewError(fmt.Sprintf("failed to encode json: %v", err), ExitGeneralError)
+		}
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(c.output.Stdout(), "Configured vaults:\n")
+	if len(result.Configured) == 0 {
+		_, _ = fmt.Fprintf(c.output.Stdout(), "  (none)\n")
+	}
+	for _, s := range result.Configured {
+		switch s.Status {
+		case VaultStatusMissing:
+			_, _ = fmt.Fprintf(c.output.Stdout(), "  %d: %s (missing)\n", s.Position, s.Vault)
+		case VaultStatusError:
+			_, _ = fmt.Fprintf(c.output.Stdout(), "  %d: %s (err: %s)\n", s.Position, s.Vault, s.Error)
+		case VaultStatusNotLoaded:
+			_, _ = fmt.Fprintf(c.output.Stdout(), "  %d: %s (not loaded)\n", s.Position, s.Vault)
+		default:
+			_, _ = fmt.Fprintf(c.output.Stdout(), "  %d: %s (loaded)\n", s.Position, s.Vault)
 		}
 	}
 
+	_, _ = fmt.Fprintf(c.output.Stdout(), "\nUnreferenced vault files:\n")
+	if len(result.Unreferenced) == 0 {
+		_, _ = fmt.Fprintf(c.output.Stdout(), "  (none)\n")
+	}
+	for _, p := range result.Unreferenced {
+		_, _ = fmt.Fprintf(c.output.Stdout(), "  - %s\n", p)
+	}
+
 	return nil
 }
 
+// findUnreferencedVaults returns the vault files in the config file's
+// directory and in the directories of configured vaults that are not
+// themselves configured. Directories are not searched recursively, and a file
+// counts as a vault only if it starts with a vault header.
+func (c *CLI) findUnreferencedVaults(entries []vault.VaultEntry) []string {
+	configured := make(map[string]bool)
+	var dirs []string
+	seenDirs := make(map[string]bool)
+	addDir := func(dir string) {
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		if !seenDirs[dir] {
+			seenDirs[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+
+	if c.configPath != "" {
+		addDir(filepath.Dir(vault.ExpandPath(c.configPath)))
+	}
+	for _, entry := range entries {
+		path := vault.ExpandPath(entry.Path)
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		configured[path] = true
+		addDir(filepath.Dir(path))
+	}
+
+	unreferenced := []string{}
+	for _, dir := range dirs {
+		dirEntries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, de := range dirEntries {
+			if !de.Type().IsRegular() {
+				continue
+			}
+			path := filepath.Join(dir, de.Name())
+			if configured[path] || c.vaultResolver.IsPathInConfig(path) {
+				continue
+			}
+			if version, err := vault.DetectVaultVersion(path); err != nil || version == 0 {
+				continue
+			}
+			unreferenced = append(unreferenced, path)
+		}
+	}
+	sort.Strings(unreferenced)
+	return unreferenced
+}
+
 // isCI returns true if running in a CI environment
 func isCI() bool {
 	// Common CI environment variables
@@ -319,6 +975,11 @@ func isCI() bool {
 	return false
 }
 
+// isGitHubActions returns true if running in a GitHub Actions workflow
+func isGitHubActions() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
 // DoctorCheckJSON represents a single health check in JSON output
 type DoctorCheckJSON struct {
 	Name    string `json:"name"`
@@ -346,6 +1007,7 @@ type upgradeCandidate struct {
 	index          int
 	path           string
 	currentVersion int
+	targetVersion  int
 }
 
 // defragCandidate tracks a vault that needs defragmentation
@@ -383,6 +1045,16 @@ func (c *CLI) VaultDoctor(jsonOutput bool, fix bool, vaultPath string, fromIndex
 		Details: gpgDetails,
 	})
 
+	// Key round trip, when requested with --select
+	if c.doctorKeyCheck {
+		for _, check := range c.doctorKeyChecks() {
+			if check.Status == "error" {
+				overallStatus = "error"
+			}
+			checks = append(checks, check)
+		}
+	}
+
 	// Check 2: Vault format versions
 	for i, entry := range cfg.Entries {
 		manager := c.vaultResolver.GetVaultManager(i)
@@ -402,7 +1074,7 @@ func (c *CLI) VaultDoctor(jsonOutput bool, fix bool, vaultPath string, fromIndex
 			continue
 		}
 
-		currentVersion, err := vault.DetectVaultVersion(entry.Path)
+		currentVersion, targetVersion, needsUpgrade, err := planVaultUpgrade(entry.Path)
 		if err != nil {
 			checks = append(checks, DoctorCheckJSON{
 				Name:    fmt.Sprintf("vault_%d_format", i+1),
@@ -423,11 +1095,11 @@ func (c *CLI) VaultDoctor(jsonOutput bool, fix bool, vaultPath string, fromIndex
 				Status:  "ok",
 				Message: fmt.Sprintf("%s: empty (will use latest format)", entry.Path),
 			})
-		} else if currentVersion < vault.LatestFormatVersion {
+		} else if needsUpgrade {
 			checks = append(checks, DoctorCheckJSON{
 				Name:    fmt.Sprintf("vault_%d_format", i+1),
 				Status:  "warning",
-				Message: fmt.Sprintf("%s: format v%d (latest: v%d)", entry.Path, currentVersion, vault.LatestFormatVersion),
+				Message: fmt.Sprintf("%s: format v%d (latest: v%d)", entry.Path, currentVersion, targetVersion),
 			})
 			if overallStatus == "healthy" {
 				overallStatus = "warning"
@@ -436,6 +1108,7 @@ func (c *CLI) VaultDoctor(jsonOutput bool, fix bool, vaultPath string, fromIndex
 				index:          i,
 				path:           entry.Path,
 				currentVersion: currentVersion,
+				targetVersion:  targetVersion,
 			})
 		} else {
 			checks = append(checks, DoctorCheckJSON{
@@ -446,6 +1119,36 @@ func (c *CLI) VaultDoctor(jsonOutput bool, fix bool, vaultPath string, fromIndex
 		}
 	}
 
+	// File permissions: vaults and the config should be private to the owner
+	for _, check := range c.doctorPermissionChecks() {
+		switch {
+		case check.Status == "error":
+			overallStatus = "error"
+		case check.Status == "warning" && overallStatus == "healthy":
+			overallStatus = "warning"
+		}
+		checks = append(checks, check)
+	}
+
+	// Recipient policy: current values shared with at least min_recipients
+	for _, check := range c.doctorRecipientChecks() {
+		switch {
+		case check.Status == "error":
+			overallStatus = "error"
+		case check.Status == "warning" && overallStatus == "healthy":
+			overallStatus = "warning"
+		}
+		checks = append(checks, check)
+	}
+
+	// Keyserver reachability, when GnuPG is configured with one
+	for _, check := range c.doctorKeyserverChecks() {
+		if check.Status == "warning" && overallStatus == "healthy" {
+			overallStatus = "warning"
+		}
+		checks = append(checks, check)
+	}
+
 	// Check 3: Vault fragmentation
 	// Determine target vault(s) for fragmentation check
 	var targetIndices []int
@@ -523,7 +1226,7 @@ func (c *CLI) VaultDoctor(jsonOutput bool, fix bool, vaultPath string, fromIndex
 				fixes = append(fixes, DoctorFixJSON{
 					Name:    fmt.Sprintf("upgrade_vault_%d", candidate.index+1),
 					Status:  "ok",
-					Message: fmt.Sprintf("upgraded %s from v%d to v%d", expandedPath, candidate.currentVersion, vault.LatestFormatVersion),
+					Message: fmt.Sprintf("upgraded %s from v%d to v%d", expandedPath, candidate.currentVersion, candidate.targetVersion),
 				})
 			}
 		}
@@ -538,6 +1241,12 @@ func (c *CLI) VaultDoctor(jsonOutput bool, fix bool, vaultPath string, fromIndex
 					Status:  "error",
 					Message: fmt.Sprintf("failed to defragment %s: %v", expandedPath, defragErr),
 				})
+			} else if hookErr := c.runPostWriteHook(candidate.index); hookErr != nil {
+				fixes = append(fixes, DoctorFixJSON{
+					Name:    fmt.Sprintf("defrag_vault_%d", candidate.index+1),
+					Status:  "error",
+					Message: hookErr.Message,
+				})
 			} else {
 				fixes = append(fixes, DoctorFixJSON{
 					Name:    fmt.Sprintf("defrag_vault_%d", candidate.index+1),
@@ -555,9 +1264,7 @@ func (c *CLI) VaultDoctor(jsonOutput bool, fix bool, vaultPath string, fromIndex
 			Checks: checks,
 			Fixes:  fixes,
 		}
-		encoder := json.NewEncoder(c.output.Stdout())
-		encoder.SetIndent("", "  ")
-		if err := encoder.Encode(result); err != nil {
+		if err := c.output.EncodeJSON(result); err != nil {
 			return NewError(fmt.Sprintf("failed to encode json: %v", err), ExitGeneralError)
 		}
 		return nil
@@ -569,11 +1276,11 @@ func (c *CLI) VaultDoctor(jsonOutput bool, fix bool, vaultPath string, fromIndex
 		var statusIcon string
 		switch check.Status {
 		case "warning":
-			statusIcon = "!"
+			statusIcon = c.output.Colorize(output.ColorYellow, "!")
 		case "error":
-			statusIcon = "✗"
+			statusIcon = c.output.Colorize(output.ColorRed, "✗")
 		default:
-			statusIcon = "✓"
+			statusIcon = c.output.Colorize(output.ColorGreen, "✓")
 		}
 		_, _ = fmt.Fprintf(c.output.Stdout(), "  [%s] %s\n", statusIcon, check.Message)
 		if check.Details != "" {
@@ -587,9 +1294,9 @@ func (c *CLI) VaultDoctor(jsonOutput bool, fix bool, vaultPath string, fromIndex
 	for _, f := range fixes {
 		var statusIcon string
 		if f.Status == "ok" {
-			statusIcon = "✓"
+			statusIcon = c.output.Colorize(output.ColorGreen, "✓")
 		} else {
-			statusIcon = "✗"
+			statusIcon = c.output.Colorize(output.ColorRed, "✗")
 		}
 		_, _ = fmt.Fprintf(c.output.Stdout(), "  [%s] %s\n", statusIcon, f.Message)
 	}
@@ -603,7 +1310,8 @@ func (c *CLI) VaultDoctor(jsonOutput bool, fix bool, vaultPath string, fromIndex
 	for _, candidate := range upgradeCandidates {
 		expandedPath := vault.ExpandPath(candidate.path)
 		_, _ = fmt.Fprintf(c.output.Stdout(), "\n")
-		confirmed, confirmErr := PromptConfirm(fmt.Sprintf("Upgrade vault %s from v%d to v%d?", expandedPath, candidate.currentVersion, vault.LatestFormatVersion), c.output.Stderr())
+		_, _ = fmt.Fprintf(c.output.Stdout(), "Vault %s uses format v%d; upgrading rewrites it in format v%d.\n", expandedPath, candidate.currentVersion, candidate.targetVersion)
+		confirmed, confirmErr := PromptConfirm(fmt.Sprintf("Upgrade vault %s from v%d to v%d?", expandedPath, candidate.currentVersion, candidate.targetVersion), c.output.Stderr())
 		if confirmErr != nil {
 			return confirmErr
 		}
@@ -635,6 +1343,9 @@ func (c *CLI) VaultDoctor(jsonOutput bool, fix bool, vaultPath string, fromIndex
 		if defragErr != nil {
 			return NewError(fmt.Sprintf("defragmentation failed: %v", defragErr), ExitVaultError)
 		}
+		if hookErr := c.runPostWriteHook(candidate.index); hookErr != nil {
+			return hookErr
+		}
 
 		_, _ = fmt.Fprintf(c.output.Stdout(), "Defragmented %s (%.1f%% -> %.1f%%)\n",
 			expandedPath, candidate.stats.FragmentationRatio*100, newStats.FragmentationRatio*100)
@@ -643,6 +1354,81 @@ func (c *CLI) VaultDoctor(jsonOutput bool, fix bool, vaultPath string, fromIndex
 	return nil
 }
 
+// planVaultUpgrade reads the vault at vaultPath without modifying it and plans
+// an explicitly requested upgrade to the latest format version.
+func planVaultUpgrade(vaultPath string) (from, to int, willUpgrade bool, err error) {
+	w, err := vault.NewWriterReadOnly(vault.ExpandPath(vaultPath))
+	if err != nil {
+		return 0, 0, false, err
+	}
+	return vault.PlanUpgrade(w, false)
+}
+
+// VaultUpgrade upgrades vaults to the latest format version. It targets the
+// vault given by vaultPath or fromIndex, or every configured vault. With
+// dryRun, it only reports what would be upgraded.
+//
+// Vaults are read from their paths rather than through the vault resolver,
+// since opening them there may already upgrade them automatically.
+func (c *CLI) VaultUpgrade(dryRun bool, vaultPath string, fromIndex int) *Error {
+	var targets []string
+	switch {
+	case vaultPath != "":
+		targets = []string{vaultPath}
+	default:
+		vaultCfg, err := vault.ParseNamedVaultConfig(c.config.Vault, c.config.VaultNameList(), c.config.SearchOrder)
+		if err != nil {
+			return NewError(fmt.Sprintf("failed to parse vault config: %v", err), ExitConfigError)
+		}
+		if fromIndex > len(vaultCfg.Entries) {
+			return NewError(fmt.Sprintf("-v index %d exceeds number of configured vaults (%d)", fromIndex, len(vaultCfg.Entries)), ExitGeneralError)
+		}
+		for i, entry := range vaultCfg.Entries {
+			if fromIndex == 0 || fromIndex == i+1 {
+				targets = append(targets, entry.Path)
+			}
+		}
+	}
+	if len(targets) == 0 {
+		return NewError("no vaults configured", ExitVaultError)
+	}
+
+	for i, target := range targets {
+		expandedPath := vault.ExpandPath(target)
+		if _, statErr := os.Stat(expandedPath); errors.Is(statErr, os.ErrNotExist) {
+			// A missing vault is only an error when asked for explicitly
+			if vaultPath != "" || fromIndex != 0 {
+				return NewError(fmt.Sprintf("vault file does not exist: %s", expandedPath), ExitVaultError)
+			}
+			_, _ = fmt.Fprintf(c.output.Stdout(), "%s: skipped (not present)\n", expandedPath)
+			continue
+		}
+
+		from, to, willUpgrade, err := planVaultUpgrade(target)
+		if err != nil {
+			return NewError(fmt.Sprintf("failed to read vault %s: %v", expandedPath, err), ExitVaultError)
+		}
+		if !willUpgrade {
+			if from == 0 {
+				_, _ = fmt.Fprintf(c.output.Stdout(), "%s: empty (will use latest format)\n", expandedPath)
+			} else {
+				_, _ = fmt.Fprintf(c.output.Stdout(), "%s: format v%d (latest)\n", expandedPath, from)
+			}
+			continue
+		}
+
+		if dryRun {
+			_, _ = fmt.Fprintf(c.output.Stdout(), "Would upgrade %s from v%d to v%d\n", expandedPath, from, to)
+			continue
+		}
+		if upgradeErr := c.performVaultUpgrade(i, target, from); upgradeErr != nil {
+			return upgradeErr
+		}
+	}
+
+	return nil
+}
+
 // performVaultUpgrade upgrades a single vault to the latest format version
 func (c *CLI) performVaultUpgrade(index int, vaultPath string, currentVersion int) *Error {
 	expandedPath := vault.ExpandPath(vaultPath)
@@ -672,5 +1458,5 @@ func (c *CLI) performVaultUpgrade(index int, vaultPath string, currentVersion in
 	_, _ = fmt.Fprintf(c.output.Stdout(), "Upgraded %s from v%d to v%d\n",
 		expandedPath, currentVersion, vault.LatestFormatVersion)
 
-	return nil
+	return c.runPostWriteHookForPath(expandedPath)
 }
@@ -0,0 +1,813 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vaulttest"
+)
+
+func TestVaultCheckAccess_MixedAccess(t *testing.T) {
+	const alice = "AAAA1111"
+	const bob = "BBBB2222"
+	now := time.Now().UTC()
+
+	value := func(deleted bool, to ...string) vault.SecretValue {
+		return vault.SecretValue{AddedAt: now, AvailableTo: to, Deleted: deleted, Value: "x"}
+	}
+
+	first := newTestManager(t, vault.Vault{
+		Secrets: []vault.Secret{
+			{Key: "SHARED", AddedAt: now, Values: []vault.SecretValue{value(false, alice, bob)}},
+			{Key: "BOB_ONLY", AddedAt: now, Values: []vault.SecretValue{value(false, bob)}},
+			// Alice lost access in the latest value.
+			{Key: "REVOKED", AddedAt: now, Values: []vault.SecretValue{value(false, alice, bob), value(false, bob)}},
+			// Alice regained access in the latest value.
+			{Key: "GRANTED", AddedAt: now, Values: []vault.SecretValue{value(false, bob), value(false, alice, bob)}},
+			{Key: "DELETED", AddedAt: now, Values: []vault.SecretValue{value(false, alice), value(true, alice)}},
+		},
+	})
+	second := newTestManager(t, vault.Vault{
+		Secrets: []vault.Secret{
+			{Key: "OTHER", AddedAt: now, Values: []vault.SecretValue{value(false, bob)}},
+		},
+	})
+
+	resolver := NewMockVaultResolver()
+	resolver.VaultEntries = []vault.VaultEntry{{Path: first.Path()}, {Path: second.Path()}}
+	resolver.Managers = map[int]*vault.Manager{0: first, 1: second}
+
+	stdout := &bytes.Buffer{}
+	cli := &CLI{
+		vaultResolver: resolver,
+		output:        output.NewHandler(stdout, &bytes.Buffer{}),
+	}
+
+	if err := cli.VaultCheckAccess(strings.ToLower(alice), true); err != nil {
+		t.Fatalf("VaultCheckAccess failed: %v", err)
+	}
+
+	var got []VaultAccessJSON
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, stdout.String())
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 vaults, got %d", len(got))
+	}
+	if want := []string{"GRANTED", "SHARED"}; strings.Join(got[0].Secrets, ",") != strings.Join(want, ",") {
+		t.Errorf("vault 1: expected %v, got %v", want, got[0].Secrets)
+	}
+	if got[1].Position != 2 || len(got[1].Secrets) != 0 {
+		t.Errorf("vault 2: expected no secrets at position 2, got %+v", got[1])
+	}
+
+	stdout.Reset()
+	if err := cli.VaultCheckAccess(bob, false); err != nil {
+		t.Fatalf("VaultCheckAccess failed: %v", err)
+	}
+	text := stdout.String()
+	for _, want := range []string{"  - BOB_ONLY\n", "  - REVOKED\n", "  - OTHER\n"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "DELETED") {
+		t.Errorf("deleted secret should not be listed:\n%s", text)
+	}
+}
+
+func TestVaultCheckAccess_EmptyFingerprint(t *testing.T) {
+	cli := &CLI{
+		vaultResolver: NewMockVaultResolver(),
+		output:        output.NewHandler(&bytes.Buffer{}, &bytes.Buffer{}),
+	}
+	err := cli.VaultCheckAccess("  ", false)
+	if err == nil || err.ExitCode != ExitValidationError {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+func TestVaultDescribeUsage_GhostIdentity(t *testing.T) {
+	const alice = "AAAA1111"
+	const ghost = "CCCC3333"
+	now := time.Now().UTC()
+
+	value := func(deleted bool, to ...string) vault.SecretValue {
+		return vault.SecretValue{AddedAt: now, AvailableTo: to, Deleted: deleted, Value: "x"}
+	}
+	m := newTestManager(t, vault.Vault{
+		Identities: []vault.Identity{
+			{UID: "alice", Fingerprint: alice, AddedAt: now},
+			{UID: "ghost", Fingerprint: ghost, AddedAt: now},
+		},
+		Secrets: []vault.Secret{
+			{Key: "DB_URL", AddedAt: now, Values: []vault.SecretValue{value(false, alice)}},
+			{Key: "API_KEY", AddedAt: now, Values: []vault.SecretValue{value(false, alice)}},
+			// Only the latest value counts.
+			{Key: "REVOKED", AddedAt: now, Values: []vault.SecretValue{value(false, alice, ghost), value(false, alice)}},
+			{Key: "DELETED", AddedAt: now, Values: []vault.SecretValue{value(false, alice, ghost), value(true, alice, ghost)}},
+		},
+	})
+
+	resolver := NewMockVaultResolver()
+	resolver.VaultEntries = []vault.VaultEntry{{Path: m.Path()}}
+	resolver.Managers = map[int]*vault.Manager{0: m}
+	stdout := &bytes.Buffer{}
+	cli := &CLI{
+		vaultResolver: resolver,
+		output:        output.NewHandler(stdout, &bytes.Buffer{}),
+	}
+
+	if err := cli.VaultDescribeUsage(true); err != nil {
+		t.Fatalf("VaultDescribeUsage failed: %v", err)
+	}
+	var got []VaultUsageJSON
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout.String())
+	}
+	want := []VaultIdentityUsageJSON{
+		{UID: "alice", Fingerprint: alice, Readable: 3},
+		{UID: "ghost", Fingerprint: ghost, Readable: 0},
+	}
+	if len(got) != 1 || !reflect.DeepEqual(got[0].Identities, want) {
+		t.Fatalf("got %+v, want identities %+v", got, want)
+	}
+
+	stdout.Reset()
+	cli.SetFilter("DB_*", "")
+	if err := cli.VaultDescribeUsage(false); err != nil {
+		t.Fatalf("VaultDescribeUsage failed: %v", err)
+	}
+	for _, line := range []string{"  alice (AAAA1111): 1 secret\n", "  ghost (CCCC3333): no secrets\n"} {
+		if !strings.Contains(stdout.String(), line) {
+			t.Errorf("missing %q in:\n%s", line, stdout.String())
+		}
+	}
+}
+
+func TestVaultDiffConfig_MissingAndExtraVaults(t *testing.T) {
+	dir := t.TempDir()
+	writeVault := func(name string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		w, err := vault.NewWriter(path)
+		if err != nil {
+			t.Fatalf("failed to create vault writer: %v", err)
+		}
+		if err := w.RewriteFromVault(vault.Vault{}); err != nil {
+			t.Fatalf("failed to write vault: %v", err)
+		}
+		return path
+	}
+
+	loadedPath := writeVault("vault")
+	extraPath := writeVault("old-vault")
+	missingPath := filepath.Join(dir, "missing")
+	brokenPath := filepath.Join(dir, "broken")
+	if err := os.WriteFile(brokenPath, []byte("not a vault\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("vault: []\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := vault.NewManager(loadedPath, false)
+	if err := loaded.OpenAndLock(); err != nil {
+		t.Fatalf("failed to open vault: %v", err)
+	}
+	t.Cleanup(func() { _ = loaded.Unlock() })
+
+	resolver := NewMockVaultResolver()
+	resolver.VaultPaths = []string{loadedPath, missingPath, brokenPath}
+	resolver.VaultEntries = []vault.VaultEntry{{Path: loadedPath}, {Path: missingPath}, {Path: brokenPath}}
+	resolver.Managers = map[int]*vault.Manager{0: loaded}
+	resolver.LoadErrors = map[int]error{
+		1: fmt.Errorf("no such file or directory: %w", os.ErrNotExist),
+		2: errors.New("invalid vault header marker"),
+	}
+
+	stdout := &bytes.Buffer{}
+	cli := &CLI{
+		configPath:    filepath.Join(dir, "config.yaml"),
+		vaultResolver: resolver,
+		output:        output.NewHandler(stdout, &bytes.Buffer{}),
+	}
+
+	if err := cli.VaultDiffConfig(true); err != nil {
+		t.Fatalf("VaultDiffConfig failed: %v", err)
+	}
+	var got VaultDiffConfigJSON
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, stdout.String())
+	}
+
+	wantStatus := []string{VaultStatusLoaded, VaultStatusMissing, VaultStatusError}
+	if len(got.Configured) != len(wantStatus) {
+		t.Fatalf("expected %d configured vaults, got %+v", len(wantStatus), got.Configured)
+	}
+	for i, want := range wantStatus {
+		if got.Configured[i].Status != want || got.Configured[i].Position != i+1 {
+			t.Errorf("vault %d: expected status %q, got %+v", i+1, want, got.Configured[i])
+		}
+	}
+	if got.Configured[2].Error == "" {
+		t.Error("expected load error to be reported for the broken vault")
+	}
+	if len(got.Unreferenced) != 1 || got.Unreferenced[0] != extraPath {
+		t.Errorf("expected unreferenced [%s], got %v", extraPath, got.Unreferenced)
+	}
+
+	stdout.Reset()
+	if err := cli.VaultDiffConfig(false); err != nil {
+		t.Fatalf("VaultDiffConfig failed: %v", err)
+	}
+	text := stdout.String()
+	for _, want := range []string{
+		"1: " + loadedPath + " (loaded)",
+		"2: " + missingPath + " (missing)",
+		"3: " + brokenPath + " (err: invalid vault header marker)",
+		"  - " + extraPath + "\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output:\n%s", want, text)
+		}
+	}
+}
+
+func TestVaultDiffConfig_NoDrift(t *testing.T) {
+	m := newTestManager(t, vault.Vault{})
+
+	resolver := NewMockVaultResolver()
+	resolver.VaultPaths = []string{m.Path()}
+	resolver.VaultEntries = []vault.VaultEntry{{Path: m.Path()}}
+	resolver.Managers = map[int]*vault.Manager{0: m}
+
+	stdout := &bytes.Buffer{}
+	cli := &CLI{
+		vaultResolver: resolver,
+		output:        output.NewHandler(stdout, &bytes.Buffer{}),
+	}
+
+	if err := cli.VaultDiffConfig(false); err != nil {
+		t.Fatalf("VaultDiffConfig failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Unreferenced vault files:\n  (none)\n") {
+		t.Errorf("expected no unreferenced vaults:\n%s", stdout.String())
+	}
+}
+
+func TestVaultDescribe_ShowsName(t *testing.T) {
+	m := newTestManager(t, vault.Vault{})
+	if err := m.SetName("Prod secrets"); err != nil {
+		t.Fatalf("SetName failed: %v", err)
+	}
+
+	resolver := NewMockVaultResolver()
+	resolver.VaultEntries = []vault.VaultEntry{{Path: m.Path()}}
+	resolver.Managers = map[int]*vault.Manager{0: m}
+
+	stdout := &bytes.Buffer{}
+	cli := &CLI{
+		vaultResolver: resolver,
+		output:        output.NewHandler(stdout, &bytes.Buffer{}),
+	}
+
+	if err := cli.VaultDescribe(false, ""); err != nil {
+		t.Fatalf("VaultDescribe failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "  Name: Prod secrets\n") {
+		t.Errorf("expected vault name in output:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := cli.VaultDescribe(true, ""); err != nil {
+		t.Fatalf("VaultDescribe failed: %v", err)
+	}
+	var got []VaultDescribeJSON
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, stdout.String())
+	}
+	if len(got) != 1 || got[0].Name != "Prod secrets" {
+		t.Errorf("expected name in json output, got %+v", got)
+	}
+}
+
+func TestVaultDescribe_SortAdded(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := newTestManager(t, vault.Vault{
+		Identities: []vault.Identity{
+			{UID: "alice", Fingerprint: "FP_A", AddedAt: base},
+			{UID: "carol", Fingerprint: "FP_C", AddedAt: base.Add(2 * time.Hour)},
+			{UID: "bob", Fingerprint: "FP_B", AddedAt: base.Add(time.Hour)},
+		},
+		Secrets: []vault.Secret{
+			{Key: "A_KEY", AddedAt: base.Add(time.Hour)},
+			{Key: "B_KEY", AddedAt: base},
+			{Key: "C_KEY", AddedAt: base.Add(2 * time.Hour)},
+		},
+	})
+
+	resolver := NewMockVaultResolver()
+	resolver.VaultEntries = []vault.VaultEntry{{Path: m.Path()}}
+	resolver.Managers = map[int]*vault.Manager{0: m}
+
+	stdout := &bytes.Buffer{}
+	cli := &CLI{
+		vaultResolver: resolver,
+		output:        output.NewHandler(stdout, &bytes.Buffer{}),
+	}
+
+	if err := cli.VaultDescribe(false, DescribeSortAdded); err != nil {
+		t.Fatalf("VaultDescribe failed: %v", err)
+	}
+	want := "  Identities:\n    - carol (FP_C)\n    - bob (FP_B)\n    - alice (FP_A)\n" +
+		"  Secrets:\n    - C_KEY\n    - A_KEY\n    - B_KEY\n"
+	if !strings.Contains(stdout.String(), want) {
+		t.Errorf("expected newest-added first, got:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := cli.VaultDescribe(true, DescribeSortAdded); err != nil {
+		t.Fatalf("VaultDescribe failed: %v", err)
+	}
+	var got []VaultDescribeJSON
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, stdout.String())
+	}
+	var uids, keys []string
+	for _, id := range got[0].Identities {
+		uids = append(uids, id.UID)
+	}
+	for _, s := range got[0].Secrets {
+		keys = append(keys, s.Key)
+	}
+	if strings.Join(uids, ",") != "carol,bob,alice" || strings.Join(keys, ",") != "C_KEY,A_KEY,B_KEY" {
+		t.Errorf("unexpected json order: identities %v, secrets %v", uids, keys)
+	}
+
+	if err := cli.VaultDescribe(false, "newest"); err == nil || err.ExitCode != ExitValidationError {
+		t.Errorf("expected validation error for unknown sort order, got %v", err)
+	}
+}
+
+// newDescribeOnlyCLI returns a describe CLI for one vault with an identity
+// and a secret.
+func newDescribeOnlyCLI(t *testing.T) (*CLI, *bytes.Buffer) {
+	t.Helper()
+	m := newTestManager(t, vault.Vault{
+		Identities: []vault.Identity{{UID: "alice", Fingerprint: "FP_A"}},
+		Secrets:    []vault.Secret{{Key: "DB_URL"}},
+	})
+	resolver := NewMockVaultResolver()
+	resolver.VaultEntries = []vault.VaultEntry{{Path: m.Path()}}
+	resolver.Managers = map[int]*vault.Manager{0: m}
+
+	stdout := &bytes.Buffer{}
+	return &CLI{
+		vaultResolver: resolver,
+		output:        output.NewHandler(stdout, &bytes.Buffer{}),
+	}, stdout
+}
+
+func TestVaultDescribe_IdentitiesOnly(t *testing.T) {
+	cli, stdout := newDescribeOnlyCLI(t)
+	cli.SetDescribeOnly(DescribeIdentities)
+
+	if err := cli.VaultDescribe(false, ""); err != nil {
+		t.Fatalf("VaultDescribe failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "  Identities:\n    - alice (FP_A)\n") || strings.Contains(stdout.String(), "Secrets:") {
+		t.Errorf("expected identities only, got:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := cli.VaultDescribe(true, ""); err != nil {
+		t.Fatalf("VaultDescribe failed: %v", err)
+	}
+	var raw []map[string]json.RawMessage
+	if err := json.Unmarshal(stdout.Bytes(), &raw); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, stdout.String())
+	}
+	if _, ok := raw[0]["secrets"]; ok {
+		t.Errorf("expected no secrets key, got %s", stdout.String())
+	}
+	var got []VaultDescribeJSON
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got[0].Identities) != 1 || got[0].Identities[0].UID != "alice" {
+		t.Errorf("expected the identity listed, got %+v", got[0].Identities)
+	}
+}
+
+func TestVaultDescribe_SecretsOnly(t *testing.T) {
+	cli, stdout := newDescribeOnlyCLI(t)
+	cli.SetDescribeOnly(DescribeSecrets)
+
+	if err := cli.VaultDescribe(false, ""); err != nil {
+		t.Fatalf("VaultDescribe failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "  Secrets:\n    - DB_URL\n") || strings.Contains(stdout.String(), "Identities:") {
+		t.Errorf("expected secrets only, got:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := cli.VaultDescribe(true, ""); err != nil {
+		t.Fatalf("VaultDescribe failed: %v", err)
+	}
+	var raw []map[string]json.RawMessage
+	if err := json.Unmarshal(stdout.Bytes(), &raw); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, stdout.String())
+	}
+	if _, ok := raw[0]["identities"]; ok {
+		t.Errorf("expected no identities key, got %s", stdout.String())
+	}
+	if !strings.Contains(string(raw[0]["secrets"]), "DB_URL") {
+		t.Errorf("expected the secret listed, got %s", raw[0]["secrets"])
+	}
+
+	// Without a limit both keys are present, even when a section is empty
+	cli.SetDescribeOnly("")
+	cli.SetFilter("NO_MATCH_*", "")
+	stdout.Reset()
+	if err := cli.VaultDescribe(true, ""); err != nil {
+		t.Fatalf("VaultDescribe failed: %v", err)
+	}
+	raw = nil
+	if err := json.Unmarshal(stdout.Bytes(), &raw); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := raw[0]["secrets"]; !ok {
+		t.Errorf("expected an empty secrets key to be kept, got %s", stdout.String())
+	}
+	if _, ok := raw[0]["identities"]; !ok {
+		t.Errorf("expected the identities key, got %s", stdout.String())
+	}
+}
+
+func TestVaultDescribe_Filter(t *testing.T) {
+	m := newTestManager(t, vault.Vault{
+		Identities: []vault.Identity{
+			{UID: "alice <alice@example.com>", Fingerprint: "FP_A"},
+			{UID: "bob <bob@other.org>", Fingerprint: "FP_B"},
+		},
+		Secrets: []vault.Secret{
+			{Key: "DB_URL"},
+			{Key: "DB_PASSWORD_PROD"},
+			{Key: "API_KEY_PROD"},
+			{Key: "API_KEY"},
+		},
+	})
+
+	resolver := NewMockVaultResolver()
+	resolver.VaultEntries = []vault.VaultEntry{{Path: m.Path()}}
+	resolver.Managers = map[int]*vault.Manager{0: m}
+
+	stdout := &bytes.Buffer{}
+	cli := &CLI{
+		vaultResolver: resolver,
+		output:        output.NewHandler(stdout, &bytes.Buffer{}),
+	}
+
+	describeKeys := func(t *testing.T) (keys, uids []string) {
+		t.Helper()
+		stdout.Reset()
+		if err := cli.VaultDescribe(true, ""); err != nil {
+			t.Fatalf("VaultDescribe failed: %v", err)
+		}
+		var got []VaultDescribeJSON
+		if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+			t.Fatalf("invalid json output: %v\n%s", err, stdout.String())
+		}
+		for _, s := range got[0].Secrets {
+			keys = append(keys, s.Key)
+		}
+		for _, id := range got[0].Identities {
+			uids = append(uids, id.UID)
+		}
+		return keys, uids
+	}
+
+	tests := []struct {
+		keyGlob, uidGlob string
+		wantKeys         string
+		wantUIDs         string
+	}{
+		{"DB_*", "", "DB_PASSWORD_PROD,DB_URL", "alice <alice@example.com>,bob <bob@other.org>"},
+		{"*_PROD", "", "API_KEY_PROD,DB_PASSWORD_PROD", "alice <alice@example.com>,bob <bob@other.org>"},
+		{"", "*@example.com>", "API_KEY,API_KEY_PROD,DB_PASSWORD_PROD,DB_URL", "alice <alice@example.com>"},
+		{"NOPE_*", "", "", "alice <alice@example.com>,bob <bob@other.org>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.keyGlob+"|"+tt.uidGlob, func(t *testing.T) {
+			cli.SetFilter(tt.keyGlob, tt.uidGlob)
+			keys, uids := describeKeys(t)
+			if got := strings.Join(keys, ","); got != tt.wantKeys {
+				t.Errorf("secrets = %q, want %q", got, tt.wantKeys)
+			}
+			if got := strings.Join(uids, ","); got != tt.wantUIDs {
+				t.Errorf("identities = %q, want %q", got, tt.wantUIDs)
+			}
+		})
+	}
+
+	cli.SetFilter("DB_*", "")
+	stdout.Reset()
+	if err := cli.VaultDescribe(false, ""); err != nil {
+		t.Fatalf("VaultDescribe failed: %v", err)
+	}
+	if want := "  Secrets:\n    - DB_PASSWORD_PROD\n    - DB_URL\n"; !strings.Contains(stdout.String(), want) {
+		t.Errorf("expected filtered text output, got:\n%s", stdout.String())
+	}
+
+	cli.SetFilter("[DB", "")
+	if err := cli.VaultDescribe(false, ""); err == nil || err.ExitCode != ExitValidationError {
+		t.Errorf("expected validation error for malformed glob, got %v", err)
+	}
+}
+
+func TestVaultDescribe_Since(t *testing.T) {
+	now := time.Now().UTC()
+	old := now.Add(-30 * 24 * time.Hour)
+	recent := now.Add(-2 * time.Hour)
+	m := newTestManager(t, vault.Vault{
+		Identities: []vault.Identity{
+			{UID: "alice", Fingerprint: "FP_A", AddedAt: old},
+			{UID: "bob", Fingerprint: "FP_B", AddedAt: recent},
+		},
+		Secrets: []vault.Secret{
+			{Key: "UNCHANGED", AddedAt: old, Values: []vault.SecretValue{{AddedAt: old}}},
+			// Created long ago, but its latest value is recent
+			{Key: "ROTATED", AddedAt: old, Values: []vault.SecretValue{{AddedAt: old}, {AddedAt: recent}}},
+			{Key: "NEW", AddedAt: recent, Values: []vault.SecretValue{{AddedAt: recent}}},
+		},
+	})
+
+	resolver := NewMockVaultResolver()
+	resolver.VaultEntries = []vault.VaultEntry{{Path: m.Path()}}
+	resolver.Managers = map[int]*vault.Manager{0: m}
+
+	stdout := &bytes.Buffer{}
+	cli := &CLI{
+		vaultResolver: resolver,
+		output:        output.NewHandler(stdout, &bytes.Buffer{}),
+	}
+	cli.SetSince(24 * time.Hour)
+
+	if err := cli.VaultDescribe(false, ""); err != nil {
+		t.Fatalf("VaultDescribe failed: %v", err)
+	}
+	want := "  Identities:\n    - bob (FP_B)\n  Secrets:\n    - NEW\n    - ROTATED\n"
+	if !strings.Contains(stdout.String(), want) {
+		t.Errorf("expected only recent entries, got:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := cli.VaultDescribe(true, ""); err != nil {
+		t.Fatalf("VaultDescribe failed: %v", err)
+	}
+	var got []VaultDescribeJSON
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, stdout.String())
+	}
+	var uids, keys []string
+	for _, id := range got[0].Identities {
+		uids = append(uids, id.UID)
+	}
+	for _, s := range got[0].Secrets {
+		keys = append(keys, s.Key)
+	}
+	if strings.Join(uids, ",") != "bob" || strings.Join(keys, ",") != "NEW,ROTATED" {
+		t.Errorf("unexpected json: identities %v, secrets %v", uids, keys)
+	}
+}
+
+func TestVaultDescribeByIdentity_TwoOfThreeVaults(t *testing.T) {
+	alice, err := vaulttest.NewIdentity("Alice", "alice@example.com")
+	if err != nil {
+		t.Fatalf("NewIdentity failed: %v", err)
+	}
+	bob, err := vaulttest.NewIdentity("Bob", "bob@example.com")
+	if err != nil {
+		t.Fatalf("NewIdentity failed: %v", err)
+	}
+
+	resolver := NewMockVaultResolver()
+	resolver.Managers = map[int]*vault.Manager{}
+	// Alice is in the first and third vaults, Bob only in the second
+	for i, members := range [][]*vaulttest.Identity{{alice}, {bob}, {alice}} {
+		v, buildErr := vaulttest.BuildSignedVault(members, nil)
+		if buildErr != nil {
+			t.Fatalf("BuildSignedVault failed: %v", buildErr)
+		}
+		m := newTestManager(t, v)
+		resolver.VaultEntries = append(resolver.VaultEntries, vault.VaultEntry{Path: m.Path()})
+		resolver.Managers[i] = m
+		resolver.IdentitiesByVault[i] = map[string]vault.Identity{}
+		for _, id := range v.Identities {
+			resolver.IdentitiesByVault[i][id.Fingerprint] = id
+		}
+	}
+
+	stdout := &bytes.Buffer{}
+	cli := &CLI{
+		vaultResolver: resolver,
+		output:        output.NewHandler(stdout, &bytes.Buffer{}),
+	}
+
+	if err := cli.VaultDescribeByIdentity(true); err != nil {
+		t.Fatalf("VaultDescribeByIdentity failed: %v", err)
+	}
+	var got []VaultIdentityMembershipJSON
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, stdout.String())
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected each identity once, got %+v", got)
+	}
+	paths := resolver.VaultEntries
+	if got[0].Fingerprint != alice.Fingerprint || !got[0].Verified ||
+		strings.Join(got[0].Vaults, ",") != paths[0].Path+","+paths[2].Path {
+		t.Errorf("unexpected entry for Alice: %+v", got[0])
+	}
+	if got[1].Fingerprint != bob.Fingerprint || !got[1].Verified ||
+		strings.Join(got[1].Vaults, ",") != paths[1].Path {
+		t.Errorf("unexpected entry for Bob: %+v", got[1])
+	}
+
+	stdout.Reset()
+	cli.SetFilter("", "Alice*")
+	if err := cli.VaultDescribeByIdentity(false); err != nil {
+		t.Fatalf("VaultDescribeByIdentity failed: %v", err)
+	}
+	want := fmt.Sprintf("%s (%s):\n  - %s\n  - %s\n", alice.UID, alice.Fingerprint, paths[0].Path, paths[2].Path)
+	if stdout.String() != want {
+		t.Errorf("unexpected text output:\n%s\nwant:\n%s", stdout.String(), want)
+	}
+}
+
+func TestVaultUpgrade_DryRunThenUpgrade(t *testing.T) {
+	dir := t.TempDir()
+	v1Path := filepath.Join(dir, "v1")
+	v2Path := filepath.Join(dir, "v2")
+	for path, fixture := range map[string]string{v1Path: "vault_v1.jsonl", v2Path: "vault_v2.jsonl"} {
+		data, err := os.ReadFile(filepath.Join("..", "..", "pkg", "dotsecenv", "vault", "testdata", fixture))
+		if err != nil {
+			t.Fatalf("failed to read fixture: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	missingPath := filepath.Join(dir, "missing")
+
+	stdout := &bytes.Buffer{}
+	cli := &CLI{
+		config: config.Config{Vault: []string{v1Path, v2Path, missingPath}},
+		output: output.NewHandler(stdout, &bytes.Buffer{}),
+	}
+
+	if err := cli.VaultUpgrade(true, "", 0); err != nil {
+		t.Fatalf("VaultUpgrade --dry-run failed: %v", err)
+	}
+	text := stdout.String()
+	for _, want := range []string{
+		"Would upgrade " + v1Path + " from v1 to v2\n",
+		v2Path + ": format v2 (latest)\n",
+		missingPath + ": skipped (not present)\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output:\n%s", want, text)
+		}
+	}
+	if version, _ := vault.DetectVaultVersion(v1Path); version != 1 {
+		t.Fatalf("dry run must not upgrade, vault is now v%d", version)
+	}
+
+	stdout.Reset()
+	if err := cli.VaultUpgrade(false, "", 1); err != nil {
+		t.Fatalf("VaultUpgrade failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Upgraded "+v1Path+" from v1 to v2") {
+		t.Errorf("expected upgrade notice, got:\n%s", stdout.String())
+	}
+	if version, _ := vault.DetectVaultVersion(v1Path); version != 2 {
+		t.Errorf("expected vault to be upgraded to v2, got v%d", version)
+	}
+
+	if err := cli.VaultUpgrade(false, "", 3); err == nil {
+		t.Error("expected an error for an explicitly targeted missing vault")
+	}
+}
+
+func TestResolveVaultPath_SuggestsNearMiss(t *testing.T) {
+	dir := t.TempDir()
+	prodPath := filepath.Join(dir, "prod.vault")
+	devPath := filepath.Join(dir, "dev.vault")
+	mock := NewMockVaultResolver()
+	mock.VaultPaths = []string{prodPath, devPath}
+	mock.VaultEntries = []vault.VaultEntry{{Path: prodPath}, {Path: devPath}}
+	stderr := &bytes.Buffer{}
+	cli := &CLI{vaultResolver: mock, output: output.NewHandler(&bytes.Buffer{}, stderr)}
+
+	_, err := cli.resolveReadableVaultIndex(filepath.Join(dir, "prdo.vault"), 0)
+	if err == nil || !strings.Contains(err.Message, "did you mean "+prodPath+"?") {
+		t.Fatalf("expected a suggestion of %s, got %v", prodPath, err)
+	}
+	if !strings.Contains(stderr.String(), "  2: "+devPath) {
+		t.Errorf("expected the configured vaults to be listed:\n%s", stderr.String())
+	}
+
+	// A write to the same typo gets the same suggestion
+	_, err = cli.resolveWritableVaultIndex(filepath.Join(dir, "prdo.vault"), 0)
+	if err == nil || !strings.Contains(err.Message, "did you mean "+prodPath+"?") {
+		t.Errorf("expected a suggestion for a write, got %v", err)
+	}
+
+	// A path nothing like the configured ones gets no suggestion
+	_, err = cli.resolveReadableVaultIndex(filepath.Join(dir, "staging", "secrets"), 0)
+	if err == nil || strings.Contains(err.Message, "did you mean") {
+		t.Errorf("expected no suggestion, got %v", err)
+	}
+}
+
+// failingDecryptGPGClient decrypts every ciphertext except failCiphertext,
+// which fails as if its key were unavailable.
+type failingDecryptGPGClient struct {
+	*MockGPGClient
+	failCiphertext string
+}
+
+func (m *failingDecryptGPGClient) DecryptWithAgent(ciphertext []byte, fingerprint string) ([]byte, error) {
+	if string(ciphertext) == m.failCiphertext {
+		return nil, errors.New("no secret key")
+	}
+	return []byte("plaintext"), nil
+}
+
+func TestVaultDescribe_DecryptCheck(t *testing.T) {
+	const fp = "MYFINGERPRINT"
+	encode := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+	m := newTestManager(t, vault.Vault{Secrets: []vault.Secret{
+		{Key: "API_KEY", Values: []vault.SecretValue{{AvailableTo: []string{fp}, Value: encode("good")}}},
+		{Key: "DB_URL", Values: []vault.SecretValue{{AvailableTo: []string{fp}, Value: encode("lost-key")}}},
+		{Key: "OTHER", Values: []vault.SecretValue{{AvailableTo: []string{"OTHERFINGERPRINT"}, Value: encode("other")}}},
+	}})
+	resolver := NewMockVaultResolver()
+	resolver.VaultEntries = []vault.VaultEntry{{Path: m.Path()}}
+	resolver.Managers = map[int]*vault.Manager{0: m}
+
+	stdout := &bytes.Buffer{}
+	cli := &CLI{
+		config:        config.Config{Login: newTestSignedLogin(t, fp)},
+		vaultResolver: resolver,
+		gpgClient:     &failingDecryptGPGClient{MockGPGClient: NewMockGPGClient(), failCiphertext: "lost-key"},
+		output:        output.NewHandler(stdout, &bytes.Buffer{}),
+	}
+	cli.SetDecryptCheck(true)
+
+	err := cli.VaultDescribe(false, "")
+	if err == nil || err.ExitCode != ExitGPGError {
+		t.Fatalf("expected a GPG error for the undecryptable secret, got %v", err)
+	}
+	for _, want := range []string{"- API_KEY (decrypt: ok)\n", "- DB_URL (decrypt: FAILED: no secret key)\n", "- OTHER\n"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("expected %q in output:\n%s", want, stdout.String())
+		}
+	}
+	if strings.Contains(stdout.String(), "plaintext") {
+		t.Error("decrypted values must not be printed")
+	}
+
+	stdout.Reset()
+	_ = cli.VaultDescribe(true, "")
+	var got []VaultDescribeJSON
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, stdout.String())
+	}
+	decryptable := make(map[string]*bool)
+	for _, s := range got[0].Secrets {
+		decryptable[s.Key] = s.Decryptable
+	}
+	if d := decryptable["API_KEY"]; d == nil || !*d {
+		t.Errorf("expected API_KEY decryptable, got %v", d)
+	}
+	if d := decryptable["DB_URL"]; d == nil || *d {
+		t.Errorf("expected DB_URL not decryptable, got %v", d)
+	}
+	if d := decryptable["OTHER"]; d != nil {
+		t.Errorf("a secret not shared with the caller should not be checked, got %v", *d)
+	}
+}
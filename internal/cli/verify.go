@@ -0,0 +1,388 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/gpg"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// verifyEntry is one signed vault entry checked by 'vault verify'.
+type verifyEntry struct {
+	label     string // e.g. "secret DB_URL value[1]"
+	level     string // ValidationError level of a failure, for --fail-on
+	canonical string // Data the stored hash was computed over
+	computed  string // Hash of canonical, as the validator computes it
+	stored    string
+	signature string
+	signedBy  string
+	identity  string // Fingerprint of the identity checked, for identity entries
+	loadErr   error  // Why a detached value could not be read, failing the entry
+}
+
+// VaultVerify checks the hash and signature of the identities, secrets and
+// secret values in the configured vaults, or in the vault at fromIndex. A
+// non-empty secretKey limits the check to that secret and its values, and a
+// non-empty fingerprint to that identity.
+//
+// With detailed, each entry is followed by the canonical data string that
+// was hashed and signed, and the computed and stored hashes. The canonical
+// data holds encrypted values only, so no plaintext is printed.
+//
+// When SetAgainstKeyring is set, the public key stored for each identity
+// checked, or for each signer of a checked secret, is also compared with the
+// key the local GPG keyring holds for that fingerprint.
+//
+// A vault that fails to load is reported as a failure. Failures are given
+// the ValidationError levels 'validate' uses: GLOBAL for a vault that fails
+// to load, IDENTITY for identities, revocations and keyring mismatches, and
+// SECRET for secrets and their values. When SetValidateFailOn is set, only
+// failures at its levels make the command fail.
+func (c *CLI) VaultVerify(fromIndex int, secretKey, fingerprint string, detailed bool) *Error {
+	entries := c.vaultResolver.GetConfig().Entries
+	if fromIndex > len(entries) {
+		return NewError(fmt.Sprintf("-v index %d exceeds number of configured vaults (%d)", fromIndex, len(entries)), ExitGeneralError)
+	}
+	if fingerprint != "" {
+		fingerprint = identity.NormalizeFingerprint(fingerprint)
+	}
+
+	// Collect every vault's entries first, so progress knows the total.
+	checksByVault := make([][]verifyEntry, len(entries))
+	loadErrs := make([]error, len(entries))
+	total := 0
+	for i := range entries {
+		if fromIndex != 0 && fromIndex != i+1 {
+			continue
+		}
+		loadErrs[i] = c.vaultLoadFailure(i, fromIndex != 0)
+		if manager := c.vaultResolver.GetVaultManager(i); manager != nil {
+			checksByVault[i] = verifyEntries(manager.Get(), secretKey, fingerprint)
+			total += len(checksByVault[i])
+		}
+	}
+	progress := c.newProgress("verified", "entries", total)
+
+	out := c.output.Stdout()
+	var verified, failed, failing int
+	fail := func(level string) {
+		failed++
+		if c.failsOnLevel(level) {
+			failing++
+		}
+	}
+	for i, entry := range entries {
+		if loadErrs[i] != nil {
+			_, _ = fmt.Fprintf(out, "vault %d (%s):\n", i+1, entry.Path)
+			_, _ = fmt.Fprintf(out, "  FAILED: failed to load: %v\n", loadErrs[i])
+			fail("GLOBAL")
+			continue
+		}
+		checks := checksByVault[i]
+		if len(checks) == 0 {
+			continue
+		}
+		vaultData := c.vaultResolver.GetVaultManager(i).Get()
+
+		_, _ = fmt.Fprintf(out, "vault %d (%s):\n", i+1, entry.Path)
+		for _, check := range checks {
+			err := check.loadErr
+			if err == nil {
+				err = verifyEntrySignature(check, vaultData)
+			}
+			if err != nil {
+				_, _ = fmt.Fprintf(out, "  FAILED: %s: %v\n", check.label, err)
+				fail(check.level)
+			} else {
+				_, _ = fmt.Fprintf(out, "  ok: %s\n", check.label)
+				verified++
+			}
+			progress.Increment()
+			if detailed {
+				_, _ = fmt.Fprintf(out, "    canonical: %s\n", check.canonical)
+				_, _ = fmt.Fprintf(out, "    computed:  %s\n", check.computed)
+				_, _ = fmt.Fprintf(out, "    stored:    %s\n", check.stored)
+				_, _ = fmt.Fprintf(out, "    signed by: %s\n", check.signedBy)
+			}
+		}
+
+		if c.againstKeyring {
+			for _, fp := range keyringCheckFingerprints(checks, secretKey) {
+				id := vaultData.GetIdentityByFingerprint(fp)
+				if id == nil {
+					continue
+				}
+				matched, err := c.matchesKeyring(id)
+				switch {
+				case err != nil:
+					c.Warnf("cannot check %s against the keyring: %v", fp, err)
+				case !matched:
+					_, _ = fmt.Fprintf(out, "  FAILED (high severity): keyring key %s: the public key stored in the vault is not the key in your keyring; it may have been replaced\n", fp)
+					fail("IDENTITY")
+				default:
+					_, _ = fmt.Fprintf(out, "  ok: keyring key %s\n", fp)
+					verified++
+				}
+			}
+		}
+	}
+
+	if verified+failed == 0 {
+		switch {
+		case secretKey != "":
+			return NewError(fmt.Sprintf("secret '%s' not found", secretKey), ExitVaultError)
+		case fingerprint != "":
+			return NewError(fmt.Sprintf("identity %s not found", fingerprint), ExitVaultError)
+		default:
+			return NewError("no vault entries to verify", ExitVaultError)
+		}
+	}
+
+	_, _ = fmt.Fprintf(out, "summary: ok=%d failed=%d\n", verified, failed)
+	if failing > 0 {
+		return NewError(fmt.Sprintf("%d vault entry(ies) failed verification", failing), ExitValidationError)
+	}
+	if failed > 0 {
+		_, _ = fmt.Fprintf(out, "no failure is at a --fail-on level (%s)\n", c.failOnString())
+	}
+	return nil
+}
+
+// VaultVerifyStructure cross-checks each vault's header against its data
+// lines, in the configured vaults or the vault at fromIndex, without any
+// hashing or signature checks. It reports every header reference that
+// points at a missing, unparsable or mismatched line and fails when one
+// does. This is the header and file-structure part of 'validate', cheap
+// enough for a pre-commit hook. A vault that fails to load, such as one
+// that can't be parsed, fails the check.
+func (c *CLI) VaultVerifyStructure(fromIndex int) *Error {
+	entries := c.vaultResolver.GetConfig().Entries
+	if fromIndex > len(entries) {
+		return NewError(fmt.Sprintf("-v index %d exceeds number of configured vaults (%d)", fromIndex, len(entries)), ExitGeneralError)
+	}
+
+	out := c.output.Stdout()
+	var checked, failed, failing int
+	for i, entry := range entries {
+		if fromIndex != 0 && fromIndex != i+1 {
+			continue
+		}
+		if loadErr := c.vaultLoadFailure(i, fromIndex != 0); loadErr != nil {
+			checked++
+			failed++
+			if c.failsOnLevel("GLOBAL") {
+				failing++
+			}
+			_, _ = fmt.Fprintf(out, "vault %d (%s):\n", i+1, entry.Path)
+			_, _ = fmt.Fprintf(out, "  FAILED: failed to load: %v\n", loadErr)
+			continue
+		}
+		manager := c.vaultResolver.GetVaultManager(i)
+		if manager == nil {
+			continue
+		}
+		checked++
+
+		header := manager.GetHeader()
+		issues := validateHeaderLineNumbers(header)
+		issues = append(issues, validateVaultFileStructure(header, manager.GetLines())...)
+
+		_, _ = fmt.Fprintf(out, "vault %d (%s):\n", i+1, entry.Path)
+		if len(issues) == 0 {
+			_, _ = fmt.Fprintf(out, "  ok: header matches data\n")
+			continue
+		}
+		for _, issue := range issues {
+			_, _ = fmt.Fprintf(out, "  FAILED: %s at %s\n", issue.Message, issue.Path)
+		}
+		failed++
+		if c.failsOnLevel("STRUCTURE") {
+			failing++
+		}
+	}
+
+	if checked == 0 {
+		return NewError("no vaults to verify", ExitVaultError)
+	}
+
+	_, _ = fmt.Fprintf(out, "summary: ok=%d failed=%d\n", checked-failed, failed)
+	if failing > 0 {
+		return NewError(fmt.Sprintf("%d vault(s) failed the structure check", failing), ExitValidationError)
+	}
+	if failed > 0 {
+		_, _ = fmt.Fprintf(out, "no failure is at a --fail-on level (%s)\n", c.failOnString())
+	}
+	return nil
+}
+
+// vaultLoadFailure returns the error that kept vault i from loading, for
+// 'vault verify' to report. A configured vault that is not present is only
+// a failure when it was selected with -v.
+func (c *CLI) vaultLoadFailure(i int, selected bool) error {
+	if c.vaultResolver.GetVaultManager(i) != nil {
+		return nil
+	}
+	loadErr := c.vaultResolver.GetLoadError(i)
+	switch {
+	case loadErr == nil && selected:
+		return errors.New("vault not loaded")
+	case loadErr == nil, errors.Is(loadErr, os.ErrNotExist) && !selected:
+		return nil
+	}
+	return loadErr
+}
+
+// verifyEntries lists the signed entries of v to check: the identity with
+// fingerprint and its revocations, the secret secretKey and its values, or
+// everything when both are empty.
+func verifyEntries(v vault.Vault, secretKey, fingerprint string) []verifyEntry {
+	var checks []verifyEntry
+
+	if secretKey == "" {
+		for i := range v.Identities {
+			id := &v.Identities[i]
+			if fingerprint != "" && id.Fingerprint != fingerprint {
+				continue
+			}
+			checks = append(checks, verifyEntry{
+				label:     fmt.Sprintf("identity %s (%s)", id.Fingerprint, id.UID),
+				level:     "IDENTITY",
+				canonical: vault.CanonicalIdentityData(id),
+				computed:  identity.ComputeIdentityHash(id),
+				stored:    id.Hash,
+				signature: id.Signature,
+				signedBy:  id.SignedBy,
+				identity:  id.Fingerprint,
+			})
+		}
+	}
+
+	if secretKey == "" {
+		for i := range v.Revocations {
+			r := &v.Revocations[i]
+			if fingerprint != "" && r.Fingerprint != fingerprint {
+				continue
+			}
+			checks = append(checks, verifyEntry{
+				label:     "revocation of identity " + r.Fingerprint,
+				level:     "IDENTITY",
+				canonical: vault.CanonicalRevocationData(r),
+				computed:  vault.ComputeRevocationHash(r, signerBits(v, r.SignedBy)),
+				stored:    r.Hash,
+				signature: r.Signature,
+				signedBy:  r.SignedBy,
+			})
+		}
+	}
+
+	if fingerprint == "" {
+		for i := range v.Secrets {
+			secret := &v.Secrets[i]
+			if secretKey != "" && !vault.CompareSecretKeys(secret.Key, secretKey) {
+				continue
+			}
+			checks = append(checks, verifyEntry{
+				label:     "secret " + secret.Key,
+				level:     "SECRET",
+				canonical: vault.CanonicalSecretData(secret),
+				computed:  vault.ComputeSecretHash(secret, signerBits(v, secret.SignedBy)),
+				stored:    secret.Hash,
+				signature: secret.Signature,
+				signedBy:  secret.SignedBy,
+			})
+			for j := range secret.Values {
+				value := &secret.Values[j]
+				label := fmt.Sprintf("secret %s value[%d]", secret.Key, j)
+				if value.Deleted {
+					label += " (deletion)"
+				}
+				checks = append(checks, verifyEntry{
+					label:     label,
+					level:     "SECRET",
+					canonical: vault.CanonicalValueData(value, secret.Key),
+					computed:  vault.ComputeSecretValueHash(value, secret.Key, signerBits(v, value.SignedBy)),
+					stored:    value.Hash,
+					signature: value.Signature,
+					signedBy:  value.SignedBy,
+					loadErr:   value.DetachedErr(),
+				})
+			}
+		}
+	}
+
+	return checks
+}
+
+// keyringCheckFingerprints lists, in order and without repeats, the
+// identities whose keys --against-keyring compares: the identities checked,
+// or with secretKey the signers of the checked entries.
+func keyringCheckFingerprints(checks []verifyEntry, secretKey string) []string {
+	var fps []string
+	seen := make(map[string]bool)
+	for _, check := range checks {
+		fp := check.identity
+		if secretKey != "" {
+			fp = check.signedBy
+		}
+		if fp == "" || seen[fp] {
+			continue
+		}
+		seen[fp] = true
+		fps = append(fps, fp)
+	}
+	return fps
+}
+
+// matchesKeyring reports whether the public key stored for id in the vault
+// is the key the local keyring holds for id's fingerprint. Keys exported
+// with different packets still match when their fingerprints agree.
+func (c *CLI) matchesKeyring(id *vault.Identity) (bool, error) {
+	info, err := c.gpgClient.GetPublicKeyInfo(id.Fingerprint)
+	if err != nil {
+		return false, fmt.Errorf("key not found in local keyring: %w", err)
+	}
+	if info.PublicKeyBase64 == id.PublicKey {
+		return true, nil
+	}
+	keyringFP, err := gpg.GetKeyFingerprint(info.PublicKeyBase64)
+	if err != nil {
+		return false, fmt.Errorf("failed to read keyring key: %w", err)
+	}
+	storedFP, err := gpg.GetKeyFingerprint(id.PublicKey)
+	if err != nil {
+		return false, nil
+	}
+	return identity.NormalizeFingerprint(storedFP) == identity.NormalizeFingerprint(keyringFP), nil
+}
+
+// signerBits returns the key size of the identity fingerprint in v, which
+// selects the hash algorithm of entries it signs, or 0 if it isn't there.
+func signerBits(v vault.Vault, fingerprint string) int {
+	if signer := v.GetIdentityByFingerprint(fingerprint); signer != nil {
+		return signer.AlgorithmBits
+	}
+	return 0
+}
+
+// verifyEntrySignature checks that check's stored hash matches its canonical
+// data and is signed by its signer's key.
+func verifyEntrySignature(check verifyEntry, v vault.Vault) error {
+	signer := v.GetIdentityByFingerprint(check.signedBy)
+	if signer == nil {
+		return fmt.Errorf("signing identity %s not found", check.signedBy)
+	}
+	if check.computed != check.stored {
+		return fmt.Errorf("hash mismatch: the entry changed after it was signed")
+	}
+	valid, err := verifySignatureWithPublicKey(signer.PublicKey, []byte(check.stored), check.signature)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return fmt.Errorf("signature does not match %s's key", check.signedBy)
+	}
+	return nil
+}
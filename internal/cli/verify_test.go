@@ -0,0 +1,385 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dotsecenv/dotsecenv/internal/progress"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/gpg"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/output"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vaulttest"
+)
+
+// newVerifyCLI returns a CLI over a signed vault holding one identity and
+// a DB_PASSWORD secret. tamper may modify the vault after it is signed.
+func newVerifyCLI(t *testing.T, tamper func(*vault.Vault)) (*CLI, *bytes.Buffer, vault.Vault) {
+	t.Helper()
+	alice, err := vaulttest.NewIdentity("Alice", "alice@example.com")
+	if err != nil {
+		t.Fatalf("NewIdentity failed: %v", err)
+	}
+	v, err := vaulttest.BuildSignedVault([]*vaulttest.Identity{alice}, []vault.Secret{
+		{Key: "DB_PASSWORD", Values: []vault.SecretValue{
+			{AvailableTo: []string{alice.Fingerprint}, Value: "Y2lwaGVy"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("BuildSignedVault failed: %v", err)
+	}
+	if tamper != nil {
+		tamper(&v)
+	}
+	manager := newTestManager(t, v)
+
+	mock := NewMockVaultResolver()
+	mock.VaultEntries = []vault.VaultEntry{{Path: "/vault1"}}
+	mock.Managers = map[int]*vault.Manager{0: manager}
+
+	stdout := &bytes.Buffer{}
+	cli := &CLI{
+		vaultResolver: mock,
+		output:        output.NewHandler(stdout, &bytes.Buffer{}),
+	}
+	return cli, stdout, manager.Get()
+}
+
+func TestVaultVerify_DetailedShowsSignedCanonicalData(t *testing.T) {
+	cli, stdout, v := newVerifyCLI(t, nil)
+
+	if err := cli.VaultVerify(0, "DB_PASSWORD", "", true); err != nil {
+		t.Fatalf("VaultVerify failed: %v\n%s", err, stdout.String())
+	}
+
+	out := stdout.String()
+	secret := &v.Secrets[0]
+	value := &secret.Values[0]
+	bits := v.Identities[0].AlgorithmBits
+
+	// The printed canonical strings are exactly what was hashed and signed.
+	for _, tc := range []struct {
+		canonical string
+		stored    string
+	}{
+		{vault.CanonicalSecretData(secret), secret.Hash},
+		{vault.CanonicalValueData(value, secret.Key), value.Hash},
+	} {
+		if identity.ComputeHash([]byte(tc.canonical), bits) != tc.stored {
+			t.Errorf("canonical data %q does not hash to the signed hash", tc.canonical)
+		}
+		if !strings.Contains(out, "    canonical: "+tc.canonical+"\n") {
+			t.Errorf("missing canonical data %q in:\n%s", tc.canonical, out)
+		}
+		if !strings.Contains(out, "    stored:    "+tc.stored+"\n") {
+			t.Errorf("missing stored hash in:\n%s", out)
+		}
+	}
+	if !strings.Contains(out, "value:") || !strings.Contains(out, ":Y2lwaGVy:false") {
+		t.Errorf("value canonical data should carry the ciphertext, got:\n%s", out)
+	}
+	if strings.Contains(out, "identity "+v.Identities[0].Fingerprint) {
+		t.Errorf("--secret should not verify identities, got:\n%s", out)
+	}
+	if !strings.Contains(out, "summary: ok=2 failed=0") {
+		t.Errorf("expected summary, got:\n%s", out)
+	}
+}
+
+func TestVaultVerify_ReportsTamperedValue(t *testing.T) {
+	cli, stdout, v := newVerifyCLI(t, func(v *vault.Vault) {
+		v.Secrets[0].Values[0].Value = "dGFtcGVyZWQ="
+	})
+
+	err := cli.VaultVerify(0, "", "", true)
+	if err == nil || err.ExitCode != ExitValidationError {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+
+	out := stdout.String()
+	if !strings.Contains(out, "FAILED: secret DB_PASSWORD value[0]: hash mismatch") {
+		t.Errorf("expected hash mismatch for the tampered value, got:\n%s", out)
+	}
+	if !strings.Contains(out, "ok: identity "+v.Identities[0].Fingerprint) {
+		t.Errorf("expected the identity to verify, got:\n%s", out)
+	}
+	computed := vault.ComputeSecretValueHash(&v.Secrets[0].Values[0], "DB_PASSWORD", v.Identities[0].AlgorithmBits)
+	if !strings.Contains(out, "    computed:  "+computed+"\n") {
+		t.Errorf("expected the recomputed hash %s, got:\n%s", computed, out)
+	}
+	if !strings.Contains(out, "summary: ok=2 failed=1") {
+		t.Errorf("expected summary, got:\n%s", out)
+	}
+}
+
+func TestVaultVerify_FailOn(t *testing.T) {
+	cli, stdout, _ := newVerifyCLI(t, func(v *vault.Vault) {
+		v.Secrets[0].Values[0].Value = "dGFtcGVyZWQ="
+	})
+
+	for _, tc := range []struct {
+		failOn []string
+		fails  bool
+	}{
+		{[]string{"any"}, true},
+		{[]string{"SECRET"}, true},
+		{[]string{"IDENTITY", "STRUCTURE"}, false},
+		{[]string{"none"}, false},
+	} {
+		if err := cli.SetValidateFailOn(tc.failOn); err != nil {
+			t.Fatal(err)
+		}
+		stdout.Reset()
+		err := cli.VaultVerify(0, "", "", false)
+		if (err != nil) != tc.fails {
+			t.Errorf("--fail-on %v: expected failure=%v, got %v\n%s", tc.failOn, tc.fails, err, stdout.String())
+		}
+		if !strings.Contains(stdout.String(), "summary: ok=2 failed=1") {
+			t.Errorf("--fail-on %v: expected the failure to be reported, got:\n%s", tc.failOn, stdout.String())
+		}
+	}
+}
+
+func TestVaultVerify_UnknownSecret(t *testing.T) {
+	cli, _, _ := newVerifyCLI(t, nil)
+
+	err := cli.VaultVerify(0, "MISSING", "", false)
+	if err == nil || err.ExitCode != ExitVaultError {
+		t.Fatalf("expected a vault error, got %v", err)
+	}
+}
+
+func TestVaultVerify_AgainstKeyring(t *testing.T) {
+	cli, stdout, v := newVerifyCLI(t, nil)
+	alice := v.Identities[0]
+	keyring := NewMockGPGClient()
+	cli.gpgClient = keyring
+	cli.SetAgainstKeyring(true)
+
+	keyring.PublicKeyInfo[alice.Fingerprint] = gpg.KeyInfo{Fingerprint: alice.Fingerprint, PublicKeyBase64: alice.PublicKey}
+	if err := cli.VaultVerify(0, "", "", false); err != nil {
+		t.Fatalf("VaultVerify failed: %v\n%s", err, stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "ok: keyring key "+alice.Fingerprint) {
+		t.Errorf("expected the stored key to match the keyring, got:\n%s", stdout.String())
+	}
+
+	// The vault's key for alice's fingerprint is not the keyring's key, as
+	// when someone replaced the stored public key with their own.
+	mallory, err := vaulttest.NewIdentity("Mallory", "mallory@example.com")
+	if err != nil {
+		t.Fatalf("NewIdentity failed: %v", err)
+	}
+	keyring.PublicKeyInfo[alice.Fingerprint] = gpg.KeyInfo{Fingerprint: alice.Fingerprint, PublicKeyBase64: mallory.PublicKey}
+	stdout.Reset()
+	verifyErr := cli.VaultVerify(0, "DB_PASSWORD", "", false)
+	if verifyErr == nil || verifyErr.ExitCode != ExitValidationError {
+		t.Fatalf("expected a validation error, got %v", verifyErr)
+	}
+	out := stdout.String()
+	if !strings.Contains(out, "FAILED (high severity): keyring key "+alice.Fingerprint) {
+		t.Errorf("expected a keyring mismatch for the secret's signer, got:\n%s", out)
+	}
+	if !strings.Contains(out, "summary: ok=2 failed=1") {
+		t.Errorf("expected summary, got:\n%s", out)
+	}
+}
+
+func TestVaultVerify_ProgressAlways(t *testing.T) {
+	cli, stdout, _ := newVerifyCLI(t, nil)
+	stderr := &bytes.Buffer{}
+	cli.output = output.NewHandler(stdout, stderr)
+
+	if err := cli.VaultVerify(0, "", "", false); err != nil {
+		t.Fatalf("VaultVerify failed: %v\n%s", err, stdout.String())
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("progress is off by default, got stderr:\n%s", stderr.String())
+	}
+
+	cli.SetProgress(progress.ModeAlways)
+	if err := cli.VaultVerify(0, "", "", false); err != nil {
+		t.Fatalf("VaultVerify failed: %v\n%s", err, stdout.String())
+	}
+	// One identity, one secret and its value; the first and last are
+	// always reported.
+	for _, want := range []string{"verified 1/3 entries\n", "verified 3/3 entries\n"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("missing %q in stderr:\n%s", want, stderr.String())
+		}
+	}
+	if strings.Contains(stdout.String(), "verified ") {
+		t.Errorf("progress must not reach stdout:\n%s", stdout.String())
+	}
+}
+
+// addCorruptVault configures a second vault, at a file that can't be
+// parsed, with the load error the resolver would record for it.
+func addCorruptVault(t *testing.T, cli *CLI) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "corrupt.vault")
+	if err := os.WriteFile(path, []byte(vault.HeaderMarker+"\n{not json\n"+vault.DataMarker+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write vault: %v", err)
+	}
+	loadErr := vault.NewManager(path, true).OpenAndLock()
+	if loadErr == nil {
+		t.Fatal("expected the corrupt vault to fail to load")
+	}
+	mock := cli.vaultResolver.(*MockVaultResolver)
+	mock.VaultEntries = append(mock.VaultEntries, vault.VaultEntry{Path: path})
+	mock.LoadErrors = map[int]error{1: loadErr}
+	return path
+}
+
+func TestVaultVerify_ReportsLoadError(t *testing.T) {
+	for _, fromIndex := range []int{0, 2} {
+		cli, stdout, _ := newVerifyCLI(t, nil)
+		path := addCorruptVault(t, cli)
+
+		verifyErr := cli.VaultVerify(fromIndex, "", "", false)
+		if verifyErr == nil || verifyErr.ExitCode != ExitValidationError {
+			t.Fatalf("-v %d: expected a validation error for an unloadable vault, got %v\n%s", fromIndex, verifyErr, stdout.String())
+		}
+		out := stdout.String()
+		if !strings.Contains(out, "vault 2 ("+path+"):\n  FAILED: failed to load: ") {
+			t.Errorf("-v %d: expected the load error to be reported, got:\n%s", fromIndex, out)
+		}
+		if !strings.Contains(out, "failed=1") {
+			t.Errorf("-v %d: expected one failure in the summary, got:\n%s", fromIndex, out)
+		}
+	}
+}
+
+func TestVaultVerifyStructure_DetectsHeaderDrift(t *testing.T) {
+	cli, stdout, _ := newVerifyCLI(t, func(v *vault.Vault) {
+		v.Secrets = append(v.Secrets, vault.Secret{Key: "API_KEY", Values: []vault.SecretValue{{Value: "b3RoZXI="}}})
+	})
+	if err := cli.VaultVerifyStructure(0); err != nil {
+		t.Fatalf("VaultVerifyStructure failed on an intact vault: %v\n%s", err, stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "ok: header matches data") {
+		t.Errorf("expected an ok line, got:\n%s", stdout.String())
+	}
+
+	// Point each secret's header entry at the other secret's value line.
+	manager := cli.vaultResolver.GetVaultManager(0)
+	path := manager.Path()
+	_ = manager.Unlock()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read vault: %v", err)
+	}
+	lines := strings.Split(string(data), "\n")
+	var header vault.Header
+	if err := json.Unmarshal([]byte(lines[1]), &header); err != nil {
+		t.Fatalf("failed to parse header: %v", err)
+	}
+	db, api := header.Secrets["DB_PASSWORD"], header.Secrets["API_KEY"]
+	db.Values, api.Values = api.Values, db.Values
+	header.Secrets["DB_PASSWORD"], header.Secrets["API_KEY"] = db, api
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		t.Fatalf("failed to encode header: %v", err)
+	}
+	lines[1] = string(headerJSON)
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0600); err != nil {
+		t.Fatalf("failed to write vault: %v", err)
+	}
+
+	drifted := vault.NewManager(path, false)
+	if err := drifted.OpenAndLock(); err != nil {
+		t.Fatalf("failed to reopen vault: %v", err)
+	}
+	t.Cleanup(func() { _ = drifted.Unlock() })
+	cli.vaultResolver.(*MockVaultResolver).Managers[0] = drifted
+	stdout.Reset()
+
+	verifyErr := cli.VaultVerifyStructure(0)
+	if verifyErr == nil || verifyErr.ExitCode != ExitValidationError {
+		t.Fatalf("expected a validation error for a drifted header, got %v\n%s", verifyErr, stdout.String())
+	}
+	out := stdout.String()
+	for _, want := range []string{
+		`has secret="API_KEY" but header maps it to secret="DB_PASSWORD"`,
+		`has secret="DB_PASSWORD" but header maps it to secret="API_KEY"`,
+		"summary: ok=0 failed=1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	// Structural drift alone passes when only tampering fails
+	if err := cli.SetValidateFailOn([]string{"SECRET", "IDENTITY"}); err != nil {
+		t.Fatal(err)
+	}
+	stdout.Reset()
+	if err := cli.VaultVerifyStructure(0); err != nil {
+		t.Errorf("expected --fail-on SECRET,IDENTITY to tolerate header drift, got %v\n%s", err, stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "FAILED: ") {
+		t.Errorf("expected the drift to still be reported, got:\n%s", stdout.String())
+	}
+}
+
+func TestVaultVerifyStructure_ReportsLoadError(t *testing.T) {
+	for _, fromIndex := range []int{0, 2} {
+		cli, stdout, _ := newVerifyCLI(t, nil)
+		path := addCorruptVault(t, cli)
+
+		verifyErr := cli.VaultVerifyStructure(fromIndex)
+		if verifyErr == nil || verifyErr.ExitCode != ExitValidationError {
+			t.Fatalf("-v %d: expected a validation error for an unloadable vault, got %v\n%s", fromIndex, verifyErr, stdout.String())
+		}
+		out := stdout.String()
+		if !strings.Contains(out, "vault 2 ("+path+"):\n  FAILED: failed to load: ") {
+			t.Errorf("-v %d: expected the load error to be reported, got:\n%s", fromIndex, out)
+		}
+		if !strings.Contains(out, "failed=1") {
+			t.Errorf("-v %d: expected one failure in the summary, got:\n%s", fromIndex, out)
+		}
+	}
+}
+
+func TestTamperedDetachedValue_Reported(t *testing.T) {
+	cli, stdout, v := newVerifyCLI(t, func(v *vault.Vault) {
+		v.Secrets[0].Values[0].Detach()
+	})
+	ref := v.Secrets[0].Values[0].ValueRef
+
+	manager := cli.vaultResolver.GetVaultManager(0)
+	path := manager.Path()
+	_ = manager.Unlock()
+	sidecar := filepath.Join(vault.DetachedValuesDir(path), ref)
+	if err := os.WriteFile(sidecar, []byte("dGFtcGVyZWQ="), 0600); err != nil {
+		t.Fatalf("failed to tamper with the value file: %v", err)
+	}
+	reopened := vault.NewManager(path, false)
+	if err := reopened.OpenAndLock(); err != nil {
+		t.Fatalf("expected the vault to load despite the tampered value file: %v", err)
+	}
+	t.Cleanup(func() { _ = reopened.Unlock() })
+	mock := cli.vaultResolver.(*MockVaultResolver)
+	mock.Managers[0] = reopened
+
+	verifyErr := cli.VaultVerify(0, "", "", false)
+	if verifyErr == nil || verifyErr.ExitCode != ExitValidationError {
+		t.Fatalf("expected vault verify to fail, got %v\n%s", verifyErr, stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "FAILED: secret DB_PASSWORD value[0]: detached value "+ref+" does not match its reference (file modified)") {
+		t.Errorf("expected the integrity error for the value, got:\n%s", stdout.String())
+	}
+
+	fp := v.Identities[0].Fingerprint
+	cli.config.Login = newTestSignedLogin(t, fp)
+	cli.gpgClient = NewMockGPGClient()
+	mock.Secrets[0] = map[string]vault.Secret{"DB_PASSWORD": *reopened.Get().GetSecretByKey("DB_PASSWORD")}
+	getErr := cli.SecretGet("DB_PASSWORD", false, false, false, "", 0)
+	if getErr == nil || getErr.ExitCode != ExitValidationError || !strings.Contains(getErr.Message, "does not match its reference (file modified)") {
+		t.Errorf("expected secret get to report the integrity error, got %v", getErr)
+	}
+}
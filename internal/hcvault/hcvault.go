@@ -0,0 +1,141 @@
+// Package hcvault reads secrets from a HashiCorp Vault KV version 2 secrets
+// engine over its HTTP API. It only supports what 'dotsecenv import
+// hashicorp' needs: reading the latest version of one secret as a flat map
+// of key to value, authenticated with a token.
+package hcvault
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TokenEnv is the environment variable holding the Vault token, as used by
+// the vault CLI.
+const TokenEnv = "VAULT_TOKEN"
+
+// AddrEnv is the environment variable holding the Vault address, as used by
+// the vault CLI.
+const AddrEnv = "VAULT_ADDR"
+
+// defaultTimeout bounds every request made by a Client.
+const defaultTimeout = 30 * time.Second
+
+// maxResponseSize caps how much of a response body is read.
+const maxResponseSize = 10 << 20
+
+// Client reads KV v2 secrets from a Vault server.
+type Client struct {
+	// Addr is the server address, e.g. https://vault.example.com:8200.
+	Addr string
+	// Token is sent as X-Vault-Token.
+	Token string
+	// HTTPClient is used for requests; nil means a client with a 30s timeout.
+	HTTPClient *http.Client
+}
+
+// kvReadResponse is the body of a KV v2 read.
+type kvReadResponse struct {
+	Data struct {
+		Data map[string]interface{} `json:"data"`
+	} `json:"data"`
+}
+
+// errorResponse is the body of a failed Vault request.
+type errorResponse struct {
+	Errors []string `json:"errors"`
+}
+
+// ReadKV2 reads the latest version of the secret at path, given as
+// MOUNT/SECRET_PATH (e.g. "secret/myapp", as with 'vault kv get'). String
+// values are returned as-is; other JSON values are returned JSON-encoded.
+func (c *Client) ReadKV2(path string) (map[string]string, error) {
+	if c.Addr == "" {
+		return nil, fmt.Errorf("vault address is required (--addr or %s)", AddrEnv)
+	}
+	if c.Token == "" {
+		return nil, fmt.Errorf("vault token is required (%s)", TokenEnv)
+	}
+	mount, secretPath, ok := strings.Cut(strings.Trim(path, "/"), "/")
+	if !ok || mount == "" || secretPath == "" {
+		return nil, fmt.Errorf("invalid path %q: expected MOUNT/SECRET_PATH (e.g. secret/myapp)", path)
+	}
+
+	base, err := url.Parse(strings.TrimSuffix(c.Addr, "/"))
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("invalid vault address %q", c.Addr)
+	}
+	endpoint := base.JoinPath("v1", mount, "data", secretPath)
+
+	req, err := http.NewRequest(http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", c.Token)
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach vault at %s: %w", c.Addr, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp.StatusCode, path, body)
+	}
+
+	var parsed kvReadResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("unexpected vault response for %s: %w", path, err)
+	}
+	if parsed.Data.Data == nil {
+		return nil, fmt.Errorf("no secret data at %s (is %s a KV version 2 mount?)", path, mount)
+	}
+
+	values := make(map[string]string, len(parsed.Data.Data))
+	for k, v := range parsed.Data.Data {
+		if s, ok := v.(string); ok {
+			values[k] = s
+			continue
+		}
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode value of %s: %w", k, err)
+		}
+		values[k] = string(encoded)
+	}
+	return values, nil
+}
+
+// statusError describes a non-200 response, including the server's error
+// messages when it sent any.
+func statusError(status int, path string, body []byte) error {
+	var msg string
+	switch status {
+	case http.StatusForbidden:
+		msg = fmt.Sprintf("permission denied reading %s (check %s and its policies)", path, TokenEnv)
+	case http.StatusNotFound:
+		msg = fmt.Sprintf("no secret found at %s", path)
+	default:
+		msg = fmt.Sprintf("vault returned HTTP %d for %s", status, path)
+	}
+
+	var parsed errorResponse
+	if json.Unmarshal(body, &parsed) == nil && len(parsed.Errors) > 0 {
+		msg += ": " + strings.Join(parsed.Errors, "; ")
+	}
+	return errors.New(msg)
+}
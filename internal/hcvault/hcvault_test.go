@@ -0,0 +1,81 @@
+package hcvault
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newKVServer serves a KV v2 mount at /v1/secret/ holding one secret at
+// secret/myapp, readable with token "good-token".
+func newKVServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "good-token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/myapp":
+			_, _ = w.Write([]byte(`{"data":{"data":{"DB_URL":"postgres://db","PORT":5432,"FLAGS":{"a":true}},"metadata":{"version":3}}}`))
+		case "/v1/kv1/data/legacy":
+			_, _ = w.Write([]byte(`{"data":{"DB_URL":"postgres://db"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestReadKV2(t *testing.T) {
+	srv := newKVServer(t)
+	c := &Client{Addr: srv.URL + "/", Token: "good-token"}
+
+	got, err := c.ReadKV2("secret/myapp")
+	if err != nil {
+		t.Fatalf("ReadKV2 failed: %v", err)
+	}
+	want := map[string]string{"DB_URL": "postgres://db", "PORT": "5432", "FLAGS": `{"a":true}`}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestReadKV2_Errors(t *testing.T) {
+	srv := newKVServer(t)
+
+	tests := []struct {
+		name    string
+		client  *Client
+		path    string
+		wantErr string
+	}{
+		{"missing token", &Client{Addr: srv.URL}, "secret/myapp", "VAULT_TOKEN"},
+		{"missing address", &Client{Token: "good-token"}, "secret/myapp", "VAULT_ADDR"},
+		{"invalid path", &Client{Addr: srv.URL, Token: "good-token"}, "myapp", "MOUNT/SECRET_PATH"},
+		{"bad token", &Client{Addr: srv.URL, Token: "bad-token"}, "secret/myapp", "permission denied reading secret/myapp (check VAULT_TOKEN and its policies): permission denied"},
+		{"not found", &Client{Addr: srv.URL, Token: "good-token"}, "secret/other", "no secret found at secret/other"},
+		{"kv v1 mount", &Client{Addr: srv.URL, Token: "good-token"}, "kv1/legacy", "KV version 2"},
+		{"unreachable", &Client{Addr: "http://127.0.0.1:1", Token: "good-token"}, "secret/myapp", "failed to reach vault"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.client.ReadKV2(tt.path)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %q does not contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// Package progress reports how far a long-running command has got, as lines
+// such as "verified 340/1000 values" on stderr. Reports are rate limited so
+// that large vaults do not flood the terminal.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Mode selects when progress is reported.
+type Mode string
+
+const (
+	// ModeNever disables progress reports. It is the default.
+	ModeNever Mode = "never"
+	// ModeAuto reports progress only when the output is a terminal.
+	ModeAuto Mode = "auto"
+	// ModeAlways reports progress even when the output is redirected.
+	ModeAlways Mode = "always"
+)
+
+// Modes lists the accepted --progress values.
+var Modes = []string{string(ModeNever), string(ModeAuto), string(ModeAlways)}
+
+// DefaultInterval is the minimum time between two progress lines.
+const DefaultInterval = 500 * time.Millisecond
+
+// ParseMode parses a --progress value. The empty string is ModeNever.
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case "", ModeNever:
+		return ModeNever, nil
+	case ModeAuto, ModeAlways:
+		return Mode(s), nil
+	}
+	return "", fmt.Errorf("invalid --progress value %q (one of: never, auto, always)", s)
+}
+
+// Reporter counts the items a command has processed and prints the count to
+// its writer at most once per interval. The last item is always reported. A
+// nil *Reporter reports nothing, so callers need not check whether progress
+// is enabled.
+type Reporter struct {
+	w        io.Writer
+	verb     string // e.g. "verified"
+	unit     string // e.g. "values"
+	total    int
+	done     int
+	printed  int // done at the last printed line
+	last     time.Time
+	interval time.Duration
+	now      func() time.Time
+}
+
+// New returns a Reporter for total items, printing "<verb> N/total <unit>"
+// lines to w, or nil when mode disables progress or total is zero. In
+// ModeAuto progress is only reported when w is a terminal.
+func New(w io.Writer, mode Mode, verb, unit string, total int) *Reporter {
+	if total <= 0 || !enabled(w, mode) {
+		return nil
+	}
+	return &Reporter{w: w, verb: verb, unit: unit, total: total, interval: DefaultInterval, now: time.Now}
+}
+
+// enabled reports whether mode reports progress to w.
+func enabled(w io.Writer, mode Mode) bool {
+	switch mode {
+	case ModeAlways:
+		return true
+	case ModeAuto:
+		f, ok := w.(*os.File)
+		return ok && term.IsTerminal(int(f.Fd()))
+	}
+	return false
+}
+
+// Increment records one more processed item.
+func (r *Reporter) Increment() {
+	r.Add(1)
+}
+
+// Add records n more processed items and prints the count when the interval
+// has passed since the last line, or when all items are done.
+func (r *Reporter) Add(n int) {
+	if r == nil {
+		return
+	}
+	r.done += n
+	now := r.now()
+	if r.done < r.total && now.Sub(r.last) < r.interval {
+		return
+	}
+	r.print(now)
+}
+
+// Finish prints the final count if it has not been printed yet. Call it
+// when a command stops early, so the last line shows how far it got.
+func (r *Reporter) Finish() {
+	if r == nil || r.done == r.printed {
+		return
+	}
+	r.print(r.now())
+}
+
+func (r *Reporter) print(now time.Time) {
+	r.last = now
+	r.printed = r.done
+	_, _ = fmt.Fprintf(r.w, "%s %d/%d %s\n", r.verb, r.done, r.total, r.unit)
+}
@@ -0,0 +1,77 @@
+package progress
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestParseMode(t *testing.T) {
+	for in, want := range map[string]Mode{"": ModeNever, "never": ModeNever, "auto": ModeAuto, "always": ModeAlways} {
+		got, err := ParseMode(in)
+		if err != nil || got != want {
+			t.Errorf("ParseMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseMode("sometimes"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
+
+func TestNew_DisabledReturnsNil(t *testing.T) {
+	var buf bytes.Buffer
+	// A buffer is not a terminal, so auto stays quiet.
+	for _, mode := range []Mode{ModeNever, ModeAuto, ""} {
+		if r := New(&buf, mode, "verified", "values", 10); r != nil {
+			t.Errorf("mode %q: expected no reporter", mode)
+		}
+	}
+	if r := New(&buf, ModeAlways, "verified", "values", 0); r != nil {
+		t.Error("expected no reporter for zero items")
+	}
+
+	var r *Reporter
+	r.Increment()
+	r.Finish()
+	if buf.Len() != 0 {
+		t.Errorf("expected no output, got %q", buf.String())
+	}
+}
+
+func TestReporter_RateLimited(t *testing.T) {
+	var buf bytes.Buffer
+	r := New(&buf, ModeAlways, "verified", "values", 1000)
+	clock := time.Unix(0, 0)
+	r.now = func() time.Time { return clock }
+	r.interval = 100 * time.Millisecond
+
+	// One item per millisecond: the first prints, then one per interval.
+	for range 340 {
+		r.Increment()
+		clock = clock.Add(time.Millisecond)
+	}
+	r.Finish()
+	r.Finish()
+
+	want := "verified 1/1000 values\n" +
+		"verified 101/1000 values\n" +
+		"verified 201/1000 values\n" +
+		"verified 301/1000 values\n" +
+		"verified 340/1000 values\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%swant:\n%s", buf.String(), want)
+	}
+}
+
+func TestReporter_LastItemAlwaysPrinted(t *testing.T) {
+	var buf bytes.Buffer
+	r := New(&buf, ModeAlways, "imported", "secrets", 3)
+	r.Increment()
+	r.Increment()
+	r.Increment()
+	r.Finish()
+
+	if want := "imported 1/3 secrets\nimported 3/3 secrets\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
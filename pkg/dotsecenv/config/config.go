@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
@@ -21,6 +22,11 @@ type ApprovedAlgorithm struct {
 // GPGConfig holds GPG-related configuration
 type GPGConfig struct {
 	Program string `yaml:"program,omitempty"` // Path to GPG executable: "PATH" or absolute path
+
+	// PassphraseCommand is a shell command whose output is used as the secret
+	// key passphrase when decrypting, similar to git's core.askpass. The
+	// passphrase is given to gpg via loopback pinentry and is never logged.
+	PassphraseCommand string `yaml:"passphrase_command,omitempty"`
 }
 
 // BehaviorConfig holds granular behavior settings.
@@ -34,6 +40,17 @@ type BehaviorConfig struct {
 	RestrictToConfiguredVaults *bool `yaml:"restrict_to_configured_vaults,omitempty"`
 }
 
+// HooksConfig holds commands run on vault events.
+type HooksConfig struct {
+	// PostWrite is a shell command run after each vault write, with the
+	// vault path in DOTSECENV_VAULT. It is never given secret values.
+	PostWrite string `yaml:"post_write,omitempty"`
+
+	// FailOnError makes a failed hook fail the command. The vault write
+	// already happened and is kept either way.
+	FailOnError bool `yaml:"fail_on_error,omitempty"`
+}
+
 // Login represents authenticated login state with cryptographic proof.
 // The signature proves the user controls the secret key at login time.
 type Login struct {
@@ -50,14 +67,82 @@ type Config struct {
 	Vault              []string            `yaml:"vault"`              // List of vault paths
 	Behavior           BehaviorConfig      `yaml:"behavior,omitempty"` // Granular behavior settings
 	GPG                GPGConfig           `yaml:"gpg,omitempty"`      // GPG configuration
+	Hooks              HooksConfig         `yaml:"hooks,omitempty"`    // Commands run on vault events
+
+	// LockTimeout is how long to wait for a vault locked by another
+	// dotsecenv process, as a Go duration ("10s", "1m"). Empty means
+	// DefaultLockTimeout; "0s" fails immediately.
+	LockTimeout string `yaml:"lock_timeout,omitempty"`
+
+	// ClockSkewTolerance is how far in the future, as a Go duration, a
+	// secret value's added_at may be before 'validate' warns about it.
+	// Empty means DefaultClockSkewTolerance.
+	ClockSkewTolerance string `yaml:"clock_skew_tolerance,omitempty"`
+
+	// SessionTTL is how long, as a Go duration, a fingerprint given with
+	// --fingerprint stays validated against the GPG keyring before it is
+	// checked again. Empty means DefaultSessionTTL; "0s" disables caching.
+	SessionTTL string `yaml:"session_ttl,omitempty"`
+
+	// MaxHistory is how many values of a secret 'secret put --replace'
+	// keeps, counting the new one. Zero keeps every value.
+	MaxHistory int `yaml:"max_history,omitempty"`
+
+	// MaxSecretSize is the largest plaintext, in bytes, 'secret put' and
+	// 'secret put-file' store without --allow-large. Zero means
+	// DefaultMaxSecretSize.
+	MaxSecretSize int64 `yaml:"max_secret_size,omitempty"`
+
+	// MinRecipients is the fewest identities a new secret value may be
+	// readable by. 'secret put' and 'secret revoke' refuse to write a value
+	// shared more narrowly. Zero disables the check.
+	MinRecipients int `yaml:"min_recipients,omitempty"`
+
+	// StrictStructure makes commands refuse to open a vault file that is
+	// not in canonical structural form, as checked by 'validate': space
+	// indentation and sorted fields.
+	StrictStructure bool `yaml:"strict_structure,omitempty"`
+
+	// SearchOrder lists vault names in the order vaults are searched for a
+	// secret. Vaults not listed are searched after, in the order of Vault.
+	SearchOrder []string `yaml:"search_order,omitempty"`
+
+	// VaultNames maps a path in Vault to the name given to it with the
+	// {name, path} form of a vault entry. Unnamed vaults are absent.
+	VaultNames map[string]string `yaml:"-"`
+
+	// VaultDefaultRecipients maps a path in Vault to the fingerprints given
+	// as default_recipients in the {name, path} form of a vault entry.
+	// 'secret put' into that vault also encrypts to them.
+	VaultDefaultRecipients map[string][]string `yaml:"-"`
 }
 
+// DefaultLockTimeout is the lock timeout used when lock_timeout is not set.
+const DefaultLockTimeout = 10 * time.Second
+
+// DefaultClockSkewTolerance is the clock skew tolerance used when
+// clock_skew_tolerance is not set.
+const DefaultClockSkewTolerance = 5 * time.Minute
+
+// DefaultSessionTTL is the session cache lifetime used when session_ttl is
+// not set.
+const DefaultSessionTTL = 15 * time.Minute
+
+// DefaultMaxSecretSize is the secret size limit used when max_secret_size
+// is not set.
+const DefaultMaxSecretSize int64 = 1 << 20
+
 // UnmarshalYAML provides custom YAML unmarshaling with better error messages for vault configuration
 func (c *Config) UnmarshalYAML(node *yaml.Node) error {
 	// Create a temporary struct with the same fields for unmarshaling
 	type configAlias Config
 	var temp configAlias
 
+	names, recipients, err := extractVaultNames(node)
+	if err != nil {
+		return err
+	}
+
 	if err := node.Decode(&temp); err != nil {
 		// Check if this is a vault configuration error
 		if strings.Contains(err.Error(), "cannot unmarshal") && strings.Contains(err.Error(), "into []string") {
@@ -73,6 +158,7 @@ func (c *Config) UnmarshalYAML(node *yaml.Node) error {
 			return fmt.Errorf(
 				"invalid vault configuration%s:\n"+
 					"  Expected format: vault: [/path/to/vault, /path/to/other]\n"+
+					"  or with names: vault: [{name: prod, path: /path/to/vault}]\n"+
 					"  Got: vault structure error (check for object syntax or missing brackets)\n"+
 					"  Original error: %w",
 				lineInfo, err,
@@ -82,9 +168,158 @@ func (c *Config) UnmarshalYAML(node *yaml.Node) error {
 	}
 
 	*c = Config(temp)
+	c.VaultNames = names
+	c.VaultDefaultRecipients = recipients
+
+	seen := make(map[string]bool, len(c.SearchOrder))
+	for _, name := range c.SearchOrder {
+		if !c.hasVaultName(name) {
+			return fmt.Errorf("invalid search_order: no vault named %q", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("invalid search_order: vault %q listed more than once", name)
+		}
+		seen[name] = true
+	}
 	return nil
 }
 
+// vaultNamePattern restricts vault names so they can't be mistaken for a
+// 1-based index or a path when given to -v.
+var vaultNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// extractVaultNames rewrites {name, path} entries of the vault list in node
+// to plain paths, returning the names and any default_recipients keyed by
+// path.
+func extractVaultNames(node *yaml.Node) (map[string]string, map[string][]string, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, nil, nil
+	}
+
+	var names map[string]string
+	var recipients map[string][]string
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		list := node.Content[i+1]
+		if node.Content[i].Value != "vault" || list.Kind != yaml.SequenceNode {
+			continue
+		}
+		for j, item := range list.Content {
+			if item.Kind != yaml.MappingNode {
+				continue
+			}
+			var entry struct {
+				Name              string   `yaml:"name"`
+				Path              string   `yaml:"path"`
+				DefaultRecipients []string `yaml:"default_recipients"`
+			}
+			if err := item.Decode(&entry); err != nil {
+				return nil, nil, fmt.Errorf("invalid vault entry on line %d: %w", item.Line, err)
+			}
+			if entry.Path == "" {
+				return nil, nil, fmt.Errorf("invalid vault entry on line %d: path is required", item.Line)
+			}
+			if !vaultNamePattern.MatchString(entry.Name) {
+				return nil, nil, fmt.Errorf("invalid vault entry on line %d: name %q must start with a letter and contain only letters, digits, '_' and '-'", item.Line, entry.Name)
+			}
+			if names == nil {
+				names = make(map[string]string)
+			}
+			for path, name := range names {
+				if name == entry.Name {
+					return nil, nil, fmt.Errorf("invalid vault entry on line %d: name %q is already used by %s", item.Line, entry.Name, path)
+				}
+			}
+			for _, fp := range entry.DefaultRecipients {
+				if strings.TrimSpace(fp) == "" {
+					return nil, nil, fmt.Errorf("invalid vault entry on line %d: default_recipients must not contain empty fingerprints", item.Line)
+				}
+			}
+			names[entry.Path] = entry.Name
+			if len(entry.DefaultRecipients) > 0 {
+				if recipients == nil {
+					recipients = make(map[string][]string)
+				}
+				recipients[entry.Path] = entry.DefaultRecipients
+			}
+			list.Content[j] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: entry.Path, Line: item.Line, Column: item.Column}
+		}
+	}
+	return names, recipients, nil
+}
+
+// MarshalYAML writes named vaults back in their {name, path} form.
+func (c Config) MarshalYAML() (interface{}, error) {
+	type configAlias Config
+	var node yaml.Node
+	if err := node.Encode(configAlias(c)); err != nil {
+		return nil, err
+	}
+	if len(c.VaultNames) == 0 {
+		return &node, nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != "vault" {
+			continue
+		}
+		for j, item := range node.Content[i+1].Content {
+			name := c.VaultNames[item.Value]
+			if name == "" {
+				continue
+			}
+			entry := &yaml.Node{
+				Kind: yaml.MappingNode,
+				Tag:  "!!map",
+				Content: []*yaml.Node{
+					{Kind: yaml.ScalarNode, Tag: "!!str", Value: "name"},
+					{Kind: yaml.ScalarNode, Tag: "!!str", Value: name},
+					{Kind: yaml.ScalarNode, Tag: "!!str", Value: "path"},
+					item,
+				},
+			}
+			if recipients := c.VaultDefaultRecipients[item.Value]; len(recipients) > 0 {
+				list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+				for _, fp := range recipients {
+					list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: fp})
+				}
+				entry.Content = append(entry.Content,
+					&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "default_recipients"}, list)
+			}
+			node.Content[i+1].Content[j] = entry
+		}
+	}
+	return &node, nil
+}
+
+// VaultNameList returns the name of each vault in Vault, "" for unnamed ones.
+func (c *Config) VaultNameList() []string {
+	names := make([]string, len(c.Vault))
+	for i, path := range c.Vault {
+		names[i] = c.VaultNames[path]
+	}
+	return names
+}
+
+// VaultDefaultRecipientList returns the default_recipients of each vault in
+// Vault, nil for vaults without any.
+func (c *Config) VaultDefaultRecipientList() [][]string {
+	recipients := make([][]string, len(c.Vault))
+	for i, path := range c.Vault {
+		recipients[i] = c.VaultDefaultRecipients[path]
+	}
+	return recipients
+}
+
+// hasVaultName reports whether a configured vault is called name.
+func (c *Config) hasVaultName(name string) bool {
+	for _, path := range c.Vault {
+		if name != "" && c.VaultNames[path] == name {
+			return true
+		}
+	}
+	return false
+}
+
 // ShouldRequireExplicitVaultUpgrade returns true if automatic vault upgrades should be prevented.
 func (c *Config) ShouldRequireExplicitVaultUpgrade() bool {
 	if c.Behavior.RequireExplicitVaultUpgrade != nil {
@@ -101,6 +336,83 @@ func (c *Config) ShouldRestrictToConfiguredVaults() bool {
 	return false
 }
 
+// GetLockTimeout returns the configured lock timeout, or DefaultLockTimeout
+// when lock_timeout is not set.
+func (c *Config) GetLockTimeout() (time.Duration, error) {
+	if c.LockTimeout == "" {
+		return DefaultLockTimeout, nil
+	}
+	timeout, err := time.ParseDuration(c.LockTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid lock_timeout %q: %w", c.LockTimeout, err)
+	}
+	if timeout < 0 {
+		return 0, fmt.Errorf("invalid lock_timeout %q: must not be negative", c.LockTimeout)
+	}
+	return timeout, nil
+}
+
+// GetClockSkewTolerance returns the configured clock skew tolerance, or
+// DefaultClockSkewTolerance when clock_skew_tolerance is not set.
+func (c *Config) GetClockSkewTolerance() (time.Duration, error) {
+	if c.ClockSkewTolerance == "" {
+		return DefaultClockSkewTolerance, nil
+	}
+	tolerance, err := time.ParseDuration(c.ClockSkewTolerance)
+	if err != nil {
+		return 0, fmt.Errorf("invalid clock_skew_tolerance %q: %w", c.ClockSkewTolerance, err)
+	}
+	if tolerance < 0 {
+		return 0, fmt.Errorf("invalid clock_skew_tolerance %q: must not be negative", c.ClockSkewTolerance)
+	}
+	return tolerance, nil
+}
+
+// GetSessionTTL returns the configured session cache lifetime, or
+// DefaultSessionTTL when session_ttl is not set.
+func (c *Config) GetSessionTTL() (time.Duration, error) {
+	if c.SessionTTL == "" {
+		return DefaultSessionTTL, nil
+	}
+	ttl, err := time.ParseDuration(c.SessionTTL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid session_ttl %q: %w", c.SessionTTL, err)
+	}
+	if ttl < 0 {
+		return 0, fmt.Errorf("invalid session_ttl %q: must not be negative", c.SessionTTL)
+	}
+	return ttl, nil
+}
+
+// GetMaxHistory returns the configured max_history, rejecting negative values.
+func (c *Config) GetMaxHistory() (int, error) {
+	if c.MaxHistory < 0 {
+		return 0, fmt.Errorf("invalid max_history %d: must not be negative", c.MaxHistory)
+	}
+	return c.MaxHistory, nil
+}
+
+// GetMinRecipients returns the configured min_recipients, rejecting negative
+// values.
+func (c *Config) GetMinRecipients() (int, error) {
+	if c.MinRecipients < 0 {
+		return 0, fmt.Errorf("invalid min_recipients %d: must not be negative", c.MinRecipients)
+	}
+	return c.MinRecipients, nil
+}
+
+// GetMaxSecretSize returns the configured max_secret_size, or
+// DefaultMaxSecretSize when it is not set.
+func (c *Config) GetMaxSecretSize() (int64, error) {
+	if c.MaxSecretSize < 0 {
+		return 0, fmt.Errorf("invalid max_secret_size %d: must not be negative", c.MaxSecretSize)
+	}
+	if c.MaxSecretSize == 0 {
+		return DefaultMaxSecretSize, nil
+	}
+	return c.MaxSecretSize, nil
+}
+
 // DefaultConfig returns a new Config with FIPS 186-5 compliant algorithm defaults.
 // Algorithm minimums are set per the Digital Signature Standard:
 //   - RSA: 2048 bits minimum (FIPS 186-5)
@@ -135,6 +447,50 @@ func DefaultConfig() Config {
 	}
 }
 
+// Approved-algorithm presets for 'init config'. PresetDefault is
+// DefaultConfig's set.
+const (
+	PresetDefault = "default"
+	PresetMinimal = "minimal"
+	PresetFIPS    = "fips"
+	PresetModern  = "modern"
+)
+
+// AlgorithmPresets lists the preset names accepted by
+// PresetApprovedAlgorithms, the default first.
+var AlgorithmPresets = []string{PresetDefault, PresetMinimal, PresetFIPS, PresetModern}
+
+// PresetApprovedAlgorithms returns the approved_algorithms of the named
+// preset:
+//   - default: the FIPS 186-5 signature set of DefaultConfig
+//   - minimal: RSA 2048 bits and up only, the widest-supported key type
+//   - fips: RSA 3072+ and ECC P-384/P-521. EdDSA is left out because
+//     OpenPGP pairs Ed25519/Ed448 with X25519/X448 encryption subkeys, which
+//     SP 800-56A does not approve; RSA-2048 is disallowed after 2030 by
+//     SP 800-131A
+//   - modern: Ed25519/Ed448 and ECC P-384/P-521, no RSA
+func PresetApprovedAlgorithms(name string) ([]ApprovedAlgorithm, error) {
+	switch name {
+	case PresetDefault, "":
+		return DefaultConfig().ApprovedAlgorithms, nil
+	case PresetMinimal:
+		return []ApprovedAlgorithm{
+			{Algo: "RSA", MinBits: 2048},
+		}, nil
+	case PresetFIPS:
+		return []ApprovedAlgorithm{
+			{Algo: "ECC", Curves: []string{"P-384", "P-521"}, MinBits: 384},
+			{Algo: "RSA", MinBits: 3072},
+		}, nil
+	case PresetModern:
+		return []ApprovedAlgorithm{
+			{Algo: "EdDSA", Curves: []string{"Ed25519", "Ed448"}, MinBits: 255},
+			{Algo: "ECC", Curves: []string{"P-384", "P-521"}, MinBits: 384},
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown algorithm preset %q (one of: %s)", name, strings.Join(AlgorithmPresets, ", "))
+}
+
 // Load reads the config from the specified path. If the file doesn't exist or
 // is empty, it returns an error.
 func Load(path string) (Config, error) {
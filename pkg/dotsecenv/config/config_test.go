@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -183,3 +184,151 @@ vault:
 		t.Errorf("expected custom error message, got: %v", err)
 	}
 }
+
+func TestGetLockTimeout(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", DefaultLockTimeout, false},
+		{"30s", 30 * time.Second, false},
+		{"0s", 0, false},
+		{"-1s", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		cfg := Config{LockTimeout: tt.value}
+		got, err := cfg.GetLockTimeout()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("GetLockTimeout(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("GetLockTimeout(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestGetClockSkewTolerance(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", DefaultClockSkewTolerance, false},
+		{"1h", time.Hour, false},
+		{"0s", 0, false},
+		{"-1m", 0, true},
+		{"later", 0, true},
+	}
+	for _, tt := range tests {
+		cfg := Config{ClockSkewTolerance: tt.value}
+		got, err := cfg.GetClockSkewTolerance()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("GetClockSkewTolerance(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("GetClockSkewTolerance(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestGetMaxSecretSize(t *testing.T) {
+	tests := []struct {
+		value   int64
+		want    int64
+		wantErr bool
+	}{
+		{0, DefaultMaxSecretSize, false},
+		{4096, 4096, false},
+		{-1, 0, true},
+	}
+	for _, tt := range tests {
+		cfg := Config{MaxSecretSize: tt.value}
+		got, err := cfg.GetMaxSecretSize()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("GetMaxSecretSize(%d) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("GetMaxSecretSize(%d) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalYAML_NamedVaults(t *testing.T) {
+	body := `
+vault:
+  - /plain/vault
+  - name: prod
+    path: /srv/prod.vault
+    default_recipients: [ALICEFP, BOBFP]
+  - {name: dev, path: ~/dev.vault}
+search_order: [dev, prod]
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(body), &cfg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	wantPaths := []string{"/plain/vault", "/srv/prod.vault", "~/dev.vault"}
+	if !slices.Equal(cfg.Vault, wantPaths) {
+		t.Errorf("Vault = %v, want %v", cfg.Vault, wantPaths)
+	}
+	if got, want := cfg.VaultNameList(), []string{"", "prod", "dev"}; !slices.Equal(got, want) {
+		t.Errorf("VaultNameList() = %v, want %v", got, want)
+	}
+	if !slices.Equal(cfg.SearchOrder, []string{"dev", "prod"}) {
+		t.Errorf("SearchOrder = %v", cfg.SearchOrder)
+	}
+	recipients := cfg.VaultDefaultRecipientList()
+	if len(recipients) != 3 || recipients[0] != nil || !slices.Equal(recipients[1], []string{"ALICEFP", "BOBFP"}) || recipients[2] != nil {
+		t.Errorf("VaultDefaultRecipientList() = %v", recipients)
+	}
+
+	// Names survive a save and reload.
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := Save(cfgPath, cfg); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := Load(cfgPath)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !slices.Equal(loaded.Vault, wantPaths) || !slices.Equal(loaded.VaultNameList(), cfg.VaultNameList()) {
+		t.Errorf("round trip lost vault names: %v %v", loaded.Vault, loaded.VaultNameList())
+	}
+	if !slices.Equal(loaded.SearchOrder, cfg.SearchOrder) {
+		t.Errorf("round trip lost search_order: %v", loaded.SearchOrder)
+	}
+	if got := loaded.VaultDefaultRecipients["/srv/prod.vault"]; !slices.Equal(got, []string{"ALICEFP", "BOBFP"}) {
+		t.Errorf("round trip lost default_recipients: %v", got)
+	}
+}
+
+func TestUnmarshalYAML_NamedVaultErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"missing path", "vault:\n  - name: prod\n", "path is required"},
+		{"numeric name", "vault:\n  - {name: '2', path: /a}\n", "must start with a letter"},
+		{"path-like name", "vault:\n  - {name: a/b, path: /a}\n", "must start with a letter"},
+		{"duplicate name", "vault:\n  - {name: prod, path: /a}\n  - {name: prod, path: /b}\n", "already used"},
+		{"empty default recipient", "vault:\n  - {name: prod, path: /a, default_recipients: ['']}\n", "empty fingerprints"},
+		{"unknown search_order", "vault:\n  - {name: prod, path: /a}\nsearch_order: [dev]\n", `no vault named "dev"`},
+		{"repeated search_order", "vault:\n  - {name: prod, path: /a}\nsearch_order: [prod, prod]\n", "more than once"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			err := yaml.Unmarshal([]byte(tt.body), &cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
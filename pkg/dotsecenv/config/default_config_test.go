@@ -1,6 +1,9 @@
 package config
 
 import (
+	"fmt"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -12,3 +15,40 @@ func TestDefaultConfig_VaultEntries(t *testing.T) {
 		t.Errorf("expected default config to have 0 vault entries, got %d", len(cfg.Vault))
 	}
 }
+
+func TestPresetApprovedAlgorithms(t *testing.T) {
+	// describe summarizes an algorithm list as "ALGO:min_bits:curve,curve".
+	describe := func(algs []ApprovedAlgorithm) []string {
+		var out []string
+		for _, a := range algs {
+			out = append(out, fmt.Sprintf("%s:%d:%s", a.Algo, a.MinBits, strings.Join(a.Curves, ",")))
+		}
+		return out
+	}
+
+	tests := []struct {
+		preset string
+		want   []string
+	}{
+		{"", []string{"ECC:384:P-384,P-521", "EdDSA:255:Ed25519,Ed448", "RSA:2048:"}},
+		{PresetDefault, []string{"ECC:384:P-384,P-521", "EdDSA:255:Ed25519,Ed448", "RSA:2048:"}},
+		{PresetMinimal, []string{"RSA:2048:"}},
+		{PresetFIPS, []string{"ECC:384:P-384,P-521", "RSA:3072:"}},
+		{PresetModern, []string{"EdDSA:255:Ed25519,Ed448", "ECC:384:P-384,P-521"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.preset, func(t *testing.T) {
+			algs, err := PresetApprovedAlgorithms(tt.preset)
+			if err != nil {
+				t.Fatalf("PresetApprovedAlgorithms(%q) failed: %v", tt.preset, err)
+			}
+			if got := describe(algs); !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := PresetApprovedAlgorithms("legacy"); err == nil {
+		t.Error("expected an error for an unknown preset")
+	}
+}
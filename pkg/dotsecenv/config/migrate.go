@@ -0,0 +1,142 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// legacyStrictFields are the behavior settings that the top-level strict
+// flag used to switch on together.
+var legacyStrictFields = []string{
+	"require_explicit_vault_upgrade",
+	"restrict_to_configured_vaults",
+}
+
+// Migrate rewrites config file data in an older shape to the current one and
+// returns a description of each change. Comments stay on the entries that
+// remain. Data already in the current shape is returned unchanged with no
+// changes, so migrating twice is the same as migrating once.
+//
+// Migrations:
+//   - strict: true becomes the behavior settings it stood for; settings
+//     already present in behavior are kept. strict: false is dropped, as the
+//     behavior settings default to false.
+//   - A top-level fingerprint is dropped; identity comes from the signed
+//     login section, created with 'dotsecenv login'.
+func Migrate(data []byte) ([]byte, []string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("config is not a YAML mapping")
+	}
+	root := doc.Content[0]
+
+	var changes []string
+	migrated, err := migrateStrict(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	changes = append(changes, migrated...)
+	changes = append(changes, migrateFingerprint(root)...)
+
+	if len(changes) == 0 {
+		return data, nil, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return buf.Bytes(), changes, nil
+}
+
+// migrateStrict replaces a top-level strict flag in root with the behavior
+// settings it stood for.
+func migrateStrict(root *yaml.Node) ([]string, error) {
+	i := mappingIndex(root, "strict")
+	if i < 0 {
+		return nil, nil
+	}
+	var strict bool
+	if err := root.Content[i+1].Decode(&strict); err != nil {
+		return nil, fmt.Errorf("invalid strict value on line %d: expected true or false", root.Content[i+1].Line)
+	}
+	removeMappingEntry(root, i)
+
+	if !strict {
+		return []string{"removed strict: false (behavior settings default to false)"}, nil
+	}
+
+	behavior := mappingValue(root, "behavior")
+	if behavior == nil {
+		behavior = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		root.Content = append(root.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "behavior"},
+			behavior)
+	} else if behavior.Kind == yaml.ScalarNode && behavior.Tag == "!!null" {
+		// An empty "behavior:" section
+		behavior.Kind, behavior.Tag, behavior.Value = yaml.MappingNode, "!!map", ""
+	} else if behavior.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("invalid behavior section on line %d: expected a mapping", behavior.Line)
+	}
+
+	var changes []string
+	for _, field := range legacyStrictFields {
+		if existing := mappingValue(behavior, field); existing != nil {
+			changes = append(changes, fmt.Sprintf("kept behavior.%s: %s (already set; strict: true not applied to it)", field, existing.Value))
+			continue
+		}
+		behavior.Content = append(behavior.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: field},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+		changes = append(changes, fmt.Sprintf("replaced strict: true with behavior.%s: true", field))
+	}
+	return changes, nil
+}
+
+// migrateFingerprint drops a top-level fingerprint from root.
+func migrateFingerprint(root *yaml.Node) []string {
+	i := mappingIndex(root, "fingerprint")
+	if i < 0 {
+		return nil
+	}
+	fp := root.Content[i+1].Value
+	removeMappingEntry(root, i)
+	if mappingValue(root, "login") == nil {
+		return []string{fmt.Sprintf("removed fingerprint: %s (run 'dotsecenv login %s' to create a signed login)", fp, fp)}
+	}
+	return []string{fmt.Sprintf("removed fingerprint: %s (superseded by the login section)", fp)}
+}
+
+// mappingIndex returns the index in m.Content of the key node named key, or
+// -1 if m has no such key.
+func mappingIndex(m *yaml.Node, key string) int {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// mappingValue returns the value node of key in m, or nil.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if i := mappingIndex(m, key); i >= 0 {
+		return m.Content[i+1]
+	}
+	return nil
+}
+
+// removeMappingEntry removes the key at index i and its value from m.
+func removeMappingEntry(m *yaml.Node, i int) {
+	m.Content = append(m.Content[:i], m.Content[i+2:]...)
+}
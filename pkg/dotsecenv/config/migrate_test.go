@@ -0,0 +1,130 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestMigrate_StrictTrue(t *testing.T) {
+	legacy := `# dotsecenv config
+approved_algorithms:
+  - algo: RSA
+    min_bits: 2048
+# Vaults searched in order
+vault:
+  - /tmp/v
+strict: true
+`
+	out, changes, err := Migrate([]byte(legacy))
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Errorf("expected 2 changes, got %v", changes)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(out, &cfg); err != nil {
+		t.Fatalf("migrated config does not load: %v\n%s", err, out)
+	}
+	if !cfg.ShouldRequireExplicitVaultUpgrade() || !cfg.ShouldRestrictToConfiguredVaults() {
+		t.Errorf("expected strict behavior settings, got %+v\n%s", cfg.Behavior, out)
+	}
+	if len(cfg.Vault) != 1 || cfg.Vault[0] != "/tmp/v" {
+		t.Errorf("vault entries lost: %v", cfg.Vault)
+	}
+
+	text := string(out)
+	if strings.Contains(text, "strict:") {
+		t.Errorf("strict should be removed:\n%s", text)
+	}
+	for _, comment := range []string{"# dotsecenv config", "# Vaults searched in order"} {
+		if !strings.Contains(text, comment) {
+			t.Errorf("comment %q lost:\n%s", comment, text)
+		}
+	}
+
+	again, changes, err := Migrate(out)
+	if err != nil {
+		t.Fatalf("second Migrate failed: %v", err)
+	}
+	if len(changes) != 0 || string(again) != text {
+		t.Errorf("migrating a migrated config should change nothing, got %v", changes)
+	}
+}
+
+func TestMigrate_StrictKeepsExistingBehavior(t *testing.T) {
+	legacy := `vault:
+  - /tmp/v
+strict: true
+behavior:
+  restrict_to_configured_vaults: false
+`
+	out, changes, err := Migrate([]byte(legacy))
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(out, &cfg); err != nil {
+		t.Fatalf("migrated config does not load: %v\n%s", err, out)
+	}
+	if !cfg.ShouldRequireExplicitVaultUpgrade() {
+		t.Error("expected require_explicit_vault_upgrade from strict: true")
+	}
+	if cfg.ShouldRestrictToConfiguredVaults() {
+		t.Error("explicit restrict_to_configured_vaults: false should be kept")
+	}
+	if !strings.Contains(strings.Join(changes, "\n"), "kept behavior.restrict_to_configured_vaults") {
+		t.Errorf("expected the kept setting to be reported, got %v", changes)
+	}
+}
+
+func TestMigrate_StrictFalseAndFingerprint(t *testing.T) {
+	legacy := `fingerprint: ABC123
+vault:
+  - /tmp/v
+strict: false
+`
+	out, changes, err := Migrate([]byte(legacy))
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Errorf("expected 2 changes, got %v", changes)
+	}
+	text := string(out)
+	if strings.Contains(text, "strict") || strings.Contains(text, "fingerprint") || strings.Contains(text, "behavior") {
+		t.Errorf("expected strict and fingerprint removed without adding behavior:\n%s", text)
+	}
+	if !strings.Contains(strings.Join(changes, "\n"), "dotsecenv login ABC123") {
+		t.Errorf("expected a hint to log in, got %v", changes)
+	}
+}
+
+func TestMigrate_CurrentConfigUnchanged(t *testing.T) {
+	current := `# keep me
+vault:   # odd spacing is kept too
+    - /tmp/v
+behavior:
+  restrict_to_configured_vaults: true
+`
+	out, changes, err := Migrate([]byte(current))
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+	if string(out) != current {
+		t.Errorf("current config should be returned as is, got:\n%s", out)
+	}
+}
+
+func TestMigrate_InvalidStrict(t *testing.T) {
+	if _, _, err := Migrate([]byte("strict: sometimes\n")); err == nil {
+		t.Error("expected error for non-boolean strict")
+	}
+}
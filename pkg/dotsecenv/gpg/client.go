@@ -4,6 +4,7 @@ import (
 	"crypto/elliptic"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"os/exec"
 	"reflect"
 	"strconv"
@@ -44,6 +45,8 @@ type Client interface {
 	EncryptToRecipients(plaintext []byte, recipients []string, signingKey *crypto.Key) (string, error)
 	SignDataWithAgent(fingerprint string, data []byte) (string, error)
 	DecryptWithAgent(ciphertext []byte, fingerprint string) ([]byte, error)
+	EncryptStream(dst io.Writer, recipients []string) (io.WriteCloser, error)
+	DecryptStream(dst io.Writer, ciphertext io.Reader, fingerprint string) error
 	ExtractAlgorithmAndCurve(fullAlgorithm string) (algorithm string, curve string)
 	GetKeyCreationTime(fingerprint string) time.Time
 	SignIdentity(identity *identity.Identity, signerFingerprint string) (hash string, signature string, err error)
@@ -57,7 +60,18 @@ type Client interface {
 
 // GPGClient provides GPG operations.
 type GPGClient struct {
+	// Validator, when set, gates the keys new data is encrypted to: see
+	// GetApprovedPublicKeyInfo. Looking a key up with GetPublicKeyInfo
+	// ignores it, so tightening the allowed algorithms never stops existing
+	// secrets from being read.
 	Validator *dscrypto.AlgorithmValidator
+
+	// PassphraseCommand, when set, is run to obtain the secret key passphrase
+	// for decryption, which is then passed to gpg via loopback pinentry.
+	PassphraseCommand string
+
+	// runPassphrase overrides how PassphraseCommand is executed (for tests).
+	runPassphrase func(command string) ([]byte, error)
 }
 
 // DefaultGPGClient is the default client instance.
@@ -526,6 +540,8 @@ func (c *GPGClient) isKeyExpired(fingerprint string) bool {
 }
 
 // GetPublicKeyInfo retrieves information about a public key from gpg-agent.
+// The key's algorithm is not checked against Validator: policy gates the
+// creation of new data, not access to what already exists.
 func (c *GPGClient) GetPublicKeyInfo(fingerprint string) (*KeyInfo, error) {
 	if fingerprint == "" {
 		return nil, fmt.Errorf("fingerprint cannot be empty")
@@ -569,13 +585,6 @@ func (c *GPGClient) GetPublicKeyInfo(fingerprint string) (*KeyInfo, error) {
 		bits = 4096
 	}
 
-	// Validate algorithm using the configured validator if present
-	if c.Validator != nil {
-		if err := c.Validator.ValidateAsymmetric(algo); err != nil {
-			return nil, fmt.Errorf("algorithm validation failed: %w", err)
-		}
-	}
-
 	canEncrypt := IsKeyEncryptionCapable(key)
 	createdAt := c.GetKeyCreationTime(fingerprint)
 	expiresAt := c.GetKeyExpiration(fingerprint)
@@ -592,6 +601,22 @@ func (c *GPGClient) GetPublicKeyInfo(fingerprint string) (*KeyInfo, error) {
 	}, nil
 }
 
+// GetApprovedPublicKeyInfo is GetPublicKeyInfo for a key about to be used
+// to create new data: it also fails when Validator is set and does not
+// allow the key's algorithm.
+func (c *GPGClient) GetApprovedPublicKeyInfo(fingerprint string) (*KeyInfo, error) {
+	info, err := c.GetPublicKeyInfo(fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	if c.Validator != nil {
+		if err := c.Validator.ValidateAsymmetric(info.Algorithm); err != nil {
+			return nil, fmt.Errorf("algorithm validation failed: %w", err)
+		}
+	}
+	return info, nil
+}
+
 // IsKeyEncryptionCapable checks if a key is capable of encryption.
 func IsKeyEncryptionCapable(key *crypto.Key) bool {
 	if key == nil {
@@ -669,6 +694,22 @@ func GetKeyFingerprint(publicKeyBase64 string) (string, error) {
 	return key.GetFingerprint(), nil
 }
 
+// ArmorPublicKey converts a base64-encoded public key, as stored in vault
+// identities, to an ASCII-armored public key block for 'gpg --import'.
+func ArmorPublicKey(publicKeyBase64 string) (string, error) {
+	keyBinary, err := base64.StdEncoding.DecodeString(publicKeyBase64)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode key from base64: %w", err)
+	}
+
+	key, err := crypto.NewKey(keyBinary)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse key: %w", err)
+	}
+
+	return key.GetArmoredPublicKey()
+}
+
 // ExportPublicKeyBase64 exports a key as base64-encoded binary.
 func ExportPublicKeyBase64(key *crypto.Key) (string, error) {
 	if key == nil {
@@ -1,13 +1,16 @@
 package gpg
 
 import (
+	"bytes"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"os/exec"
 	"strings"
 
 	"github.com/ProtonMail/gopenpgp/v3/crypto"
 	"github.com/ProtonMail/gopenpgp/v3/profile"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
 )
 
@@ -47,32 +50,72 @@ func (c *GPGClient) DecryptWithAgent(ciphertext []byte, fingerprint string) ([]b
 	}
 
 	// Ciphertext is armored message (as bytes), use directly
-	armoredCiphertext := string(ciphertext)
+	var output bytes.Buffer
+	if err := c.runDecrypt(&output, bytes.NewReader(ciphertext), fingerprint); err != nil {
+		return nil, err
+	}
+	return output.Bytes(), nil
+}
+
+// DecryptStream decrypts the armored message read from ciphertext using
+// gpg-agent and writes the plaintext to dst as gpg produces it, so large
+// values never have to be held in memory. fingerprint is optional - if
+// provided, restricts which secret key is tried. On error, dst may already
+// hold partial plaintext and should be discarded.
+func (c *GPGClient) DecryptStream(dst io.Writer, ciphertext io.Reader, fingerprint string) error {
+	return c.runDecrypt(dst, ciphertext, fingerprint)
+}
 
+// runDecrypt runs gpg --decrypt with ciphertext on stdin and stdout going to dst.
+func (c *GPGClient) runDecrypt(dst io.Writer, ciphertext io.Reader, fingerprint string) error {
 	// Build GPG command
 	// If fingerprint is provided, use it to restrict which secret key is tried.
-	var cmd *exec.Cmd
+	args := []string{"--decrypt", "--quiet"}
 	if fingerprint != "" {
-		cmd = exec.Command(GetGPGProgram(), "--decrypt", "--try-secret-key", fingerprint, "--quiet")
-	} else {
-		cmd = exec.Command(GetGPGProgram(), "--decrypt", "--quiet")
+		args = []string{"--decrypt", "--try-secret-key", fingerprint, "--quiet"}
+	}
+
+	// With a passphrase command, feed the passphrase to gpg via loopback
+	// pinentry so decryption never needs a TTY.
+	var passphrase []byte
+	if c.PassphraseCommand != "" {
+		var err error
+		passphrase, err = c.passphrase()
+		if err != nil {
+			return err
+		}
+		defer clear(passphrase)
+		args = append(append([]string{}, loopbackArgs...), args...)
+	}
+
+	cmd := exec.Command(GetGPGProgram(), args...)
+	cmd.Stdin = ciphertext
+	cmd.Stdout = dst
+
+	if passphrase != nil {
+		release, err := attachPassphrase(cmd, passphrase)
+		if err != nil {
+			return err
+		}
+		defer release()
 	}
-	cmd.Stdin = strings.NewReader(armoredCiphertext)
 
 	// Capture stderr to show actual GPG errors
 	var stderr strings.Builder
 	cmd.Stderr = &stderr
 
-	output, err := cmd.Output()
-	if err != nil {
+	if err := cmd.Run(); err != nil {
 		stderrMsg := stderr.String()
+		if passphrase != nil && isLoopbackUnsupported(stderrMsg) {
+			return errLoopbackUnsupported(stderrMsg)
+		}
 		if stderrMsg != "" {
-			return nil, fmt.Errorf("failed to decrypt with gpg-agent: %w\nGPG error: %s", err, stderrMsg)
+			return fmt.Errorf("failed to decrypt with gpg-agent: %w\nGPG error: %s", err, stderrMsg)
 		}
-		return nil, fmt.Errorf("failed to decrypt with gpg-agent: %w\nMake sure gpg-agent is running and has access to the private key", err)
+		return fmt.Errorf("failed to decrypt with gpg-agent: %w\nMake sure gpg-agent is running and has access to the private key", err)
 	}
 
-	return output, nil
+	return nil
 }
 
 // DecryptWithKey decrypts data using a private key.
@@ -123,7 +166,12 @@ func (c *GPGClient) DecryptSecret(encryptedBase64 string, fingerprint string) ([
 	return c.DecryptWithAgent([]byte(armored), fingerprint)
 }
 
-// DecryptSecretValue decrypts a SecretValue and returns the plaintext.
+// DecryptSecretValue decrypts a SecretValue and returns the plaintext,
+// decompressed if the value was stored compressed.
 func (c *GPGClient) DecryptSecretValue(value *vault.SecretValue, fingerprint string) ([]byte, error) {
-	return c.DecryptSecret(value.Value, fingerprint)
+	payload, err := c.DecryptSecret(value.Value, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	return vault.DecompressValue(value, payload, config.DefaultMaxSecretSize)
 }
@@ -3,6 +3,7 @@ package gpg
 import (
 	"encoding/base64"
 	"fmt"
+	"io"
 
 	"github.com/ProtonMail/gopenpgp/v3/constants"
 	"github.com/ProtonMail/gopenpgp/v3/crypto"
@@ -17,6 +18,51 @@ func (c *GPGClient) EncryptToRecipients(plaintext []byte, publicKeyBase64List []
 		return "", fmt.Errorf("no recipients specified")
 	}
 
+	encHandle, err := newEncryptionHandle(publicKeyBase64List, signingKey)
+	if err != nil {
+		return "", err
+	}
+
+	// Encrypt
+	pgpMessage, err := encHandle.Encrypt(plaintext)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt: %w", err)
+	}
+
+	// Return armored encrypted data as string
+	armoredBytes, err := pgpMessage.ArmorBytes()
+	if err != nil {
+		return "", fmt.Errorf("failed to armor message: %w", err)
+	}
+
+	return string(armoredBytes), nil
+}
+
+// EncryptStream returns a writer that encrypts everything written to it for
+// the given recipients and writes the armored ciphertext to dst as it goes,
+// so large values never have to be held in memory. recipients contains
+// base64-encoded public keys. The caller must Close the returned writer to
+// flush the final packets; closing it does not close dst.
+func (c *GPGClient) EncryptStream(dst io.Writer, recipients []string) (io.WriteCloser, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("no recipients specified")
+	}
+
+	encHandle, err := newEncryptionHandle(recipients, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := encHandle.EncryptingWriter(dst, crypto.Armor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create encrypting writer: %w", err)
+	}
+	return w, nil
+}
+
+// newEncryptionHandle builds an encryption handle for the base64-encoded
+// recipient public keys, signing with signingKey when it is non-nil.
+func newEncryptionHandle(publicKeyBase64List []string, signingKey *crypto.Key) (crypto.PGPEncryption, error) {
 	// Use RFC9580 profile which enforces AEAD with AES-256-GCM
 	// RFC 9580 is the updated OpenPGP standard with mandatory AEAD support
 	pgp := crypto.PGPWithProfile(profile.RFC9580())
@@ -24,19 +70,19 @@ func (c *GPGClient) EncryptToRecipients(plaintext []byte, publicKeyBase64List []
 	// Build keyring for multiple recipients
 	recipients, err := crypto.NewKeyRing(nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create key ring: %w", err)
+		return nil, fmt.Errorf("failed to create key ring: %w", err)
 	}
 
 	for i, keyBase64 := range publicKeyBase64List {
 		// Decode base64 to binary
 		keyBinary, err := base64.StdEncoding.DecodeString(keyBase64)
 		if err != nil {
-			return "", fmt.Errorf("failed to decode recipient key %d from base64: %w", i, err)
+			return nil, fmt.Errorf("failed to decode recipient key %d from base64: %w", i, err)
 		}
 
 		key, err := crypto.NewKey(keyBinary)
 		if err != nil {
-			return "", fmt.Errorf("failed to parse recipient key %d: %w", i, err)
+			return nil, fmt.Errorf("failed to parse recipient key %d: %w", i, err)
 		}
 
 		// Check if key can encrypt (has encryption-capable subkeys)
@@ -45,37 +91,25 @@ func (c *GPGClient) EncryptToRecipients(plaintext []byte, publicKeyBase64List []
 			if keyEntity := key.GetEntity(); keyEntity != nil {
 				uid, _ = GetKeyUID(key)
 			}
-			return "", fmt.Errorf("recipient %s has a signing-only key and cannot decrypt messages (requires an encryption-capable subkey)", uid)
+			return nil, fmt.Errorf("recipient %s has a signing-only key and cannot decrypt messages (requires an encryption-capable subkey)", uid)
 		}
 
 		if err := recipients.AddKey(key); err != nil {
-			return "", fmt.Errorf("failed to add recipient key %d to keyring: %w", i, err)
+			return nil, fmt.Errorf("failed to add recipient key %d to keyring: %w", i, err)
 		}
 	}
 
 	// Explicitly disable compression per RFC 9580 (avoids CRIME-style side-channel attacks)
 	encHandle, err := pgp.Encryption().Recipients(recipients).SigningKey(signingKey).CompressWith(constants.NoCompression).New()
 	if err != nil {
-		return "", fmt.Errorf("failed to create encryption handle: %w", err)
+		return nil, fmt.Errorf("failed to create encryption handle: %w", err)
 	}
-
-	// Encrypt
-	pgpMessage, err := encHandle.Encrypt(plaintext)
-	if err != nil {
-		return "", fmt.Errorf("failed to encrypt: %w", err)
-	}
-
-	// Return armored encrypted data as string
-	armoredBytes, err := pgpMessage.ArmorBytes()
-	if err != nil {
-		return "", fmt.Errorf("failed to armor message: %w", err)
-	}
-
-	return string(armoredBytes), nil
+	return encHandle, nil
 }
 
 // EncryptToRecipientsWithFingerprints encrypts data to multiple recipients using their fingerprints.
 // This is a convenience function that looks up public keys from GPG.
+// Recipients whose algorithm Validator does not allow are refused.
 func (c *GPGClient) EncryptToRecipientsWithFingerprints(plaintext []byte, fingerprints []string, signingFingerprint string) (string, error) {
 	if len(fingerprints) == 0 {
 		return "", fmt.Errorf("no recipients specified")
@@ -84,7 +118,7 @@ func (c *GPGClient) EncryptToRecipientsWithFingerprints(plaintext []byte, finger
 	// Collect public keys for all recipients
 	publicKeys := make([]string, 0, len(fingerprints))
 	for _, fp := range fingerprints {
-		keyInfo, err := c.GetPublicKeyInfo(fp)
+		keyInfo, err := c.GetApprovedPublicKeyInfo(fp)
 		if err != nil {
 			return "", fmt.Errorf("failed to get public key for %s: %w", fp, err)
 		}
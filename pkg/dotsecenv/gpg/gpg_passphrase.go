@@ -0,0 +1,68 @@
+package gpg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// passphraseFD is the file descriptor gpg reads the loopback passphrase
+// from. Stdin is already used for the ciphertext, so the passphrase is passed
+// as the first extra file (fd 3).
+const passphraseFD = "3"
+
+// maxPassphraseLength caps the passphrase taken from gpg.passphrase_command.
+// It is written to a pipe before gpg starts, so it must fit the smallest pipe
+// buffer of the supported platforms, and longer output is most likely a
+// command printing more than the passphrase.
+const maxPassphraseLength = 4096
+
+// loopbackArgs are the gpg options that make it read the passphrase from
+// passphraseFD instead of asking pinentry.
+var loopbackArgs = []string{"--batch", "--pinentry-mode", "loopback", "--passphrase-fd", passphraseFD}
+
+// passphrase runs PassphraseCommand and returns its output with the trailing
+// newline removed. The output is never included in errors or logs.
+func (c *GPGClient) passphrase() ([]byte, error) {
+	run := c.runPassphrase
+	if run == nil {
+		run = runPassphraseCommand
+	}
+
+	out, err := run(c.PassphraseCommand)
+	if err != nil {
+		clear(out)
+		return nil, fmt.Errorf("gpg.passphrase_command failed: %w", err)
+	}
+
+	out = bytes.TrimSuffix(out, []byte("\n"))
+	out = bytes.TrimSuffix(out, []byte("\r"))
+	if len(out) == 0 {
+		return nil, errors.New("gpg.passphrase_command produced no output")
+	}
+	if len(out) > maxPassphraseLength {
+		clear(out)
+		return nil, fmt.Errorf("gpg.passphrase_command produced more than %d bytes; it must print only the passphrase", maxPassphraseLength)
+	}
+	return out, nil
+}
+
+// isLoopbackUnsupported reports whether gpg's stderr indicates that loopback
+// pinentry is unavailable, either because gpg predates --pinentry-mode or
+// because gpg-agent has allow-loopback-pinentry disabled.
+func isLoopbackUnsupported(stderr string) bool {
+	s := strings.ToLower(stderr)
+	return strings.Contains(s, "invalid option \"--pinentry-mode\"") ||
+		strings.Contains(s, "invalid option \"--passphrase-fd\"") ||
+		(strings.Contains(s, "loopback") && strings.Contains(s, "not allowed")) ||
+		strings.Contains(s, "not supported by the agent")
+}
+
+// errLoopbackUnsupported is returned when gpg.passphrase_command is set but gpg
+// cannot accept the passphrase through loopback pinentry.
+func errLoopbackUnsupported(stderr string) error {
+	return fmt.Errorf("gpg.passphrase_command requires loopback pinentry, which this gpg does not allow.\n"+
+		"Use GnuPG 2.1.12 or newer and make sure gpg-agent.conf does not disable allow-loopback-pinentry.\n"+
+		"GPG error: %s", stderr)
+}
@@ -0,0 +1,197 @@
+//go:build unix
+
+package gpg
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useFakeGPG installs a shell script as the gpg program for the duration of
+// the test and returns the directory it records its inputs in.
+func useFakeGPG(t *testing.T, script string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gpg")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatalf("failed to write fake gpg: %v", err)
+	}
+
+	prev := configuredPath
+	setGPGProgramInternal(path)
+	t.Cleanup(func() { setGPGProgramInternal(prev) })
+	return dir
+}
+
+func TestDecryptWithAgent_PassphraseCommand(t *testing.T) {
+	dir := useFakeGPG(t, `dir=$(dirname "$0")
+echo "$@" > "$dir/args"
+cat <&3 > "$dir/passphrase"
+cat > "$dir/stdin"
+printf plaintext
+`)
+
+	var ran string
+	client := &GPGClient{
+		PassphraseCommand: "pass show gpg",
+		runPassphrase: func(command string) ([]byte, error) {
+			ran = command
+			return []byte("s3cret\n"), nil
+		},
+	}
+
+	out, err := client.DecryptWithAgent([]byte("CIPHERTEXT"), "ABCD")
+	if err != nil {
+		t.Fatalf("DecryptWithAgent failed: %v", err)
+	}
+	if string(out) != "plaintext" {
+		t.Errorf("expected plaintext, got %q", out)
+	}
+	if ran != "pass show gpg" {
+		t.Errorf("expected passphrase command to run, got %q", ran)
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("fake gpg did not record %s: %v", name, err)
+		}
+		return strings.TrimSpace(string(data))
+	}
+	if args := read("args"); args != "--batch --pinentry-mode loopback --passphrase-fd 3 --decrypt --try-secret-key ABCD --quiet" {
+		t.Errorf("unexpected gpg args: %s", args)
+	}
+	if got := read("passphrase"); got != "s3cret" {
+		t.Errorf("expected passphrase on fd 3, got %q", got)
+	}
+	if got := read("stdin"); got != "CIPHERTEXT" {
+		t.Errorf("expected ciphertext on stdin, got %q", got)
+	}
+}
+
+func TestDecryptWithAgent_WithoutPassphraseCommand(t *testing.T) {
+	dir := useFakeGPG(t, `echo "$@" > "$(dirname "$0")/args"
+printf plaintext
+`)
+
+	client := &GPGClient{
+		runPassphrase: func(string) ([]byte, error) {
+			t.Fatal("passphrase command should not run")
+			return nil, nil
+		},
+	}
+	if _, err := client.DecryptWithAgent([]byte("CIPHERTEXT"), ""); err != nil {
+		t.Fatalf("DecryptWithAgent failed: %v", err)
+	}
+
+	args, _ := os.ReadFile(filepath.Join(dir, "args"))
+	if strings.Contains(string(args), "loopback") {
+		t.Errorf("loopback pinentry should not be used without a passphrase command: %s", args)
+	}
+}
+
+func TestDecryptWithAgent_LoopbackUnsupported(t *testing.T) {
+	useFakeGPG(t, `echo 'gpg: invalid option "--pinentry-mode"' >&2
+exit 2
+`)
+
+	client := &GPGClient{
+		PassphraseCommand: "echo",
+		runPassphrase:     func(string) ([]byte, error) { return []byte("s3cret"), nil },
+	}
+	_, err := client.DecryptWithAgent([]byte("CIPHERTEXT"), "")
+	if err == nil {
+		t.Fatal("expected error when loopback pinentry is unsupported")
+	}
+	if !strings.Contains(err.Error(), "requires loopback pinentry") {
+		t.Errorf("expected loopback error, got: %v", err)
+	}
+}
+
+func TestDecryptWithAgent_PassphraseCommandErrors(t *testing.T) {
+	useFakeGPG(t, "exit 0\n")
+
+	tests := []struct {
+		name    string
+		out     string
+		err     error
+		wantErr string
+	}{
+		{"command fails", "s3cret", errors.New("exit status 1"), "gpg.passphrase_command failed"},
+		{"empty output", "\n", nil, "produced no output"},
+		{"oversized output", strings.Repeat("s3cret", 1000), nil, "produced more than 4096 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &GPGClient{
+				PassphraseCommand: "false",
+				runPassphrase:     func(string) ([]byte, error) { return []byte(tt.out), tt.err },
+			}
+			_, err := client.DecryptWithAgent([]byte("CIPHERTEXT"), "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if strings.Contains(err.Error(), "s3cret") {
+				t.Errorf("error leaks passphrase: %v", err)
+			}
+		})
+	}
+}
+
+func TestDecryptStream_LargePayload(t *testing.T) {
+	// The fake gpg echoes its input, so the test checks the plumbing: the
+	// whole stream must reach gpg's stdin and come back from its stdout.
+	useFakeGPG(t, "exec cat\n")
+
+	payload := bytes.Repeat([]byte("0123456789abcdef"), 3<<18) // 12 MiB
+	var out bytes.Buffer
+	if err := (&GPGClient{}).DecryptStream(&out, bytes.NewReader(payload), "ABCD"); err != nil {
+		t.Fatalf("DecryptStream failed: %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), payload) {
+		t.Errorf("expected %d bytes through gpg, got %d", len(payload), out.Len())
+	}
+}
+
+func TestDecryptStream_ReportsGPGError(t *testing.T) {
+	useFakeGPG(t, "cat > /dev/null\necho 'gpg: decryption failed: No secret key' >&2\nexit 2\n")
+
+	err := (&GPGClient{}).DecryptStream(&bytes.Buffer{}, strings.NewReader("CIPHERTEXT"), "")
+	if err == nil || !strings.Contains(err.Error(), "No secret key") {
+		t.Errorf("expected gpg stderr in error, got %v", err)
+	}
+}
+
+func TestAttachPassphrase_WritesNoCopy(t *testing.T) {
+	// Spare capacity past the passphrase, as left by trimming its newline
+	buf := make([]byte, 16)
+	passphrase := append(buf[:0], "s3cret"...)
+
+	cmd := exec.Command("true")
+	release, err := attachPassphrase(cmd, passphrase)
+	if err != nil {
+		t.Fatalf("attachPassphrase failed: %v", err)
+	}
+	defer release()
+
+	got, err := io.ReadAll(cmd.ExtraFiles[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "s3cret\n" {
+		t.Errorf("pipe holds %q, want %q", got, "s3cret\n")
+	}
+
+	// Clearing the passphrase leaves nothing of it behind
+	clear(passphrase)
+	if !bytes.Equal(buf, make([]byte, len(buf))) {
+		t.Errorf("passphrase buffer not cleared: %q", buf)
+	}
+}
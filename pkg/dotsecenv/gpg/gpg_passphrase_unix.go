@@ -0,0 +1,44 @@
+//go:build unix
+
+package gpg
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// runPassphraseCommand runs command through the shell and returns its stdout.
+// Stderr is passed through so prompts from the command reach the user.
+func runPassphraseCommand(command string) ([]byte, error) {
+	cmd := exec.Command("/bin/sh", "-c", command)
+	cmd.Stderr = os.Stderr
+	return cmd.Output()
+}
+
+// attachPassphrase makes passphrase readable by cmd on passphraseFD. The
+// returned function releases the pipe and must be called after cmd exits.
+func attachPassphrase(cmd *exec.Cmd, passphrase []byte) (func(), error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create passphrase pipe: %w", err)
+	}
+
+	// passphrase caps the passphrase at maxPassphraseLength, far below the
+	// pipe buffer, so the writes complete before gpg starts reading and
+	// cannot block. The newline is written separately:
+	// appending it could copy the passphrase into a buffer the caller never
+	// clears.
+	_, err = w.Write(passphrase)
+	if err == nil {
+		_, err = w.Write([]byte{'\n'})
+	}
+	_ = w.Close()
+	if err != nil {
+		_ = r.Close()
+		return nil, fmt.Errorf("failed to write passphrase pipe: %w", err)
+	}
+
+	cmd.ExtraFiles = []*os.File{r}
+	return func() { _ = r.Close() }, nil
+}
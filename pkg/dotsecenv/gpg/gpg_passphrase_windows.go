@@ -0,0 +1,22 @@
+//go:build windows
+
+package gpg
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// runPassphraseCommand runs command through cmd.exe and returns its stdout.
+func runPassphraseCommand(command string) ([]byte, error) {
+	cmd := exec.Command("cmd", "/C", command)
+	cmd.Stderr = os.Stderr
+	return cmd.Output()
+}
+
+// attachPassphrase is unavailable on Windows, where child processes cannot
+// inherit extra file descriptors.
+func attachPassphrase(cmd *exec.Cmd, passphrase []byte) (func(), error) {
+	return nil, errors.New("gpg.passphrase_command is not supported on Windows")
+}
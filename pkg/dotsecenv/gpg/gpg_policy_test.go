@@ -0,0 +1,75 @@
+package gpg
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+	dscrypto "github.com/dotsecenv/dotsecenv/pkg/dotsecenv/crypto"
+)
+
+// importPublicKey generates a key in process and imports its public half
+// into a fresh GNUPGHOME, returning its fingerprint.
+func importPublicKey(t *testing.T) string {
+	t.Helper()
+	gpgPath, err := exec.LookPath("gpg")
+	if err != nil {
+		t.Skip("gpg not found")
+	}
+	if err := ValidateAndSetGPGProgram(gpgPath); err != nil {
+		t.Fatalf("ValidateAndSetGPGProgram failed: %v", err)
+	}
+	// Keep the path short for gpg's socket paths
+	home, err := os.MkdirTemp("/tmp", "gpg")
+	if err != nil {
+		home = t.TempDir()
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(home) })
+	t.Setenv("GNUPGHOME", home)
+
+	key, err := crypto.PGP().KeyGeneration().AddUserId("Old Signer", "old@example.com").New().GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	publicKey, err := key.GetPublicKey()
+	if err != nil {
+		t.Fatalf("failed to export public key: %v", err)
+	}
+	cmd := exec.Command(gpgPath, "--batch", "--import")
+	cmd.Stdin = bytes.NewReader(publicKey)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("gpg --import failed: %v\n%s", err, out)
+	}
+	return strings.ToUpper(key.GetFingerprint())
+}
+
+func TestGetPublicKeyInfo_IgnoresValidator(t *testing.T) {
+	fp := importPublicKey(t)
+
+	// Only RSA-4096 is approved now; the key is EdDSA
+	rsaOnly := dscrypto.NewAlgorithmValidator(nil, nil, nil, []string{dscrypto.FIPSKeyAlgorithmRSA4096}, nil)
+	client := &GPGClient{Validator: &rsaOnly}
+
+	info, err := client.GetPublicKeyInfo(fp)
+	if err != nil {
+		t.Fatalf("expected the key to be looked up despite the policy, got %v", err)
+	}
+	if !strings.HasPrefix(info.Algorithm, "EdDSA") {
+		t.Fatalf("expected an EdDSA key, got %s", info.Algorithm)
+	}
+
+	if _, err := client.GetApprovedPublicKeyInfo(fp); err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("expected the key to be refused for new data, got %v", err)
+	}
+	if _, err := client.EncryptToRecipientsWithFingerprints([]byte("new"), []string{fp}, ""); err == nil {
+		t.Error("expected encrypting to a disallowed key to fail")
+	}
+
+	client.Validator = nil
+	if _, err := client.GetApprovedPublicKeyInfo(fp); err != nil {
+		t.Errorf("expected no check without a validator, got %v", err)
+	}
+}
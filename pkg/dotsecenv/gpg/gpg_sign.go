@@ -103,8 +103,8 @@ func SignData(privateKey *crypto.Key, data []byte) (string, error) {
 		return "", fmt.Errorf("failed to create signer: %w", err)
 	}
 
-	// Sign the data (with encoding 0 for raw binary)
-	signature, err := signer.Sign(data, 0)
+	// Sign the data as raw binary, the form the vault stores (hex-encoded)
+	signature, err := signer.Sign(data, crypto.Bytes)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign data: %w", err)
 	}
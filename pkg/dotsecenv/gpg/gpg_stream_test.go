@@ -0,0 +1,64 @@
+package gpg
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/ProtonMail/gopenpgp/v3/profile"
+)
+
+func TestEncryptStream_LargePayload(t *testing.T) {
+	key, err := crypto.PGPWithProfile(profile.RFC9580()).KeyGeneration().
+		AddUserId("Stream Test", "stream@example.com").New().GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	publicKey, err := key.GetPublicKey()
+	if err != nil {
+		t.Fatalf("failed to export public key: %v", err)
+	}
+
+	payload := make([]byte, 4<<20)
+	if _, err := rand.Read(payload); err != nil {
+		t.Fatalf("failed to build payload: %v", err)
+	}
+
+	var armored bytes.Buffer
+	client := &GPGClient{}
+	w, err := client.EncryptStream(&armored, []string{base64.StdEncoding.EncodeToString(publicKey)})
+	if err != nil {
+		t.Fatalf("EncryptStream failed: %v", err)
+	}
+	// Write in uneven chunks to exercise partial writes.
+	for rest := payload; len(rest) > 0; {
+		n := min(len(rest), 100_003)
+		if _, err := w.Write(rest[:n]); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+		rest = rest[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	if !bytes.HasPrefix(armored.Bytes(), []byte("-----BEGIN PGP MESSAGE-----")) {
+		t.Fatalf("expected armored output, got %q", armored.Bytes()[:min(armored.Len(), 40)])
+	}
+
+	plaintext, err := DecryptWithKey(key, armored.Bytes())
+	if err != nil {
+		t.Fatalf("failed to decrypt stream output: %v", err)
+	}
+	if !bytes.Equal(plaintext, payload) {
+		t.Errorf("round trip mismatch: got %d bytes, want %d", len(plaintext), len(payload))
+	}
+}
+
+func TestEncryptStream_NoRecipients(t *testing.T) {
+	if _, err := (&GPGClient{}).EncryptStream(&bytes.Buffer{}, nil); err == nil {
+		t.Error("expected error without recipients")
+	}
+}
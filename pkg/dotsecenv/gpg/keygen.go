@@ -174,6 +174,69 @@ func GenerateKeyTemplate(algo KeyAlgorithm, name, email string, opts *KeyTemplat
 	return sb.String(), nil
 }
 
+// ParseKeyTemplate returns the parameters of a GPG batch key generation
+// template, keyed by lower-cased name. Control statements such as
+// %no-protection are returned as keys with an empty value. Comments and
+// blank lines are skipped.
+func ParseKeyTemplate(template string) (map[string]string, error) {
+	params := make(map[string]string)
+	for i, line := range strings.Split(template, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "%") {
+			params[strings.ToLower(strings.Fields(line)[0])] = ""
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected 'Name: value', got %q", i+1, line)
+		}
+		params[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(value)
+	}
+	return params, nil
+}
+
+// ValidateKeyTemplate checks that a custom batch template generates a
+// primary key of algo for name and email, so that approved_algorithms
+// applies to it as to a generated template. Other parameters, such as
+// Expire-Date or the subkey, are left to the template.
+func ValidateKeyTemplate(template string, algo KeyAlgorithm, name, email string) error {
+	info, ok := SupportedAlgorithms[algo]
+	if !ok {
+		return fmt.Errorf("unsupported algorithm: %s", algo)
+	}
+	params, err := ParseKeyTemplate(template)
+	if err != nil {
+		return err
+	}
+
+	// Pairs of template field and required value
+	required := [][2]string{
+		{"Key-Type", info.KeyType},
+		{"Name-Real", name},
+		{"Name-Email", email},
+	}
+	if info.KeyCurve != "" {
+		required = append(required, [2]string{"Key-Curve", info.KeyCurve})
+	}
+	if info.KeyLength > 0 {
+		required = append(required, [2]string{"Key-Length", fmt.Sprintf("%d", info.KeyLength)})
+	}
+	for _, r := range required {
+		field, want := r[0], r[1]
+		got, ok := params[strings.ToLower(field)]
+		if !ok {
+			return fmt.Errorf("template is missing %s (want %q)", field, want)
+		}
+		if !strings.EqualFold(got, want) {
+			return fmt.Errorf("template %s is %q, want %q", field, got, want)
+		}
+	}
+	return nil
+}
+
 // GetSupportedAlgorithmsString returns a human-readable list of supported algorithms.
 func GetSupportedAlgorithmsString() string {
 	return "Supported algorithms: ED25519, RSA4096, P384 (default), P521"
@@ -0,0 +1,227 @@
+package gpg
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/config"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// MemoryClient is a Client whose keys live in process memory instead of the
+// gpg keyring, so neither gpg nor gpg-agent is involved. Keys are generated
+// with GenerateKey and disappear with the client. Encryption is shared with
+// GPGClient; signing and decryption use the in-memory private keys.
+type MemoryClient struct {
+	mu   sync.Mutex
+	keys map[string]*memoryKey
+}
+
+// memoryKey is a generated key with the metadata gpg would report for it.
+type memoryKey struct {
+	key  *crypto.Key
+	info KeyInfo
+}
+
+// Compile-time check that MemoryClient implements Client.
+var _ Client = (*MemoryClient)(nil)
+
+// NewMemoryClient returns a MemoryClient without any keys.
+func NewMemoryClient() *MemoryClient {
+	return &MemoryClient{keys: make(map[string]*memoryKey)}
+}
+
+// GenerateKey creates an Ed25519 key with an encryption subkey for name and
+// email and returns its fingerprint.
+func (m *MemoryClient) GenerateKey(name, email string) (string, error) {
+	key, err := crypto.PGP().KeyGeneration().AddUserId(name, email).New().GenerateKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	publicKey, err := key.GetPublicKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to export public key: %w", err)
+	}
+
+	fingerprint := strings.ToUpper(key.GetFingerprint())
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys[fingerprint] = &memoryKey{
+		key: key,
+		info: KeyInfo{
+			Fingerprint:     fingerprint,
+			UID:             fmt.Sprintf("%s <%s>", name, email),
+			Algorithm:       "EdDSA Ed25519",
+			AlgorithmBits:   255,
+			CreatedAt:       key.GetEntity().PrimaryKey.CreationTime.UTC(),
+			CanEncrypt:      IsKeyEncryptionCapable(key),
+			PublicKeyBase64: base64.StdEncoding.EncodeToString(publicKey),
+		},
+	}
+	return fingerprint, nil
+}
+
+// privateKey returns a copy of the private key for fingerprint. SignData
+// clears the private parameters of the key it is given, so every operation
+// works on its own copy.
+func (m *MemoryClient) privateKey(fingerprint string) (*crypto.Key, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k, ok := m.keys[strings.ToUpper(fingerprint)]
+	if !ok {
+		return nil, fmt.Errorf("secret key not found for fingerprint: %s", fingerprint)
+	}
+	key, err := k.key.Copy()
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy key: %w", err)
+	}
+	return key, nil
+}
+
+// GetPublicKeyInfo returns the metadata of a generated key.
+func (m *MemoryClient) GetPublicKeyInfo(fingerprint string) (*KeyInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k, ok := m.keys[strings.ToUpper(fingerprint)]
+	if !ok {
+		return nil, fmt.Errorf("public key not found for fingerprint: %s", fingerprint)
+	}
+	info := k.info
+	return &info, nil
+}
+
+// EncryptToRecipients encrypts plaintext exactly as GPGClient does.
+func (m *MemoryClient) EncryptToRecipients(plaintext []byte, recipients []string, signingKey *crypto.Key) (string, error) {
+	return (&GPGClient{}).EncryptToRecipients(plaintext, recipients, signingKey)
+}
+
+// EncryptStream encrypts a stream exactly as GPGClient does.
+func (m *MemoryClient) EncryptStream(dst io.Writer, recipients []string) (io.WriteCloser, error) {
+	return (&GPGClient{}).EncryptStream(dst, recipients)
+}
+
+// SignDataWithAgent signs data with the in-memory key for fingerprint.
+func (m *MemoryClient) SignDataWithAgent(fingerprint string, data []byte) (string, error) {
+	key, err := m.privateKey(fingerprint)
+	if err != nil {
+		return "", err
+	}
+	return SignData(key, data)
+}
+
+// DecryptWithAgent decrypts an armored message with the in-memory key for
+// fingerprint.
+func (m *MemoryClient) DecryptWithAgent(ciphertext []byte, fingerprint string) ([]byte, error) {
+	key, err := m.privateKey(fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	defer key.ClearPrivateParams()
+	return DecryptWithKey(key, ciphertext)
+}
+
+// DecryptStream decrypts the armored message read from ciphertext into dst.
+// Unlike GPGClient, the message is decrypted as a whole before writing.
+func (m *MemoryClient) DecryptStream(dst io.Writer, ciphertext io.Reader, fingerprint string) error {
+	data, err := io.ReadAll(ciphertext)
+	if err != nil {
+		return fmt.Errorf("failed to read ciphertext: %w", err)
+	}
+	plaintext, err := m.DecryptWithAgent(data, fingerprint)
+	if err != nil {
+		return err
+	}
+	_, err = dst.Write(plaintext)
+	return err
+}
+
+// ExtractAlgorithmAndCurve splits an algorithm name exactly as GPGClient does.
+func (m *MemoryClient) ExtractAlgorithmAndCurve(fullAlgorithm string) (algorithm string, curve string) {
+	return (&GPGClient{}).ExtractAlgorithmAndCurve(fullAlgorithm)
+}
+
+// GetKeyCreationTime returns the creation time of a generated key, or the
+// zero time for an unknown fingerprint.
+func (m *MemoryClient) GetKeyCreationTime(fingerprint string) time.Time {
+	info, err := m.GetPublicKeyInfo(fingerprint)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.CreatedAt
+}
+
+// SignIdentity signs an identity's canonical hash with the in-memory key.
+func (m *MemoryClient) SignIdentity(id *identity.Identity, signerFingerprint string) (hash string, signature string, err error) {
+	hash = identity.ComputeIdentityHash(id)
+	signature, err = m.SignDataWithAgent(signerFingerprint, []byte(hash))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to sign identity: %w", err)
+	}
+	return hash, signature, nil
+}
+
+// SignSecret signs a secret's canonical hash with the in-memory key.
+func (m *MemoryClient) SignSecret(secret *vault.Secret, signerFingerprint string, algorithmBits int) (hash string, signature string, err error) {
+	hash = vault.ComputeSecretHash(secret, algorithmBits)
+	signature, err = m.SignDataWithAgent(signerFingerprint, []byte(hash))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to sign secret: %w", err)
+	}
+	return hash, signature, nil
+}
+
+// SignSecretValue signs a secret value's canonical hash with the in-memory key.
+func (m *MemoryClient) SignSecretValue(value *vault.SecretValue, secretKey string, signerFingerprint string, algorithmBits int) (hash string, signature string, err error) {
+	hash = vault.ComputeSecretValueHash(value, secretKey, algorithmBits)
+	signature, err = m.SignDataWithAgent(signerFingerprint, []byte(hash))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to sign secret value: %w", err)
+	}
+	return hash, signature, nil
+}
+
+// DecryptSecret decrypts a base64-encoded secret value.
+func (m *MemoryClient) DecryptSecret(encryptedBase64 string, fingerprint string) ([]byte, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(encryptedBase64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode secret from base64: %w", err)
+	}
+	armored, err := ensureArmoredFormat(string(encrypted))
+	if err != nil {
+		return nil, fmt.Errorf("failed to format ciphertext: %w", err)
+	}
+	return m.DecryptWithAgent([]byte(armored), fingerprint)
+}
+
+// DecryptSecretValue decrypts a SecretValue and returns the plaintext,
+// decompressed if the value was stored compressed.
+func (m *MemoryClient) DecryptSecretValue(value *vault.SecretValue, fingerprint string) ([]byte, error) {
+	payload, err := m.DecryptSecret(value.Value, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	return vault.DecompressValue(value, payload, config.DefaultMaxSecretSize)
+}
+
+// IsAgentAvailable always reports true: the keys need no agent.
+func (m *MemoryClient) IsAgentAvailable() bool {
+	return true
+}
+
+// ListSecretKeys lists the generated keys.
+func (m *MemoryClient) ListSecretKeys() ([]SecretKeyInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := make([]SecretKeyInfo, 0, len(m.keys))
+	for _, k := range m.keys {
+		keys = append(keys, SecretKeyInfo{Fingerprint: k.info.Fingerprint, UID: k.info.UID})
+	}
+	return keys, nil
+}
@@ -28,17 +28,32 @@ func ComputeHash(data []byte, algorithmBits int) string {
 	return hex.EncodeToString(hash[:])
 }
 
+// HashHexLength returns the hex-encoded length of the hash ComputeHash produces
+// for algorithmBits: 128 characters for SHA-512, 64 for SHA-256.
+func HashHexLength(algorithmBits int) int {
+	if algorithmBits >= 256 {
+		return sha512.Size * 2
+	}
+	return sha256.Size * 2
+}
+
 // ComputeIdentityHash computes the canonical hash for an identity.
 // The canonical format includes all identity fields in a deterministic order:
 // added_at:algorithm:algorithm_bits:curve:created_at:expires_at:fingerprint:public_key:signed_by:uid
 func ComputeIdentityHash(identity *Identity) string {
+	return ComputeHash([]byte(IdentityCanonicalData(identity)), identity.AlgorithmBits)
+}
+
+// IdentityCanonicalData returns the canonical data string that
+// ComputeIdentityHash hashes for an identity.
+func IdentityCanonicalData(identity *Identity) string {
 	expiresAtStr := ""
 	if identity.ExpiresAt != nil {
 		expiresAtStr = identity.ExpiresAt.Format(time.RFC3339Nano)
 	}
 
 	// Reconstruct the canonical data with all fields: identity:added_at:algorithm:algorithm_bits:curve:created_at:expires_at:fingerprint:public_key:signed_by:uid
-	canonicalData := fmt.Sprintf("identity:%s:%s:%d:%s:%s:%s:%s:%s:%s:%s",
+	return fmt.Sprintf("identity:%s:%s:%d:%s:%s:%s:%s:%s:%s:%s",
 		identity.AddedAt.Format(time.RFC3339Nano),
 		identity.Algorithm,
 		identity.AlgorithmBits,
@@ -49,8 +64,6 @@ func ComputeIdentityHash(identity *Identity) string {
 		identity.PublicKey,
 		identity.SignedBy,
 		identity.UID)
-
-	return ComputeHash([]byte(canonicalData), identity.AlgorithmBits)
 }
 
 // VerifyIdentitySignature verifies the cryptographic signature of an identity.
@@ -0,0 +1,60 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// MarshalCanonical returns v as indented JSON in a canonical form: object
+// keys are sorted, and the elements of every array are sorted by their own
+// canonical encoding. Equal data therefore always encodes to the same
+// bytes, whatever order it was collected in.
+func MarshalCanonical(v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+	return json.MarshalIndent(canonicalize(doc), "", "  ")
+}
+
+// canonicalize sorts the arrays in doc, a value decoded from JSON, from the
+// innermost out. Object keys need no work: encoding/json sorts map keys.
+func canonicalize(doc interface{}) interface{} {
+	switch value := doc.(type) {
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = canonicalize(item)
+		}
+	case []interface{}:
+		keys := make([]string, len(value))
+		for i, item := range value {
+			value[i] = canonicalize(item)
+			// Values decoded from JSON always encode again
+			encoded, _ := json.Marshal(value[i])
+			keys[i] = string(encoded)
+		}
+		sort.Sort(byKey{items: value, keys: keys})
+	}
+	return doc
+}
+
+// byKey sorts items by the parallel slice keys.
+type byKey struct {
+	items []interface{}
+	keys  []string
+}
+
+func (b byKey) Len() int           { return len(b.items) }
+func (b byKey) Less(i, j int) bool { return b.keys[i] < b.keys[j] }
+func (b byKey) Swap(i, j int) {
+	b.items[i], b.items[j] = b.items[j], b.items[i]
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+}
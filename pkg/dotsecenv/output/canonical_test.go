@@ -0,0 +1,76 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMarshalCanonical(t *testing.T) {
+	type item struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+	a := map[string]interface{}{
+		"b": []item{{Name: "y", Tags: []string{"2", "1"}}, {Name: "x", Tags: nil}},
+		"a": []int{3, 1, 2},
+	}
+	b := map[string]interface{}{
+		"a": []int{2, 3, 1},
+		"b": []item{{Name: "x", Tags: nil}, {Name: "y", Tags: []string{"1", "2"}}},
+	}
+
+	gotA, err := MarshalCanonical(a)
+	if err != nil {
+		t.Fatalf("MarshalCanonical failed: %v", err)
+	}
+	gotB, err := MarshalCanonical(b)
+	if err != nil {
+		t.Fatalf("MarshalCanonical failed: %v", err)
+	}
+	if !bytes.Equal(gotA, gotB) {
+		t.Errorf("expected identical output, got:\n%s\nand:\n%s", gotA, gotB)
+	}
+
+	want := `{
+  "a": [
+    1,
+    2,
+    3
+  ],
+  "b": [
+    {
+      "name": "x",
+      "tags": null
+    },
+    {
+      "name": "y",
+      "tags": [
+        "1",
+        "2"
+      ]
+    }
+  ]
+}`
+	if string(gotA) != want {
+		t.Errorf("unexpected output:\n%s", gotA)
+	}
+}
+
+func TestHandlerEncodeJSON_Canonical(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &buf).WithCanonicalMode(true)
+	if err := h.EncodeJSON([]string{"b", "a"}); err != nil {
+		t.Fatalf("EncodeJSON failed: %v", err)
+	}
+	if got, want := buf.String(), "[\n  \"a\",\n  \"b\"\n]\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	if err := NewHandler(&buf, &buf).EncodeJSON([]string{"b", "a"}); err != nil {
+		t.Fatalf("EncodeJSON failed: %v", err)
+	}
+	if got, want := buf.String(), "[\n  \"b\",\n  \"a\"\n]\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
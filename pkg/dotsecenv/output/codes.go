@@ -23,7 +23,6 @@ const (
 	CodeVaultLoadError   Code = "VAULT_LOAD_ERROR"
 	CodeVaultSaveError   Code = "VAULT_SAVE_ERROR"
 	CodeVaultEmpty       Code = "VAULT_EMPTY"
-	CodeVaultLocked      Code = "VAULT_LOCKED"
 	CodeVaultReadOnly    Code = "VAULT_READ_ONLY"
 	CodeSecretNotFound   Code = "SECRET_NOT_FOUND"
 	CodeSecretNoValues   Code = "SECRET_NO_VALUES"
@@ -55,6 +54,9 @@ const (
 	// Algorithm errors (exit code 9)
 	CodeAlgorithmNotAllowed Code = "ALGORITHM_NOT_ALLOWED"
 	CodeAlgorithmWeak       Code = "ALGORITHM_WEAK"
+
+	// Vault locked errors (exit code 10)
+	CodeVaultLocked Code = "VAULT_LOCKED"
 )
 
 // Warning codes
@@ -0,0 +1,38 @@
+package output
+
+import "os"
+
+// Color is an ANSI foreground color used for human-readable output.
+type Color string
+
+// Colors used for status output.
+const (
+	ColorGreen  Color = "\033[32m"
+	ColorRed    Color = "\033[31m"
+	ColorYellow Color = "\033[33m"
+)
+
+// colorReset ends a colored span.
+const colorReset = "\033[0m"
+
+// Colorize wraps s in color when color output is enabled, and returns s
+// unchanged otherwise. Color is only used for text output to a terminal:
+// JSON mode, --no-color and a non-empty NO_COLOR environment variable
+// (https://no-color.org) all disable it.
+func (h *Handler) Colorize(color Color, s string) string {
+	if !h.colorEnabled() {
+		return s
+	}
+	return string(color) + s + colorReset
+}
+
+// colorEnabled reports whether Colorize emits ANSI escape codes.
+func (h *Handler) colorEnabled() bool {
+	if h.json || h.noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if h.stdoutTTY != nil {
+		return h.stdoutTTY()
+	}
+	return h.isStdoutTerminal()
+}
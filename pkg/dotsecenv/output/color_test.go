@@ -0,0 +1,47 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+func newTerminalHandler(opts ...HandlerOption) *Handler {
+	var buf bytes.Buffer
+	h := NewHandler(&buf, &buf, opts...)
+	h.stdoutTTY = func() bool { return true }
+	return h
+}
+
+func TestColorize(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+
+	tests := []struct {
+		name string
+		h    *Handler
+		want string
+	}{
+		{"terminal", newTerminalHandler(), "\033[32mok\033[0m"},
+		{"no-color flag", newTerminalHandler(WithNoColor(true)), "ok"},
+		{"json mode", newTerminalHandler(WithJSON(true)), "ok"},
+		{"no-color mode on clone", newTerminalHandler().WithNoColorMode(true), "ok"},
+		{"not a terminal", NewHandler(&bytes.Buffer{}, &bytes.Buffer{}), "ok"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.h.Colorize(ColorGreen, "ok"); got != tt.want {
+				t.Errorf("Colorize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColorize_NoColorEnv(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	h := newTerminalHandler()
+	for _, c := range []Color{ColorGreen, ColorRed, ColorYellow} {
+		if got := h.Colorize(c, "status"); got != "status" {
+			t.Errorf("Colorize(%q) = %q, want no escape codes with NO_COLOR set", c, got)
+		}
+	}
+}
@@ -15,6 +15,7 @@ const (
 	ExitFingerprintRequired ExitCode = 7
 	ExitAccessDenied        ExitCode = 8
 	ExitAlgorithmNotAllowed ExitCode = 9
+	ExitVaultLocked         ExitCode = 10
 )
 
 // codeToExitCode maps structured codes to numeric exit codes.
@@ -36,7 +37,6 @@ var codeToExitCode = map[Code]ExitCode{
 	CodeVaultLoadError:   ExitVaultError,
 	CodeVaultSaveError:   ExitVaultError,
 	CodeVaultEmpty:       ExitVaultError,
-	CodeVaultLocked:      ExitVaultError,
 	CodeVaultReadOnly:    ExitVaultError,
 	CodeSecretNotFound:   ExitVaultError,
 	CodeSecretNoValues:   ExitVaultError,
@@ -68,6 +68,9 @@ var codeToExitCode = map[Code]ExitCode{
 	// Algorithm errors (exit code 9)
 	CodeAlgorithmNotAllowed: ExitAlgorithmNotAllowed,
 	CodeAlgorithmWeak:       ExitAlgorithmNotAllowed,
+
+	// Vault locked errors (exit code 10)
+	CodeVaultLocked: ExitVaultLocked,
 }
 
 // exitCodeToCode provides reverse mapping for compatibility helpers.
@@ -81,6 +84,7 @@ var exitCodeToCode = map[ExitCode]Code{
 	ExitFingerprintRequired: CodeFingerprintRequired,
 	ExitAccessDenied:        CodeAccessDenied,
 	ExitAlgorithmNotAllowed: CodeAlgorithmNotAllowed,
+	ExitVaultLocked:         CodeVaultLocked,
 }
 
 // GetExitCode returns the numeric exit code for a structured code.
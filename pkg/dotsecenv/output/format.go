@@ -0,0 +1,174 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the encoding of a command's structured output, chosen with the
+// global --output flag.
+type Format string
+
+// Output formats. Text is each command's own human-readable output; the
+// others encode the document a command prints with --json.
+const (
+	FormatText      Format = "text"
+	FormatJSON      Format = "json"
+	FormatYAML      Format = "yaml"
+	FormatPorcelain Format = "porcelain"
+)
+
+// Formats lists the supported formats, for help and errors.
+var Formats = []string{string(FormatText), string(FormatJSON), string(FormatYAML), string(FormatPorcelain)}
+
+// encoders render a JSON document in the other structured formats. JSON is
+// the source document, so struct tags and MarshalJSON methods shape every
+// format alike.
+var encoders = map[Format]func(doc []byte) ([]byte, error){
+	FormatYAML:      encodeYAML,
+	FormatPorcelain: encodePorcelain,
+}
+
+// ParseFormat parses an --output value. Empty means FormatText.
+func ParseFormat(s string) (Format, error) {
+	if s == "" {
+		return FormatText, nil
+	}
+	for _, f := range Formats {
+		if s == f {
+			return Format(s), nil
+		}
+	}
+	return "", fmt.Errorf("invalid format %q: must be one of %s", s, strings.Join(Formats, ", "))
+}
+
+// Structured reports whether f replaces a command's text output with an
+// encoded document.
+func (f Format) Structured() bool {
+	return f != "" && f != FormatText
+}
+
+// decodeDocument decodes a JSON document, keeping numbers exact.
+func decodeDocument(doc []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("failed to decode json: %w", err)
+	}
+	return v, nil
+}
+
+// encodeYAML renders a JSON document as YAML. Mapping keys come out sorted.
+func encodeYAML(doc []byte) ([]byte, error) {
+	v, err := decodeDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(yamlValue(v)); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// yamlValue converts the json.Number leaves of a decoded document to Go
+// numbers, which YAML prints unquoted.
+func yamlValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = yamlValue(e)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = yamlValue(e)
+		}
+		return v
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	default:
+		return v
+	}
+}
+
+// encodePorcelain renders a JSON document as one "path<TAB>value" line per
+// leaf, in a stable order for scripts. Paths join object keys and array
+// indices with dots; object keys are sorted. Strings are printed unquoted
+// with backslash, tab and newline escaped, null as an empty value, and
+// empty arrays and objects as [] and {}.
+func encodePorcelain(doc []byte) ([]byte, error) {
+	v, err := decodeDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	writePorcelain(&buf, "", v)
+	return buf.Bytes(), nil
+}
+
+var porcelainEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+func writePorcelain(buf *bytes.Buffer, path string, v interface{}) {
+	child := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			writePorcelainLine(buf, path, "{}")
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			writePorcelain(buf, child(k), v[k])
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			writePorcelainLine(buf, path, "[]")
+			return
+		}
+		for i, e := range v {
+			writePorcelain(buf, child(strconv.Itoa(i)), e)
+		}
+	case string:
+		writePorcelainLine(buf, path, porcelainEscaper.Replace(v))
+	case nil:
+		writePorcelainLine(buf, path, "")
+	default:
+		writePorcelainLine(buf, path, fmt.Sprint(v))
+	}
+}
+
+func writePorcelainLine(buf *bytes.Buffer, path, value string) {
+	buf.WriteString(path)
+	buf.WriteByte('\t')
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
@@ -0,0 +1,93 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+type formatDoc struct {
+	Name    string            `json:"name"`
+	Bits    int               `json:"algorithm_bits"`
+	Tags    []string          `json:"tags"`
+	Empty   []string          `json:"empty"`
+	Missing *string           `json:"missing"`
+	Labels  map[string]string `json:"labels"`
+}
+
+var testDoc = []formatDoc{{
+	Name:   "a\tb",
+	Bits:   4096,
+	Tags:   []string{"x", "y"},
+	Empty:  []string{},
+	Labels: map[string]string{"z": "1", "m": "multi\nline"},
+}}
+
+func TestParseFormat(t *testing.T) {
+	for _, s := range Formats {
+		if f, err := ParseFormat(s); err != nil || string(f) != s {
+			t.Errorf("ParseFormat(%q) = %q, %v", s, f, err)
+		}
+	}
+	if f, err := ParseFormat(""); err != nil || f != FormatText {
+		t.Errorf("ParseFormat(\"\") = %q, %v; want text", f, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil || !strings.Contains(err.Error(), "json, yaml") {
+		t.Errorf("expected an error listing the formats, got %v", err)
+	}
+}
+
+func TestFormatData_YAML(t *testing.T) {
+	h := NewHandler(&strings.Builder{}, &strings.Builder{}, WithFormat(FormatYAML))
+	data, err := h.FormatData(testDoc)
+	if err != nil {
+		t.Fatalf("FormatData failed: %v", err)
+	}
+	want := `- algorithm_bits: 4096
+  empty: []
+  labels:
+    m: |-
+      multi
+      line
+    z: "1"
+  missing: null
+  name: "a\tb"
+  tags:
+    - x
+    - "y"
+`
+	if string(data) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestFormatData_Porcelain(t *testing.T) {
+	h := NewHandler(&strings.Builder{}, &strings.Builder{}, WithFormat(FormatPorcelain))
+	data, err := h.FormatData(testDoc)
+	if err != nil {
+		t.Fatalf("FormatData failed: %v", err)
+	}
+	want := "0.algorithm_bits\t4096\n" +
+		"0.empty\t[]\n" +
+		"0.labels.m\tmulti\\nline\n" +
+		"0.labels.z\t1\n" +
+		"0.missing\t\n" +
+		"0.name\ta\\tb\n" +
+		"0.tags.0\tx\n" +
+		"0.tags.1\ty\n"
+	if string(data) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestEncodeJSON_DefaultsToJSON(t *testing.T) {
+	for _, format := range []Format{"", FormatText, FormatJSON} {
+		var stdout strings.Builder
+		h := NewHandler(&stdout, &strings.Builder{}, WithFormat(format))
+		if err := h.EncodeJSON(map[string]int{"n": 1}); err != nil {
+			t.Fatalf("EncodeJSON failed: %v", err)
+		}
+		if stdout.String() != "{\n  \"n\": 1\n}\n" {
+			t.Errorf("format %q: got %q", format, stdout.String())
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package output
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -18,8 +20,30 @@ type Handler struct {
 	silent   bool
 	json     bool
 	warnings []*Warning
+
+	// redactStdout guards WriteSecretValue: when set and stdout is not a
+	// terminal, values are only written after AllowSecretValues is called.
+	redactStdout      bool
+	allowSecretValues bool
+
+	// noColor disables Colorize; stdoutTTY overrides the terminal check
+	// for stdout in tests.
+	noColor   bool
+	stdoutTTY func() bool
+
+	// canonical makes EncodeJSON and FormatJSON emit MarshalCanonical
+	// output.
+	canonical bool
+
+	// format is the encoding FormatData and EncodeJSON give structured
+	// output; text and empty mean JSON.
+	format Format
 }
 
+// ErrSecretValueRedacted is returned by WriteSecretValue when the redaction
+// guard refuses to print a secret value.
+var ErrSecretValueRedacted = errors.New("refusing to print secret value to non-terminal stdout (--redact-stdout)")
+
 // HandlerOption configures a Handler.
 type HandlerOption func(*Handler)
 
@@ -37,6 +61,34 @@ func WithJSON(json bool) HandlerOption {
 	}
 }
 
+// WithRedactStdout enables the secret value guard for WriteSecretValue.
+func WithRedactStdout(redact bool) HandlerOption {
+	return func(h *Handler) {
+		h.redactStdout = redact
+	}
+}
+
+// WithNoColor disables colored output.
+func WithNoColor(noColor bool) HandlerOption {
+	return func(h *Handler) {
+		h.noColor = noColor
+	}
+}
+
+// WithCanonical sets canonical JSON output (sorted keys and arrays).
+func WithCanonical(canonical bool) HandlerOption {
+	return func(h *Handler) {
+		h.canonical = canonical
+	}
+}
+
+// WithFormat sets the encoding of structured output.
+func WithFormat(format Format) HandlerOption {
+	return func(h *Handler) {
+		h.format = format
+	}
+}
+
 // WithStdin sets the stdin reader.
 func WithStdin(stdin io.Reader) HandlerOption {
 	return func(h *Handler) {
@@ -125,6 +177,36 @@ func (h *Handler) WriteLine(message string) {
 	}
 }
 
+// WriteSecretValue writes a decrypted secret value, already formatted by the
+// caller, to stdout. It is the only code path that may print secret values.
+// In JSON mode the caller is expected to pass the encoded document.
+//
+// When the redaction guard is enabled and stdout is not a terminal, the value
+// is written only if AllowSecretValues was called; otherwise
+// ErrSecretValueRedacted is returned and nothing is written.
+func (h *Handler) WriteSecretValue(value []byte) error {
+	if h.redactStdout && !h.allowSecretValues && !h.isStdoutTerminal() {
+		return ErrSecretValueRedacted
+	}
+	_, err := h.stdout.Write(value)
+	return err
+}
+
+// AllowSecretValues marks this handler as explicitly permitted to print
+// secret values, bypassing the redaction guard. Only commands whose purpose
+// is to print a value (secret get) should call it.
+func (h *Handler) AllowSecretValues() {
+	h.allowSecretValues = true
+}
+
+// isStdoutTerminal returns true if stdout is connected to a terminal.
+func (h *Handler) isStdoutTerminal() bool {
+	if f, ok := h.stdout.(*os.File); ok {
+		return term.IsTerminal(int(f.Fd()))
+	}
+	return false
+}
+
 // WriteJSON writes the JSON envelope with collected warnings and optional error.
 // This is the primary output method for JSON mode.
 func (h *Handler) WriteJSON(data interface{}, err *Error) error {
@@ -136,6 +218,47 @@ func (h *Handler) WriteJSON(data interface{}, err *Error) error {
 	return env.WriteTo(h.stdout, true)
 }
 
+// FormatJSON returns v as indented JSON ending in a newline, in canonical
+// form when canonical output is enabled.
+func (h *Handler) FormatJSON(v interface{}) ([]byte, error) {
+	var data []byte
+	var err error
+	if h.canonical {
+		data, err = MarshalCanonical(v)
+	} else {
+		data, err = json.MarshalIndent(v, "", "  ")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// FormatData returns v encoded in the handler's format: YAML or porcelain
+// when one was set with WithFormat, otherwise JSON as FormatJSON returns it.
+func (h *Handler) FormatData(v interface{}) ([]byte, error) {
+	data, err := h.FormatJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	if encode, ok := encoders[h.format]; ok {
+		return encode(data)
+	}
+	return data, nil
+}
+
+// EncodeJSON writes v to stdout as FormatData formats it. It is used by
+// the commands' --json outputs, which are not wrapped in an envelope, so
+// that --output can choose their encoding.
+func (h *Handler) EncodeJSON(v interface{}) error {
+	data, err := h.FormatData(v)
+	if err != nil {
+		return err
+	}
+	_, err = h.stdout.Write(data)
+	return err
+}
+
 // WriteJSONError writes a JSON envelope containing only an error.
 func (h *Handler) WriteJSONError(err *Error) error {
 	return h.WriteJSON(nil, err)
@@ -199,6 +322,13 @@ func (h *Handler) Clone() *Handler {
 		silent:   h.silent,
 		json:     h.json,
 		warnings: make([]*Warning, 0),
+
+		redactStdout:      h.redactStdout,
+		allowSecretValues: h.allowSecretValues,
+		noColor:           h.noColor,
+		stdoutTTY:         h.stdoutTTY,
+		canonical:         h.canonical,
+		format:            h.format,
 	}
 }
 
@@ -212,5 +342,46 @@ func (h *Handler) WithJSONMode(enabled bool) *Handler {
 		silent:   h.silent,
 		json:     enabled,
 		warnings: make([]*Warning, 0),
+
+		redactStdout:      h.redactStdout,
+		allowSecretValues: h.allowSecretValues,
+		noColor:           h.noColor,
+		stdoutTTY:         h.stdoutTTY,
+		canonical:         h.canonical,
+		format:            h.format,
 	}
 }
+
+// WithRedactStdoutMode returns a new handler with the redaction guard set.
+// The new handler shares stdout/stderr but has fresh warning collection.
+func (h *Handler) WithRedactStdoutMode(enabled bool) *Handler {
+	c := h.Clone()
+	c.redactStdout = enabled
+	return c
+}
+
+// WithNoColorMode returns a new handler with colored output disabled or
+// re-enabled. The new handler shares stdout/stderr but has fresh warning
+// collection.
+func (h *Handler) WithNoColorMode(noColor bool) *Handler {
+	c := h.Clone()
+	c.noColor = noColor
+	return c
+}
+
+// WithFormatMode returns a new handler with the structured output format
+// set. The new handler shares stdout/stderr but has fresh warning
+// collection.
+func (h *Handler) WithFormatMode(format Format) *Handler {
+	c := h.Clone()
+	c.format = format
+	return c
+}
+
+// WithCanonicalMode returns a new handler with canonical JSON output set.
+// The new handler shares stdout/stderr but has fresh warning collection.
+func (h *Handler) WithCanonicalMode(canonical bool) *Handler {
+	c := h.Clone()
+	c.canonical = canonical
+	return c
+}
@@ -0,0 +1,72 @@
+package vault
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
+)
+
+// The canonical data of an entry is the string whose hash is stored in the
+// entry's "hash" field and signed by its "signed_by" identity. Tools that
+// verify vaults independently can hash these strings (see
+// identity.ComputeHash) and check the signatures without reimplementing
+// them. Times are formatted as RFC 3339 with nanoseconds, in the entry's
+// stored zone.
+
+// CanonicalIdentityData returns the canonical data of an identity:
+//
+//	identity:added_at:algorithm:algorithm_bits:curve:created_at:expires_at:fingerprint:public_key:signed_by:uid
+//
+// expires_at is empty for keys that do not expire. It is defined in package
+// identity, which cannot import this package; this is the same function.
+func CanonicalIdentityData(id *Identity) string {
+	return identity.IdentityCanonicalData(id)
+}
+
+// CanonicalSecretData returns the canonical data of a secret definition:
+//
+//	secret:added_at:key:signed_by
+func CanonicalSecretData(secret *Secret) string {
+	return fmt.Sprintf("secret:%s:%s:%s",
+		secret.AddedAt.Format(time.RFC3339Nano),
+		secret.Key,
+		secret.SignedBy)
+}
+
+// CanonicalValueData returns the canonical data of a value of secretKey:
+//
+//	value:added_at:secret_key:available_to:signed_by:value:deleted[:compressed][:source=SOURCE]
+//
+// available_to is joined with commas in stored order, value is the
+// base64-encoded ciphertext, never plaintext, and deleted is true or false.
+// The ":compressed" and ":source=" suffixes are present only when set, so
+// values that predate them hash as they always did.
+func CanonicalValueData(value *SecretValue, secretKey string) string {
+	data := fmt.Sprintf("value:%s:%s:%s:%s:%s:%t",
+		value.AddedAt.Format(time.RFC3339Nano),
+		secretKey,
+		strings.Join(value.AvailableTo, ","),
+		value.SignedBy,
+		value.Value,
+		value.Deleted)
+	if value.Compressed {
+		data += ":compressed"
+	}
+	if value.Source != "" {
+		data += ":source=" + value.Source
+	}
+	return data
+}
+
+// CanonicalRevocationData returns the canonical data of an identity
+// revocation:
+//
+//	revocation:added_at:fingerprint:signed_by
+func CanonicalRevocationData(r *Revocation) string {
+	return fmt.Sprintf("revocation:%s:%s:%s",
+		r.AddedAt.Format(time.RFC3339Nano),
+		r.Fingerprint,
+		r.SignedBy)
+}
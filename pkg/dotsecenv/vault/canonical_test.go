@@ -0,0 +1,97 @@
+package vault
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
+)
+
+// The canonical strings are what every stored hash covers. These tests pin
+// them byte for byte: a change here breaks verification of existing vaults.
+
+func TestCanonicalIdentityData(t *testing.T) {
+	added := time.Date(2024, time.January, 2, 3, 4, 5, 600, time.UTC)
+	created := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+	expires := time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)
+	id := Identity{
+		AddedAt:       added,
+		Algorithm:     "EdDSA",
+		AlgorithmBits: 255,
+		Curve:         "Ed25519",
+		CreatedAt:     created,
+		Fingerprint:   "ABCD1234",
+		PublicKey:     "cHVia2V5",
+		SignedBy:      "ABCD1234",
+		UID:           "Alice <alice@example.com>",
+	}
+
+	want := "identity:2024-01-02T03:04:05.0000006Z:EdDSA:255:Ed25519:2023-06-01T00:00:00Z::ABCD1234:cHVia2V5:ABCD1234:Alice <alice@example.com>"
+	if got := CanonicalIdentityData(&id); got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+
+	id.ExpiresAt = &expires
+	want = "identity:2024-01-02T03:04:05.0000006Z:EdDSA:255:Ed25519:2023-06-01T00:00:00Z:2026-06-01T00:00:00Z:ABCD1234:cHVia2V5:ABCD1234:Alice <alice@example.com>"
+	if got := CanonicalIdentityData(&id); got != want {
+		t.Errorf("with expiry: got  %q\nwant %q", got, want)
+	}
+}
+
+func TestCanonicalSecretData(t *testing.T) {
+	secret := Secret{
+		AddedAt:  time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC),
+		Key:      "myapp::DB_URL",
+		SignedBy: "ABCD1234",
+	}
+
+	want := "secret:2024-01-02T03:04:05Z:myapp::DB_URL:ABCD1234"
+	if got := CanonicalSecretData(&secret); got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestCanonicalValueData(t *testing.T) {
+	value := SecretValue{
+		AddedAt:     time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC),
+		AvailableTo: []string{"AAAA", "BBBB"},
+		SignedBy:    "AAAA",
+		Value:       "Y2lwaGVy",
+	}
+
+	tests := []struct {
+		name   string
+		modify func(v *SecretValue)
+		want   string
+	}{
+		{"plain", func(*SecretValue) {}, "value:2024-01-02T03:04:05Z:DB_URL:AAAA,BBBB:AAAA:Y2lwaGVy:false"},
+		{"deleted", func(v *SecretValue) { v.Deleted, v.AvailableTo, v.Value = true, nil, "" }, "value:2024-01-02T03:04:05Z:DB_URL::AAAA::true"},
+		{"compressed", func(v *SecretValue) { v.Compressed = true }, "value:2024-01-02T03:04:05Z:DB_URL:AAAA,BBBB:AAAA:Y2lwaGVy:false:compressed"},
+		{"source", func(v *SecretValue) { v.Source = "rotated-by-ci" }, "value:2024-01-02T03:04:05Z:DB_URL:AAAA,BBBB:AAAA:Y2lwaGVy:false:source=rotated-by-ci"},
+		{"compressed and source", func(v *SecretValue) { v.Compressed, v.Source = true, "ci" }, "value:2024-01-02T03:04:05Z:DB_URL:AAAA,BBBB:AAAA:Y2lwaGVy:false:compressed:source=ci"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := value
+			tt.modify(&v)
+			if got := CanonicalValueData(&v, "DB_URL"); got != tt.want {
+				t.Errorf("got  %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeHashes_UseCanonicalData(t *testing.T) {
+	secret := Secret{AddedAt: time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC), Key: "K", SignedBy: "AAAA"}
+	value := SecretValue{AddedAt: secret.AddedAt, AvailableTo: []string{"AAAA"}, SignedBy: "AAAA", Value: "eA=="}
+
+	// SHA-256 below 256 bits, SHA-512 from 256 up
+	for _, bits := range []int{255, 4096} {
+		if got, want := ComputeSecretHash(&secret, bits), identity.ComputeHash([]byte(CanonicalSecretData(&secret)), bits); got != want {
+			t.Errorf("%d bits: secret hash %s, want %s", bits, got, want)
+		}
+		if got, want := ComputeSecretValueHash(&value, "K", bits), identity.ComputeHash([]byte(CanonicalValueData(&value, "K")), bits); got != want {
+			t.Errorf("%d bits: value hash %s, want %s", bits, got, want)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package vault
 
 import (
 	"fmt"
-	"slices"
 )
 
 // CompactSecretStat describes the compaction effect on a single secret.
@@ -55,7 +54,7 @@ func PlanCompaction(v Vault) (Vault, *CompactStats) {
 	}
 
 	stats := &CompactStats{}
-	compacted := Vault{Identities: v.Identities}
+	compacted := Vault{Identities: v.Identities, Revocations: v.Revocations}
 
 	for i := range v.Secrets {
 		s := v.Secrets[i]
@@ -80,7 +79,7 @@ func PlanCompaction(v Vault) (Vault, *CompactStats) {
 				if s.Values[j].Deleted {
 					continue
 				}
-				if slices.Contains(s.Values[j].AvailableTo, fp) {
+				if s.Values[j].CanBeReadBy(fp) {
 					keep[j] = true
 					break
 				}
@@ -134,3 +133,26 @@ func Compact(w *Writer) (*CompactStats, error) {
 
 	return stats, nil
 }
+
+// TrimSecretHistory returns a copy of v in which the secret key keeps only its
+// newest keep values, and the number of values dropped. Like compaction it
+// never decrypts and keeps values verbatim, so signatures stay valid. A keep
+// of zero or less, or a key not in v, leaves the vault unchanged.
+func TrimSecretHistory(v Vault, key string, keep int) (Vault, int) {
+	if keep <= 0 {
+		return v, 0
+	}
+	trimmed := Vault{Identities: v.Identities, Secrets: make([]Secret, len(v.Secrets)), Revocations: v.Revocations}
+	copy(trimmed.Secrets, v.Secrets)
+
+	for i := range trimmed.Secrets {
+		s := &trimmed.Secrets[i]
+		if !CompareSecretKeys(s.Key, key) || len(s.Values) <= keep {
+			continue
+		}
+		dropped := len(s.Values) - keep
+		s.Values = append([]SecretValue(nil), s.Values[dropped:]...)
+		return trimmed, dropped
+	}
+	return v, 0
+}
@@ -235,3 +235,38 @@ func TestCompact_RoundTripPreservesValues(t *testing.T) {
 		t.Errorf("kept value not preserved verbatim: %+v", kept)
 	}
 }
+
+func TestTrimSecretHistory(t *testing.T) {
+	values := func(n int) []SecretValue {
+		var vs []SecretValue
+		for i := 0; i < n; i++ {
+			vs = append(vs, SecretValue{Value: string(rune('a' + i))})
+		}
+		return vs
+	}
+	v := Vault{Secrets: []Secret{
+		{Key: "OTHER", Values: values(4)},
+		{Key: "app::DB", Values: values(4)},
+	}}
+
+	trimmed, dropped := TrimSecretHistory(v, "APP::db", 2)
+	if dropped != 2 {
+		t.Fatalf("dropped = %d, want 2", dropped)
+	}
+	got := trimmed.Secrets[1].Values
+	if len(got) != 2 || got[0].Value != "c" || got[1].Value != "d" {
+		t.Errorf("expected the newest two values to be kept, got %+v", got)
+	}
+	if len(trimmed.Secrets[0].Values) != 4 {
+		t.Errorf("other secrets must be untouched, got %d values", len(trimmed.Secrets[0].Values))
+	}
+	if len(v.Secrets[1].Values) != 4 {
+		t.Errorf("input vault was modified")
+	}
+
+	for _, keep := range []int{0, 4, 10} {
+		if _, dropped := TrimSecretHistory(v, "app::DB", keep); dropped != 0 {
+			t.Errorf("keep %d: dropped = %d, want 0", keep, dropped)
+		}
+	}
+}
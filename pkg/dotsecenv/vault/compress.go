@@ -0,0 +1,154 @@
+package vault
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// CompressedExt is the file extension of gzip-compressed vaults. Vaults with
+// this extension are written compressed; any vault starting with the gzip
+// magic header is read compressed, regardless of its name.
+const CompressedExt = ".gz"
+
+// gzipMagic is the two-byte header every gzip stream starts with.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// isCompressedPath reports whether a vault at path should be written
+// gzip-compressed.
+func isCompressedPath(path string) bool {
+	return strings.HasSuffix(strings.ToLower(path), CompressedExt)
+}
+
+// vaultFile is a vault file opened for reading. Reads return the vault's
+// JSONL content, transparently decompressed when the file is gzip-compressed.
+type vaultFile struct {
+	io.Reader
+	file       *os.File
+	gz         *gzip.Reader
+	size       int64 // size of the file on disk
+	compressed bool
+	bom        int64 // length of the skipped UTF-8 BOM, 0 if there was none
+}
+
+// openVaultFile opens a vault file for reading. Errors from opening the file
+// are returned unwrapped so callers can check os.IsNotExist.
+func openVaultFile(path string) (*vaultFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to stat vault: %w", err)
+	}
+
+	f := &vaultFile{Reader: file, file: file, size: info.Size()}
+	if f.size == 0 {
+		return f, nil
+	}
+
+	br := bufio.NewReader(file)
+	f.Reader = br
+	if magic, _ := br.Peek(len(gzipMagic)); bytes.Equal(magic, gzipMagic) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to read compressed vault: %w", err)
+		}
+		f.Reader = gz
+		f.gz = gz
+		f.compressed = true
+	} else if prefix, _ := br.Peek(len(utf8BOM)); string(prefix) == utf8BOM {
+		// Editors may prefix the file with a BOM; readers never see it.
+		_, _ = br.Discard(len(utf8BOM))
+		f.bom = int64(len(utf8BOM))
+	}
+
+	return f, nil
+}
+
+// ReadFile returns the JSONL content of the vault file at path as the loader
+// sees it: decompressed when the file is gzip-compressed, and without a
+// leading UTF-8 BOM. Errors from opening the file are returned unwrapped.
+func ReadFile(path string) ([]byte, error) {
+	f, err := openVaultFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	content, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault: %w", err)
+	}
+	return content, nil
+}
+
+// SkipTo positions the reader at offset bytes into the decompressed content,
+// not counting a skipped BOM. It must be called before any other reads.
+func (f *vaultFile) SkipTo(offset int64) error {
+	if !f.compressed {
+		if _, err := f.file.Seek(f.bom+offset, io.SeekStart); err != nil {
+			return err
+		}
+		f.Reader = f.file
+		return nil
+	}
+	_, err := io.CopyN(io.Discard, f.Reader, offset)
+	return err
+}
+
+// Close closes the decompressor, if any, and the underlying file.
+func (f *vaultFile) Close() error {
+	if f.gz != nil {
+		_ = f.gz.Close()
+	}
+	return f.file.Close()
+}
+
+// CompressValue gzips plaintext before it is encrypted into a value stored
+// with Compressed set.
+func CompressValue(plaintext []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(plaintext); err != nil {
+		return nil, fmt.Errorf("failed to compress value: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress value: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ErrDecompressedTooLarge is returned by DecompressValue when a compressed
+// value inflates past the size limit it was given.
+var ErrDecompressedTooLarge = errors.New("decompressed value is too large")
+
+// DecompressValue returns the plaintext of value from its decrypted payload,
+// gunzipping the payload when value was stored compressed. At most limit
+// bytes are inflated, so a small payload cannot expand without bound.
+func DecompressValue(value *SecretValue, payload []byte, limit int64) ([]byte, error) {
+	if !value.Compressed {
+		return payload, nil
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress value: %w", err)
+	}
+	plaintext, err := io.ReadAll(io.LimitReader(gz, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress value: %w", err)
+	}
+	if int64(len(plaintext)) > limit {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrDecompressedTooLarge, limit)
+	}
+	return plaintext, nil
+}
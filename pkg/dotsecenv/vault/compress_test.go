@@ -0,0 +1,286 @@
+package vault
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
+)
+
+// compressTestVault returns a vault with long, compressible value lines.
+func compressTestVault() Vault {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	ciphertext := strings.Repeat("QUJDREVGR0hJSktMTU5PUFFSU1RVVldYWVo=", 40)
+	return Vault{
+		Identities: []identity.Identity{{
+			AddedAt:     now,
+			Fingerprint: "ABCD1234",
+			UID:         "Test <test@example.com>",
+			Algorithm:   "RSA",
+			PublicKey:   "cHVibGljLWtleQ==",
+		}},
+		Secrets: []Secret{
+			{Key: "DB_URL", AddedAt: now, SignedBy: "ABCD1234", Values: []SecretValue{
+				{AddedAt: now, AvailableTo: []string{"ABCD1234"}, SignedBy: "ABCD1234", Value: ciphertext},
+				{AddedAt: now.Add(time.Hour), AvailableTo: []string{"ABCD1234"}, SignedBy: "ABCD1234", Value: ciphertext + "AA"},
+			}},
+			{Key: "API_KEY", AddedAt: now, SignedBy: "ABCD1234", Values: []SecretValue{
+				{AddedAt: now, AvailableTo: []string{"ABCD1234"}, SignedBy: "ABCD1234", Value: ciphertext},
+			}},
+		},
+	}
+}
+
+func isGzipFile(t *testing.T, path string) bool {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return bytes.HasPrefix(data, gzipMagic)
+}
+
+func TestCompressedVault_RoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	plainPath := filepath.Join(tmpDir, "vault")
+	gzPath := filepath.Join(tmpDir, "vault.gz")
+	want := compressTestVault()
+
+	for _, path := range []string{plainPath, gzPath} {
+		w, err := NewWriter(path)
+		if err != nil {
+			t.Fatalf("NewWriter(%s) failed: %v", path, err)
+		}
+		if err := w.RewriteFromVault(want); err != nil {
+			t.Fatalf("RewriteFromVault(%s) failed: %v", path, err)
+		}
+	}
+
+	if isGzipFile(t, plainPath) {
+		t.Error("vault without .gz extension should be written uncompressed")
+	}
+	if !isGzipFile(t, gzPath) {
+		t.Fatal("vault with .gz extension should be written compressed")
+	}
+
+	plainInfo, _ := os.Stat(plainPath)
+	gzInfo, _ := os.Stat(gzPath)
+	if gzInfo.Size() >= plainInfo.Size() {
+		t.Errorf("compressed vault (%d bytes) is not smaller than plain (%d bytes)", gzInfo.Size(), plainInfo.Size())
+	}
+
+	// The decompressed content must match the plain vault byte for byte.
+	f, err := os.Open(gzPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var content bytes.Buffer
+	if _, err := content.ReadFrom(gz); err != nil {
+		t.Fatal(err)
+	}
+	plainContent, _ := os.ReadFile(plainPath)
+	if !bytes.Equal(content.Bytes(), plainContent) {
+		t.Error("decompressed vault differs from plain vault")
+	}
+
+	// Writer
+	w, err := NewWriter(gzPath)
+	if err != nil {
+		t.Fatalf("NewWriter on compressed vault failed: %v", err)
+	}
+	got, err := w.ReadVault()
+	if err != nil {
+		t.Fatalf("ReadVault failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("compressed vault did not read back identically:\ngot  %+v\nwant %+v", got, want)
+	}
+
+	// Reader, including random access to a later line
+	r, err := NewReader(gzPath)
+	if err != nil {
+		t.Fatalf("NewReader on compressed vault failed: %v", err)
+	}
+	values, err := r.GetSecretValues("API_KEY")
+	if err != nil {
+		t.Fatalf("GetSecretValues failed: %v", err)
+	}
+	if len(values) != 1 || values[0].Value != want.Secrets[1].Values[0].Value {
+		t.Errorf("unexpected values from compressed reader: %+v", values)
+	}
+
+	// Version detection and inspection
+	version, err := DetectVaultVersion(gzPath)
+	if err != nil || version != LatestFormatVersion {
+		t.Errorf("DetectVaultVersion = %d, %v; want %d", version, err, LatestFormatVersion)
+	}
+	info, err := InspectVault(gzPath)
+	if err != nil {
+		t.Fatalf("InspectVault failed: %v", err)
+	}
+	if info.IdentityCount != 1 || info.SecretCount != 2 {
+		t.Errorf("InspectVault counts = %d identities, %d secrets; want 1, 2", info.IdentityCount, info.SecretCount)
+	}
+}
+
+func TestCompressedVault_DetectedByMagic(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "vault")
+
+	w, err := NewWriter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.RewriteFromVault(compressTestVault()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Compress the vault in place, keeping its extension-less name.
+	plain, _ := os.ReadFile(path)
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, _ = gz.Write(plain)
+	_ = gz.Close()
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err = NewWriter(path)
+	if err != nil {
+		t.Fatalf("NewWriter on gzip content failed: %v", err)
+	}
+	if err := w.AddIdentity(identity.Identity{
+		AddedAt:     time.Now().UTC(),
+		Fingerprint: "EFGH5678",
+		Algorithm:   "RSA",
+		PublicKey:   "b3RoZXI=",
+	}); err != nil {
+		t.Fatalf("AddIdentity failed: %v", err)
+	}
+
+	if !isGzipFile(t, path) {
+		t.Error("vault read compressed should stay compressed after a write")
+	}
+	got, err := NewWriter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := got.ReadVault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v.Identities) != 2 || len(v.Secrets) != 2 {
+		t.Errorf("expected 2 identities and 2 secrets, got %d and %d", len(v.Identities), len(v.Secrets))
+	}
+}
+
+func TestCompressedVault_NewVault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new.gz")
+
+	m := NewManager(path, false)
+	if err := m.OpenAndLock(); err != nil {
+		t.Fatalf("OpenAndLock failed: %v", err)
+	}
+	defer func() { _ = m.Unlock() }()
+
+	if !isGzipFile(t, path) {
+		t.Error("new vault with .gz extension should be created compressed")
+	}
+	if version, err := DetectVaultVersion(path); err != nil || version != LatestFormatVersion {
+		t.Errorf("DetectVaultVersion = %d, %v; want %d", version, err, LatestFormatVersion)
+	}
+}
+
+func TestCompressValue_RoundTrip(t *testing.T) {
+	plaintext := []byte(strings.Repeat("verbose secret text\n", 100))
+	payload, err := CompressValue(plaintext)
+	if err != nil {
+		t.Fatalf("CompressValue failed: %v", err)
+	}
+	if len(payload) >= len(plaintext) {
+		t.Errorf("expected compression, got %d bytes for %d", len(payload), len(plaintext))
+	}
+
+	got, err := DecompressValue(&SecretValue{Compressed: true}, payload, int64(len(plaintext)))
+	if err != nil {
+		t.Fatalf("DecompressValue failed: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Error("round trip mismatch")
+	}
+
+	// Values not marked compressed are returned as is
+	got, err = DecompressValue(&SecretValue{}, payload, 0)
+	if err != nil || !bytes.Equal(got, payload) {
+		t.Errorf("expected an uncompressed value unchanged, got %v", err)
+	}
+}
+
+func TestDecompressValue_Limit(t *testing.T) {
+	plaintext := bytes.Repeat([]byte{0}, 1<<20)
+	payload, err := CompressValue(plaintext)
+	if err != nil {
+		t.Fatalf("CompressValue failed: %v", err)
+	}
+
+	if _, err := DecompressValue(&SecretValue{Compressed: true}, payload, int64(len(plaintext))); err != nil {
+		t.Errorf("expected a value at the limit to decompress, got %v", err)
+	}
+	_, err = DecompressValue(&SecretValue{Compressed: true}, payload, int64(len(plaintext))-1)
+	if !errors.Is(err, ErrDecompressedTooLarge) {
+		t.Errorf("expected ErrDecompressedTooLarge, got %v", err)
+	}
+}
+
+func TestWriter_CompressedValueBumpsVersion(t *testing.T) {
+	vaultPath := filepath.Join(t.TempDir(), "vault")
+	w, err := NewWriter(vaultPath)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	plain := SecretValue{AddedAt: now, AvailableTo: []string{"FP1"}, SignedBy: "FP1", Value: "cGxhaW4="}
+	if err := w.AddSecretWithValues(Secret{AddedAt: now, Key: "KEY", SignedBy: "FP1", Values: []SecretValue{plain}}); err != nil {
+		t.Fatalf("AddSecretWithValues failed: %v", err)
+	}
+	if w.Version() != LatestFormatVersion {
+		t.Fatalf("expected a plain value to keep version %d, got %d", LatestFormatVersion, w.Version())
+	}
+
+	compressed := SecretValue{AddedAt: now.Add(time.Second), AvailableTo: []string{"FP1"}, SignedBy: "FP1", Value: "Z3o=", Compressed: true}
+	if err := w.AddSecretValue("KEY", compressed); err != nil {
+		t.Fatalf("AddSecretValue failed: %v", err)
+	}
+
+	reopened, err := NewWriterReadOnly(vaultPath)
+	if err != nil {
+		t.Fatalf("NewWriterReadOnly failed: %v", err)
+	}
+	if got := reopened.Version(); got != CompressedFormatVersion {
+		t.Errorf("expected format version %d, got %d", CompressedFormatVersion, got)
+	}
+	v, err := reopened.ReadVault()
+	if err != nil {
+		t.Fatalf("ReadVault failed: %v", err)
+	}
+	values := v.GetSecretByKey("KEY").Values
+	if len(values) != 2 || values[0].Compressed || !values[1].Compressed {
+		t.Errorf("expected the compressed flag kept per value, got %+v", values)
+	}
+	if !strings.HasSuffix(CanonicalValueData(&values[1], "KEY"), ":compressed") {
+		t.Error("expected the compressed flag in the canonical data")
+	}
+}
@@ -176,26 +176,7 @@ func Defragment(w *Writer) (*FragmentationStats, error) {
 		return nil, fmt.Errorf("failed to read vault for defragmentation: %w", err)
 	}
 
-	// Sort identities by AddedAt
-	sort.Slice(vault.Identities, func(i, j int) bool {
-		return vault.Identities[i].AddedAt.Before(vault.Identities[j].AddedAt)
-	})
-
-	// Sort secrets by key
-	sort.Slice(vault.Secrets, func(i, j int) bool {
-		return vault.Secrets[i].Key < vault.Secrets[j].Key
-	})
-
-	// Sort values within each secret by AddedAt
-	for i := range vault.Secrets {
-		sort.Slice(vault.Secrets[i].Values, func(a, b int) bool {
-			return vault.Secrets[i].Values[a].AddedAt.Before(vault.Secrets[i].Values[b].AddedAt)
-		})
-		// Sort available_to fingerprints alphabetically
-		for j := range vault.Secrets[i].Values {
-			sort.Strings(vault.Secrets[i].Values[j].AvailableTo)
-		}
-	}
+	sortCanonical(&vault)
 
 	// Rewrite the vault
 	if err := w.RewriteFromVault(vault); err != nil {
@@ -211,6 +192,27 @@ func Defragment(w *Writer) (*FragmentationStats, error) {
 	return CalculateFragmentation(reader)
 }
 
+// sortCanonical puts v in the order Defragment writes: identities by
+// AddedAt, secrets by key, each secret's values by AddedAt, and each value's
+// available_to fingerprints alphabetically.
+func sortCanonical(v *Vault) {
+	sort.SliceStable(v.Identities, func(i, j int) bool {
+		return v.Identities[i].AddedAt.Before(v.Identities[j].AddedAt)
+	})
+	sort.Slice(v.Secrets, func(i, j int) bool {
+		return v.Secrets[i].Key < v.Secrets[j].Key
+	})
+	for i := range v.Secrets {
+		values := v.Secrets[i].Values
+		sort.SliceStable(values, func(a, b int) bool {
+			return values[a].AddedAt.Before(values[b].AddedAt)
+		})
+		for j := range values {
+			sort.Strings(values[j].AvailableTo)
+		}
+	}
+}
+
 // DefragmentIfNeeded checks fragmentation and defragments only if recommended
 func DefragmentIfNeeded(w *Writer) (*FragmentationStats, bool, error) {
 	reader, err := NewReader(w.Path())
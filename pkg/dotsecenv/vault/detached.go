@@ -0,0 +1,138 @@
+package vault
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// detachedValueExt is the file extension of detached value files.
+const detachedValueExt = ".enc"
+
+// DetachedValuesDir returns the directory holding the detached values of
+// the vault at vaultPath.
+func DetachedValuesDir(vaultPath string) string {
+	return vaultPath + ".values"
+}
+
+// DetachedValueRef returns the reference a detached value is stored under:
+// the SHA-256 of the encrypted value, in hex, with the .enc extension.
+func DetachedValueRef(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:]) + detachedValueExt
+}
+
+// Detach marks sv to be stored detached, under the reference of its
+// encrypted value. Call it once Value is final: a value re-encrypted from a
+// detached one is detached again under its new reference.
+func (sv *SecretValue) Detach() {
+	sv.ValueRef = DetachedValueRef(sv.Value)
+}
+
+// hasDetachedValues reports whether any value of v is stored detached.
+func hasDetachedValues(v Vault) bool {
+	for _, s := range v.Secrets {
+		for _, sv := range s.Values {
+			if sv.ValueRef != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// detachedValuePath checks that ref is a reference DetachedValueRef could
+// have made, so it cannot name a file outside the values directory, and
+// returns the path of its file.
+func detachedValuePath(vaultPath, ref string) (string, error) {
+	digest, ok := strings.CutSuffix(ref, detachedValueExt)
+	if !ok || len(digest) != sha256.Size*2 || strings.ToLower(digest) != digest {
+		return "", fmt.Errorf("invalid detached value reference: %q", ref)
+	}
+	if _, err := hex.DecodeString(digest); err != nil {
+		return "", fmt.Errorf("invalid detached value reference: %q", ref)
+	}
+	return filepath.Join(DetachedValuesDir(vaultPath), ref), nil
+}
+
+// writeDetachedValue writes the encrypted value of sv to the file its
+// ValueRef names. Files are named by their content, so one that already
+// exists holds the same value and is left alone.
+func writeDetachedValue(vaultPath string, sv SecretValue) error {
+	if sv.detachedErr != nil {
+		return sv.detachedErr
+	}
+	if sv.ValueRef != DetachedValueRef(sv.Value) {
+		return fmt.Errorf("detached value reference %s does not match the value", sv.ValueRef)
+	}
+	path, err := detachedValuePath(vaultPath, sv.ValueRef)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create detached values directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write detached value: %w", err)
+	}
+	tmpPath := tmp.Name()
+	_, writeErr := tmp.WriteString(sv.Value)
+	closeErr := tmp.Close()
+	if writeErr == nil {
+		writeErr = closeErr
+	}
+	if writeErr == nil {
+		writeErr = os.Rename(tmpPath, path)
+	}
+	if writeErr != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to write detached value: %w", writeErr)
+	}
+	return nil
+}
+
+// loadDetachedValue fills in the encrypted value of sv, when it is stored
+// detached, from the file its ValueRef names. The file's content must hash
+// to the reference; the value's own hash and signature then cover it as
+// they cover an inline value.
+//
+// A value that can't be filled in keeps the error, returned by DetachedErr,
+// so a modified or missing value file fails the reads and checks of that
+// value rather than the loading of the whole vault.
+func loadDetachedValue(vaultPath string, sv *SecretValue) error {
+	if sv.ValueRef == "" {
+		return nil
+	}
+	sv.detachedErr = readDetachedValue(vaultPath, sv)
+	return sv.detachedErr
+}
+
+func readDetachedValue(vaultPath string, sv *SecretValue) error {
+	path, err := detachedValuePath(vaultPath, sv.ValueRef)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read detached value: %w", err)
+	}
+	if DetachedValueRef(string(data)) != sv.ValueRef {
+		return fmt.Errorf("detached value %s does not match its reference (file modified)", sv.ValueRef)
+	}
+	sv.Value = string(data)
+	return nil
+}
+
+// DetachedErr returns why the detached value of sv could not be read when
+// its vault was loaded, or nil. Value is empty when it is set.
+func (sv *SecretValue) DetachedErr() error {
+	return sv.detachedErr
+}
@@ -0,0 +1,148 @@
+package vault
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newDetachedVault returns a vault at a temp path holding one secret whose
+// value is stored detached, along with that value.
+func newDetachedVault(t *testing.T) (string, SecretValue) {
+	t.Helper()
+	vaultPath := filepath.Join(t.TempDir(), "vault")
+	w, err := NewWriter(vaultPath)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	encrypted := strings.Repeat("QUJDRA==", 512)
+	sv := SecretValue{
+		AddedAt:     now,
+		AvailableTo: []string{"FP1"},
+		SignedBy:    "FP1",
+		Value:       encrypted,
+		ValueRef:    DetachedValueRef(encrypted),
+	}
+	sv.Hash = ComputeSecretValueHash(&sv, "BIG_SECRET", 4096)
+	if err := w.AddSecretWithValues(Secret{
+		AddedAt:  now,
+		Key:      "BIG_SECRET",
+		SignedBy: "FP1",
+		Values:   []SecretValue{sv},
+	}); err != nil {
+		t.Fatalf("AddSecretWithValues failed: %v", err)
+	}
+	return vaultPath, sv
+}
+
+func TestDetachedValue_RoundTrip(t *testing.T) {
+	vaultPath, sv := newDetachedVault(t)
+
+	data, err := os.ReadFile(vaultPath)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if strings.Contains(string(data), sv.Value) {
+		t.Error("expected the value to be stored outside the vault file")
+	}
+	if !strings.Contains(string(data), `"value_ref":"`+sv.ValueRef+`"`) {
+		t.Errorf("expected the vault to reference %s, got:\n%s", sv.ValueRef, data)
+	}
+	sidecar, err := os.ReadFile(filepath.Join(DetachedValuesDir(vaultPath), sv.ValueRef))
+	if err != nil {
+		t.Fatalf("expected a sidecar file: %v", err)
+	}
+	if string(sidecar) != sv.Value {
+		t.Error("sidecar content does not match the value")
+	}
+
+	reopened, err := NewWriterReadOnly(vaultPath)
+	if err != nil {
+		t.Fatalf("NewWriterReadOnly failed: %v", err)
+	}
+	if got := reopened.Version(); got != DetachedFormatVersion {
+		t.Errorf("expected format version %d, got %d", DetachedFormatVersion, got)
+	}
+	v, err := reopened.ReadVault()
+	if err != nil {
+		t.Fatalf("ReadVault failed: %v", err)
+	}
+	got := v.GetSecretByKey("BIG_SECRET")
+	if got == nil || len(got.Values) != 1 {
+		t.Fatalf("expected one value, got %+v", got)
+	}
+	if got.Values[0].Value != sv.Value {
+		t.Error("expected the detached value to be restored on read")
+	}
+	// The hash, and so the signature over it, covers the content
+	if hash := ComputeSecretValueHash(&got.Values[0], "BIG_SECRET", 4096); hash != sv.Hash {
+		t.Errorf("hash of the restored value changed: %s != %s", hash, sv.Hash)
+	}
+
+	reader, err := NewReader(vaultPath)
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	value, err := reader.GetSecretValue("BIG_SECRET")
+	if err != nil {
+		t.Fatalf("GetSecretValue failed: %v", err)
+	}
+	if value.Value != sv.Value {
+		t.Error("expected the reader to restore the detached value")
+	}
+}
+
+func TestDetachedValue_TamperedSidecar(t *testing.T) {
+	vaultPath, sv := newDetachedVault(t)
+
+	sidecar := filepath.Join(DetachedValuesDir(vaultPath), sv.ValueRef)
+	if err := os.WriteFile(sidecar, []byte("dGFtcGVyZWQ="), 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	w, err := NewWriterReadOnly(vaultPath)
+	if err != nil {
+		t.Fatalf("NewWriterReadOnly failed: %v", err)
+	}
+	// The vault still loads; the value carries the integrity error
+	v, err := w.ReadVault()
+	if err != nil {
+		t.Fatalf("expected the vault to load despite a tampered sidecar, got %v", err)
+	}
+	got := v.GetSecretByKey("BIG_SECRET").Values[0]
+	if err := got.DetachedErr(); err == nil || !strings.Contains(err.Error(), "does not match its reference") {
+		t.Errorf("expected a tampered sidecar to be reported, got %v", err)
+	}
+	if got.Value != "" {
+		t.Error("expected no value from a tampered sidecar")
+	}
+
+	reader, err := NewReader(vaultPath)
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	value, err := reader.GetSecretValue("BIG_SECRET")
+	if err != nil {
+		t.Fatalf("GetSecretValue failed: %v", err)
+	}
+	if value.DetachedErr() == nil {
+		t.Error("expected the reader to report the tampered sidecar")
+	}
+
+	// Rewriting the vault must not pass the missing value off as intact
+	if err := w.RewriteFromVault(v); err == nil || !strings.Contains(err.Error(), "does not match its reference") {
+		t.Errorf("expected rewriting a tampered value to fail, got %v", err)
+	}
+}
+
+func TestDetachedValue_RejectsInvalidRef(t *testing.T) {
+	for _, ref := range []string{"../vault.enc", "abc.enc", strings.Repeat("A", 64) + ".enc", strings.Repeat("a", 64)} {
+		if _, err := detachedValuePath("/tmp/vault", ref); err == nil {
+			t.Errorf("expected reference %q to be rejected", ref)
+		}
+	}
+}
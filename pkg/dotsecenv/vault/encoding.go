@@ -0,0 +1,39 @@
+package vault
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// EncodingIssues describes editor artifacts in a vault file: a UTF-8 BOM
+// and CRLF line endings, as left by saving the file on Windows. Readers
+// tolerate both, but the writer never produces them.
+type EncodingIssues struct {
+	BOM  bool
+	CRLF bool
+}
+
+// Any reports whether the file has any encoding issue.
+func (e EncodingIssues) Any() bool {
+	return e.BOM || e.CRLF
+}
+
+// DetectEncodingIssues reports the encoding issues of the vault file at
+// path. Compressed vaults are checked after decompression.
+func DetectEncodingIssues(path string) (EncodingIssues, error) {
+	file, err := openVaultFile(path)
+	if err != nil {
+		return EncodingIssues{}, WrapVaultError(path, fmt.Errorf("failed to open vault: %w", err))
+	}
+	defer func() { _ = file.Close() }()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return EncodingIssues{}, WrapVaultError(path, fmt.Errorf("failed to read vault: %w", err))
+	}
+	return EncodingIssues{
+		BOM:  file.bom > 0,
+		CRLF: bytes.Contains(content, []byte("\r\n")),
+	}, nil
+}
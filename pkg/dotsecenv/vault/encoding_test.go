@@ -0,0 +1,111 @@
+package vault
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeWindowsVault writes compressTestVault to path as an editor on Windows
+// would save it, with a UTF-8 BOM and CRLF line endings, and returns the
+// vault as the writer wrote it.
+func writeWindowsVault(t *testing.T, path string) []byte {
+	t.Helper()
+	w, err := NewWriter(path)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	if err := w.RewriteFromVault(compressTestVault()); err != nil {
+		t.Fatalf("RewriteFromVault failed: %v", err)
+	}
+	clean, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read vault: %v", err)
+	}
+	dirty := append([]byte(utf8BOM), bytes.ReplaceAll(clean, []byte("\n"), []byte("\r\n"))...)
+	if err := os.WriteFile(path, dirty, 0600); err != nil {
+		t.Fatalf("failed to write vault: %v", err)
+	}
+	return clean
+}
+
+func TestValidateHeaderMarker_BOMAndCRLF(t *testing.T) {
+	if err := ValidateHeaderMarker(utf8BOM + HeaderMarker + "\r"); err != nil {
+		t.Errorf("expected BOM and CR to be ignored, got %v", err)
+	}
+	if err := ValidateDataMarker(DataMarker + "\r"); err != nil {
+		t.Errorf("expected CR to be ignored, got %v", err)
+	}
+}
+
+func TestWindowsVault_Loads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault")
+	writeWindowsVault(t, path)
+	want := compressTestVault().Secrets[1].Values[0].Value
+
+	// The indexed reader seeks by byte offset, so it must count the BOM and
+	// the CR of each line.
+	r, err := NewReader(path)
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	value, err := r.GetSecretValue("API_KEY")
+	if err != nil || value.Value != want {
+		t.Errorf("NewReader: unexpected value %v, err %v", value, err)
+	}
+
+	hr, err := NewHeaderReader(path)
+	if err != nil {
+		t.Fatalf("NewHeaderReader failed: %v", err)
+	}
+	value, err = hr.GetSecretValue("API_KEY")
+	if err != nil || value.Value != want {
+		t.Errorf("NewHeaderReader: unexpected value %v, err %v", value, err)
+	}
+
+	w, err := NewWriterReadOnly(path)
+	if err != nil {
+		t.Fatalf("NewWriterReadOnly failed: %v", err)
+	}
+	v, err := w.ReadVault()
+	if err != nil {
+		t.Fatalf("ReadVault failed: %v", err)
+	}
+	if len(v.Secrets) != 2 || len(v.Identities) != 1 || v.Identities[0].Fingerprint != "ABCD1234" {
+		t.Errorf("unexpected vault: %+v", v)
+	}
+}
+
+func TestManager_NormalizeRemovesBOMAndCRLF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault")
+	clean := writeWindowsVault(t, path)
+
+	issues, err := DetectEncodingIssues(path)
+	if err != nil {
+		t.Fatalf("DetectEncodingIssues failed: %v", err)
+	}
+	if !issues.BOM || !issues.CRLF {
+		t.Fatalf("expected BOM and CRLF, got %+v", issues)
+	}
+
+	m := NewManager(path, true)
+	if err := m.OpenAndLock(); err != nil {
+		t.Fatalf("OpenAndLock failed: %v", err)
+	}
+	defer func() { _ = m.Unlock() }()
+	if err := m.Normalize(); err != nil {
+		t.Fatalf("Normalize failed: %v", err)
+	}
+
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read vault: %v", err)
+	}
+	if !bytes.Equal(after, clean) {
+		t.Errorf("expected the vault as the writer wrote it, got:\n%q", after)
+	}
+	if issues, err := DetectEncodingIssues(path); err != nil || issues.Any() {
+		t.Errorf("expected no issues after Normalize, got %+v, err %v", issues, err)
+	}
+}
@@ -3,6 +3,7 @@
 package vault
 
 import (
+	"errors"
 	"os"
 
 	"golang.org/x/sys/unix"
@@ -17,6 +18,20 @@ func lockFile(file *os.File, exclusive bool) error {
 	return unix.Flock(int(file.Fd()), lockType)
 }
 
+// tryLockFile attempts to lock the file without blocking. It reports false,
+// with a nil error, when the lock is held by another process.
+func tryLockFile(file *os.File, exclusive bool) (bool, error) {
+	lockType := unix.LOCK_SH
+	if exclusive {
+		lockType = unix.LOCK_EX
+	}
+	err := unix.Flock(int(file.Fd()), lockType|unix.LOCK_NB)
+	if errors.Is(err, unix.EWOULDBLOCK) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
 // unlockFile releases the lock on the file
 func unlockFile(file *os.File) error {
 	return unix.Flock(int(file.Fd()), unix.LOCK_UN)
@@ -3,6 +3,7 @@
 package vault
 
 import (
+	"errors"
 	"os"
 
 	"golang.org/x/sys/windows"
@@ -10,7 +11,8 @@ import (
 
 const (
 	// Windows LockFileEx flags
-	lockfileExclusiveLock = 0x00000002
+	lockfileFailImmediately = 0x00000001
+	lockfileExclusiveLock   = 0x00000002
 )
 
 // lockFile locks the file for exclusive or shared access
@@ -32,6 +34,29 @@ func lockFile(file *os.File, exclusive bool) error {
 	)
 }
 
+// tryLockFile attempts to lock the file without blocking. It reports false,
+// with a nil error, when the lock is held by another process.
+func tryLockFile(file *os.File, exclusive bool) (bool, error) {
+	flags := uint32(lockfileFailImmediately)
+	if exclusive {
+		flags |= lockfileExclusiveLock
+	}
+
+	ol := new(windows.Overlapped)
+	err := windows.LockFileEx(
+		windows.Handle(file.Fd()),
+		flags,
+		0,
+		1,
+		0,
+		ol,
+	)
+	if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
 // unlockFile releases the lock on the file
 func unlockFile(file *os.File) error {
 	ol := new(windows.Overlapped)
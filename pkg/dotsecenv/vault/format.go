@@ -27,6 +27,31 @@ const (
 	MinSupportedVersion = 1
 	// FormatVersion is kept for backward compatibility, use LatestFormatVersion instead
 	FormatVersion = LatestFormatVersion
+	// DetachedFormatVersion is the format version of vaults holding detached
+	// values. Its header has the v2 layout; the version marks value entries
+	// that may carry a value_ref instead of an inline value, which older
+	// releases would read as empty. Vaults move to it when the first
+	// detached value is written.
+	DetachedFormatVersion = 3
+	// CompressedFormatVersion is the format version of vaults holding
+	// compressed values. Its header has the v2 layout; the version marks
+	// value entries that may be gzipped before encryption, which older
+	// releases would return still compressed. Vaults move to it when the
+	// first compressed value is written.
+	CompressedFormatVersion = 4
+	// SourceFormatVersion is the format version of vaults holding values
+	// tagged with a source. Its header has the v2 layout; the version marks
+	// value entries whose signed data includes the source, which older
+	// releases would fail to verify. Vaults move to it when the first value
+	// with a source is written.
+	SourceFormatVersion = 5
+	// RevocationFormatVersion is the format version of vaults holding
+	// identity revocations. Its header has the v2 layout plus the lines of
+	// the revocation entries, which older releases would not know to skip.
+	// Vaults move to it when the first identity is revoked.
+	RevocationFormatVersion = 6
+	// MaxSupportedVersion is the newest vault format version that can be read
+	MaxSupportedVersion = RevocationFormatVersion
 )
 
 // Entry types for JSONL records
@@ -34,15 +59,24 @@ const (
 	EntryTypeIdentity = "identity"
 	EntryTypeSecret   = "secret"
 	EntryTypeValue    = "value"
+	// EntryTypeRevocation records the removal of an identity.
+	EntryTypeRevocation = "revocation"
 )
 
 // Header contains the vault index for efficient lookups.
 // It maps fingerprints to line numbers for identities,
 // and secret keys to their definition and value line numbers.
+//
+// Name is an optional human-readable label. The header is not signed, so the
+// name is informational only.
 type Header struct {
 	Version    int                    `json:"version"`
+	Name       string                 `json:"name,omitempty"`
 	Identities map[string]int         `json:"identities"` // fingerprint -> line number
 	Secrets    map[string]SecretIndex `json:"secrets"`    // key -> secret index
+
+	// Revocations lists the line numbers of revocation entries, in order.
+	Revocations []int `json:"revocations,omitempty"`
 }
 
 // SecretIndex tracks line numbers for a secret and its values
@@ -149,6 +183,8 @@ func MarshalHeaderVersioned(h *Header, version int) ([]byte, error) {
 		return MarshalHeaderV1(h)
 	case 2:
 		return MarshalHeaderV2(h)
+	case DetachedFormatVersion, CompressedFormatVersion, SourceFormatVersion, RevocationFormatVersion:
+		return marshalHeaderV2Layout(h, version)
 	default:
 		return nil, fmt.Errorf("unsupported vault format version: %d", version)
 	}
@@ -204,11 +240,21 @@ const legacyMarkerPrefix = "# === VAULT HEADER v"
 // legacyMarkerSuffix is the suffix for old versioned header markers.
 const legacyMarkerSuffix = " ==="
 
+// utf8BOM is the byte order mark some editors write at the start of a file.
+const utf8BOM = "\ufeff"
+
+// trimMarkerLine strips a leading UTF-8 BOM and a trailing CR, left by
+// editors that save with a BOM or CRLF line endings, from a marker line.
+func trimMarkerLine(line string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(line, utf8BOM), "\r")
+}
+
 // ValidateHeaderMarker checks if a header marker line is valid.
 // Accepts both the new versionless format and old versioned formats for backward compatibility.
+// A leading UTF-8 BOM and a trailing CR are ignored.
 // Returns nil if the marker is valid, or an error if invalid.
 func ValidateHeaderMarker(markerLine string) error {
-	markerType := DetectMarkerType(markerLine)
+	markerType := DetectMarkerType(trimMarkerLine(markerLine))
 	if markerType == MarkerHeader || markerType == MarkerHeaderLegacy {
 		return nil
 	}
@@ -218,7 +264,7 @@ func ValidateHeaderMarker(markerLine string) error {
 // ValidateDataMarker checks if a data marker line is valid.
 // Returns nil if the marker is valid, or an error if invalid.
 func ValidateDataMarker(markerLine string) error {
-	if DetectMarkerType(markerLine) == MarkerData {
+	if DetectMarkerType(trimMarkerLine(markerLine)) == MarkerData {
 		return nil
 	}
 	return fmt.Errorf("invalid vault data marker: %q", markerLine)
@@ -305,7 +351,7 @@ func UnmarshalHeaderVersioned(data []byte, version int) (*Header, error) {
 	switch version {
 	case 1:
 		return UnmarshalHeaderV1(data)
-	case 2:
+	case 2, DetachedFormatVersion, CompressedFormatVersion, SourceFormatVersion, RevocationFormatVersion:
 		return UnmarshalHeaderV2(data)
 	default:
 		return nil, fmt.Errorf("unsupported vault format version: %d", version)
@@ -385,6 +431,19 @@ func ParseSecretValue(e *Entry) (*SecretValue, error) {
 	if err := json.Unmarshal(e.Data, &data); err != nil {
 		return nil, fmt.Errorf("failed to parse secret value: %w", err)
 	}
+	data.indexReaders()
+	return &data, nil
+}
+
+// ParseRevocation extracts a Revocation from an Entry
+func ParseRevocation(e *Entry) (*Revocation, error) {
+	if e.Type != EntryTypeRevocation {
+		return nil, fmt.Errorf("entry is not a revocation (type=%s)", e.Type)
+	}
+	var data Revocation
+	if err := json.Unmarshal(e.Data, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse revocation: %w", err)
+	}
 	return &data, nil
 }
 
@@ -420,9 +479,13 @@ func CreateSecretEntry(s Secret) (*Entry, error) {
 	}, nil
 }
 
-// CreateValueEntry creates an Entry for a secret value
+// CreateValueEntry creates an Entry for a secret value. A detached value
+// keeps only its reference in the entry.
 func CreateValueEntry(secretKey string, sv SecretValue) (*Entry, error) {
 	data := sv
+	if data.ValueRef != "" {
+		data.Value = ""
+	}
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal value data: %w", err)
@@ -434,6 +497,18 @@ func CreateValueEntry(secretKey string, sv SecretValue) (*Entry, error) {
 	}, nil
 }
 
+// CreateRevocationEntry creates an Entry for an identity revocation
+func CreateRevocationEntry(r Revocation) (*Entry, error) {
+	jsonData, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal revocation data: %w", err)
+	}
+	return &Entry{
+		Type: EntryTypeRevocation,
+		Data: jsonData,
+	}, nil
+}
+
 // VaultInfo contains lightweight metadata about a vault file.
 // It can be obtained without fully parsing all vault entries.
 type VaultInfo struct {
@@ -447,7 +522,7 @@ type VaultInfo struct {
 // InspectVault returns lightweight metadata about a vault without fully loading it.
 // This is useful for quick vault inspection or validation.
 func InspectVault(path string) (*VaultInfo, error) {
-	file, err := os.Open(path)
+	file, err := openVaultFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return &VaultInfo{
@@ -463,11 +538,7 @@ func InspectVault(path string) (*VaultInfo, error) {
 	defer func() { _ = file.Close() }()
 
 	// Check if file is empty
-	info, err := file.Stat()
-	if err != nil {
-		return nil, WrapVaultError(path, fmt.Errorf("failed to stat: %w", err))
-	}
-	if info.Size() == 0 {
+	if file.size == 0 {
 		return &VaultInfo{
 			Path:          path,
 			Version:       LatestFormatVersion,
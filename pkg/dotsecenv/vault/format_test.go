@@ -571,6 +571,57 @@ func TestWriterDuplicateIdentity(t *testing.T) {
 	}
 }
 
+func TestWriterReplaceIdentity(t *testing.T) {
+	tmpDir := t.TempDir()
+	vaultPath := filepath.Join(tmpDir, "vault")
+
+	w, err := NewWriter(vaultPath)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	id := identity.Identity{
+		AddedAt:       now,
+		Algorithm:     "RSA",
+		AlgorithmBits: 4096,
+		Fingerprint:   "ABC123",
+		UID:           "old@example.com",
+	}
+	if err := w.AddIdentity(id); err != nil {
+		t.Fatalf("AddIdentity failed: %v", err)
+	}
+	lines := w.TotalLines()
+
+	refreshed := id
+	refreshed.AddedAt = now.Add(time.Second)
+	refreshed.UID = "new@example.com"
+	if err := w.ReplaceIdentity(refreshed); err != nil {
+		t.Fatalf("ReplaceIdentity failed: %v", err)
+	}
+
+	reopened, err := NewWriter(vaultPath)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	if got := reopened.TotalLines(); got != lines+1 {
+		t.Errorf("expected the old record kept and one line appended (%d lines), got %d", lines+1, got)
+	}
+	v, err := reopened.ReadVault()
+	if err != nil {
+		t.Fatalf("ReadVault failed: %v", err)
+	}
+	if len(v.Identities) != 1 || v.Identities[0].UID != "new@example.com" {
+		t.Errorf("expected only the refreshed identity, got %+v", v.Identities)
+	}
+
+	missing := id
+	missing.Fingerprint = "DEF456"
+	if err := w.ReplaceIdentity(missing); err == nil {
+		t.Error("expected error replacing an identity not in the vault")
+	}
+}
+
 func TestWriterDuplicateSecret(t *testing.T) {
 	tmpDir := t.TempDir()
 	vaultPath := filepath.Join(tmpDir, "vault")
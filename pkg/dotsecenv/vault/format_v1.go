@@ -10,6 +10,7 @@ import (
 // Identities are stored as an array of [fingerprint, line] pairs.
 type HeaderV1Raw struct {
 	Version    int                    `json:"version"`
+	Name       string                 `json:"name,omitempty"`
 	Identities [][2]interface{}       `json:"identities"` // [[fingerprint, line], ...]
 	Secrets    map[string]SecretIndex `json:"secrets"`
 }
@@ -37,6 +38,7 @@ func MarshalHeaderV1(h *Header) ([]byte, error) {
 
 	raw := HeaderV1Raw{
 		Version:    1,
+		Name:       h.Name,
 		Identities: identities,
 		Secrets:    h.Secrets,
 	}
@@ -54,6 +56,7 @@ func UnmarshalHeaderV1(data []byte) (*Header, error) {
 
 	h := &Header{
 		Version:    raw.Version,
+		Name:       raw.Name,
 		Identities: make(map[string]int, len(raw.Identities)),
 		Secrets:    raw.Secrets,
 	}
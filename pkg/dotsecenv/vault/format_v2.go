@@ -9,18 +9,32 @@ import (
 // Identities are stored as a dict {fingerprint: line, ...}.
 type HeaderV2Raw struct {
 	Version    int                    `json:"version"`
+	Name       string                 `json:"name,omitempty"`
 	Identities map[string]int         `json:"identities"` // {fingerprint: line, ...}
 	Secrets    map[string]SecretIndex `json:"secrets"`
+	// Revocations is only present from RevocationFormatVersion on.
+	Revocations []int `json:"revocations,omitempty"`
 }
 
 // MarshalHeaderV2 creates the JSON representation of the header in v2 format.
 // Identities are serialized as {fingerprint: line, ...} dict.
 func MarshalHeaderV2(h *Header) ([]byte, error) {
+	return marshalHeaderV2Layout(h, 2)
+}
+
+// marshalHeaderV2Layout serializes h in the v2 layout as version. Versions
+// 3 to 5 only add fields to value entries and share it; version 6 adds the
+// revocation lines.
+func marshalHeaderV2Layout(h *Header, version int) ([]byte, error) {
 	raw := HeaderV2Raw{
-		Version:    2,
+		Version:    version,
+		Name:       h.Name,
 		Identities: h.Identities,
 		Secrets:    h.Secrets,
 	}
+	if version >= RevocationFormatVersion {
+		raw.Revocations = h.Revocations
+	}
 
 	// Ensure non-nil maps for consistent JSON output
 	if raw.Identities == nil {
@@ -33,7 +47,8 @@ func MarshalHeaderV2(h *Header) ([]byte, error) {
 	return json.Marshal(raw)
 }
 
-// UnmarshalHeaderV2 parses v2 header JSON into a Header.
+// UnmarshalHeaderV2 parses v2 header JSON into a Header. It also parses v3
+// to v6 headers, which have the same layout.
 // Identities are already in map[string]int format.
 func UnmarshalHeaderV2(data []byte) (*Header, error) {
 	var raw HeaderV2Raw
@@ -42,9 +57,11 @@ func UnmarshalHeaderV2(data []byte) (*Header, error) {
 	}
 
 	h := &Header{
-		Version:    raw.Version,
-		Identities: raw.Identities,
-		Secrets:    raw.Secrets,
+		Version:     raw.Version,
+		Name:        raw.Name,
+		Identities:  raw.Identities,
+		Secrets:     raw.Secrets,
+		Revocations: raw.Revocations,
 	}
 
 	if h.Identities == nil {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -155,6 +156,73 @@ func TestManagerAddSecretValues(t *testing.T) {
 	}
 }
 
+func TestManagerDeferWrites(t *testing.T) {
+	vaultPath := filepath.Join(t.TempDir(), "vault")
+	m := NewManager(vaultPath, false)
+	if err := m.OpenAndLock(); err != nil {
+		t.Fatalf("OpenAndLock failed: %v", err)
+	}
+	before, err := os.Stat(vaultPath)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+
+	m.DeferWrites()
+	const puts = 50
+	for i := 0; i < puts; i++ {
+		m.AddSecret(Secret{
+			Key:    fmt.Sprintf("KEY_%d", i),
+			Values: []SecretValue{{AvailableTo: []string{"FP1"}, Value: fmt.Sprintf("value-%d", i)}},
+		})
+	}
+
+	// Every flush renames a new file over the vault, so an unchanged file
+	// means none of the staged puts was written yet.
+	if during, _ := os.Stat(vaultPath); !os.SameFile(before, during) {
+		t.Fatal("staged puts were written before Save")
+	}
+	if err := m.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if after, _ := os.Stat(vaultPath); os.SameFile(before, after) {
+		t.Fatal("Save did not write the staged puts")
+	}
+	if err := m.Unlock(); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+
+	m2 := NewManager(vaultPath, true)
+	if err := m2.OpenAndLock(); err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer func() { _ = m2.Unlock() }()
+	if got := len(m2.Get().Secrets); got != puts {
+		t.Errorf("reopened vault has %d secrets, want %d", got, puts)
+	}
+}
+
+func TestManagerDeferWrites_DroppedWithoutSave(t *testing.T) {
+	vaultPath := filepath.Join(t.TempDir(), "vault")
+	m := NewManager(vaultPath, false)
+	if err := m.OpenAndLock(); err != nil {
+		t.Fatalf("OpenAndLock failed: %v", err)
+	}
+	m.DeferWrites()
+	m.AddSecret(Secret{Key: "KEY", Values: []SecretValue{{AvailableTo: []string{"FP1"}, Value: "v"}}})
+	if err := m.Unlock(); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+
+	m2 := NewManager(vaultPath, true)
+	if err := m2.OpenAndLock(); err != nil {
+		t.Fatalf("reopen failed: %v", err)
+	}
+	defer func() { _ = m2.Unlock() }()
+	if got := len(m2.Get().Secrets); got != 0 {
+		t.Errorf("unsaved secret was written: vault has %d secrets", got)
+	}
+}
+
 func TestManagerGetAccessibleSecretValue(t *testing.T) {
 	tmpDir := t.TempDir()
 	vaultPath := filepath.Join(tmpDir, "vault")
@@ -622,6 +690,71 @@ func BenchmarkDefragment(b *testing.B) {
 	}
 }
 
+// largeTestVault writes a vault with secrets secrets of three values each
+// and returns its path.
+func largeTestVault(b *testing.B, secrets int) string {
+	b.Helper()
+	vaultPath := filepath.Join(b.TempDir(), "vault")
+	now := time.Now().UTC().Truncate(time.Second)
+	ciphertext := strings.Repeat("QUJDREVGR0hJSktMTU5PUFFSU1RVVldYWVo=", 20)
+
+	v := Vault{Identities: []identity.Identity{{AddedAt: now, Fingerprint: "FP1"}}}
+	for i := 0; i < secrets; i++ {
+		s := Secret{Key: fmt.Sprintf("SECRET_%05d", i), AddedAt: now, SignedBy: "FP1"}
+		for j := 0; j < 3; j++ {
+			s.Values = append(s.Values, SecretValue{AddedAt: now, AvailableTo: []string{"FP1"}, SignedBy: "FP1", Value: ciphertext})
+		}
+		v.Secrets = append(v.Secrets, s)
+	}
+
+	w, err := NewWriter(vaultPath)
+	if err != nil {
+		b.Fatalf("NewWriter failed: %v", err)
+	}
+	if err := w.RewriteFromVault(v); err != nil {
+		b.Fatalf("RewriteFromVault failed: %v", err)
+	}
+	return vaultPath
+}
+
+// BenchmarkHeaderReaderGetSecretValue measures a single-key read through the
+// header index; compare with BenchmarkReadVaultSingleKey.
+func BenchmarkHeaderReaderGetSecretValue(b *testing.B) {
+	vaultPath := largeTestVault(b, 1000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r, err := NewHeaderReader(vaultPath)
+		if err != nil {
+			b.Fatalf("NewHeaderReader failed: %v", err)
+		}
+		if _, err := r.GetSecretValue("SECRET_00500"); err != nil {
+			b.Fatalf("GetSecretValue failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkReadVaultSingleKey measures a single-key read that loads the whole
+// vault first.
+func BenchmarkReadVaultSingleKey(b *testing.B) {
+	vaultPath := largeTestVault(b, 1000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w, err := NewWriterReadOnly(vaultPath)
+		if err != nil {
+			b.Fatalf("NewWriterReadOnly failed: %v", err)
+		}
+		v, err := w.ReadVault()
+		if err != nil {
+			b.Fatalf("ReadVault failed: %v", err)
+		}
+		if v.GetSecretByKey("SECRET_00500") == nil {
+			b.Fatal("secret not found")
+		}
+	}
+}
+
 // Error handling tests
 
 func TestReaderInvalidHeader(t *testing.T) {
@@ -639,6 +772,66 @@ func TestReaderInvalidHeader(t *testing.T) {
 	}
 }
 
+func TestHeaderReaderGetSecretValue(t *testing.T) {
+	tmpDir := t.TempDir()
+	want := compressTestVault()
+
+	for _, name := range []string{"vault", "vault.gz"} {
+		vaultPath := filepath.Join(tmpDir, name)
+		w, err := NewWriter(vaultPath)
+		if err != nil {
+			t.Fatalf("NewWriter failed: %v", err)
+		}
+		if err := w.RewriteFromVault(want); err != nil {
+			t.Fatalf("RewriteFromVault failed: %v", err)
+		}
+
+		r, err := NewHeaderReader(vaultPath)
+		if err != nil {
+			t.Fatalf("%s: NewHeaderReader failed: %v", name, err)
+		}
+		got, err := r.GetSecretValue("db_url")
+		if err != nil {
+			t.Fatalf("%s: GetSecretValue failed: %v", name, err)
+		}
+		latest := want.Secrets[0].Values[1]
+		if got.Value != latest.Value || !got.AddedAt.Equal(latest.AddedAt) {
+			t.Errorf("%s: expected the latest value, got %+v", name, got)
+		}
+		if r.TotalLines() != 0 {
+			t.Errorf("%s: expected no line index, got %d lines", name, r.TotalLines())
+		}
+		if _, err := r.GetSecretValue("MISSING"); err == nil {
+			t.Errorf("%s: expected an error for a missing secret", name)
+		}
+	}
+}
+
+func TestHeaderReaderInvalidDataMarker(t *testing.T) {
+	vaultPath := filepath.Join(t.TempDir(), "vault")
+	w, err := NewWriter(vaultPath)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	if err := w.RewriteFromVault(compressTestVault()); err != nil {
+		t.Fatalf("RewriteFromVault failed: %v", err)
+	}
+
+	data, err := os.ReadFile(vaultPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitN(string(data), "\n", 4)
+	lines[2] = "# not the data marker"
+	if err := os.WriteFile(vaultPath, []byte(strings.Join(lines, "\n")), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewHeaderReader(vaultPath); err == nil {
+		t.Error("expected error for an invalid data marker")
+	}
+}
+
 func TestReaderMissingEntry(t *testing.T) {
 	tmpDir := t.TempDir()
 	vaultPath := filepath.Join(tmpDir, "vault")
@@ -0,0 +1,68 @@
+package vault
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Lock timeouts with a special meaning for Manager.SetLockTimeout.
+const (
+	// LockWaitForever blocks until the vault lock is acquired. It is the
+	// zero value, so managers block unless configured otherwise.
+	LockWaitForever time.Duration = 0
+
+	// LockNoWait fails immediately when the vault lock is held.
+	LockNoWait time.Duration = -1
+)
+
+// lockRetryInterval is how often a held lock is retried while waiting.
+const lockRetryInterval = 50 * time.Millisecond
+
+// LockTimeoutError is returned by OpenAndLock when the vault lock is held by
+// another process for longer than the lock timeout.
+type LockTimeoutError struct {
+	Path   string
+	Waited time.Duration
+}
+
+// Error implements the error interface.
+func (e *LockTimeoutError) Error() string {
+	return fmt.Sprintf("another dotsecenv process is writing to %s (held for at least %s)", e.Path, e.Waited.Round(time.Millisecond))
+}
+
+// SetLockTimeout sets how long OpenAndLock waits for a lock held by another
+// process: LockWaitForever blocks, LockNoWait fails immediately, and a
+// positive duration waits at most that long.
+func (m *Manager) SetLockTimeout(timeout time.Duration) {
+	m.lockTimeout = timeout
+}
+
+// acquireLock locks file according to the manager's lock timeout. A shared
+// lock is taken for read-only access, an exclusive one for read-write.
+func (m *Manager) acquireLock(file *os.File) error {
+	exclusive := !m.readOnly
+	if m.lockTimeout == LockWaitForever {
+		if err := lockFile(file, exclusive); err != nil {
+			return fmt.Errorf("failed to lock vault file: %w", err)
+		}
+		return nil
+	}
+
+	start := time.Now()
+	for {
+		acquired, err := tryLockFile(file, exclusive)
+		if err != nil {
+			return fmt.Errorf("failed to lock vault file: %w", err)
+		}
+		if acquired {
+			return nil
+		}
+
+		waited := time.Since(start)
+		if m.lockTimeout < 0 || waited >= m.lockTimeout {
+			return &LockTimeoutError{Path: m.path, Waited: waited}
+		}
+		time.Sleep(min(lockRetryInterval, m.lockTimeout-waited))
+	}
+}
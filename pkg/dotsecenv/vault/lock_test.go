@@ -0,0 +1,96 @@
+package vault
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
+)
+
+// newLockTestVault creates an initialized vault file. Locks are taken on the
+// open file, so tests must not race the writer replacing an empty file.
+func newLockTestVault(t *testing.T) string {
+	t.Helper()
+	vaultPath := filepath.Join(t.TempDir(), "vault")
+	m := NewManager(vaultPath, false)
+	if err := m.OpenAndLock(); err != nil {
+		t.Fatalf("OpenAndLock failed: %v", err)
+	}
+	m.AddIdentity(identity.Identity{AddedAt: time.Now().UTC(), Fingerprint: "FP1"})
+	if err := m.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := m.Unlock(); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	return vaultPath
+}
+
+func TestOpenAndLock_LockTimeout(t *testing.T) {
+	vaultPath := newLockTestVault(t)
+
+	holder := NewManager(vaultPath, false)
+	if err := holder.OpenAndLock(); err != nil {
+		t.Fatalf("OpenAndLock failed: %v", err)
+	}
+	defer func() { _ = holder.Unlock() }()
+
+	tests := []struct {
+		name    string
+		timeout time.Duration
+	}{
+		{"short timeout", 100 * time.Millisecond},
+		{"no wait", LockNoWait},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(vaultPath, false)
+			m.SetLockTimeout(tt.timeout)
+
+			start := time.Now()
+			err := m.OpenAndLock()
+			elapsed := time.Since(start)
+			if err == nil {
+				_ = m.Unlock()
+				t.Fatal("expected OpenAndLock to fail while the vault is locked")
+			}
+
+			var lockErr *LockTimeoutError
+			if !errors.As(err, &lockErr) {
+				t.Fatalf("expected LockTimeoutError, got %T: %v", err, err)
+			}
+			if lockErr.Path != vaultPath {
+				t.Errorf("Path = %q, want %q", lockErr.Path, vaultPath)
+			}
+			if !strings.Contains(err.Error(), "another dotsecenv process is writing to "+vaultPath) {
+				t.Errorf("unexpected message: %v", err)
+			}
+			if tt.timeout > 0 && elapsed < tt.timeout {
+				t.Errorf("gave up after %v, want at least %v", elapsed, tt.timeout)
+			}
+		})
+	}
+}
+
+func TestOpenAndLock_LockTimeoutAcquiresReleasedLock(t *testing.T) {
+	vaultPath := newLockTestVault(t)
+
+	holder := NewManager(vaultPath, false)
+	if err := holder.OpenAndLock(); err != nil {
+		t.Fatalf("OpenAndLock failed: %v", err)
+	}
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = holder.Unlock()
+	}()
+
+	m := NewManager(vaultPath, false)
+	m.SetLockTimeout(5 * time.Second)
+	if err := m.OpenAndLock(); err != nil {
+		t.Fatalf("expected the lock to be acquired once released, got %v", err)
+	}
+	_ = m.Unlock()
+}
@@ -0,0 +1,466 @@
+package vault
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// MemoryResolver is an in-memory stand-in for VaultResolver, for testing
+// code that embeds dotsecenv without writing vault files. It has the same
+// methods and lookup semantics as VaultResolver, but its vaults live only in
+// memory: opening, saving and closing do nothing, and GetVaultManager
+// returns nil since there is no file for a Manager to hold.
+//
+// Vault i is configured at the path "memory:<i+1>" unless the config is
+// replaced with SetConfig.
+type MemoryResolver struct {
+	vaults     []Vault
+	loadErrors map[int]error
+	config     VaultConfig
+	mu         sync.RWMutex
+}
+
+// NewMemoryResolver creates a MemoryResolver holding vaults, in order. The
+// vaults are copied, so later changes through the resolver leave the
+// arguments untouched.
+func NewMemoryResolver(vaults ...Vault) *MemoryResolver {
+	mr := &MemoryResolver{
+		vaults:     make([]Vault, len(vaults)),
+		loadErrors: make(map[int]error),
+	}
+	for i, v := range vaults {
+		mr.vaults[i] = cloneVault(v)
+		mr.config.Entries = append(mr.config.Entries, VaultEntry{Path: fmt.Sprintf("memory:%d", i+1)})
+	}
+	return mr
+}
+
+// cloneVault copies v deeply enough that appending to or replacing its
+// identities, secrets and secret values does not affect v.
+func cloneVault(v Vault) Vault {
+	clone := Vault{
+		Identities:  append([]Identity{}, v.Identities...),
+		Secrets:     make([]Secret, len(v.Secrets)),
+		Revocations: append([]Revocation{}, v.Revocations...),
+	}
+	for i, s := range v.Secrets {
+		s.Values = append([]SecretValue{}, s.Values...)
+		clone.Secrets[i] = s
+	}
+	return clone
+}
+
+// SetConfig replaces the vault configuration, e.g. to name vaults or set a
+// search order. config must have one entry per vault.
+func (mr *MemoryResolver) SetConfig(config VaultConfig) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if len(config.Entries) != len(mr.vaults) {
+		return fmt.Errorf("config has %d entries for %d vaults", len(config.Entries), len(mr.vaults))
+	}
+	mr.config = config
+	return nil
+}
+
+// SetLoadError makes the vault at index behave as if it failed to load with
+// err, as a missing or corrupt vault file does for VaultResolver.
+func (mr *MemoryResolver) SetLoadError(index int, err error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	mr.loadErrors[index] = err
+}
+
+// Vault returns a copy of the vault at index, e.g. to check what was added.
+func (mr *MemoryResolver) Vault(index int) (Vault, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	if index < 0 || index >= len(mr.vaults) {
+		return Vault{}, fmt.Errorf("vault index %d out of range", index)
+	}
+	return cloneVault(mr.vaults[index]), nil
+}
+
+// loaded returns the vault at index, or nil if it is out of range or failed
+// to load.
+func (mr *MemoryResolver) loaded(index int) *Vault {
+	if index < 0 || index >= len(mr.vaults) || mr.loadErrors[index] != nil {
+		return nil
+	}
+	return &mr.vaults[index]
+}
+
+// loadedOrError is loaded, with the error VaultResolver gives for a vault
+// that is not available.
+func (mr *MemoryResolver) loadedOrError(index int) (*Vault, error) {
+	if index < 0 || index >= len(mr.vaults) {
+		return nil, fmt.Errorf("vault index %d out of range", index)
+	}
+	if loadErr := mr.loadErrors[index]; loadErr != nil {
+		return nil, loadErr
+	}
+	return &mr.vaults[index], nil
+}
+
+// OpenVaults does nothing; the vaults are already in memory.
+func (mr *MemoryResolver) OpenVaults(stderr io.Writer) error {
+	if len(mr.vaults) == 0 {
+		return fmt.Errorf("no vaults configured")
+	}
+	return nil
+}
+
+// OpenVaultsFromPaths fails: a MemoryResolver has no files to open.
+func (mr *MemoryResolver) OpenVaultsFromPaths(paths []string, stderr io.Writer) error {
+	return fmt.Errorf("in-memory resolver cannot open vault files")
+}
+
+// GetLoadError returns the error set with SetLoadError for the vault at index
+func (mr *MemoryResolver) GetLoadError(index int) error {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+	return mr.loadErrors[index]
+}
+
+// GetSecret retrieves the latest value of a secret from a specific vault
+// index (0-based)
+func (mr *MemoryResolver) GetSecret(index int, key string) (*SecretValue, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	key = NormalizeKeyForLookup(key)
+
+	v, err := mr.loadedOrError(index)
+	if err != nil {
+		return nil, fmt.Errorf("Vault %d: %v", index+1, err)
+	}
+	secret := v.GetSecretByKey(key)
+	if secret == nil {
+		return nil, fmt.Errorf("secret '%s' not found in vault %d", key, index+1)
+	}
+	if len(secret.Values) == 0 {
+		return nil, fmt.Errorf("secret '%s' has no values in vault %d", key, index+1)
+	}
+	return &secret.Values[len(secret.Values)-1], nil
+}
+
+// GetSecretFromAnyVault retrieves the latest value of a secret from the
+// first vault holding it, in search order, whoever it is shared with.
+func (mr *MemoryResolver) GetSecretFromAnyVault(key string, stderr io.Writer) (*SecretValue, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	key = NormalizeKeyForLookup(key)
+
+	for _, i := range mr.config.SearchIndices() {
+		v := mr.loaded(i)
+		if v == nil {
+			continue
+		}
+		secret := v.GetSecretByKey(key)
+		if secret != nil && len(secret.Values) > 0 {
+			return &secret.Values[len(secret.Values)-1], nil
+		}
+	}
+	return nil, fmt.Errorf("secret '%s' not found in any vault", key)
+}
+
+// GetAccessibleSecretFromAnyVault retrieves the most recent value of a
+// secret that fingerprint can read, searching vaults in search order and
+// falling back to older values, as VaultResolver does.
+func (mr *MemoryResolver) GetAccessibleSecretFromAnyVault(key, fingerprint string) (*SecretValue, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	key = NormalizeKeyForLookup(key)
+
+	for _, i := range mr.config.SearchIndices() {
+		v := mr.loaded(i)
+		if v == nil {
+			continue
+		}
+		if val := v.GetAccessibleSecretValue(fingerprint, key); val != nil {
+			return val, nil
+		}
+	}
+	return nil, fmt.Errorf("secret '%s' not found or not accessible in any vault", key)
+}
+
+// AddIdentity adds an identity to all loaded vaults (or a specific vault if
+// index >= 0). An identity already present is updated in place.
+func (mr *MemoryResolver) AddIdentity(identity Identity, index int) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if index >= 0 {
+		v, err := mr.loadedOrError(index)
+		if err != nil {
+			return err
+		}
+		addIdentityInMemory(v, identity)
+		return nil
+	}
+	for i := range mr.vaults {
+		if v := mr.loaded(i); v != nil {
+			addIdentityInMemory(v, identity)
+		}
+	}
+	return nil
+}
+
+// addIdentityInMemory adds identity to v as Manager.AddIdentity does.
+func addIdentityInMemory(v *Vault, identity Identity) {
+	if existing := v.GetIdentityByFingerprint(identity.Fingerprint); existing != nil {
+		*existing = identity
+		return
+	}
+	v.Identities = append(v.Identities, identity)
+}
+
+// ReplaceIdentity records identity as the current version of an identity
+// already in the vault at index, moving it last as Manager.ReplaceIdentity
+// does.
+func (mr *MemoryResolver) ReplaceIdentity(identity Identity, index int) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	v := mr.loaded(index)
+	if v == nil {
+		return fmt.Errorf("vault index %d not available", index)
+	}
+	for i, existing := range v.Identities {
+		if existing.Fingerprint == identity.Fingerprint {
+			v.Identities = append(v.Identities[:i], v.Identities[i+1:]...)
+			break
+		}
+	}
+	v.Identities = append(v.Identities, identity)
+	return nil
+}
+
+// AddSecret adds a secret to a specific vault index (0-based), appending its
+// values to an existing secret with the same key.
+func (mr *MemoryResolver) AddSecret(secret Secret, index int) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	v, err := mr.loadedOrError(index)
+	if err != nil {
+		return err
+	}
+	if existing := v.GetSecretByKey(secret.Key); existing != nil {
+		existing.Values = append(existing.Values, secret.Values...)
+		return nil
+	}
+	secret.Values = append([]SecretValue{}, secret.Values...)
+	v.Secrets = append(v.Secrets, secret)
+	return nil
+}
+
+// GetIdentityByFingerprint finds an identity by fingerprint in any vault
+func (mr *MemoryResolver) GetIdentityByFingerprint(fingerprint string) *Identity {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	for i := range mr.vaults {
+		if v := mr.loaded(i); v != nil {
+			if identity := v.GetIdentityByFingerprint(fingerprint); identity != nil {
+				return identity
+			}
+		}
+	}
+	return nil
+}
+
+// DeferWrites does nothing; changes are never written anywhere.
+func (mr *MemoryResolver) DeferWrites() {}
+
+// SaveAll does nothing; changes are kept in memory only.
+func (mr *MemoryResolver) SaveAll() error {
+	return nil
+}
+
+// SaveVault fails for a vault that is not available, like VaultResolver,
+// and otherwise does nothing.
+func (mr *MemoryResolver) SaveVault(index int) error {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	_, err := mr.loadedOrError(index)
+	return err
+}
+
+// CloseAll does nothing; there are no files to close.
+func (mr *MemoryResolver) CloseAll() error {
+	return nil
+}
+
+// GetConfig returns the vault configuration
+func (mr *MemoryResolver) GetConfig() VaultConfig {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+	return mr.config
+}
+
+// SetSearchDepth limits searches across vaults to the first depth vaults in
+// search order. A depth of 0 or less searches all of them.
+func (mr *MemoryResolver) SetSearchDepth(depth int) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+	mr.config.SearchDepth = depth
+}
+
+// GetVaultManager returns nil: in-memory vaults have no Manager.
+func (mr *MemoryResolver) GetVaultManager(index int) *Manager {
+	return nil
+}
+
+// GetSecretByKeyFromVault gets a secret by key from a specific vault index
+func (mr *MemoryResolver) GetSecretByKeyFromVault(index int, key string) *Secret {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	v := mr.loaded(index)
+	if v == nil {
+		return nil
+	}
+	return v.GetSecretByKey(NormalizeKeyForLookup(key))
+}
+
+// GetVaultPaths returns all vault paths in configuration order
+func (mr *MemoryResolver) GetVaultPaths() []string {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	var paths []string
+	for _, entry := range mr.config.Entries {
+		paths = append(paths, entry.Path)
+	}
+	return paths
+}
+
+// GetAvailableVaultPathsWithIndices returns the paths of the vaults without a
+// load error, paired with their configuration indices.
+func (mr *MemoryResolver) GetAvailableVaultPathsWithIndices() []VaultPathWithIndex {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	var result []VaultPathWithIndex
+	for i, entry := range mr.config.Entries {
+		if mr.loaded(i) != nil {
+			result = append(result, VaultPathWithIndex{Path: entry.Path, Index: i})
+		}
+	}
+	return result
+}
+
+// VaultCount returns the number of vaults in the resolver.
+func (mr *MemoryResolver) VaultCount() int {
+	return len(mr.vaults)
+}
+
+// IsPathInConfig checks if a vault path is configured
+func (mr *MemoryResolver) IsPathInConfig(path string) bool {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	expanded := ExpandPath(path)
+	for _, entry := range mr.config.Entries {
+		if ExpandPath(entry.Path) == expanded {
+			return true
+		}
+	}
+	return false
+}
+
+// IdentityExistsInVault checks if an identity exists in a specific vault index
+func (mr *MemoryResolver) IdentityExistsInVault(fingerprint string, index int) bool {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	v := mr.loaded(index)
+	return v != nil && v.GetIdentityByFingerprint(fingerprint) != nil
+}
+
+// TrimSecretHistory drops all but the newest keep values of the secret key in
+// the vault at index and returns the number of values dropped.
+func (mr *MemoryResolver) TrimSecretHistory(index int, key string, keep int) (int, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	v := mr.loaded(index)
+	if v == nil {
+		return 0, fmt.Errorf("vault index %d not available", index)
+	}
+	trimmed, dropped := TrimSecretHistory(*v, key, keep)
+	*v = trimmed
+	return dropped, nil
+}
+
+// FindSecretVaultIndex finds the first vault index, in search order,
+// containing the secret key. Returns -1 if not found
+func (mr *MemoryResolver) FindSecretVaultIndex(key string) int {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	key = NormalizeKeyForLookup(key)
+
+	for _, i := range mr.config.SearchIndices() {
+		if v := mr.loaded(i); v != nil && v.GetSecretByKey(key) != nil {
+			return i
+		}
+	}
+	return -1
+}
+
+// ListAllSecretKeys returns all secret keys from all loaded vaults. A key
+// present in several vaults is reported from the first in search order.
+func (mr *MemoryResolver) ListAllSecretKeys() []SecretKeyInfo {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	var result []SecretKeyInfo
+	seen := make(map[string]bool)
+	for _, i := range mr.config.SearchIndices() {
+		v := mr.loaded(i)
+		if v == nil {
+			continue
+		}
+		for _, secret := range v.Secrets {
+			if seen[secret.Key] {
+				continue
+			}
+			seen[secret.Key] = true
+			result = append(result, SecretKeyInfo{
+				Key:      secret.Key,
+				Vault:    mr.config.Entries[i].Path,
+				VaultIdx: i + 1,
+				Deleted:  secret.IsDeleted(),
+			})
+		}
+	}
+	return result
+}
+
+// ListSecretKeysFromVault returns all secret keys from a specific vault
+func (mr *MemoryResolver) ListSecretKeysFromVault(index int) []SecretKeyInfo {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	v := mr.loaded(index)
+	if v == nil {
+		return nil
+	}
+	var result []SecretKeyInfo
+	for _, secret := range v.Secrets {
+		result = append(result, SecretKeyInfo{
+			Key:      secret.Key,
+			Vault:    mr.config.Entries[index].Path,
+			VaultIdx: index + 1,
+			Deleted:  secret.IsDeleted(),
+		})
+	}
+	return result
+}
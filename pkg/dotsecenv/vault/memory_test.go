@@ -0,0 +1,135 @@
+package vault
+
+import (
+	"errors"
+	"testing"
+)
+
+const (
+	memAlice = "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+	memBob   = "BBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBB"
+)
+
+// newSharedHistoryResolver returns a resolver whose first vault holds
+// DB_PASSWORD shared with Alice and Bob, then re-shared with Alice only,
+// and whose second vault holds a value of DB_PASSWORD shared with Bob.
+func newSharedHistoryResolver() *MemoryResolver {
+	return NewMemoryResolver(
+		Vault{Secrets: []Secret{{Key: "DB_PASSWORD", Values: []SecretValue{
+			{AvailableTo: []string{memAlice, memBob}, Value: "v1"},
+			{AvailableTo: []string{memAlice}, Value: "v2"},
+		}}}},
+		Vault{Secrets: []Secret{{Key: "DB_PASSWORD", Values: []SecretValue{
+			{AvailableTo: []string{memBob}, Value: "other-vault"},
+		}}}},
+	)
+}
+
+func TestMemoryResolver_AccessibleSecretFallsBackToOlderValues(t *testing.T) {
+	mr := newSharedHistoryResolver()
+
+	// Alice reads the latest value
+	val, err := mr.GetAccessibleSecretFromAnyVault("DB_PASSWORD", memAlice)
+	if err != nil || val.Value != "v2" {
+		t.Fatalf("expected v2 for Alice, got %v, %v", val, err)
+	}
+
+	// Bob is not in the latest value, so he falls back to the newest value
+	// of the first vault he can read, not to the second vault
+	val, err = mr.GetAccessibleSecretFromAnyVault("db_password", memBob)
+	if err != nil || val.Value != "v1" {
+		t.Fatalf("expected fallback to v1 for Bob, got %v, %v", val, err)
+	}
+
+	// Strict lookup returns the latest value whoever it is shared with
+	val, err = mr.GetSecretFromAnyVault("DB_PASSWORD", nil)
+	if err != nil || val.Value != "v2" {
+		t.Fatalf("expected latest value v2, got %v, %v", val, err)
+	}
+	if val.CanBeReadBy(memBob) {
+		t.Error("latest value should not be readable by Bob")
+	}
+
+	if _, err := mr.GetAccessibleSecretFromAnyVault("DB_PASSWORD", "CCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCCC"); err == nil {
+		t.Error("expected an error for an identity with no access")
+	}
+}
+
+func TestMemoryResolver_AccessibleSecretSearchOrder(t *testing.T) {
+	mr := newSharedHistoryResolver()
+
+	// Searching the second vault first finds Bob's value there
+	if err := mr.SetConfig(VaultConfig{
+		Entries:     []VaultEntry{{Path: "memory:1"}, {Path: "memory:2"}},
+		SearchOrder: []int{1, 0},
+	}); err != nil {
+		t.Fatalf("SetConfig failed: %v", err)
+	}
+	val, err := mr.GetAccessibleSecretFromAnyVault("DB_PASSWORD", memBob)
+	if err != nil || val.Value != "other-vault" {
+		t.Fatalf("expected the second vault's value, got %v, %v", val, err)
+	}
+	// Alice has nothing in the second vault and falls through to the first
+	val, err = mr.GetAccessibleSecretFromAnyVault("DB_PASSWORD", memAlice)
+	if err != nil || val.Value != "v2" {
+		t.Fatalf("expected v2 for Alice, got %v, %v", val, err)
+	}
+
+	// A vault that failed to load is skipped
+	mr.SetLoadError(1, errors.New("corrupt"))
+	val, err = mr.GetAccessibleSecretFromAnyVault("DB_PASSWORD", memBob)
+	if err != nil || val.Value != "v1" {
+		t.Fatalf("expected v1 with the second vault unavailable, got %v, %v", val, err)
+	}
+	if idx := mr.FindSecretVaultIndex("DB_PASSWORD"); idx != 0 {
+		t.Errorf("expected the secret in vault 0, got %d", idx)
+	}
+}
+
+func TestMemoryResolver_DeletedSecretIsNotAccessible(t *testing.T) {
+	mr := newSharedHistoryResolver()
+	if err := mr.AddSecret(Secret{Key: "DB_PASSWORD", Values: []SecretValue{
+		{AvailableTo: []string{memAlice, memBob}, Deleted: true},
+	}}, 0); err != nil {
+		t.Fatalf("AddSecret failed: %v", err)
+	}
+
+	// The deletion hides the first vault's values; Bob's value in the
+	// second vault is still found
+	if _, err := mr.GetAccessibleSecretFromAnyVault("DB_PASSWORD", memAlice); err == nil {
+		t.Error("expected a deleted secret to be inaccessible to Alice")
+	}
+	val, err := mr.GetAccessibleSecretFromAnyVault("DB_PASSWORD", memBob)
+	if err != nil || val.Value != "other-vault" {
+		t.Fatalf("expected the second vault's value, got %v, %v", val, err)
+	}
+}
+
+func TestMemoryResolver_ChangesStayInResolver(t *testing.T) {
+	original := Vault{Secrets: []Secret{{Key: "API_KEY", Values: []SecretValue{{Value: "old"}}}}}
+	mr := NewMemoryResolver(original)
+
+	if err := mr.AddSecret(Secret{Key: "API_KEY", Values: []SecretValue{{Value: "new"}}}, 0); err != nil {
+		t.Fatalf("AddSecret failed: %v", err)
+	}
+	if err := mr.AddIdentity(Identity{Fingerprint: memAlice}, -1); err != nil {
+		t.Fatalf("AddIdentity failed: %v", err)
+	}
+	if err := mr.AddSecret(Secret{Key: "API_KEY"}, 1); err == nil {
+		t.Error("expected an error for an out-of-range vault")
+	}
+
+	got, err := mr.Vault(0)
+	if err != nil {
+		t.Fatalf("Vault failed: %v", err)
+	}
+	if len(got.Secrets[0].Values) != 2 || !mr.IdentityExistsInVault(memAlice, 0) {
+		t.Errorf("unexpected vault after changes: %+v", got)
+	}
+	if len(original.Secrets[0].Values) != 1 {
+		t.Error("changes leaked into the vault passed to NewMemoryResolver")
+	}
+	if keys := mr.ListAllSecretKeys(); len(keys) != 1 || keys[0].Vault != "memory:1" || keys[0].VaultIdx != 1 {
+		t.Errorf("unexpected keys: %+v", keys)
+	}
+}
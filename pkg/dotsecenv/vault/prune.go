@@ -0,0 +1,82 @@
+package vault
+
+import (
+	"time"
+)
+
+// IdentityPruneStat describes one expired identity considered for pruning.
+type IdentityPruneStat struct {
+	// Fingerprint and UID identify the identity.
+	Fingerprint string
+	UID         string
+	// ExpiresAt is when the identity's key expired.
+	ExpiresAt time.Time
+	// Removed is true when the identity is dropped from the pruned vault.
+	Removed bool
+	// Reason explains why an expired identity is kept; empty when Removed.
+	Reason string
+}
+
+// PlanIdentityPrune computes the vault without its unused expired identities,
+// and a stat per identity whose key expired at or before now, in vault order.
+//
+// An expired identity is kept while the latest value of a secret that is not
+// deleted is readable by it, or while it signed any entry still in the
+// vault: another identity, a secret, any secret value or a revocation. Removing it then
+// would leave readers without a key or signatures without a signer. Nothing
+// is decrypted and kept entries are preserved verbatim, so signatures stay
+// valid after the rewrite.
+func PlanIdentityPrune(v Vault, now time.Time) (Vault, []IdentityPruneStat) {
+	reader := make(map[string]bool)
+	signer := make(map[string]string) // fingerprint -> first entry it signed
+	markSigner := func(fp, entry string) {
+		if _, seen := signer[fp]; fp != "" && !seen {
+			signer[fp] = entry
+		}
+	}
+
+	for _, id := range v.Identities {
+		// An identity signing its own entry goes away with it
+		if id.SignedBy != id.Fingerprint {
+			markSigner(id.SignedBy, "identity "+id.Fingerprint)
+		}
+	}
+	for _, r := range v.Revocations {
+		markSigner(r.SignedBy, "the revocation of "+r.Fingerprint)
+	}
+	for _, s := range v.Secrets {
+		markSigner(s.SignedBy, "secret "+s.Key)
+		for _, value := range s.Values {
+			markSigner(value.SignedBy, "a value of secret "+s.Key)
+		}
+		if !s.IsDeleted() && len(s.Values) > 0 {
+			for _, fp := range s.Values[len(s.Values)-1].AvailableTo {
+				reader[fp] = true
+			}
+		}
+	}
+
+	pruned := Vault{Secrets: v.Secrets, Revocations: v.Revocations}
+	var stats []IdentityPruneStat
+	for _, id := range v.Identities {
+		if id.ExpiresAt == nil || id.ExpiresAt.After(now) {
+			pruned.Identities = append(pruned.Identities, id)
+			continue
+		}
+
+		stat := IdentityPruneStat{Fingerprint: id.Fingerprint, UID: id.UID, ExpiresAt: *id.ExpiresAt}
+		switch entry, signed := signer[id.Fingerprint]; {
+		case reader[id.Fingerprint]:
+			stat.Reason = "can read the current value of a secret"
+		case signed:
+			stat.Reason = "signed " + entry
+		default:
+			stat.Removed = true
+		}
+		if !stat.Removed {
+			pruned.Identities = append(pruned.Identities, id)
+		}
+		stats = append(stats, stat)
+	}
+	return pruned, stats
+}
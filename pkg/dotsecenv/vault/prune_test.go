@@ -0,0 +1,209 @@
+package vault
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
+)
+
+// pruneStat looks up the stat for fingerprint, failing if there is none.
+func pruneStat(t *testing.T, stats []IdentityPruneStat, fingerprint string) IdentityPruneStat {
+	t.Helper()
+	for _, s := range stats {
+		if s.Fingerprint == fingerprint {
+			return s
+		}
+	}
+	t.Fatalf("no stat for identity %q", fingerprint)
+	return IdentityPruneStat{}
+}
+
+func TestPlanIdentityPrune(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	expired := now.Add(-24 * time.Hour)
+	future := now.Add(24 * time.Hour)
+
+	v := Vault{
+		Identities: []identity.Identity{
+			{Fingerprint: "OWNER", SignedBy: "OWNER"},
+			{Fingerprint: "UNUSED", ExpiresAt: &expired, SignedBy: "OWNER"},
+			{Fingerprint: "SELF", ExpiresAt: &expired, SignedBy: "SELF"},
+			{Fingerprint: "READER", ExpiresAt: &expired, SignedBy: "OWNER"},
+			{Fingerprint: "SIGNER", ExpiresAt: &expired, SignedBy: "OWNER"},
+			{Fingerprint: "OLDREADER", ExpiresAt: &expired, SignedBy: "OWNER"},
+			{Fingerprint: "VALID", ExpiresAt: &future, SignedBy: "OWNER"},
+		},
+		Secrets: []Secret{
+			{Key: "SEC", SignedBy: "OWNER", Values: []SecretValue{
+				{AvailableTo: []string{"OWNER", "OLDREADER"}, SignedBy: "OWNER"},
+				{AvailableTo: []string{"OWNER", "READER"}, SignedBy: "SIGNER"},
+			}},
+			{Key: "GONE", SignedBy: "OWNER", Values: []SecretValue{
+				{AvailableTo: []string{"UNUSED"}, SignedBy: "OWNER"},
+				{Deleted: true, SignedBy: "OWNER"},
+			}},
+		},
+	}
+
+	pruned, stats := PlanIdentityPrune(v, now)
+
+	if len(stats) != 5 {
+		t.Fatalf("expected 5 expired identities, got %+v", stats)
+	}
+	for _, fp := range []string{"UNUSED", "SELF", "OLDREADER"} {
+		if st := pruneStat(t, stats, fp); !st.Removed {
+			t.Errorf("%s should be pruned, kept because %s", fp, st.Reason)
+		}
+	}
+	if st := pruneStat(t, stats, "READER"); st.Removed || st.Reason != "can read the current value of a secret" {
+		t.Errorf("READER should be kept as a reader: %+v", st)
+	}
+	if st := pruneStat(t, stats, "SIGNER"); st.Removed || st.Reason != "signed a value of secret SEC" {
+		t.Errorf("SIGNER should be kept as a signer: %+v", st)
+	}
+
+	var kept []string
+	for _, id := range pruned.Identities {
+		kept = append(kept, id.Fingerprint)
+	}
+	want := []string{"OWNER", "READER", "SIGNER", "VALID"}
+	if len(kept) != len(want) {
+		t.Fatalf("kept identities = %v, want %v", kept, want)
+	}
+	for i := range want {
+		if kept[i] != want[i] {
+			t.Fatalf("kept identities = %v, want %v", kept, want)
+		}
+	}
+	if len(pruned.Secrets) != 2 {
+		t.Errorf("secrets must be untouched, got %d", len(pruned.Secrets))
+	}
+}
+
+func TestPlanIdentityPrune_NoExpiredIdentities(t *testing.T) {
+	now := time.Now()
+	future := now.Add(time.Hour)
+	v := Vault{Identities: []identity.Identity{{Fingerprint: "FP1"}, {Fingerprint: "FP2", ExpiresAt: &future}}}
+
+	pruned, stats := PlanIdentityPrune(v, now)
+	if len(stats) != 0 || len(pruned.Identities) != 2 {
+		t.Errorf("expected nothing to prune, got stats=%+v identities=%d", stats, len(pruned.Identities))
+	}
+}
+
+func TestPlanIdentityPrune_RoundTrip(t *testing.T) {
+	vaultPath := filepath.Join(t.TempDir(), "vault")
+	w, err := NewWriter(vaultPath)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	expired := now.Add(-time.Hour)
+	seed := Vault{
+		Identities: []identity.Identity{
+			{AddedAt: now, Fingerprint: "FP1", Hash: "h1", Signature: "s1", SignedBy: "FP1"},
+			{AddedAt: now, Fingerprint: "FP2", ExpiresAt: &expired, Hash: "h2", Signature: "s2", SignedBy: "FP1"},
+		},
+		Secrets: []Secret{{
+			AddedAt: now, Key: "SEC", Hash: "sh", Signature: "ss", SignedBy: "FP1",
+			Values: []SecretValue{{AddedAt: now, AvailableTo: []string{"FP1"}, Value: "v", Hash: "vh", Signature: "vs", SignedBy: "FP1"}},
+		}},
+	}
+	if err := w.RewriteFromVault(seed); err != nil {
+		t.Fatalf("seed RewriteFromVault failed: %v", err)
+	}
+
+	v, err := w.ReadVault()
+	if err != nil {
+		t.Fatalf("ReadVault failed: %v", err)
+	}
+	pruned, _ := PlanIdentityPrune(v, now)
+	if err := w.RewriteFromVault(pruned); err != nil {
+		t.Fatalf("RewriteFromVault failed: %v", err)
+	}
+
+	w2, err := NewWriter(vaultPath)
+	if err != nil {
+		t.Fatalf("re-open NewWriter failed: %v", err)
+	}
+	got, err := w2.ReadVault()
+	if err != nil {
+		t.Fatalf("ReadVault failed: %v", err)
+	}
+	if len(got.Identities) != 1 || got.Identities[0].Fingerprint != "FP1" || got.Identities[0].Signature != "s1" {
+		t.Errorf("expected only FP1 kept verbatim, got %+v", got.Identities)
+	}
+	if len(got.Secrets) != 1 || got.Secrets[0].Values[0].Signature != "vs" {
+		t.Errorf("secret not preserved verbatim: %+v", got.Secrets)
+	}
+}
+
+func TestWriterRevokeIdentity(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault")
+	w, err := NewWriter(path)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	base := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	for i, fp := range []string{"OWNER", "GONE"} {
+		if err := w.AddIdentity(identity.Identity{AddedAt: base.Add(time.Duration(i) * time.Second), Fingerprint: fp, SignedBy: "OWNER"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	linesBefore := w.TotalLines()
+
+	revocation := Revocation{AddedAt: base.Add(time.Minute), Fingerprint: "GONE", SignedBy: "OWNER"}
+	if err := w.RevokeIdentity(revocation); err != nil {
+		t.Fatalf("RevokeIdentity failed: %v", err)
+	}
+	if err := w.RevokeIdentity(revocation); err == nil {
+		t.Error("expected revoking an identity twice to fail")
+	}
+
+	// The revocation is appended; no line is removed
+	reopened, err := NewWriter(path)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	if reopened.TotalLines() != linesBefore+1 || reopened.Version() != RevocationFormatVersion {
+		t.Errorf("expected one appended line at v%d, got %d lines at v%d", RevocationFormatVersion, reopened.TotalLines(), reopened.Version())
+	}
+	v, err := reopened.ReadVault()
+	if err != nil {
+		t.Fatalf("ReadVault failed: %v", err)
+	}
+	if len(v.Identities) != 1 || v.Identities[0].Fingerprint != "OWNER" {
+		t.Errorf("expected only OWNER left, got %+v", v.Identities)
+	}
+	if len(v.Revocations) != 1 || v.Revocations[0].Fingerprint != "GONE" {
+		t.Errorf("expected the revocation to be read back, got %+v", v.Revocations)
+	}
+
+	// Reindexing agrees with the header and keeps the revocation
+	stats, err := Reindex(reopened, nil)
+	if err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	if len(stats.Drift) != 0 || stats.Identities != 1 {
+		t.Errorf("expected no drift and one identity, got %+v", stats)
+	}
+	v, _ = reopened.ReadVault()
+	if len(v.Revocations) != 1 {
+		t.Errorf("expected the revocation to survive a reindex, got %+v", v.Revocations)
+	}
+
+	// An identity added back after its revocation is current again
+	if err := reopened.AddIdentity(identity.Identity{AddedAt: base.Add(2 * time.Minute), Fingerprint: "GONE", SignedBy: "OWNER"}); err != nil {
+		t.Fatalf("AddIdentity after revocation failed: %v", err)
+	}
+	if _, err := Reindex(reopened, nil); err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	v, _ = reopened.ReadVault()
+	if v.GetIdentityByFingerprint("GONE") == nil || len(v.Revocations) != 1 {
+		t.Errorf("expected GONE back with its revocation kept, got %+v", v)
+	}
+}
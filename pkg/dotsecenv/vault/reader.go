@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
 )
 
@@ -14,6 +15,7 @@ type Reader struct {
 	header      *Header
 	version     int     // detected format version
 	lineOffsets []int64 // byte offsets for each line (0-indexed)
+	headerOnly  bool    // no line index; entries are read by scanning forward
 }
 
 // NewReader creates a new vault reader and parses the header
@@ -25,9 +27,99 @@ func NewReader(path string) (*Reader, error) {
 	return r, nil
 }
 
+// NewHeaderReader creates a vault reader that parses only the header and the
+// data marker, without indexing the rest of the file. Entries are read by
+// scanning forward to the line the header references, so a single-key read
+// neither parses nor keeps any other entry. TotalLines is 0 for such a
+// reader.
+func NewHeaderReader(path string) (*Reader, error) {
+	r := &Reader{path: path, headerOnly: true}
+	if err := r.loadHeaderOnly(); err != nil {
+		return nil, WrapVaultError(path, err)
+	}
+	return r, nil
+}
+
+// loadHeaderOnly reads the first three lines of the vault file: the header
+// marker, the header and the data marker.
+func (r *Reader) loadHeaderOnly() error {
+	file, err := openVaultFile(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			r.header = NewHeader()
+			r.version = LatestFormatVersion
+			return nil
+		}
+		return fmt.Errorf("failed to open vault: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	if file.size == 0 {
+		r.header = NewHeader()
+		r.version = LatestFormatVersion
+		return nil
+	}
+
+	br := bufio.NewReader(file)
+	var lines [3]string
+	for i := range lines {
+		line, err := readVaultLine(br)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read vault: %w", err)
+		}
+		lines[i] = line
+	}
+
+	if lines[0] == "" || lines[1] == "" {
+		return fmt.Errorf("vault file missing valid header")
+	}
+
+	header, version, err := parseVaultHeader(lines[0], lines[1])
+	if err != nil {
+		return err
+	}
+
+	if lines[2] != "" {
+		if err := ValidateDataMarker(lines[2]); err != nil {
+			return fmt.Errorf("invalid vault file: %w", err)
+		}
+	}
+
+	r.header = header
+	r.version = version
+	return nil
+}
+
+// readVaultLine reads one line of any length from br, without its line
+// ending. It returns io.EOF only when no bytes are left.
+func readVaultLine(br *bufio.Reader) (string, error) {
+	line, err := br.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), nil
+}
+
+// skipVaultLine discards one line from br without holding it in memory.
+func skipVaultLine(br *bufio.Reader) error {
+	for {
+		_, err := br.ReadSlice('\n')
+		if err != bufio.ErrBufferFull {
+			return err
+		}
+	}
+}
+
 // loadHeader reads the vault file header and builds line offset index
 func (r *Reader) loadHeader() error {
-	file, err := os.Open(r.path)
+	file, err := openVaultFile(r.path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// Empty vault
@@ -41,11 +133,7 @@ func (r *Reader) loadHeader() error {
 	defer func() { _ = file.Close() }()
 
 	// Check if file is empty
-	info, err := file.Stat()
-	if err != nil {
-		return fmt.Errorf("failed to stat vault: %w", err)
-	}
-	if info.Size() == 0 {
+	if file.size == 0 {
 		r.header = NewHeader()
 		r.version = LatestFormatVersion
 		r.lineOffsets = nil
@@ -55,6 +143,14 @@ func (r *Reader) loadHeader() error {
 	// Build line offset index and parse header
 	r.lineOffsets = make([]int64, 0, 100)
 	scanner := bufio.NewScanner(file)
+	// Offsets advance by the bytes each line takes on disk, which includes
+	// the CR of CRLF line endings that the scanner drops from the line.
+	var lineLen int
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		lineLen = advance
+		return advance, token, err
+	})
 	var offset int64
 	lineNum := 0
 	var markerLine string
@@ -74,8 +170,7 @@ func (r *Reader) loadHeader() error {
 			dataMarkerLine = line
 		}
 
-		// Account for newline character
-		offset += int64(len(scanner.Bytes())) + 1
+		offset += int64(lineLen)
 		lineNum++
 	}
 
@@ -121,9 +216,10 @@ func (r *Reader) Header() Header {
 	}
 	// Return a copy to prevent modification
 	h := Header{
-		Version:    r.header.Version,
-		Identities: make(map[string]int, len(r.header.Identities)),
-		Secrets:    make(map[string]SecretIndex, len(r.header.Secrets)),
+		Version:     r.header.Version,
+		Identities:  make(map[string]int, len(r.header.Identities)),
+		Secrets:     make(map[string]SecretIndex, len(r.header.Secrets)),
+		Revocations: slices.Clone(r.header.Revocations),
 	}
 	for k, v := range r.header.Identities {
 		h.Identities[k] = v
@@ -183,11 +279,14 @@ func (r *Reader) HasSecret(key string) bool {
 
 // readLine reads a specific line from the vault file (1-indexed)
 func (r *Reader) readLine(lineNum int) (string, error) {
+	if r.headerOnly {
+		return r.scanToLine(lineNum)
+	}
 	if lineNum < 1 || lineNum > len(r.lineOffsets) {
 		return "", fmt.Errorf("line %d out of range (1-%d)", lineNum, len(r.lineOffsets))
 	}
 
-	file, err := os.Open(r.path)
+	file, err := openVaultFile(r.path)
 	if err != nil {
 		return "", fmt.Errorf("failed to open vault: %w", err)
 	}
@@ -195,7 +294,7 @@ func (r *Reader) readLine(lineNum int) (string, error) {
 
 	// Seek to line offset (convert to 0-indexed)
 	offset := r.lineOffsets[lineNum-1]
-	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+	if err := file.SkipTo(offset); err != nil {
 		return "", fmt.Errorf("failed to seek to line %d: %w", lineNum, err)
 	}
 
@@ -210,6 +309,39 @@ func (r *Reader) readLine(lineNum int) (string, error) {
 	return scanner.Text(), nil
 }
 
+// scanToLine reads a specific line (1-indexed) by skipping the lines before
+// it, for readers without a line index.
+func (r *Reader) scanToLine(lineNum int) (string, error) {
+	if lineNum < 1 {
+		return "", fmt.Errorf("line %d out of range", lineNum)
+	}
+
+	file, err := openVaultFile(r.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open vault: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	br := bufio.NewReader(file)
+	for i := 1; i < lineNum; i++ {
+		if err := skipVaultLine(br); err != nil {
+			if err == io.EOF {
+				return "", fmt.Errorf("unexpected EOF at line %d", i)
+			}
+			return "", fmt.Errorf("failed to read line %d: %w", i, err)
+		}
+	}
+
+	line, err := readVaultLine(br)
+	if err == io.EOF {
+		return "", fmt.Errorf("unexpected EOF at line %d", lineNum)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read line %d: %w", lineNum, err)
+	}
+	return line, nil
+}
+
 // ReadEntry reads and parses an entry at a specific line (1-indexed)
 func (r *Reader) ReadEntry(lineNum int) (*Entry, error) {
 	line, err := r.readLine(lineNum)
@@ -285,12 +417,55 @@ func (r *Reader) GetSecretValues(key string) ([]SecretValue, error) {
 		if err != nil {
 			return nil, err
 		}
+		_ = loadDetachedValue(r.path, data) // kept on the value, see DetachedErr
 		values = append(values, *data)
 	}
 
 	return values, nil
 }
 
+// GetSecretValue retrieves the latest value of a secret, reading only the
+// line the header references for it. Keys are matched as by
+// CompareSecretKeys.
+func (r *Reader) GetSecretValue(key string) (*SecretValue, error) {
+	idx, found := r.secretIndex(key)
+	if !found {
+		return nil, fmt.Errorf("secret not found: %s", key)
+	}
+	if len(idx.Values) == 0 {
+		return nil, fmt.Errorf("secret %s has no values", key)
+	}
+
+	lineNum := idx.Values[len(idx.Values)-1]
+	entry, err := r.ReadEntry(lineNum)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read value at line %d: %w", lineNum, err)
+	}
+	value, err := ParseSecretValue(entry)
+	if err != nil {
+		return nil, err
+	}
+	_ = loadDetachedValue(r.path, value) // kept on the value, see DetachedErr
+	return value, nil
+}
+
+// secretIndex looks up the header index of a secret, trying the exact key
+// before a case-insensitive match.
+func (r *Reader) secretIndex(key string) (SecretIndex, bool) {
+	if r.header == nil {
+		return SecretIndex{}, false
+	}
+	if idx, ok := r.header.Secrets[key]; ok {
+		return idx, true
+	}
+	for k, idx := range r.header.Secrets {
+		if CompareSecretKeys(k, key) {
+			return idx, true
+		}
+	}
+	return SecretIndex{}, false
+}
+
 // GetAllIdentities retrieves all identities from the vault
 func (r *Reader) GetAllIdentities() ([]IdentityData, error) {
 	if r.header == nil || len(r.header.Identities) == 0 {
@@ -311,7 +486,7 @@ func (r *Reader) GetAllIdentities() ([]IdentityData, error) {
 
 // StreamEntries iterates through all entries in the vault, calling the handler for each
 func (r *Reader) StreamEntries(handler func(entry *Entry) error) error {
-	file, err := os.Open(r.path)
+	file, err := openVaultFile(r.path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil // Empty vault
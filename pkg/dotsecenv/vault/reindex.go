@@ -0,0 +1,259 @@
+package vault
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// ReindexStats describes what a reindex found and changed.
+type ReindexStats struct {
+	// Drift lists, in a stable order, each way the old header disagreed
+	// with the data lines.
+	Drift []string
+	// SupersededIdentities counts the older records of replaced identities,
+	// which are not carried over.
+	SupersededIdentities int
+	// Identities, Secrets and Values count the entries in the reindexed vault.
+	Identities, Secrets, Values int
+	// LinesBefore and LinesAfter are the line counts of the file.
+	LinesBefore, LinesAfter int
+	// VersionBefore and VersionAfter are the format versions of the file.
+	VersionBefore, VersionAfter int
+	// Changed reports whether the file differs after the reindex.
+	Changed bool
+}
+
+// scannedVault is a vault rebuilt from data lines, with the header those
+// lines call for.
+type scannedVault struct {
+	vault      Vault
+	header     *Header
+	superseded int
+}
+
+// scanDataLines rebuilds the vault from the data lines of w alone, ignoring
+// its header. When an identity has several records, as 'identity refresh'
+// leaves behind, the last one is kept, unless a revocation of it follows. A line that does not parse, a second
+// definition of a secret and a value of an undefined secret are errors.
+func (w *Writer) scanDataLines() (*scannedVault, error) {
+	sv := &scannedVault{header: NewHeader()}
+	secretAt := make(map[string]int) // header key -> index in vault.Secrets
+
+	findSecret := func(key string) (string, bool) {
+		if _, ok := secretAt[key]; ok {
+			return key, true
+		}
+		for existing := range secretAt {
+			if CompareSecretKeys(existing, key) {
+				return existing, true
+			}
+		}
+		return "", false
+	}
+
+	identityAt := make(map[string]int) // fingerprint -> index in vault.Identities
+	for i := 3; i < len(w.lines); i++ {
+		lineNum := i + 1
+		line := w.lines[i]
+		if line == "" {
+			continue
+		}
+		entry, err := UnmarshalEntry([]byte(line))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+
+		switch entry.Type {
+		case EntryTypeIdentity:
+			data, err := ParseIdentityData(entry)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			id := data.ToIdentity()
+			if at, ok := identityAt[id.Fingerprint]; ok {
+				sv.vault.Identities[at] = id
+				sv.superseded++
+			} else {
+				identityAt[id.Fingerprint] = len(sv.vault.Identities)
+				sv.vault.Identities = append(sv.vault.Identities, id)
+			}
+			sv.header.Identities[id.Fingerprint] = lineNum
+
+		case EntryTypeRevocation:
+			revocation, err := ParseRevocation(entry)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			if at, ok := identityAt[revocation.Fingerprint]; ok {
+				sv.vault.Identities = slices.Delete(sv.vault.Identities, at, at+1)
+				delete(identityAt, revocation.Fingerprint)
+				for fp, i := range identityAt {
+					if i > at {
+						identityAt[fp] = i - 1
+					}
+				}
+				delete(sv.header.Identities, revocation.Fingerprint)
+			}
+			sv.vault.Revocations = append(sv.vault.Revocations, *revocation)
+			sv.header.Revocations = append(sv.header.Revocations, lineNum)
+
+		case EntryTypeSecret:
+			data, err := ParseSecretData(entry)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			if existing, ok := findSecret(data.Key); ok {
+				return nil, fmt.Errorf("line %d: secret %s is already defined at line %d", lineNum, data.Key, sv.header.Secrets[existing].Definition)
+			}
+			secretAt[data.Key] = len(sv.vault.Secrets)
+			sv.vault.Secrets = append(sv.vault.Secrets, Secret{
+				AddedAt:   data.AddedAt,
+				Hash:      data.Hash,
+				Key:       data.Key,
+				Signature: data.Signature,
+				SignedBy:  data.SignedBy,
+				Values:    []SecretValue{},
+			})
+			sv.header.Secrets[data.Key] = SecretIndex{Definition: lineNum, Values: []int{}}
+
+		case EntryTypeValue:
+			key, ok := findSecret(entry.SecretKey)
+			if !ok {
+				return nil, fmt.Errorf("line %d: value of secret %s, which is not defined before it", lineNum, entry.SecretKey)
+			}
+			value, err := ParseSecretValue(entry)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			if err := loadDetachedValue(w.path, value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			secret := &sv.vault.Secrets[secretAt[key]]
+			secret.Values = append(secret.Values, *value)
+			idx := sv.header.Secrets[key]
+			idx.Values = append(idx.Values, lineNum)
+			sv.header.Secrets[key] = idx
+
+		default:
+			return nil, fmt.Errorf("line %d: unknown entry type %q", lineNum, entry.Type)
+		}
+	}
+	return sv, nil
+}
+
+// headerDrift lists, sorted, the ways header disagrees with the header the
+// data lines call for.
+func headerDrift(header, want *Header) []string {
+	var drift []string
+	for fp, line := range want.Identities {
+		got, ok := header.Identities[fp]
+		switch {
+		case !ok:
+			drift = append(drift, fmt.Sprintf("identity %s at line %d is missing from the header", fp, line))
+		case got != line:
+			drift = append(drift, fmt.Sprintf("identity %s: header points to line %d, current record is at line %d", fp, got, line))
+		}
+	}
+	for fp, line := range header.Identities {
+		if _, ok := want.Identities[fp]; !ok {
+			drift = append(drift, fmt.Sprintf("header lists identity %s at line %d, which holds no record of it", fp, line))
+		}
+	}
+	if !slices.Equal(header.Revocations, want.Revocations) {
+		drift = append(drift, fmt.Sprintf("header lists revocations at lines %v, revocations are at lines %v", header.Revocations, want.Revocations))
+	}
+	for key, idx := range want.Secrets {
+		got, ok := header.Secrets[key]
+		switch {
+		case !ok:
+			drift = append(drift, fmt.Sprintf("secret %s at line %d is missing from the header", key, idx.Definition))
+		case got.Definition != idx.Definition:
+			drift = append(drift, fmt.Sprintf("secret %s: header points to line %d, definition is at line %d", key, got.Definition, idx.Definition))
+		case !slices.Equal(got.Values, idx.Values):
+			drift = append(drift, fmt.Sprintf("secret %s: header lists values at lines %v, values are at lines %v", key, got.Values, idx.Values))
+		}
+	}
+	for key, idx := range header.Secrets {
+		if _, ok := want.Secrets[key]; !ok {
+			drift = append(drift, fmt.Sprintf("header lists secret %s at line %d, which holds no definition of it", key, idx.Definition))
+		}
+	}
+	sort.Strings(drift)
+	return drift
+}
+
+// Reindex rebuilds the vault of w from its data lines, ignoring the header,
+// and rewrites it in the canonical order Defragment uses, with a header that
+// matches. This recovers a vault whose header has drifted from its data.
+//
+// check is called with the rewritten vault, as read back through its new
+// header, before the file is replaced; when it returns an error the file is
+// left untouched. Nothing is written when the file would not change. Unless
+// the file is replaced, w is left as it was.
+func Reindex(w *Writer, check func(Vault) error) (*ReindexStats, error) {
+	scanned, err := w.scanDataLines()
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan vault: %w", err)
+	}
+
+	stats := &ReindexStats{
+		Drift:                headerDrift(w.header, scanned.header),
+		SupersededIdentities: scanned.superseded,
+		Identities:           len(scanned.vault.Identities),
+		Secrets:              len(scanned.vault.Secrets),
+		LinesBefore:          len(w.lines),
+		VersionBefore:        w.version,
+	}
+	for _, s := range scanned.vault.Secrets {
+		stats.Values += len(s.Values)
+	}
+
+	oldLines, oldHeader, oldVersion := slices.Clone(w.lines), w.header, w.version
+	restore := func() {
+		w.lines, w.header, w.version, w.dirty = oldLines, oldHeader, oldVersion, false
+	}
+	sortCanonical(&scanned.vault)
+
+	// Rewrite in memory only, so the result can be checked before the file
+	// is replaced.
+	deferred := w.deferred
+	w.deferred = true
+	defer func() { w.deferred = deferred }()
+	if err := w.RewriteFromVault(scanned.vault); err != nil {
+		restore()
+		return nil, fmt.Errorf("failed to rewrite vault: %w", err)
+	}
+	stats.LinesAfter = len(w.lines)
+	stats.VersionAfter = w.version
+	stats.Changed = len(stats.Drift) > 0 || stats.VersionBefore != stats.VersionAfter ||
+		!slices.Equal(oldLines[min(3, len(oldLines)):], w.lines[3:])
+	if !stats.Changed {
+		restore()
+		return stats, nil
+	}
+
+	reread, err := w.ReadVault()
+	if err != nil {
+		restore()
+		return nil, fmt.Errorf("rewritten vault does not read back: %w", err)
+	}
+	if check != nil {
+		if err := check(reread); err != nil {
+			restore()
+			return nil, err
+		}
+	}
+
+	tmpPath, err := w.stage()
+	if err != nil {
+		restore()
+		return nil, err
+	}
+	if err := w.commit(tmpPath); err != nil {
+		restore()
+		return nil, err
+	}
+	w.dirty = false
+	return stats, nil
+}
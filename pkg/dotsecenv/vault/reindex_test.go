@@ -0,0 +1,193 @@
+package vault
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
+)
+
+// writeDriftedVault writes a vault whose entries were appended interleaved,
+// so it is fragmented, and whose header then drifted: BETA's definition
+// points at ALPHA's and an identity is missing.
+func writeDriftedVault(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "vault")
+	w, err := NewWriter(path)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+
+	base := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	at := func(n int) time.Time { return base.Add(time.Duration(n) * time.Minute) }
+	steps := []func() error{
+		func() error {
+			return w.AddIdentity(identity.Identity{AddedAt: at(0), Fingerprint: "FP1", SignedBy: "FP1"})
+		},
+		func() error { return w.AddSecret(Secret{AddedAt: at(1), Key: "BETA", SignedBy: "FP1"}) },
+		func() error { return w.AddSecret(Secret{AddedAt: at(2), Key: "ALPHA", SignedBy: "FP1"}) },
+		func() error {
+			return w.AddSecretValue("BETA", SecretValue{AddedAt: at(3), AvailableTo: []string{"FP1"}, Value: "b1", SignedBy: "FP1"})
+		},
+		func() error {
+			return w.AddIdentity(identity.Identity{AddedAt: at(4), Fingerprint: "FP2", SignedBy: "FP1"})
+		},
+		func() error {
+			return w.AddSecretValue("ALPHA", SecretValue{AddedAt: at(5), AvailableTo: []string{"FP2", "FP1"}, Value: "a1", SignedBy: "FP1"})
+		},
+		func() error {
+			return w.AddSecretValue("BETA", SecretValue{AddedAt: at(6), AvailableTo: []string{"FP1"}, Value: "b2", SignedBy: "FP1"})
+		},
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("step %d failed: %v", i, err)
+		}
+	}
+
+	w.header.Secrets["BETA"] = SecretIndex{Definition: w.header.Secrets["ALPHA"].Definition, Values: w.header.Secrets["BETA"].Values}
+	delete(w.header.Identities, "FP2")
+	if err := w.flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	return path
+}
+
+func TestReindex_RecoversDriftedFragmentedVault(t *testing.T) {
+	path := writeDriftedVault(t)
+
+	w, err := NewWriter(path)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	var checked Vault
+	stats, err := Reindex(w, func(v Vault) error {
+		checked = v
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	if !stats.Changed || len(stats.Drift) != 2 {
+		t.Fatalf("expected two drift entries and a rewrite, got %+v", stats)
+	}
+	if stats.Identities != 2 || stats.Secrets != 2 || stats.Values != 3 {
+		t.Errorf("unexpected counts: %+v", stats)
+	}
+	if len(checked.Secrets) != 2 {
+		t.Errorf("check was not given the rewritten vault: %+v", checked)
+	}
+
+	reread, err := NewWriter(path)
+	if err != nil {
+		t.Fatalf("re-open failed: %v", err)
+	}
+	v, err := reread.ReadVault()
+	if err != nil {
+		t.Fatalf("ReadVault after reindex failed: %v", err)
+	}
+	if len(v.Identities) != 2 || v.Identities[1].Fingerprint != "FP2" {
+		t.Errorf("expected both identities, got %+v", v.Identities)
+	}
+	if len(v.Secrets) != 2 || v.Secrets[0].Key != "ALPHA" || v.Secrets[1].Key != "BETA" {
+		t.Fatalf("expected ALPHA then BETA, got %+v", v.Secrets)
+	}
+	if got := v.Secrets[0].Values[0].AvailableTo; got[0] != "FP1" || got[1] != "FP2" {
+		t.Errorf("expected available_to sorted, got %v", got)
+	}
+	if b := v.Secrets[1].Values; len(b) != 2 || b[0].Value != "b1" || b[1].Value != "b2" {
+		t.Errorf("expected BETA's values in order, got %+v", b)
+	}
+
+	// Each secret is now followed by its values
+	beta := reread.header.Secrets["BETA"]
+	for i, line := range beta.Values {
+		if line != beta.Definition+1+i {
+			t.Errorf("BETA values at lines %v, definition at %d: not consecutive", beta.Values, beta.Definition)
+		}
+	}
+
+	again, err := Reindex(reread, nil)
+	if err != nil {
+		t.Fatalf("second Reindex failed: %v", err)
+	}
+	if again.Changed || len(again.Drift) != 0 {
+		t.Errorf("expected a reindexed vault to be left alone, got %+v", again)
+	}
+}
+
+func TestReindex_CheckFailureLeavesVaultUntouched(t *testing.T) {
+	path := writeDriftedVault(t)
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewWriter(path)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	refused := errors.New("bad signatures")
+	if _, err := Reindex(w, func(Vault) error { return refused }); !errors.Is(err, refused) {
+		t.Fatalf("expected the check error, got %v", err)
+	}
+
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(before) != string(after) {
+		t.Error("vault file changed despite the failed check")
+	}
+	if _, ok := w.header.Secrets["BETA"]; !ok || w.header.Identities["FP2"] != 0 {
+		t.Error("expected the writer to be left with its original header")
+	}
+}
+
+func TestReindex_KeepsLatestIdentityRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault")
+	w, err := NewWriter(path)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	base := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	if err := w.AddIdentity(identity.Identity{AddedAt: base, Fingerprint: "FP1", UID: "old", SignedBy: "FP1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.ReplaceIdentity(identity.Identity{AddedAt: base.Add(time.Minute), Fingerprint: "FP1", UID: "new", SignedBy: "FP1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := Reindex(w, nil)
+	if err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+	if stats.SupersededIdentities != 1 || len(stats.Drift) != 0 || !stats.Changed {
+		t.Errorf("expected one superseded record and no drift, got %+v", stats)
+	}
+	v, err := w.ReadVault()
+	if err != nil {
+		t.Fatalf("ReadVault failed: %v", err)
+	}
+	if len(v.Identities) != 1 || v.Identities[0].UID != "new" || w.TotalLines() != 4 {
+		t.Errorf("expected only the latest record kept, got %+v (%d lines)", v.Identities, w.TotalLines())
+	}
+}
+
+func TestReindex_RejectsOrphanValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault")
+	w, err := NewWriter(path)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	entry, _ := CreateValueEntry("GHOST", SecretValue{AddedAt: time.Now().UTC(), Value: "x"})
+	line, _ := MarshalEntry(*entry)
+	w.lines = append(w.lines, string(line))
+
+	if _, err := Reindex(w, nil); err == nil {
+		t.Fatal("expected a value of an undefined secret to be refused")
+	}
+}
@@ -70,10 +70,22 @@ func (vr *VaultResolver) OpenVaults(stderr io.Writer) error {
 			continue
 		}
 
+		if vr.config.StructureCheck != nil {
+			if err := vr.config.StructureCheck(entry.Path); err != nil {
+				return fmt.Errorf("vault '%s': %w", entry.Path, err)
+			}
+		}
+
 		manager := NewManager(entry.Path, vr.config.RequireExplicitVaultUpgrade)
+		manager.SetLockTimeout(vr.config.LockTimeout)
 
 		// Try to open the vault
 		if err := manager.OpenAndLock(); err != nil {
+			// A vault held by another process is not skipped: continuing
+			// without it would hide its secrets from this command.
+			if _, ok := err.(*LockTimeoutError); ok {
+				return err
+			}
 			errmsg := fmt.Sprintf("vault '%s': %v", entry.Path, err)
 			vr.loadErrors[i] = err
 			errors = append(errors, errmsg)
@@ -110,10 +122,12 @@ func (vr *VaultResolver) OpenVaultsFromPaths(paths []string, stderr io.Writer) e
 		return fmt.Errorf("no vault paths specified")
 	}
 
-	// Update config (preserve RequireExplicitVaultUpgrade setting)
-	requireExplicitUpgrade := vr.config.RequireExplicitVaultUpgrade
+	// Update config (preserve RequireExplicitVaultUpgrade, LockTimeout and
+	// StructureCheck settings)
 	vr.config = VaultConfig{
-		RequireExplicitVaultUpgrade: requireExplicitUpgrade,
+		RequireExplicitVaultUpgrade: vr.config.RequireExplicitVaultUpgrade,
+		LockTimeout:                 vr.config.LockTimeout,
+		StructureCheck:              vr.config.StructureCheck,
 	}
 	for _, path := range paths {
 		vr.config.Entries = append(vr.config.Entries, VaultEntry{Path: ExpandPath(path)})
@@ -134,13 +148,22 @@ func (vr *VaultResolver) OpenVaultsFromPaths(paths []string, stderr io.Writer) e
 			}
 			return fmt.Errorf("cannot access vault file: %s: %v", entry.Path, err)
 		}
+		if vr.config.StructureCheck != nil {
+			if err := vr.config.StructureCheck(entry.Path); err != nil {
+				return fmt.Errorf("vault '%s': %w", entry.Path, err)
+			}
+		}
 
 		manager := NewManager(entry.Path, vr.config.RequireExplicitVaultUpgrade)
+		manager.SetLockTimeout(vr.config.LockTimeout)
 		if err := manager.OpenAndLock(); err != nil {
 			// Use errors.Is to detect wrapped permission errors
 			if errors.Is(err, fs.ErrPermission) {
 				return fmt.Errorf("vault file permission denied: %s\nCheck file permissions or run with appropriate privileges", entry.Path)
 			}
+			if _, ok := err.(*LockTimeoutError); ok {
+				return err
+			}
 			return fmt.Errorf("failed to open vault %s: %v", entry.Path, err)
 		}
 
@@ -189,7 +212,8 @@ func (vr *VaultResolver) GetSecret(index int, key string) (*SecretValue, error)
 	return &secret.Values[len(secret.Values)-1], nil
 }
 
-// GetSecretFromAnyVault retrieves a secret value from any vault, searching in order
+// GetSecretFromAnyVault retrieves a secret value from any vault, searching in
+// search order
 func (vr *VaultResolver) GetSecretFromAnyVault(key string, stderr io.Writer) (*SecretValue, error) {
 	vr.mu.RLock()
 	defer vr.mu.RUnlock()
@@ -197,7 +221,8 @@ func (vr *VaultResolver) GetSecretFromAnyVault(key string, stderr io.Writer) (*S
 	// Normalize key for lookup (graceful fallback for legacy keys)
 	key = NormalizeKeyForLookup(key)
 
-	for _, manager := range vr.vaults {
+	for _, i := range vr.config.SearchIndices() {
+		manager := vr.vaults[i]
 		if manager == nil {
 			continue
 		}
@@ -211,7 +236,7 @@ func (vr *VaultResolver) GetSecretFromAnyVault(key string, stderr io.Writer) (*S
 	return nil, fmt.Errorf("secret '%s' not found in any vault", key)
 }
 
-// GetAccessibleSecretFromAnyVault retrieves the most recent accessible secret value from any vault, searching in order.
+// GetAccessibleSecretFromAnyVault retrieves the most recent accessible secret value from any vault, searching in search order.
 // Returns the most recent value the identity has access to (falls back to older values if needed).
 func (vr *VaultResolver) GetAccessibleSecretFromAnyVault(key, fingerprint string) (*SecretValue, error) {
 	vr.mu.RLock()
@@ -220,7 +245,8 @@ func (vr *VaultResolver) GetAccessibleSecretFromAnyVault(key, fingerprint string
 	// Normalize key for lookup (graceful fallback for legacy keys)
 	key = NormalizeKeyForLookup(key)
 
-	for _, manager := range vr.vaults {
+	for _, i := range vr.config.SearchIndices() {
+		manager := vr.vaults[i]
 		if manager == nil {
 			continue
 		}
@@ -266,6 +292,18 @@ func (vr *VaultResolver) AddIdentity(identity Identity, index int) error {
 	return nil
 }
 
+// ReplaceIdentity records identity as the current version of an identity
+// already in the vault at index.
+func (vr *VaultResolver) ReplaceIdentity(identity Identity, index int) error {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+
+	if index < 0 || index >= len(vr.vaults) || vr.vaults[index] == nil {
+		return fmt.Errorf("vault index %d not available", index)
+	}
+	return vr.vaults[index].ReplaceIdentity(identity)
+}
+
 // AddSecret adds a secret to a specific vault index (0-based)
 func (vr *VaultResolver) AddSecret(secret Secret, index int) error {
 	vr.mu.Lock()
@@ -306,17 +344,56 @@ func (vr *VaultResolver) GetIdentityByFingerprint(fingerprint string) *Identity
 	return nil
 }
 
-// SaveAll saves all open vaults
+// DeferWrites makes later changes to all open vaults stay in memory until
+// SaveAll or SaveVault writes them. See Manager.DeferWrites.
+func (vr *VaultResolver) DeferWrites() {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+
+	for _, manager := range vr.vaults {
+		if manager != nil {
+			manager.DeferWrites()
+		}
+	}
+}
+
+// SaveAll saves all open vaults in two phases: the pending changes of every
+// vault are first written to temp files, and only when all of them are
+// written are the temp files renamed over the vaults. If any vault fails to
+// stage, the temp files are removed and no vault is changed. Renames are
+// not atomic across files, but staging first leaves only the renames
+// themselves as the window for a partial save.
 func (vr *VaultResolver) SaveAll() error {
 	vr.mu.Lock()
 	defer vr.mu.Unlock()
 
+	type staged struct {
+		index   int
+		tmpPath string
+	}
+	var pending []staged
 	for i, manager := range vr.vaults {
 		if manager == nil || manager.IsReadOnly() {
 			continue
 		}
-		if err := manager.Save(); err != nil {
-			return fmt.Errorf("failed to save vault %d: %w", i+1, err)
+		tmpPath, err := manager.stageSave()
+		if err != nil {
+			for _, p := range pending {
+				_ = os.Remove(p.tmpPath)
+			}
+			return fmt.Errorf("failed to save vault %d, no vault was changed: %w", i+1, err)
+		}
+		if tmpPath != "" {
+			pending = append(pending, staged{index: i, tmpPath: tmpPath})
+		}
+	}
+
+	for n, p := range pending {
+		if err := vr.vaults[p.index].commitSave(p.tmpPath); err != nil {
+			for _, rest := range pending[n+1:] {
+				_ = os.Remove(rest.tmpPath)
+			}
+			return fmt.Errorf("failed to save vault %d after saving %d other vault(s): %w", p.index+1, n, err)
 		}
 	}
 	return nil
@@ -344,6 +421,14 @@ func (vr *VaultResolver) GetConfig() VaultConfig {
 	return vr.config
 }
 
+// SetSearchDepth limits searches across vaults to the first depth vaults in
+// search order. A depth of 0 or less searches all of them.
+func (vr *VaultResolver) SetSearchDepth(depth int) {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	vr.config.SearchDepth = depth
+}
+
 // GetVaultManager returns the vault manager for a specific index
 func (vr *VaultResolver) GetVaultManager(index int) *Manager {
 	vr.mu.RLock()
@@ -465,7 +550,19 @@ func (vr *VaultResolver) SaveVault(index int) error {
 	return nil
 }
 
-// FindSecretVaultIndex finds the first vault index containing the secret key
+// TrimSecretHistory drops all but the newest keep values of the secret key in
+// the vault at index and returns the number of values dropped.
+func (vr *VaultResolver) TrimSecretHistory(index int, key string, keep int) (int, error) {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+
+	if index < 0 || index >= len(vr.vaults) || vr.vaults[index] == nil {
+		return 0, fmt.Errorf("vault index %d not available", index)
+	}
+	return vr.vaults[index].TrimSecretHistory(key, keep)
+}
+
+// FindSecretVaultIndex finds the first vault index, in search order, containing the secret key
 // Returns -1 if not found
 func (vr *VaultResolver) FindSecretVaultIndex(key string) int {
 	vr.mu.RLock()
@@ -474,8 +571,8 @@ func (vr *VaultResolver) FindSecretVaultIndex(key string) int {
 	// Normalize key for lookup (graceful fallback for legacy keys)
 	key = NormalizeKeyForLookup(key)
 
-	for i, manager := range vr.vaults {
-		if manager != nil && manager.GetSecretByKey(key) != nil {
+	for _, i := range vr.config.SearchIndices() {
+		if manager := vr.vaults[i]; manager != nil && manager.GetSecretByKey(key) != nil {
 			return i
 		}
 	}
@@ -490,7 +587,8 @@ type SecretKeyInfo struct {
 	Deleted  bool   `json:"deleted,omitempty"`
 }
 
-// ListAllSecretKeys returns all secret keys from all valid vaults
+// ListAllSecretKeys returns all secret keys from all valid vaults. A key
+// present in several vaults is reported from the first in search order.
 func (vr *VaultResolver) ListAllSecretKeys() []SecretKeyInfo {
 	vr.mu.RLock()
 	defer vr.mu.RUnlock()
@@ -498,7 +596,8 @@ func (vr *VaultResolver) ListAllSecretKeys() []SecretKeyInfo {
 	var result []SecretKeyInfo
 	seen := make(map[string]bool)
 
-	for i, manager := range vr.vaults {
+	for _, i := range vr.config.SearchIndices() {
+		manager := vr.vaults[i]
 		if manager == nil {
 			continue
 		}
@@ -2,6 +2,7 @@ package vault
 
 import (
 	"bytes"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -325,3 +326,140 @@ func TestOpenVaultsFromPaths_PermissionDenied(t *testing.T) {
 		t.Errorf("expected error to contain helpful suggestion, got: %v", errMsg)
 	}
 }
+
+func TestResolver_SearchOrderServesShadowedKey(t *testing.T) {
+	tmpDir := t.TempDir()
+	var paths []string
+	for _, name := range []string{"dev", "prod"} {
+		path := filepath.Join(tmpDir, name+".vault")
+		vm := NewManager(path, false)
+		if err := vm.OpenAndLock(); err != nil {
+			t.Fatalf("failed to create %s vault: %v", name, err)
+		}
+		vm.AddSecret(Secret{
+			Key:    "DB_URL",
+			Values: []SecretValue{{AvailableTo: []string{"FP1"}, Value: name}},
+		})
+		if err := vm.Save(); err != nil {
+			t.Fatalf("failed to save %s vault: %v", name, err)
+		}
+		if err := vm.Unlock(); err != nil {
+			t.Fatalf("failed to close %s vault: %v", name, err)
+		}
+		paths = append(paths, path)
+	}
+
+	tests := []struct {
+		searchOrder []string
+		want        string
+		wantIdx     int
+	}{
+		{nil, "dev", 0},
+		{[]string{"prod"}, "prod", 1},
+		{[]string{"dev", "prod"}, "dev", 0},
+	}
+	for _, tt := range tests {
+		config, err := ParseNamedVaultConfig(paths, []string{"dev", "prod"}, tt.searchOrder)
+		if err != nil {
+			t.Fatalf("ParseNamedVaultConfig(%v): %v", tt.searchOrder, err)
+		}
+		resolver := NewVaultResolver(config)
+		if err := resolver.OpenVaults(io.Discard); err != nil {
+			t.Fatalf("OpenVaults: %v", err)
+		}
+
+		value, err := resolver.GetSecretFromAnyVault("DB_URL", io.Discard)
+		if err != nil {
+			t.Fatalf("GetSecretFromAnyVault: %v", err)
+		}
+		if value.Value != tt.want {
+			t.Errorf("search order %v: got value %q, want %q", tt.searchOrder, value.Value, tt.want)
+		}
+		value, err = resolver.GetAccessibleSecretFromAnyVault("DB_URL", "FP1")
+		if err != nil || value.Value != tt.want {
+			t.Errorf("search order %v: accessible value = %v, %v; want %q", tt.searchOrder, value, err, tt.want)
+		}
+		if idx := resolver.FindSecretVaultIndex("DB_URL"); idx != tt.wantIdx {
+			t.Errorf("search order %v: FindSecretVaultIndex = %d, want %d", tt.searchOrder, idx, tt.wantIdx)
+		}
+		if keys := resolver.ListAllSecretKeys(); len(keys) != 1 || keys[0].VaultIdx != tt.wantIdx+1 {
+			t.Errorf("search order %v: ListAllSecretKeys = %+v", tt.searchOrder, keys)
+		}
+		_ = resolver.CloseAll()
+	}
+}
+
+func TestResolver_SaveAllStagesBeforeRenaming(t *testing.T) {
+	tmpDir := t.TempDir()
+	var entries []VaultEntry
+	for _, name := range []string{"first", "second"} {
+		path := filepath.Join(tmpDir, name+".vault")
+		vm := NewManager(path, false)
+		if err := vm.OpenAndLock(); err != nil {
+			t.Fatalf("failed to create %s vault: %v", name, err)
+		}
+		if err := vm.Unlock(); err != nil {
+			t.Fatalf("failed to close %s vault: %v", name, err)
+		}
+		entries = append(entries, VaultEntry{Path: path})
+	}
+	first, second := entries[0].Path, entries[1].Path
+
+	resolver := NewVaultResolver(VaultConfig{Entries: entries})
+	if err := resolver.OpenVaults(io.Discard); err != nil {
+		t.Fatalf("OpenVaults: %v", err)
+	}
+	defer func() { _ = resolver.CloseAll() }()
+
+	before, err := os.ReadFile(first)
+	if err != nil {
+		t.Fatalf("failed to read first vault: %v", err)
+	}
+
+	resolver.DeferWrites()
+	id := Identity{Fingerprint: "FP1", UID: "Alice"}
+	for i := range entries {
+		if err := resolver.AddIdentity(id, i); err != nil {
+			t.Fatalf("AddIdentity(%d): %v", i, err)
+		}
+	}
+	if after, _ := os.ReadFile(first); !bytes.Equal(before, after) {
+		t.Fatal("deferred change was written before SaveAll")
+	}
+
+	// A directory where the second vault's temp file goes makes it fail to stage.
+	if err := os.Mkdir(second+".tmp", 0o700); err != nil {
+		t.Fatalf("failed to block temp file: %v", err)
+	}
+	err = resolver.SaveAll()
+	if err == nil || !strings.Contains(err.Error(), "failed to save vault 2, no vault was changed") {
+		t.Fatalf("expected a staging failure on vault 2, got %v", err)
+	}
+	if after, _ := os.ReadFile(first); !bytes.Equal(before, after) {
+		t.Error("first vault was changed although the second failed to stage")
+	}
+	if _, statErr := os.Stat(first + ".tmp"); !os.IsNotExist(statErr) {
+		t.Errorf("staged temp file of the first vault was left behind: %v", statErr)
+	}
+
+	// Once the second vault can be staged, both changes are saved.
+	if err := os.Remove(second + ".tmp"); err != nil {
+		t.Fatalf("failed to unblock temp file: %v", err)
+	}
+	if err := resolver.SaveAll(); err != nil {
+		t.Fatalf("SaveAll: %v", err)
+	}
+	for _, path := range []string{first, second} {
+		w, err := NewWriterReadOnly(path)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", path, err)
+		}
+		v, err := w.ReadVault()
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", path, err)
+		}
+		if v.GetIdentityByFingerprint("FP1") == nil {
+			t.Errorf("%s: identity was not saved", path)
+		}
+	}
+}
@@ -9,34 +9,22 @@ import (
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
 )
 
-// ComputeSecretHash computes the canonical hash for a secret.
-// The canonical format includes: added_at:key:signed_by
+// ComputeSecretHash computes the canonical hash for a secret, the hash of
+// CanonicalSecretData.
 func ComputeSecretHash(secret *Secret, algorithmBits int) string {
-	// Canonical data format: secret:added_at:key:signed_by
-	canonicalData := fmt.Sprintf("secret:%s:%s:%s",
-		secret.AddedAt.Format(time.RFC3339Nano),
-		secret.Key,
-		secret.SignedBy)
-
-	return identity.ComputeHash([]byte(canonicalData), algorithmBits)
+	return identity.ComputeHash([]byte(CanonicalSecretData(secret)), algorithmBits)
 }
 
-// ComputeSecretValueHash computes the canonical hash for a secret value.
-// The canonical format includes: added_at:available_to:signed_by:value
-// The available_to list is joined with commas for deterministic representation.
+// ComputeSecretValueHash computes the canonical hash for a secret value, the
+// hash of CanonicalValueData.
 func ComputeSecretValueHash(value *SecretValue, secretKey string, algorithmBits int) string {
-	// Join recipients with commas for deterministic representation
-	availableTo := strings.Join(value.AvailableTo, ",")
-	// Canonical data format: value:added_at:secret_key:available_to:signed_by:value:deleted
-	canonicalData := fmt.Sprintf("value:%s:%s:%s:%s:%s:%t",
-		value.AddedAt.Format(time.RFC3339Nano),
-		secretKey,
-		availableTo,
-		value.SignedBy,
-		value.Value,
-		value.Deleted)
+	return identity.ComputeHash([]byte(CanonicalValueData(value, secretKey)), algorithmBits)
+}
 
-	return identity.ComputeHash([]byte(canonicalData), algorithmBits)
+// ComputeRevocationHash computes the canonical hash for an identity
+// revocation, the hash of CanonicalRevocationData.
+func ComputeRevocationHash(r *Revocation, algorithmBits int) string {
+	return identity.ComputeHash([]byte(CanonicalRevocationData(r)), algorithmBits)
 }
 
 // VerifySecretSignature verifies the cryptographic signature of a secret.
@@ -1,6 +1,7 @@
 package vault
 
 import (
+	"slices"
 	"time"
 
 	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
@@ -20,6 +21,60 @@ type SecretValue struct {
 	Signature   string    `json:"signature"`
 	SignedBy    string    `json:"signed_by"`
 	Value       string    `json:"value"` // Base64-encoded encrypted value
+
+	// ValueRef names the file holding Value when the value is stored
+	// detached (see DetachedValueRef). Value is still filled in when the
+	// vault is read; only the vault file leaves it empty.
+	ValueRef string `json:"value_ref,omitempty"`
+
+	// detachedErr is why a detached Value could not be filled in, such as
+	// a value file that was modified. Value is left empty; see DetachedErr.
+	detachedErr error
+
+	// Compressed marks a value whose plaintext was gzipped before
+	// encryption (see CompressValue). It is part of the signed canonical
+	// data, so a compressed payload cannot be passed off as plain, or the
+	// reverse.
+	Compressed bool `json:"compressed,omitempty"`
+
+	// Source records where the value came from, such as "imported-from:aws"
+	// or "rotated-by-ci", so audits can tell human and automated changes
+	// apart. It is part of the signed canonical data. Empty for values
+	// written before it existed.
+	Source string `json:"source,omitempty"`
+
+	// readers is the set of AvailableTo, built when a value with many
+	// recipients is loaded (see indexReaders) so CanBeReadBy needn't scan.
+	readers map[string]struct{}
+}
+
+// readerSetMinRecipients is the number of recipients from which a loaded
+// value gets a reader set. Below it, scanning AvailableTo is as fast as a
+// map lookup and saves the allocation.
+const readerSetMinRecipients = 16
+
+// indexReaders builds the reader set of a freshly loaded value. AvailableTo
+// is not sorted instead: lists written out of order by older versions must
+// keep their order, which is signed.
+func (v *SecretValue) indexReaders() {
+	if len(v.AvailableTo) < readerSetMinRecipients {
+		return
+	}
+	v.readers = make(map[string]struct{}, len(v.AvailableTo))
+	for _, fp := range v.AvailableTo {
+		v.readers[fp] = struct{}{}
+	}
+}
+
+// CanBeReadBy reports whether fingerprint is listed in AvailableTo. Values
+// loaded from a vault with many recipients use their reader set; others,
+// including values built in memory, scan the list.
+func (v *SecretValue) CanBeReadBy(fingerprint string) bool {
+	if v.readers != nil {
+		_, ok := v.readers[fingerprint]
+		return ok
+	}
+	return slices.Contains(v.AvailableTo, fingerprint)
 }
 
 // Secret represents a secret with its encrypted values.
@@ -34,23 +89,55 @@ type Secret struct {
 	Values    []SecretValue `json:"values"`
 }
 
+// Revocation records that an identity was removed from a vault. The
+// identity's records stay in the file; the revocation, signed by whoever
+// removed it, tells readers to stop indexing them.
+type Revocation struct {
+	AddedAt     time.Time `json:"added_at"`
+	Fingerprint string    `json:"fingerprint"`
+	Hash        string    `json:"hash"`
+	Signature   string    `json:"signature"`
+	SignedBy    string    `json:"signed_by"`
+}
+
 // Vault represents the complete vault file structure.
-// A vault contains identities (public keys) and secrets (encrypted values).
+// A vault contains identities (public keys) and secrets (encrypted values),
+// and the revocations of identities it no longer holds.
 type Vault struct {
-	Identities []Identity `json:"identities,omitempty"`
-	Secrets    []Secret   `json:"secrets,omitempty"`
+	Identities  []Identity   `json:"identities,omitempty"`
+	Secrets     []Secret     `json:"secrets,omitempty"`
+	Revocations []Revocation `json:"revocations,omitempty"`
 }
 
 // VaultEntry represents a single vault configuration entry
 type VaultEntry struct {
+	Name     string `json:"name,omitempty"` // Optional name usable in place of the index with -v
 	Path     string `json:"path"`
 	Optional bool   `json:"optional,omitempty"` // If true, missing vault is not an error
+
+	// DefaultRecipients lists fingerprints that 'secret put' into this vault
+	// also encrypts new values to, besides the signer.
+	DefaultRecipients []string `json:"default_recipients,omitempty"`
 }
 
 // VaultConfig represents parsed vault configuration
 type VaultConfig struct {
 	Entries                     []VaultEntry
-	RequireExplicitVaultUpgrade bool // If true, don't auto-upgrade vaults
+	RequireExplicitVaultUpgrade bool          // If true, don't auto-upgrade vaults
+	LockTimeout                 time.Duration // See Manager.SetLockTimeout
+
+	// SearchOrder lists entry indices in the order vaults are searched for
+	// a secret. Entries missing from it are searched after, in config order.
+	SearchOrder []int
+
+	// SearchDepth, when positive, limits searches to the first SearchDepth
+	// vaults in search order.
+	SearchDepth int
+
+	// StructureCheck, when set, is run on each vault file before it is
+	// opened. A vault it rejects is not opened, and opening fails with its
+	// error.
+	StructureCheck func(path string) error
 }
 
 // NewVault creates an empty vault.
@@ -94,10 +181,8 @@ func (v Vault) CanIdentityAccessSecret(fingerprint, secretKey string) bool {
 
 	// Check from most recent to oldest
 	for i := len(secret.Values) - 1; i >= 0; i-- {
-		for _, fp := range secret.Values[i].AvailableTo {
-			if fp == fingerprint {
-				return true
-			}
+		if secret.Values[i].CanBeReadBy(fingerprint) {
+			return true
 		}
 	}
 	return false
@@ -111,6 +196,18 @@ func (s Secret) IsDeleted() bool {
 	return s.Values[len(s.Values)-1].Deleted
 }
 
+// LatestReadableValue returns the newest value readable by fingerprint,
+// skipping deletion markers, so a deleted secret yields the last value it
+// had before deletion. Returns nil if no such value exists.
+func (s Secret) LatestReadableValue(fingerprint string) *SecretValue {
+	for i := len(s.Values) - 1; i >= 0; i-- {
+		if !s.Values[i].Deleted && s.Values[i].CanBeReadBy(fingerprint) {
+			return &s.Values[i]
+		}
+	}
+	return nil
+}
+
 // GetAccessibleSecretValue returns the most recent secret value accessible to the identity.
 // Returns nil if identity cannot access any version of the secret.
 // Returns nil if the secret is deleted (latest value has Deleted=true).
@@ -132,10 +229,8 @@ func (v Vault) GetAccessibleSecretValue(fingerprint, secretKey string) *SecretVa
 
 	// Check from most recent to oldest (fallback behavior)
 	for i := len(secret.Values) - 1; i >= 0; i-- {
-		for _, fp := range secret.Values[i].AvailableTo {
-			if fp == fingerprint {
-				return &secret.Values[i]
-			}
+		if secret.Values[i].CanBeReadBy(fingerprint) {
+			return &secret.Values[i]
 		}
 	}
 	return nil
@@ -1,6 +1,7 @@
 package vault
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -211,3 +212,106 @@ func TestVault_GetAccessibleSecretValue_Deleted(t *testing.T) {
 		}
 	})
 }
+
+func TestSecretValue_CanBeReadBy(t *testing.T) {
+	tests := []struct {
+		name        string
+		availableTo []string
+		fp          string
+		want        bool
+	}{
+		{"empty list", nil, "fp1", false},
+		{"sorted hit", []string{"fp1", "fp2", "fp3"}, "fp2", true},
+		{"sorted miss", []string{"fp1", "fp2", "fp3"}, "fp4", false},
+		{"unsorted hit", []string{"fp3", "fp1", "fp2"}, "fp1", true},
+		{"unsorted miss", []string{"fp3", "fp1", "fp2"}, "fp4", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := SecretValue{AvailableTo: tt.availableTo}
+			if got := v.CanBeReadBy(tt.fp); got != tt.want {
+				t.Errorf("CanBeReadBy(%q) = %v, want %v", tt.fp, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecretValue_CanBeReadBy_LoadedValue(t *testing.T) {
+	// An unsorted list wide enough to get a reader set when loaded
+	wide := wideRecipientValues(1, readerSetMinRecipients)[0]
+	wide.AvailableTo = append([]string{"fp1"}, wide.AvailableTo...)
+	entry, err := CreateValueEntry("KEY", wide)
+	if err != nil {
+		t.Fatalf("CreateValueEntry failed: %v", err)
+	}
+	loaded, err := ParseSecretValue(entry)
+	if err != nil {
+		t.Fatalf("ParseSecretValue failed: %v", err)
+	}
+	if loaded.readers == nil {
+		t.Fatal("expected the loaded value to have a reader set")
+	}
+
+	for _, fp := range append(wide.AvailableTo, "fp2", "") {
+		if got, want := loaded.CanBeReadBy(fp), wide.CanBeReadBy(fp); got != want {
+			t.Errorf("CanBeReadBy(%q) = %v for the loaded value, %v for the scanned one", fp, got, want)
+		}
+	}
+}
+
+// wideRecipientValues returns values shared with n sorted fingerprints,
+// mimicking a vault secret shared with a large team.
+func wideRecipientValues(values, n int) []SecretValue {
+	recipients := make([]string, n)
+	for i := range recipients {
+		recipients[i] = fmt.Sprintf("%040X", i)
+	}
+	out := make([]SecretValue, values)
+	for i := range out {
+		out[i] = SecretValue{AvailableTo: recipients}
+	}
+	return out
+}
+
+// BenchmarkSecretValueCanBeReadBy compares scanning AvailableTo, as for
+// values built in memory, with the reader set of values loaded from a vault.
+func BenchmarkSecretValueCanBeReadBy(b *testing.B) {
+	scanned := wideRecipientValues(100, 1000)
+	indexed := wideRecipientValues(100, 1000)
+	for i := range indexed {
+		indexed[i].indexReaders()
+	}
+
+	for _, bc := range []struct {
+		name   string
+		values []SecretValue
+	}{
+		{"scan", scanned},
+		{"set", indexed},
+	} {
+		b.Run(bc.name+"/hit", func(b *testing.B) {
+			fp := fmt.Sprintf("%040X", 999)
+			for b.Loop() {
+				for i := range bc.values {
+					if !bc.values[i].CanBeReadBy(fp) {
+						b.Fatal("expected access")
+					}
+				}
+			}
+		})
+
+		// Misses are the common case when searching for the values one
+		// identity can read, and scan the whole list.
+		b.Run(bc.name+"/miss", func(b *testing.B) {
+			fp := fmt.Sprintf("%040X", 1000)
+			for b.Loop() {
+				for i := range bc.values {
+					if bc.values[i].CanBeReadBy(fp) {
+						b.Fatal("expected no access")
+					}
+				}
+			}
+		})
+	}
+}
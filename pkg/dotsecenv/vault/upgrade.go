@@ -9,7 +9,7 @@ import (
 // DetectVaultVersion reads just the first line of a vault file and extracts the version
 // from the header marker without parsing the full header JSON.
 func DetectVaultVersion(path string) (int, error) {
-	f, err := os.Open(path)
+	f, err := openVaultFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// Non-existent vault is treated as "no version" - will create with latest
@@ -20,11 +20,7 @@ func DetectVaultVersion(path string) (int, error) {
 	defer func() { _ = f.Close() }()
 
 	// Check if file is empty
-	info, err := f.Stat()
-	if err != nil {
-		return 0, fmt.Errorf("failed to stat vault: %w", err)
-	}
-	if info.Size() == 0 {
+	if f.size == 0 {
 		// Empty file is treated as "no version" - will create with latest
 		return 0, nil
 	}
@@ -86,41 +82,53 @@ func printExplicitUpgradeHint() {
 	fmt.Fprintf(os.Stderr, "dotsecenv: run 'dotsecenv vault upgrade' to upgrade the vault format\n")
 }
 
+// PlanUpgrade reports what CheckAndUpgradeVault would do for w, without
+// writing or printing anything. from is the vault's current format version
+// (0 for a new or empty vault) and to is the version it would be upgraded to,
+// or from itself when the vault needs no upgrade. willUpgrade is false when the vault is already current, when it is empty,
+// and when requireExplicitUpgrade is set; pass false to plan an explicitly
+// requested upgrade.
+func PlanUpgrade(w *Writer, requireExplicitUpgrade bool) (from, to int, willUpgrade bool, err error) {
+	from = w.Version()
+	to = LatestFormatVersion
+
+	if from >= LatestFormatVersion || from == 0 {
+		return from, from, false, nil
+	}
+	if from < MinSupportedVersion {
+		return from, to, false, fmt.Errorf("vault format v%d is no longer supported (minimum: v%d)",
+			from, MinSupportedVersion)
+	}
+	return from, to, !requireExplicitUpgrade, nil
+}
+
 // CheckAndUpgradeVault checks if a vault needs upgrading and handles it based on requireExplicitUpgrade.
 // Returns true if the vault was upgraded (caller may need to reload).
 // If requireExplicitUpgrade is true: warns but doesn't modify the vault.
 // If requireExplicitUpgrade is false: upgrades the vault in-place.
 func CheckAndUpgradeVault(w *Writer, path string, requireExplicitUpgrade bool) (bool, error) {
-	currentVersion := w.Version()
-
-	if currentVersion >= LatestFormatVersion {
-		return false, nil // Already at latest version
-	}
-
-	if currentVersion == 0 {
-		// New vault or empty file - no upgrade needed, will be created with latest
-		return false, nil
+	from, to, willUpgrade, err := PlanUpgrade(w, requireExplicitUpgrade)
+	if err != nil {
+		return false, err
 	}
-
-	if currentVersion < MinSupportedVersion {
-		return false, fmt.Errorf("vault format v%d is no longer supported (minimum: v%d)",
-			currentVersion, MinSupportedVersion)
+	if from == to {
+		return false, nil // Already at latest version, or new/empty vault
 	}
 
 	// Always warn to stderr
-	printUpgradeWarning(path, currentVersion, LatestFormatVersion)
+	printUpgradeWarning(path, from, to)
 
-	if requireExplicitUpgrade {
+	if !willUpgrade {
 		// Explicit upgrade required: warn only, don't modify
 		printExplicitUpgradeHint()
 		return false, nil
 	}
 
 	// Auto-upgrade: perform upgrade
-	if err := upgradeVault(w, currentVersion, LatestFormatVersion); err != nil {
+	if err := upgradeVault(w, from, to); err != nil {
 		return false, fmt.Errorf("failed to upgrade vault: %w", err)
 	}
 
-	printUpgradeNotice(path, currentVersion, LatestFormatVersion)
+	printUpgradeNotice(path, from, to)
 	return true, nil
 }
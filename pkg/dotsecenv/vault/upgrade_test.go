@@ -2,6 +2,7 @@ package vault
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"os"
 	"path/filepath"
@@ -589,3 +590,161 @@ func TestHeaderMarkerForVersion(t *testing.T) {
 		}
 	}
 }
+
+func TestHeaderName_RoundTrip(t *testing.T) {
+	for _, version := range []int{1, 2} {
+		h := NewHeader()
+		h.Name = "Prod secrets"
+		h.Identities["FP1"] = 4
+
+		data, err := MarshalHeaderVersioned(h, version)
+		if err != nil {
+			t.Fatalf("v%d: MarshalHeaderVersioned failed: %v", version, err)
+		}
+		got, err := UnmarshalHeaderVersioned(data, version)
+		if err != nil {
+			t.Fatalf("v%d: UnmarshalHeaderVersioned failed: %v", version, err)
+		}
+		if got.Name != "Prod secrets" {
+			t.Errorf("v%d: expected name to round-trip, got %q", version, got.Name)
+		}
+	}
+
+	// Unnamed headers serialize exactly as before.
+	data, err := MarshalHeaderV2(NewHeader())
+	if err != nil {
+		t.Fatalf("MarshalHeaderV2 failed: %v", err)
+	}
+	if strings.Contains(string(data), `"name"`) {
+		t.Errorf("unnamed header should omit name, got: %s", data)
+	}
+}
+
+func TestHeaderName_IgnoredByOlderReaders(t *testing.T) {
+	h := NewHeader()
+	h.Name = "Prod secrets"
+	h.Identities["FP1"] = 4
+	data, err := MarshalHeaderV2(h)
+	if err != nil {
+		t.Fatalf("MarshalHeaderV2 failed: %v", err)
+	}
+
+	// A reader built before the name field existed decodes into a struct
+	// without it.
+	var old struct {
+		Version    int                    `json:"version"`
+		Identities map[string]int         `json:"identities"`
+		Secrets    map[string]SecretIndex `json:"secrets"`
+	}
+	if err := json.Unmarshal(data, &old); err != nil {
+		t.Fatalf("older reader failed to parse named header: %v", err)
+	}
+	if old.Version != 2 || old.Identities["FP1"] != 4 {
+		t.Errorf("older reader got unexpected header: %+v", old)
+	}
+}
+
+func TestHeaderName_SurvivesUpgrade(t *testing.T) {
+	tmpDir := t.TempDir()
+	vaultPath := filepath.Join(tmpDir, "vault")
+
+	v1Data, err := os.ReadFile(filepath.Join("testdata", "vault_v1.jsonl"))
+	if err != nil {
+		t.Fatalf("failed to read v1 fixture: %v", err)
+	}
+	if err := os.WriteFile(vaultPath, v1Data, 0600); err != nil {
+		t.Fatalf("failed to write vault: %v", err)
+	}
+
+	w, err := NewWriter(vaultPath)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	if err := w.SetName("Legacy vault"); err != nil {
+		t.Fatalf("SetName failed: %v", err)
+	}
+
+	if _, err := CheckAndUpgradeVault(w, vaultPath, false); err != nil {
+		t.Fatalf("CheckAndUpgradeVault failed: %v", err)
+	}
+	v, err := w.ReadVault()
+	if err != nil {
+		t.Fatalf("ReadVault failed: %v", err)
+	}
+	if err := w.RewriteFromVault(v); err != nil {
+		t.Fatalf("RewriteFromVault failed: %v", err)
+	}
+
+	reopened, err := NewWriter(vaultPath)
+	if err != nil {
+		t.Fatalf("NewWriter after upgrade failed: %v", err)
+	}
+	if reopened.Version() != LatestFormatVersion {
+		t.Errorf("expected version %d, got %d", LatestFormatVersion, reopened.Version())
+	}
+	if got := reopened.Header().Name; got != "Legacy vault" {
+		t.Errorf("expected name to survive upgrade and rewrite, got %q", got)
+	}
+}
+
+func TestPlanUpgrade(t *testing.T) {
+	tests := []struct {
+		name                   string
+		fixture                string
+		requireExplicitUpgrade bool
+		wantFrom, wantTo       int
+		wantUpgrade            bool
+	}{
+		{name: "v1 plans upgrade", fixture: "vault_v1.jsonl", wantFrom: 1, wantTo: 2, wantUpgrade: true},
+		{name: "v1 under explicit upgrade", fixture: "vault_v1.jsonl", requireExplicitUpgrade: true, wantFrom: 1, wantTo: 2},
+		{name: "v2 is a no-op", fixture: "vault_v2.jsonl", wantFrom: 2, wantTo: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vaultPath := filepath.Join(t.TempDir(), "vault")
+			data, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+			if err := os.WriteFile(vaultPath, data, 0600); err != nil {
+				t.Fatalf("failed to write vault: %v", err)
+			}
+
+			w, err := NewWriter(vaultPath)
+			if err != nil {
+				t.Fatalf("NewWriter failed: %v", err)
+			}
+			from, to, willUpgrade, err := PlanUpgrade(w, tt.requireExplicitUpgrade)
+			if err != nil {
+				t.Fatalf("PlanUpgrade failed: %v", err)
+			}
+			if from != tt.wantFrom || to != tt.wantTo || willUpgrade != tt.wantUpgrade {
+				t.Errorf("PlanUpgrade = (%d, %d, %v), want (%d, %d, %v)",
+					from, to, willUpgrade, tt.wantFrom, tt.wantTo, tt.wantUpgrade)
+			}
+
+			after, err := os.ReadFile(vaultPath)
+			if err != nil {
+				t.Fatalf("failed to read vault: %v", err)
+			}
+			if !bytes.Equal(after, data) {
+				t.Error("PlanUpgrade must not modify the vault file")
+			}
+		})
+	}
+}
+
+func TestPlanUpgrade_NewVault(t *testing.T) {
+	w, err := NewWriter(filepath.Join(t.TempDir(), "vault"))
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	from, to, willUpgrade, err := PlanUpgrade(w, false)
+	if err != nil {
+		t.Fatalf("PlanUpgrade failed: %v", err)
+	}
+	if willUpgrade || from != to {
+		t.Errorf("new vault should need no upgrade, got (%d, %d, %v)", from, to, willUpgrade)
+	}
+}
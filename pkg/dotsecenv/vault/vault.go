@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"time"
 )
 
 // Manager handles vault file operations with locking
@@ -13,7 +14,8 @@ type Manager struct {
 	file                        *os.File
 	locked                      bool
 	readOnly                    bool
-	requireExplicitVaultUpgrade bool // if true, don't auto-upgrade vaults
+	requireExplicitVaultUpgrade bool          // if true, don't auto-upgrade vaults
+	lockTimeout                 time.Duration // how long to wait for a held lock
 	writer                      *Writer
 	vault                       Vault // cached vault for fast access
 }
@@ -65,10 +67,9 @@ func (m *Manager) OpenAndLock() error {
 	}
 
 	// Lock the file
-	// Use shared lock for read-only access, exclusive for read-write
-	if err := lockFile(file, !m.readOnly); err != nil {
+	if err := m.acquireLock(file); err != nil {
 		_ = file.Close()
-		return fmt.Errorf("failed to lock vault file: %w", err)
+		return err
 	}
 
 	m.file = file
@@ -162,6 +163,41 @@ func (m *Manager) GetHeader() *Header {
 	return m.writer.header
 }
 
+// Name returns the vault name from the header, or "" if none is set.
+func (m *Manager) Name() string {
+	if m.writer == nil || m.writer.header == nil {
+		return ""
+	}
+	return m.writer.header.Name
+}
+
+// SetName sets the vault name in the header and writes it to disk.
+func (m *Manager) SetName(name string) error {
+	if m.writer == nil {
+		return fmt.Errorf("vault not loaded")
+	}
+	if m.readOnly {
+		return fmt.Errorf("cannot modify read-only vault")
+	}
+	return m.writer.SetName(name)
+}
+
+// Normalize rewrites the vault file from its parsed lines, dropping a UTF-8
+// BOM and CRLF line endings. The entries are unchanged.
+func (m *Manager) Normalize() error {
+	if m.writer == nil {
+		return fmt.Errorf("vault not loaded")
+	}
+	if m.readOnly {
+		return fmt.Errorf("cannot modify read-only vault")
+	}
+	tmpPath, err := m.writer.stage()
+	if err != nil {
+		return err
+	}
+	return m.writer.commit(tmpPath)
+}
+
 // GetLines returns the raw lines of the vault file for validation
 // Returns nil if the vault hasn't been loaded yet
 func (m *Manager) GetLines() []string {
@@ -171,19 +207,50 @@ func (m *Manager) GetLines() []string {
 	return m.writer.lines
 }
 
-// Save is a no-op kept for API compatibility.
-// Writer methods already persist changes via flush().
+// Save writes the changes held back by DeferWrites. Otherwise it is a
+// no-op: writer methods (AddIdentity, AddSecret, AddSecretValue, etc.)
+// already persist each change via flush().
 func (m *Manager) Save() error {
+	tmpPath, err := m.stageSave()
+	if err != nil || tmpPath == "" {
+		return err
+	}
+	return m.commitSave(tmpPath)
+}
+
+// DeferWrites makes later changes stay in memory until Save, instead of
+// being written to the vault file one by one, so a batch of changes is
+// written once. VaultResolver.SaveAll uses this to write several vaults
+// together. Pending changes live only in this process: unsaved changes are
+// dropped when the vault is closed.
+func (m *Manager) DeferWrites() {
+	if m.writer != nil && !m.readOnly {
+		m.writer.deferred = true
+	}
+}
+
+// stageSave writes the pending deferred changes to a temp file next to the
+// vault and returns its path, or "" when nothing is pending. The changes
+// stay pending until the temp file is committed.
+func (m *Manager) stageSave() (string, error) {
 	if m.file == nil {
-		return fmt.Errorf("vault file not open")
+		return "", fmt.Errorf("vault file not open")
 	}
 	if m.readOnly {
-		return fmt.Errorf("cannot save to read-only vault")
+		return "", fmt.Errorf("cannot save to read-only vault")
+	}
+	if !m.writer.dirty {
+		return "", nil
 	}
+	return m.writer.stage()
+}
 
-	// No-op: Writer methods (AddIdentity, AddSecret, AddSecretValue, etc.)
-	// already call flush() which persists changes immediately.
-	// Full rewrites should only happen via explicit Defragment() calls.
+// commitSave renames the temp file from stageSave over the vault.
+func (m *Manager) commitSave(tmpPath string) error {
+	if err := m.writer.commit(tmpPath); err != nil {
+		return err
+	}
+	m.writer.dirty = false
 	return nil
 }
 
@@ -210,6 +277,27 @@ func (m *Manager) AddIdentity(id Identity) {
 	m.vault.Identities = append(m.vault.Identities, id)
 }
 
+// ReplaceIdentity records id as the current version of an identity already
+// in the vault, appending it after the records written so far.
+func (m *Manager) ReplaceIdentity(id Identity) error {
+	if m.readOnly {
+		return fmt.Errorf("cannot replace identity in read-only vault")
+	}
+	if err := m.writer.ReplaceIdentity(id); err != nil {
+		return err
+	}
+
+	// Match the order ReadVault returns: by line in the file
+	for i, existing := range m.vault.Identities {
+		if existing.Fingerprint == id.Fingerprint {
+			m.vault.Identities = append(m.vault.Identities[:i], m.vault.Identities[i+1:]...)
+			break
+		}
+	}
+	m.vault.Identities = append(m.vault.Identities, id)
+	return nil
+}
+
 // AddSecret adds a new secret or updates an existing one with a new value.
 // Secret keys are compared case-insensitively using CompareSecretKeys.
 func (m *Manager) AddSecret(secret Secret) {
@@ -272,16 +360,7 @@ func (m *Manager) GetAllAccessibleSecretValues(fingerprint, secretKey string) []
 	// Iterate from oldest to newest to maintain chronological order
 	for i := 0; i < len(secret.Values); i++ {
 		value := &secret.Values[i]
-		// Check if identity can access this value
-		canAccess := false
-		for _, fp := range value.AvailableTo {
-			if fp == fingerprint {
-				canAccess = true
-				break
-			}
-		}
-
-		if canAccess && !seen[value.Value] {
+		if value.CanBeReadBy(fingerprint) && !seen[value.Value] {
 			result = append(result, value)
 			seen[value.Value] = true
 		}
@@ -318,6 +397,25 @@ func (m *Manager) FragmentationStats() (*FragmentationStats, error) {
 	return CalculateFragmentation(reader)
 }
 
+// TrimSecretHistory drops all but the newest keep values of the secret key
+// and rewrites the vault file. It returns the number of values dropped; when
+// nothing is dropped the file is left untouched.
+func (m *Manager) TrimSecretHistory(key string, keep int) (int, error) {
+	if m.readOnly {
+		return 0, fmt.Errorf("cannot trim history in read-only vault")
+	}
+
+	trimmed, dropped := TrimSecretHistory(m.vault, key, keep)
+	if dropped == 0 {
+		return 0, nil
+	}
+	if err := m.writer.RewriteFromVault(trimmed); err != nil {
+		return 0, fmt.Errorf("failed to rewrite vault: %w", err)
+	}
+	m.vault = trimmed
+	return dropped, nil
+}
+
 // Defragment performs vault defragmentation
 func (m *Manager) Defragment() (*FragmentationStats, error) {
 	stats, err := Defragment(m.writer)
@@ -26,6 +26,41 @@ func ParseVaultConfig(vaultPaths []string) (VaultConfig, error) {
 	return config, nil
 }
 
+// ParseNamedVaultConfig parses vault configuration from a list of paths and
+// their names. names runs parallel to vaultPaths, with "" for an unnamed
+// vault. searchOrder lists vault names in the order vaults are searched.
+func ParseNamedVaultConfig(vaultPaths, names, searchOrder []string) (VaultConfig, error) {
+	config, err := ParseVaultConfig(vaultPaths)
+	if err != nil {
+		return config, err
+	}
+
+	for i, name := range names {
+		if name == "" || i >= len(config.Entries) {
+			continue
+		}
+		if config.IndexByName(name) >= 0 {
+			return config, fmt.Errorf("duplicate vault name %q", name)
+		}
+		config.Entries[i].Name = name
+	}
+
+	seen := make(map[int]bool)
+	for _, name := range searchOrder {
+		idx := config.IndexByName(name)
+		if idx < 0 {
+			return config, fmt.Errorf("search_order: no vault named %q", name)
+		}
+		if seen[idx] {
+			return config, fmt.Errorf("search_order: vault %q listed more than once", name)
+		}
+		seen[idx] = true
+		config.SearchOrder = append(config.SearchOrder, idx)
+	}
+
+	return config, nil
+}
+
 // getCurrentUserHomeDir resolves the current user's home directory
 func getCurrentUserHomeDir() (string, error) {
 	currentUser, err := user.Current()
@@ -72,3 +107,36 @@ func ValidateVaultConfigExists(config VaultConfig) error {
 func (vc VaultConfig) GetEntriesInOrder() []VaultEntry {
 	return vc.Entries
 }
+
+// IndexByName returns the index of the entry with the given name, or -1.
+func (vc VaultConfig) IndexByName(name string) int {
+	for i, entry := range vc.Entries {
+		if entry.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// SearchIndices returns entry indices in the order vaults are searched:
+// those in SearchOrder first, then the rest in config order, cut to
+// SearchDepth when it is set.
+func (vc VaultConfig) SearchIndices() []int {
+	indices := make([]int, 0, len(vc.Entries))
+	listed := make(map[int]bool, len(vc.SearchOrder))
+	for _, idx := range vc.SearchOrder {
+		if idx >= 0 && idx < len(vc.Entries) && !listed[idx] {
+			listed[idx] = true
+			indices = append(indices, idx)
+		}
+	}
+	for i := range vc.Entries {
+		if !listed[i] {
+			indices = append(indices, i)
+		}
+	}
+	if vc.SearchDepth > 0 && len(indices) > vc.SearchDepth {
+		indices = indices[:vc.SearchDepth]
+	}
+	return indices
+}
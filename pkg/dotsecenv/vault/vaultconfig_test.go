@@ -3,6 +3,7 @@ package vault
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 )
 
@@ -81,3 +82,38 @@ func TestGetEntriesInOrder(t *testing.T) {
 		t.Errorf("order mismatch 1")
 	}
 }
+
+func TestParseNamedVaultConfig(t *testing.T) {
+	paths := []string{"/a/vault", "/b/vault", "/c/vault"}
+
+	cfg, err := ParseNamedVaultConfig(paths, []string{"dev", "", "prod"}, []string{"prod"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Entries[0].Name != "dev" || cfg.Entries[1].Name != "" || cfg.Entries[2].Name != "prod" {
+		t.Errorf("names not applied: %+v", cfg.Entries)
+	}
+	if idx := cfg.IndexByName("prod"); idx != 2 {
+		t.Errorf("IndexByName(prod) = %d, want 2", idx)
+	}
+	if idx := cfg.IndexByName("staging"); idx != -1 {
+		t.Errorf("IndexByName(staging) = %d, want -1", idx)
+	}
+	if got, want := cfg.SearchIndices(), []int{2, 0, 1}; !slices.Equal(got, want) {
+		t.Errorf("SearchIndices() = %v, want %v", got, want)
+	}
+	cfg.SearchDepth = 2
+	if got, want := cfg.SearchIndices(), []int{2, 0}; !slices.Equal(got, want) {
+		t.Errorf("SearchIndices() with depth 2 = %v, want %v", got, want)
+	}
+
+	if _, err := ParseNamedVaultConfig(paths, []string{"dev", "dev", ""}, nil); err == nil {
+		t.Error("expected error for duplicate vault name")
+	}
+	if _, err := ParseNamedVaultConfig(paths, []string{"dev", "", ""}, []string{"prod"}); err == nil {
+		t.Error("expected error for search_order naming an unknown vault")
+	}
+	if _, err := ParseNamedVaultConfig(paths, []string{"dev", "", ""}, []string{"dev", "dev"}); err == nil {
+		t.Error("expected error for vault listed twice in search_order")
+	}
+}
@@ -2,9 +2,12 @@ package vault
 
 import (
 	"bufio"
+	"compress/gzip"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -19,6 +22,15 @@ type Writer struct {
 	version  int      // current vault format version
 	lines    []string // cached lines for header rewriting
 	readOnly bool     // if true, don't try to create/modify files
+
+	// compressed is true when the vault is stored gzip-compressed, either
+	// because its name ends in CompressedExt or because it was read compressed.
+	compressed bool
+
+	// deferred holds changes in memory instead of writing each one; dirty
+	// records that some are pending. See Manager.DeferWrites.
+	deferred bool
+	dirty    bool
 }
 
 // NewWriter creates a new vault writer
@@ -35,7 +47,7 @@ func NewWriterReadOnly(path string) (*Writer, error) {
 }
 
 func newWriter(path string, readOnly bool) (*Writer, error) {
-	w := &Writer{path: path, readOnly: readOnly}
+	w := &Writer{path: path, readOnly: readOnly, compressed: isCompressedPath(path)}
 
 	// Check if file exists
 	if _, err := os.Stat(path); os.IsNotExist(err) {
@@ -79,18 +91,17 @@ func (w *Writer) createNewVault() error {
 
 // loadExisting loads an existing vault's header and lines
 func (w *Writer) loadExisting() error {
-	file, err := os.Open(w.path)
+	file, err := openVaultFile(w.path)
 	if err != nil {
 		return fmt.Errorf("failed to open vault: %w", err)
 	}
 	defer func() { _ = file.Close() }()
+	if file.compressed {
+		w.compressed = true
+	}
 
 	// Check if file is empty
-	info, err := file.Stat()
-	if err != nil {
-		return fmt.Errorf("failed to stat vault: %w", err)
-	}
-	if info.Size() == 0 {
+	if file.size == 0 {
 		if w.readOnly {
 			// In read-only mode, treat empty file as empty vault (no write needed)
 			w.header = NewHeader()
@@ -164,59 +175,99 @@ func (w *Writer) loadExisting() error {
 	return nil
 }
 
-// flush writes all lines to the vault file atomically
+// flush writes all lines to the vault file atomically, or with deferred
+// writes only marks them pending.
 func (w *Writer) flush() error {
+	if w.deferred {
+		w.dirty = true
+		return nil
+	}
+	tmpPath, err := w.stage()
+	if err != nil {
+		return err
+	}
+	return w.commit(tmpPath)
+}
+
+// stage writes all lines to a temp file next to the vault and returns its
+// path. The vault itself is untouched until commit renames the temp file.
+func (w *Writer) stage() (string, error) {
 	// Update header line using current version's format
 	headerJSON, err := MarshalHeaderVersioned(w.header, w.version)
 	if err != nil {
-		return fmt.Errorf("failed to marshal header: %w", err)
+		return "", fmt.Errorf("failed to marshal header: %w", err)
 	}
 	w.lines[1] = string(headerJSON)
 
-	// Capture original file's permissions and ownership before writing
+	// Keep the original file's permissions
 	var originalMode os.FileMode = 0o600 // default for new files
-	var originalUID, originalGID = -1, -1
 	if info, err := os.Stat(w.path); err == nil {
 		originalMode = info.Mode().Perm()
-		originalUID, originalGID = getFileOwner(info)
 	}
 
 	// Write to temp file first for atomicity
 	tmpPath := w.path + ".tmp"
 	tmpFile, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, originalMode)
 	if err != nil {
-		return fmt.Errorf("failed to create temp file: %w", err)
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	// Compress on the way out when the vault is stored gzip-compressed
+	var out io.Writer = tmpFile
+	var gz *gzip.Writer
+	if w.compressed {
+		gz = gzip.NewWriter(tmpFile)
+		out = gz
 	}
 
-	writer := bufio.NewWriter(tmpFile)
+	writer := bufio.NewWriter(out)
 	for i, line := range w.lines {
 		if _, err := writer.WriteString(line); err != nil {
 			_ = tmpFile.Close()
 			_ = os.Remove(tmpPath)
-			return fmt.Errorf("failed to write line %d: %w", i, err)
+			return "", fmt.Errorf("failed to write line %d: %w", i, err)
 		}
 		if _, err := writer.WriteString("\n"); err != nil {
 			_ = tmpFile.Close()
 			_ = os.Remove(tmpPath)
-			return fmt.Errorf("failed to write newline at line %d: %w", i, err)
+			return "", fmt.Errorf("failed to write newline at line %d: %w", i, err)
 		}
 	}
 
 	if err := writer.Flush(); err != nil {
 		_ = tmpFile.Close()
 		_ = os.Remove(tmpPath)
-		return fmt.Errorf("failed to flush writer: %w", err)
+		return "", fmt.Errorf("failed to flush writer: %w", err)
+	}
+
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			_ = tmpFile.Close()
+			_ = os.Remove(tmpPath)
+			return "", fmt.Errorf("failed to compress vault: %w", err)
+		}
 	}
 
 	if err := tmpFile.Sync(); err != nil {
 		_ = tmpFile.Close()
 		_ = os.Remove(tmpPath)
-		return fmt.Errorf("failed to sync temp file: %w", err)
+		return "", fmt.Errorf("failed to sync temp file: %w", err)
 	}
 
 	if err := tmpFile.Close(); err != nil {
 		_ = os.Remove(tmpPath)
-		return fmt.Errorf("failed to close temp file: %w", err)
+		return "", fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	return tmpPath, nil
+}
+
+// commit renames the temp file written by stage over the vault.
+func (w *Writer) commit(tmpPath string) error {
+	// Capture original file's ownership before replacing it
+	var originalUID, originalGID = -1, -1
+	if info, err := os.Stat(w.path); err == nil {
+		originalUID, originalGID = getFileOwner(info)
 	}
 
 	// Atomic rename
@@ -269,6 +320,68 @@ func (w *Writer) AddIdentity(id identity.Identity) error {
 	return w.flush()
 }
 
+// ReplaceIdentity appends id as the current record of an identity already
+// in the vault. The earlier record stays in the file for history, but the
+// header no longer indexes it, so readers see only id.
+func (w *Writer) ReplaceIdentity(id identity.Identity) error {
+	if _, exists := w.header.Identities[id.Fingerprint]; !exists {
+		return fmt.Errorf("identity not found: %s", id.Fingerprint)
+	}
+
+	if err := w.checkAppendTimestamps(id.AddedAt); err != nil {
+		return err
+	}
+
+	lineNum := w.nextLineNumber()
+
+	entry, err := CreateIdentityEntry(id)
+	if err != nil {
+		return err
+	}
+
+	entryJSON, err := MarshalEntry(*entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal identity entry: %w", err)
+	}
+
+	w.lines = append(w.lines, string(entryJSON))
+	w.header.Identities[id.Fingerprint] = lineNum
+
+	return w.flush()
+}
+
+// RevokeIdentity appends r, the revocation of an identity in the vault. The
+// identity's records stay in the file, but the header no longer indexes
+// them, so readers no longer see the identity.
+func (w *Writer) RevokeIdentity(r Revocation) error {
+	if _, exists := w.header.Identities[r.Fingerprint]; !exists {
+		return fmt.Errorf("identity not found: %s", r.Fingerprint)
+	}
+
+	if err := w.checkAppendTimestamps(r.AddedAt); err != nil {
+		return err
+	}
+
+	lineNum := w.nextLineNumber()
+
+	entry, err := CreateRevocationEntry(r)
+	if err != nil {
+		return err
+	}
+
+	entryJSON, err := MarshalEntry(*entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal revocation entry: %w", err)
+	}
+
+	w.requireVersion(RevocationFormatVersion)
+	w.lines = append(w.lines, string(entryJSON))
+	delete(w.header.Identities, r.Fingerprint)
+	w.header.Revocations = append(w.header.Revocations, lineNum)
+
+	return w.flush()
+}
+
 // AddSecret adds a new secret definition to the vault
 func (w *Writer) AddSecret(s Secret) error {
 	// Check for duplicate (case-insensitive)
@@ -325,6 +438,9 @@ func (w *Writer) AddSecretValue(secretKey string, sv SecretValue) error {
 	if err := w.checkAppendTimestamps(sv.AddedAt); err != nil {
 		return err
 	}
+	if err := w.prepareValues(sv); err != nil {
+		return err
+	}
 
 	lineNum := w.nextLineNumber()
 
@@ -346,6 +462,37 @@ func (w *Writer) AddSecretValue(secretKey string, sv SecretValue) error {
 	return w.flush()
 }
 
+// prepareValues writes the detached values among values to their files,
+// and moves the vault to the format version its values need if it is older:
+// DetachedFormatVersion for detached values, CompressedFormatVersion for
+// compressed ones and SourceFormatVersion for ones with a source.
+func (w *Writer) prepareValues(values ...SecretValue) error {
+	for _, sv := range values {
+		if sv.Compressed {
+			w.requireVersion(CompressedFormatVersion)
+		}
+		if sv.Source != "" {
+			w.requireVersion(SourceFormatVersion)
+		}
+		if sv.ValueRef == "" {
+			continue
+		}
+		if err := writeDetachedValue(w.path, sv); err != nil {
+			return err
+		}
+		w.requireVersion(DetachedFormatVersion)
+	}
+	return nil
+}
+
+// requireVersion moves the vault to format version if it is older.
+func (w *Writer) requireVersion(version int) {
+	if w.version < version {
+		w.version = version
+		w.header.Version = version
+	}
+}
+
 // AddSecretWithValues adds a secret definition and its initial values
 func (w *Writer) AddSecretWithValues(s Secret) error {
 	// Check for duplicate (case-insensitive)
@@ -363,6 +510,9 @@ func (w *Writer) AddSecretWithValues(s Secret) error {
 	if err := w.checkAppendTimestamps(timestamps...); err != nil {
 		return err
 	}
+	if err := w.prepareValues(s.Values...); err != nil {
+		return err
+	}
 
 	// Add secret definition
 	defLineNum := w.nextLineNumber()
@@ -422,9 +572,11 @@ func (w *Writer) Header() Header {
 		}
 	}
 	h := Header{
-		Version:    w.header.Version,
-		Identities: make(map[string]int, len(w.header.Identities)),
-		Secrets:    make(map[string]SecretIndex, len(w.header.Secrets)),
+		Version:     w.header.Version,
+		Name:        w.header.Name,
+		Identities:  make(map[string]int, len(w.header.Identities)),
+		Secrets:     make(map[string]SecretIndex, len(w.header.Secrets)),
+		Revocations: slices.Clone(w.header.Revocations),
 	}
 	for k, v := range w.header.Identities {
 		h.Identities[k] = v
@@ -458,9 +610,16 @@ func (w *Writer) RewriteFromVault(v Vault) error {
 
 // RewriteFromVaultWithVersion completely rewrites the vault file from a Vault struct
 // using the specified format version. This is used for upgrades and defragmentation.
+// The vault name, if any, is kept.
 func (w *Writer) RewriteFromVaultWithVersion(v Vault, version int) error {
+	var name string
+	if w.header != nil {
+		name = w.header.Name
+	}
+
 	// Start fresh with specified version
 	w.header = NewHeader()
+	w.header.Name = name
 	w.version = version
 	w.lines = []string{
 		HeaderMarker,
@@ -468,6 +627,35 @@ func (w *Writer) RewriteFromVaultWithVersion(v Vault, version int) error {
 		DataMarker,
 	}
 
+	// Detached, compressed and sourced values need their format version, and
+	// detached ones their files next to this vault, which may not be the one
+	// they were read from
+	for _, sec := range v.Secrets {
+		if err := w.prepareValues(sec.Values...); err != nil {
+			return err
+		}
+	}
+
+	// Add revocations before identities, so an identity added back after
+	// its revocation stays current when the file is reindexed
+	for _, r := range v.Revocations {
+		w.requireVersion(RevocationFormatVersion)
+		lineNum := w.nextLineNumber()
+
+		entry, err := CreateRevocationEntry(r)
+		if err != nil {
+			return err
+		}
+
+		entryJSON, err := MarshalEntry(*entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal revocation entry: %w", err)
+		}
+
+		w.lines = append(w.lines, string(entryJSON))
+		w.header.Revocations = append(w.header.Revocations, lineNum)
+	}
+
 	// Add identities
 	for _, id := range v.Identities {
 		lineNum := w.nextLineNumber()
@@ -567,6 +755,24 @@ func (w *Writer) ReadVault() (Vault, error) {
 		v.Identities = append(v.Identities, data.ToIdentity())
 	}
 
+	for _, lineNum := range w.header.Revocations {
+		if lineNum < 1 || lineNum > len(w.lines) {
+			return v, fmt.Errorf("invalid line number %d for revocation", lineNum)
+		}
+
+		entry, err := UnmarshalEntry([]byte(w.lines[lineNum-1]))
+		if err != nil {
+			return v, fmt.Errorf("failed to parse revocation entry at line %d: %w", lineNum, err)
+		}
+
+		data, err := ParseRevocation(entry)
+		if err != nil {
+			return v, err
+		}
+
+		v.Revocations = append(v.Revocations, *data)
+	}
+
 	// Collect secrets with their line numbers for sorting
 	type secretWithLine struct {
 		key     string
@@ -624,6 +830,7 @@ func (w *Writer) ReadVault() (Vault, error) {
 			if err != nil {
 				return v, err
 			}
+			_ = loadDetachedValue(w.path, valData) // kept on the value, see DetachedErr
 
 			secret.Values = append(secret.Values, *valData)
 		}
@@ -642,6 +849,12 @@ func (w *Writer) GetLine(lineNum int) (string, error) {
 	return w.lines[lineNum-1], nil
 }
 
+// SetName sets the vault name in the header and persists it.
+func (w *Writer) SetName(name string) error {
+	w.header.Name = name
+	return w.flush()
+}
+
 // UpdateHeader updates only the header without modifying data entries
 // Used when the header needs to be refreshed (e.g., after external modification)
 func (w *Writer) UpdateHeader(h *Header) error {
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -345,3 +346,49 @@ func TestDirectoryPermissionsOnNewVault(t *testing.T) {
 		t.Errorf("expected directory permissions 0700, got %o", dirInfo.Mode().Perm())
 	}
 }
+
+func TestWriter_SourcedValueBumpsVersion(t *testing.T) {
+	vaultPath := filepath.Join(t.TempDir(), "vault")
+	w, err := NewWriter(vaultPath)
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	plain := SecretValue{AddedAt: now, AvailableTo: []string{"FP1"}, SignedBy: "FP1", Value: "cGxhaW4="}
+	if err := w.AddSecretWithValues(Secret{AddedAt: now, Key: "KEY", SignedBy: "FP1", Values: []SecretValue{plain}}); err != nil {
+		t.Fatalf("AddSecretWithValues failed: %v", err)
+	}
+	if w.Version() != LatestFormatVersion {
+		t.Fatalf("expected a value without source to keep version %d, got %d", LatestFormatVersion, w.Version())
+	}
+
+	sourced := SecretValue{AddedAt: now.Add(time.Second), AvailableTo: []string{"FP1"}, SignedBy: "FP1", Value: "Y2k=", Source: "rotated-by-ci"}
+	if err := w.AddSecretValue("KEY", sourced); err != nil {
+		t.Fatalf("AddSecretValue failed: %v", err)
+	}
+
+	reopened, err := NewWriterReadOnly(vaultPath)
+	if err != nil {
+		t.Fatalf("NewWriterReadOnly failed: %v", err)
+	}
+	if got := reopened.Version(); got != SourceFormatVersion {
+		t.Errorf("expected format version %d, got %d", SourceFormatVersion, got)
+	}
+	v, err := reopened.ReadVault()
+	if err != nil {
+		t.Fatalf("ReadVault failed: %v", err)
+	}
+	values := v.GetSecretByKey("KEY").Values
+	if len(values) != 2 || values[0].Source != "" || values[1].Source != "rotated-by-ci" {
+		t.Errorf("expected the source kept per value, got %+v", values)
+	}
+
+	// Values without a source hash exactly as before the field existed
+	if got := CanonicalValueData(&values[0], "KEY"); strings.Contains(got, "source") {
+		t.Errorf("expected no source in canonical data, got %q", got)
+	}
+	if !strings.HasSuffix(CanonicalValueData(&values[1], "KEY"), ":source=rotated-by-ci") {
+		t.Error("expected the source in the canonical data")
+	}
+}
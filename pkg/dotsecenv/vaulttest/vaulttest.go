@@ -0,0 +1,175 @@
+// Package vaulttest builds vaults with valid hashes and signatures for tests
+// of code built on pkg/dotsecenv/vault. Identities are backed by in-memory
+// OpenPGP keys, so neither gpg nor gpg-agent is involved.
+//
+// Hashes are computed with the same canonical functions the validator uses
+// (identity.ComputeIdentityHash, vault.ComputeSecretHash and
+// vault.ComputeSecretValueHash), so a built vault verifies exactly like one
+// written by the CLI.
+package vaulttest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v3/crypto"
+	"github.com/ProtonMail/gopenpgp/v3/profile"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/gpg"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+// BaseTime is the added_at given to the first entry whose AddedAt is unset.
+// Later unset entries follow one second apart, keeping the vault ordered.
+var BaseTime = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Identity is a vault identity together with the private key that signs on
+// its behalf.
+type Identity struct {
+	vault.Identity
+	key *crypto.Key
+}
+
+// NewIdentity generates an Ed25519 key for name and email and returns it as
+// an unsigned vault identity. BuildSignedVault fills in its hash and signature.
+func NewIdentity(name, email string) (*Identity, error) {
+	key, err := crypto.PGPWithProfile(profile.RFC9580()).KeyGeneration().
+		AddUserId(name, email).New().GenerateKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	publicKey, err := key.GetPublicKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to export public key: %w", err)
+	}
+
+	bits, err := key.GetEntity().PrimaryKey.BitLength()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key size: %w", err)
+	}
+
+	return &Identity{
+		Identity: vault.Identity{
+			Algorithm:     "EdDSA",
+			AlgorithmBits: int(bits),
+			CreatedAt:     key.GetEntity().PrimaryKey.CreationTime.UTC(),
+			Curve:         "Ed25519",
+			Fingerprint:   strings.ToUpper(key.GetFingerprint()),
+			PublicKey:     base64.StdEncoding.EncodeToString(publicKey),
+			UID:           fmt.Sprintf("%s <%s>", name, email),
+		},
+		key: key,
+	}, nil
+}
+
+// Sign returns the hex-encoded detached signature of data, in the form the
+// vault stores it.
+func (id *Identity) Sign(data []byte) (string, error) {
+	// gpg.SignData clears the private parameters of the key it is given,
+	// so each signature uses a fresh copy.
+	key, err := id.key.Copy()
+	if err != nil {
+		return "", fmt.Errorf("failed to copy key: %w", err)
+	}
+	return gpg.SignData(key, data)
+}
+
+// BuildSignedVault returns a vault holding identities and secrets with every
+// hash and signature computed. The inputs are not modified. Unset fields get
+// defaults:
+//   - an identity's SignedBy is its own fingerprint
+//   - a secret's SignedBy is the first identity
+//   - a value's SignedBy is its secret's signer
+//   - AddedAt counts up from BaseTime in input order
+//
+// Every SignedBy must name one of identities, and available_to lists are
+// sorted as the validator requires.
+func BuildSignedVault(identities []*Identity, secrets []vault.Secret) (vault.Vault, error) {
+	if len(identities) == 0 {
+		return vault.Vault{}, fmt.Errorf("at least one identity is required")
+	}
+
+	signers := make(map[string]*Identity, len(identities))
+	for _, id := range identities {
+		signers[id.Fingerprint] = id
+	}
+	signerFor := func(fingerprint string) (*Identity, error) {
+		signer, ok := signers[fingerprint]
+		if !ok {
+			return nil, fmt.Errorf("signer %s is not one of the vault identities", fingerprint)
+		}
+		return signer, nil
+	}
+
+	next := BaseTime
+	stamp := func(t time.Time) time.Time {
+		if t.IsZero() {
+			t = next
+			next = next.Add(time.Second)
+		}
+		return t
+	}
+
+	var v vault.Vault
+	for _, id := range identities {
+		entry := id.Identity
+		entry.AddedAt = stamp(entry.AddedAt)
+		if entry.SignedBy == "" {
+			entry.SignedBy = entry.Fingerprint
+		}
+		signer, err := signerFor(entry.SignedBy)
+		if err != nil {
+			return vault.Vault{}, fmt.Errorf("identity %s: %w", entry.Fingerprint, err)
+		}
+
+		entry.Hash = identity.ComputeIdentityHash(&entry)
+		if entry.Signature, err = signer.Sign([]byte(entry.Hash)); err != nil {
+			return vault.Vault{}, fmt.Errorf("identity %s: %w", entry.Fingerprint, err)
+		}
+		v.Identities = append(v.Identities, entry)
+	}
+
+	for _, secret := range secrets {
+		entry := secret
+		entry.AddedAt = stamp(entry.AddedAt)
+		if entry.SignedBy == "" {
+			entry.SignedBy = identities[0].Fingerprint
+		}
+		signer, err := signerFor(entry.SignedBy)
+		if err != nil {
+			return vault.Vault{}, fmt.Errorf("secret %s: %w", entry.Key, err)
+		}
+
+		entry.Hash = vault.ComputeSecretHash(&entry, signer.AlgorithmBits)
+		if entry.Signature, err = signer.Sign([]byte(entry.Hash)); err != nil {
+			return vault.Vault{}, fmt.Errorf("secret %s: %w", entry.Key, err)
+		}
+
+		entry.Values = make([]vault.SecretValue, len(secret.Values))
+		for j, value := range secret.Values {
+			value.AddedAt = stamp(value.AddedAt)
+			if value.SignedBy == "" {
+				value.SignedBy = entry.SignedBy
+			}
+			value.AvailableTo = append([]string(nil), value.AvailableTo...)
+			sort.Strings(value.AvailableTo)
+
+			valueSigner, err := signerFor(value.SignedBy)
+			if err != nil {
+				return vault.Vault{}, fmt.Errorf("secret %s value %d: %w", entry.Key, j, err)
+			}
+			value.Hash = vault.ComputeSecretValueHash(&value, entry.Key, valueSigner.AlgorithmBits)
+			if value.Signature, err = valueSigner.Sign([]byte(value.Hash)); err != nil {
+				return vault.Vault{}, fmt.Errorf("secret %s value %d: %w", entry.Key, j, err)
+			}
+			entry.Values[j] = value
+		}
+		v.Secrets = append(v.Secrets, entry)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,129 @@
+package vaulttest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/identity"
+	"github.com/dotsecenv/dotsecenv/pkg/dotsecenv/vault"
+)
+
+func newTestIdentities(t *testing.T) (*Identity, *Identity) {
+	t.Helper()
+	alice, err := NewIdentity("Alice", "alice@example.com")
+	if err != nil {
+		t.Fatalf("NewIdentity failed: %v", err)
+	}
+	bob, err := NewIdentity("Bob", "bob@example.com")
+	if err != nil {
+		t.Fatalf("NewIdentity failed: %v", err)
+	}
+	bob.SignedBy = alice.Fingerprint
+	return alice, bob
+}
+
+func TestBuildSignedVault(t *testing.T) {
+	alice, bob := newTestIdentities(t)
+
+	v, err := BuildSignedVault([]*Identity{alice, bob}, []vault.Secret{
+		{Key: "DB_PASSWORD", Values: []vault.SecretValue{
+			{AvailableTo: []string{bob.Fingerprint, alice.Fingerprint}, Value: "Y2lwaGVy"},
+			{AvailableTo: []string{alice.Fingerprint}, SignedBy: bob.Fingerprint, Value: "Y2lwaGVyMg=="},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("BuildSignedVault failed: %v", err)
+	}
+
+	for i := range v.Identities {
+		id := &v.Identities[i]
+		signer := v.GetIdentityByFingerprint(id.SignedBy)
+		if err := identity.ValidateIdentity(id, signer); err != nil {
+			t.Errorf("identity %s does not verify: %v", id.UID, err)
+		}
+	}
+	if v.Identities[1].SignedBy != alice.Fingerprint {
+		t.Errorf("explicit SignedBy was not kept: %s", v.Identities[1].SignedBy)
+	}
+
+	secret := &v.Secrets[0]
+	if err := vault.ValidateSecret(secret, v.GetIdentityByFingerprint(secret.SignedBy)); err != nil {
+		t.Errorf("secret does not verify: %v", err)
+	}
+	for j := range secret.Values {
+		value := &secret.Values[j]
+		if err := vault.ValidateSecretValue(value, secret.Key, v.GetIdentityByFingerprint(value.SignedBy)); err != nil {
+			t.Errorf("value %d does not verify: %v", j, err)
+		}
+	}
+	if secret.Values[0].SignedBy != alice.Fingerprint || secret.Values[1].SignedBy != bob.Fingerprint {
+		t.Errorf("unexpected value signers: %s, %s", secret.Values[0].SignedBy, secret.Values[1].SignedBy)
+	}
+	if !secret.Values[0].AddedAt.After(secret.AddedAt) || !secret.Values[1].AddedAt.After(secret.Values[0].AddedAt) {
+		t.Errorf("entries are not in added_at order")
+	}
+
+	// Tampering with a signed field must break verification.
+	secret.Values[0].Value = "dGFtcGVyZWQ="
+	if err := vault.ValidateSecretValue(&secret.Values[0], secret.Key, v.GetIdentityByFingerprint(alice.Fingerprint)); err == nil {
+		t.Error("expected tampered value to fail verification")
+	}
+}
+
+func TestBuildSignedVault_DoesNotModifyInputs(t *testing.T) {
+	alice, bob := newTestIdentities(t)
+	secrets := []vault.Secret{{Key: "KEY", Values: []vault.SecretValue{
+		{AvailableTo: []string{"ZZZ", "AAA"}, Value: "dmFsdWU="},
+	}}}
+
+	if _, err := BuildSignedVault([]*Identity{alice, bob}, secrets); err != nil {
+		t.Fatalf("BuildSignedVault failed: %v", err)
+	}
+	if alice.Hash != "" || secrets[0].Hash != "" || secrets[0].Values[0].Hash != "" {
+		t.Error("inputs were given hashes")
+	}
+	if secrets[0].Values[0].AvailableTo[0] != "ZZZ" {
+		t.Error("input available_to was sorted in place")
+	}
+}
+
+func TestBuildSignedVault_UnknownSigner(t *testing.T) {
+	alice, _ := newTestIdentities(t)
+
+	_, err := BuildSignedVault([]*Identity{alice}, []vault.Secret{{Key: "KEY", SignedBy: "UNKNOWN"}})
+	if err == nil || !strings.Contains(err.Error(), "not one of the vault identities") {
+		t.Errorf("expected unknown signer error, got %v", err)
+	}
+
+	if _, err := BuildSignedVault(nil, nil); err == nil {
+		t.Error("expected error without identities")
+	}
+}
+
+func TestBuildSignedVault_ValueSourceIsSigned(t *testing.T) {
+	alice, _ := newTestIdentities(t)
+
+	v, err := BuildSignedVault([]*Identity{alice}, []vault.Secret{
+		{Key: "API_KEY", Values: []vault.SecretValue{
+			{AvailableTo: []string{alice.Fingerprint}, Value: "Y2lwaGVy", Source: "imported-from:aws"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("BuildSignedVault failed: %v", err)
+	}
+
+	value := &v.Secrets[0].Values[0]
+	signer := v.GetIdentityByFingerprint(alice.Fingerprint)
+	if err := vault.ValidateSecretValue(value, "API_KEY", signer); err != nil {
+		t.Fatalf("value with a source does not verify: %v", err)
+	}
+
+	// Relabelling or dropping the source must break verification.
+	for _, source := range []string{"rotated-by-ci", ""} {
+		tampered := *value
+		tampered.Source = source
+		if err := vault.ValidateSecretValue(&tampered, "API_KEY", signer); err == nil {
+			t.Errorf("expected source %q to fail verification", source)
+		}
+	}
+}